@@ -0,0 +1,110 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	"arbitrage/internal/backtest"
+	"arbitrage/internal/models"
+	"arbitrage/pkg/utils"
+)
+
+// Backtest: прогон движка по записанным рыночным данным
+//
+// Пример:
+//
+//	go run ./cmd/backtest -data data/marketdata -pairs pairs.json
+//
+// pairs.json - массив models.PairConfig:
+//
+//	[{"id": 1, "symbol": "BTCUSDT", "entry_spread": 0.3, "exit_spread": 0.05, "volume": 0.01, "n_orders": 1}]
+func main() {
+	dataDir := flag.String("data", "data/marketdata", "каталог с записью рыночных данных")
+	pairsFile := flag.String("pairs", "pairs.json", "JSON файл с массивом PairConfig")
+	venues := flag.String("exchanges", "", "биржи через запятую (по умолчанию все)")
+	balance := flag.Float64("balance", 10000, "стартовый баланс на каждой бирже, USDT")
+	leverage := flag.Int("leverage", 10, "плечо симулятора")
+	maxArbs := flag.Int("max-arbs", 0, "максимум одновременных арбитражей (0 = без лимита)")
+	exitInterval := flag.Duration("exit-interval", 500*time.Millisecond, "период проверки условий выхода (виртуальное время)")
+	jsonOut := flag.Bool("json", false, "вывести отчёт в JSON")
+	flag.Parse()
+
+	utils.InitGlobalLogger(utils.LogConfig{Level: "error", Format: "text"})
+
+	pairs, err := loadPairs(*pairsFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load pairs: %v\n", err)
+		os.Exit(1)
+	}
+
+	cfg := backtest.DefaultConfig(*dataDir)
+	cfg.Pairs = pairs
+	cfg.InitialBalance = *balance
+	cfg.Leverage = *leverage
+	cfg.Bot.MaxConcurrentArbs = *maxArbs
+	cfg.ExitCheckInterval = *exitInterval
+	if *venues != "" {
+		cfg.Venues = strings.Split(*venues, ",")
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report, err := backtest.Run(ctx, cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Backtest failed: %v\n", err)
+		os.Exit(1)
+	}
+
+	if *jsonOut {
+		enc := json.NewEncoder(os.Stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(report); err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to encode report: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	report.WriteText(os.Stdout)
+}
+
+// loadPairs читает массив PairConfig; статус, число частей и валюты по умолчанию
+// Пары проверяются так же, как при создании через API (PairConfig.Validate)
+func loadPairs(path string) ([]*models.PairConfig, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var pairs []*models.PairConfig
+	if err := json.Unmarshal(data, &pairs); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+
+	for i, pair := range pairs {
+		if pair.ID == 0 {
+			pair.ID = i + 1
+		}
+		if pair.NOrders == 0 {
+			pair.NOrders = 1
+		}
+		// Валюты по символу USDT-контракта, если не заданы
+		if pair.Base == "" && pair.Quote == "" && strings.HasSuffix(pair.Symbol, "USDT") {
+			pair.Base, pair.Quote = strings.TrimSuffix(pair.Symbol, "USDT"), "USDT"
+		}
+		pair.Status = models.PairStatusActive
+
+		if err := pair.Validate(); err != nil {
+			return nil, fmt.Errorf("%s: pair %d: %w", path, pair.ID, err)
+		}
+	}
+	return pairs, nil
+}
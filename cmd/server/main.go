@@ -11,7 +11,9 @@ import (
 	"time"
 
 	"arbitrage/internal/api"
+	"arbitrage/internal/bot"
 	"arbitrage/internal/config"
+	"arbitrage/internal/exchange"
 	"arbitrage/internal/repository"
 	"arbitrage/internal/service"
 	"arbitrage/internal/websocket"
@@ -42,6 +44,22 @@ func main() {
 		utils.String("log_format", cfg.Logging.Format),
 	)
 
+	// Синхронизация часов и окно приёма подписанных запросов для всех адаптеров бирж
+	exchange.SetClockConfig(exchange.ClockConfig{
+		SyncInterval: cfg.Exchange.ClockSyncInterval,
+		RecvWindow:   cfg.Exchange.RecvWindow,
+		RecvWindows:  cfg.Exchange.RecvWindows,
+	})
+
+	// Пороги отключения бирж от новых входов
+	exchange.SetHealthConfig(exchange.HealthConfig{
+		Window:        cfg.Exchange.HealthWindow,
+		TripErrorRate: float64(cfg.Exchange.HealthTripErrorPct) / 100,
+		TripLatency:   cfg.Exchange.HealthTripLatency,
+		StaleAfter:    cfg.Exchange.HealthStaleAfter,
+		Cooldown:      cfg.Exchange.HealthCooldown,
+	})
+
 	// Инициализация базы данных
 	db, err := initDatabase(cfg, logger)
 	if err != nil {
@@ -103,9 +121,32 @@ func main() {
 	exchangeService.SetWebSocketHub(wsHub)
 	statsService.SetWebSocketHub(wsHub)
 
-	// TODO: Инициализация бота
-	// botEngine := bot.NewEngine(db, hub)
-	// go botEngine.Run()
+	// Инициализация торгового движка
+	botEngine := bot.NewEngine(cfg, wsHub)
+	pairService.SetEngine(botEngine)
+	exchangeService.SetEngine(botEngine)
+	settingsService.SetEngine(botEngine)
+
+	// Глобальные настройки, влияющие на торговлю
+	if settings, err := settingsService.GetSettings(); err != nil {
+		utils.Warn("Failed to load settings, funding is not considered", utils.Err(err))
+	} else {
+		botEngine.SetConsiderFunding(settings.ConsiderFunding)
+	}
+
+	engineCtx, engineCancel := context.WithCancel(context.Background())
+	defer engineCancel()
+
+	engineDone := make(chan error, 1)
+	go func() {
+		engineDone <- botEngine.Run(engineCtx)
+	}()
+	utils.Info("Bot engine started",
+		utils.Int("shards", botEngine.GetNumShards()),
+	)
+
+	// Восстановление бирж, пар и открытых позиций после перезапуска
+	recoverEngine(cfg, botEngine, exchangeRepo, pairRepo, exchangeService)
 
 	// Настройка зависимостей для API
 	deps := &api.Dependencies{
@@ -159,27 +200,92 @@ func main() {
 		utils.String("signal", sig.String()),
 	)
 
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	utils.Info("Shutting down server gracefully",
+		utils.Duration("timeout", 30*time.Second),
+	)
+
+	// Сначала останавливаем HTTP, чтобы API не запускал пары во время остановки движка
+	if err := server.Shutdown(ctx); err != nil {
+		utils.Error("Server forced to shutdown", utils.Err(err))
+	}
+
+	// Останавливаем движок: новые входы запрещены, ждём пары в ENTERING/EXITING
+	stopEngine(botEngine, engineCancel, engineDone, cfg.Bot.ShutdownTimeout)
+
 	// Останавливаем WebSocket hub (graceful shutdown)
 	wsHub.Stop()
 	utils.Info("WebSocket hub stopped")
 
-	// Закрываем соединения с биржами
+	// Закрываем соединения с биржами (включая восстановленные движком)
 	if err := exchangeService.Close(); err != nil {
 		utils.Error("Error closing exchange connections", utils.Err(err))
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
+	utils.Info("Server exited successfully")
+}
 
-	utils.Info("Shutting down server gracefully",
-		utils.Duration("timeout", 30*time.Second),
+// recoverEngine восстанавливает биржи, пары и позиции движка из БД
+// Ошибки восстановления не фатальны: сервер продолжает работу, пользователь получает уведомления
+func recoverEngine(
+	cfg *config.Config,
+	engine *bot.Engine,
+	exchangeRepo *repository.ExchangeRepository,
+	pairRepo *repository.PairRepository,
+	exchangeService *service.ExchangeService,
+) {
+	recovery := bot.NewRecoveryManager(
+		cfg,
+		exchangeRepo,
+		pairRepo,
+		engine,
+		engine.NotificationChan(),
+		bot.DefaultRecoveryConfig(),
 	)
 
-	if err := server.Shutdown(ctx); err != nil {
-		utils.Fatal("Server forced to shutdown", utils.Err(err))
+	result, err := recovery.Recover(context.Background())
+	if err != nil {
+		utils.Error("Engine recovery failed", utils.Err(err))
 	}
 
-	utils.Info("Server exited successfully")
+	// API должен работать через те же соединения, что и движок
+	for name, exch := range engine.GetExchanges() {
+		exchangeService.AttachConnection(name, exch)
+	}
+
+	if result != nil {
+		utils.Info("Engine recovery completed",
+			utils.Int("exchanges", result.ExchangesRestored),
+			utils.Int("pairs_loaded", result.PairsLoaded),
+			utils.Int("pairs_activated", result.PairsActivated),
+			utils.Int("matched_positions", len(result.MatchedPositions)),
+			utils.Int("orphaned_positions", len(result.OrphanedPositions)),
+		)
+	}
+}
+
+// stopEngine дожидается завершения активных входов/выходов и останавливает движок
+func stopEngine(engine *bot.Engine, cancel context.CancelFunc, done <-chan error, timeout time.Duration) {
+	drainCtx, drainCancel := context.WithTimeout(context.Background(), timeout)
+	defer drainCancel()
+
+	utils.Info("Draining bot engine",
+		utils.Duration("timeout", timeout),
+	)
+
+	if err := engine.Drain(drainCtx); err != nil {
+		utils.Warn("Engine drain timed out, pairs may remain in ENTERING/EXITING",
+			utils.Err(err),
+		)
+	}
+
+	cancel()
+	<-done
+	utils.Info("Bot engine stopped",
+		utils.Int64("active_arbitrages", engine.GetActiveArbitrages()),
+	)
 }
 
 // initDatabase создает подключение к базе данных
@@ -7,6 +7,7 @@ import (
 	"strings"
 
 	"arbitrage/internal/exchange"
+	"arbitrage/internal/models"
 	"arbitrage/internal/service"
 
 	"github.com/gorilla/mux"
@@ -16,15 +17,39 @@ import (
 type ConnectExchangeRequest struct {
 	APIKey     string `json:"api_key"`
 	SecretKey  string `json:"secret_key"`
-	Passphrase string `json:"passphrase,omitempty"` // для OKX
+	Passphrase string `json:"passphrase,omitempty"` // для бирж с RequiresPassphrase (OKX, Bitget)
 }
 
 // ExchangeResponse - ответ с информацией о бирже
 type ExchangeResponse struct {
-	Name      string  `json:"name"`
-	Connected bool    `json:"connected"`
-	Balance   float64 `json:"balance"`
-	LastError string  `json:"last_error,omitempty"`
+	Name               string                 `json:"name"`
+	Label              string                 `json:"label,omitempty"`
+	Account            string                 `json:"account"` // идентификатор аккаунта: bybit, arb1@bybit
+	Connected          bool                   `json:"connected"`
+	Balance            float64                `json:"balance"`
+	LastError          string                 `json:"last_error,omitempty"`
+	Capabilities       exchange.Capabilities  `json:"capabilities"`
+	RequiresPassphrase bool                   `json:"requires_passphrase"`
+	Health             *exchange.HealthStatus `json:"health,omitempty"` // только для подключённых бирж
+}
+
+// newExchangeResponse формирует ответ по аккаунту биржи и описанию адаптера из реестра
+func newExchangeResponse(account *models.ExchangeAccount) ExchangeResponse {
+	response := ExchangeResponse{
+		Name:               account.Name,
+		Label:              account.Label,
+		Account:            exchange.AccountKey(account.Name, account.Label),
+		Connected:          account.Connected,
+		Balance:            account.Balance,
+		LastError:          account.LastError,
+		Capabilities:       exchange.CapabilitiesOf(account.Name),
+		RequiresPassphrase: exchange.RequiresPassphrase(account.Name),
+	}
+	if account.Connected {
+		health := exchange.HealthOf(account.Name).Status()
+		response.Health = &health
+	}
+	return response
 }
 
 // BalanceResponse - ответ с балансом биржи
@@ -44,6 +69,10 @@ const MaxRequestBodySize = 1 << 20 // 1 MB
 // - DELETE /api/v1/exchanges/{name}/connect - отключение биржи
 // - GET /api/v1/exchanges - получение списка бирж и их статусов
 // - GET /api/v1/exchanges/{name}/balance - обновление баланса биржи
+// - GET /api/v1/exchanges/{name}/health - состояние биржи (circuit breaker)
+//
+// {name} - идентификатор аккаунта: имя биржи для основного аккаунта (bybit)
+// или метка@биржа для субаккаунта (arb1@bybit)
 type ExchangeHandler struct {
 	exchangeService service.ExchangeServiceInterface
 }
@@ -63,7 +92,7 @@ func NewExchangeHandler(exchangeService service.ExchangeServiceInterface) *Excha
 //	{
 //	  "api_key": "your-api-key",
 //	  "secret_key": "your-secret-key",
-//	  "passphrase": "optional-passphrase" // для OKX
+//	  "passphrase": "optional-passphrase" // если requires_passphrase
 //	}
 //
 // Ответы:
@@ -76,8 +105,8 @@ func (h *ExchangeHandler) ConnectExchange(w http.ResponseWriter, r *http.Request
 	exchangeName := strings.ToLower(vars["name"])
 
 	// 1. Проверяем поддержку биржи
-	if !exchange.IsSupported(exchangeName) {
-		h.respondWithError(w, http.StatusBadRequest, "Unsupported exchange", "Supported exchanges: "+strings.Join(exchange.SupportedExchanges, ", "))
+	if !exchange.IsSupportedAccount(exchangeName) {
+		h.respondWithError(w, http.StatusBadRequest, "Unsupported exchange", "Supported exchanges: "+strings.Join(exchange.SupportedExchanges(), ", "))
 		return
 	}
 
@@ -99,9 +128,9 @@ func (h *ExchangeHandler) ConnectExchange(w http.ResponseWriter, r *http.Request
 		return
 	}
 
-	// OKX требует passphrase
-	if exchangeName == "okx" && req.Passphrase == "" {
-		h.respondWithError(w, http.StatusBadRequest, "Passphrase is required for OKX", "")
+	// Некоторые биржи (OKX, Bitget) требуют passphrase
+	if exchange.RequiresPassphrase(exchange.VenueOf(exchangeName)) && req.Passphrase == "" {
+		h.respondWithError(w, http.StatusBadRequest, "Passphrase is required for "+exchangeName, "")
 		return
 	}
 
@@ -113,6 +142,8 @@ func (h *ExchangeHandler) ConnectExchange(w http.ResponseWriter, r *http.Request
 		switch {
 		case errors.Is(err, service.ErrExchangeNotSupported):
 			h.respondWithError(w, http.StatusBadRequest, "Exchange not supported", err.Error())
+		case errors.Is(err, service.ErrInvalidAccountLabel):
+			h.respondWithError(w, http.StatusBadRequest, "Invalid account label", err.Error())
 		case errors.Is(err, service.ErrExchangeAlreadyConnected):
 			h.respondWithError(w, http.StatusConflict, "Exchange is already connected", "Disconnect first to change credentials")
 		case errors.Is(err, service.ErrInvalidCredentials):
@@ -138,12 +169,7 @@ func (h *ExchangeHandler) ConnectExchange(w http.ResponseWriter, r *http.Request
 	}
 
 	// 6. Возвращаем успешный ответ
-	h.respondWithJSON(w, http.StatusOK, ExchangeResponse{
-		Name:      account.Name,
-		Connected: account.Connected,
-		Balance:   account.Balance,
-		LastError: account.LastError,
-	})
+	h.respondWithJSON(w, http.StatusOK, newExchangeResponse(account))
 }
 
 // DisconnectExchange отключает биржу (удаляет API ключи)
@@ -158,8 +184,8 @@ func (h *ExchangeHandler) DisconnectExchange(w http.ResponseWriter, r *http.Requ
 	exchangeName := strings.ToLower(vars["name"])
 
 	// 1. Проверяем поддержку биржи
-	if !exchange.IsSupported(exchangeName) {
-		h.respondWithError(w, http.StatusBadRequest, "Unsupported exchange", "Supported exchanges: "+strings.Join(exchange.SupportedExchanges, ", "))
+	if !exchange.IsSupportedAccount(exchangeName) {
+		h.respondWithError(w, http.StatusBadRequest, "Unsupported exchange", "Supported exchanges: "+strings.Join(exchange.SupportedExchanges(), ", "))
 		return
 	}
 
@@ -196,7 +222,10 @@ func (h *ExchangeHandler) DisconnectExchange(w http.ResponseWriter, r *http.Requ
 //	    "name": "bybit",
 //	    "connected": true,
 //	    "balance": 1500.00,
-//	    "last_error": ""
+//	    "last_error": "",
+//	    "capabilities": {"private_ws": true, "limit_orders": true, "funding": true, "hedge_mode": false},
+//	    "requires_passphrase": false,
+//	    "health": {"exchange": "bybit", "state": "healthy", "score": 0.98, ...}
 //	  },
 //	  ...
 //	]
@@ -211,12 +240,7 @@ func (h *ExchangeHandler) GetExchanges(w http.ResponseWriter, r *http.Request) {
 	// 2. Формируем ответ
 	response := make([]ExchangeResponse, 0, len(accounts))
 	for _, account := range accounts {
-		response = append(response, ExchangeResponse{
-			Name:      account.Name,
-			Connected: account.Connected,
-			Balance:   account.Balance,
-			LastError: account.LastError,
-		})
+		response = append(response, newExchangeResponse(account))
 	}
 
 	// 3. Возвращаем список
@@ -238,8 +262,8 @@ func (h *ExchangeHandler) GetExchangeBalance(w http.ResponseWriter, r *http.Requ
 	exchangeName := strings.ToLower(vars["name"])
 
 	// 1. Проверяем поддержку биржи
-	if !exchange.IsSupported(exchangeName) {
-		h.respondWithError(w, http.StatusBadRequest, "Unsupported exchange", "Supported exchanges: "+strings.Join(exchange.SupportedExchanges, ", "))
+	if !exchange.IsSupportedAccount(exchangeName) {
+		h.respondWithError(w, http.StatusBadRequest, "Unsupported exchange", "Supported exchanges: "+strings.Join(exchange.SupportedExchanges(), ", "))
 		return
 	}
 
@@ -264,6 +288,35 @@ func (h *ExchangeHandler) GetExchangeBalance(w http.ResponseWriter, r *http.Requ
 	})
 }
 
+// GetExchangeHealth возвращает состояние биржи: оценку, показатели REST и WebSocket
+// GET /api/v1/exchanges/{name}/health
+//
+// Ответ:
+//
+//	{
+//	  "exchange": "bybit",
+//	  "state": "tripped",
+//	  "score": 0,
+//	  "reason": "rest errors",
+//	  "requests": 12,
+//	  "error_rate": 0.58,
+//	  "avg_latency_ms": 240.5,
+//	  "ws_stale_sec": 0.4,
+//	  "ws_reconnects": 0,
+//	  "tripped_until": "2024-01-01T12:00:30Z"
+//	}
+func (h *ExchangeHandler) GetExchangeHealth(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	exchangeName := strings.ToLower(vars["name"])
+
+	if !exchange.IsSupportedAccount(exchangeName) {
+		h.respondWithError(w, http.StatusBadRequest, "Unsupported exchange", "Supported exchanges: "+strings.Join(exchange.SupportedExchanges(), ", "))
+		return
+	}
+
+	h.respondWithJSON(w, http.StatusOK, exchange.HealthOf(exchangeName).Status())
+}
+
 // respondWithJSON отправляет JSON ответ
 func (h *ExchangeHandler) respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	response, err := json.Marshal(payload)
@@ -35,23 +35,37 @@ func NewPairHandler(pairService service.PairServiceInterface) *PairHandler {
 
 // CreatePairRequest структура запроса на создание пары
 type CreatePairRequest struct {
-	Symbol         string  `json:"symbol"`       // BTCUSDT
-	Base           string  `json:"base"`         // BTC
-	Quote          string  `json:"quote"`        // USDT
-	EntrySpreadPct float64 `json:"entry_spread"` // % для входа
-	ExitSpreadPct  float64 `json:"exit_spread"`  // % для выхода
-	VolumeAsset    float64 `json:"volume"`       // объем в монетах
-	NOrders        int     `json:"n_orders"`     // количество частей (default: 1)
-	StopLoss       float64 `json:"stop_loss"`    // в USDT (опционально)
+	Symbol         string   `json:"symbol"`         // BTCUSDT
+	Base           string   `json:"base"`           // BTC
+	Quote          string   `json:"quote"`          // USDT
+	EntrySpreadPct float64  `json:"entry_spread"`   // % для входа
+	ExitSpreadPct  float64  `json:"exit_spread"`    // % для выхода
+	VolumeAsset    float64  `json:"volume"`         // объем в монетах
+	NOrders        int      `json:"n_orders"`       // количество частей (default: 1)
+	StopLoss       float64  `json:"stop_loss"`      // в USDT (опционально)
+	EntryMode      string   `json:"entry_mode"`     // taker (default), maker_taker
+	Strategy       string   `json:"strategy"`       // spread (default), funding
+	FundingDiffPct float64  `json:"funding_diff"`   // % разницы ставок фандинга за 8ч для входа (funding)
+	MaxHoldHours   int      `json:"max_hold_hours"` // максимальное время удержания (0 = без ограничения)
+	Leverage       int      `json:"leverage"`       // плечо на обеих биржах (default: 1)
+	MarginMode     string   `json:"margin_mode"`    // cross (default), isolated
+	Accounts       []string `json:"accounts"`       // аккаунты пары (пусто - все подключенные)
 }
 
 // UpdatePairRequest структура запроса на обновление пары
 type UpdatePairRequest struct {
-	EntrySpreadPct *float64 `json:"entry_spread,omitempty"`
-	ExitSpreadPct  *float64 `json:"exit_spread,omitempty"`
-	VolumeAsset    *float64 `json:"volume,omitempty"`
-	NOrders        *int     `json:"n_orders,omitempty"`
-	StopLoss       *float64 `json:"stop_loss,omitempty"`
+	EntrySpreadPct *float64  `json:"entry_spread,omitempty"`
+	ExitSpreadPct  *float64  `json:"exit_spread,omitempty"`
+	VolumeAsset    *float64  `json:"volume,omitempty"`
+	NOrders        *int      `json:"n_orders,omitempty"`
+	StopLoss       *float64  `json:"stop_loss,omitempty"`
+	EntryMode      *string   `json:"entry_mode,omitempty"`
+	Strategy       *string   `json:"strategy,omitempty"`
+	FundingDiffPct *float64  `json:"funding_diff,omitempty"`
+	MaxHoldHours   *int      `json:"max_hold_hours,omitempty"`
+	Leverage       *int      `json:"leverage,omitempty"`
+	MarginMode     *string   `json:"margin_mode,omitempty"`
+	Accounts       *[]string `json:"accounts,omitempty"`
 }
 
 // PairResponse структура ответа с данными пары
@@ -65,6 +79,13 @@ type PairResponse struct {
 	VolumeAsset    float64                `json:"volume"`
 	NOrders        int                    `json:"n_orders"`
 	StopLoss       float64                `json:"stop_loss"`
+	EntryMode      string                 `json:"entry_mode"`
+	Strategy       string                 `json:"strategy"`
+	FundingDiffPct float64                `json:"funding_diff"`
+	MaxHoldHours   int                    `json:"max_hold_hours"`
+	Leverage       int                    `json:"leverage"`
+	MarginMode     string                 `json:"margin_mode"`
+	Accounts       []string               `json:"accounts,omitempty"`
 	Status         string                 `json:"status"`
 	Stats          *PairStatsResponse     `json:"stats"`
 	Runtime        *PairRuntimeResponse   `json:"runtime,omitempty"`
@@ -79,12 +100,14 @@ type PairStatsResponse struct {
 
 // PairRuntimeResponse runtime состояние пары
 type PairRuntimeResponse struct {
-	State          string        `json:"state"`
-	Legs           []LegResponse `json:"legs,omitempty"`
-	CurrentSpread  float64       `json:"current_spread"`
-	UnrealizedPnl  float64       `json:"unrealized_pnl"`
-	RealizedPnl    float64       `json:"realized_pnl"`
-	FilledParts    int           `json:"filled_parts"`
+	State            string        `json:"state"`
+	Legs             []LegResponse `json:"legs,omitempty"`
+	CurrentSpread    float64       `json:"current_spread"`
+	UnrealizedPnl    float64       `json:"unrealized_pnl"`
+	RealizedPnl      float64       `json:"realized_pnl"`
+	FundingPnl       float64       `json:"funding_pnl"`
+	FundingEstimated float64       `json:"funding_estimated"` // часть FundingPnl по оценке, без подтверждения биржей
+	FilledParts      int           `json:"filled_parts"`
 }
 
 // LegResponse данные об одной ноге позиции
@@ -99,11 +122,18 @@ type LegResponse struct {
 
 // PendingConfigResponse отложенные изменения конфигурации
 type PendingConfigResponse struct {
-	EntrySpreadPct float64 `json:"entry_spread"`
-	ExitSpreadPct  float64 `json:"exit_spread"`
-	VolumeAsset    float64 `json:"volume"`
-	NOrders        int     `json:"n_orders"`
-	StopLoss       float64 `json:"stop_loss"`
+	EntrySpreadPct float64  `json:"entry_spread"`
+	ExitSpreadPct  float64  `json:"exit_spread"`
+	VolumeAsset    float64  `json:"volume"`
+	NOrders        int      `json:"n_orders"`
+	StopLoss       float64  `json:"stop_loss"`
+	EntryMode      string   `json:"entry_mode"`
+	Strategy       string   `json:"strategy"`
+	FundingDiffPct float64  `json:"funding_diff"`
+	MaxHoldHours   int      `json:"max_hold_hours"`
+	Leverage       int      `json:"leverage"`
+	MarginMode     string   `json:"margin_mode"`
+	Accounts       []string `json:"accounts,omitempty"`
 }
 
 // CreatePair добавляет новую торговую пару
@@ -119,7 +149,25 @@ type PendingConfigResponse struct {
 //	  "exit_spread": 0.2,
 //	  "volume": 0.5,
 //	  "n_orders": 4,
-//	  "stop_loss": 100
+//	  "stop_loss": 100,
+//	  "entry_mode": "maker_taker",
+//	  "leverage": 5,
+//	  "margin_mode": "isolated",
+//	  "accounts": ["arb1@bybit", "okx"]
+//	}
+//
+// accounts привязывает пару к аккаунтам бирж (минимум 2), пусто - все подключенные.
+//
+// Фандинговая пара (strategy=funding) входит по разнице ставок вместо спреда:
+//
+//	{
+//	  "symbol": "ETHUSDT",
+//	  "base": "ETH",
+//	  "quote": "USDT",
+//	  "volume": 2,
+//	  "strategy": "funding",
+//	  "funding_diff": 0.03,
+//	  "max_hold_hours": 72
 //	}
 //
 // Response:
@@ -151,6 +199,13 @@ func (h *PairHandler) CreatePair(w http.ResponseWriter, r *http.Request) {
 		VolumeAsset:    req.VolumeAsset,
 		NOrders:        req.NOrders,
 		StopLoss:       req.StopLoss,
+		EntryMode:      req.EntryMode,
+		Strategy:       req.Strategy,
+		FundingDiffPct: req.FundingDiffPct,
+		MaxHoldHours:   req.MaxHoldHours,
+		Leverage:       req.Leverage,
+		MarginMode:     req.MarginMode,
+		Accounts:       req.Accounts,
 	}
 
 	// Вызываем сервис для создания пары
@@ -267,6 +322,13 @@ func (h *PairHandler) UpdatePair(w http.ResponseWriter, r *http.Request) {
 		VolumeAsset:    req.VolumeAsset,
 		NOrders:        req.NOrders,
 		StopLoss:       req.StopLoss,
+		EntryMode:      req.EntryMode,
+		Strategy:       req.Strategy,
+		FundingDiffPct: req.FundingDiffPct,
+		MaxHoldHours:   req.MaxHoldHours,
+		Leverage:       req.Leverage,
+		MarginMode:     req.MarginMode,
+		Accounts:       req.Accounts,
 	}
 
 	// Обновляем пару
@@ -291,6 +353,13 @@ func (h *PairHandler) UpdatePair(w http.ResponseWriter, r *http.Request) {
 			VolumeAsset:    pending.VolumeAsset,
 			NOrders:        pending.NOrders,
 			StopLoss:       pending.StopLoss,
+			EntryMode:      pending.EntryMode,
+			Strategy:       pending.Strategy,
+			FundingDiffPct: pending.FundingDiffPct,
+			MaxHoldHours:   pending.MaxHoldHours,
+			Leverage:       pending.Leverage,
+			MarginMode:     pending.MarginMode,
+			Accounts:       pending.Accounts,
 		}
 	}
 
@@ -408,6 +477,13 @@ func (h *PairHandler) pairToResponse(pair *models.PairConfig, runtime *models.Pa
 		VolumeAsset:    pair.VolumeAsset,
 		NOrders:        pair.NOrders,
 		StopLoss:       pair.StopLoss,
+		EntryMode:      pair.EntryMode,
+		Strategy:       pair.Strategy,
+		FundingDiffPct: pair.FundingDiffPct,
+		MaxHoldHours:   pair.MaxHoldHours,
+		Leverage:       pair.Leverage,
+		MarginMode:     pair.MarginMode,
+		Accounts:       pair.Accounts,
 		Status:         pair.Status,
 		Stats: &PairStatsResponse{
 			TradesCount: pair.TradesCount,
@@ -418,12 +494,14 @@ func (h *PairHandler) pairToResponse(pair *models.PairConfig, runtime *models.Pa
 	// Добавляем runtime данные если есть
 	if runtime != nil {
 		runtimeResp := &PairRuntimeResponse{
-			State:         runtime.State,
-			CurrentSpread: runtime.CurrentSpread,
-			UnrealizedPnl: runtime.UnrealizedPnl,
-			RealizedPnl:   runtime.RealizedPnl,
-			FilledParts:   runtime.FilledParts,
-			Legs:          make([]LegResponse, 0, len(runtime.Legs)),
+			State:            runtime.State,
+			CurrentSpread:    runtime.CurrentSpread,
+			UnrealizedPnl:    runtime.UnrealizedPnl,
+			RealizedPnl:      runtime.RealizedPnl,
+			FundingPnl:       runtime.FundingPnl,
+			FundingEstimated: runtime.FundingEstimated,
+			FilledParts:      runtime.FilledParts,
+			Legs:             make([]LegResponse, 0, len(runtime.Legs)),
 		}
 
 		for _, leg := range runtime.Legs {
@@ -448,6 +526,13 @@ func (h *PairHandler) pairToResponse(pair *models.PairConfig, runtime *models.Pa
 			VolumeAsset:    pending.VolumeAsset,
 			NOrders:        pending.NOrders,
 			StopLoss:       pending.StopLoss,
+			EntryMode:      pending.EntryMode,
+			Strategy:       pending.Strategy,
+			FundingDiffPct: pending.FundingDiffPct,
+			MaxHoldHours:   pending.MaxHoldHours,
+			Leverage:       pending.Leverage,
+			MarginMode:     pending.MarginMode,
+			Accounts:       pending.Accounts,
 		}
 	}
 
@@ -502,6 +587,27 @@ func (h *PairHandler) handleServiceError(w http.ResponseWriter, err error) {
 	case errors.Is(err, service.ErrInvalidStopLoss):
 		h.respondWithError(w, http.StatusBadRequest, "invalid_stop_loss", "Stop loss must be non-negative", "")
 
+	case errors.Is(err, service.ErrInvalidEntryMode):
+		h.respondWithError(w, http.StatusBadRequest, "invalid_entry_mode", "Entry mode must be 'taker' or 'maker_taker'", "")
+
+	case errors.Is(err, service.ErrInvalidStrategy):
+		h.respondWithError(w, http.StatusBadRequest, "invalid_strategy", "Strategy must be 'spread' or 'funding'", "")
+
+	case errors.Is(err, service.ErrInvalidFundingDiff):
+		h.respondWithError(w, http.StatusBadRequest, "invalid_funding_diff", "Funding differential must be greater than 0", "")
+
+	case errors.Is(err, service.ErrInvalidMaxHold):
+		h.respondWithError(w, http.StatusBadRequest, "invalid_max_hold", "Max hold hours must be non-negative", "")
+
+	case errors.Is(err, service.ErrInvalidLeverage):
+		h.respondWithError(w, http.StatusBadRequest, "invalid_leverage", "Leverage must be between 1 and 125, or 0 for the default 1x", "")
+
+	case errors.Is(err, service.ErrInvalidMarginMode):
+		h.respondWithError(w, http.StatusBadRequest, "invalid_margin_mode", "Margin mode must be 'cross' or 'isolated'", "")
+
+	case errors.Is(err, service.ErrInvalidPairAccounts):
+		h.respondWithError(w, http.StatusBadRequest, "invalid_accounts", "Accounts must list at least 2 distinct supported exchange accounts", "")
+
 	case errors.Is(err, service.ErrInvalidSymbol):
 		h.respondWithError(w, http.StatusBadRequest, "invalid_symbol", "Invalid symbol format", "")
 
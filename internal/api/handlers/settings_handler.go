@@ -16,7 +16,7 @@ import (
 //
 // Настройки включают:
 // - max_concurrent_trades: ограничение на количество одновременных арбитражей (null = без ограничений)
-// - consider_funding: учитывать ли фандинг-рейты (future feature)
+// - consider_funding: учитывать ли ожидаемый фандинг в чистом спреде при входе
 // - notification_prefs: настройки отображения типов уведомлений
 type SettingsHandler struct {
 	settingsService service.SettingsServiceInterface
@@ -131,6 +131,7 @@ func SetupRoutes(deps *Dependencies) *mux.Router {
 		api.HandleFunc("/exchanges/{name}/connect", exchangeHandler.ConnectExchange).Methods("POST")
 		api.HandleFunc("/exchanges/{name}/connect", exchangeHandler.DisconnectExchange).Methods("DELETE")
 		api.HandleFunc("/exchanges/{name}/balance", exchangeHandler.GetExchangeBalance).Methods("GET")
+		api.HandleFunc("/exchanges/{name}/health", exchangeHandler.GetExchangeHealth).Methods("GET")
 	}
 
 	// Pair routes
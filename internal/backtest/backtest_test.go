@@ -0,0 +1,156 @@
+package backtest
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"arbitrage/internal/marketdata"
+	"arbitrage/internal/models"
+)
+
+func writeRecords(t *testing.T, dir string, records []*marketdata.Record) {
+	t.Helper()
+
+	writer, err := marketdata.NewWriter(marketdata.WriterConfig{Dir: dir})
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+	for _, rec := range records {
+		if err := writer.Write(rec); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+}
+
+// spreadDay - спред bybit/okx открывается на 1 секунду и схлопывается
+func spreadDay(base time.Time) []*marketdata.Record {
+	at := func(ms int) int64 { return base.Add(time.Duration(ms) * time.Millisecond).UnixNano() }
+	book := func(ms int, exch string, bid, ask float64) *marketdata.Record {
+		return &marketdata.Record{
+			Type: marketdata.TypeOrderBook, Ts: at(ms), RecvTs: at(ms), Exchange: exch, Symbol: "BTCUSDT",
+			Bids: []marketdata.Level{{bid, 5}, {bid - 0.5, 10}},
+			Asks: []marketdata.Level{{ask, 5}, {ask + 0.5, 10}},
+		}
+	}
+
+	return []*marketdata.Record{
+		book(0, "bybit", 100, 100.1),
+		book(10, "okx", 100, 100.1),
+		book(1000, "okx", 101, 101.1), // спред ~0.9%: вход long bybit / short okx
+		book(2000, "okx", 100, 100.1), // спред схлопнулся: выход
+		book(3000, "bybit", 100, 100.1),
+	}
+}
+
+// TestRun_SingleTrade проверяет полный цикл вход/выход и отчёт
+func TestRun_SingleTrade(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	writeRecords(t, dir, spreadDay(base))
+
+	cfg := DefaultConfig(dir)
+	cfg.Venues = []string{"bybit", "okx"}
+	cfg.Pairs = []*models.PairConfig{{
+		ID: 1, Symbol: "BTCUSDT", EntrySpreadPct: 0.3, ExitSpreadPct: 0.15,
+		VolumeAsset: 1, NOrders: 1, Status: models.PairStatusActive,
+	}}
+
+	report, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if report.TotalTrades != 1 || len(report.Pairs) != 1 {
+		t.Fatalf("expected 1 trade, got %d", report.TotalTrades)
+	}
+
+	trade := report.Pairs[0].Trades[0]
+	if trade.LongExchange != "bybit" || trade.ShortExchange != "okx" {
+		t.Fatalf("unexpected legs: %+v", trade)
+	}
+	if trade.LongEntry != 100.1 || trade.ShortEntry != 101 || trade.LongExit != 100 || trade.ShortExit != 100.1 {
+		t.Fatalf("unexpected fill prices: %+v", trade)
+	}
+	if math.Abs(trade.GrossPnl-0.8) > 1e-9 {
+		t.Fatalf("expected gross pnl 0.8, got %.6f", trade.GrossPnl)
+	}
+
+	expectedFees := 0.00055*(100.1+100) + 0.0005*(101+100.1)
+	if math.Abs(trade.Fees-expectedFees) > 1e-9 || math.Abs(trade.NetPnl-(0.8-expectedFees)) > 1e-9 {
+		t.Fatalf("unexpected fees/net: %.6f / %.6f", trade.Fees, trade.NetPnl)
+	}
+	if trade.HoldTime != time.Second {
+		t.Fatalf("expected hold time 1s (virtual clock), got %s", trade.HoldTime)
+	}
+	if trade.EntrySlippagePct != 0 {
+		t.Fatalf("expected zero slippage vs analyzer, got %.6f", trade.EntrySlippagePct)
+	}
+	if report.OpenAtEnd != 0 || report.HoldTimes.Buckets["<1m"] != 1 {
+		t.Fatalf("unexpected summary: open %d, buckets %v", report.OpenAtEnd, report.HoldTimes.Buckets)
+	}
+}
+
+// TestRun_Deterministic проверяет совпадение результатов повторных прогонов
+func TestRun_Deterministic(t *testing.T) {
+	dir := t.TempDir()
+	writeRecords(t, dir, spreadDay(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)))
+
+	cfg := DefaultConfig(dir)
+	cfg.Venues = []string{"bybit", "okx"}
+	cfg.Pairs = []*models.PairConfig{{
+		ID: 1, Symbol: "BTCUSDT", EntrySpreadPct: 0.3, ExitSpreadPct: 0.15,
+		VolumeAsset: 1, NOrders: 1, Status: models.PairStatusActive,
+	}}
+
+	first, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	second, err := Run(context.Background(), cfg)
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if first.NetPnl != second.NetPnl || first.TotalTrades != second.TotalTrades || first.MaxDrawdown != second.MaxDrawdown {
+		t.Fatalf("runs differ: %+v vs %+v", first, second)
+	}
+}
+
+// TestRun_RejectsMakerTaker проверяет, что пара maker_taker не подменяется тейкером молча
+func TestRun_RejectsMakerTaker(t *testing.T) {
+	dir := t.TempDir()
+	writeRecords(t, dir, spreadDay(time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)))
+
+	cfg := DefaultConfig(dir)
+	cfg.Venues = []string{"bybit", "okx"}
+	cfg.Pairs = []*models.PairConfig{{
+		ID: 1, Symbol: "BTCUSDT", EntrySpreadPct: 0.3, ExitSpreadPct: 0.15,
+		VolumeAsset: 1, NOrders: 1, EntryMode: models.EntryModeMakerTaker, Status: models.PairStatusActive,
+	}}
+
+	if _, err := Run(context.Background(), cfg); err == nil || !strings.Contains(err.Error(), models.EntryModeMakerTaker) {
+		t.Fatalf("expected maker_taker pair rejected, got %v", err)
+	}
+}
+
+// TestRejectionKey проверяет агрегацию причин отказа
+func TestRejectionKey(t *testing.T) {
+	cases := map[string]string{
+		"spread 0.1200% < entry threshold 0.3000%":          "spread below entry threshold",
+		"insufficient liquidity: no orderbook data for okx": "insufficient liquidity",
+		"insufficient margin on bybit: need 10.00 USDT":     "insufficient margin",
+		"order validation failed: qty below min":            "order validation failed",
+		"max concurrent arbitrages reached":                 "max concurrent arbitrages reached",
+	}
+	for reason, expected := range cases {
+		if got := RejectionKey(reason); got != expected {
+			t.Errorf("RejectionKey(%q) = %q, want %q", reason, got, expected)
+		}
+	}
+}
@@ -0,0 +1,474 @@
+package backtest
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"arbitrage/internal/bot"
+	"arbitrage/internal/exchange"
+	"arbitrage/internal/models"
+)
+
+// Trade - одна арбитражная сделка (вход + выход)
+type Trade struct {
+	PairID        int           `json:"pair_id"`
+	Symbol        string        `json:"symbol"`
+	LongExchange  string        `json:"long_exchange"`
+	ShortExchange string        `json:"short_exchange"`
+	Quantity      float64       `json:"quantity"`
+	OpenedAt      time.Time     `json:"opened_at"`
+	ClosedAt      time.Time     `json:"closed_at,omitempty"`
+	HoldTime      time.Duration `json:"hold_time"`
+
+	// Цены исполнения
+	LongEntry  float64 `json:"long_entry"`
+	ShortEntry float64 `json:"short_entry"`
+	LongExit   float64 `json:"long_exit,omitempty"`
+	ShortExit  float64 `json:"short_exit,omitempty"`
+
+	// Оценка OrderBookAnalyzer на момент решения (VWAP)
+	EstLongEntry  float64 `json:"est_long_entry"`
+	EstShortEntry float64 `json:"est_short_entry"`
+
+	// Slippage входа относительно оценки, % (положительный = хуже оценки)
+	EntrySlippagePct float64 `json:"entry_slippage_pct"`
+
+	GrossPnl   float64 `json:"gross_pnl"`
+	Fees       float64 `json:"fees"`
+	NetPnl     float64 `json:"net_pnl"`
+	ExitReason string  `json:"exit_reason,omitempty"`
+	Closed     bool    `json:"closed"`
+}
+
+// PairReport - итоги по паре
+type PairReport struct {
+	PairID   int     `json:"pair_id"`
+	Symbol   string  `json:"symbol"`
+	Trades   []Trade `json:"trades"`
+	GrossPnl float64 `json:"gross_pnl"`
+	Fees     float64 `json:"fees"`
+	NetPnl   float64 `json:"net_pnl"`
+	Wins     int     `json:"wins"`
+	Losses   int     `json:"losses"`
+}
+
+// HoldTimeStats - распределение времени удержания закрытых сделок
+type HoldTimeStats struct {
+	Count   int            `json:"count"`
+	Min     time.Duration  `json:"min"`
+	P50     time.Duration  `json:"p50"`
+	P90     time.Duration  `json:"p90"`
+	Max     time.Duration  `json:"max"`
+	Mean    time.Duration  `json:"mean"`
+	Buckets map[string]int `json:"buckets"`
+}
+
+// Report - итоговый отчёт backtest
+type Report struct {
+	Start  time.Time `json:"start"`
+	End    time.Time `json:"end"`
+	Events int64     `json:"events"`
+
+	Pairs []PairReport `json:"pairs"`
+
+	TotalTrades int     `json:"total_trades"`
+	GrossPnl    float64 `json:"gross_pnl"`
+	Fees        float64 `json:"fees"`
+	NetPnl      float64 `json:"net_pnl"`
+
+	AvgEntrySlippagePct float64 `json:"avg_entry_slippage_pct"`
+
+	StartEquity    float64 `json:"start_equity"`
+	EndEquity      float64 `json:"end_equity"`
+	MaxDrawdown    float64 `json:"max_drawdown"`     // USDT от пика equity
+	MaxDrawdownPct float64 `json:"max_drawdown_pct"` // % от пика equity
+
+	HoldTimes HoldTimeStats `json:"hold_times"`
+
+	// Отказы во входе по причинам (спред прошёл быструю проверку, полная проверка отказала)
+	Rejections map[string]int `json:"rejections"`
+
+	// Сделки, не закрытые к концу данных (не входят в PNL)
+	OpenTrades []Trade `json:"open_trades"`
+
+	// Позиции, оставшиеся открытыми на биржах в конце данных
+	OpenAtEnd int `json:"open_at_end"`
+}
+
+// holdBuckets - границы гистограммы времени удержания
+var holdBuckets = []struct {
+	label string
+	max   time.Duration
+}{
+	{"<1m", time.Minute},
+	{"1m-5m", 5 * time.Minute},
+	{"5m-15m", 15 * time.Minute},
+	{"15m-1h", time.Hour},
+	{"1h-4h", 4 * time.Hour},
+	{">4h", math.MaxInt64},
+}
+
+// collector реализует bot.TradeObserver и накапливает данные для отчёта
+type collector struct {
+	now  func() time.Time
+	fees map[string]float64
+
+	mu         sync.Mutex
+	pairs      map[int]*models.PairConfig
+	decisions  map[int]bot.ArbitrageOpportunity
+	open       map[int]*Trade
+	trades     map[int][]Trade
+	rejections map[string]int
+
+	start, end  time.Time
+	startEquity float64
+	endEquity   float64
+	peakEquity  float64
+	maxDD       float64
+	maxDDPct    float64
+	sampled     bool
+}
+
+func newCollector(now func() time.Time, fees map[string]float64, pairs []*models.PairConfig) *collector {
+	c := &collector{
+		now:        now,
+		fees:       fees,
+		pairs:      make(map[int]*models.PairConfig, len(pairs)),
+		decisions:  make(map[int]bot.ArbitrageOpportunity),
+		open:       make(map[int]*Trade),
+		trades:     make(map[int][]Trade),
+		rejections: make(map[string]int),
+	}
+	for _, pair := range pairs {
+		c.pairs[pair.ID] = pair
+	}
+	return c
+}
+
+// ============ bot.TradeObserver ============
+
+func (c *collector) OnEntryRejected(pairID int, reason string) {
+	c.mu.Lock()
+	c.rejections[RejectionKey(reason)]++
+	c.mu.Unlock()
+}
+
+func (c *collector) OnEntryDecision(pairID int, opp bot.ArbitrageOpportunity, volume float64) {
+	c.mu.Lock()
+	c.decisions[pairID] = opp
+	c.mu.Unlock()
+}
+
+func (c *collector) OnTradeOpened(pairID int, result *bot.ExecuteResult) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	trade := &Trade{
+		PairID:   pairID,
+		OpenedAt: c.now(),
+	}
+	if pair, ok := c.pairs[pairID]; ok {
+		trade.Symbol = pair.Symbol
+	}
+
+	for _, leg := range result.Legs {
+		trade.Quantity = leg.Quantity
+		if leg.Side == exchange.SideLong {
+			trade.LongExchange = leg.Exchange
+			trade.LongEntry = leg.EntryPrice
+		} else {
+			trade.ShortExchange = leg.Exchange
+			trade.ShortEntry = leg.EntryPrice
+		}
+	}
+
+	if opp, ok := c.decisions[pairID]; ok {
+		trade.EstLongEntry = opp.LongPrice
+		trade.EstShortEntry = opp.ShortPrice
+		trade.EntrySlippagePct = entrySlippagePct(trade)
+		delete(c.decisions, pairID)
+	}
+
+	c.open[pairID] = trade
+}
+
+func (c *collector) OnEntryFailed(pairID int, err error) {
+	c.mu.Lock()
+	delete(c.decisions, pairID)
+	if err != nil {
+		c.rejections["execution failed"]++
+	}
+	c.mu.Unlock()
+}
+
+func (c *collector) OnTradeClosed(pairID int, result *bot.ExecuteResult, reason bot.ExitReason) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	trade, ok := c.open[pairID]
+	if !ok {
+		return
+	}
+	delete(c.open, pairID)
+
+	trade.ClosedAt = c.now()
+	trade.HoldTime = trade.ClosedAt.Sub(trade.OpenedAt)
+	trade.ExitReason = string(reason)
+	trade.Closed = true
+	trade.GrossPnl = result.TotalPnl
+
+	// Закрытие лонга - продажа на бирже лонга, шорта - покупка на бирже шорта
+	for _, order := range []*exchange.Order{result.LongOrder, result.ShortOrder} {
+		if order == nil {
+			continue
+		}
+		if order.Side == exchange.SideSell {
+			trade.LongExit = order.AvgFillPrice
+		} else {
+			trade.ShortExit = order.AvgFillPrice
+		}
+	}
+
+	trade.Fees = c.tradeFees(trade)
+	trade.NetPnl = trade.GrossPnl - trade.Fees
+
+	c.trades[pairID] = append(c.trades[pairID], *trade)
+}
+
+// sampleEquity добавляет точку equity для расчёта просадки
+func (c *collector) sampleEquity(equity float64) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.now()
+	if !c.sampled {
+		c.sampled = true
+		c.start = now
+		c.startEquity = equity
+		c.peakEquity = equity
+	}
+	c.end = now
+	c.endEquity = equity
+
+	if equity > c.peakEquity {
+		c.peakEquity = equity
+	}
+	if dd := c.peakEquity - equity; dd > c.maxDD {
+		c.maxDD = dd
+		if c.peakEquity > 0 {
+			c.maxDDPct = dd / c.peakEquity * 100
+		}
+	}
+}
+
+// tradeFees - комиссии тейкера за 4 исполнения сделки
+func (c *collector) tradeFees(trade *Trade) float64 {
+	longFee := c.fees[trade.LongExchange]
+	shortFee := c.fees[trade.ShortExchange]
+
+	return trade.Quantity * (longFee*(trade.LongEntry+trade.LongExit) +
+		shortFee*(trade.ShortEntry+trade.ShortExit))
+}
+
+// report строит итоговый отчёт
+func (c *collector) report(events int64, openAtEnd int) *Report {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	report := &Report{
+		Start:          c.start,
+		End:            c.end,
+		Events:         events,
+		StartEquity:    c.startEquity,
+		EndEquity:      c.endEquity,
+		MaxDrawdown:    c.maxDD,
+		MaxDrawdownPct: c.maxDDPct,
+		Rejections:     make(map[string]int, len(c.rejections)),
+		OpenAtEnd:      openAtEnd,
+	}
+	for reason, count := range c.rejections {
+		report.Rejections[reason] = count
+	}
+
+	pairIDs := make([]int, 0, len(c.pairs))
+	for id := range c.pairs {
+		pairIDs = append(pairIDs, id)
+	}
+	sort.Ints(pairIDs)
+
+	var holdTimes []time.Duration
+	var slippageSum float64
+
+	for _, id := range pairIDs {
+		pr := PairReport{
+			PairID: id,
+			Symbol: c.pairs[id].Symbol,
+			Trades: c.trades[id],
+		}
+		for _, trade := range pr.Trades {
+			pr.GrossPnl += trade.GrossPnl
+			pr.Fees += trade.Fees
+			pr.NetPnl += trade.NetPnl
+			if trade.NetPnl > 0 {
+				pr.Wins++
+			} else {
+				pr.Losses++
+			}
+			holdTimes = append(holdTimes, trade.HoldTime)
+			slippageSum += trade.EntrySlippagePct
+		}
+
+		report.Pairs = append(report.Pairs, pr)
+		report.TotalTrades += len(pr.Trades)
+		report.GrossPnl += pr.GrossPnl
+		report.Fees += pr.Fees
+		report.NetPnl += pr.NetPnl
+	}
+
+	for _, id := range pairIDs {
+		if trade, ok := c.open[id]; ok {
+			report.OpenTrades = append(report.OpenTrades, *trade)
+		}
+	}
+
+	if report.TotalTrades > 0 {
+		report.AvgEntrySlippagePct = slippageSum / float64(report.TotalTrades)
+	}
+	report.HoldTimes = holdTimeStats(holdTimes)
+
+	return report
+}
+
+// entrySlippagePct - slippage входа относительно оценки анализатора, %
+// Лонг: дороже оценки = хуже, шорт: дешевле оценки = хуже
+func entrySlippagePct(trade *Trade) float64 {
+	var slippage float64
+	if trade.EstLongEntry > 0 {
+		slippage += (trade.LongEntry - trade.EstLongEntry) / trade.EstLongEntry * 100
+	}
+	if trade.EstShortEntry > 0 {
+		slippage += (trade.EstShortEntry - trade.ShortEntry) / trade.EstShortEntry * 100
+	}
+	return slippage
+}
+
+func holdTimeStats(holdTimes []time.Duration) HoldTimeStats {
+	stats := HoldTimeStats{
+		Count:   len(holdTimes),
+		Buckets: make(map[string]int, len(holdBuckets)),
+	}
+	for _, bucket := range holdBuckets {
+		stats.Buckets[bucket.label] = 0
+	}
+	if len(holdTimes) == 0 {
+		return stats
+	}
+
+	sorted := append([]time.Duration(nil), holdTimes...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	var total time.Duration
+	for _, d := range sorted {
+		total += d
+		for _, bucket := range holdBuckets {
+			if d < bucket.max {
+				stats.Buckets[bucket.label]++
+				break
+			}
+		}
+	}
+
+	stats.Min = sorted[0]
+	stats.Max = sorted[len(sorted)-1]
+	stats.P50 = percentile(sorted, 0.5)
+	stats.P90 = percentile(sorted, 0.9)
+	stats.Mean = total / time.Duration(len(sorted))
+	return stats
+}
+
+// percentile - nearest-rank перцентиль отсортированного слайса
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	idx := int(math.Ceil(p*float64(len(sorted)))) - 1
+	if idx < 0 {
+		idx = 0
+	}
+	return sorted[idx]
+}
+
+// RejectionKey сводит текст причины отказа ArbitrageDetector к категории
+// (без чисел и имён бирж), чтобы отказы можно было агрегировать
+func RejectionKey(reason string) string {
+	switch {
+	case strings.HasPrefix(reason, "spread "):
+		return "spread below entry threshold"
+	case strings.HasPrefix(reason, "insufficient margin"),
+		strings.HasPrefix(reason, "margin data unavailable"):
+		return "insufficient margin"
+	case strings.HasPrefix(reason, "failed to fetch margin"):
+		return "margin fetch failed"
+	case strings.HasPrefix(reason, "pair state blocks entry"),
+		strings.Contains(reason, "already has an open or pending position"):
+		return "pair not ready"
+	}
+
+	if idx := strings.Index(reason, ":"); idx > 0 {
+		return reason[:idx]
+	}
+	return reason
+}
+
+// WriteText печатает отчёт в человекочитаемом виде
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Backtest %s - %s (%s), %d events\n",
+		r.Start.UTC().Format(time.RFC3339), r.End.UTC().Format(time.RFC3339),
+		r.End.Sub(r.Start).Round(time.Second), r.Events)
+	fmt.Fprintln(w)
+
+	for _, pr := range r.Pairs {
+		fmt.Fprintf(w, "Pair %d %s: %d trades (%d win / %d loss), gross %.4f, fees %.4f, net %.4f USDT\n",
+			pr.PairID, pr.Symbol, len(pr.Trades), pr.Wins, pr.Losses, pr.GrossPnl, pr.Fees, pr.NetPnl)
+		for _, t := range pr.Trades {
+			fmt.Fprintf(w, "  %s  L %s %.4f→%.4f  S %s %.4f→%.4f  qty %.4f  hold %s  slip %.4f%%  net %.4f  (%s)\n",
+				t.OpenedAt.UTC().Format("2006-01-02 15:04:05.000"),
+				t.LongExchange, t.LongEntry, t.LongExit,
+				t.ShortExchange, t.ShortEntry, t.ShortExit,
+				t.Quantity, t.HoldTime.Round(time.Millisecond), t.EntrySlippagePct, t.NetPnl, t.ExitReason)
+		}
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "Total: %d trades, gross %.4f, fees %.4f, net %.4f USDT\n",
+		r.TotalTrades, r.GrossPnl, r.Fees, r.NetPnl)
+	fmt.Fprintf(w, "Equity: %.2f → %.2f, max drawdown %.4f USDT (%.4f%%)\n",
+		r.StartEquity, r.EndEquity, r.MaxDrawdown, r.MaxDrawdownPct)
+	fmt.Fprintf(w, "Avg entry slippage vs analyzer: %.4f%%\n", r.AvgEntrySlippagePct)
+	fmt.Fprintf(w, "Open at end: %d trades, %d exchange positions\n", len(r.OpenTrades), r.OpenAtEnd)
+	fmt.Fprintln(w)
+
+	fmt.Fprintf(w, "Hold time: n=%d min %s p50 %s p90 %s max %s mean %s\n",
+		r.HoldTimes.Count, r.HoldTimes.Min, r.HoldTimes.P50, r.HoldTimes.P90, r.HoldTimes.Max, r.HoldTimes.Mean)
+	for _, bucket := range holdBuckets {
+		fmt.Fprintf(w, "  %-7s %d\n", bucket.label, r.HoldTimes.Buckets[bucket.label])
+	}
+	fmt.Fprintln(w)
+
+	fmt.Fprintln(w, "Rejected opportunities:")
+	reasons := make([]string, 0, len(r.Rejections))
+	for reason := range r.Rejections {
+		reasons = append(reasons, reason)
+	}
+	sort.Slice(reasons, func(i, j int) bool {
+		if r.Rejections[reasons[i]] != r.Rejections[reasons[j]] {
+			return r.Rejections[reasons[i]] > r.Rejections[reasons[j]]
+		}
+		return reasons[i] < reasons[j]
+	})
+	for _, reason := range reasons {
+		fmt.Fprintf(w, "  %-40s %d\n", reason, r.Rejections[reason])
+	}
+}
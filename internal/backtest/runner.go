@@ -0,0 +1,239 @@
+// Package backtest прогоняет bot.Engine по записанным рыночным данным
+//
+// Поток данных:
+// marketdata.Reader → exchange.ReplayFeed → ReplayExchange (стакан + исполнение Sim) →
+// Engine.OnOrderBookUpdate / ProcessPriceUpdate → OrderExecutor → ReplayExchange
+//
+// Движок работает в пошаговом режиме: после каждой записи раннер ждёт
+// завершения входов/выходов, поэтому прогон детерминирован.
+package backtest
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"arbitrage/internal/bot"
+	"arbitrage/internal/config"
+	"arbitrage/internal/exchange"
+	"arbitrage/internal/marketdata"
+	"arbitrage/internal/models"
+)
+
+// Config - параметры прогона
+type Config struct {
+	DataDir string               // каталог с записью (bot.Recorder)
+	Pairs   []*models.PairConfig // торговые пары (ID должны быть уникальны)
+	Bot     config.BotConfig     // параметры движка (OrderTimeout, MaxConcurrentArbs)
+
+	Venues         []string // биржи прогона (пусто = все поддерживаемые)
+	InitialBalance float64  // стартовый баланс на каждой бирже
+	Leverage       int      // плечо симулятора
+
+	// ExitCheckInterval - период проверки условий выхода по виртуальному времени
+	// (в живом движке exitConditionChecker срабатывает каждые 500ms)
+	ExitCheckInterval time.Duration
+
+	// StepTimeout - максимальное ожидание завершения входа/выхода на одном шаге
+	StepTimeout time.Duration
+}
+
+// DefaultConfig возвращает конфигурацию по умолчанию
+func DefaultConfig(dataDir string) Config {
+	return Config{
+		DataDir: dataDir,
+		Bot: config.BotConfig{
+			OrderTimeout: 5 * time.Second,
+		},
+		InitialBalance:    10000,
+		Leverage:          10,
+		ExitCheckInterval: 500 * time.Millisecond,
+		StepTimeout:       10 * time.Second,
+	}
+}
+
+// replayVenue - адаптер replay без push-тикеров и стаканов
+// Цены подаёт раннер синхронно через Engine.ProcessPriceUpdate, иначе тикеры
+// ушли бы в шарды движка, которые в пошаговом режиме никто не читает.
+// Стаканы раннер тоже передаёт сам через Engine.OnOrderBookUpdate.
+type replayVenue struct {
+	*exchange.ReplayExchange
+}
+
+func (v replayVenue) SubscribeTicker(symbol string, callback func(*exchange.Ticker)) error {
+	return nil
+}
+
+func (v replayVenue) SubscribeOrderBook(symbol string, depth int, callback func(*exchange.OrderBook)) error {
+	return nil
+}
+
+// runner - состояние одного прогона
+type runner struct {
+	cfg       Config
+	engine    *bot.Engine
+	feed      *exchange.ReplayFeed
+	venues    map[string]*exchange.ReplayExchange
+	collector *collector
+
+	lastExitCheck time.Time
+	err           error
+}
+
+// Run выполняет прогон и возвращает отчёт
+func Run(ctx context.Context, cfg Config) (*Report, error) {
+	if len(cfg.Pairs) == 0 {
+		return nil, fmt.Errorf("backtest: no pairs configured")
+	}
+	for _, pair := range cfg.Pairs {
+		// Пошаговый replay ждёт завершения входа на каждом событии, поэтому
+		// пассивная котировка maker_taker не может дождаться исполнения
+		if pair.IsMakerTaker() {
+			return nil, fmt.Errorf("backtest: pair %d: entry mode %s is not supported, use %s",
+				pair.ID, pair.EntryMode, models.EntryModeTaker)
+		}
+	}
+	if len(cfg.Venues) == 0 {
+		cfg.Venues = exchange.SupportedExchanges()
+	}
+	if cfg.ExitCheckInterval <= 0 {
+		cfg.ExitCheckInterval = 500 * time.Millisecond
+	}
+	if cfg.StepTimeout <= 0 {
+		cfg.StepTimeout = 10 * time.Second
+	}
+	if cfg.Bot.OrderTimeout <= 0 {
+		cfg.Bot.OrderTimeout = 5 * time.Second
+	}
+
+	reader, err := marketdata.NewReader(cfg.DataDir)
+	if err != nil {
+		return nil, err
+	}
+	defer reader.Close()
+
+	r := &runner{
+		cfg:    cfg,
+		venues: make(map[string]*exchange.ReplayExchange),
+	}
+
+	r.feed = exchange.NewReplayFeed(reader, exchange.ReplayConfig{
+		SimConfig: func(venue string) exchange.SimConfig {
+			simCfg := exchange.DefaultSimConfig(venue)
+			if cfg.InitialBalance > 0 {
+				simCfg.InitialBalance = cfg.InitialBalance
+			}
+			if cfg.Leverage > 0 {
+				simCfg.Leverage = cfg.Leverage
+			}
+			return simCfg
+		},
+		AfterEvent: func(rec *marketdata.Record) {
+			r.step(ctx, rec)
+		},
+	})
+
+	r.engine = bot.NewEngine(&config.Config{Bot: cfg.Bot}, nil)
+	r.engine.SetClock(r.feed.Clock().Now)
+
+	fees := make(map[string]float64, len(cfg.Venues))
+	for _, venue := range cfg.Venues {
+		exch := r.feed.Exchange(venue)
+		r.venues[venue] = exch
+
+		venueFees, err := exch.GetTradingFees(ctx, "")
+		if err != nil {
+			return nil, err
+		}
+		fees[venue] = venueFees.Taker
+		r.engine.SetFee(venue, venueFees.Taker)
+		r.engine.SetMakerFee(venue, venueFees.Maker)
+		r.engine.AddExchange(venue, replayVenue{exch})
+	}
+
+	r.collector = newCollector(r.feed.Clock().Now, fees, cfg.Pairs)
+	r.engine.SetTradeObserver(r.collector)
+
+	for _, pair := range cfg.Pairs {
+		pairCopy := *pair
+		r.engine.AddPair(&pairCopy)
+		if err := r.engine.StartPair(pairCopy.ID); err != nil {
+			return nil, fmt.Errorf("backtest: start pair %d: %w", pairCopy.ID, err)
+		}
+	}
+
+	if err := r.feed.Run(ctx); err != nil {
+		return nil, err
+	}
+	if r.err != nil {
+		return nil, r.err
+	}
+
+	return r.collector.report(r.feed.Events(), r.openPositions()), nil
+}
+
+// step обрабатывает одну запись: стакан → цена → выходы → ликвидации
+func (r *runner) step(ctx context.Context, rec *marketdata.Record) {
+	if r.err != nil {
+		return
+	}
+
+	exch, ok := r.venues[rec.Exchange]
+	if !ok {
+		return
+	}
+
+	book, err := exch.GetOrderBook(ctx, rec.Symbol, 0)
+	if err != nil {
+		return
+	}
+	r.engine.OnOrderBookUpdate(rec.Exchange, rec.Symbol, book.Bids, book.Asks)
+
+	ticker, err := exch.GetTicker(ctx, rec.Symbol)
+	if err != nil {
+		return
+	}
+	r.engine.ProcessPriceUpdate(rec.Exchange, rec.Symbol, ticker.BidPrice, ticker.AskPrice, ticker.Timestamp)
+	r.waitIdle(ctx)
+
+	now := r.feed.Clock().Now()
+	if now.Sub(r.lastExitCheck) >= r.cfg.ExitCheckInterval {
+		r.lastExitCheck = now
+		r.engine.CheckExits()
+		r.waitIdle(ctx)
+	}
+
+	r.engine.ProcessPositionUpdates()
+	r.waitIdle(ctx)
+
+	r.collector.sampleEquity(r.equity())
+}
+
+func (r *runner) waitIdle(ctx context.Context) {
+	waitCtx, cancel := context.WithTimeout(ctx, r.cfg.StepTimeout)
+	defer cancel()
+
+	if err := r.engine.WaitIdle(waitCtx); err != nil && r.err == nil {
+		r.err = fmt.Errorf("backtest: engine did not settle at %s: %w", r.feed.Clock().Now().UTC(), err)
+	}
+}
+
+// equity - суммарный equity всех бирж (баланс + нереализованный PNL)
+func (r *runner) equity() float64 {
+	var total float64
+	for _, exch := range r.venues {
+		balance, _ := exch.GetBalance(context.Background())
+		total += balance
+	}
+	return total
+}
+
+// openPositions - количество позиций, оставшихся открытыми в конце данных
+func (r *runner) openPositions() int {
+	var count int
+	for _, exch := range r.venues {
+		positions, _ := exch.GetOpenPositions(context.Background())
+		count += len(positions)
+	}
+	return count
+}
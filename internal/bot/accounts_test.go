@@ -0,0 +1,59 @@
+package bot
+
+import (
+	"testing"
+	"time"
+
+	"arbitrage/internal/exchange"
+	"arbitrage/internal/models"
+)
+
+// TestDetectOpportunity_PairAccounts проверяет, что ноги входа выбираются
+// только на аккаунтах, к которым привязана пара
+func TestDetectOpportunity_PairAccounts(t *testing.T) {
+	pt := NewPriceTracker(1)
+	sc := NewSpreadCalculator(pt)
+	ad := NewArbitrageDetector(pt, sc, nil, nil)
+
+	now := time.Now()
+	pt.Update(PriceUpdate{Exchange: "acc-long", Symbol: "BTCUSDT", BidPrice: 99, AskPrice: 100, Timestamp: now})
+	pt.Update(PriceUpdate{Exchange: "arb1@acc-short", Symbol: "BTCUSDT", BidPrice: 103, AskPrice: 104, Timestamp: now})
+	pt.Update(PriceUpdate{Exchange: "arb2@acc-short", Symbol: "BTCUSDT", BidPrice: 105, AskPrice: 106, Timestamp: now})
+
+	opp := ad.DetectOpportunity("BTCUSDT", nil)
+	if opp == nil || opp.ShortExchange != "arb2@acc-short" {
+		t.Fatalf("expected short on arb2@acc-short without binding, got %+v", opp)
+	}
+	ReleaseArbitrageOpportunity(opp)
+
+	opp = ad.DetectOpportunity("BTCUSDT", []string{"acc-long", "arb1@acc-short"})
+	if opp == nil {
+		t.Fatal("expected opportunity on bound accounts")
+	}
+	defer ReleaseArbitrageOpportunity(opp)
+	if opp.LongExchange != "acc-long" || opp.ShortExchange != "arb1@acc-short" || opp.ShortPrice != 103 {
+		t.Fatalf("expected acc-long/arb1@acc-short at 103, got %s/%s at %.0f",
+			opp.LongExchange, opp.ShortExchange, opp.ShortPrice)
+	}
+}
+
+// TestMatchPositionsToPairs_PairAccounts проверяет, что восстановление
+// связывает с парой только позиции на её аккаунтах
+func TestMatchPositionsToPairs_PairAccounts(t *testing.T) {
+	rm := &RecoveryManager{}
+	foreign := &DiscoveredPosition{Exchange: "bybit", Symbol: "BTCUSDT", Side: exchange.SideLong, Size: 1}
+	long := &DiscoveredPosition{Exchange: "arb1@bybit", Symbol: "BTCUSDT", Side: exchange.SideLong, Size: 1}
+	short := &DiscoveredPosition{Exchange: "okx", Symbol: "BTCUSDT", Side: exchange.SideShort, Size: 1}
+	pair := &models.PairConfig{ID: 1, Symbol: "BTCUSDT", Accounts: []string{"arb1@bybit", "okx"}}
+
+	matched, orphaned := rm.matchPositionsToPairs([]*DiscoveredPosition{foreign, long, short}, []*models.PairConfig{pair})
+	if len(matched) != 1 || !matched[0].IsComplete {
+		t.Fatalf("expected one complete match, got %+v", matched)
+	}
+	if matched[0].LongLeg != long || matched[0].ShortLeg != short {
+		t.Fatalf("expected legs on arb1@bybit/okx, got %s/%s", matched[0].LongLeg.Exchange, matched[0].ShortLeg.Exchange)
+	}
+	if len(orphaned) != 1 || orphaned[0] != foreign {
+		t.Fatalf("expected position on main bybit account orphaned, got %+v", orphaned)
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"sync/atomic"
 	"time"
 
+	"arbitrage/internal/exchange"
 	"arbitrage/internal/models"
 )
 
@@ -109,8 +110,19 @@ func NewArbitrageDetector(
 // - nil если нет подходящей возможности
 //
 // Сложность: O(1) благодаря предвычисленным данным в PriceTracker
-func (ad *ArbitrageDetector) DetectOpportunity(symbol string) *ArbitrageOpportunity {
-	opp := ad.spreadCalc.GetBestOpportunity(symbol)
+// accounts - аккаунты пары для ног (nil - все аккаунты)
+func (ad *ArbitrageDetector) DetectOpportunity(symbol string, accounts []string) *ArbitrageOpportunity {
+	opp := ad.spreadCalc.GetBestOpportunity(symbol, accounts)
+	if opp != nil {
+		atomic.AddInt64(&ad.opportunitiesDetected, 1)
+	}
+	return opp
+}
+
+// DetectMakerOpportunity находит возможность для входа maker_taker
+// Спред считается от пассивной цены на менее ликвидной бирже (см. GetMakerOpportunity)
+func (ad *ArbitrageDetector) DetectMakerOpportunity(symbol string, accounts []string) *ArbitrageOpportunity {
+	opp := ad.spreadCalc.GetMakerOpportunity(symbol, accounts)
 	if opp != nil {
 		atomic.AddInt64(&ad.opportunitiesDetected, 1)
 	}
@@ -123,10 +135,10 @@ func (ad *ArbitrageDetector) DetectOpportunity(symbol string) *ArbitrageOpportun
 // - Проверки достаточности ликвидности на обеих биржах
 // - Расчёта реального спреда с учётом slippage (VWAP)
 // - Оценки фактической прибыли
-func (ad *ArbitrageDetector) DetectWithLiquidity(symbol string, volume float64) *SpreadWithLiquidity {
+func (ad *ArbitrageDetector) DetectWithLiquidity(symbol string, accounts []string, volume float64) *SpreadWithLiquidity {
 	if ad.orderBookAnalyzer == nil {
 		// Без анализатора стаканов возвращаем базовую возможность
-		opp := ad.spreadCalc.GetBestOpportunity(symbol)
+		opp := ad.spreadCalc.GetBestOpportunity(symbol, accounts)
 		if opp == nil {
 			return nil
 		}
@@ -136,7 +148,7 @@ func (ad *ArbitrageDetector) DetectWithLiquidity(symbol string, volume float64)
 		}
 	}
 
-	return ad.spreadCalc.GetSpreadWithLiquidity(symbol, volume, ad.orderBookAnalyzer)
+	return ad.spreadCalc.GetSpreadWithLiquidity(symbol, accounts, volume, ad.orderBookAnalyzer)
 }
 
 // ============================================================
@@ -169,11 +181,13 @@ type EntryConditions struct {
 // CheckEntryConditions выполняет полную проверку условий для входа в арбитраж
 //
 // Согласно ТЗ проверяет:
-// 1. Спред >= entry_spread (с учётом комиссий)
+// 1. Спред >= entry_spread (с учётом комиссий и, при ConsiderFunding, ожидаемого фандинга)
+// 1a. Для стратегии funding вместо спреда: разница ставок фандинга >= funding_diff
 // 2. Достаточная ликвидность на обеих биржах
 // 3. Достаточная маржа для открытия позиций
 // 4. Соблюдение лимитов бирж (min/max qty, lot size)
 // 5. Лимит максимальных одновременных арбитражей
+// 6. Биржи ног не отключены по состоянию (exchange.HealthTripped)
 //
 // ОПТИМИЗАЦИЯ: использует sync.Pool для EntryConditions
 // Вызывающий код НЕ должен вызывать ReleaseEntryConditions если CanEnter=true
@@ -189,6 +203,7 @@ func (ad *ArbitrageDetector) CheckEntryConditions(
 	config := ps.Config
 	symbol := config.Symbol
 	volume := config.VolumeAsset
+	accounts := ps.GetAccounts()
 
 	// 0. Быстрая проверка статуса пары и открытых позиций
 	if config.Status != models.PairStatusActive {
@@ -220,9 +235,23 @@ func (ad *ArbitrageDetector) CheckEntryConditions(
 	var liquidityOK bool = true
 	var liquidityIssue string
 
-	if ad.orderBookAnalyzer != nil {
+	if ps.IsFundingStrategy() {
+		// Связка определяется ставками фандинга, а не лучшими ценами
+		opp = ad.DetectFundingOpportunity(symbol, accounts)
+		if opp == nil {
+			result.Reason = "no funding opportunity found"
+			return result
+		}
+	} else if ps.IsMakerTaker() {
+		// Пассивная нога не проходит по стакану, цену хеджа перепроверяет OrderExecutor
+		opp = ad.DetectMakerOpportunity(symbol, accounts)
+		if opp == nil {
+			result.Reason = "no maker-taker opportunity found"
+			return result
+		}
+	} else if ad.orderBookAnalyzer != nil {
 		// С анализом ликвидности (более точно)
-		spreadWithLiq := ad.DetectWithLiquidity(symbol, volume)
+		spreadWithLiq := ad.DetectWithLiquidity(symbol, accounts, volume)
 		if spreadWithLiq == nil {
 			result.Reason = "no arbitrage opportunity found"
 			return result
@@ -237,7 +266,7 @@ func (ad *ArbitrageDetector) CheckEntryConditions(
 		}
 	} else {
 		// Базовая проверка без стаканов
-		opp = ad.DetectOpportunity(symbol)
+		opp = ad.DetectOpportunity(symbol, accounts)
 		if opp == nil {
 			result.Reason = "no arbitrage opportunity found"
 			return result
@@ -246,6 +275,16 @@ func (ad *ArbitrageDetector) CheckEntryConditions(
 
 	result.Opportunity = opp
 
+	// 2a. Отключённая биржа не открывает новых позиций, даже если отключилась после выбора связки
+	for _, name := range [2]string{opp.LongExchange, opp.ShortExchange} {
+		if exchange.HealthOf(name).Tripped() {
+			result.Reason = fmt.Sprintf("exchange %s is tripped by health check", name)
+			ReleaseArbitrageOpportunity(opp) // Освобождаем opp
+			result.Opportunity = nil
+			return result
+		}
+	}
+
 	// 3. Проверка ликвидности
 	if !liquidityOK {
 		result.Reason = "insufficient liquidity: " + liquidityIssue
@@ -257,16 +296,44 @@ func (ad *ArbitrageDetector) CheckEntryConditions(
 
 	// 4. Проверка спреда
 	// ОПТИМИЗАЦИЯ: используем atomic read для lock-free доступа в горячем пути
+	// NetSpread уже включает ожидаемый фандинг за время удержания (Settings.ConsiderFunding)
 	entrySpread := ps.GetEntrySpread()
-	if opp.NetSpread < entrySpread {
-		result.Reason = fmt.Sprintf("spread %.4f%% < entry threshold %.4f%%",
-			opp.NetSpread, entrySpread)
+	if ps.IsFundingStrategy() {
+		if fundingDiff := ps.GetFundingDiff(); opp.FundingDiff < fundingDiff {
+			result.Reason = fmt.Sprintf("funding differential %.4f%% < entry threshold %.4f%%",
+				opp.FundingDiff, fundingDiff)
+			ReleaseArbitrageOpportunity(opp) // Освобождаем opp
+			result.Opportunity = nil
+			return result
+		}
+		// Начисления за время удержания должны окупить вход и выход
+		funding := ad.spreadCalc.FundingOverHoldPct(symbol, opp.LongExchange, opp.ShortExchange, ps.GetMaxHold())
+		if funding <= opp.RoundTripCost {
+			result.Reason = fmt.Sprintf("expected funding %.4f%% over hold <= round-trip cost %.4f%%",
+				funding, opp.RoundTripCost)
+			ReleaseArbitrageOpportunity(opp) // Освобождаем opp
+			result.Opportunity = nil
+			return result
+		}
+	} else if opp.NetSpread < entrySpread {
+		if opp.FundingPct != 0 {
+			result.Reason = fmt.Sprintf("spread %.4f%% (funding %+.4f%%) < entry threshold %.4f%%",
+				opp.NetSpread, opp.FundingPct, entrySpread)
+		} else {
+			result.Reason = fmt.Sprintf("spread %.4f%% < entry threshold %.4f%%",
+				opp.NetSpread, entrySpread)
+		}
 		ReleaseArbitrageOpportunity(opp) // Освобождаем opp
 		result.Opportunity = nil
 		return result
 	}
 	result.SpreadOK = true
 
+	if opp.FundingPct < 0 {
+		result.Warnings = append(result.Warnings,
+			fmt.Sprintf("adverse funding: %.4f%%", opp.FundingPct))
+	}
+
 	// 5. Проверка лимитов ордеров (если есть валидатор)
 	adjustedVolume := volume
 	if validator != nil {
@@ -294,7 +361,7 @@ func (ad *ArbitrageDetector) CheckEntryConditions(
 
 	// 6. Проверка маржи
 	marginOK, marginReason := ad.checkMarginRequirement(
-		opp.LongExchange, opp.ShortExchange, symbol, adjustedVolume, opp.LongPrice)
+		opp.LongExchange, opp.ShortExchange, symbol, adjustedVolume, opp.LongPrice, ps.GetLeverage())
 
 	if !marginOK {
 		result.Reason = marginReason
@@ -317,17 +384,19 @@ func (ad *ArbitrageDetector) CheckEntryConditions(
 func (ad *ArbitrageDetector) checkMarginRequirement(
 	longExch, shortExch, symbol string,
 	volume, price float64,
+	leverage int,
 ) (bool, string) {
-	// Примерный расчёт требуемой маржи
-	// Для фьючерсов с плечом 10x: margin = notional / 10
-	notional := volume * price
-	requiredMargin := notional / 10 * 2 // на обеих биржах
+	// Примерный расчёт требуемой маржи на каждой бирже: margin = notional / leverage
+	if leverage < 1 {
+		leverage = 1
+	}
+	requiredMargin := volume * price / float64(leverage)
 
 	for _, exch := range []string{longExch, shortExch} {
 		// Сначала проверяем кэш
 		if margin, ok := ad.marginCache.Load(exch); ok {
-			if margin.(float64) < requiredMargin/2 {
-				return false, fmt.Sprintf("insufficient margin on %s: need %.2f USDT", exch, requiredMargin/2)
+			if margin.(float64) < requiredMargin {
+				return false, fmt.Sprintf("insufficient margin on %s: need %.2f USDT", exch, requiredMargin)
 			}
 			continue
 		}
@@ -345,8 +414,8 @@ func (ad *ArbitrageDetector) checkMarginRequirement(
 		}
 
 		ad.marginCache.Store(exch, available)
-		if available < requiredMargin/2 {
-			return false, fmt.Sprintf("insufficient margin on %s: need %.2f USDT", exch, requiredMargin/2)
+		if available < requiredMargin {
+			return false, fmt.Sprintf("insufficient margin on %s: need %.2f USDT", exch, requiredMargin)
 		}
 	}
 
@@ -377,19 +446,22 @@ type ExitReason string
 
 const (
 	ExitReasonNone        ExitReason = ""
-	ExitReasonSpread      ExitReason = "spread_reached" // спред достиг порога выхода
-	ExitReasonStopLoss    ExitReason = "stop_loss"      // достигнут stop loss
-	ExitReasonLiquidation ExitReason = "liquidation"    // ликвидация позиции
-	ExitReasonManual      ExitReason = "manual"         // ручное закрытие
-	ExitReasonError       ExitReason = "error"          // ошибка
+	ExitReasonSpread      ExitReason = "spread_reached"  // спред достиг порога выхода
+	ExitReasonStopLoss    ExitReason = "stop_loss"       // достигнут stop loss
+	ExitReasonLiquidation ExitReason = "liquidation"     // ликвидация позиции
+	ExitReasonManual      ExitReason = "manual"          // ручное закрытие
+	ExitReasonFundingFlip ExitReason = "funding_flipped" // разница ставок фандинга развернулась (funding)
+	ExitReasonMaxHold     ExitReason = "max_hold_time"   // истекло максимальное время удержания
+	ExitReasonError       ExitReason = "error"           // ошибка
 )
 
 // CheckExitConditions проверяет условия для выхода из позиции
 //
 // Согласно ТЗ проверяет:
-// 1. Спред <= exit_spread
+// 1. Спред <= exit_spread (для funding - разворот разницы ставок)
 // 2. PNL <= -StopLoss
 // 3. Ликвидация одной из ног
+// 4. Время удержания >= max_hold_hours (если задано)
 func (ad *ArbitrageDetector) CheckExitConditions(ps *PairState) *ExitConditions {
 	result := &ExitConditions{
 		ShouldExit: false,
@@ -450,7 +522,25 @@ func (ad *ArbitrageDetector) CheckExitConditions(ps *PairState) *ExitConditions
 		return result
 	}
 
-	// 4. Проверяем достижение спреда выхода
+	// 4. Проверяем максимальное время удержания
+	if ad.holdExpired(ps) {
+		result.ShouldExit = true
+		result.Reason = ExitReasonMaxHold
+		atomic.AddInt64(&ad.exitsTriggered, 1)
+		return result
+	}
+
+	// 5. Funding держит позицию, пока шорт получает больше, чем платит лонг
+	if ps.IsFundingStrategy() {
+		if ad.checkFundingExit(config.Symbol, longLeg.Exchange, shortLeg.Exchange) {
+			result.ShouldExit = true
+			result.Reason = ExitReasonFundingFlip
+			atomic.AddInt64(&ad.exitsTriggered, 1)
+		}
+		return result
+	}
+
+	// 6. Проверяем достижение спреда выхода
 	// ОПТИМИЗАЦИЯ: используем atomic read для lock-free доступа
 	exitSpread := ps.GetExitSpread()
 	if currentSpread <= exitSpread {
@@ -481,14 +571,16 @@ type PartialEntryManager struct {
 
 // PartialEntryParams параметры частичного входа
 type PartialEntryParams struct {
+	PairID        int // ID пары для клиентских ID ордеров
 	Symbol        string
 	TotalVolume   float64
 	NOrders       int
 	LongExchange  string
 	ShortExchange string
-	EntrySpread   float64 // порог входа
-	ExitSpread    float64 // порог выхода (для немедленного стопа частями)
-	MinSpread     float64 // минимальный спред для продолжения (зона деградации)
+	Accounts      []string // аккаунты пары для ног (nil - все аккаунты)
+	EntrySpread   float64  // порог входа
+	ExitSpread    float64  // порог выхода (для немедленного стопа частями)
+	MinSpread     float64  // минимальный спред для продолжения (зона деградации)
 }
 
 // PartialEntryResult результат частичного входа
@@ -543,6 +635,7 @@ func (pem *PartialEntryManager) ExecutePartialEntry(
 		entrySpread = params.MinSpread
 	}
 	exitSpread := params.ExitSpread
+	entryAt := time.Now()
 
 	// Аккумуляторы для ног
 	var totalLongQty, totalShortQty float64
@@ -559,7 +652,7 @@ func (pem *PartialEntryManager) ExecutePartialEntry(
 		}
 
 		// Перед каждой частью пересчитываем спред и ликвидность (VWAP 5 уровней)
-		spreadWithLiq := pem.detector.DetectWithLiquidity(params.Symbol, partVolume)
+		spreadWithLiq := pem.detector.DetectWithLiquidity(params.Symbol, params.Accounts, partVolume)
 		if spreadWithLiq == nil || spreadWithLiq.ArbitrageOpportunity == nil {
 			result.Error = fmt.Errorf("no arbitrage opportunity before part %d/%d", i+1, params.NOrders)
 			return result
@@ -604,6 +697,9 @@ func (pem *PartialEntryManager) ExecutePartialEntry(
 			LongExchange:  params.LongExchange,
 			ShortExchange: params.ShortExchange,
 			NOrders:       1, // уже разбили
+			PairID:        params.PairID,
+			EntryAt:       entryAt,
+			Part:          i,
 		}
 
 		partResult := pem.orderExec.ExecuteParallel(ctx, execParams)
@@ -951,17 +1047,30 @@ func (ac *ArbitrageCoordinator) TryEnter(ctx context.Context, ps *PairState) (bo
 	config := ps.Config
 	opp := conditions.Opportunity
 
+	// Плечо и режим маржи сверяются при запуске пары (Engine.StartPair), здесь - только кэш
+	if !ac.orderExec.MarginSettingsApplied(config.Symbol, ps.GetMarginSettings(), opp.LongExchange, opp.ShortExchange) {
+		return false, nil, fmt.Errorf("margin settings for %s are not verified on %s/%s",
+			config.Symbol, opp.LongExchange, opp.ShortExchange)
+	}
+
 	// Выполняем вход
 	var result *ExecuteResult
 
-	if config.NOrders > 1 && ac.partialManager != nil {
+	if opp.MakerExchange != "" {
+		// Пассивная нога + рыночный хедж по исполнению
+		execParams := newMakerTakerParams(config.Symbol, conditions.AdjustedVolume, config.EntrySpreadPct, opp, ac.detector.spreadCalc)
+		execParams.PairID = config.ID
+		result = ac.orderExec.ExecuteMakerTaker(ctx, execParams)
+	} else if config.NOrders > 1 && ac.partialManager != nil && !ps.IsFundingStrategy() {
 		// Частичный вход
 		partialResult := ac.partialManager.ExecutePartialEntry(ctx, PartialEntryParams{
+			PairID:        config.ID,
 			Symbol:        config.Symbol,
 			TotalVolume:   conditions.AdjustedVolume,
 			NOrders:       config.NOrders,
 			LongExchange:  opp.LongExchange,
 			ShortExchange: opp.ShortExchange,
+			Accounts:      ps.GetAccounts(),
 			EntrySpread:   config.EntrySpreadPct,
 			ExitSpread:    config.ExitSpreadPct,
 			MinSpread:     config.EntrySpreadPct * 0.8, // 80% от entry spread
@@ -980,6 +1089,7 @@ func (ac *ArbitrageCoordinator) TryEnter(ctx context.Context, ps *PairState) (bo
 			LongExchange:  opp.LongExchange,
 			ShortExchange: opp.ShortExchange,
 			NOrders:       1,
+			PairID:        config.ID,
 		}
 
 		result = ac.orderExec.ExecuteParallel(ctx, execParams)
@@ -34,7 +34,7 @@ func newMockExchangeBench(name string, latency time.Duration) *mockExchangeBench
 
 func (m *mockExchangeBench) GetName() string { return m.name }
 
-func (m *mockExchangeBench) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64) (*exchange.Order, error) {
+func (m *mockExchangeBench) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*exchange.Order, error) {
 	if m.latency > 0 {
 		time.Sleep(m.latency)
 	}
@@ -50,6 +50,30 @@ func (m *mockExchangeBench) PlaceMarketOrder(ctx context.Context, symbol, side s
 	}, nil
 }
 
+func (m *mockExchangeBench) PlaceLimitOrder(ctx context.Context, symbol, side string, qty, price float64, tif string) (*exchange.Order, error) {
+	order, err := m.PlaceMarketOrder(ctx, symbol, side, qty, "")
+	if err != nil {
+		return nil, err
+	}
+	order.Type = "limit"
+	order.Price = price
+	order.AvgFillPrice = price
+	order.TimeInForce = tif
+	return order, nil
+}
+func (m *mockExchangeBench) CancelOrder(ctx context.Context, symbol, orderID string) error {
+	return nil
+}
+func (m *mockExchangeBench) GetOrder(ctx context.Context, symbol, orderID string) (*exchange.Order, error) {
+	return &exchange.Order{ID: orderID, Symbol: symbol, Status: "filled"}, nil
+}
+func (m *mockExchangeBench) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*exchange.Order, error) {
+	return nil, exchange.ErrOrderNotFound
+}
+func (m *mockExchangeBench) GetOpenOrders(ctx context.Context, symbol string) ([]*exchange.Order, error) {
+	return nil, nil
+}
+
 func (m *mockExchangeBench) Connect(apiKey, secretKey, passphrase string) error { return nil }
 func (m *mockExchangeBench) Close() error                                       { return nil }
 func (m *mockExchangeBench) GetBalance(ctx context.Context) (float64, error) {
@@ -70,15 +94,36 @@ func (m *mockExchangeBench) ClosePosition(ctx context.Context, symbol, side stri
 func (m *mockExchangeBench) GetLimits(ctx context.Context, symbol string) (*exchange.Limits, error) {
 	return &exchange.Limits{MinOrderQty: 0.001, MaxOrderQty: 100, QtyStep: 0.001}, nil
 }
+func (m *mockExchangeBench) GetInstrument(ctx context.Context, symbol string) (*exchange.Instrument, error) {
+	return &exchange.Instrument{Symbol: symbol, ContractValue: 1, LotSize: 0.001, MinSize: 0.001, MaxSize: 100}, nil
+}
+func (m *mockExchangeBench) GetFundingRate(ctx context.Context, symbol string) (*exchange.FundingRate, error) {
+	return &exchange.FundingRate{Symbol: symbol, Interval: exchange.DefaultFundingInterval}, nil
+}
+func (m *mockExchangeBench) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	return nil
+}
+func (m *mockExchangeBench) SetMarginMode(ctx context.Context, symbol, mode string) error {
+	return nil
+}
 func (m *mockExchangeBench) SubscribeTicker(symbol string, callback func(*exchange.Ticker)) error {
 	return nil
 }
+func (m *mockExchangeBench) SubscribeOrderBook(symbol string, depth int, callback func(*exchange.OrderBook)) error {
+	return nil
+}
 func (m *mockExchangeBench) SubscribePositions(callback func(*exchange.Position)) error {
 	return nil
 }
+func (m *mockExchangeBench) SubscribeOrders(callback func(*exchange.Order)) error {
+	return nil
+}
 func (m *mockExchangeBench) GetTradingFee(ctx context.Context, symbol string) (float64, error) {
 	return 0.0004, nil
 }
+func (m *mockExchangeBench) GetTradingFees(ctx context.Context, symbol string) (*exchange.TradingFees, error) {
+	return &exchange.TradingFees{Symbol: symbol, Maker: 0.0002, Taker: 0.0004}, nil
+}
 
 // ============ Вспомогательные функции для тестов ============
 
@@ -157,7 +202,7 @@ func BenchmarkArbitrageDetectorDetect(b *testing.B) {
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		opp := detector.DetectOpportunity("BTCUSDT")
+		opp := detector.DetectOpportunity("BTCUSDT", nil)
 		if opp != nil {
 			ReleaseArbitrageOpportunity(opp)
 		}
@@ -304,7 +349,7 @@ func BenchmarkFullHotPathComplete(b *testing.B) {
 		_ = tracker.GetBestPrices("BTCUSDT")
 
 		// 3. Обнаружение возможности
-		opp := detector.DetectOpportunity("BTCUSDT")
+		opp := detector.DetectOpportunity("BTCUSDT", nil)
 		if opp == nil {
 			continue
 		}
@@ -376,7 +421,7 @@ func BenchmarkFullHotPathConcurrentMulti(b *testing.B) {
 			_ = tracker.GetBestPrices(sym)
 
 			// Обнаружение возможности
-			opp := detector.DetectOpportunity(sym)
+			opp := detector.DetectOpportunity(sym, nil)
 			if opp != nil {
 				// Проверка условий
 				conditions := detector.CheckEntryConditions(ps, 0, 10, nil)
@@ -478,7 +523,7 @@ func BenchmarkSpreadCalculation(b *testing.B) {
 	b.ReportAllocs()
 
 	for i := 0; i < b.N; i++ {
-		opp := calc.GetBestOpportunity("BTCUSDT")
+		opp := calc.GetBestOpportunity("BTCUSDT", nil)
 		if opp != nil {
 			ReleaseArbitrageOpportunity(opp)
 		}
@@ -0,0 +1,159 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"arbitrage/internal/exchange"
+)
+
+// ============================================================
+// Клиентские ID ордеров (идемпотентные повторы)
+// ============================================================
+//
+// Рыночный ордер, ответ на который не получен (таймаут, обрыв соединения),
+// мог быть принят биржей. Повтор без проверки удваивает позицию, поэтому
+// каждый ордер входа получает детерминированный клиентский ID, а перед
+// повтором предыдущий ID запрашивается через GetOrderByClientID.
+//
+// Формат: a<пара>t<начало входа, мс>p<часть>n<попытка входа><нога>[r<повтор>][x]
+//   - нога: l - лонг, s - шорт, h<номер> - хедж maker_taker
+//   - r<повтор> - повтор второй ноги (retrySecondLeg)
+//   - x - откат ордера (или излишка ноги при неравном исполнении ног)
+//
+// Ордера закрытия с повторами (placeClose): c<время, мс>q<номер>r<повтор>
+//
+// Числа в hex, разделители вне алфавита hex - ID не пересекаются.
+// Только строчные латинские буквы и цифры (требование OKX), длина до 28 символов.
+
+const (
+	legLong  = "l"
+	legShort = "s"
+	legHedge = "h"
+)
+
+// orderRef возвращает общую часть клиентских ID ордеров одной попытки входа
+func orderRef(params ExecuteParams) string {
+	return "a" + strconv.FormatInt(int64(params.PairID), 16) +
+		"t" + strconv.FormatInt(params.EntryAt.UnixMilli(), 16) +
+		"p" + strconv.FormatInt(int64(params.Part), 16) +
+		"n" + strconv.FormatInt(int64(params.Attempt), 16)
+}
+
+// legClientID возвращает клиентский ID ордера ноги
+func legClientID(params ExecuteParams, leg string) string {
+	return orderRef(params) + leg
+}
+
+// hedgeClientID возвращает клиентский ID n-го хеджа maker_taker
+func hedgeClientID(params ExecuteParams, n int) string {
+	return legClientID(params, legHedge+strconv.FormatInt(int64(n), 16))
+}
+
+// retryClientID возвращает клиентский ID n-го повтора ордера
+func retryClientID(clientID string, n int) string {
+	if clientID == "" {
+		return ""
+	}
+	return clientID + "r" + strconv.FormatInt(int64(n), 16)
+}
+
+// isLegClientID проверяет, что clientID - ордер ноги legID или его повтор (r<номер>)
+func isLegClientID(clientID, legID string) bool {
+	if clientID == legID {
+		return true
+	}
+	retry, ok := strings.CutPrefix(clientID, legID+"r")
+	if !ok || retry == "" {
+		return false
+	}
+	_, err := strconv.ParseUint(retry, 16, 64)
+	return err == nil
+}
+
+// matchLegClientID возвращает клиентский ID ордера ноги legID или её повтора,
+// соответствующий clientID ордера биржи exch
+// Биржи с ClientIDMapper возвращают ID в своей форме (HTX - число), из которой
+// номер повтора не извлечь: сравниваются ID ноги и повторов до maxRetries
+func matchLegClientID(exch exchange.Exchange, clientID, legID string, maxRetries int) (string, bool) {
+	if _, ok := exch.(exchange.ClientIDMapper); !ok {
+		return clientID, isLegClientID(clientID, legID)
+	}
+	if clientID == "" {
+		return "", false
+	}
+	for n := 0; n <= maxRetries; n++ {
+		id := legID
+		if n > 0 {
+			id = retryClientID(legID, n)
+		}
+		if exchange.VenueClientOrderID(exch, id) == clientID {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// rollbackClientID возвращает клиентский ID отката ордера
+// Повторный откат того же ордера биржа отклонит как дубликат
+func rollbackClientID(order *exchange.Order) string {
+	if order == nil || order.ClientOrderID == "" {
+		return ""
+	}
+	return order.ClientOrderID + "x"
+}
+
+// closeSeq - номер ордера закрытия, различает закрытия одной миллисекунды
+var closeSeq atomic.Uint64
+
+// closeClientID возвращает клиентский ID ордера закрытия, повторы добавляют r<номер>
+func closeClientID() string {
+	return "c" + strconv.FormatInt(time.Now().UnixMilli(), 16) + "q" + strconv.FormatUint(closeSeq.Add(1), 16)
+}
+
+// findPlaced ищет ордер по клиентскому ID
+// Возвращает nil без ошибки, если биржа ордер не приняла или отклонила без исполнения
+func (oe *OrderExecutor) findPlaced(ctx context.Context, exch exchange.Exchange, symbol, clientOrderID string) (*exchange.Order, error) {
+	order, err := exch.GetOrderByClientID(ctx, symbol, clientOrderID)
+	if errors.Is(err, exchange.ErrOrderNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("lookup order %s on %s: %w", clientOrderID, exch.GetName(), err)
+	}
+	if order == nil {
+		return nil, nil
+	}
+	if order.FilledQty <= 0 && (order.Status == exchange.OrderStatusRejected || order.Status == exchange.OrderStatusCancelled) {
+		return nil, nil
+	}
+	return order, nil
+}
+
+// placeLeg размещает ордер ноги входа
+//
+// При повторе входа (Attempt > 0) сначала ищет ордера ноги предыдущих попыток:
+// принятый биржей ордер возвращается вместо нового. Если поиск не удался,
+// новый ордер не отправляется - ClientOrderID результата указывает на
+// ордер с неизвестным состоянием.
+func (oe *OrderExecutor) placeLeg(ctx context.Context, exch exchange.Exchange, params ExecuteParams, leg, side string, qty float64) LegResult {
+	for attempt := params.Attempt - 1; attempt >= 0; attempt-- {
+		prev := params
+		prev.Attempt = attempt
+		prevID := legClientID(prev, leg)
+
+		order, err := oe.findPlaced(ctx, exch, params.Symbol, prevID)
+		if err != nil || order != nil {
+			return LegResult{Order: order, Error: err, ClientOrderID: prevID}
+		}
+	}
+
+	clientID := legClientID(params, leg)
+	order, err := exch.PlaceMarketOrder(ctx, params.Symbol, side, qty, clientID)
+	return LegResult{Order: order, Error: err, ClientOrderID: clientID}
+}
@@ -0,0 +1,140 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+
+	"arbitrage/internal/exchange"
+	"arbitrage/pkg/retry"
+)
+
+// lostResponseExchange исполняет рыночные ордера, но теряет ответ на первые lost из них,
+// как при таймауте запроса, который биржа успела принять
+type lostResponseExchange struct {
+	*exchange.Sim
+	lost int
+}
+
+func (l *lostResponseExchange) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*exchange.Order, error) {
+	order, err := l.Sim.PlaceMarketOrder(ctx, symbol, side, qty, clientOrderID)
+	if err == nil && l.lost > 0 {
+		l.lost--
+		return nil, errors.New("read tcp: i/o timeout")
+	}
+	return order, err
+}
+
+// positionSize возвращает суммарный объём открытых позиций симулятора
+func positionSize(t *testing.T, sim *exchange.Sim) float64 {
+	t.Helper()
+	positions, err := sim.GetOpenPositions(context.Background())
+	if err != nil {
+		t.Fatalf("GetOpenPositions: %v", err)
+	}
+	var size float64
+	for _, pos := range positions {
+		size += pos.Size
+	}
+	return size
+}
+
+// TestClientOrderIDs проверяет формат и уникальность клиентских ID
+func TestClientOrderIDs(t *testing.T) {
+	valid := regexp.MustCompile(`^[a-z0-9]{1,28}$`)
+	params := ExecuteParams{
+		PairID:  65535,
+		EntryAt: time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC),
+		Part:    15,
+		Attempt: 5,
+	}
+
+	seen := make(map[string]bool)
+	check := func(id string) {
+		t.Helper()
+		if !valid.MatchString(id) {
+			t.Errorf("invalid client order id %q (%d chars)", id, len(id))
+		}
+		if seen[id] {
+			t.Errorf("duplicate client order id %q", id)
+		}
+		seen[id] = true
+	}
+
+	for _, leg := range []string{legLong, legShort} {
+		id := legClientID(params, leg)
+		check(id)
+		check(retryClientID(id, 2))
+		check(rollbackClientID(&exchange.Order{ClientOrderID: retryClientID(id, 2)}))
+	}
+	check(rollbackClientID(&exchange.Order{ClientOrderID: hedgeClientID(params, 255)}))
+
+	next := params
+	next.Attempt++
+	check(legClientID(next, legLong))
+	next.Part++
+	check(legClientID(next, legLong))
+
+	if legClientID(params, legLong) != legClientID(params, legLong) {
+		t.Fatal("client order id must be deterministic")
+	}
+	if retryClientID("", 1) != "" || rollbackClientID(&exchange.Order{}) != "" {
+		t.Fatal("expected no client id without base id")
+	}
+}
+
+// TestExecuteParallel_RetryFindsAcceptedOrder проверяет, что повтор второй ноги
+// не удваивает позицию, если биржа приняла ордер без ответа
+func TestExecuteParallel_RetryFindsAcceptedOrder(t *testing.T) {
+	long := newMakerTestSim("bybit", 99, 100)
+	short := &lostResponseExchange{Sim: newMakerTestSim("okx", 101, 102), lost: 1}
+	oe := newFillsTestExecutor(map[string]exchange.Exchange{"bybit": long, "okx": short})
+
+	result := oe.ExecuteParallel(context.Background(), ExecuteParams{
+		Symbol:        "BTCUSDT",
+		Volume:        1,
+		LongExchange:  "bybit",
+		ShortExchange: "okx",
+		NOrders:       1,
+		PairID:        7,
+	})
+
+	if !result.Success {
+		t.Fatalf("expected success, got error: %v", result.Error)
+	}
+	if size := positionSize(t, short.Sim); size != 1 {
+		t.Fatalf("expected short position 1 without duplicate, got %.4f", size)
+	}
+	if result.ShortOrder.ClientOrderID == "" || result.ShortOrder.AvgFillPrice != 101 {
+		t.Fatalf("expected accepted short order, got %+v", result.ShortOrder)
+	}
+}
+
+// TestExecuteWithRetry_ReusesAcceptedLegs проверяет, что повтор входа использует
+// ордера прошлой попытки, принятые биржей без ответа
+func TestExecuteWithRetry_ReusesAcceptedLegs(t *testing.T) {
+	long := &lostResponseExchange{Sim: newMakerTestSim("bybit", 99, 100), lost: 1}
+	short := &lostResponseExchange{Sim: newMakerTestSim("okx", 101, 102), lost: 1}
+	oe := newFillsTestExecutor(map[string]exchange.Exchange{"bybit": long, "okx": short})
+
+	result := oe.ExecuteWithRetry(context.Background(), ExecuteParams{
+		Symbol:        "BTCUSDT",
+		Volume:        1,
+		LongExchange:  "bybit",
+		ShortExchange: "okx",
+		NOrders:       1,
+		PairID:        7,
+	}, retry.Config{MaxRetries: 3, InitialDelay: time.Millisecond, MaxDelay: time.Millisecond})
+
+	if !result.Success {
+		t.Fatalf("expected success, got error: %v", result.Error)
+	}
+	if size := positionSize(t, long.Sim); size != 1 {
+		t.Fatalf("expected long position 1 without duplicate, got %.4f", size)
+	}
+	if size := positionSize(t, short.Sim); size != 1 {
+		t.Fatalf("expected short position 1 without duplicate, got %.4f", size)
+	}
+}
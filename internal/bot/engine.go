@@ -66,8 +66,8 @@ func releaseNotification(n *models.Notification) {
 
 // PositionKey - ключ для быстрого поиска пары по позиции O(1)
 type PositionKey struct {
-	Exchange string
-	Symbol   string
+	Account string // идентификатор аккаунта (exchange.AccountKey)
+	Symbol  string
 }
 
 // acquirePriceUpdate получает PriceUpdate из пула
@@ -176,6 +176,15 @@ type Engine struct {
 
 	// ОПТИМИЗАЦИЯ: Atomic counter вместо mutex для activeArbs
 	activeArbs int64
+
+	// 1 = движок останавливается, новые входы запрещены (см. Drain)
+	draining int32
+
+	// Запись рыночных данных (nil если выключена в BotConfig)
+	recorder *Recorder
+
+	// Наблюдатель торговых решений (backtest), nil в production
+	observer TradeObserver
 }
 
 // priceShard - шард для обработки ценовых событий
@@ -197,9 +206,22 @@ type PairState struct {
 	// ОПТИМИЗАЦИЯ: atomic копии торговых параметров для lock-free чтения в горячем пути
 	// Используем atomic.Uint64 + math.Float64bits/Float64frombits для float64
 	// Обновляются при UpdatePairConfig, читаются в checkArbitrageOpportunity
-	entrySpreadBits uint64 // atomic: EntrySpreadPct в битовом представлении
-	exitSpreadBits  uint64 // atomic: ExitSpreadPct в битовом представлении
-	stopLossBits    uint64 // atomic: StopLoss в битовом представлении
+	entrySpreadBits uint64                   // atomic: EntrySpreadPct в битовом представлении
+	exitSpreadBits  uint64                   // atomic: ExitSpreadPct в битовом представлении
+	stopLossBits    uint64                   // atomic: StopLoss в битовом представлении
+	makerTaker      int32                    // atomic: 1 = режим входа maker_taker
+	fundingStrategy int32                    // atomic: 1 = стратегия funding
+	fundingDiffBits uint64                   // atomic: FundingDiffPct в битовом представлении
+	maxHold         int64                    // atomic: максимальное время удержания (0 = без ограничения)
+	leverage        int32                    // atomic: плечо на обеих биржах
+	isolatedMargin  int32                    // atomic: 1 = режим маржи isolated
+	accounts        atomic.Pointer[[]string] // аккаунты для ног (nil - все подключенные)
+	marginState     int32                    // atomic: проверка плеча и режима маржи (marginVerified/...)
+
+	// fundingSettled - время последнего учтённого начисления фандинга по биржам ног (под mu)
+	fundingSettled map[string]time.Time
+	// fundingPending - начисления, учтённые по оценке и ожидающие сверки с историей биржи (под mu)
+	fundingPending []*fundingEstimate
 }
 
 // GetEntrySpread возвращает EntrySpreadPct атомарно (lock-free)
@@ -232,6 +254,101 @@ func (ps *PairState) setStopLoss(v float64) {
 	atomic.StoreUint64(&ps.stopLossBits, math.Float64bits(v))
 }
 
+// IsMakerTaker возвращает true если пара входит в режиме maker_taker (lock-free)
+func (ps *PairState) IsMakerTaker() bool {
+	return atomic.LoadInt32(&ps.makerTaker) == 1
+}
+
+// setEntryMode устанавливает режим входа атомарно
+func (ps *PairState) setEntryMode(mode string) {
+	var v int32
+	if mode == models.EntryModeMakerTaker {
+		v = 1
+	}
+	atomic.StoreInt32(&ps.makerTaker, v)
+}
+
+// IsFundingStrategy возвращает true если пара торгует разницу ставок фандинга (lock-free)
+func (ps *PairState) IsFundingStrategy() bool {
+	return atomic.LoadInt32(&ps.fundingStrategy) == 1
+}
+
+// GetFundingDiff возвращает порог разницы ставок фандинга для входа атомарно (lock-free)
+func (ps *PairState) GetFundingDiff() float64 {
+	return math.Float64frombits(atomic.LoadUint64(&ps.fundingDiffBits))
+}
+
+// GetMaxHold возвращает максимальное время удержания позиции атомарно (lock-free)
+func (ps *PairState) GetMaxHold() time.Duration {
+	return time.Duration(atomic.LoadInt64(&ps.maxHold))
+}
+
+// setStrategy устанавливает стратегию и её параметры атомарно
+func (ps *PairState) setStrategy(cfg *models.PairConfig) {
+	var v int32
+	if cfg.IsFundingStrategy() {
+		v = 1
+	}
+	atomic.StoreInt32(&ps.fundingStrategy, v)
+	atomic.StoreUint64(&ps.fundingDiffBits, math.Float64bits(cfg.FundingDiffPct))
+	atomic.StoreInt64(&ps.maxHold, int64(cfg.MaxHold()))
+}
+
+// GetLeverage возвращает плечо пары атомарно (lock-free)
+func (ps *PairState) GetLeverage() int {
+	if v := atomic.LoadInt32(&ps.leverage); v > 0 {
+		return int(v)
+	}
+	return 1
+}
+
+// GetMarginSettings возвращает плечо и режим маржи пары атомарно (lock-free)
+func (ps *PairState) GetMarginSettings() MarginSettings {
+	mode := exchange.MarginModeCross
+	if atomic.LoadInt32(&ps.isolatedMargin) == 1 {
+		mode = exchange.MarginModeIsolated
+	}
+	return MarginSettings{Leverage: ps.GetLeverage(), Mode: mode}
+}
+
+// setMarginSettings устанавливает плечо и режим маржи атомарно
+func (ps *PairState) setMarginSettings(cfg *models.PairConfig) {
+	var v int32
+	if cfg.GetMarginMode() == models.MarginModeIsolated {
+		v = 1
+	}
+	atomic.StoreInt32(&ps.isolatedMargin, v)
+	atomic.StoreInt32(&ps.leverage, int32(cfg.GetLeverage()))
+}
+
+// GetAccounts возвращает аккаунты, на которых пара открывает ноги (lock-free)
+// nil - любые подключенные аккаунты
+func (ps *PairState) GetAccounts() []string {
+	if accounts := ps.accounts.Load(); accounts != nil {
+		return *accounts
+	}
+	return nil
+}
+
+// setAccounts устанавливает аккаунты пары атомарно
+func (ps *PairState) setAccounts(accounts []string) {
+	if len(accounts) == 0 {
+		ps.accounts.Store(nil)
+		return
+	}
+	accounts = append([]string(nil), accounts...)
+	ps.accounts.Store(&accounts)
+}
+
+// meetsEntryThreshold проверяет порог входа стратегии пары (lock-free)
+// spread: чистый спред >= entry_spread; funding: разница ставок >= funding_diff
+func (ps *PairState) meetsEntryThreshold(opp *ArbitrageOpportunity) bool {
+	if ps.IsFundingStrategy() {
+		return opp.FundingDiff >= ps.GetFundingDiff()
+	}
+	return opp.NetSpread >= ps.GetEntrySpread()
+}
+
 // PriceUpdate - событие обновления цены от WebSocket
 type PriceUpdate struct {
 	Exchange  string
@@ -267,9 +384,9 @@ type WebSocketHub interface {
 	// Вызывается при OPEN, CLOSE, SL, LIQUIDATION, ERROR и др.
 	BroadcastNotification(notif *models.Notification)
 
-	// BroadcastBalanceUpdate отправляет обновление баланса биржи
-	// Вызывается каждую минуту для каждой подключенной биржи
-	BroadcastBalanceUpdate(exchange string, balance float64)
+	// BroadcastBalanceUpdate отправляет обновление баланса аккаунта биржи
+	// Вызывается каждую минуту для каждого подключенного аккаунта
+	BroadcastBalanceUpdate(account string, balance float64)
 
 	// BroadcastStatsUpdate отправляет обновление статистики
 	// Вызывается после завершения каждой сделки
@@ -321,6 +438,8 @@ func NewEngine(cfg *config.Config, wsHub WebSocketHub) *Engine {
 
 	// Инициализация основных компонентов
 	e.spreadCalc = NewSpreadCalculator(e.priceTracker)
+	e.spreadCalc.SetConsiderFunding(false, cfg.Bot.FundingHoldPeriod)
+	// Своя копия карты: исполнитель синхронизируется через AddExchange/RemoveExchange под своим мьютексом
 	e.orderExec = NewOrderExecutor(e.exchanges, cfg.Bot)
 
 	// Инициализация валидатора ордеров
@@ -335,6 +454,7 @@ func NewEngine(cfg *config.Config, wsHub WebSocketHub) *Engine {
 	// Инициализация анализатора стаканов (5 уровней, 5 секунд актуальности)
 	e.orderBookAnalyzer = NewOrderBookAnalyzer(5, 5*time.Second)
 	e.spreadCalc.AttachOrderBookAnalyzer(e.orderBookAnalyzer, 0)
+	e.orderExec.SetOrderBooks(e.orderBookAnalyzer)
 	e.priceTracker.AttachOrderBookAnalyzer(e.orderBookAnalyzer, func(symbol string) float64 {
 		return e.spreadCalc.getVolumeForSymbol(symbol)
 	})
@@ -390,9 +510,26 @@ func NewEngine(cfg *config.Config, wsHub WebSocketHub) *Engine {
 		func(pairID int) { _ = e.PausePair(pairID) },
 		DefaultRiskConfig(),
 	)
-	e.riskManager.SetExchanges(e.exchanges)
+	// Своя карта: риск-менеджер синхронизируется через AddExchange/RemoveExchange под своим мьютексом
+	e.riskManager.SetExchanges(make(map[string]exchange.Exchange))
 	e.riskMonitor = NewRiskMonitor(e.riskManager, e.getHoldingPairsSnapshot)
 
+	// Запись рыночных данных: ошибка не мешает торговле, запись просто выключается
+	if cfg.Bot.RecordMarketData {
+		recCfg := DefaultRecorderConfig(cfg.Bot.RecordDir)
+		recCfg.SnapshotInterval = cfg.Bot.RecordSnapshotInterval
+		recCfg.SnapshotDepth = cfg.Bot.RecordSnapshotDepth
+		recCfg.MaxFileSize = int64(cfg.Bot.RecordMaxFileSizeMB) << 20
+		recCfg.RotateInterval = cfg.Bot.RecordRotateInterval
+
+		recorder, err := NewRecorder(recCfg, e.GetExchanges)
+		if err != nil {
+			utils.Warn("market data recording disabled", utils.Err(err))
+		} else {
+			e.recorder = recorder
+		}
+	}
+
 	return e
 }
 
@@ -415,6 +552,9 @@ func (e *Engine) Run(ctx context.Context) error {
 	if e.riskMonitor != nil {
 		go e.riskMonitor.Start(ctx) // аварийный SL мониторинг
 	}
+	if e.recorder != nil {
+		go e.recorder.Run(ctx) // запись рыночных данных
+	}
 
 	<-ctx.Done()
 
@@ -422,12 +562,63 @@ func (e *Engine) Run(ctx context.Context) error {
 	e.cancel() // отменяем внутренний контекст для горутин закрытия
 	close(e.shutdown)
 
+	// Дожидаемся сброса записи рыночных данных на диск
+	if e.recorder != nil {
+		<-e.recorder.Done()
+	}
+
 	// Drain каналов для освобождения памяти
 	e.drainChannels()
 
 	return ctx.Err()
 }
 
+// Drain готовит движок к остановке: запрещает новые входы и ждёт,
+// пока все пары выйдут из ENTERING/EXITING.
+//
+// Вызывается ДО отмены контекста Run - executeExit использует e.ctx,
+// и ранняя отмена оборвала бы закрытие позиции на полпути.
+// Возвращает ctx.Err(), если пары не успели завершить переходы.
+func (e *Engine) Drain(ctx context.Context) error {
+	atomic.StoreInt32(&e.draining, 1)
+	return e.waitNoTransitions(ctx, 100*time.Millisecond)
+}
+
+// waitNoTransitions ждёт, пока все пары выйдут из ENTERING/EXITING
+func (e *Engine) waitNoTransitions(ctx context.Context, interval time.Duration) error {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if e.countInTransition() == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// countInTransition возвращает количество пар в ENTERING или EXITING
+func (e *Engine) countInTransition() int {
+	e.pairsMu.RLock()
+	defer e.pairsMu.RUnlock()
+
+	count := 0
+	for _, ps := range e.pairs {
+		ps.mu.RLock()
+		state := ps.Runtime.State
+		ps.mu.RUnlock()
+		if state == models.StateEntering || state == models.StateExiting {
+			count++
+		}
+	}
+	return count
+}
+
 // drainChannels очищает буферы каналов при shutdown
 func (e *Engine) drainChannels() {
 	// Drain price shards
@@ -545,6 +736,11 @@ func (e *Engine) checkExitConditionsForPair(ctx context.Context, ps *PairState)
 		return
 	}
 
+	// Начисления фандинга по удерживаемой позиции - в реализованный PNL
+	if ps.IsFundingStrategy() {
+		e.settleFunding(ps)
+	}
+
 	// Используем ArbitrageDetector для проверки условий
 	exitConditions := e.arbDetector.CheckExitConditions(ps)
 
@@ -597,6 +793,7 @@ func (e *Engine) executeExit(ps *PairState, reason ExitReason) {
 
 		ps.Runtime.Legs = nil
 		ps.Runtime.FilledParts = 0
+		ps.Runtime.EntryTime = nil
 		e.decrementActiveArbs()
 
 		// МЕТРИКА: записываем успешную сделку
@@ -618,6 +815,10 @@ func (e *Engine) executeExit(ps *PairState, reason ExitReason) {
 			atomic.StoreInt32(&ps.isReady, 1)
 		}
 
+		if e.observer != nil {
+			e.observer.OnTradeClosed(ps.Config.ID, result, reason)
+		}
+
 		// Отправляем уведомление
 		e.notifyTradeClosed(ps, result, reason)
 	} else {
@@ -652,10 +853,12 @@ func (e *Engine) notifyTradeClosed(ps *PairState, result *ExecuteResult, reason
 		Message: fmt.Sprintf("%s closed: PNL %.2f USDT (reason: %s)",
 			ps.Config.Symbol, result.TotalPnl, reason),
 		Meta: map[string]interface{}{
-			"symbol":       ps.Config.Symbol,
-			"pnl":          result.TotalPnl,
-			"reason":       string(reason),
-			"realized_pnl": ps.Runtime.RealizedPnl,
+			"symbol":            ps.Config.Symbol,
+			"pnl":               result.TotalPnl,
+			"reason":            string(reason),
+			"realized_pnl":      ps.Runtime.RealizedPnl,
+			"funding_pnl":       ps.Runtime.FundingPnl,
+			"funding_estimated": ps.Runtime.FundingEstimated,
 		},
 	}
 
@@ -741,23 +944,42 @@ func (e *Engine) checkArbitrageOpportunity(ps *PairState) {
 		return
 	}
 
+	// Плечо и режим маржи не сверены (изменены настройки, переподключена биржа) -
+	// проверяем в фоне, вход после проверки
+	if state := atomic.LoadInt32(&ps.marginState); state != marginVerified {
+		if state == marginPending && atomic.CompareAndSwapInt32(&ps.marginState, marginPending, marginVerifying) {
+			go e.verifyPairMargin(ps)
+		}
+		return
+	}
+
 	// ОПТИМИЗАЦИЯ 2: atomic проверка лимита арбитражей
 	if !e.canOpenNewArbitrage() {
 		return
 	}
 
-	// ОПТИМИЗАЦИЯ 3: быстрая проверка спреда БЕЗ Lock
+	// ОПТИМИЗАЦИЯ 3: быстрая проверка порога входа БЕЗ Lock
 	// Config.Symbol - immutable (не меняется после создания пары)
-	// EntrySpread - читаем атомарно через GetEntrySpread() (lock-free)
+	// Пороги читаем атомарно (lock-free), см. meetsEntryThreshold
 	symbol := ps.Config.Symbol
-	entrySpread := ps.GetEntrySpread()
+	accounts := ps.GetAccounts()
 
 	// Получаем текущую арбитражную возможность (lock-free через sync.Map)
-	opp := e.arbDetector.DetectOpportunity(symbol)
+	// Для maker_taker спред считается от пассивной цены (см. GetMakerOpportunity),
+	// для funding связка выбирается по ставкам фандинга (см. GetFundingOpportunity)
+	var opp *ArbitrageOpportunity
+	switch {
+	case ps.IsFundingStrategy():
+		opp = e.arbDetector.DetectFundingOpportunity(symbol, accounts)
+	case ps.IsMakerTaker():
+		opp = e.arbDetector.DetectMakerOpportunity(symbol, accounts)
+	default:
+		opp = e.arbDetector.DetectOpportunity(symbol, accounts)
+	}
 	if opp == nil {
 		return
 	}
-	if opp.NetSpread < entrySpread {
+	if !ps.meetsEntryThreshold(opp) {
 		// Нет подходящей возможности - возвращаем в пул и выходим БЕЗ Lock (90%+ случаев)
 		ReleaseArbitrageOpportunity(opp)
 		return
@@ -789,6 +1011,9 @@ func (e *Engine) checkArbitrageOpportunity(ps *PairState) {
 	// Условия не выполнены - выходим
 	if !conditions.CanEnter {
 		ps.mu.Unlock()
+		if e.observer != nil {
+			e.observer.OnEntryRejected(ps.Config.ID, conditions.Reason)
+		}
 		// Освобождаем EntryConditions в пул (opp уже освобождён в CheckEntryConditions)
 		ReleaseEntryConditions(conditions)
 		return
@@ -801,6 +1026,10 @@ func (e *Engine) checkArbitrageOpportunity(ps *PairState) {
 
 	ps.mu.Unlock() // Освобождаем Lock как можно раньше!
 
+	if e.observer != nil {
+		e.observer.OnEntryDecision(ps.Config.ID, *conditions.Opportunity, conditions.AdjustedVolume)
+	}
+
 	// МЕТРИКА: записываем возможность, которая привела к входу
 	RecordOpportunity(ps.Config.Symbol, true)
 	RecordSpread(ps.Config.Symbol, conditions.Opportunity.NetSpread)
@@ -824,7 +1053,12 @@ func (e *Engine) shouldEnter(ps *PairState, opp *ArbitrageOpportunity) bool {
 // executeEntryWithConditions - исполнение входа с предварительно проверенными условиями
 func (e *Engine) executeEntryWithConditions(ps *PairState, conditions *EntryConditions) {
 	// Используем родительский контекст e.ctx для корректного graceful shutdown
-	ctx, cancel := context.WithTimeout(e.ctx, e.cfg.Bot.OrderTimeout)
+	// Котировке maker_taker нужно время на исполнение поверх таймаута ордеров
+	timeout := e.cfg.Bot.OrderTimeout
+	if conditions.Opportunity.MakerExchange != "" {
+		timeout += e.cfg.Bot.MakerQuoteTimeout
+	}
+	ctx, cancel := context.WithTimeout(e.ctx, timeout)
 	defer cancel()
 
 	// ОПТИМИЗАЦИЯ: освобождаем объекты в конце (возвращаем в пул)
@@ -834,6 +1068,19 @@ func (e *Engine) executeEntryWithConditions(ps *PairState, conditions *EntryCond
 		ReleaseEntryConditions(conditions)
 	}()
 
+	// Плечо и режим маржи сверены при запуске пары - здесь только проверка кэша.
+	// Промах (биржа переподключена во время входа) - вход откладывается до новой проверки
+	if !e.orderExec.MarginSettingsApplied(ps.Config.Symbol, ps.GetMarginSettings(),
+		opp.LongExchange, opp.ShortExchange) {
+		atomic.StoreInt32(&ps.marginState, marginPending)
+		ps.mu.Lock()
+		ps.Runtime.State = models.StateReady
+		atomic.StoreInt32(&ps.isReady, 1)
+		ps.mu.Unlock()
+		e.decrementActiveArbs()
+		return
+	}
+
 	// МЕТРИКА: засекаем время исполнения входа
 	entryStart := time.Now()
 
@@ -841,15 +1088,23 @@ func (e *Engine) executeEntryWithConditions(ps *PairState, conditions *EntryCond
 
 	var result *ExecuteResult
 
-	// Проверяем нужен ли частичный вход
-	if ps.Config.NOrders > 1 && e.partialManager != nil {
+	// maker_taker исполняется частями сам (по мере исполнения котировки),
+	// поэтому имеет приоритет над частичным входом.
+	// Частичный вход перепроверяет спред перед каждой частью - funding входит целиком
+	if opp.MakerExchange != "" {
+		params := newMakerTakerParams(ps.Config.Symbol, volume, ps.GetEntrySpread(), opp, e.spreadCalc)
+		params.PairID = ps.Config.ID
+		result = e.orderExec.ExecuteMakerTaker(ctx, params)
+	} else if ps.Config.NOrders > 1 && e.partialManager != nil && !ps.IsFundingStrategy() {
 		// Частичный вход через PartialEntryManager
 		partialResult := e.partialManager.ExecutePartialEntry(ctx, PartialEntryParams{
+			PairID:        ps.Config.ID,
 			Symbol:        ps.Config.Symbol,
 			TotalVolume:   volume,
 			NOrders:       ps.Config.NOrders,
 			LongExchange:  opp.LongExchange,
 			ShortExchange: opp.ShortExchange,
+			Accounts:      ps.GetAccounts(),
 			EntrySpread:   ps.GetEntrySpread(),
 			ExitSpread:    ps.GetExitSpread(),
 			MinSpread:     ps.GetEntrySpread() * 0.8, // 80% от entry spread (atomic read)
@@ -868,6 +1123,8 @@ func (e *Engine) executeEntryWithConditions(ps *PairState, conditions *EntryCond
 			LongExchange:  opp.LongExchange,
 			ShortExchange: opp.ShortExchange,
 			NOrders:       1,
+			PairID:        ps.Config.ID,
+			EntryAt:       entryStart,
 		})
 	}
 
@@ -880,10 +1137,14 @@ func (e *Engine) executeEntryWithConditions(ps *PairState, conditions *EntryCond
 
 	if result.Success {
 		// Успешный вход
+		entryTime := e.spreadCalc.now()
 		ps.Runtime.State = models.StateHolding
 		ps.Runtime.Legs = result.Legs
 		ps.Runtime.FilledParts = 1
+		ps.Runtime.EntryTime = &entryTime
 		ps.Runtime.LastUpdate = time.Now()
+		ps.fundingSettled = nil
+		ps.fundingPending = nil
 
 		// ОПТИМИЗАЦИЯ: добавляем в positionIndex для O(1) поиска при ликвидациях
 		e.addToPositionIndex(ps)
@@ -892,6 +1153,9 @@ func (e *Engine) executeEntryWithConditions(ps *PairState, conditions *EntryCond
 		UpdateActiveArbitrages(atomic.LoadInt64(&e.activeArbs))
 		EventsProcessed.WithLabelValues("entry").Inc()
 
+		if e.observer != nil {
+			e.observer.OnTradeOpened(ps.Config.ID, result)
+		}
 		e.notifyTradeOpened(ps, result)
 	} else {
 		// Ошибка - возврат в готовность или пауза при провале второй ноги
@@ -909,6 +1173,9 @@ func (e *Engine) executeEntryWithConditions(ps *PairState, conditions *EntryCond
 		// МЕТРИКА: записываем откат
 		RecordTrade(ps.Config.Symbol, "rollback", 0)
 
+		if e.observer != nil {
+			e.observer.OnEntryFailed(ps.Config.ID, result.Error)
+		}
 		e.notifyError(ps, result.Error)
 	}
 }
@@ -927,6 +1194,7 @@ func (e *Engine) executeEntry(ps *PairState, opp *ArbitrageOpportunity) {
 		LongExchange:  opp.LongExchange,
 		ShortExchange: opp.ShortExchange,
 		NOrders:       ps.Config.NOrders,
+		PairID:        ps.Config.ID,
 	})
 
 	ps.mu.Lock()
@@ -979,7 +1247,7 @@ func (e *Engine) positionEventLoop(ctx context.Context) {
 // Критически важно для минимизации убытков при ликвидации!
 func (e *Engine) handleLiquidation(update PositionUpdate) {
 	// O(1) поиск пары через индекс
-	key := PositionKey{Exchange: update.Exchange, Symbol: update.Symbol}
+	key := PositionKey{Account: update.Exchange, Symbol: update.Symbol}
 	v, ok := e.positionIndex.Load(key)
 	if !ok {
 		// Позиция не найдена в индексе - возможно уже закрыта
@@ -988,6 +1256,12 @@ func (e *Engine) handleLiquidation(update PositionUpdate) {
 
 	ps := v.(*PairState)
 
+	// В режиме hedge на аккаунте бывает позиция другой стороны (ручная торговля):
+	// её ликвидация не затрагивает ногу пары
+	if update.Side != "" && !ps.holdsLeg(update.Exchange, update.Side) {
+		return
+	}
+
 	// МЕТРИКА: записываем ликвидацию
 	LiquidationsDetected.WithLabelValues(update.Exchange, update.Symbol).Inc()
 
@@ -1009,6 +1283,22 @@ func (e *Engine) handleLiquidation(update PositionUpdate) {
 	go e.emergencyCloseSecondLeg(ps, update)
 }
 
+// holdsLeg проверяет, что у пары есть нога стороны side на аккаунте account
+func (ps *PairState) holdsLeg(account, side string) bool {
+	ps.mu.RLock()
+	defer ps.mu.RUnlock()
+
+	if ps.Runtime == nil {
+		return false
+	}
+	for _, leg := range ps.Runtime.Legs {
+		if leg.Exchange == account && leg.Side == side {
+			return true
+		}
+	}
+	return false
+}
+
 // closePositionForRisk - аварийное закрытие обеих ног по сигналу RiskManager
 func (e *Engine) closePositionForRisk(ctx context.Context, ps *PairState, reason ExitReason) error {
 	if ps == nil || ps.Runtime == nil {
@@ -1182,9 +1472,21 @@ func (e *Engine) periodicTasks(ctx context.Context) {
 	balanceTicker := time.NewTicker(e.cfg.Bot.BalanceUpdateFreq)
 	statsTicker := time.NewTicker(e.cfg.Bot.StatsUpdateFreq)
 	goroutineTicker := time.NewTicker(10 * time.Second) // мониторинг goroutines
+	feeTicker := time.NewTicker(e.feeRefreshInterval())
+	instrumentTicker := time.NewTicker(exchange.DefaultInstrumentTTL)
 	defer balanceTicker.Stop()
 	defer statsTicker.Stop()
 	defer goroutineTicker.Stop()
+	defer feeTicker.Stop()
+	defer instrumentTicker.Stop()
+
+	// Ставки фандинга запрашиваются при включённом ConsiderFunding или для пар стратегии funding
+	var fundingC <-chan time.Time
+	if e.cfg.Bot.FundingRefreshInterval > 0 {
+		fundingTicker := time.NewTicker(e.cfg.Bot.FundingRefreshInterval)
+		defer fundingTicker.Stop()
+		fundingC = fundingTicker.C
+	}
 
 	for {
 		select {
@@ -1194,6 +1496,12 @@ func (e *Engine) periodicTasks(ctx context.Context) {
 			e.updateBalances()
 		case <-statsTicker.C:
 			e.broadcastPairStates()
+		case <-fundingC:
+			e.updateFundingRates(e.fundingSymbols())
+		case <-feeTicker.C:
+			e.updateTradingFees()
+		case <-instrumentTicker.C:
+			e.updateInstruments()
 		case <-goroutineTicker.C:
 			// МЕТРИКА: обновляем счётчик горутин для мониторинга утечек
 			GoroutineCount.Set(float64(runtime.NumGoroutine()))
@@ -1237,6 +1545,51 @@ func (e *Engine) updateBalances() {
 	wg.Wait()
 }
 
+// updateFundingRates запрашивает ставки фандинга символов на всех биржах
+// Ошибки не критичны: для биржи остаётся предыдущая ставка
+func (e *Engine) updateFundingRates(symbols []string) {
+	if len(symbols) == 0 {
+		return
+	}
+
+	var wg sync.WaitGroup
+	for name, exch := range e.GetExchanges() {
+		if !exchange.CapabilitiesOf(name).Funding {
+			continue
+		}
+		wg.Add(1)
+		go func(exchName string, ex exchange.Exchange) {
+			defer wg.Done()
+
+			for _, symbol := range symbols {
+				ctx, cancel := context.WithTimeout(e.ctx, 5*time.Second)
+				rate, err := ex.GetFundingRate(ctx, symbol)
+				cancel()
+
+				if err != nil {
+					utils.Debugf("funding rate %s on %s: %v", symbol, exchName, err)
+					continue
+				}
+				rate.Symbol = symbol
+				e.spreadCalc.SetFundingRate(exchName, rate)
+			}
+		}(name, exch)
+	}
+	wg.Wait()
+}
+
+// pairSymbols возвращает символы всех пар движка
+func (e *Engine) pairSymbols() []string {
+	var symbols []string
+	e.pairsBySymbol.Range(func(key, value interface{}) bool {
+		if len(value.([]*PairState)) > 0 {
+			symbols = append(symbols, key.(string))
+		}
+		return true
+	})
+	return symbols
+}
+
 // broadcastPairStates - отправка состояний пар клиентам
 // ОПТИМИЗАЦИЯ: копируем данные под коротким RLock, отправляем без Lock
 // Было: RLock на весь цикл broadcast (300-900ms при 30 парах)
@@ -1284,6 +1637,9 @@ func (e *Engine) getPairsForSymbol(symbol string) []*PairState {
 
 // canOpenNewArbitrage - atomic проверка без lock
 func (e *Engine) canOpenNewArbitrage() bool {
+	if atomic.LoadInt32(&e.draining) == 1 {
+		return false // движок останавливается
+	}
 	if e.cfg.Bot.MaxConcurrentArbs == 0 {
 		return true // без лимита
 	}
@@ -1304,7 +1660,7 @@ func (e *Engine) decrementActiveArbs() {
 // ВАЖНО: вызывать после успешного входа в позицию
 func (e *Engine) addToPositionIndex(ps *PairState) {
 	for _, leg := range ps.Runtime.Legs {
-		key := PositionKey{Exchange: leg.Exchange, Symbol: ps.Config.Symbol}
+		key := PositionKey{Account: leg.Exchange, Symbol: ps.Config.Symbol}
 		e.positionIndex.Store(key, ps)
 	}
 }
@@ -1313,7 +1669,7 @@ func (e *Engine) addToPositionIndex(ps *PairState) {
 // ВАЖНО: вызывать перед очисткой ps.Runtime.Legs
 func (e *Engine) removeFromPositionIndex(ps *PairState) {
 	for _, leg := range ps.Runtime.Legs {
-		key := PositionKey{Exchange: leg.Exchange, Symbol: ps.Config.Symbol}
+		key := PositionKey{Account: leg.Exchange, Symbol: ps.Config.Symbol}
 		e.positionIndex.Delete(key)
 	}
 }
@@ -1432,6 +1788,22 @@ func (e *Engine) AddExchange(name string, exch exchange.Exchange) {
 	e.exchMu.Lock()
 	e.exchanges[name] = exch
 	e.exchMu.Unlock()
+	e.orderExec.AddExchange(name, exch)
+	e.orderExec.ResetExchangeMarginSettings(name)
+
+	// Новый адаптер не знает настроек символов - пары, торгующие на бирже, сверяют их заново
+	e.resetPairMargin(func(ps *PairState) bool {
+		accounts := ps.GetAccounts()
+		if accounts == nil {
+			return true
+		}
+		for _, account := range accounts {
+			if account == name {
+				return true
+			}
+		}
+		return false
+	})
 
 	if e.riskManager != nil {
 		e.riskManager.AddExchange(name, exch)
@@ -1439,10 +1811,56 @@ func (e *Engine) AddExchange(name string, exch exchange.Exchange) {
 
 	// Подписка на WebSocket обновления
 	e.subscribeToExchange(name, exch)
+
+	// Комиссии аккаунта - сразу, не дожидаясь FeeRefreshInterval
+	go e.refreshTradingFees(name, exch)
+
+	// Спецификации контрактов активных пар - для округления объёма до лота биржи
+	go e.refreshInstruments(name, exch)
+}
+
+// RemoveExchange убирает биржу из движка (после отключения через API)
+// Соединение не закрывается - им владеет вызывающая сторона
+func (e *Engine) RemoveExchange(name string) {
+	e.exchMu.Lock()
+	delete(e.exchanges, name)
+	e.exchMu.Unlock()
+	e.orderExec.RemoveExchange(name)
+	e.orderExec.ResetExchangeMarginSettings(name)
+
+	if e.riskManager != nil {
+		e.riskManager.RemoveExchange(name)
+	}
+	e.spreadCalc.RemoveFundingRates(name)
+	e.spreadCalc.RemoveFees(name)
+}
+
+// GetExchanges возвращает копию карты подключенных бирж
+func (e *Engine) GetExchanges() map[string]exchange.Exchange {
+	e.exchMu.RLock()
+	defer e.exchMu.RUnlock()
+
+	exchanges := make(map[string]exchange.Exchange, len(e.exchanges))
+	for name, exch := range e.exchanges {
+		exchanges[name] = exch
+	}
+	return exchanges
+}
+
+// NotificationChan возвращает канал уведомлений движка
+// Используется RecoveryManager, чтобы его уведомления попадали в тот же поток
+func (e *Engine) NotificationChan() chan *models.Notification {
+	return e.notificationChan
 }
 
 // subscribeToExchange подписывается на WS события биржи
+// Без приватного WebSocket ликвидации и исполнения видны только через REST
 func (e *Engine) subscribeToExchange(name string, exch exchange.Exchange) {
+	if !exchange.CapabilitiesOf(name).PrivateWS {
+		utils.Debugf("%s has no private WebSocket, positions and orders via REST only", name)
+		return
+	}
+
 	// Подписка на позиции (для ликвидаций)
 	exch.SubscribePositions(func(pos *exchange.Position) {
 		e.enqueuePositionUpdate(PositionUpdate{
@@ -1453,6 +1871,11 @@ func (e *Engine) subscribeToExchange(name string, exch exchange.Exchange) {
 			UnrealizedPnl: pos.UnrealizedPnl,
 		})
 	})
+
+	// Подписка на ордера (подтверждение исполнения ног)
+	exch.SubscribeOrders(func(order *exchange.Order) {
+		e.orderExec.OnOrderUpdate(name, order)
+	})
 }
 
 // getHoldingPairsSnapshot возвращает snapshot пар в HOLDING для RiskMonitor
@@ -1486,6 +1909,11 @@ func (e *Engine) AddPair(cfg *models.PairConfig) {
 	ps.setEntrySpread(cfg.EntrySpreadPct)
 	ps.setExitSpread(cfg.ExitSpreadPct)
 	ps.setStopLoss(cfg.StopLoss)
+	ps.setEntryMode(cfg.EntryMode)
+	ps.setStrategy(cfg)
+	ps.setMarginSettings(cfg)
+	ps.setAccounts(cfg.Accounts)
+	ps.marginState = marginPending // настройки маржи сверяются в StartPair
 	e.spreadCalc.SetDefaultVolume(cfg.Symbol, cfg.VolumeAsset)
 
 	// Добавляем в основной map под lock
@@ -1531,6 +1959,11 @@ func (e *Engine) AddPair(cfg *models.PairConfig) {
 
 	// Подписываемся на цены ПОСЛЕ обновления индекса
 	e.subscribeToSymbol(cfg.Symbol)
+
+	// Ставки фандинга новой пары запрашиваем сразу, не дожидаясь очередного обновления
+	if e.spreadCalc.IsFundingConsidered() || cfg.IsFundingStrategy() {
+		go e.updateFundingRates([]string{cfg.Symbol})
+	}
 }
 
 // RemovePair удаляет торговую пару
@@ -1588,8 +2021,13 @@ func (e *Engine) subscribeToSymbol(symbol string) {
 	e.exchMu.RLock()
 	defer e.exchMu.RUnlock()
 
+	recorder := e.recorder
+
 	for name, exch := range e.exchanges {
 		exchName := name // захват для closure
+		if recorder != nil {
+			recorder.Track(exchName, symbol)
+		}
 		exch.SubscribeTicker(symbol, func(ticker *exchange.Ticker) {
 			// Роутинг через шардированный канал (без аллокации!)
 			e.routePriceUpdate(
@@ -1599,17 +2037,37 @@ func (e *Engine) subscribeToSymbol(symbol string) {
 				ticker.AskPrice,
 				ticker.Timestamp,
 			)
+
+			// Запись после роутинга: неблокирующая постановка в очередь
+			if recorder != nil {
+				recorder.RecordTicker(exchName, ticker)
+			}
 		})
+
+		// Живой стакан для проверки ликвидности (GetSpreadWithLiquidity)
+		if err := exch.SubscribeOrderBook(symbol, e.orderBookAnalyzer.depth, func(book *exchange.OrderBook) {
+			e.OnOrderBookUpdate(exchName, book.Symbol, book.Bids, book.Asks)
+		}); err != nil {
+			utils.Debugf("order book subscription %s on %s: %v", symbol, exchName, err)
+		}
+
+		go e.loadInstrument(exchName, exch, symbol)
 	}
 }
 
 // StartPair запускает мониторинг пары
+// Перед запуском плечо и режим маржи применяются и сверяются на аккаунтах пары:
+// при ошибке пара остаётся на паузе
 func (e *Engine) StartPair(pairID int) error {
 	e.pairsMu.RLock()
 	ps, ok := e.pairs[pairID]
 	e.pairsMu.RUnlock()
 
 	if ok {
+		if err := e.applyPairMargin(ps); err != nil {
+			return fmt.Errorf("pair %d margin settings: %w", pairID, err)
+		}
+
 		ps.mu.Lock()
 		ps.Config.Status = "active"
 		ps.Runtime.State = models.StateReady
@@ -1638,6 +2096,98 @@ func (e *Engine) PausePair(pairID int) error {
 	return nil
 }
 
+// ============ Пошаговый режим (backtest) ============
+//
+// В backtest движок не запускается через Run: события подаются синхронно,
+// после каждого события вызывающий ждёт WaitIdle. Так результат прогона
+// не зависит от планировщика горутин.
+
+// SetTradeObserver устанавливает наблюдателя торговых решений
+// Вызывать до AddPair/Run
+func (e *Engine) SetTradeObserver(observer TradeObserver) {
+	e.observer = observer
+}
+
+// SetClock подменяет источник времени анализатора стаканов и фандинга (виртуальные часы replay)
+// Вызывать до AddPair/Run
+func (e *Engine) SetClock(now func() time.Time) {
+	e.orderBookAnalyzer.SetClock(now)
+	e.spreadCalc.SetClock(now)
+}
+
+// SetConsiderFunding включает/выключает учёт фандинга в чистом спреде (Settings.ConsiderFunding)
+// При включении ставки запрашиваются сразу, не дожидаясь FundingRefreshInterval
+func (e *Engine) SetConsiderFunding(consider bool) {
+	wasConsidered := e.spreadCalc.IsFundingConsidered()
+	e.spreadCalc.SetConsiderFunding(consider, e.cfg.Bot.FundingHoldPeriod)
+
+	if consider && !wasConsidered {
+		go e.updateFundingRates(e.pairSymbols())
+	}
+}
+
+// SetFee устанавливает комиссию тейкера биржи для расчёта чистого спреда
+func (e *Engine) SetFee(exchName string, fee float64) {
+	e.spreadCalc.SetFee(exchName, fee)
+}
+
+// SetMakerFee устанавливает комиссию мейкера биржи для входа maker_taker
+func (e *Engine) SetMakerFee(exchName string, fee float64) {
+	e.spreadCalc.SetMakerFee(exchName, fee)
+}
+
+// OnOrderBookUpdate обновляет стакан биржи в анализаторе ликвидности
+func (e *Engine) OnOrderBookUpdate(exchName, symbol string, bids, asks []exchange.PriceLevel) {
+	e.orderBookAnalyzer.UpdateOrderBook(symbol, exchName, toAnalyzerLevels(bids), toAnalyzerLevels(asks))
+}
+
+// ProcessPriceUpdate синхронно обрабатывает обновление цены, минуя шарды
+// Вход (если условия выполнены) запускается асинхронно - дождитесь WaitIdle
+func (e *Engine) ProcessPriceUpdate(exchName, symbol string, bidPrice, askPrice float64, timestamp time.Time) {
+	update := acquirePriceUpdate()
+	update.Exchange = exchName
+	update.Symbol = symbol
+	update.BidPrice = bidPrice
+	update.AskPrice = askPrice
+	update.Timestamp = timestamp
+
+	e.handlePriceUpdate(update)
+	releasePriceUpdate(update)
+}
+
+// CheckExits синхронно проверяет условия выхода для пар в HOLDING
+// Выход запускается асинхронно - дождитесь WaitIdle
+func (e *Engine) CheckExits() {
+	e.checkAllExitConditions(e.ctx)
+}
+
+// ProcessPositionUpdates обрабатывает накопленные события позиций (ликвидации)
+func (e *Engine) ProcessPositionUpdates() {
+	for {
+		select {
+		case update := <-e.positionUpdates:
+			if update.Liquidated {
+				e.handleLiquidation(update)
+			}
+		default:
+			return
+		}
+	}
+}
+
+// WaitIdle ждёт завершения всех входов и выходов
+func (e *Engine) WaitIdle(ctx context.Context) error {
+	return e.waitNoTransitions(ctx, time.Millisecond)
+}
+
+func toAnalyzerLevels(levels []exchange.PriceLevel) []PriceLevel {
+	result := make([]PriceLevel, len(levels))
+	for i, level := range levels {
+		result[i] = PriceLevel{Price: level.Price, Volume: level.Volume}
+	}
+	return result
+}
+
 // OnPriceUpdate - публичный метод для приема ценовых обновлений
 // Использует sync.Pool для zero-allocation
 func (e *Engine) OnPriceUpdate(exchange, symbol string, bidPrice, askPrice float64, timestamp time.Time) {
@@ -1733,6 +2283,7 @@ func (e *Engine) executeForceClose(ps *PairState) {
 		ps.Runtime.Legs = nil
 		ps.Runtime.State = models.StatePaused
 		ps.Runtime.FilledParts = 0
+		ps.Runtime.EntryTime = nil
 		e.decrementActiveArbs()
 	} else {
 		// Ошибка закрытия - переводим в ERROR
@@ -1760,6 +2311,13 @@ func (e *Engine) UpdatePairConfig(pairID int, cfg *models.PairConfig) {
 	ps.Config.VolumeAsset = cfg.VolumeAsset
 	ps.Config.NOrders = cfg.NOrders
 	ps.Config.StopLoss = cfg.StopLoss
+	ps.Config.EntryMode = cfg.EntryMode
+	ps.Config.Strategy = cfg.Strategy
+	ps.Config.FundingDiffPct = cfg.FundingDiffPct
+	ps.Config.MaxHoldHours = cfg.MaxHoldHours
+	ps.Config.Leverage = cfg.Leverage
+	ps.Config.MarginMode = cfg.MarginMode
+	ps.Config.Accounts = cfg.Accounts
 	e.spreadCalc.SetDefaultVolume(cfg.Symbol, cfg.VolumeAsset)
 
 	// ОПТИМИЗАЦИЯ: обновляем atomic копии для lock-free чтения в горячем пути
@@ -1767,6 +2325,24 @@ func (e *Engine) UpdatePairConfig(pairID int, cfg *models.PairConfig) {
 	ps.setEntrySpread(cfg.EntrySpreadPct)
 	ps.setExitSpread(cfg.ExitSpreadPct)
 	ps.setStopLoss(cfg.StopLoss)
+	ps.setEntryMode(cfg.EntryMode)
+	ps.setAccounts(cfg.Accounts)
+
+	wasFunding := ps.IsFundingStrategy()
+	ps.setStrategy(cfg)
+	if cfg.IsFundingStrategy() && !wasFunding && !e.spreadCalc.IsFundingConsidered() {
+		go e.updateFundingRates([]string{cfg.Symbol})
+	}
+
+	// Настройки маржи (и новые аккаунты) сверяются на биржах перед следующим входом,
+	// несменившиеся значения берутся из кэша без запросов
+	prevMargin := ps.GetMarginSettings()
+	ps.setMarginSettings(cfg)
+	if ps.GetMarginSettings() != prevMargin {
+		e.orderExec.ResetMarginSettings(cfg.Symbol)
+		e.resetPairMargin(func(other *PairState) bool { return other.Config.Symbol == cfg.Symbol })
+	}
+	atomic.StoreInt32(&ps.marginState, marginPending)
 }
 
 // HasOpenPosition проверяет, есть ли открытая позиция у пары
@@ -0,0 +1,164 @@
+package bot
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"arbitrage/internal/config"
+	"arbitrage/internal/exchange"
+	"arbitrage/internal/models"
+)
+
+func newTestEngine() *Engine {
+	return NewEngine(&config.Config{Bot: defaultBotConfig()}, nil)
+}
+
+// TestEngineDrain_BlocksNewEntries проверяет, что после Drain новые входы запрещены
+func TestEngineDrain_BlocksNewEntries(t *testing.T) {
+	e := newTestEngine()
+
+	if !e.canOpenNewArbitrage() {
+		t.Fatal("expected new arbitrage to be allowed before drain")
+	}
+
+	if err := e.Drain(context.Background()); err != nil {
+		t.Fatalf("Drain without pairs returned error: %v", err)
+	}
+
+	if e.canOpenNewArbitrage() {
+		t.Fatal("expected new arbitrage to be blocked after drain")
+	}
+}
+
+// TestEngineDrain_WaitsForExiting проверяет ожидание пар в EXITING
+func TestEngineDrain_WaitsForExiting(t *testing.T) {
+	e := newTestEngine()
+	e.AddPair(&models.PairConfig{ID: 1, Symbol: "BTCUSDT", VolumeAsset: 1})
+
+	e.pairsMu.RLock()
+	ps := e.pairs[1]
+	e.pairsMu.RUnlock()
+
+	ps.mu.Lock()
+	ps.Runtime.State = models.StateExiting
+	ps.mu.Unlock()
+
+	go func() {
+		time.Sleep(150 * time.Millisecond)
+		ps.mu.Lock()
+		ps.Runtime.State = models.StatePaused
+		ps.mu.Unlock()
+	}()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	if err := e.Drain(ctx); err != nil {
+		t.Fatalf("expected drain to finish after exit completed, got %v", err)
+	}
+}
+
+// TestEngineDrain_Timeout проверяет возврат ошибки, если пара застряла в ENTERING
+func TestEngineDrain_Timeout(t *testing.T) {
+	e := newTestEngine()
+	e.AddPair(&models.PairConfig{ID: 1, Symbol: "ETHUSDT", VolumeAsset: 1})
+
+	e.pairsMu.RLock()
+	ps := e.pairs[1]
+	e.pairsMu.RUnlock()
+
+	ps.mu.Lock()
+	ps.Runtime.State = models.StateEntering
+	ps.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+
+	if err := e.Drain(ctx); err != context.DeadlineExceeded {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+}
+
+// TestEngineRemoveExchange проверяет удаление биржи из движка
+func TestEngineRemoveExchange(t *testing.T) {
+	e := newTestEngine()
+	e.AddExchange("bybit", newMockExchangeBench("bybit", 0))
+
+	if _, ok := e.GetExchanges()["bybit"]; !ok {
+		t.Fatal("expected bybit to be registered")
+	}
+
+	e.RemoveExchange("bybit")
+
+	if _, ok := e.GetExchanges()["bybit"]; ok {
+		t.Fatal("expected bybit to be removed")
+	}
+}
+
+// TestEngine_ExchangeChangesDuringExecute проверяет, что подключение и отключение биржи
+// через движок не гоняется с чтением карты бирж исполнителем (запускать с -race)
+func TestEngine_ExchangeChangesDuringExecute(t *testing.T) {
+	e := newTestEngine()
+	e.AddExchange("race-long", newMakerTestSim("race-long", 99, 100))
+	e.AddExchange("race-short", newMakerTestSim("race-short", 101, 102))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 50; i++ {
+			e.AddExchange("race-extra", newMakerTestSim("race-extra", 99, 100))
+			e.RemoveExchange("race-extra")
+		}
+	}()
+
+	for i := 0; i < 20; i++ {
+		result := e.orderExec.ExecuteParallel(context.Background(), ExecuteParams{
+			Symbol:        "BTCUSDT",
+			Volume:        0.01,
+			LongExchange:  "race-long",
+			ShortExchange: "race-short",
+			NOrders:       1,
+			Attempt:       i,
+		})
+		if !result.Success {
+			t.Fatalf("entry %d failed: %v", i, result.Error)
+		}
+	}
+	<-done
+
+	e.RemoveExchange("race-long")
+	result := e.orderExec.ExecuteParallel(context.Background(), ExecuteParams{
+		Symbol:        "BTCUSDT",
+		Volume:        0.01,
+		LongExchange:  "race-long",
+		ShortExchange: "race-short",
+		NOrders:       1,
+	})
+	if result.Success {
+		t.Fatal("expected entry to fail on exchange removed from engine")
+	}
+}
+
+// TestEngine_OrderBookStreamFeedsAnalyzer проверяет, что поток стаканов попадает в анализатор ликвидности
+func TestEngine_OrderBookStreamFeedsAnalyzer(t *testing.T) {
+	e := newTestEngine()
+	sim := exchange.NewSim(exchange.DefaultSimConfig("bybit"))
+	e.AddExchange("bybit", sim)
+	e.AddPair(&models.PairConfig{ID: 1, Symbol: "BTCUSDT", VolumeAsset: 1})
+
+	if e.orderBookAnalyzer.GetOrderBook("BTCUSDT", "bybit") != nil {
+		t.Fatal("expected no order book before the first update")
+	}
+
+	sim.SetOrderBook(&exchange.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   []exchange.PriceLevel{{Price: 99, Volume: 2}, {Price: 98, Volume: 3}},
+		Asks:   []exchange.PriceLevel{{Price: 101, Volume: 1}},
+	})
+
+	book := e.orderBookAnalyzer.GetOrderBook("BTCUSDT", "bybit")
+	if book == nil || len(book.Bids) != 2 || book.Bids[0].Price != 99 || book.Asks[0].Volume != 1 {
+		t.Fatalf("expected live order book in analyzer, got %+v", book)
+	}
+}
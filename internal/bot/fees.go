@@ -0,0 +1,131 @@
+package bot
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"arbitrage/internal/exchange"
+	"arbitrage/pkg/utils"
+)
+
+// Комиссии по умолчанию, пока не получены ставки аккаунта
+const (
+	defaultTakerFee = 0.0005 // 0.05%
+	defaultMakerFee = 0.0002 // 0.02%
+)
+
+// defaultFeeRefreshInterval - период обновления комиссий, если не задан в конфиге
+const defaultFeeRefreshInterval = time.Hour
+
+// feeReferenceSymbol - символ для запроса комиссий, пока у движка нет пар
+const feeReferenceSymbol = "BTCUSDT"
+
+// ============================================================
+// Комиссии аккаунта в чистом спреде
+// ============================================================
+
+// takerFees возвращает комиссии тейкера двух бирж
+// Нулевая комиссия (акция биржи) - валидная ставка, дефолт только для неизвестной биржи
+func (sc *SpreadCalculator) takerFees(first, second string) (float64, float64) {
+	sc.feesMu.RLock()
+	defer sc.feesMu.RUnlock()
+	return sc.takerFeeLocked(first), sc.takerFeeLocked(second)
+}
+
+func (sc *SpreadCalculator) takerFeeLocked(exch string) float64 {
+	if fee, ok := sc.fees[exch]; ok {
+		return fee
+	}
+	return defaultTakerFee
+}
+
+// makerFee возвращает комиссию мейкера биржи (отрицательная - ребейт)
+func (sc *SpreadCalculator) makerFee(exch string) float64 {
+	sc.feesMu.RLock()
+	defer sc.feesMu.RUnlock()
+	if fee, ok := sc.makerFees[exch]; ok {
+		return fee
+	}
+	return defaultMakerFee
+}
+
+// SetTradingFees сохраняет комиссии мейкера и тейкера биржи
+func (sc *SpreadCalculator) SetTradingFees(exch string, fees *exchange.TradingFees) {
+	if fees == nil {
+		return
+	}
+
+	sc.feesMu.Lock()
+	sc.fees[exch] = fees.Taker
+	sc.makerFees[exch] = fees.Maker
+	sc.feesMu.Unlock()
+}
+
+// RemoveFees удаляет комиссии биржи (при её отключении)
+func (sc *SpreadCalculator) RemoveFees(exch string) {
+	sc.feesMu.Lock()
+	delete(sc.fees, exch)
+	delete(sc.makerFees, exch)
+	sc.feesMu.Unlock()
+}
+
+// updateTradingFees запрашивает комиссии аккаунта на всех биржах
+// Ошибки не критичны: для биржи остаётся предыдущая комиссия
+func (e *Engine) updateTradingFees() {
+	var wg sync.WaitGroup
+	for name, exch := range e.GetExchanges() {
+		wg.Add(1)
+		go func(exchName string, ex exchange.Exchange) {
+			defer wg.Done()
+			e.refreshTradingFees(exchName, ex)
+		}(name, exch)
+	}
+	wg.Wait()
+}
+
+// refreshTradingFees запрашивает комиссии одной биржи и передаёт их в SpreadCalculator
+func (e *Engine) refreshTradingFees(exchName string, ex exchange.Exchange) {
+	symbol := e.feeSymbol()
+
+	ctx, cancel := context.WithTimeout(e.ctx, 5*time.Second)
+	fees, err := ex.GetTradingFees(ctx, symbol)
+	cancel()
+
+	if err != nil {
+		utils.Debugf("trading fees %s on %s: %v", symbol, exchName, err)
+		return
+	}
+
+	// Биржа могла быть отключена, пока шёл запрос
+	e.exchMu.RLock()
+	current := e.exchanges[exchName]
+	e.exchMu.RUnlock()
+	if current != ex {
+		return
+	}
+
+	e.spreadCalc.SetTradingFees(exchName, fees)
+	utils.Debugf("trading fees on %s: maker %.4f%% taker %.4f%% tier %q",
+		exchName, fees.Maker*100, fees.Taker*100, fees.Tier)
+}
+
+// feeSymbol возвращает символ для запроса комиссий
+// Ставки аккаунта общие для линейных контрактов, поэтому достаточно одного символа
+func (e *Engine) feeSymbol() string {
+	symbols := e.pairSymbols()
+	if len(symbols) == 0 {
+		return feeReferenceSymbol
+	}
+	sort.Strings(symbols)
+	return symbols[0]
+}
+
+// feeRefreshInterval возвращает период обновления комиссий
+func (e *Engine) feeRefreshInterval() time.Duration {
+	if e.cfg.Bot.FeeRefreshInterval > 0 {
+		return e.cfg.Bot.FeeRefreshInterval
+	}
+	return defaultFeeRefreshInterval
+}
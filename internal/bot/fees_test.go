@@ -0,0 +1,44 @@
+package bot
+
+import (
+	"math"
+	"testing"
+
+	"arbitrage/internal/exchange"
+)
+
+// TestUpdateTradingFees_AccountRates проверяет, что комиссии аккаунта попадают в чистый спред
+func TestUpdateTradingFees_AccountRates(t *testing.T) {
+	e := newTestEngine()
+
+	// VIP-аккаунт: тейкер без комиссии, мейкер с ребейтом
+	vipCfg := exchange.DefaultSimConfig("bybit")
+	vipCfg.TakerFee = 0
+	vipCfg.MakerFee = -0.0001
+	e.AddExchange("bybit", exchange.NewSim(vipCfg))
+
+	okxCfg := exchange.DefaultSimConfig("okx")
+	okxCfg.TakerFee = 0.0003
+	e.AddExchange("okx", exchange.NewSim(okxCfg))
+
+	e.updateTradingFees()
+
+	// 2 * (0 + 0.03%) = 0.06% вместо дефолтных 0.2%
+	net := e.spreadCalc.calculateNetSpreadFromPrices(1.0, "bybit", "okx")
+	if math.Abs(net-0.94) > 1e-9 {
+		t.Fatalf("expected net spread 0.94, got %v", net)
+	}
+
+	// мейкер -0.01% + тейкер 0 + хедж 2 * 0.03%
+	fees := e.spreadCalc.MakerTakerFees("bybit", "okx")
+	if math.Abs(fees-0.05) > 1e-9 {
+		t.Fatalf("expected maker_taker fees 0.05, got %v", fees)
+	}
+
+	// После отключения биржи снова действуют дефолты
+	e.RemoveExchange("bybit")
+	net = e.spreadCalc.calculateNetSpreadFromPrices(1.0, "bybit", "okx")
+	if math.Abs(net-(1.0-2*(defaultTakerFee+0.0003)*100)) > 1e-9 {
+		t.Fatalf("expected default bybit fee after removal, got net %v", net)
+	}
+}
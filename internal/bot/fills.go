@@ -0,0 +1,398 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"arbitrage/internal/exchange"
+	"arbitrage/pkg/utils"
+)
+
+// ============================================================
+// Подтверждение исполнения через поток ордеров (SubscribeOrders)
+// ============================================================
+//
+// REST ответ на рыночный ордер у части бирж приходит до исполнения
+// (нулевой filled qty, нет средней цены). Поток ордеров сообщает
+// фактическое исполнение: объём, среднюю цену, комиссию и отклонения.
+// OrderExecutor сверяет с ним размер ног, а после таймаута входа ищет
+// исполнения, ответ на которые не был получен (ghost fills).
+
+const (
+	// defaultFillConfirmTimeout - ожидание итогового статуса, если не задано в конфиге
+	defaultFillConfirmTimeout = time.Second
+
+	// orderUpdateTTL - сколько хранить обновления ордеров
+	orderUpdateTTL = 10 * time.Minute
+
+	// ghostFillWindow - сколько ждать обновлений потока после таймаута входа
+	ghostFillWindow = 3 * time.Second
+
+	// ghostFillClockSkew - допуск расхождения часов биржи при поиске ghost fills
+	ghostFillClockSkew = time.Second
+)
+
+// orderUpdateKey - ключ ордера в трекере
+type orderUpdateKey struct {
+	Exchange string
+	OrderID  string
+}
+
+// trackedOrder - последнее известное состояние ордера
+type trackedOrder struct {
+	order      exchange.Order
+	claimed    bool // ордер известен исполнителю (получен ответ на размещение)
+	receivedAt time.Time
+}
+
+// OrderTracker хранит обновления ордеров из потоков бирж и будит ожидающих
+type OrderTracker struct {
+	mu      sync.Mutex
+	orders  map[orderUpdateKey]*trackedOrder
+	waiters map[orderUpdateKey][]chan struct{}
+	now     func() time.Time
+}
+
+// NewOrderTracker создаёт трекер ордеров
+func NewOrderTracker() *OrderTracker {
+	return &OrderTracker{
+		orders:  make(map[orderUpdateKey]*trackedOrder),
+		waiters: make(map[orderUpdateKey][]chan struct{}),
+		now:     time.Now,
+	}
+}
+
+// Update сохраняет обновление ордера биржи
+// Обновления могут приходить не по порядку: исполненный объём не уменьшается
+func (t *OrderTracker) Update(exchName string, order *exchange.Order) {
+	if order == nil || order.ID == "" {
+		return
+	}
+
+	key := orderUpdateKey{Exchange: exchName, OrderID: order.ID}
+	now := t.now()
+
+	t.mu.Lock()
+	tracked, ok := t.orders[key]
+	if !ok {
+		tracked = &trackedOrder{}
+		t.orders[key] = tracked
+	}
+	if !ok || order.FilledQty >= tracked.order.FilledQty {
+		tracked.order = *order
+	}
+	tracked.receivedAt = now
+
+	waiters := t.waiters[key]
+	delete(t.waiters, key)
+
+	t.evictLocked(now)
+	t.mu.Unlock()
+
+	for _, ch := range waiters {
+		close(ch)
+	}
+}
+
+// Claim помечает ордер как известный исполнителю
+// Неотмеченные исполнения после таймаута входа считаются ghost fills
+func (t *OrderTracker) Claim(exchName, orderID string) {
+	if orderID == "" {
+		return
+	}
+
+	key := orderUpdateKey{Exchange: exchName, OrderID: orderID}
+
+	t.mu.Lock()
+	tracked, ok := t.orders[key]
+	if !ok {
+		tracked = &trackedOrder{receivedAt: t.now()}
+		tracked.order.ID = orderID
+		t.orders[key] = tracked
+	}
+	tracked.claimed = true
+	t.mu.Unlock()
+}
+
+// Get возвращает последнее состояние ордера из потока или nil
+func (t *OrderTracker) Get(exchName, orderID string) *exchange.Order {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	tracked, ok := t.orders[orderUpdateKey{Exchange: exchName, OrderID: orderID}]
+	if !ok || tracked.order.Status == "" {
+		return nil
+	}
+	order := tracked.order
+	return &order
+}
+
+// WaitFinal ждёт итогового статуса ордера (filled, cancelled, rejected)
+// Возвращает последнее известное состояние (nil, если обновлений не было)
+func (t *OrderTracker) WaitFinal(ctx context.Context, exchName, orderID string) *exchange.Order {
+	key := orderUpdateKey{Exchange: exchName, OrderID: orderID}
+
+	for {
+		t.mu.Lock()
+		var last *exchange.Order
+		if tracked, ok := t.orders[key]; ok && tracked.order.Status != "" {
+			order := tracked.order
+			last = &order
+		}
+		if last != nil && isFinalOrderStatus(last.Status) {
+			t.mu.Unlock()
+			return last
+		}
+		ch := make(chan struct{})
+		t.waiters[key] = append(t.waiters[key], ch)
+		t.mu.Unlock()
+
+		select {
+		case <-ch:
+		case <-ctx.Done():
+			t.removeWaiter(key, ch)
+			return last
+		}
+	}
+}
+
+// UnclaimedFills возвращает исполненные ордера биржи по символу и стороне,
+// созданные в окне [from, to] и не отмеченные через Claim
+func (t *OrderTracker) UnclaimedFills(exchName, symbol, side string, from, to time.Time) []*exchange.Order {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var fills []*exchange.Order
+	for key, tracked := range t.orders {
+		order := tracked.order
+		if key.Exchange != exchName || tracked.claimed || order.Symbol != symbol || order.Side != side {
+			continue
+		}
+		if order.FilledQty <= 0 || order.CreatedAt.Before(from) || order.CreatedAt.After(to) {
+			continue
+		}
+		fills = append(fills, &order)
+	}
+	return fills
+}
+
+// removeWaiter убирает канал ожидания после отмены контекста
+func (t *OrderTracker) removeWaiter(key orderUpdateKey, ch chan struct{}) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	waiters := t.waiters[key]
+	for i, w := range waiters {
+		if w == ch {
+			t.waiters[key] = append(waiters[:i], waiters[i+1:]...)
+			break
+		}
+	}
+	if len(t.waiters[key]) == 0 {
+		delete(t.waiters, key)
+	}
+}
+
+// evictLocked удаляет устаревшие обновления (вызывать под t.mu)
+func (t *OrderTracker) evictLocked(now time.Time) {
+	for key, tracked := range t.orders {
+		if now.Sub(tracked.receivedAt) > orderUpdateTTL {
+			delete(t.orders, key)
+		}
+	}
+}
+
+// isFinalOrderStatus возвращает true для статусов, после которых ордер не меняется
+func isFinalOrderStatus(status string) bool {
+	switch status {
+	case exchange.OrderStatusFilled, exchange.OrderStatusCancelled, exchange.OrderStatusRejected:
+		return true
+	}
+	return false
+}
+
+// ============ Интеграция с OrderExecutor ============
+
+// OnOrderUpdate принимает обновление ордера из потока биржи
+func (oe *OrderExecutor) OnOrderUpdate(exchName string, order *exchange.Order) {
+	oe.fills.Update(exchName, order)
+}
+
+// fillConfirmTimeout возвращает время ожидания подтверждения исполнения
+func (oe *OrderExecutor) fillConfirmTimeout() time.Duration {
+	if oe.cfg.FillConfirmTimeout > 0 {
+		return oe.cfg.FillConfirmTimeout
+	}
+	return defaultFillConfirmTimeout
+}
+
+// confirmFill уточняет исполнение ордера по потоку ордеров
+//
+// Ордер с итоговым статусом, объёмом и ценой возвращается как есть (дополняется
+// комиссией из потока). Иначе ждём итоговый статус из потока до FillConfirmTimeout,
+// а если поток молчит - запрашиваем ордер через REST. Отмена ctx прерывает оба шага.
+func (oe *OrderExecutor) confirmFill(ctx context.Context, exchName string, exch exchange.Exchange, order *exchange.Order) *exchange.Order {
+	if order.ID == "" {
+		return order
+	}
+
+	if order.Status == exchange.OrderStatusFilled && order.FilledQty > 0 && order.AvgFillPrice > 0 {
+		if streamed := oe.fills.Get(exchName, order.ID); streamed != nil && order.Fee == 0 {
+			confirmed := *order
+			confirmed.Fee = streamed.Fee
+			return &confirmed
+		}
+		return order
+	}
+
+	waitCtx, cancel := context.WithTimeout(ctx, oe.fillConfirmTimeout())
+	streamed := oe.fills.WaitFinal(waitCtx, exchName, order.ID)
+	cancel()
+
+	if streamed == nil || !isFinalOrderStatus(streamed.Status) {
+		restCtx, restCancel := context.WithTimeout(ctx, oe.cfg.OrderTimeout)
+		fetched, err := exch.GetOrder(restCtx, order.Symbol, order.ID)
+		restCancel()
+
+		if err == nil && fetched != nil && (streamed == nil || fetched.FilledQty >= streamed.FilledQty) {
+			streamed = fetched
+		}
+	}
+	if streamed == nil {
+		return order
+	}
+
+	confirmed := *order
+	confirmed.Status = streamed.Status
+	if streamed.FilledQty > 0 {
+		confirmed.FilledQty = streamed.FilledQty
+	}
+	if streamed.AvgFillPrice > 0 {
+		confirmed.AvgFillPrice = streamed.AvgFillPrice
+	}
+	if streamed.Fee != 0 {
+		confirmed.Fee = streamed.Fee
+	}
+	return &confirmed
+}
+
+// confirmLeg подтверждает исполнение ноги
+// Ордер без исполнения с итоговым статусом (отклонён, отменён) считается ошибкой ноги
+func (oe *OrderExecutor) confirmLeg(ctx context.Context, exchName string, exch exchange.Exchange, res LegResult) LegResult {
+	if res.Error != nil || res.Order == nil {
+		return res
+	}
+
+	order := oe.confirmFill(ctx, exchName, exch, res.Order)
+	if order.FilledQty <= 0 && (order.Status == exchange.OrderStatusRejected || order.Status == exchange.OrderStatusCancelled) {
+		return LegResult{
+			Order: order,
+			Error: fmt.Errorf("order %s on %s %s without fill", order.ID, exchName, order.Status),
+		}
+	}
+	return LegResult{Order: order}
+}
+
+// confirmLegs подтверждает исполнение двух ног параллельно: ожидание потока и REST
+// запрос одной ноги не задерживают другую
+func (oe *OrderExecutor) confirmLegs(ctx context.Context, exchName1 string, exch1 exchange.Exchange, res1 LegResult, exchName2 string, exch2 exchange.Exchange, res2 LegResult) (LegResult, LegResult) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		res1 = oe.confirmLeg(ctx, exchName1, exch1, res1)
+	}()
+	res2 = oe.confirmLeg(ctx, exchName2, exch2, res2)
+	<-done
+	return res1, res2
+}
+
+// reconcileGhostFills закрывает исполнения ноги, ответ на которые не был получен
+//
+// Вызывается после таймаута входа для ноги без ответа: ордер мог исполниться
+// на бирже уже после отмены контекста. Ждёт ghostFillWindow обновлений потока
+// и закрывает неотмеченные исполнения той же стороны, созданные до таймаута.
+func (oe *OrderExecutor) reconcileGhostFills(exchName string, exch exchange.Exchange, symbol, side, legID string, from, to time.Time) {
+	time.Sleep(ghostFillWindow)
+	oe.closeGhostFills(exchName, exch, symbol, side, legID, from, to)
+}
+
+// closeGhostFills закрывает неотмеченные исполнения ордеров ноги legID и её повторов
+// Исполнения с чужим клиентским ID (ручная торговля, другие пары) не трогаются
+func (oe *OrderExecutor) closeGhostFills(exchName string, exch exchange.Exchange, symbol, side, legID string, from, to time.Time) {
+	fills := oe.fills.UnclaimedFills(exchName, symbol, side, from.Add(-ghostFillClockSkew), to.Add(ghostFillClockSkew))
+	for _, order := range fills {
+		clientID, ok := matchLegClientID(exch, order.ClientOrderID, legID, oe.cfg.MaxRetries)
+		if !ok {
+			continue
+		}
+		// Откат получает ID ордера ноги с суффиксом x, а не производный ID биржи
+		order.ClientOrderID = clientID
+
+		positionSide := exchange.SideLong
+		if side == exchange.SideSell {
+			positionSide = exchange.SideShort
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), oe.cfg.OrderTimeout)
+		_, err := exchange.PlaceCloseOrder(ctx, exch, symbol, positionSide, order.FilledQty, rollbackClientID(order))
+		cancel()
+
+		if logger := utils.GetGlobalLogger(); logger != nil {
+			if err != nil {
+				logger.Sugar().Errorf("ghost fill %s on %s (%s %.8f %s) not closed: %v",
+					order.ID, exchName, side, order.FilledQty, symbol, err)
+			} else {
+				logger.Sugar().Warnf("ghost fill %s on %s (%s %.8f %s) closed",
+					order.ID, exchName, side, order.FilledQty, symbol)
+			}
+		}
+		if err == nil {
+			oe.fills.Claim(exchName, order.ID)
+		}
+	}
+}
+
+// reconcileTimedOutLeg обрабатывает ногу, ответ на которую не пришёл до таймаута входа
+// Запоздавший успешный ответ откатывается, при его отсутствии ищутся ghost fills
+func (oe *OrderExecutor) reconcileTimedOutLeg(params ExecuteParams, leg, exchName string, exch exchange.Exchange, side string, ch chan LegResult, from, to time.Time) {
+	symbol := params.Symbol
+
+	var late LegResult
+	select {
+	case late = <-ch:
+	default:
+	}
+
+	// Ответ потерян - ордер мог быть принят биржей, ищем его по клиентскому ID
+	if (late.Error != nil || late.Order == nil) && late.ClientOrderID != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), oe.cfg.OrderTimeout)
+		placed, err := oe.findPlaced(ctx, exch, symbol, late.ClientOrderID)
+		cancel()
+		if err == nil && placed != nil {
+			oe.fills.Claim(exchName, placed.ID)
+			late = LegResult{Order: placed, ClientOrderID: late.ClientOrderID}
+		}
+	}
+
+	if late.Error != nil || late.Order == nil {
+		oe.reconcileGhostFills(exchName, exch, symbol, side, legClientID(params, leg), from, to)
+		return
+	}
+
+	order := oe.confirmFill(context.Background(), exchName, exch, late.Order)
+
+	var err error
+	if side == exchange.SideBuy {
+		err = oe.rollbackLong(symbol, exch, order)
+	} else {
+		err = oe.rollbackShort(symbol, exch, order)
+	}
+
+	if err != nil {
+		if logger := utils.GetGlobalLogger(); logger != nil {
+			logger.Sugar().Errorf("late order %s on %s not rolled back: %v", order.ID, exchName, err)
+		}
+	}
+}
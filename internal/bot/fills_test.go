@@ -0,0 +1,257 @@
+package bot
+
+import (
+	"context"
+	"hash/crc32"
+	"math"
+	"strconv"
+	"testing"
+	"time"
+
+	"arbitrage/internal/config"
+	"arbitrage/internal/exchange"
+)
+
+// staleRestExchange возвращает ответ на рыночный ордер до исполнения,
+// как REST API части бирж; фактическое исполнение приходит только в поток ордеров
+type staleRestExchange struct {
+	*exchange.Sim
+}
+
+func (s *staleRestExchange) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*exchange.Order, error) {
+	order, err := s.Sim.PlaceMarketOrder(ctx, symbol, side, qty, clientOrderID)
+	if err != nil {
+		return nil, err
+	}
+	return &exchange.Order{
+		ID:            order.ID,
+		ClientOrderID: clientOrderID,
+		Symbol:        symbol,
+		Side:          side,
+		Type:          exchange.OrderTypeMarket,
+		Quantity:      qty,
+		Status:        exchange.OrderStatusNew,
+	}, nil
+}
+
+func (s *staleRestExchange) GetOrder(ctx context.Context, symbol, orderID string) (*exchange.Order, error) {
+	return nil, context.DeadlineExceeded
+}
+
+func newFillsTestExecutor(exchanges map[string]exchange.Exchange) *OrderExecutor {
+	return NewOrderExecutor(exchanges, config.BotConfig{
+		MaxRetries:         1,
+		RetryBackoff:       time.Millisecond,
+		OrderTimeout:       time.Second,
+		FillConfirmTimeout: 100 * time.Millisecond,
+	})
+}
+
+// TestExecuteParallel_ConfirmsFillsFromOrderStream проверяет размер ноги по потоку ордеров
+func TestExecuteParallel_ConfirmsFillsFromOrderStream(t *testing.T) {
+	long := &staleRestExchange{Sim: newMakerTestSim("bybit", 99, 100)}
+	short := newMakerTestSim("okx", 101, 102)
+	oe := newFillsTestExecutor(map[string]exchange.Exchange{"bybit": long, "okx": short})
+
+	long.SubscribeOrders(func(order *exchange.Order) { oe.OnOrderUpdate("bybit", order) })
+
+	result := oe.ExecuteParallel(context.Background(), ExecuteParams{
+		Symbol:        "BTCUSDT",
+		Volume:        1,
+		LongExchange:  "bybit",
+		ShortExchange: "okx",
+		NOrders:       1,
+	})
+
+	if !result.Success {
+		t.Fatalf("expected success, got error: %v", result.Error)
+	}
+	if result.Legs[0].Quantity != 1 || result.Legs[0].EntryPrice != 100 {
+		t.Fatalf("expected long leg 1 @ 100, got %.4f @ %.4f", result.Legs[0].Quantity, result.Legs[0].EntryPrice)
+	}
+	if result.LongOrder.Fee <= 0 {
+		t.Fatalf("expected fee from order stream, got %.6f", result.LongOrder.Fee)
+	}
+}
+
+// partialFillExchange исполняет рыночный ордер частично, остаток отменяется (как IOC)
+type partialFillExchange struct {
+	*exchange.Sim
+	ratio float64
+}
+
+func (p *partialFillExchange) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*exchange.Order, error) {
+	order, err := p.Sim.PlaceMarketOrder(ctx, symbol, side, qty*p.ratio, clientOrderID)
+	if err != nil {
+		return nil, err
+	}
+	partial := *order
+	partial.Quantity = qty
+	partial.Status = exchange.OrderStatusCancelled
+	return &partial, nil
+}
+
+// TestExecuteParallel_TrimsUnequalLegs проверяет, что излишек большей ноги закрывается
+// и обе ноги позиции получают меньший объём
+func TestExecuteParallel_TrimsUnequalLegs(t *testing.T) {
+	long := newMakerTestSim("bybit", 99, 100)
+	short := &partialFillExchange{Sim: newMakerTestSim("okx", 101, 102), ratio: 0.6}
+	oe := newFillsTestExecutor(map[string]exchange.Exchange{"bybit": long, "okx": short})
+
+	params := ExecuteParams{
+		Symbol:        "BTCUSDT",
+		Volume:        1,
+		LongExchange:  "bybit",
+		ShortExchange: "okx",
+		NOrders:       1,
+		EntryAt:       time.Now(),
+	}
+	result := oe.ExecuteParallel(context.Background(), params)
+
+	if !result.Success {
+		t.Fatalf("expected success, got error: %v", result.Error)
+	}
+	if math.Abs(result.Legs[0].Quantity-0.6) > 1e-9 || math.Abs(result.Legs[1].Quantity-0.6) > 1e-9 {
+		t.Fatalf("expected both legs 0.6, got long %.4f short %.4f", result.Legs[0].Quantity, result.Legs[1].Quantity)
+	}
+	if size := positionSize(t, long); math.Abs(size-0.6) > 1e-9 {
+		t.Fatalf("expected long position trimmed to 0.6, got %.4f", size)
+	}
+	if _, err := long.GetOrderByClientID(context.Background(), "BTCUSDT", legClientID(params, legLong)+"x"); err != nil {
+		t.Fatalf("expected trim order with rollback client id: %v", err)
+	}
+}
+
+// TestExecuteParallel_ConfirmsLegsInParallel проверяет, что ожидание подтверждения
+// ног не складывается: обе ноги без потока ждут FillConfirmTimeout одновременно
+func TestExecuteParallel_ConfirmsLegsInParallel(t *testing.T) {
+	long := &staleRestExchange{Sim: newMakerTestSim("bybit", 99, 100)}
+	short := &staleRestExchange{Sim: newMakerTestSim("okx", 101, 102)}
+	oe := NewOrderExecutor(map[string]exchange.Exchange{"bybit": long, "okx": short}, config.BotConfig{
+		MaxRetries:         1,
+		RetryBackoff:       time.Millisecond,
+		OrderTimeout:       time.Second,
+		FillConfirmTimeout: 300 * time.Millisecond,
+	})
+
+	start := time.Now()
+	oe.ExecuteParallel(context.Background(), ExecuteParams{
+		Symbol:        "BTCUSDT",
+		Volume:        1,
+		LongExchange:  "bybit",
+		ShortExchange: "okx",
+		NOrders:       1,
+	})
+
+	if elapsed := time.Since(start); elapsed >= 550*time.Millisecond {
+		t.Fatalf("expected legs confirmed in parallel (~300ms), took %v", elapsed)
+	}
+}
+
+// TestConfirmLeg_RejectedWithoutFill проверяет, что отклонённый ордер без исполнения - ошибка ноги
+func TestConfirmLeg_RejectedWithoutFill(t *testing.T) {
+	sim := newMakerTestSim("bybit", 99, 100)
+	oe := newFillsTestExecutor(map[string]exchange.Exchange{"bybit": sim})
+
+	oe.OnOrderUpdate("bybit", &exchange.Order{ID: "1", Symbol: "BTCUSDT", Status: exchange.OrderStatusRejected})
+
+	res := oe.confirmLeg(context.Background(), "bybit", sim, LegResult{
+		Order: &exchange.Order{ID: "1", Symbol: "BTCUSDT", Status: exchange.OrderStatusNew},
+	})
+	if res.Error == nil {
+		t.Fatal("expected error for rejected order")
+	}
+}
+
+// TestOrderTracker_UnclaimedFills проверяет поиск исполнений без ответа на размещение
+func TestOrderTracker_UnclaimedFills(t *testing.T) {
+	tracker := NewOrderTracker()
+	from := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	to := from.Add(5 * time.Second)
+
+	fill := func(id, side string, created time.Time) *exchange.Order {
+		return &exchange.Order{
+			ID: id, Symbol: "BTCUSDT", Side: side, FilledQty: 1,
+			Status: exchange.OrderStatusFilled, CreatedAt: created,
+		}
+	}
+
+	tracker.Update("bybit", fill("ghost", exchange.SideBuy, from.Add(time.Second)))
+	tracker.Update("bybit", fill("claimed", exchange.SideBuy, from.Add(time.Second)))
+	tracker.Update("bybit", fill("other-side", exchange.SideSell, from.Add(time.Second)))
+	tracker.Update("bybit", fill("too-late", exchange.SideBuy, to.Add(time.Minute)))
+	tracker.Claim("bybit", "claimed")
+
+	fills := tracker.UnclaimedFills("bybit", "BTCUSDT", exchange.SideBuy, from, to)
+	if len(fills) != 1 || fills[0].ID != "ghost" {
+		t.Fatalf("expected only ghost fill, got %+v", fills)
+	}
+}
+
+// venueIDExchange передаёт бирже производный client order ID, как HTX (число вместо ID клиента)
+type venueIDExchange struct {
+	*exchange.Sim
+}
+
+func (v *venueIDExchange) VenueClientOrderID(clientOrderID string) string {
+	return strconv.FormatUint(uint64(crc32.ChecksumIEEE([]byte(clientOrderID))), 10)
+}
+
+func (v *venueIDExchange) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*exchange.Order, error) {
+	return v.Sim.PlaceMarketOrder(ctx, symbol, side, qty, exchange.VenueClientOrderID(v, clientOrderID))
+}
+
+// TestCloseGhostFills_VenueClientIDs проверяет закрытие ghost fills биржи, возвращающей
+// client order ID в своей форме: ордера ноги и повторов узнаются по форме биржи
+func TestCloseGhostFills_VenueClientIDs(t *testing.T) {
+	exch := &venueIDExchange{Sim: newMakerTestSim("htx", 99, 100)}
+	oe := newFillsTestExecutor(map[string]exchange.Exchange{"htx": exch})
+	exch.SubscribeOrders(func(order *exchange.Order) { oe.OnOrderUpdate("htx", order) })
+
+	params := ExecuteParams{Symbol: "BTCUSDT", PairID: 7, EntryAt: time.Now()}
+	legID := legClientID(params, legLong)
+
+	from := time.Now()
+	for _, clientID := range []string{legID, retryClientID(legID, 1), "manual"} {
+		if _, err := exch.PlaceMarketOrder(context.Background(), "BTCUSDT", exchange.SideBuy, 1, clientID); err != nil {
+			t.Fatalf("PlaceMarketOrder %s: %v", clientID, err)
+		}
+	}
+
+	oe.closeGhostFills("htx", exch, "BTCUSDT", exchange.SideBuy, legID, from, time.Now())
+
+	if size := positionSize(t, exch.Sim); size != 1 {
+		t.Fatalf("expected only leg fills closed (size 1), got %.4f", size)
+	}
+	for _, clientID := range []string{legID + "x", retryClientID(legID, 1) + "x"} {
+		if _, err := exch.GetOrderByClientID(context.Background(), "BTCUSDT", exchange.VenueClientOrderID(exch, clientID)); err != nil {
+			t.Fatalf("expected ghost fill closed with client id %s: %v", clientID, err)
+		}
+	}
+}
+
+// TestCloseGhostFills_SkipsForeignFills проверяет, что закрываются только исполнения ноги без ответа
+func TestCloseGhostFills_SkipsForeignFills(t *testing.T) {
+	sim := newMakerTestSim("bybit", 99, 100)
+	oe := newFillsTestExecutor(map[string]exchange.Exchange{"bybit": sim})
+	sim.SubscribeOrders(func(order *exchange.Order) { oe.OnOrderUpdate("bybit", order) })
+
+	params := ExecuteParams{Symbol: "BTCUSDT", PairID: 7, EntryAt: time.Now()}
+	legID := legClientID(params, legLong)
+
+	from := time.Now()
+	for _, clientID := range []string{legID, retryClientID(legID, 1), "manual"} {
+		if _, err := sim.PlaceMarketOrder(context.Background(), "BTCUSDT", exchange.SideBuy, 1, clientID); err != nil {
+			t.Fatalf("PlaceMarketOrder %s: %v", clientID, err)
+		}
+	}
+
+	oe.closeGhostFills("bybit", sim, "BTCUSDT", exchange.SideBuy, legID, from, time.Now())
+
+	if size := positionSize(t, sim); size != 1 {
+		t.Fatalf("expected foreign fill to stay open (size 1), got %.4f", size)
+	}
+	if _, err := sim.GetOrderByClientID(context.Background(), "BTCUSDT", legID+"x"); err != nil {
+		t.Fatalf("expected ghost fill closed with client id %sx: %v", legID, err)
+	}
+}
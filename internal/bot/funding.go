@@ -0,0 +1,140 @@
+package bot
+
+import (
+	"sync/atomic"
+	"time"
+
+	"arbitrage/internal/exchange"
+)
+
+// defaultFundingHoldPeriod - ожидаемое время удержания позиции, если не задано в конфиге
+const defaultFundingHoldPeriod = 8 * time.Hour
+
+// ============================================================
+// Учёт фандинга в чистом спреде (Settings.ConsiderFunding)
+// ============================================================
+
+// SetConsiderFunding включает учёт ожидаемого фандинга в NetSpread
+// holdPeriod - ожидаемое время удержания позиции (<= 0 = defaultFundingHoldPeriod)
+func (sc *SpreadCalculator) SetConsiderFunding(consider bool, holdPeriod time.Duration) {
+	if holdPeriod <= 0 {
+		holdPeriod = defaultFundingHoldPeriod
+	}
+	atomic.StoreInt64(&sc.fundingHold, int64(holdPeriod))
+
+	var v int32
+	if consider {
+		v = 1
+	}
+	atomic.StoreInt32(&sc.considerFunding, v)
+}
+
+// IsFundingConsidered возвращает true если фандинг учитывается в NetSpread
+func (sc *SpreadCalculator) IsFundingConsidered() bool {
+	return atomic.LoadInt32(&sc.considerFunding) == 1
+}
+
+// SetFundingRate сохраняет ставку фандинга биржи по символу
+func (sc *SpreadCalculator) SetFundingRate(exchName string, rate *exchange.FundingRate) {
+	if rate == nil {
+		return
+	}
+
+	rateCopy := *rate
+	key := PositionKey{Account: exchName, Symbol: rate.Symbol}
+
+	sc.fundingMu.Lock()
+	// Ставка сменилась на следующий период - запоминаем прошедшее начисление
+	if prev := sc.fundingRates[key]; prev != nil && !prev.NextFundingTime.IsZero() &&
+		rateCopy.NextFundingTime.After(prev.NextFundingTime) && !prev.NextFundingTime.After(sc.now()) {
+		sc.fundingEvents[key] = FundingEvent{Time: prev.NextFundingTime, Rate: prev.Rate}
+	}
+	sc.fundingRates[key] = &rateCopy
+	sc.fundingMu.Unlock()
+}
+
+// GetFundingRate возвращает сохранённую ставку фандинга или nil
+func (sc *SpreadCalculator) GetFundingRate(exchName, symbol string) *exchange.FundingRate {
+	sc.fundingMu.RLock()
+	rate := sc.fundingRates[PositionKey{Account: exchName, Symbol: symbol}]
+	sc.fundingMu.RUnlock()
+	return rate
+}
+
+// RemoveFundingRates удаляет ставки биржи (при её отключении)
+func (sc *SpreadCalculator) RemoveFundingRates(exchName string) {
+	sc.fundingMu.Lock()
+	for key := range sc.fundingRates {
+		if key.Account == exchName {
+			delete(sc.fundingRates, key)
+		}
+	}
+	for key := range sc.fundingEvents {
+		if key.Account == exchName {
+			delete(sc.fundingEvents, key)
+		}
+	}
+	sc.fundingMu.Unlock()
+}
+
+// ExpectedFundingPct возвращает ожидаемый фандинг связки за период удержания, % от notional
+// Положительное значение - получаем, отрицательное - платим. 0 если учёт выключен
+func (sc *SpreadCalculator) ExpectedFundingPct(symbol, longExch, shortExch string) float64 {
+	if !sc.IsFundingConsidered() {
+		return 0
+	}
+	return sc.FundingOverHoldPct(symbol, longExch, shortExch, 0)
+}
+
+// FundingOverHoldPct возвращает ожидаемый фандинг связки за время удержания hold, % от notional
+// hold <= 0 - ожидаемое время удержания из конфига (FundingHoldPeriod)
+func (sc *SpreadCalculator) FundingOverHoldPct(symbol, longExch, shortExch string, hold time.Duration) float64 {
+	if hold <= 0 {
+		hold = time.Duration(atomic.LoadInt64(&sc.fundingHold))
+	}
+	now := sc.now()
+
+	// Лонг платит положительную ставку, шорт её получает
+	return (fundingSum(sc.GetFundingRate(shortExch, symbol), now, hold) -
+		fundingSum(sc.GetFundingRate(longExch, symbol), now, hold)) * 100
+}
+
+// applyFunding добавляет ожидаемый фандинг к NetSpread возможности
+func (sc *SpreadCalculator) applyFunding(opp *ArbitrageOpportunity) {
+	opp.FundingPct = sc.ExpectedFundingPct(opp.Symbol, opp.LongExchange, opp.ShortExchange)
+	opp.NetSpread += opp.FundingPct
+}
+
+// fundingSum суммирует ставки начислений, попадающих в окно (now, now+hold]
+// Первое начисление - по Rate, следующие - по PredictedRate (если есть)
+func fundingSum(rate *exchange.FundingRate, now time.Time, hold time.Duration) float64 {
+	if rate == nil || rate.NextFundingTime.IsZero() {
+		return 0
+	}
+
+	interval := rate.Interval
+	if interval <= 0 {
+		interval = exchange.DefaultFundingInterval
+	}
+
+	// Ставка могла устареть: ближайшее начисление уже прошло
+	next := rate.NextFundingTime
+	first := true
+	for !next.After(now) {
+		next = next.Add(interval)
+		first = false
+	}
+
+	var total float64
+	end := now.Add(hold)
+	for ; !next.After(end); next = next.Add(interval) {
+		r := rate.Rate
+		if !first && rate.PredictedRate != 0 {
+			r = rate.PredictedRate
+		}
+		total += r
+		first = false
+	}
+
+	return total
+}
@@ -0,0 +1,324 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"arbitrage/internal/exchange"
+	"arbitrage/internal/models"
+	"arbitrage/pkg/utils"
+)
+
+// ============================================================
+// Стратегия funding (PairConfig.Strategy = "funding")
+// ============================================================
+//
+// Дельта-нейтральная позиция: лонг на бирже с минимальной ставкой фандинга,
+// шорт - с максимальной. Вход, когда разница ставок (за 8ч) >= funding_diff
+// и ожидаемый фандинг за время удержания (max_hold_hours, иначе FUNDING_HOLD_PERIOD)
+// покрывает стоимость входа и выхода: спреды и комиссии тейкера.
+// Позиция удерживается через начисления, которые идут в реализованный PNL,
+// и закрывается при развороте разницы, по max_hold_hours или stop loss.
+//
+// Начисление сначала учитывается по оценке (ставка × размер × цена) и помечается
+// в FundingEstimated, затем заменяется фактическим из истории биржи
+// (exchange.FundingHistoryReader). Если биржа не даёт историю или начисление
+// не найдено за fundingConfirmAttempts сверок, оценка остаётся помеченной.
+
+const (
+	// fundingConfirmDelay - интервал сверки начисления с историей биржи
+	// (биржи публикуют начисление с задержкой после расчёта)
+	fundingConfirmDelay = time.Minute
+	// fundingConfirmAttempts - число сверок, после которых оценка остаётся в PNL
+	fundingConfirmAttempts = 5
+	// fundingConfirmTimeout - таймаут запроса истории начислений
+	fundingConfirmTimeout = 10 * time.Second
+	// fundingPaymentWindow - допуск времени начисления в истории относительно расчёта
+	fundingPaymentWindow = 5 * time.Minute
+)
+
+// FundingEvent - прошедшее начисление фандинга
+type FundingEvent struct {
+	Time time.Time
+	Rate float64
+}
+
+// normalizedFundingRate приводит ставку к стандартному 8-часовому периоду
+func normalizedFundingRate(rate *exchange.FundingRate) float64 {
+	if rate.Interval <= 0 || rate.Interval == exchange.DefaultFundingInterval {
+		return rate.Rate
+	}
+	return rate.Rate * float64(exchange.DefaultFundingInterval) / float64(rate.Interval)
+}
+
+// GetFundingOpportunity возвращает связку с максимальной разницей ставок фандинга
+// Лонг - биржа с минимальной ставкой, шорт - с максимальной (обе должны иметь цены).
+// RawSpread/NetSpread - стоимость входа по текущим ценам, FundingDiff - разница ставок за 8ч, %.
+// RoundTripCost - стоимость входа и выхода по текущим ценам: спреды обеих сделок и комиссии тейкера
+//
+// accounts - аккаунты пары для ног (nil - все аккаунты)
+//
+// ВАЖНО: вызывающий код ДОЛЖЕН вызвать ReleaseArbitrageOpportunity() после использования!
+func (sc *SpreadCalculator) GetFundingOpportunity(symbol string, accounts []string) *ArbitrageOpportunity {
+	var longPrice, shortPrice *ExchangePrice
+	var longRate, shortRate float64
+
+	sc.fundingMu.RLock()
+	for key, rate := range sc.fundingRates {
+		if key.Symbol != symbol {
+			continue
+		}
+
+		// Отключённая биржа и аккаунты вне пары не выбираются ногой входа
+		if !entryAllowed(key.Account, accounts) {
+			continue
+		}
+
+		price := sc.tracker.GetExchangePrice(symbol, key.Account)
+		if price == nil || price.BidPrice <= 0 || price.AskPrice <= 0 {
+			continue
+		}
+
+		r := normalizedFundingRate(rate)
+		if longPrice == nil || r < longRate {
+			longPrice, longRate = price, r
+		}
+		if shortPrice == nil || r > shortRate {
+			shortPrice, shortRate = price, r
+		}
+	}
+	sc.fundingMu.RUnlock()
+
+	if longPrice == nil || shortPrice == nil || longPrice.Exchange == shortPrice.Exchange {
+		return nil
+	}
+
+	rawSpread := (shortPrice.BidPrice - longPrice.AskPrice) / longPrice.AskPrice * 100
+
+	opp := acquireArbitrageOpportunity()
+	opp.Symbol = symbol
+	opp.LongExchange = longPrice.Exchange
+	opp.LongPrice = longPrice.AskPrice
+	opp.ShortExchange = shortPrice.Exchange
+	opp.ShortPrice = shortPrice.BidPrice
+	opp.RawSpread = rawSpread
+	opp.NetSpread = sc.calculateNetSpreadFromPrices(rawSpread, longPrice.Exchange, shortPrice.Exchange)
+	opp.FundingDiff = (shortRate - longRate) * 100
+
+	// Выход: продажа лонга по bid, откуп шорта по ask
+	exitSpread := (longPrice.BidPrice - shortPrice.AskPrice) / shortPrice.AskPrice * 100
+	opp.RoundTripCost = -(opp.NetSpread + exitSpread)
+	opp.Timestamp = longPrice.Timestamp
+
+	return opp
+}
+
+// FundingDiffPct возвращает разницу ставок шорт − лонг за 8ч, %
+// false если ставка одной из бирж неизвестна
+func (sc *SpreadCalculator) FundingDiffPct(symbol, longExch, shortExch string) (float64, bool) {
+	longRate := sc.GetFundingRate(longExch, symbol)
+	shortRate := sc.GetFundingRate(shortExch, symbol)
+	if longRate == nil || shortRate == nil {
+		return 0, false
+	}
+	return (normalizedFundingRate(shortRate) - normalizedFundingRate(longRate)) * 100, true
+}
+
+// LastFundingEvent возвращает последнее прошедшее начисление фандинга биржи по символу
+// Если время начисления сохранённой ставки уже наступило, а новая ещё не получена -
+// начислением считается сама сохранённая ставка
+func (sc *SpreadCalculator) LastFundingEvent(exchName, symbol string) (FundingEvent, bool) {
+	key := PositionKey{Account: exchName, Symbol: symbol}
+
+	sc.fundingMu.RLock()
+	defer sc.fundingMu.RUnlock()
+
+	if rate := sc.fundingRates[key]; rate != nil && !rate.NextFundingTime.IsZero() && !rate.NextFundingTime.After(sc.now()) {
+		return FundingEvent{Time: rate.NextFundingTime, Rate: rate.Rate}, true
+	}
+
+	event, ok := sc.fundingEvents[key]
+	return event, ok
+}
+
+// DetectFundingOpportunity находит связку для стратегии funding
+// ВАЖНО: вызывающий код ДОЛЖЕН вызвать ReleaseArbitrageOpportunity() после использования!
+func (ad *ArbitrageDetector) DetectFundingOpportunity(symbol string, accounts []string) *ArbitrageOpportunity {
+	return ad.spreadCalc.GetFundingOpportunity(symbol, accounts)
+}
+
+// checkFundingExit проверяет разворот разницы ставок для открытой позиции funding
+func (ad *ArbitrageDetector) checkFundingExit(symbol, longExch, shortExch string) bool {
+	diff, ok := ad.spreadCalc.FundingDiffPct(symbol, longExch, shortExch)
+	return ok && diff <= 0
+}
+
+// holdExpired возвращает true если позиция удерживается дольше max_hold_hours
+func (ad *ArbitrageDetector) holdExpired(ps *PairState) bool {
+	maxHold := ps.GetMaxHold()
+	if maxHold <= 0 || ps.Runtime.EntryTime == nil {
+		return false
+	}
+	return ad.spreadCalc.now().Sub(*ps.Runtime.EntryTime) >= maxHold
+}
+
+// fundingEstimate - начисление по ноге, учтённое по оценке до сверки с историей биржи
+type fundingEstimate struct {
+	exchange string
+	time     time.Time // время расчёта
+	amount   float64   // оценка, учтённая в PNL
+	checkAt  time.Time // время следующей сверки
+	attempts int
+	inFlight bool // идёт запрос истории
+}
+
+// settleFunding учитывает прошедшие начисления фандинга по ногам позиции
+// Платёж оценивается по ставке и текущей цене ноги: лонг платит положительную
+// ставку, шорт её получает. Оценка заменяется фактическим начислением после
+// сверки с историей биржи. Вызывается под ps.mu
+func (e *Engine) settleFunding(ps *PairState) {
+	if ps.Runtime.EntryTime == nil {
+		return
+	}
+	defer e.confirmFundingEstimates(ps)
+
+	for i := range ps.Runtime.Legs {
+		leg := &ps.Runtime.Legs[i]
+
+		event, ok := e.spreadCalc.LastFundingEvent(leg.Exchange, ps.Config.Symbol)
+		if !ok || !event.Time.After(*ps.Runtime.EntryTime) || !event.Time.After(ps.fundingSettled[leg.Exchange]) {
+			continue
+		}
+
+		if ps.fundingSettled == nil {
+			ps.fundingSettled = make(map[string]time.Time, 2)
+		}
+		ps.fundingSettled[leg.Exchange] = event.Time
+
+		payment := fundingPayment(leg, event.Rate)
+		ps.Runtime.RealizedPnl += payment
+		ps.Runtime.FundingPnl += payment
+		ps.Runtime.FundingEstimated += payment
+		ps.fundingPending = append(ps.fundingPending, &fundingEstimate{
+			exchange: leg.Exchange,
+			time:     event.Time,
+			amount:   payment,
+			checkAt:  event.Time.Add(fundingConfirmDelay),
+		})
+	}
+}
+
+// confirmFundingEstimates запускает сверку оценок, для которых подошло время
+// Без истории начислений на бирже оценка остаётся помеченной. Вызывается под ps.mu
+func (e *Engine) confirmFundingEstimates(ps *PairState) {
+	now := e.spreadCalc.now()
+	pending := ps.fundingPending[:0]
+	for _, est := range ps.fundingPending {
+		if est.inFlight || now.Before(est.checkAt) {
+			pending = append(pending, est)
+			continue
+		}
+
+		e.exchMu.RLock()
+		reader, ok := e.exchanges[est.exchange].(exchange.FundingHistoryReader)
+		e.exchMu.RUnlock()
+		if !ok {
+			continue
+		}
+
+		est.inFlight = true
+		pending = append(pending, est)
+		go e.confirmFunding(ps, reader, ps.Config.Symbol, *ps.Runtime.EntryTime, est)
+	}
+	ps.fundingPending = pending
+}
+
+// confirmFunding заменяет оценку начисления фактическим из истории биржи
+// Если начисление ещё не опубликовано, сверка повторяется через fundingConfirmDelay
+func (e *Engine) confirmFunding(ps *PairState, reader exchange.FundingHistoryReader, symbol string, entryTime time.Time, est *fundingEstimate) {
+	ctx, cancel := context.WithTimeout(e.ctx, fundingConfirmTimeout)
+	payments, err := reader.GetFundingPayments(ctx, symbol,
+		est.time.Add(-fundingPaymentWindow), est.time.Add(fundingPaymentWindow))
+	cancel()
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	est.inFlight = false
+	// Позиция закрыта или открыта заново: оценка относится к прошлой сделке
+	if ps.Runtime.EntryTime == nil || !ps.Runtime.EntryTime.Equal(entryTime) {
+		return
+	}
+
+	if err == nil && len(payments) > 0 {
+		actual := 0.0
+		for _, p := range payments {
+			actual += p.Amount
+		}
+		ps.Runtime.RealizedPnl += actual - est.amount
+		ps.Runtime.FundingPnl += actual - est.amount
+		ps.Runtime.FundingEstimated -= est.amount
+		ps.dropFundingEstimate(est)
+		return
+	}
+
+	est.attempts++
+	est.checkAt = est.checkAt.Add(fundingConfirmDelay)
+	if est.attempts < fundingConfirmAttempts {
+		return
+	}
+
+	ps.dropFundingEstimate(est)
+	if logger := utils.GetGlobalLogger(); logger != nil {
+		reason := "not found in history"
+		if err != nil {
+			reason = err.Error()
+		}
+		logger.Sugar().Warnf("funding %s on %s at %s not confirmed: %s, estimate %.4f kept",
+			symbol, est.exchange, est.time.Format(time.RFC3339), reason, est.amount)
+	}
+}
+
+// dropFundingEstimate убирает оценку из ожидающих сверки (под mu)
+func (ps *PairState) dropFundingEstimate(est *fundingEstimate) {
+	for i, pending := range ps.fundingPending {
+		if pending == est {
+			ps.fundingPending = append(ps.fundingPending[:i], ps.fundingPending[i+1:]...)
+			return
+		}
+	}
+}
+
+// fundingPayment возвращает платёж фандинга по ноге в USDT (> 0 - получаем)
+func fundingPayment(leg *models.Leg, rate float64) float64 {
+	price := leg.CurrentPrice
+	if price <= 0 {
+		price = leg.EntryPrice
+	}
+
+	payment := rate * leg.Quantity * price
+	if leg.Side == "long" {
+		return -payment
+	}
+	return payment
+}
+
+// fundingSymbols возвращает символы, по которым нужны ставки фандинга:
+// все пары при ConsiderFunding, иначе только пары стратегии funding
+func (e *Engine) fundingSymbols() []string {
+	if e.spreadCalc.IsFundingConsidered() {
+		return e.pairSymbols()
+	}
+
+	var symbols []string
+	e.pairsBySymbol.Range(func(key, value interface{}) bool {
+		for _, ps := range value.([]*PairState) {
+			if ps.IsFundingStrategy() {
+				symbols = append(symbols, key.(string))
+				break
+			}
+		}
+		return true
+	})
+	return symbols
+}
@@ -0,0 +1,325 @@
+package bot
+
+import (
+	"context"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"arbitrage/internal/exchange"
+	"arbitrage/internal/models"
+)
+
+func newFundingPairState(maxHoldHours int) *PairState {
+	cfg := &models.PairConfig{
+		ID:             1,
+		Symbol:         "BTCUSDT",
+		VolumeAsset:    1,
+		NOrders:        1,
+		Strategy:       models.StrategyFunding,
+		FundingDiffPct: 0.05,
+		MaxHoldHours:   maxHoldHours,
+		Status:         models.PairStatusActive,
+	}
+	ps := &PairState{
+		Config:  cfg,
+		Runtime: &models.PairRuntime{PairID: cfg.ID, State: models.StateReady},
+	}
+	ps.setStrategy(cfg)
+	return ps
+}
+
+// TestGetFundingOpportunity проверяет выбор связки по нормализованным ставкам
+func TestGetFundingOpportunity(t *testing.T) {
+	now := time.Now()
+	tracker := NewPriceTracker(1)
+	sc := NewSpreadCalculator(tracker)
+
+	updatePrice(tracker, "BTCUSDT", "bybit", 100, 100.1)
+	updatePrice(tracker, "BTCUSDT", "okx", 100, 100.1)
+	updatePrice(tracker, "BTCUSDT", "gate", 100, 100.1)
+
+	sc.SetFundingRate("bybit", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0001, NextFundingTime: now.Add(time.Hour), Interval: 8 * time.Hour})
+	sc.SetFundingRate("okx", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: -0.0002, NextFundingTime: now.Add(time.Hour), Interval: 8 * time.Hour})
+	// 0.03% за 4ч = 0.06% за 8ч
+	sc.SetFundingRate("gate", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0003, NextFundingTime: now.Add(time.Hour), Interval: 4 * time.Hour})
+	// Ставка без цены не участвует
+	sc.SetFundingRate("htx", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.01, NextFundingTime: now.Add(time.Hour), Interval: 8 * time.Hour})
+
+	opp := sc.GetFundingOpportunity("BTCUSDT", nil)
+	if opp == nil {
+		t.Fatal("expected funding opportunity")
+	}
+	defer ReleaseArbitrageOpportunity(opp)
+
+	if opp.LongExchange != "okx" || opp.ShortExchange != "gate" {
+		t.Fatalf("expected long okx / short gate, got %s / %s", opp.LongExchange, opp.ShortExchange)
+	}
+	if math.Abs(opp.FundingDiff-0.08) > 1e-9 {
+		t.Fatalf("expected funding diff 0.08%%, got %.6f", opp.FundingDiff)
+	}
+	if opp.NetSpread >= 0 {
+		t.Fatalf("expected entry cost in net spread, got %.6f", opp.NetSpread)
+	}
+}
+
+// TestCheckEntryConditions_FundingThreshold проверяет порог funding_diff вместо entry_spread
+func TestCheckEntryConditions_FundingThreshold(t *testing.T) {
+	now := time.Now()
+	tracker := NewPriceTracker(1)
+	sc := NewSpreadCalculator(tracker)
+	detector := NewArbitrageDetector(tracker, sc, nil, nil)
+	detector.UpdateMarginCache("bybit", 100000)
+	detector.UpdateMarginCache("okx", 100000)
+
+	updatePrice(tracker, "BTCUSDT", "bybit", 100, 100.01)
+	updatePrice(tracker, "BTCUSDT", "okx", 100, 100.01)
+
+	sc.SetFundingRate("bybit", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0001, NextFundingTime: now.Add(time.Hour)})
+	sc.SetFundingRate("okx", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0003, NextFundingTime: now.Add(time.Hour)})
+
+	// За 72ч удержания начисления окупают вход и выход
+	ps := newFundingPairState(72)
+
+	// Разница 0.02% < порога 0.05%
+	conditions := detector.CheckEntryConditions(ps, 0, 0, nil)
+	if conditions.CanEnter {
+		t.Fatal("expected entry rejected below funding threshold")
+	}
+	ReleaseEntryConditions(conditions)
+
+	sc.SetFundingRate("okx", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0008, NextFundingTime: now.Add(time.Hour)})
+
+	conditions = detector.CheckEntryConditions(ps, 0, 0, nil)
+	if !conditions.CanEnter {
+		t.Fatalf("expected entry allowed, reason: %s", conditions.Reason)
+	}
+	if conditions.Opportunity.LongExchange != "bybit" || conditions.Opportunity.ShortExchange != "okx" {
+		t.Fatalf("expected long bybit / short okx, got %s / %s",
+			conditions.Opportunity.LongExchange, conditions.Opportunity.ShortExchange)
+	}
+	ReleaseArbitrageOpportunity(conditions.Opportunity)
+	ReleaseEntryConditions(conditions)
+}
+
+// TestCheckEntryConditions_FundingRoundTripCost проверяет отказ во входе, когда спреды
+// и комиссии входа и выхода дороже фандинга за время удержания
+func TestCheckEntryConditions_FundingRoundTripCost(t *testing.T) {
+	now := time.Now()
+	tracker := NewPriceTracker(1)
+	sc := NewSpreadCalculator(tracker)
+	detector := NewArbitrageDetector(tracker, sc, nil, nil)
+	detector.UpdateMarginCache("bybit", 100000)
+	detector.UpdateMarginCache("okx", 100000)
+
+	// Спред стаканов 0.5%: вход и выход стоят ~1% плюс 0.2% комиссий тейкера
+	updatePrice(tracker, "BTCUSDT", "bybit", 100, 100.5)
+	updatePrice(tracker, "BTCUSDT", "okx", 100, 100.5)
+
+	// Разница 0.07% за 8ч выше порога 0.05%, за 48ч удержания - 0.42%
+	sc.SetFundingRate("bybit", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0001, NextFundingTime: now.Add(time.Hour)})
+	sc.SetFundingRate("okx", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0008, NextFundingTime: now.Add(time.Hour)})
+
+	ps := newFundingPairState(48)
+
+	conditions := detector.CheckEntryConditions(ps, 0, 0, nil)
+	if conditions.CanEnter {
+		t.Fatal("expected entry rejected when round-trip cost exceeds funding")
+	}
+	if !strings.Contains(conditions.Reason, "round-trip cost") {
+		t.Fatalf("expected round-trip cost reason, got %q", conditions.Reason)
+	}
+	ReleaseEntryConditions(conditions)
+
+	// Узкие стаканы: ~0.02% спредов и 0.2% комиссий окупаются фандингом
+	updatePrice(tracker, "BTCUSDT", "bybit", 100, 100.01)
+	updatePrice(tracker, "BTCUSDT", "okx", 100, 100.01)
+
+	conditions = detector.CheckEntryConditions(ps, 0, 0, nil)
+	if !conditions.CanEnter {
+		t.Fatalf("expected entry allowed, reason: %s", conditions.Reason)
+	}
+	ReleaseArbitrageOpportunity(conditions.Opportunity)
+	ReleaseEntryConditions(conditions)
+}
+
+// TestCheckExitConditions_Funding проверяет выход по развороту ставок и max_hold_hours
+func TestCheckExitConditions_Funding(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	tracker := NewPriceTracker(1)
+	sc := NewSpreadCalculator(tracker)
+	sc.SetClock(func() time.Time { return now })
+	detector := NewArbitrageDetector(tracker, sc, nil, nil)
+
+	updatePrice(tracker, "BTCUSDT", "bybit", 100, 100.1)
+	updatePrice(tracker, "BTCUSDT", "okx", 100, 100.1)
+
+	sc.SetFundingRate("bybit", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0001, NextFundingTime: now.Add(time.Hour)})
+	sc.SetFundingRate("okx", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0008, NextFundingTime: now.Add(time.Hour)})
+
+	ps := newFundingPairState(24)
+	entryTime := now.Add(-time.Hour)
+	ps.Runtime.State = models.StateHolding
+	ps.Runtime.EntryTime = &entryTime
+	ps.Runtime.Legs = []models.Leg{
+		{Exchange: "bybit", Side: "long", EntryPrice: 100, Quantity: 1},
+		{Exchange: "okx", Side: "short", EntryPrice: 100, Quantity: 1},
+	}
+
+	// Спред схлопнулся, но разница ставок положительна - держим
+	if exit := detector.CheckExitConditions(ps); exit.ShouldExit {
+		t.Fatalf("expected hold, got exit %s", exit.Reason)
+	}
+
+	sc.SetFundingRate("okx", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: -0.0001, NextFundingTime: now.Add(time.Hour)})
+	if exit := detector.CheckExitConditions(ps); exit.Reason != ExitReasonFundingFlip {
+		t.Fatalf("expected %s, got %q", ExitReasonFundingFlip, exit.Reason)
+	}
+
+	sc.SetFundingRate("okx", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0008, NextFundingTime: now.Add(time.Hour)})
+	entryTime = now.Add(-25 * time.Hour)
+	if exit := detector.CheckExitConditions(ps); exit.Reason != ExitReasonMaxHold {
+		t.Fatalf("expected %s, got %q", ExitReasonMaxHold, exit.Reason)
+	}
+}
+
+// TestSettleFunding проверяет учёт начислений в реализованном PNL ровно один раз
+func TestSettleFunding(t *testing.T) {
+	now := time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC)
+	sc := NewSpreadCalculator(NewPriceTracker(1))
+	sc.SetClock(func() time.Time { return now })
+	e := &Engine{spreadCalc: sc}
+
+	funding := now.Add(time.Hour)
+	sc.SetFundingRate("bybit", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0001, NextFundingTime: funding, Interval: 8 * time.Hour})
+	sc.SetFundingRate("okx", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0005, NextFundingTime: funding, Interval: 8 * time.Hour})
+
+	ps := newFundingPairState(0)
+	entryTime := now
+	ps.Runtime.State = models.StateHolding
+	ps.Runtime.EntryTime = &entryTime
+	ps.Runtime.Legs = []models.Leg{
+		{Exchange: "bybit", Side: "long", EntryPrice: 100, CurrentPrice: 100, Quantity: 2},
+		{Exchange: "okx", Side: "short", EntryPrice: 100, CurrentPrice: 100, Quantity: 2},
+	}
+
+	// До начисления ничего не учитываем
+	e.settleFunding(ps)
+	if ps.Runtime.FundingPnl != 0 {
+		t.Fatalf("expected no funding before settlement, got %.6f", ps.Runtime.FundingPnl)
+	}
+
+	// Начисление прошло, новая ставка ещё не получена
+	now = funding.Add(time.Second)
+	e.settleFunding(ps)
+
+	// Шорт получает 0.05% от 200, лонг платит 0.01% от 200
+	expected := 0.1 - 0.02
+	if math.Abs(ps.Runtime.FundingPnl-expected) > 1e-9 {
+		t.Fatalf("expected funding pnl %.4f, got %.6f", expected, ps.Runtime.FundingPnl)
+	}
+	if math.Abs(ps.Runtime.RealizedPnl-expected) > 1e-9 {
+		t.Fatalf("expected realized pnl %.4f, got %.6f", expected, ps.Runtime.RealizedPnl)
+	}
+	// Без истории биржи начисление остаётся помеченной оценкой
+	if math.Abs(ps.Runtime.FundingEstimated-expected) > 1e-9 {
+		t.Fatalf("expected estimated funding %.4f, got %.6f", expected, ps.Runtime.FundingEstimated)
+	}
+
+	// Обновление ставок на следующий период не учитывает начисление повторно
+	next := funding.Add(8 * time.Hour)
+	sc.SetFundingRate("bybit", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0002, NextFundingTime: next, Interval: 8 * time.Hour})
+	sc.SetFundingRate("okx", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0002, NextFundingTime: next, Interval: 8 * time.Hour})
+	e.settleFunding(ps)
+	if math.Abs(ps.Runtime.FundingPnl-expected) > 1e-9 {
+		t.Fatalf("expected funding settled once, got %.6f", ps.Runtime.FundingPnl)
+	}
+}
+
+// TestSettleFunding_RealisedFunding проверяет замену оценки фактическим начислением
+// из истории биржи и сохранение помеченной оценки, если начисление не найдено
+func TestSettleFunding_RealisedFunding(t *testing.T) {
+	now := time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC)
+	sc := NewSpreadCalculator(NewPriceTracker(1))
+	sc.SetClock(func() time.Time { return now })
+
+	bybit := exchange.NewSim(exchange.DefaultSimConfig("bybit"))
+	okx := exchange.NewSim(exchange.DefaultSimConfig("okx"))
+	e := &Engine{
+		ctx:        context.Background(),
+		spreadCalc: sc,
+		exchanges:  map[string]exchange.Exchange{"bybit": bybit, "okx": okx},
+	}
+
+	funding := now.Add(time.Hour)
+	sc.SetFundingRate("bybit", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0001, NextFundingTime: funding, Interval: 8 * time.Hour})
+	sc.SetFundingRate("okx", &exchange.FundingRate{Symbol: "BTCUSDT", Rate: 0.0005, NextFundingTime: funding, Interval: 8 * time.Hour})
+
+	ps := newFundingPairState(0)
+	entryTime := now
+	ps.Runtime.State = models.StateHolding
+	ps.Runtime.EntryTime = &entryTime
+	ps.Runtime.Legs = []models.Leg{
+		{Exchange: "bybit", Side: "long", EntryPrice: 100, CurrentPrice: 100, Quantity: 2},
+		{Exchange: "okx", Side: "short", EntryPrice: 100, CurrentPrice: 100, Quantity: 2},
+	}
+
+	// Bybit списал больше оценки (расчёт по своей mark-цене), OKX начисление не опубликовал
+	bybit.AddFundingPayment(exchange.FundingPayment{Symbol: "BTCUSDT", Amount: -0.025, Time: funding})
+	bybit.AddFundingPayment(exchange.FundingPayment{Symbol: "ETHUSDT", Amount: -1, Time: funding})
+
+	settle := func() {
+		ps.mu.Lock()
+		e.settleFunding(ps)
+		ps.mu.Unlock()
+
+		// Ждём завершения запущенных сверок
+		for deadline := time.Now().Add(5 * time.Second); ; time.Sleep(time.Millisecond) {
+			ps.mu.Lock()
+			inFlight := false
+			for _, est := range ps.fundingPending {
+				inFlight = inFlight || est.inFlight
+			}
+			ps.mu.Unlock()
+			if !inFlight {
+				return
+			}
+			if time.Now().After(deadline) {
+				t.Fatal("funding confirmation did not finish")
+			}
+		}
+	}
+
+	now = funding.Add(time.Second)
+	settle()
+	estimated := 0.1 - 0.02
+	if math.Abs(ps.Runtime.FundingEstimated-estimated) > 1e-9 || math.Abs(ps.Runtime.FundingPnl-estimated) > 1e-9 {
+		t.Fatalf("expected estimate %.4f before confirmation, got pnl %.6f, estimated %.6f",
+			estimated, ps.Runtime.FundingPnl, ps.Runtime.FundingEstimated)
+	}
+
+	// Первая сверка: Bybit подтверждён фактическим списанием, OKX ждёт
+	now = funding.Add(fundingConfirmDelay)
+	settle()
+	expected := 0.1 - 0.025
+	if math.Abs(ps.Runtime.FundingPnl-expected) > 1e-9 || math.Abs(ps.Runtime.RealizedPnl-expected) > 1e-9 {
+		t.Fatalf("expected funding pnl %.4f with realised bybit payment, got %.6f (realized %.6f)",
+			expected, ps.Runtime.FundingPnl, ps.Runtime.RealizedPnl)
+	}
+	if math.Abs(ps.Runtime.FundingEstimated-0.1) > 1e-9 || len(ps.fundingPending) != 1 {
+		t.Fatalf("expected okx estimate 0.1 pending, got %.6f, %d pending", ps.Runtime.FundingEstimated, len(ps.fundingPending))
+	}
+
+	// OKX не подтвердил начисление за все сверки: оценка остаётся помеченной
+	for attempt := 2; attempt <= fundingConfirmAttempts; attempt++ {
+		now = funding.Add(time.Duration(attempt) * fundingConfirmDelay)
+		settle()
+	}
+	if len(ps.fundingPending) != 0 {
+		t.Fatalf("expected no pending estimates after %d attempts, got %d", fundingConfirmAttempts, len(ps.fundingPending))
+	}
+	if math.Abs(ps.Runtime.FundingPnl-expected) > 1e-9 || math.Abs(ps.Runtime.FundingEstimated-0.1) > 1e-9 {
+		t.Fatalf("expected okx estimate kept, got pnl %.6f, estimated %.6f", ps.Runtime.FundingPnl, ps.Runtime.FundingEstimated)
+	}
+}
@@ -0,0 +1,102 @@
+package bot
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"arbitrage/internal/exchange"
+)
+
+func TestFundingSum(t *testing.T) {
+	now := time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC)
+	rate := &exchange.FundingRate{
+		Rate:            0.0001,
+		PredictedRate:   0.0003,
+		NextFundingTime: now.Add(time.Hour),
+		Interval:        8 * time.Hour,
+	}
+
+	tests := []struct {
+		name     string
+		hold     time.Duration
+		expected float64
+	}{
+		{"before first funding", 30 * time.Minute, 0},
+		{"one funding", 2 * time.Hour, 0.0001},
+		{"two fundings use predicted rate", 10 * time.Hour, 0.0004},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fundingSum(rate, now, tt.hold); math.Abs(got-tt.expected) > 1e-12 {
+				t.Errorf("expected %.6f, got %.6f", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestExpectedFundingPct(t *testing.T) {
+	now := time.Date(2024, 1, 1, 7, 0, 0, 0, time.UTC)
+	sc := NewSpreadCalculator(NewPriceTracker(1))
+	sc.SetClock(func() time.Time { return now })
+
+	sc.SetFundingRate("bybit", &exchange.FundingRate{
+		Symbol: "BTCUSDT", Rate: 0.001, NextFundingTime: now.Add(time.Hour), Interval: 8 * time.Hour,
+	})
+	sc.SetFundingRate("okx", &exchange.FundingRate{
+		Symbol: "BTCUSDT", Rate: -0.0005, NextFundingTime: now.Add(time.Hour), Interval: 8 * time.Hour,
+	})
+
+	// Учёт выключен - фандинг не влияет на спред
+	if got := sc.ExpectedFundingPct("BTCUSDT", "bybit", "okx"); got != 0 {
+		t.Fatalf("expected 0 when disabled, got %.6f", got)
+	}
+
+	sc.SetConsiderFunding(true, 2*time.Hour)
+
+	// Лонг на bybit платит 0.1%, шорт на okx платит 0.05%
+	if got := sc.ExpectedFundingPct("BTCUSDT", "bybit", "okx"); math.Abs(got-(-0.15)) > 1e-9 {
+		t.Fatalf("expected -0.15%%, got %.6f", got)
+	}
+
+	// Обратная связка получает оба платежа
+	if got := sc.ExpectedFundingPct("BTCUSDT", "okx", "bybit"); math.Abs(got-0.15) > 1e-9 {
+		t.Fatalf("expected 0.15%%, got %.6f", got)
+	}
+}
+
+func TestGetBestOpportunity_IncludesFunding(t *testing.T) {
+	now := time.Now()
+	pt := NewPriceTracker(1)
+	sc := NewSpreadCalculator(pt)
+	sc.SetClock(func() time.Time { return now })
+
+	pt.Update(PriceUpdate{Exchange: "bybit", Symbol: "BTCUSDT", BidPrice: 99.9, AskPrice: 100, Timestamp: now})
+	pt.Update(PriceUpdate{Exchange: "okx", Symbol: "BTCUSDT", BidPrice: 101, AskPrice: 101.1, Timestamp: now})
+
+	opp := sc.GetBestOpportunity("BTCUSDT", nil)
+	if opp == nil {
+		t.Fatal("expected opportunity")
+	}
+	baseNet := opp.NetSpread
+	ReleaseArbitrageOpportunity(opp)
+
+	sc.SetConsiderFunding(true, time.Hour)
+	sc.SetFundingRate("bybit", &exchange.FundingRate{
+		Symbol: "BTCUSDT", Rate: 0.002, NextFundingTime: now.Add(30 * time.Minute), Interval: 8 * time.Hour,
+	})
+
+	opp = sc.GetBestOpportunity("BTCUSDT", nil)
+	if opp == nil {
+		t.Fatal("expected opportunity")
+	}
+	defer ReleaseArbitrageOpportunity(opp)
+
+	if math.Abs(opp.FundingPct-(-0.2)) > 1e-9 {
+		t.Fatalf("expected funding -0.2%%, got %.6f", opp.FundingPct)
+	}
+	if math.Abs(opp.NetSpread-(baseNet-0.2)) > 1e-9 {
+		t.Fatalf("expected net spread %.6f, got %.6f", baseNet-0.2, opp.NetSpread)
+	}
+}
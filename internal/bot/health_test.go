@@ -0,0 +1,93 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"arbitrage/internal/exchange"
+	"arbitrage/internal/models"
+)
+
+// tripExchange отключает биржу ошибками REST запросов
+// Состояние бирж общее для пакета, поэтому тесты используют уникальные имена бирж
+func tripExchange(t *testing.T, name string) {
+	t.Helper()
+	health := exchange.HealthOf(name)
+	for i := 0; i < exchange.DefaultHealthConfig().MinRequests; i++ {
+		health.ObserveRequest(time.Millisecond, errors.New("read tcp: i/o timeout"))
+	}
+	if !health.Tripped() {
+		t.Fatalf("expected %s tripped, got %s", name, health.State())
+	}
+}
+
+// TestDetectOpportunity_SkipsTrippedExchange проверяет, что отключённая биржа
+// не выбирается ногой входа, а связка строится по остальным биржам
+func TestDetectOpportunity_SkipsTrippedExchange(t *testing.T) {
+	pt := NewPriceTracker(1)
+	sc := NewSpreadCalculator(pt)
+	ad := NewArbitrageDetector(pt, sc, nil, nil)
+
+	now := time.Now()
+	pt.Update(PriceUpdate{Exchange: "health-long", Symbol: "BTCUSDT", BidPrice: 99, AskPrice: 100, Timestamp: now})
+	pt.Update(PriceUpdate{Exchange: "health-short", Symbol: "BTCUSDT", BidPrice: 103, AskPrice: 104, Timestamp: now})
+	pt.Update(PriceUpdate{Exchange: "health-tripped", Symbol: "BTCUSDT", BidPrice: 105, AskPrice: 106, Timestamp: now})
+
+	opp := ad.DetectOpportunity("BTCUSDT", nil)
+	if opp == nil || opp.ShortExchange != "health-tripped" {
+		t.Fatalf("expected short on health-tripped before trip, got %+v", opp)
+	}
+	ReleaseArbitrageOpportunity(opp)
+
+	tripExchange(t, "health-tripped")
+
+	opp = ad.DetectOpportunity("BTCUSDT", nil)
+	if opp == nil {
+		t.Fatal("expected opportunity without tripped exchange")
+	}
+	defer ReleaseArbitrageOpportunity(opp)
+	if opp.LongExchange != "health-long" || opp.ShortExchange != "health-short" || opp.ShortPrice != 103 {
+		t.Fatalf("expected health-long/health-short at 103, got %s/%s at %.0f",
+			opp.LongExchange, opp.ShortExchange, opp.ShortPrice)
+	}
+}
+
+// TestCloseParallel_RetriesOnTrippedExchange проверяет, что закрытие на отключённой
+// бирже повторяется и не удваивает ордер, принятый биржей без ответа
+func TestCloseParallel_RetriesOnTrippedExchange(t *testing.T) {
+	sim := newMakerTestSim("health-close", 99, 100)
+	if _, err := sim.PlaceMarketOrder(context.Background(), "BTCUSDT", exchange.SideBuy, 1, ""); err != nil {
+		t.Fatalf("open position: %v", err)
+	}
+	flaky := &lostResponseExchange{Sim: sim, lost: 1}
+	oe := newFillsTestExecutor(map[string]exchange.Exchange{"health-close": flaky})
+	leg := models.Leg{Exchange: "health-close", Side: "long", Quantity: 1, EntryPrice: 100}
+
+	// На здоровой бирже - одна попытка
+	result := oe.CloseParallel(context.Background(), CloseParams{Symbol: "BTCUSDT", Legs: []models.Leg{leg}})
+	if result.Success {
+		t.Fatal("expected single close attempt to fail on lost response")
+	}
+	if size := positionSize(t, sim); size != 0 {
+		t.Fatalf("expected position closed by lost order, got %.4f", size)
+	}
+	if _, err := sim.PlaceMarketOrder(context.Background(), "BTCUSDT", exchange.SideBuy, 1, ""); err != nil {
+		t.Fatalf("reopen position: %v", err)
+	}
+
+	tripExchange(t, "health-close")
+	flaky.lost = 1
+
+	result = oe.CloseParallel(context.Background(), CloseParams{Symbol: "BTCUSDT", Legs: []models.Leg{leg}})
+	if !result.Success {
+		t.Fatalf("expected close to succeed with retries, got error: %v", result.Error)
+	}
+	if size := positionSize(t, sim); size != 0 {
+		t.Fatalf("expected position closed once, got %.4f", size)
+	}
+	if result.LongOrder == nil || result.LongOrder.ClientOrderID == "" {
+		t.Fatalf("expected close order with client id, got %+v", result.LongOrder)
+	}
+}
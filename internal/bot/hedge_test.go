@@ -0,0 +1,96 @@
+package bot
+
+import (
+	"context"
+	"testing"
+
+	"arbitrage/internal/exchange"
+	"arbitrage/internal/models"
+)
+
+// hedgeModeExchange - аккаунт в режиме hedge: записывает закрытия через PlaceCloseOrder
+type hedgeModeExchange struct {
+	*exchange.Sim
+	closes []string
+}
+
+func (h *hedgeModeExchange) PositionMode() string {
+	return exchange.PositionModeHedge
+}
+
+func (h *hedgeModeExchange) PlaceCloseOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*exchange.Order, error) {
+	h.closes = append(h.closes, side)
+	return h.Sim.PlaceMarketOrder(ctx, symbol, exchange.CloseOrderSide(side), qty, clientOrderID)
+}
+
+// TestCloseParallel_HedgeModeClosesPositionSide проверяет, что закрытие и откат ноги
+// на аккаунте в режиме hedge закрывают позицию её стороны, а не открывают встречную
+func TestCloseParallel_HedgeModeClosesPositionSide(t *testing.T) {
+	hedge := &hedgeModeExchange{Sim: newMakerTestSim("hedge-close", 99, 100)}
+	oe := newFillsTestExecutor(map[string]exchange.Exchange{"hedge-close": hedge})
+	ctx := context.Background()
+
+	order, err := hedge.PlaceMarketOrder(ctx, "BTCUSDT", exchange.SideSell, 1, "")
+	if err != nil {
+		t.Fatalf("open short: %v", err)
+	}
+	leg := models.Leg{Exchange: "hedge-close", Side: exchange.SideShort, Quantity: 1, EntryPrice: 99}
+
+	result := oe.CloseParallel(ctx, CloseParams{Symbol: "BTCUSDT", Legs: []models.Leg{leg}})
+	if !result.Success {
+		t.Fatalf("expected close to succeed, got error: %v", result.Error)
+	}
+
+	if err := oe.rollbackShort("BTCUSDT", hedge, order); err != nil {
+		t.Fatalf("rollback short: %v", err)
+	}
+	if len(hedge.closes) != 2 || hedge.closes[0] != exchange.SideShort || hedge.closes[1] != exchange.SideShort {
+		t.Fatalf("expected two closes of short position, got %v", hedge.closes)
+	}
+}
+
+// TestHandleLiquidation_IgnoresOtherSide проверяет, что ликвидация позиции другой
+// стороны на аккаунте ноги (ручная торговля в режиме hedge) не закрывает пару
+func TestHandleLiquidation_IgnoresOtherSide(t *testing.T) {
+	e := &Engine{}
+	ps := &PairState{
+		Config: &models.PairConfig{ID: 1, Symbol: "BTCUSDT"},
+		Runtime: &models.PairRuntime{
+			State: models.StateHolding,
+			Legs: []models.Leg{
+				{Exchange: "bybit", Side: exchange.SideLong, Quantity: 1},
+				{Exchange: "okx", Side: exchange.SideShort, Quantity: 1},
+			},
+		},
+	}
+	e.addToPositionIndex(ps)
+
+	e.handleLiquidation(PositionUpdate{Exchange: "bybit", Symbol: "BTCUSDT", Side: exchange.SideShort, Liquidated: true})
+	if ps.Runtime.State != models.StateHolding {
+		t.Fatalf("expected pair holding after other side liquidation, got %s", ps.Runtime.State)
+	}
+	if !ps.holdsLeg("bybit", exchange.SideLong) || ps.holdsLeg("okx", exchange.SideLong) {
+		t.Fatal("holdsLeg does not match pair legs")
+	}
+}
+
+// TestMatchPositionsToPairs_HedgeAccount проверяет, что лонг и шорт одного аккаунта
+// в режиме hedge не считаются связкой пары
+func TestMatchPositionsToPairs_HedgeAccount(t *testing.T) {
+	rm := &RecoveryManager{}
+	manualShort := &DiscoveredPosition{Exchange: "bybit", Symbol: "BTCUSDT", Side: exchange.SideShort, Size: 2}
+	long := &DiscoveredPosition{Exchange: "bybit", Symbol: "BTCUSDT", Side: exchange.SideLong, Size: 1}
+	short := &DiscoveredPosition{Exchange: "okx", Symbol: "BTCUSDT", Side: exchange.SideShort, Size: 1}
+	pair := &models.PairConfig{ID: 1, Symbol: "BTCUSDT"}
+
+	matched, orphaned := rm.matchPositionsToPairs([]*DiscoveredPosition{manualShort, long, short}, []*models.PairConfig{pair})
+	if len(matched) != 1 || !matched[0].IsComplete {
+		t.Fatalf("expected one complete match, got %+v", matched)
+	}
+	if matched[0].LongLeg != long || matched[0].ShortLeg != short {
+		t.Fatalf("expected legs on bybit/okx, got %s/%s", matched[0].LongLeg.Exchange, matched[0].ShortLeg.Exchange)
+	}
+	if len(orphaned) != 1 || orphaned[0] != manualShort {
+		t.Fatalf("expected manual short on bybit orphaned, got %+v", orphaned)
+	}
+}
@@ -0,0 +1,50 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"arbitrage/internal/exchange"
+	"arbitrage/pkg/utils"
+)
+
+// ============================================================
+// Спецификации контрактов в OrderValidator
+// ============================================================
+
+// updateInstruments перезагружает спецификации контрактов всех пар на всех биржах
+// Лоты и статус листинга меняются редко, поэтому достаточно периода DefaultInstrumentTTL
+func (e *Engine) updateInstruments() {
+	var wg sync.WaitGroup
+	for name, exch := range e.GetExchanges() {
+		wg.Add(1)
+		go func(exchName string, ex exchange.Exchange) {
+			defer wg.Done()
+			e.refreshInstruments(exchName, ex)
+		}(name, exch)
+	}
+	wg.Wait()
+}
+
+// refreshInstruments загружает спецификации всех пар одной биржи
+func (e *Engine) refreshInstruments(exchName string, ex exchange.Exchange) {
+	for _, symbol := range e.pairSymbols() {
+		e.loadInstrument(exchName, ex, symbol)
+	}
+}
+
+// loadInstrument загружает спецификацию контракта символа в OrderValidator
+// Ошибка не критична: валидатор использует дефолтные лимиты
+func (e *Engine) loadInstrument(exchName string, ex exchange.Exchange, symbol string) {
+	ctx, cancel := context.WithTimeout(e.ctx, 10*time.Second)
+	inst, err := ex.GetInstrument(ctx, symbol)
+	cancel()
+
+	if err != nil {
+		utils.Debugf("instrument %s on %s: %v", symbol, exchName, err)
+		return
+	}
+
+	e.orderValidator.UpdateInstrument(exchName, inst)
+}
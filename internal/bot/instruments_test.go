@@ -0,0 +1,51 @@
+package bot
+
+import (
+	"strings"
+	"testing"
+
+	"arbitrage/internal/exchange"
+)
+
+// TestOrderValidator_ContractLegsEqualExposure проверяет, что ноги на биржах
+// с контрактами и монетами получают одинаковый объём в монетах
+func TestOrderValidator_ContractLegsEqualExposure(t *testing.T) {
+	ov := NewOrderValidator(nil)
+
+	// Bybit: лот 0.001 BTC; OKX: контракт 0.01 BTC
+	ov.UpdateInstrument("bybit", &exchange.Instrument{
+		Symbol: "BTCUSDT", ContractValue: 1, LotSize: 0.001, MinSize: 0.001, MinNotional: 5,
+	})
+	okx := &exchange.Instrument{
+		Symbol: "BTCUSDT", ContractValue: 0.01, LotSize: 1, MinSize: 1, MinNotional: 5,
+	}
+	ov.UpdateInstrument("okx", okx)
+
+	result := ov.ValidateBothLegs("bybit", "okx", "BTCUSDT", 0.0355, 50000, 50010)
+	if !result.Valid {
+		t.Fatalf("expected valid result, got %s", result.Error)
+	}
+	if result.AdjustedQty != 0.03 {
+		t.Fatalf("expected 0.03 BTC on both legs, got %v", result.AdjustedQty)
+	}
+	if contracts := okx.ToVenueSize(result.AdjustedQty); contracts != 3 {
+		t.Fatalf("expected 3 OKX contracts, got %v", contracts)
+	}
+
+	// Лоты 0.003 и 0.002: общий объём кратен обоим
+	ov.UpdateInstrument("gate", &exchange.Instrument{Symbol: "ETHUSDT", ContractValue: 0.003, LotSize: 1, MinSize: 1})
+	ov.UpdateInstrument("htx", &exchange.Instrument{Symbol: "ETHUSDT", ContractValue: 0.002, LotSize: 1, MinSize: 1})
+	result = ov.ValidateBothLegs("gate", "htx", "ETHUSDT", 0.0119, 3000, 3001)
+	if !result.Valid || result.AdjustedQty != 0.006 {
+		t.Fatalf("expected 0.006 ETH common lot, got %+v", result)
+	}
+
+	// Делистинг: вход запрещён
+	ov.UpdateInstrument("okx", &exchange.Instrument{
+		Symbol: "BTCUSDT", ContractValue: 0.01, LotSize: 1, MinSize: 1, Status: exchange.InstrumentStatusDelisted,
+	})
+	result = ov.ValidateBothLegs("bybit", "okx", "BTCUSDT", 0.0355, 50000, 50010)
+	if result.Valid || !strings.Contains(result.Error, "delisted") {
+		t.Fatalf("expected delisted instrument to be rejected, got %+v", result)
+	}
+}
@@ -0,0 +1,468 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"arbitrage/internal/exchange"
+	"arbitrage/internal/models"
+	"arbitrage/pkg/utils"
+)
+
+// defaultMakerRepriceInterval - период проверки котировки, если не задан в конфиге
+const defaultMakerRepriceInterval = 250 * time.Millisecond
+
+// makerQtyEpsilon - остаток объёма, который считается нулевым
+const makerQtyEpsilon = 1e-9
+
+// newMakerTakerParams формирует параметры входа maker_taker по найденной возможности
+// MinSpread = entry_spread + комиссии (мейкер/тейкер пассивной биржи, 2 тейкера хеджа),
+// чтобы котировка проходила тот же порог, что и NetSpread при детекции
+func newMakerTakerParams(symbol string, volume, entrySpread float64, opp *ArbitrageOpportunity, sc *SpreadCalculator) ExecuteParams {
+	hedgeExch := opp.ShortExchange
+	if opp.MakerExchange == opp.ShortExchange {
+		hedgeExch = opp.LongExchange
+	}
+
+	return ExecuteParams{
+		Symbol:        symbol,
+		Volume:        volume,
+		LongExchange:  opp.LongExchange,
+		ShortExchange: opp.ShortExchange,
+		NOrders:       1,
+		MakerExchange: opp.MakerExchange,
+		MinSpread:     entrySpread + sc.MakerTakerFees(opp.MakerExchange, hedgeExch),
+	}
+}
+
+// ExecuteMakerTaker выполняет вход в режиме maker_taker
+//
+// Алгоритм:
+//  1. На MakerExchange выставляется post-only ордер по цене, при которой спред
+//     к цене хеджа (Bid/Ask другой биржи) не меньше MinSpread
+//  2. Каждые MakerRepriceInterval проверяется исполнение; каждое новое исполнение
+//     сразу хеджируется рыночным ордером на другой бирже (с ретраями)
+//  3. Если цена хеджа сдвинулась - котировка переставляется, если спред больше
+//     не проходит или истёк MakerQuoteTimeout - котировка снимается
+//
+// Исполнения берутся из потока ордеров (SubscribeOrders), цены - из потоков
+// стаканов (SetOrderBooks). REST запросы - только если поток молчит.
+//
+// Частично исполненный вход считается успешным на захеджированный объём.
+// Если хедж не удался - непокрытая часть пассивной ноги закрывается, пара ставится на паузу.
+func (oe *OrderExecutor) ExecuteMakerTaker(ctx context.Context, params ExecuteParams) *ExecuteResult {
+	oe.mu.RLock()
+	longExch, longOk := oe.exchanges[params.LongExchange]
+	shortExch, shortOk := oe.exchanges[params.ShortExchange]
+	oe.mu.RUnlock()
+
+	if !longOk || !shortOk {
+		return &ExecuteResult{
+			Success: false,
+			Error: fmt.Errorf("exchange not found: long=%s(%v) short=%s(%v)",
+				params.LongExchange, longOk, params.ShortExchange, shortOk),
+		}
+	}
+
+	if params.EntryAt.IsZero() {
+		params.EntryAt = time.Now()
+	}
+
+	s := &makerSession{oe: oe, params: params, makerName: params.MakerExchange}
+	switch params.MakerExchange {
+	case params.LongExchange:
+		s.maker, s.hedge = longExch, shortExch
+		s.hedgeName = params.ShortExchange
+		s.makerSide, s.hedgeSide = exchange.SideBuy, exchange.SideSell
+	case params.ShortExchange:
+		s.maker, s.hedge = shortExch, longExch
+		s.hedgeName = params.LongExchange
+		s.makerSide, s.hedgeSide = exchange.SideSell, exchange.SideBuy
+	default:
+		return &ExecuteResult{
+			Success: false,
+			Error:   fmt.Errorf("maker exchange %q is neither long (%s) nor short (%s)", params.MakerExchange, params.LongExchange, params.ShortExchange),
+		}
+	}
+
+	// Шаг цены нужен, чтобы котировка не была отклонена биржей
+	if limits, err := s.maker.GetLimits(ctx, params.Symbol); err == nil && limits != nil {
+		s.priceStep = limits.PriceStep
+	}
+
+	quoteTimeout := oe.cfg.MakerQuoteTimeout
+	if quoteTimeout <= 0 {
+		quoteTimeout = oe.cfg.OrderTimeout
+	}
+	interval := oe.cfg.MakerRepriceInterval
+	if interval <= 0 {
+		interval = defaultMakerRepriceInterval
+	}
+
+	quoteCtx, cancel := context.WithTimeout(ctx, quoteTimeout)
+	defer cancel()
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	err := s.run(ctx, quoteCtx, ticker.C)
+
+	// Снимаем остаток котировки и хеджируем исполнения, пришедшие до отмены
+	if cancelErr := s.cancelQuote(); cancelErr != nil && err == nil {
+		err = cancelErr
+	}
+	if !s.hedgeFailed {
+		if hedgeErr := s.hedgeFills(ctx); hedgeErr != nil && err == nil {
+			err = hedgeErr
+		}
+	}
+
+	return s.result(err)
+}
+
+// makerSession - состояние одного входа maker_taker
+type makerSession struct {
+	oe     *OrderExecutor
+	params ExecuteParams
+
+	maker, hedge         exchange.Exchange
+	makerName, hedgeName string // аккаунты ног (ключи потоков ордеров и стаканов)
+	makerSide, hedgeSide string
+	priceStep            float64
+
+	// Текущая котировка, её цена, время выставления и уже учтённое по ней исполнение
+	quote       *exchange.Order
+	quotePx     float64
+	quotedAt    time.Time
+	quoteFilled float64
+	quoteCost   float64
+
+	// Итоги по ногам: объём и стоимость (qty * price)
+	makerQty, makerCost float64
+	hedgeQty, hedgeCost float64
+	hedgeFailed         bool
+	hedges              int // число отправленных хеджей (для клиентских ID)
+}
+
+// run - цикл котирования до полного исполнения, ухода спреда или таймаута
+// ctx - для хеджа и запросов по ордеру, quoteCtx - время жизни котировки
+func (s *makerSession) run(ctx, quoteCtx context.Context, tick <-chan time.Time) error {
+	for {
+		if s.quote != nil {
+			if err := s.syncQuote(ctx); err != nil {
+				utils.Warnf("maker-taker %s: order status on %s: %v", s.params.Symbol, s.maker.GetName(), err)
+			}
+		}
+
+		if err := s.hedgeFills(ctx); err != nil {
+			return err
+		}
+
+		remaining := s.params.Volume - s.makerQty
+		if remaining <= makerQtyEpsilon {
+			return nil
+		}
+
+		if quoteCtx.Err() != nil {
+			return nil
+		}
+
+		price, ok, err := s.quotePrice(quoteCtx)
+		if err != nil {
+			if quoteCtx.Err() != nil {
+				return nil
+			}
+			utils.Warnf("maker-taker %s: %v", s.params.Symbol, err)
+		} else if !ok {
+			// Спред больше не проходит - снимаем котировку и выходим
+			return nil
+		} else {
+			if s.quote != nil && s.quotePx != price {
+				if err := s.cancelQuote(); err != nil {
+					return err
+				}
+				// Пока отменяли, котировка могла исполниться - хеджируем и пересчитываем остаток
+				if err := s.hedgeFills(ctx); err != nil {
+					return err
+				}
+				remaining = s.params.Volume - s.makerQty
+				if remaining <= makerQtyEpsilon {
+					return nil
+				}
+			}
+
+			if s.quote == nil {
+				if err := s.placeQuote(quoteCtx, remaining, price); err != nil {
+					if quoteCtx.Err() != nil {
+						return nil
+					}
+					return err
+				}
+			}
+		}
+
+		select {
+		case <-quoteCtx.Done():
+		case <-tick:
+		}
+	}
+}
+
+// quotePrice рассчитывает цену котировки по текущим стаканам обеих бирж
+// ok=false если даже лучшая цена пассивной биржи не даёт MinSpread к хеджу
+func (s *makerSession) quotePrice(ctx context.Context) (float64, bool, error) {
+	bid, ask, err := s.topOfBook(ctx, s.makerName, s.maker)
+	if err != nil {
+		return 0, false, err
+	}
+	hedgeBid, hedgeAsk, err := s.topOfBook(ctx, s.hedgeName, s.hedge)
+	if err != nil {
+		return 0, false, err
+	}
+
+	k := 1 + s.params.MinSpread/100
+
+	if s.makerSide == exchange.SideBuy {
+		// Покупаем не дороже Bid_хеджа / (1 + MinSpread), округляя вниз
+		target := utils.RoundToLotSize(hedgeBid/k, s.priceStep)
+		if target < bid {
+			return 0, false, nil
+		}
+		if target >= ask {
+			// Внутри спреда не встать без пересечения - становимся в лучший Bid
+			return bid, true, nil
+		}
+		return target, true, nil
+	}
+
+	// Продаём не дешевле Ask_хеджа * (1 + MinSpread), округляя вверх
+	target := utils.RoundToLotSizeUp(hedgeAsk*k, s.priceStep)
+	if target > ask {
+		return 0, false, nil
+	}
+	if target <= bid {
+		return ask, true, nil
+	}
+	return target, true, nil
+}
+
+// topOfBook возвращает лучшие цены биржи: из потока стаканов, а если он
+// не подключен или устарел - через REST
+func (s *makerSession) topOfBook(ctx context.Context, name string, exch exchange.Exchange) (bid, ask float64, err error) {
+	if s.oe.books != nil {
+		if book := s.oe.books.GetOrderBook(s.params.Symbol, name); book != nil && len(book.Bids) > 0 && len(book.Asks) > 0 {
+			return book.Bids[0].Price, book.Asks[0].Price, nil
+		}
+	}
+
+	book, err := exch.GetOrderBook(ctx, s.params.Symbol, 1)
+	if err != nil {
+		return 0, 0, fmt.Errorf("order book %s: %w", exch.GetName(), err)
+	}
+	if len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return 0, 0, fmt.Errorf("empty order book on %s", exch.GetName())
+	}
+	return book.Bids[0].Price, book.Asks[0].Price, nil
+}
+
+// placeQuote выставляет post-only котировку на остаток объёма
+func (s *makerSession) placeQuote(ctx context.Context, qty, price float64) error {
+	order, err := s.maker.PlaceLimitOrder(ctx, s.params.Symbol, s.makerSide, qty, price, exchange.TimeInForcePostOnly)
+	if err != nil {
+		return fmt.Errorf("place maker quote on %s: %w", s.maker.GetName(), err)
+	}
+	if order == nil {
+		return fmt.Errorf("place maker quote on %s: nil order", s.maker.GetName())
+	}
+
+	s.quote, s.quotePx, s.quotedAt = order, price, time.Now()
+	s.quoteFilled, s.quoteCost = 0, 0
+	s.applyQuoteState(order)
+	return nil
+}
+
+// syncQuote учитывает новые исполнения котировки из потока ордеров
+// Если поток не сообщил о котировке за FillConfirmTimeout - состояние запрашивается через REST
+func (s *makerSession) syncQuote(ctx context.Context) error {
+	if streamed := s.oe.fills.Get(s.makerName, s.quote.ID); streamed != nil {
+		s.applyQuoteState(streamed)
+		return nil
+	}
+	if time.Since(s.quotedAt) < s.oe.fillConfirmTimeout() {
+		return nil
+	}
+
+	order, err := s.maker.GetOrder(ctx, s.params.Symbol, s.quote.ID)
+	if err != nil {
+		return err
+	}
+	if order != nil {
+		s.applyQuoteState(order)
+	}
+	return nil
+}
+
+// applyQuoteState переносит исполнение котировки в итоги пассивной ноги
+// Post-only, отклонённый из-за пересечения, приходит как cancelled без исполнения
+func (s *makerSession) applyQuoteState(order *exchange.Order) {
+	if order.FilledQty > s.quoteFilled {
+		price := order.AvgFillPrice
+		if price == 0 {
+			price = s.quotePx
+		}
+		cost := order.FilledQty * price
+		s.makerQty += order.FilledQty - s.quoteFilled
+		s.makerCost += cost - s.quoteCost
+		s.quoteFilled, s.quoteCost = order.FilledQty, cost
+	}
+
+	switch order.Status {
+	case exchange.OrderStatusFilled, exchange.OrderStatusCancelled, exchange.OrderStatusRejected:
+		s.quote = nil
+	}
+}
+
+// cancelQuote снимает котировку и забирает её финальное исполнение
+// Использует отдельный таймаут: котировка должна быть снята и при остановке движка
+func (s *makerSession) cancelQuote() error {
+	if s.quote == nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.oe.cfg.OrderTimeout)
+	defer cancel()
+
+	quoteID := s.quote.ID
+	cancelErr := s.maker.CancelOrder(ctx, s.params.Symbol, quoteID)
+
+	// Ордер мог исполниться до отмены - отмена тогда вернёт ошибку, это нормально.
+	// Итоговое исполнение - из потока ордеров, при его молчании через REST
+	s.applyQuoteState(s.oe.confirmFill(ctx, s.makerName, s.maker, s.quote))
+	if s.quote != nil && cancelErr == nil {
+		cancelErr = fmt.Errorf("final state not confirmed")
+	}
+
+	if s.quote != nil && cancelErr != nil {
+		return fmt.Errorf("CRITICAL: failed to cancel maker quote %s on %s: %w", quoteID, s.maker.GetName(), cancelErr)
+	}
+	s.quote = nil
+	return nil
+}
+
+// hedgeFills хеджирует рыночным ордером исполненный, но ещё не покрытый объём
+// Объём хеджа берётся из подтверждённого исполнения (confirmFill): хедж с неизвестным
+// исполнением не засчитывается, а останавливает вход
+func (s *makerSession) hedgeFills(ctx context.Context) error {
+	qty := s.makerQty - s.hedgeQty
+	if qty <= makerQtyEpsilon {
+		return nil
+	}
+
+	s.hedges++
+	clientID := hedgeClientID(s.params, s.hedges)
+	order, err := s.hedge.PlaceMarketOrder(ctx, s.params.Symbol, s.hedgeSide, qty, clientID)
+	if err != nil || order == nil {
+		order, err = s.oe.retrySecondLeg(ctx, s.params.Symbol, s.hedge, s.hedgeSide, qty, clientID, clientID)
+	}
+	if err != nil {
+		s.hedgeFailed = true
+		return fmt.Errorf("hedge %.8f on %s failed: %w", qty, s.hedge.GetName(), err)
+	}
+
+	order = s.oe.confirmFill(ctx, s.hedgeName, s.hedge, order)
+	if order.FilledQty <= 0 {
+		s.hedgeFailed = true
+		return fmt.Errorf("hedge order %s on %s: fill not confirmed (status %q)", order.ID, s.hedge.GetName(), order.Status)
+	}
+	s.hedgeQty += order.FilledQty
+	s.hedgeCost += order.FilledQty * order.AvgFillPrice
+	return nil
+}
+
+// result закрывает непокрытый остаток пассивной ноги и формирует ExecuteResult
+func (s *makerSession) result(err error) *ExecuteResult {
+	var makerAvg float64
+	if s.makerQty > 0 {
+		makerAvg = s.makerCost / s.makerQty
+	}
+
+	if excess := s.makerQty - s.hedgeQty; excess > makerQtyEpsilon {
+		unhedged := &exchange.Order{FilledQty: excess}
+		var rollbackErr error
+		if s.makerSide == exchange.SideBuy {
+			rollbackErr = s.oe.rollbackLong(s.params.Symbol, s.maker, unhedged)
+		} else {
+			rollbackErr = s.oe.rollbackShort(s.params.Symbol, s.maker, unhedged)
+		}
+		if rollbackErr != nil {
+			return &ExecuteResult{
+				Success:     false,
+				Error:       fmt.Errorf("maker-taker hedge failed AND maker rollback failed: hedge=%v, rollback=%w", err, rollbackErr),
+				ShouldPause: true,
+			}
+		}
+		s.makerQty = s.hedgeQty
+	}
+
+	if s.hedgeQty <= makerQtyEpsilon {
+		if err == nil {
+			err = fmt.Errorf("maker quote on %s not filled", s.maker.GetName())
+		}
+		return &ExecuteResult{
+			Success:     false,
+			Error:       err,
+			ShouldPause: s.hedgeFailed,
+		}
+	}
+
+	if err != nil {
+		// Захеджированная часть уже открыта - работаем с ней как с обычной позицией
+		utils.Warnf("maker-taker %s: entered %.8f of %.8f: %v", s.params.Symbol, s.hedgeQty, s.params.Volume, err)
+	}
+
+	makerOrder := &exchange.Order{
+		Symbol:       s.params.Symbol,
+		Side:         s.makerSide,
+		Type:         exchange.OrderTypeLimit,
+		TimeInForce:  exchange.TimeInForcePostOnly,
+		Quantity:     s.params.Volume,
+		FilledQty:    s.hedgeQty,
+		AvgFillPrice: makerAvg,
+		Status:       exchange.OrderStatusFilled,
+	}
+	hedgeOrder := &exchange.Order{
+		Symbol:       s.params.Symbol,
+		Side:         s.hedgeSide,
+		Type:         exchange.OrderTypeMarket,
+		Quantity:     s.hedgeQty,
+		FilledQty:    s.hedgeQty,
+		AvgFillPrice: s.hedgeCost / s.hedgeQty,
+		Status:       exchange.OrderStatusFilled,
+	}
+
+	longOrder, shortOrder := makerOrder, hedgeOrder
+	if s.makerSide == exchange.SideSell {
+		longOrder, shortOrder = hedgeOrder, makerOrder
+	}
+
+	return &ExecuteResult{
+		Success:    true,
+		LongOrder:  longOrder,
+		ShortOrder: shortOrder,
+		Legs: []models.Leg{
+			{
+				Exchange:   s.params.LongExchange,
+				Side:       "long",
+				EntryPrice: longOrder.AvgFillPrice,
+				Quantity:   longOrder.FilledQty,
+			},
+			{
+				Exchange:   s.params.ShortExchange,
+				Side:       "short",
+				EntryPrice: shortOrder.AvgFillPrice,
+				Quantity:   shortOrder.FilledQty,
+			},
+		},
+	}
+}
@@ -0,0 +1,247 @@
+package bot
+
+import (
+	"context"
+	"math"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"arbitrage/internal/config"
+	"arbitrage/internal/exchange"
+)
+
+func newMakerTestSim(venue string, bid, ask float64) *exchange.Sim {
+	cfg := exchange.DefaultSimConfig(venue)
+	cfg.InitialBalance = 100000
+	sim := exchange.NewSim(cfg)
+	sim.SetOrderBook(&exchange.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   []exchange.PriceLevel{{Price: bid, Volume: 10}},
+		Asks:   []exchange.PriceLevel{{Price: ask, Volume: 10}},
+	})
+	return sim
+}
+
+func newMakerTestExecutor(maker, hedge exchange.Exchange) *OrderExecutor {
+	return NewOrderExecutor(map[string]exchange.Exchange{
+		maker.GetName(): maker,
+		hedge.GetName(): hedge,
+	}, config.BotConfig{
+		MaxRetries:           1,
+		RetryBackoff:         time.Millisecond,
+		OrderTimeout:         time.Second,
+		FillConfirmTimeout:   100 * time.Millisecond,
+		MakerQuoteTimeout:    2 * time.Second,
+		MakerRepriceInterval: 5 * time.Millisecond,
+	})
+}
+
+// takeMakerQuote - как только котировка встала, продавец заходит в неё
+func takeMakerQuote(maker *exchange.Sim) {
+	go func() {
+		for i := 0; i < 200; i++ {
+			orders, _ := maker.GetOpenOrders(context.Background(), "BTCUSDT")
+			if len(orders) == 1 {
+				price := orders[0].Price
+				maker.SetOrderBook(&exchange.OrderBook{
+					Symbol: "BTCUSDT",
+					Bids:   []exchange.PriceLevel{{Price: price - 1, Volume: 10}},
+					Asks:   []exchange.PriceLevel{{Price: price, Volume: 10}},
+				})
+				return
+			}
+			time.Sleep(5 * time.Millisecond)
+		}
+	}()
+}
+
+// TestExecuteMakerTaker_HedgesOnFill проверяет хедж рыночным ордером после исполнения котировки
+func TestExecuteMakerTaker_HedgesOnFill(t *testing.T) {
+	maker := newMakerTestSim("bybit", 99, 101)
+	hedge := newMakerTestSim("okx", 102, 103)
+	oe := newMakerTestExecutor(maker, hedge)
+
+	takeMakerQuote(maker)
+	result := oe.ExecuteMakerTaker(context.Background(), ExecuteParams{
+		Symbol:        "BTCUSDT",
+		Volume:        1,
+		LongExchange:  "bybit",
+		ShortExchange: "okx",
+		NOrders:       1,
+		MakerExchange: "bybit",
+		MinSpread:     1,
+	})
+
+	if !result.Success {
+		t.Fatalf("expected success, got error: %v", result.Error)
+	}
+	if result.LongOrder.FilledQty != 1 || result.ShortOrder.FilledQty != 1 {
+		t.Fatalf("expected both legs filled 1, got long=%.4f short=%.4f",
+			result.LongOrder.FilledQty, result.ShortOrder.FilledQty)
+	}
+	if result.ShortOrder.AvgFillPrice != 102 {
+		t.Fatalf("expected hedge at 102, got %.4f", result.ShortOrder.AvgFillPrice)
+	}
+
+	// Котировка не дороже Bid хеджа / (1 + MinSpread)
+	spread := (result.ShortOrder.AvgFillPrice - result.LongOrder.AvgFillPrice) / result.LongOrder.AvgFillPrice * 100
+	if spread < 1-1e-9 {
+		t.Fatalf("expected spread >= 1%%, got %.4f%%", spread)
+	}
+}
+
+// TestExecuteMakerTaker_HedgeFillConfirmed проверяет, что объём хеджа берётся из
+// подтверждённого исполнения, а хедж без подтверждения останавливает вход
+func TestExecuteMakerTaker_HedgeFillConfirmed(t *testing.T) {
+	params := ExecuteParams{
+		Symbol:        "BTCUSDT",
+		Volume:        1,
+		LongExchange:  "bybit",
+		ShortExchange: "okx",
+		NOrders:       1,
+		MakerExchange: "bybit",
+		MinSpread:     1,
+	}
+
+	// REST ответ хеджа без исполнения, исполнение приходит потоком ордеров
+	maker := newMakerTestSim("bybit", 99, 101)
+	hedge := &staleRestExchange{Sim: newMakerTestSim("okx", 102, 103)}
+	oe := newMakerTestExecutor(maker, hedge)
+	hedge.SubscribeOrders(func(order *exchange.Order) { oe.OnOrderUpdate("okx", order) })
+
+	takeMakerQuote(maker)
+	result := oe.ExecuteMakerTaker(context.Background(), params)
+	if !result.Success {
+		t.Fatalf("expected success, got error: %v", result.Error)
+	}
+	if result.ShortOrder.FilledQty != 1 || result.ShortOrder.AvgFillPrice != 102 {
+		t.Fatalf("expected hedge 1 at 102 from order stream, got %.4f at %.4f",
+			result.ShortOrder.FilledQty, result.ShortOrder.AvgFillPrice)
+	}
+
+	// Ни поток, ни REST не подтвердили исполнение - хедж не засчитан, котировка откачена
+	maker = newMakerTestSim("bybit", 99, 101)
+	hedge = &staleRestExchange{Sim: newMakerTestSim("okx", 102, 103)}
+	oe = newMakerTestExecutor(maker, hedge)
+
+	takeMakerQuote(maker)
+	result = oe.ExecuteMakerTaker(context.Background(), params)
+	if result.Success || !result.ShouldPause {
+		t.Fatalf("expected failed entry with pause on unconfirmed hedge, got success=%v pause=%v",
+			result.Success, result.ShouldPause)
+	}
+	positions, _ := maker.GetOpenPositions(context.Background())
+	if len(positions) != 0 {
+		t.Fatalf("expected maker fill rolled back, got %+v", positions[0])
+	}
+}
+
+// restCountingExchange считает REST запросы состояния ордеров и стаканов
+type restCountingExchange struct {
+	*exchange.Sim
+	orders, books atomic.Int32
+}
+
+func (r *restCountingExchange) GetOrder(ctx context.Context, symbol, orderID string) (*exchange.Order, error) {
+	r.orders.Add(1)
+	return r.Sim.GetOrder(ctx, symbol, orderID)
+}
+
+func (r *restCountingExchange) GetOrderBook(ctx context.Context, symbol string, depth int) (*exchange.OrderBook, error) {
+	r.books.Add(1)
+	return r.Sim.GetOrderBook(ctx, symbol, depth)
+}
+
+// TestExecuteMakerTaker_UsesStreams проверяет, что при живых потоках котировка
+// не опрашивает ордер и стаканы через REST
+func TestExecuteMakerTaker_UsesStreams(t *testing.T) {
+	maker := &restCountingExchange{Sim: newMakerTestSim("bybit", 99, 101)}
+	hedge := &restCountingExchange{Sim: newMakerTestSim("okx", 102, 103)}
+	oe := newMakerTestExecutor(maker, hedge)
+	books := NewOrderBookAnalyzer(5, 5*time.Second)
+	oe.SetOrderBooks(books)
+
+	for _, exch := range []*restCountingExchange{maker, hedge} {
+		name := exch.GetName()
+		exch.SubscribeOrders(func(order *exchange.Order) { oe.OnOrderUpdate(name, order) })
+		exch.SubscribeOrderBook("BTCUSDT", 5, func(book *exchange.OrderBook) {
+			books.UpdateOrderBook(book.Symbol, name, toAnalyzerLevels(book.Bids), toAnalyzerLevels(book.Asks))
+		})
+	}
+	maker.SetOrderBook(&exchange.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   []exchange.PriceLevel{{Price: 99, Volume: 10}},
+		Asks:   []exchange.PriceLevel{{Price: 101, Volume: 10}},
+	})
+	hedge.SetOrderBook(&exchange.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   []exchange.PriceLevel{{Price: 102, Volume: 10}},
+		Asks:   []exchange.PriceLevel{{Price: 103, Volume: 10}},
+	})
+
+	takeMakerQuote(maker.Sim)
+	result := oe.ExecuteMakerTaker(context.Background(), ExecuteParams{
+		Symbol:        "BTCUSDT",
+		Volume:        1,
+		LongExchange:  "bybit",
+		ShortExchange: "okx",
+		NOrders:       1,
+		MakerExchange: "bybit",
+		MinSpread:     1,
+	})
+
+	if !result.Success || result.LongOrder.FilledQty != 1 || result.ShortOrder.FilledQty != 1 {
+		t.Fatalf("expected both legs filled 1, got %+v", result)
+	}
+	if n := maker.orders.Load(); n != 0 {
+		t.Fatalf("expected quote fills from order stream, got %d REST order requests", n)
+	}
+	if n := maker.books.Load() + hedge.books.Load(); n != 0 {
+		t.Fatalf("expected prices from order book streams, got %d REST order book requests", n)
+	}
+}
+
+// TestExecuteMakerTaker_NoQuoteWhenSpreadGone проверяет, что котировка не ставится без спреда
+func TestExecuteMakerTaker_NoQuoteWhenSpreadGone(t *testing.T) {
+	maker := newMakerTestSim("bybit", 99, 101)
+	hedge := newMakerTestSim("okx", 99.5, 100)
+	oe := newMakerTestExecutor(maker, hedge)
+
+	result := oe.ExecuteMakerTaker(context.Background(), ExecuteParams{
+		Symbol:        "BTCUSDT",
+		Volume:        1,
+		LongExchange:  "bybit",
+		ShortExchange: "okx",
+		NOrders:       1,
+		MakerExchange: "bybit",
+		MinSpread:     1,
+	})
+
+	if result.Success {
+		t.Fatal("expected failure without spread")
+	}
+	if result.ShouldPause {
+		t.Fatal("unfilled quote must not pause the pair")
+	}
+
+	positions, _ := hedge.GetOpenPositions(context.Background())
+	if len(positions) != 0 {
+		t.Fatalf("expected no hedge positions, got %d", len(positions))
+	}
+	if orders := maker.Orders(); len(orders) != 0 {
+		t.Fatalf("expected no maker orders, got %d", len(orders))
+	}
+}
+
+// TestMakerTakerFees проверяет комиссии входа: мейкер + тейкер пассивной биржи и 2 тейкера хеджа
+func TestMakerTakerFees(t *testing.T) {
+	sc := NewSpreadCalculator(NewPriceTracker(1))
+	sc.SetFee("bybit", 0.0005)
+	sc.SetMakerFee("bybit", 0.0001)
+	sc.SetFee("okx", 0.0004)
+
+	if got := sc.MakerTakerFees("bybit", "okx"); math.Abs(got-0.14) > 1e-9 {
+		t.Fatalf("expected 0.14%%, got %.6f", got)
+	}
+}
@@ -0,0 +1,213 @@
+package bot
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+
+	"arbitrage/internal/exchange"
+	"arbitrage/internal/models"
+)
+
+// ============================================================
+// Плечо и режим маржи (PairConfig.Leverage / PairConfig.MarginMode)
+// ============================================================
+//
+// Биржи не меняют режим маржи при открытой позиции, поэтому настройки
+// применяются при запуске пары (StartPair), а после изменения настроек пары
+// или переподключения биржи - перед следующим входом. Пока настройки не
+// сверены, пара не входит (PairState.marginState). Применённые значения
+// кэшируются по бирже+символу: горячий путь входа проверяет только кэш.
+//
+// Биржа может принять запрос и оставить другое значение (плечо урезано
+// лимитом риска, режим маржи общий для аккаунта), поэтому после установки
+// настройки читаются обратно (exchange.MarginReader) и сверяются.
+
+// MarginSettings - плечо и режим маржи символа на бирже
+type MarginSettings struct {
+	Leverage int
+	Mode     string // exchange.MarginModeCross / exchange.MarginModeIsolated
+}
+
+// ApplyMarginSettings устанавливает режим маржи и плечо символа на биржах
+//
+// Перед установкой плечо проверяется по лимиту биржи (Limits.MaxLeverage).
+// Режим устанавливается раньше плеча: часть бирж хранит плечо отдельно для режима.
+// Возвращает ошибку первой биржи, на которой настройки не применились или
+// прочитанные с биржи значения отличаются от заданных
+func (oe *OrderExecutor) ApplyMarginSettings(ctx context.Context, symbol string, settings MarginSettings, exchanges ...string) error {
+	for _, exchName := range exchanges {
+		key := PositionKey{Account: exchName, Symbol: symbol}
+		if applied, ok := oe.marginApplied.Load(key); ok && applied.(MarginSettings) == settings {
+			continue
+		}
+
+		oe.mu.RLock()
+		exch, ok := oe.exchanges[exchName]
+		oe.mu.RUnlock()
+		if !ok {
+			return fmt.Errorf("exchange %s not found", exchName)
+		}
+
+		if err := applyMarginSettings(ctx, exch, symbol, settings); err != nil {
+			return fmt.Errorf("%s: %w", exchName, err)
+		}
+		oe.marginApplied.Store(key, settings)
+	}
+	return nil
+}
+
+// applyMarginSettings проверяет плечо по лимитам биржи и применяет настройки
+func applyMarginSettings(ctx context.Context, exch exchange.Exchange, symbol string, settings MarginSettings) error {
+	limits, err := exch.GetLimits(ctx, symbol)
+	if err != nil {
+		return fmt.Errorf("failed to get limits: %w", err)
+	}
+	if limits.MaxLeverage > 0 && settings.Leverage > limits.MaxLeverage {
+		return fmt.Errorf("leverage %dx exceeds max %dx for %s", settings.Leverage, limits.MaxLeverage, symbol)
+	}
+
+	if err := exch.SetMarginMode(ctx, symbol, settings.Mode); err != nil {
+		return fmt.Errorf("failed to set %s margin mode: %w", settings.Mode, err)
+	}
+	if err := exch.SetLeverage(ctx, symbol, settings.Leverage); err != nil {
+		return fmt.Errorf("failed to set leverage %dx: %w", settings.Leverage, err)
+	}
+
+	reader, ok := exch.(exchange.MarginReader)
+	if !ok {
+		return nil
+	}
+	leverage, mode, err := reader.GetMarginSettings(ctx, symbol)
+	switch {
+	case errors.Is(err, exchange.ErrMarginModeNotReported):
+		// Без позиции биржа не хранит режим (OKX, HTX): режим задают ордера, сверяется плечо
+		mode = settings.Mode
+	case err != nil:
+		return fmt.Errorf("failed to read margin settings: %w", err)
+	}
+	if leverage != settings.Leverage || mode != settings.Mode {
+		return fmt.Errorf("exchange reports %dx %s for %s after setting %dx %s",
+			leverage, mode, symbol, settings.Leverage, settings.Mode)
+	}
+	return nil
+}
+
+// MarginSettingsApplied проверяет по кэшу (без запросов к биржам), что настройки
+// символа применены и сверены на всех биржах
+func (oe *OrderExecutor) MarginSettingsApplied(symbol string, settings MarginSettings, exchanges ...string) bool {
+	for _, exchName := range exchanges {
+		applied, ok := oe.marginApplied.Load(PositionKey{Account: exchName, Symbol: symbol})
+		if !ok || applied.(MarginSettings) != settings {
+			return false
+		}
+	}
+	return true
+}
+
+// ResetMarginSettings сбрасывает кэш применённых настроек символа
+// Вызывается при изменении плеча или режима маржи пары
+func (oe *OrderExecutor) ResetMarginSettings(symbol string) {
+	resetMarginApplied(&oe.marginApplied, func(key PositionKey) bool { return key.Symbol == symbol })
+}
+
+// ResetExchangeMarginSettings сбрасывает кэш применённых настроек биржи
+// Вызывается при (пере)подключении биржи: новый адаптер не знает режимов символов
+func (oe *OrderExecutor) ResetExchangeMarginSettings(exchName string) {
+	resetMarginApplied(&oe.marginApplied, func(key PositionKey) bool { return key.Account == exchName })
+}
+
+// resetMarginApplied удаляет из кэша ключи, подходящие под match
+func resetMarginApplied(applied *sync.Map, match func(PositionKey) bool) {
+	applied.Range(func(key, _ interface{}) bool {
+		if match(key.(PositionKey)) {
+			applied.Delete(key)
+		}
+		return true
+	})
+}
+
+// Состояние проверки настроек маржи пары (PairState.marginState)
+const (
+	marginVerified  int32 = iota // настройки сверены на аккаунтах пары, вход разрешён
+	marginPending                // настройки не проверены, вход запрещён
+	marginVerifying              // идёт проверка, вход запрещён
+)
+
+// pairMarginAccounts возвращает подключенные аккаунты, на которых может торговать пара
+func (e *Engine) pairMarginAccounts(ps *PairState) []string {
+	e.exchMu.RLock()
+	defer e.exchMu.RUnlock()
+
+	accounts := ps.GetAccounts()
+	if accounts == nil {
+		accounts = make([]string, 0, len(e.exchanges))
+		for name := range e.exchanges {
+			accounts = append(accounts, name)
+		}
+		sort.Strings(accounts)
+		return accounts
+	}
+
+	connected := make([]string, 0, len(accounts))
+	for _, name := range accounts {
+		if _, ok := e.exchanges[name]; ok {
+			connected = append(connected, name)
+		}
+	}
+	return connected
+}
+
+// applyPairMargin применяет и сверяет плечо и режим маржи пары на её аккаунтах
+// Вход разрешается, только если настройки пары не менялись во время проверки
+func (e *Engine) applyPairMargin(ps *PairState) error {
+	atomic.StoreInt32(&ps.marginState, marginVerifying)
+
+	settings := ps.GetMarginSettings()
+	for _, account := range e.pairMarginAccounts(ps) {
+		ctx, cancel := context.WithTimeout(e.ctx, e.cfg.Bot.OrderTimeout)
+		err := e.orderExec.ApplyMarginSettings(ctx, ps.Config.Symbol, settings, account)
+		cancel()
+		if err != nil {
+			atomic.CompareAndSwapInt32(&ps.marginState, marginVerifying, marginPending)
+			return err
+		}
+	}
+
+	atomic.CompareAndSwapInt32(&ps.marginState, marginVerifying, marginVerified)
+	return nil
+}
+
+// verifyPairMargin проверяет настройки пары в фоне (запускается из горячего пути)
+// При ошибке пара, ожидающая входа, ставится на паузу
+func (e *Engine) verifyPairMargin(ps *PairState) {
+	err := e.applyPairMargin(ps)
+	if err == nil {
+		return
+	}
+
+	ps.mu.Lock()
+	defer ps.mu.Unlock()
+
+	if ps.Runtime.State == models.StateReady {
+		atomic.StoreInt32(&ps.isReady, 0)
+		ps.Runtime.State = models.StatePaused
+		ps.Config.Status = "paused"
+	}
+	e.notifyError(ps, fmt.Errorf("margin settings: %w", err))
+}
+
+// resetPairMargin запрещает вход пар, подходящих под match, до новой проверки настроек
+func (e *Engine) resetPairMargin(match func(*PairState) bool) {
+	e.pairsMu.RLock()
+	defer e.pairsMu.RUnlock()
+
+	for _, ps := range e.pairs {
+		if match(ps) {
+			atomic.StoreInt32(&ps.marginState, marginPending)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package bot
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"arbitrage/internal/exchange"
+	"arbitrage/internal/models"
+)
+
+// TestApplyMarginSettings проверяет установку плеча и режима маржи на обеих биржах
+func TestApplyMarginSettings(t *testing.T) {
+	long := newMakerTestSim("bybit", 99, 101)
+	short := newMakerTestSim("okx", 99, 101)
+	oe := newMakerTestExecutor(long, short)
+	ctx := context.Background()
+
+	settings := MarginSettings{Leverage: 5, Mode: exchange.MarginModeIsolated}
+	if err := oe.ApplyMarginSettings(ctx, "BTCUSDT", settings, "bybit", "okx"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, sim := range []*exchange.Sim{long, short} {
+		if sim.Leverage("BTCUSDT") != 5 || sim.MarginMode("BTCUSDT") != exchange.MarginModeIsolated {
+			t.Fatalf("%s: expected 5x isolated, got %dx %q",
+				sim.GetName(), sim.Leverage("BTCUSDT"), sim.MarginMode("BTCUSDT"))
+		}
+	}
+
+	// Открытая позиция не мешает повторному входу: настройки уже применены
+	if _, err := long.PlaceMarketOrder(ctx, "BTCUSDT", exchange.SideBuy, 1, ""); err != nil {
+		t.Fatalf("failed to open position: %v", err)
+	}
+	if err := oe.ApplyMarginSettings(ctx, "BTCUSDT", settings, "bybit", "okx"); err != nil {
+		t.Fatalf("expected cached settings, got %v", err)
+	}
+
+	// Плечо выше лимита биржи отклоняется до запросов к бирже
+	oe.ResetMarginSettings("BTCUSDT")
+	err := oe.ApplyMarginSettings(ctx, "BTCUSDT", MarginSettings{Leverage: 200, Mode: exchange.MarginModeIsolated}, "okx")
+	if err == nil {
+		t.Fatal("expected error for leverage above exchange max")
+	}
+	if short.Leverage("BTCUSDT") != 5 {
+		t.Fatalf("expected leverage unchanged, got %dx", short.Leverage("BTCUSDT"))
+	}
+}
+
+// cappedLeverageExchange принимает плечо, но молча урезает его лимитом риска
+type cappedLeverageExchange struct {
+	*exchange.Sim
+	cap int
+}
+
+func (c *cappedLeverageExchange) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if leverage > c.cap {
+		leverage = c.cap
+	}
+	return c.Sim.SetLeverage(ctx, symbol, leverage)
+}
+
+// TestApplyMarginSettings_VenueMismatch проверяет, что настройки сверяются с биржей:
+// урезанное плечо - ошибка входа, а не кэшированный успех
+func TestApplyMarginSettings_VenueMismatch(t *testing.T) {
+	capped := &cappedLeverageExchange{Sim: newMakerTestSim("bybit", 99, 101), cap: 3}
+	oe := newFillsTestExecutor(map[string]exchange.Exchange{"bybit": capped})
+	ctx := context.Background()
+
+	settings := MarginSettings{Leverage: 5, Mode: exchange.MarginModeIsolated}
+	if err := oe.ApplyMarginSettings(ctx, "BTCUSDT", settings, "bybit"); err == nil {
+		t.Fatal("expected error when exchange keeps 3x after setting 5x")
+	}
+	if _, ok := oe.marginApplied.Load(PositionKey{Account: "bybit", Symbol: "BTCUSDT"}); ok {
+		t.Fatal("mismatched settings must not be cached")
+	}
+
+	if err := oe.ApplyMarginSettings(ctx, "BTCUSDT", MarginSettings{Leverage: 3, Mode: exchange.MarginModeIsolated}, "bybit"); err != nil {
+		t.Fatalf("expected matching settings to apply, got %v", err)
+	}
+}
+
+// modeNotReportedExchange - биржа без режима маржи символа (как OKX и HTX без позиции)
+type modeNotReportedExchange struct {
+	*cappedLeverageExchange
+}
+
+func (m *modeNotReportedExchange) GetMarginSettings(ctx context.Context, symbol string) (int, string, error) {
+	leverage, _, err := m.Sim.GetMarginSettings(ctx, symbol)
+	if err != nil {
+		return 0, "", err
+	}
+	return leverage, "", exchange.ErrMarginModeNotReported
+}
+
+// TestApplyMarginSettings_ModeNotReported проверяет, что без режима с биржи сверяется плечо
+func TestApplyMarginSettings_ModeNotReported(t *testing.T) {
+	exch := &modeNotReportedExchange{&cappedLeverageExchange{Sim: newMakerTestSim("okx", 99, 101), cap: 3}}
+	oe := newFillsTestExecutor(map[string]exchange.Exchange{"okx": exch})
+	ctx := context.Background()
+
+	if err := oe.ApplyMarginSettings(ctx, "BTCUSDT", MarginSettings{Leverage: 3, Mode: exchange.MarginModeCross}, "okx"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := oe.ApplyMarginSettings(ctx, "BTCUSDT", MarginSettings{Leverage: 5, Mode: exchange.MarginModeCross}, "okx"); err == nil {
+		t.Fatal("expected error when exchange keeps 3x after setting 5x")
+	}
+}
+
+// TestStartPair_VerifiesMarginSettings проверяет, что настройки маржи сверяются при запуске
+// пары, а после изменения настроек вход ждёт новой проверки
+func TestStartPair_VerifiesMarginSettings(t *testing.T) {
+	e := newTestEngine()
+	e.AddExchange("bybit", &cappedLeverageExchange{Sim: newMakerTestSim("bybit", 99, 101), cap: 3})
+	okx := newMakerTestSim("okx", 99, 101)
+	e.AddExchange("okx", okx)
+	cfg := models.PairConfig{ID: 1, Symbol: "BTCUSDT", VolumeAsset: 1, Leverage: 5, MarginMode: models.MarginModeIsolated}
+	e.AddPair(&cfg)
+	ps := e.pairs[1]
+
+	// Биржа урезала плечо - пара не запускается
+	if err := e.StartPair(1); err == nil {
+		t.Fatal("expected StartPair to fail when exchange keeps 3x after setting 5x")
+	}
+	if atomic.LoadInt32(&ps.isReady) != 0 || ps.Runtime.State != models.StatePaused {
+		t.Fatalf("expected pair to stay paused, got state %s", ps.Runtime.State)
+	}
+
+	cfg.Leverage = 3
+	e.UpdatePairConfig(1, &cfg)
+	if err := e.StartPair(1); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if atomic.LoadInt32(&ps.marginState) != marginVerified || atomic.LoadInt32(&ps.isReady) != 1 {
+		t.Fatal("expected started pair with verified margin settings")
+	}
+	if okx.Leverage("BTCUSDT") != 3 || okx.MarginMode("BTCUSDT") != exchange.MarginModeIsolated {
+		t.Fatalf("expected 3x isolated on okx, got %dx %q", okx.Leverage("BTCUSDT"), okx.MarginMode("BTCUSDT"))
+	}
+
+	// Новое плечо: вход запрещён, пока проверка из горячего пути не применит его на биржах
+	cfg.Leverage = 2
+	e.UpdatePairConfig(1, &cfg)
+	if atomic.LoadInt32(&ps.marginState) != marginPending {
+		t.Fatal("expected entries blocked after margin settings change")
+	}
+	e.checkArbitrageOpportunity(ps)
+	for deadline := time.Now().Add(time.Second); atomic.LoadInt32(&ps.marginState) != marginVerified; time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatal("margin settings were not verified after config change")
+		}
+	}
+	if okx.Leverage("BTCUSDT") != 2 {
+		t.Fatalf("expected 2x on okx, got %dx", okx.Leverage("BTCUSDT"))
+	}
+	if ps.Runtime.State != models.StateReady {
+		t.Fatalf("expected pair ready after verification, got %s", ps.Runtime.State)
+	}
+}
+
+// TestCheckMarginRequirement_UsesLeverage проверяет расчёт маржи по плечу пары
+func TestCheckMarginRequirement_UsesLeverage(t *testing.T) {
+	tracker := NewPriceTracker(1)
+	detector := NewArbitrageDetector(tracker, NewSpreadCalculator(tracker), nil, nil)
+	detector.UpdateMarginCache("bybit", 300)
+	detector.UpdateMarginCache("okx", 300)
+
+	// notional 1000 USDT: 1x требует 1000 на каждой бирже, 5x - 200
+	if ok, _ := detector.checkMarginRequirement("bybit", "okx", "BTCUSDT", 10, 100, 1); ok {
+		t.Fatal("expected insufficient margin at 1x")
+	}
+	if ok, reason := detector.checkMarginRequirement("bybit", "okx", "BTCUSDT", 10, 100, 5); !ok {
+		t.Fatalf("expected sufficient margin at 5x, got %s", reason)
+	}
+
+	ps := &PairState{Config: &models.PairConfig{Leverage: 5}}
+	ps.setMarginSettings(ps.Config)
+	if got := ps.GetMarginSettings(); got.Leverage != 5 || got.Mode != exchange.MarginModeCross {
+		t.Fatalf("expected 5x cross, got %dx %s", got.Leverage, got.Mode)
+	}
+}
@@ -94,8 +94,9 @@ var TradesTotal = promauto.NewCounterVec(
 )
 
 // PnlTotal - суммарный PNL в USDT
-var PnlTotal = promauto.NewCounter(
-	prometheus.CounterOpts{
+// Gauge, а не Counter: PNL сделки бывает отрицательным
+var PnlTotal = promauto.NewGauge(
+	prometheus.GaugeOpts{
 		Namespace: "arbitrage",
 		Subsystem: "trading",
 		Name:      "pnl_total_usdt",
@@ -0,0 +1,26 @@
+package bot
+
+// TradeObserver получает торговые решения движка
+//
+// Используется backtest для построения отчёта (сделки, slippage, причины отказов).
+// В production не устанавливается.
+//
+// Вызовы синхронные и могут происходить под ps.mu пары -
+// реализация должна быть быстрой и не вызывать методы Engine.
+type TradeObserver interface {
+	// OnEntryRejected - спред прошёл быструю проверку, но полная проверка условий входа отказала
+	OnEntryRejected(pairID int, reason string)
+
+	// OnEntryDecision - движок решил входить; opp содержит оценку цен
+	// OrderBookAnalyzer (VWAP по стакану), volume - объём после валидации лимитов
+	OnEntryDecision(pairID int, opp ArbitrageOpportunity, volume float64)
+
+	// OnTradeOpened - обе ноги открыты
+	OnTradeOpened(pairID int, result *ExecuteResult)
+
+	// OnEntryFailed - вход не удался (откат или пауза)
+	OnEntryFailed(pairID int, err error)
+
+	// OnTradeClosed - позиция закрыта по условию выхода
+	OnTradeClosed(pairID int, result *ExecuteResult, reason ExitReason)
+}
@@ -3,6 +3,7 @@ package bot
 import (
 	"context"
 	"fmt"
+	"math"
 	"strings"
 	"sync"
 	"time"
@@ -49,6 +50,15 @@ type OrderExecutor struct {
 	exchanges map[string]exchange.Exchange
 	cfg       config.BotConfig
 	mu        sync.RWMutex
+
+	// Применённые плечо и режим маржи: PositionKey -> MarginSettings (см. ApplyMarginSettings)
+	marginApplied sync.Map
+
+	// Обновления ордеров из потоков бирж (см. OnOrderUpdate)
+	fills *OrderTracker
+
+	// Стаканы из потоков бирж для котировки maker_taker (nil - только REST)
+	books *OrderBookAnalyzer
 }
 
 // ExecuteParams - параметры для исполнения арбитража
@@ -58,6 +68,16 @@ type ExecuteParams struct {
 	LongExchange  string  // биржа для лонга
 	ShortExchange string  // биржа для шорта
 	NOrders       int     // на сколько частей разбить
+
+	// Режим maker_taker (см. ExecuteMakerTaker)
+	MakerExchange string  // биржа post-only ноги
+	MinSpread     float64 // минимальный спред котировки к цене хеджа, % (с комиссиями)
+
+	// Клиентские ID ордеров (см. clientid.go)
+	PairID  int       // ID пары
+	EntryAt time.Time // начало входа, общее для всех частей (пусто - время вызова)
+	Part    int       // номер части частичного входа
+	Attempt int       // номер попытки входа (ExecuteWithRetry)
 }
 
 // ExecuteResult - результат исполнения
@@ -90,16 +110,27 @@ type CloseParams struct {
 type LegResult struct {
 	Order *exchange.Order
 	Error error
+
+	// ClientOrderID - клиентский ID последнего отправленного ордера ноги
+	ClientOrderID string
 }
 
 // NewOrderExecutor создаёт исполнитель
+// Исполнитель хранит свою копию карты бирж: изменения вносятся через AddExchange/RemoveExchange
 func NewOrderExecutor(exchanges map[string]exchange.Exchange, cfg config.BotConfig) *OrderExecutor {
 	return &OrderExecutor{
-		exchanges: exchanges,
+		exchanges: copyExchanges(exchanges),
 		cfg:       cfg,
+		fills:     NewOrderTracker(),
 	}
 }
 
+// SetOrderBooks подключает стаканы из потоков бирж (SubscribeOrderBook)
+// Вызывать до начала торговли
+func (oe *OrderExecutor) SetOrderBooks(books *OrderBookAnalyzer) {
+	oe.books = books
+}
+
 // ExecuteParallel выполняет вход в арбитраж ПАРАЛЛЕЛЬНО на обеих биржах
 //
 // Тайминги:
@@ -134,26 +165,37 @@ func (oe *OrderExecutor) ExecuteParallel(ctx context.Context, params ExecutePara
 		partVolume = params.Volume / float64(params.NOrders)
 	}
 
+	if params.EntryAt.IsZero() {
+		params.EntryAt = time.Now()
+	}
+
 	// WaitGroup для отслеживания завершения горутин (FIX race condition)
 	var wg sync.WaitGroup
 	wg.Add(2)
+	startedAt := time.Now()
 
 	// ПАРАЛЛЕЛЬНАЯ отправка ордеров
 	go func() {
 		defer wg.Done()
-		order, err := longExch.PlaceMarketOrder(ctx, params.Symbol, exchange.SideBuy, partVolume)
+		res := oe.placeLeg(ctx, longExch, params, legLong, exchange.SideBuy, partVolume)
+		if res.Order != nil {
+			oe.fills.Claim(params.LongExchange, res.Order.ID)
+		}
 		// Безопасная запись - если канал переполнен, не блокируемся
 		select {
-		case longCh <- LegResult{Order: order, Error: err}:
+		case longCh <- res:
 		default:
 		}
 	}()
 
 	go func() {
 		defer wg.Done()
-		order, err := shortExch.PlaceMarketOrder(ctx, params.Symbol, exchange.SideSell, partVolume)
+		res := oe.placeLeg(ctx, shortExch, params, legShort, exchange.SideSell, partVolume)
+		if res.Order != nil {
+			oe.fills.Claim(params.ShortExchange, res.Order.ID)
+		}
 		select {
-		case shortCh <- LegResult{Order: order, Error: err}:
+		case shortCh <- res:
 		default:
 		}
 	}()
@@ -169,22 +211,33 @@ func (oe *OrderExecutor) ExecuteParallel(ctx context.Context, params ExecutePara
 		case shortResult = <-shortCh:
 			shortReceived = true
 		case <-ctx.Done():
-			// Timeout - асинхронно ждём завершения горутин и возвращаем каналы в пул
+			// Timeout - асинхронно ждём завершения горутин, откатываем ноги без ответа
+			// (ответ мог прийти позже или не прийти вовсе) и возвращаем каналы в пул
+			timedOutAt := time.Now()
+			lateLong, lateShort := !longReceived, !shortReceived
 			go func() {
 				wg.Wait()
+				if lateLong {
+					oe.reconcileTimedOutLeg(params, legLong, params.LongExchange, longExch, exchange.SideBuy, longCh, startedAt, timedOutAt)
+				}
+				if lateShort {
+					oe.reconcileTimedOutLeg(params, legShort, params.ShortExchange, shortExch, exchange.SideSell, shortCh, startedAt, timedOutAt)
+				}
 				releaseLegResultChan(longCh)
 				releaseLegResultChan(shortCh)
 			}()
 
-			// Откатываем то, что успело исполниться
+			// Откатываем то, что успело исполниться (объём сверяется с потоком ордеров)
 			var rollbackErrors []error
 			if longReceived && longResult.Error == nil && longResult.Order != nil {
-				if err := oe.rollbackLongWithCtx(context.Background(), params.Symbol, longExch, longResult.Order); err != nil {
+				order := oe.confirmFill(context.Background(), params.LongExchange, longExch, longResult.Order)
+				if err := oe.rollbackLongWithCtx(context.Background(), params.Symbol, longExch, order); err != nil {
 					rollbackErrors = append(rollbackErrors, err)
 				}
 			}
 			if shortReceived && shortResult.Error == nil && shortResult.Order != nil {
-				if err := oe.rollbackShortWithCtx(context.Background(), params.Symbol, shortExch, shortResult.Order); err != nil {
+				order := oe.confirmFill(context.Background(), params.ShortExchange, shortExch, shortResult.Order)
+				if err := oe.rollbackShortWithCtx(context.Background(), params.Symbol, shortExch, order); err != nil {
 					rollbackErrors = append(rollbackErrors, err)
 				}
 			}
@@ -220,6 +273,9 @@ func (oe *OrderExecutor) handleResults(
 		partVolume = params.Volume / float64(params.NOrders)
 	}
 
+	// REST ответ может не содержать исполнение - сверяем с потоком ордеров
+	longRes, shortRes = oe.confirmLegs(ctx, params.LongExchange, longExch, longRes, params.ShortExchange, shortExch, shortRes)
+
 	// Оба успешны - проверяем что Order != nil (защита от edge case биржевого API)
 	if longRes.Error == nil && shortRes.Error == nil {
 		if longRes.Order == nil || shortRes.Order == nil {
@@ -228,25 +284,7 @@ func (oe *OrderExecutor) handleResults(
 				Error:   fmt.Errorf("unexpected nil order: long=%v, short=%v", longRes.Order, shortRes.Order),
 			}
 		}
-		return &ExecuteResult{
-			Success:    true,
-			LongOrder:  longRes.Order,
-			ShortOrder: shortRes.Order,
-			Legs: []models.Leg{
-				{
-					Exchange:   params.LongExchange,
-					Side:       "long",
-					EntryPrice: longRes.Order.AvgFillPrice,
-					Quantity:   longRes.Order.FilledQty,
-				},
-				{
-					Exchange:   params.ShortExchange,
-					Side:       "short",
-					EntryPrice: shortRes.Order.AvgFillPrice,
-					Quantity:   shortRes.Order.FilledQty,
-				},
-			},
-		}
+		return oe.entryResult(params, longExch, shortExch, longRes.Order, shortRes.Order)
 	}
 
 	// Лонг успешен, шорт провалился - откат лонга
@@ -256,27 +294,10 @@ func (oe *OrderExecutor) handleResults(
 			retryQty = partVolume
 		}
 
-		retryOrder, retryErr := oe.retrySecondLeg(ctx, params.Symbol, shortExch, exchange.SideSell, retryQty)
+		retryOrder, retryErr := oe.retrySecondLeg(ctx, params.Symbol, shortExch, exchange.SideSell, retryQty,
+			shortRes.ClientOrderID, legClientID(params, legShort))
 		if retryErr == nil && retryOrder != nil {
-			return &ExecuteResult{
-				Success:    true,
-				LongOrder:  longRes.Order,
-				ShortOrder: retryOrder,
-				Legs: []models.Leg{
-					{
-						Exchange:   params.LongExchange,
-						Side:       "long",
-						EntryPrice: longRes.Order.AvgFillPrice,
-						Quantity:   longRes.Order.FilledQty,
-					},
-					{
-						Exchange:   params.ShortExchange,
-						Side:       "short",
-						EntryPrice: retryOrder.AvgFillPrice,
-						Quantity:   retryOrder.FilledQty,
-					},
-				},
-			}
+			return oe.entryResult(params, longExch, shortExch, longRes.Order, retryOrder)
 		}
 
 		rollbackErr := oe.rollbackLong(params.Symbol, longExch, longRes.Order)
@@ -301,27 +322,10 @@ func (oe *OrderExecutor) handleResults(
 			retryQty = partVolume
 		}
 
-		retryOrder, retryErr := oe.retrySecondLeg(ctx, params.Symbol, longExch, exchange.SideBuy, retryQty)
+		retryOrder, retryErr := oe.retrySecondLeg(ctx, params.Symbol, longExch, exchange.SideBuy, retryQty,
+			longRes.ClientOrderID, legClientID(params, legLong))
 		if retryErr == nil && retryOrder != nil {
-			return &ExecuteResult{
-				Success:    true,
-				LongOrder:  retryOrder,
-				ShortOrder: shortRes.Order,
-				Legs: []models.Leg{
-					{
-						Exchange:   params.LongExchange,
-						Side:       "long",
-						EntryPrice: retryOrder.AvgFillPrice,
-						Quantity:   retryOrder.FilledQty,
-					},
-					{
-						Exchange:   params.ShortExchange,
-						Side:       "short",
-						EntryPrice: shortRes.Order.AvgFillPrice,
-						Quantity:   shortRes.Order.FilledQty,
-					},
-				},
-			}
+			return oe.entryResult(params, longExch, shortExch, retryOrder, shortRes.Order)
 		}
 
 		rollbackErr := oe.rollbackShort(params.Symbol, shortExch, shortRes.Order)
@@ -346,6 +350,71 @@ func (oe *OrderExecutor) handleResults(
 	}
 }
 
+// legQtyTolerance - относительная разница объёмов ног, при которой ноги считаются равными
+// (погрешность пересчёта контрактов в монеты)
+const legQtyTolerance = 1e-9
+
+// entryResult возвращает результат успешного входа с выровненными объёмами ног
+func (oe *OrderExecutor) entryResult(params ExecuteParams, longExch, shortExch exchange.Exchange, longOrder, shortOrder *exchange.Order) *ExecuteResult {
+	longQty, shortQty := oe.balanceLegs(params.Symbol, longExch, shortExch, longOrder, shortOrder)
+	return &ExecuteResult{
+		Success:    true,
+		LongOrder:  longOrder,
+		ShortOrder: shortOrder,
+		Legs: []models.Leg{
+			{
+				Exchange:   params.LongExchange,
+				Side:       "long",
+				EntryPrice: longOrder.AvgFillPrice,
+				Quantity:   longQty,
+			},
+			{
+				Exchange:   params.ShortExchange,
+				Side:       "short",
+				EntryPrice: shortOrder.AvgFillPrice,
+				Quantity:   shortQty,
+			},
+		},
+	}
+}
+
+// balanceLegs выравнивает ноги, исполненные разным объёмом (частичное исполнение,
+// разное округление бирж): излишек большей ноги закрывается ордером отката.
+// Возвращает объёмы ног после выравнивания; если закрыть излишек не удалось,
+// ноги остаются как есть - позиция сопровождается с фактическими объёмами.
+// Ноги с неизвестным исполнением (нулевой объём) не выравниваются.
+func (oe *OrderExecutor) balanceLegs(symbol string, longExch, shortExch exchange.Exchange, longOrder, shortOrder *exchange.Order) (longQty, shortQty float64) {
+	longQty, shortQty = longOrder.FilledQty, shortOrder.FilledQty
+	if longQty <= 0 || shortQty <= 0 || math.Abs(longQty-shortQty) <= legQtyTolerance*math.Max(longQty, shortQty) {
+		return longQty, shortQty
+	}
+
+	exch, order, side, excess := longExch, longOrder, exchange.SideLong, longQty-shortQty
+	if excess < 0 {
+		exch, order, side, excess = shortExch, shortOrder, exchange.SideShort, -excess
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), oe.cfg.OrderTimeout)
+	_, err := exchange.PlaceCloseOrder(ctx, exch, symbol, side, excess, rollbackClientID(order))
+	cancel()
+
+	if logger := utils.GetGlobalLogger(); logger != nil {
+		if err != nil {
+			logger.Sugar().Errorf("legs filled unequally (long %.8f, short %.8f %s), %s excess %.8f on %s not closed: %v",
+				longQty, shortQty, symbol, side, excess, exch.GetName(), err)
+		} else {
+			logger.Sugar().Warnf("legs filled unequally (long %.8f, short %.8f %s), %s excess %.8f on %s closed",
+				longQty, shortQty, symbol, side, excess, exch.GetName())
+		}
+	}
+	if err != nil {
+		return longQty, shortQty
+	}
+
+	qty := math.Min(longQty, shortQty)
+	return qty, qty
+}
+
 // rollbackLong закрывает лонг при ошибке шорта (использует внутренний таймаут)
 func (oe *OrderExecutor) rollbackLong(symbol string, exch exchange.Exchange, order *exchange.Order) error {
 	return oe.rollbackLongWithCtx(context.Background(), symbol, exch, order)
@@ -362,7 +431,7 @@ func (oe *OrderExecutor) rollbackLongWithCtx(parentCtx context.Context, symbol s
 	defer cancel()
 
 	// Продаём то, что купили
-	_, err := exch.PlaceMarketOrder(ctx, symbol, exchange.SideSell, order.FilledQty)
+	_, err := exchange.PlaceCloseOrder(ctx, exch, symbol, exchange.SideLong, order.FilledQty, rollbackClientID(order))
 	if err != nil {
 		return fmt.Errorf("CRITICAL: failed to rollback long on %s: %w", exch.GetName(), err)
 	}
@@ -385,7 +454,7 @@ func (oe *OrderExecutor) rollbackShortWithCtx(parentCtx context.Context, symbol
 	defer cancel()
 
 	// Покупаем то, что продали
-	_, err := exch.PlaceMarketOrder(ctx, symbol, exchange.SideBuy, order.FilledQty)
+	_, err := exchange.PlaceCloseOrder(ctx, exch, symbol, exchange.SideShort, order.FilledQty, rollbackClientID(order))
 	if err != nil {
 		return fmt.Errorf("CRITICAL: failed to rollback short on %s: %w", exch.GetName(), err)
 	}
@@ -394,27 +463,68 @@ func (oe *OrderExecutor) rollbackShortWithCtx(parentCtx context.Context, symbol
 
 // retrySecondLeg пытается открыть вторую ногу с экспоненциальным backoff
 // Возвращает Order при успешном исполнении или ошибку после исчерпания попыток
-func (oe *OrderExecutor) retrySecondLeg(ctx context.Context, symbol string, exch exchange.Exchange, side exchange.Side, qty float64) (*exchange.Order, error) {
+//
+// sentID - клиентский ID уже отправленного ордера ноги, повторы получают ID
+// clientID + r<номер>. Перед каждым повтором и после исчерпания попыток
+// последний отправленный ордер запрашивается по клиентскому ID: если биржа
+// его приняла, он возвращается вместо нового (см. findPlaced).
+func (oe *OrderExecutor) retrySecondLeg(ctx context.Context, symbol string, exch exchange.Exchange, side string, qty float64, sentID, clientID string) (*exchange.Order, error) {
 	// Используем агрессивный бэкофф, но ограничиваем максимальной задержкой чтобы не копить латентность
 	cfg := retry.Config{
-		MaxRetries:   oe.cfg.Bot.MaxRetries,
-		InitialDelay: oe.cfg.Bot.RetryBackoff,
-		MaxDelay:     oe.cfg.Bot.RetryBackoff * 8,
+		MaxRetries:   oe.cfg.MaxRetries,
+		InitialDelay: oe.cfg.RetryBackoff,
+		MaxDelay:     oe.cfg.RetryBackoff * 8,
 		Multiplier:   2.0,
 		JitterFactor: 0.1,
 		RetryIf:      retry.RetryIfNotContext,
 	}
+	return oe.retryOrder(ctx, symbol, exch, side, qty, sentID, clientID, false, cfg)
+}
+
+// placeMarket размещает рыночный ордер стороны side
+// closing - ордер закрытия позиции противоположной стороны: в режиме hedge обычный
+// ордер открыл бы встречную позицию, поэтому закрытие идёт через PlaceCloseOrder
+func placeMarket(ctx context.Context, exch exchange.Exchange, symbol, side string, qty float64, clientID string, closing bool) (*exchange.Order, error) {
+	if closing {
+		return exchange.PlaceCloseOrder(ctx, exch, symbol, exchange.ClosedPositionSide(side), qty, clientID)
+	}
+	return exch.PlaceMarketOrder(ctx, symbol, side, qty, clientID)
+}
+
+// retryOrder повторяет рыночный ордер по политике cfg без дублей (см. retrySecondLeg)
+// closing - ордер закрытия позиции (см. placeMarket)
+func (oe *OrderExecutor) retryOrder(ctx context.Context, symbol string, exch exchange.Exchange, side string, qty float64, sentID, clientID string, closing bool, cfg retry.Config) (*exchange.Order, error) {
+	attempt := 0
+	order, err := retry.DoWithResult(ctx, func() (*exchange.Order, error) {
+		if sentID != "" {
+			placed, err := oe.findPlaced(ctx, exch, symbol, sentID)
+			if err != nil || placed != nil {
+				return placed, err
+			}
+		}
 
-	return retry.DoWithResult(ctx, func() (*exchange.Order, error) {
-		order, err := exch.PlaceMarketOrder(ctx, symbol, side, qty)
+		attempt++
+		sentID = retryClientID(clientID, attempt)
+		order, err := placeMarket(ctx, exch, symbol, side, qty, sentID, closing)
 		if err != nil {
 			return nil, err
 		}
 		if order == nil {
-			return nil, fmt.Errorf("retrySecondLeg: nil order from %s", exch.GetName())
+			return nil, fmt.Errorf("retryOrder: nil order from %s", exch.GetName())
 		}
 		return order, nil
 	}, cfg)
+	if err == nil || sentID == "" {
+		return order, err
+	}
+
+	// Последний ордер мог быть принят без ответа - контекст входа уже может быть отменён
+	lookupCtx, cancel := context.WithTimeout(context.Background(), oe.cfg.OrderTimeout)
+	defer cancel()
+	if placed, lookupErr := oe.findPlaced(lookupCtx, exch, symbol, sentID); lookupErr == nil && placed != nil {
+		return placed, nil
+	}
+	return nil, err
 }
 
 // CloseParallel закрывает позиции параллельно
@@ -438,6 +548,34 @@ func (oe *OrderExecutor) CloseParallel(ctx context.Context, params CloseParams)
 	return oe.closeTwoLegs(ctx, symbol, legs)
 }
 
+// placeClose отправляет рыночный ордер закрытия ноги
+// На здоровой бирже - одна попытка. Деградировавшей или отключённой бирже закрытие
+// повторяется по closeRetryConfig, без дублей - по клиентским ID (см. retrySecondLeg)
+func (oe *OrderExecutor) placeClose(ctx context.Context, symbol, exchName string, exch exchange.Exchange, side string, qty float64) (*exchange.Order, error) {
+	cfg := closeRetryConfig(exchName, 1)
+	if cfg.MaxRetries <= 1 {
+		return placeMarket(ctx, exch, symbol, side, qty, "", true)
+	}
+	return oe.retryOrder(ctx, symbol, exch, side, qty, "", closeClientID(), true, cfg)
+}
+
+// closeRetryConfig возвращает политику повторов закрытия позиции на бирже
+// attempts - попыток на здоровой бирже; деградировавшая получает не меньше попыток
+// retry.AggressiveConfig, отключённая - вдвое больше: закрытия не ждут восстановления биржи
+func closeRetryConfig(exchName string, attempts int) retry.Config {
+	cfg := retry.AggressiveConfig()
+	cfg.RetryIf = retry.RetryIfNotContext
+
+	switch exchange.HealthOf(exchName).State() {
+	case exchange.HealthDegraded:
+		attempts = max(attempts, cfg.MaxRetries)
+	case exchange.HealthTripped:
+		attempts = 2 * max(attempts, cfg.MaxRetries)
+	}
+	cfg.MaxRetries = attempts
+	return cfg
+}
+
 // closeSingleLeg закрывает одну ногу (для rollback)
 func (oe *OrderExecutor) closeSingleLeg(ctx context.Context, symbol string, leg models.Leg) *ExecuteResult {
 	oe.mu.RLock()
@@ -459,7 +597,11 @@ func (oe *OrderExecutor) closeSingleLeg(ctx context.Context, symbol string, leg
 		side = exchange.SideBuy // закрываем шорт покупкой
 	}
 
-	order, err := exch.PlaceMarketOrder(ctx, symbol, side, leg.Quantity)
+	order, err := oe.placeClose(ctx, symbol, leg.Exchange, exch, side, leg.Quantity)
+	if err == nil && order != nil {
+		res := oe.confirmLeg(ctx, leg.Exchange, exch, LegResult{Order: order})
+		order, err = res.Order, res.Error
+	}
 	if err != nil {
 		return &ExecuteResult{
 			Success: false,
@@ -519,7 +661,7 @@ func (oe *OrderExecutor) closeTwoLegs(ctx context.Context, symbol string, legs [
 	// Параллельное закрытие
 	go func() {
 		defer wg.Done()
-		order, err := exch1.PlaceMarketOrder(ctx, symbol, side1, legs[0].Quantity)
+		order, err := oe.placeClose(ctx, symbol, legs[0].Exchange, exch1, side1, legs[0].Quantity)
 		select {
 		case ch1 <- LegResult{Order: order, Error: err}:
 		default:
@@ -528,7 +670,7 @@ func (oe *OrderExecutor) closeTwoLegs(ctx context.Context, symbol string, legs [
 
 	go func() {
 		defer wg.Done()
-		order, err := exch2.PlaceMarketOrder(ctx, symbol, side2, legs[1].Quantity)
+		order, err := oe.placeClose(ctx, symbol, legs[1].Exchange, exch2, side2, legs[1].Quantity)
 		select {
 		case ch2 <- LegResult{Order: order, Error: err}:
 		default:
@@ -560,6 +702,9 @@ func (oe *OrderExecutor) closeTwoLegs(ctx context.Context, symbol string, legs [
 	releaseLegResultChan(ch1)
 	releaseLegResultChan(ch2)
 
+	// Цена закрытия для PNL - из подтверждённого исполнения
+	res1, res2 = oe.confirmLegs(ctx, legs[0].Exchange, exch1, res1, legs[1].Exchange, exch2, res2)
+
 	// Проверяем результаты
 	if res1.Error != nil || res2.Error != nil {
 		return &ExecuteResult{
@@ -595,13 +740,37 @@ func (oe *OrderExecutor) closeTwoLegs(ctx context.Context, symbol string, legs [
 	}
 }
 
-// UpdateExchanges обновляет карту бирж (потокобезопасно)
+// UpdateExchanges заменяет карту бирж копией exchanges (потокобезопасно)
 func (oe *OrderExecutor) UpdateExchanges(exchanges map[string]exchange.Exchange) {
+	exchanges = copyExchanges(exchanges)
 	oe.mu.Lock()
 	oe.exchanges = exchanges
 	oe.mu.Unlock()
 }
 
+// AddExchange добавляет биржу (потокобезопасно)
+func (oe *OrderExecutor) AddExchange(name string, exch exchange.Exchange) {
+	oe.mu.Lock()
+	oe.exchanges[name] = exch
+	oe.mu.Unlock()
+}
+
+// RemoveExchange удаляет биржу (потокобезопасно)
+func (oe *OrderExecutor) RemoveExchange(name string) {
+	oe.mu.Lock()
+	delete(oe.exchanges, name)
+	oe.mu.Unlock()
+}
+
+// copyExchanges возвращает копию карты бирж
+func copyExchanges(exchanges map[string]exchange.Exchange) map[string]exchange.Exchange {
+	result := make(map[string]exchange.Exchange, len(exchanges))
+	for name, exch := range exchanges {
+		result[name] = exch
+	}
+	return result
+}
+
 // ============================================================
 // OrderValidator - валидация ордеров согласно лимитам биржи
 // ============================================================
@@ -611,6 +780,10 @@ func (oe *OrderExecutor) UpdateExchanges(exchanges map[string]exchange.Exchange)
 // - Округление объёмов до lot size биржи
 // - Проверка min notional (минимальная сумма сделки в USDT)
 // - Предотвращение отклонения ордеров биржей
+//
+// Объёмы всегда в монетах. Для бирж с размером в контрактах (OKX, Gate, HTX)
+// округление идёт через exchange.Instrument - тот же путь, что и в адаптере,
+// поэтому обе ноги открываются на одинаковое количество монет.
 
 // OrderValidator проверяет и корректирует параметры ордеров
 type OrderValidator struct {
@@ -638,11 +811,14 @@ type LimitsKey struct {
 type CachedLimits struct {
 	MinOrderQty float64
 	MaxOrderQty float64
-	QtyStep     float64 // lot size
+	QtyStep     float64 // lot size в монетах
 	MinNotional float64
 	PriceStep   float64 // tick size
 	MaxLeverage int
 	UpdatedAt   time.Time
+
+	// Спецификация контракта, если лимиты загружены через UpdateInstrument
+	Instrument *exchange.Instrument
 }
 
 // ValidationResult - результат валидации
@@ -684,6 +860,27 @@ func (ov *OrderValidator) UpdateLimits(exchangeName, symbol string, limits *exch
 	ov.limits.Store(key, cached)
 }
 
+// UpdateInstrument обновляет лимиты по спецификации контракта
+// Лимиты переводятся в монеты, округление объёма - через Instrument.RoundQty
+func (ov *OrderValidator) UpdateInstrument(exchangeName string, inst *exchange.Instrument) {
+	if inst == nil {
+		return
+	}
+
+	limits := inst.Limits()
+	key := LimitsKey{Exchange: exchangeName, Symbol: inst.Symbol}
+	ov.limits.Store(key, &CachedLimits{
+		MinOrderQty: limits.MinOrderQty,
+		MaxOrderQty: limits.MaxOrderQty,
+		QtyStep:     limits.QtyStep,
+		MinNotional: limits.MinNotional,
+		PriceStep:   limits.PriceStep,
+		MaxLeverage: limits.MaxLeverage,
+		UpdatedAt:   time.Now(),
+		Instrument:  inst,
+	})
+}
+
 // GetLimits возвращает кэшированные лимиты
 func (ov *OrderValidator) GetLimits(exchangeName, symbol string) *CachedLimits {
 	key := LimitsKey{Exchange: exchangeName, Symbol: symbol}
@@ -723,6 +920,14 @@ func (ov *OrderValidator) ValidateOrderQty(
 		maxQty = limits.MaxOrderQty
 		qtyStep = limits.QtyStep
 		minNotional = limits.MinNotional
+
+		// Делистинг или приостановка торгов - ордер будет отклонён биржей
+		if limits.Instrument != nil && !limits.Instrument.Trading() {
+			result.Valid = false
+			result.Error = fmt.Sprintf("%s is %s on %s",
+				symbol, limits.Instrument.Status, exchangeName)
+			return result
+		}
 	} else {
 		// Используем дефолты если лимиты не загружены
 		minQty = ov.defaultMinQty
@@ -735,7 +940,7 @@ func (ov *OrderValidator) ValidateOrderQty(
 
 	// 1. Округляем до lot size (qtyStep)
 	if qtyStep > 0 {
-		result.AdjustedQty = ov.roundToStep(qty, qtyStep)
+		result.AdjustedQty = ov.roundQty(limits, qty, qtyStep)
 		if result.AdjustedQty != qty {
 			result.Warnings = append(result.Warnings,
 				fmt.Sprintf("qty adjusted from %.8f to %.8f (lot size: %.8f)",
@@ -755,7 +960,7 @@ func (ov *OrderValidator) ValidateOrderQty(
 	if maxQty > 0 && result.AdjustedQty > maxQty {
 		// Ограничиваем до максимума
 		oldQty := result.AdjustedQty
-		result.AdjustedQty = ov.roundToStep(maxQty, qtyStep)
+		result.AdjustedQty = ov.roundQty(limits, maxQty, qtyStep)
 		result.Warnings = append(result.Warnings,
 			fmt.Sprintf("qty limited from %.8f to max %.8f on %s",
 				oldQty, result.AdjustedQty, exchangeName))
@@ -767,7 +972,7 @@ func (ov *OrderValidator) ValidateOrderQty(
 		if notional < minNotional {
 			// Рассчитываем минимальное количество для достижения min notional
 			requiredQty := minNotional / currentPrice
-			requiredQty = ov.roundToStep(requiredQty, qtyStep)
+			requiredQty = ov.roundQty(limits, requiredQty, qtyStep)
 
 			// Проверяем что требуемое qty не меньше minQty
 			if requiredQty < minQty {
@@ -814,6 +1019,19 @@ func (ov *OrderValidator) ValidateBothLegs(
 		adjustedQty = shortResult.AdjustedQty
 	}
 
+	// Лоты бирж могут не совпадать (0.001 BTC и контракт 0.01 BTC):
+	// объём должен быть кратен обоим, иначе ноги разойдутся по экспозиции
+	adjustedQty = ov.commonLotQty(longExchange, shortExchange, symbol, adjustedQty)
+	for _, leg := range []string{longExchange, shortExchange} {
+		if limits := ov.GetLimits(leg, symbol); limits != nil && adjustedQty < limits.MinOrderQty {
+			return &ValidationResult{
+				Valid: false,
+				Error: fmt.Sprintf("qty %.8f below minimum %.8f on %s after lot alignment",
+					adjustedQty, limits.MinOrderQty, leg),
+			}
+		}
+	}
+
 	// Собираем предупреждения
 	warnings := make([]string, 0)
 	warnings = append(warnings, longResult.Warnings...)
@@ -831,6 +1049,39 @@ func (ov *OrderValidator) ValidateBothLegs(
 	}
 }
 
+// roundQty округляет объём вниз до лота биржи через Instrument.RoundQty -
+// тот же путь, что и при отправке ордера адаптером
+// Без загруженной спецификации лот step задан в монетах
+func (ov *OrderValidator) roundQty(limits *CachedLimits, qty, step float64) float64 {
+	inst := &exchange.Instrument{LotSize: step}
+	if limits != nil && limits.Instrument != nil {
+		inst = limits.Instrument
+	}
+	return inst.RoundQty(qty)
+}
+
+// commonLotQty округляет объём вниз до значения, кратного лотам обеих бирж
+// Каждое округление только уменьшает объём, поэтому цикл сходится за пару итераций
+func (ov *OrderValidator) commonLotQty(longExchange, shortExchange, symbol string, qty float64) float64 {
+	legs := []*CachedLimits{ov.GetLimits(longExchange, symbol), ov.GetLimits(shortExchange, symbol)}
+	for i := 0; i < 10; i++ {
+		prev := qty
+		for _, limits := range legs {
+			step := ov.defaultQtyStep
+			if limits != nil {
+				step = limits.QtyStep
+			}
+			if step > 0 {
+				qty = ov.roundQty(limits, qty, step)
+			}
+		}
+		if qty == prev {
+			break
+		}
+	}
+	return qty
+}
+
 // roundToStep округляет значение до ближайшего кратного step (в меньшую сторону)
 // Делегирует в utils.RoundToLotSize для единообразия
 func (ov *OrderValidator) roundToStep(value, step float64) float64 {
@@ -883,18 +1134,20 @@ func (oe *OrderExecutor) ExecuteWithValidation(
 // Утилиты для работы с лимитами
 // ============================================================
 
-// LoadLimitsFromExchange загружает лимиты для символа с биржи
+// LoadLimitsFromExchange загружает спецификацию контракта символа с биржи
+// Лимиты сохраняются по идентификатору аккаунта exchName (ключу движка)
 func (ov *OrderValidator) LoadLimitsFromExchange(
 	ctx context.Context,
+	exchName string,
 	exch exchange.Exchange,
 	symbol string,
 ) error {
-	limits, err := exch.GetLimits(ctx, symbol)
+	inst, err := exch.GetInstrument(ctx, symbol)
 	if err != nil {
-		return fmt.Errorf("failed to get limits from %s: %w", exch.GetName(), err)
+		return fmt.Errorf("failed to get instrument from %s: %w", exchName, err)
 	}
 
-	ov.UpdateLimits(exch.GetName(), symbol, limits)
+	ov.UpdateInstrument(exchName, inst)
 	return nil
 }
 
@@ -909,7 +1162,7 @@ func (ov *OrderValidator) PreloadLimits(
 	for exchName, exch := range exchanges {
 		for _, symbol := range symbols {
 			key := exchName + ":" + symbol
-			if err := ov.LoadLimitsFromExchange(ctx, exch, symbol); err != nil {
+			if err := ov.LoadLimitsFromExchange(ctx, exchName, exch, symbol); err != nil {
 				errors[key] = err
 			}
 		}
@@ -937,6 +1190,10 @@ func (ov *OrderValidator) LimitsInfo() map[string]*CachedLimits {
 // ============================================================
 
 // ExecuteWithRetry выполняет ордер с retry при сетевых ошибках
+//
+// Попытки делят начало входа и различаются номером попытки в клиентских ID:
+// ордер ноги, принятый биржей в прошлой попытке, не отправляется повторно (см. placeLeg).
+// После отката ноги (ShouldPause) вход не повторяется.
 func (oe *OrderExecutor) ExecuteWithRetry(
 	ctx context.Context,
 	params ExecuteParams,
@@ -944,11 +1201,22 @@ func (oe *OrderExecutor) ExecuteWithRetry(
 ) *ExecuteResult {
 	var result *ExecuteResult
 
+	if params.EntryAt.IsZero() {
+		params.EntryAt = time.Now()
+	}
+	attempt := params.Attempt
+
 	operation := func() error {
+		params.Attempt = attempt
+		attempt++
+
 		result = oe.ExecuteParallel(ctx, params)
 		if result.Success {
 			return nil
 		}
+		if result.ShouldPause {
+			return retry.Permanent(result.Error)
+		}
 		// Проверяем, является ли ошибка retriable
 		if isRetriableError(result.Error) {
 			return result.Error
@@ -156,11 +156,11 @@ func (pm *PositionManager) CheckPosition(ps *PairState) *PositionStatus {
 
 // checkExitConditions проверяет условия для закрытия позиции
 func (pm *PositionManager) checkExitConditions(ps *PairState, status *PositionStatus) (bool, string) {
-	// 1. Проверка exit spread
+	// 1. Проверка exit spread (стратегия funding выходит по ставкам, см. CheckExitConditions)
 	// ОПТИМИЗАЦИЯ: используем atomic read для lock-free доступа
 	// Выходим когда спред схлопнулся до exit_spread или ниже
 	exitSpread := ps.GetExitSpread()
-	if status.CurrentSpread <= exitSpread {
+	if !ps.IsFundingStrategy() && status.CurrentSpread <= exitSpread {
 		return true, "exit_spread_reached"
 	}
 
@@ -0,0 +1,284 @@
+package bot
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"arbitrage/internal/exchange"
+	"arbitrage/internal/marketdata"
+	"arbitrage/pkg/utils"
+)
+
+// RecorderConfig - параметры записи рыночных данных
+type RecorderConfig struct {
+	Dir              string        // каталог для файлов записи
+	BufferSize       int           // размер очереди записей (при переполнении записи отбрасываются)
+	SnapshotInterval time.Duration // период снимков стаканов (0 = без снимков)
+	SnapshotDepth    int           // глубина снимка стакана
+	SnapshotTimeout  time.Duration // таймаут одного GetOrderBook
+	MaxFileSize      int64         // ротация по размеру (несжатые байты)
+	RotateInterval   time.Duration // ротация по времени
+	FlushInterval    time.Duration // период сброса буферов на диск
+}
+
+// DefaultRecorderConfig возвращает конфигурацию по умолчанию
+func DefaultRecorderConfig(dir string) RecorderConfig {
+	return RecorderConfig{
+		Dir:              dir,
+		BufferSize:       65536,
+		SnapshotInterval: time.Second,
+		SnapshotDepth:    20,
+		SnapshotTimeout:  5 * time.Second,
+		MaxFileSize:      256 << 20,
+		RotateInterval:   time.Hour,
+		FlushInterval:    time.Second,
+	}
+}
+
+// recordStream - поток exchange:symbol, для которого снимаются стаканы
+type recordStream struct {
+	exchange string
+	symbol   string
+}
+
+// Recorder записывает тикеры и снимки стаканов со всех подключенных бирж
+//
+// Горячий путь:
+// RecordTicker вызывается из WS callback рядом с routePriceUpdate и только
+// кладёт запись в буферизованный канал (select/default). Если писатель
+// не успевает, запись отбрасывается и увеличивается счётчик dropped -
+// торговля никогда не ждёт диск.
+//
+// Запись на диск, сжатие и ротация выполняются в отдельной горутине (Run).
+type Recorder struct {
+	cfg    RecorderConfig
+	writer *marketdata.Writer
+
+	records chan *marketdata.Record
+
+	// Потоки для снимков: map[string]recordStream
+	streams sync.Map
+
+	// Источник подключенных бирж (Engine.GetExchanges)
+	getExchanges func() map[string]exchange.Exchange
+
+	written int64
+	dropped int64
+
+	done chan struct{}
+}
+
+// NewRecorder создает Recorder; файлы создаются в cfg.Dir
+func NewRecorder(cfg RecorderConfig, getExchanges func() map[string]exchange.Exchange) (*Recorder, error) {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 65536
+	}
+	if cfg.SnapshotTimeout <= 0 {
+		cfg.SnapshotTimeout = 5 * time.Second
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+
+	writer, err := marketdata.NewWriter(marketdata.WriterConfig{
+		Dir:            cfg.Dir,
+		MaxFileSize:    cfg.MaxFileSize,
+		RotateInterval: cfg.RotateInterval,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &Recorder{
+		cfg:          cfg,
+		writer:       writer,
+		records:      make(chan *marketdata.Record, cfg.BufferSize),
+		getExchanges: getExchanges,
+		done:         make(chan struct{}),
+	}, nil
+}
+
+// Track добавляет поток exchange:symbol для периодических снимков стакана
+func (r *Recorder) Track(exchName, symbol string) {
+	r.streams.Store(exchName+":"+symbol, recordStream{exchange: exchName, symbol: symbol})
+}
+
+// RecordTicker ставит тикер в очередь записи (неблокирующий)
+func (r *Recorder) RecordTicker(exchName string, ticker *exchange.Ticker) {
+	now := time.Now().UnixNano()
+	ts := now
+	if !ticker.Timestamp.IsZero() {
+		ts = ticker.Timestamp.UnixNano()
+	}
+
+	r.enqueue(&marketdata.Record{
+		Type:     marketdata.TypeTicker,
+		Ts:       ts,
+		RecvTs:   now,
+		Exchange: exchName,
+		Symbol:   ticker.Symbol,
+		Bid:      ticker.BidPrice,
+		Ask:      ticker.AskPrice,
+		Last:     ticker.LastPrice,
+	})
+}
+
+// RecordOrderBook ставит снимок стакана в очередь записи (неблокирующий)
+func (r *Recorder) RecordOrderBook(exchName string, book *exchange.OrderBook) {
+	now := time.Now().UnixNano()
+	ts := now
+	if !book.Timestamp.IsZero() {
+		ts = book.Timestamp.UnixNano()
+	}
+
+	r.enqueue(&marketdata.Record{
+		Type:     marketdata.TypeOrderBook,
+		Ts:       ts,
+		RecvTs:   now,
+		Exchange: exchName,
+		Symbol:   book.Symbol,
+		Bids:     toLevels(book.Bids),
+		Asks:     toLevels(book.Asks),
+	})
+}
+
+// Stats возвращает количество записанных и отброшенных записей
+func (r *Recorder) Stats() (written, dropped int64) {
+	return atomic.LoadInt64(&r.written), atomic.LoadInt64(&r.dropped)
+}
+
+// Done закрывается после того, как Run сбросил и закрыл файлы
+func (r *Recorder) Done() <-chan struct{} {
+	return r.done
+}
+
+// Run пишет очередь на диск и снимает стаканы до отмены контекста
+// При остановке дописывает оставшиеся в очереди записи и закрывает файл
+func (r *Recorder) Run(ctx context.Context) {
+	defer close(r.done)
+
+	if r.cfg.SnapshotInterval > 0 {
+		go r.snapshotLoop(ctx)
+	}
+
+	flushTicker := time.NewTicker(r.cfg.FlushInterval)
+	defer flushTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			r.drain()
+			if err := r.writer.Close(); err != nil {
+				utils.Warn("market data recorder close failed", utils.Err(err))
+			}
+			return
+
+		case rec := <-r.records:
+			r.write(rec)
+
+		case <-flushTicker.C:
+			if err := r.writer.Flush(); err != nil {
+				utils.Warn("market data recorder flush failed", utils.Err(err))
+			}
+		}
+	}
+}
+
+func (r *Recorder) enqueue(rec *marketdata.Record) {
+	select {
+	case r.records <- rec:
+	default:
+		atomic.AddInt64(&r.dropped, 1)
+	}
+}
+
+func (r *Recorder) write(rec *marketdata.Record) {
+	if err := r.writer.Write(rec); err != nil {
+		atomic.AddInt64(&r.dropped, 1)
+		utils.Warn("market data record write failed", utils.Err(err))
+		return
+	}
+	atomic.AddInt64(&r.written, 1)
+}
+
+// drain дописывает записи, оставшиеся в очереди при остановке
+func (r *Recorder) drain() {
+	for {
+		select {
+		case rec := <-r.records:
+			r.write(rec)
+		default:
+			return
+		}
+	}
+}
+
+// snapshotLoop периодически запрашивает стаканы по всем потокам
+func (r *Recorder) snapshotLoop(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.SnapshotInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			r.snapshotAll(ctx)
+		}
+	}
+}
+
+// snapshotAll снимает стаканы параллельно по биржам (последовательно внутри биржи,
+// чтобы не упираться в rate limit)
+func (r *Recorder) snapshotAll(ctx context.Context) {
+	exchanges := r.getExchanges()
+
+	byExchange := make(map[string][]string)
+	r.streams.Range(func(_, value interface{}) bool {
+		stream := value.(recordStream)
+		byExchange[stream.exchange] = append(byExchange[stream.exchange], stream.symbol)
+		return true
+	})
+
+	var wg sync.WaitGroup
+	for exchName, symbols := range byExchange {
+		exch, ok := exchanges[exchName]
+		if !ok {
+			continue
+		}
+
+		wg.Add(1)
+		go func(exchName string, exch exchange.Exchange, symbols []string) {
+			defer wg.Done()
+			for _, symbol := range symbols {
+				reqCtx, cancel := context.WithTimeout(ctx, r.cfg.SnapshotTimeout)
+				book, err := exch.GetOrderBook(reqCtx, symbol, r.cfg.SnapshotDepth)
+				cancel()
+
+				if err != nil {
+					if ctx.Err() != nil {
+						return
+					}
+					utils.Debug("market data snapshot failed",
+						utils.Exchange(exchName), utils.Symbol(symbol), utils.Err(err))
+					continue
+				}
+				if book.Symbol == "" {
+					book.Symbol = symbol
+				}
+				r.RecordOrderBook(exchName, book)
+			}
+		}(exchName, exch, symbols)
+	}
+	wg.Wait()
+}
+
+func toLevels(levels []exchange.PriceLevel) []marketdata.Level {
+	result := make([]marketdata.Level, len(levels))
+	for i, level := range levels {
+		result[i] = marketdata.Level{level.Price, level.Volume}
+	}
+	return result
+}
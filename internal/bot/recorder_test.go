@@ -0,0 +1,100 @@
+package bot
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"arbitrage/internal/exchange"
+	"arbitrage/internal/marketdata"
+)
+
+// TestRecorder_WritesTickersAndSnapshots проверяет запись тикеров и снимков стакана
+func TestRecorder_WritesTickersAndSnapshots(t *testing.T) {
+	dir := t.TempDir()
+
+	sim := exchange.NewSim(exchange.DefaultSimConfig("bybit"))
+	sim.SetOrderBook(&exchange.OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   []exchange.PriceLevel{{Price: 100, Volume: 1}},
+		Asks:   []exchange.PriceLevel{{Price: 101, Volume: 1}},
+	})
+
+	cfg := DefaultRecorderConfig(dir)
+	cfg.SnapshotInterval = 20 * time.Millisecond
+	recorder, err := NewRecorder(cfg, func() map[string]exchange.Exchange {
+		return map[string]exchange.Exchange{"bybit": sim}
+	})
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+	recorder.Track("bybit", "BTCUSDT")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go recorder.Run(ctx)
+
+	recorder.RecordTicker("bybit", &exchange.Ticker{Symbol: "BTCUSDT", BidPrice: 100, AskPrice: 101})
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+	<-recorder.Done()
+
+	reader, err := marketdata.NewReader(dir)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	defer reader.Close()
+
+	var tickers, books int
+	for {
+		rec, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		switch rec.Type {
+		case marketdata.TypeTicker:
+			tickers++
+		case marketdata.TypeOrderBook:
+			books++
+			if len(rec.Bids) != 1 || rec.Bids[0][0] != 100 {
+				t.Fatalf("unexpected snapshot: %+v", rec)
+			}
+		}
+	}
+
+	if tickers != 1 || books == 0 {
+		t.Fatalf("expected 1 ticker and snapshots, got %d tickers, %d books", tickers, books)
+	}
+}
+
+// TestRecorder_NeverBlocks проверяет, что переполненная очередь не блокирует вызывающего
+func TestRecorder_NeverBlocks(t *testing.T) {
+	cfg := DefaultRecorderConfig(t.TempDir())
+	cfg.BufferSize = 2
+	recorder, err := NewRecorder(cfg, func() map[string]exchange.Exchange { return nil })
+	if err != nil {
+		t.Fatalf("NewRecorder: %v", err)
+	}
+
+	// Run не запущен: очередь никто не читает
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 10; i++ {
+			recorder.RecordTicker("okx", &exchange.Ticker{Symbol: "ETHUSDT"})
+		}
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("RecordTicker blocked on full queue")
+	}
+
+	if _, dropped := recorder.Stats(); dropped != 8 {
+		t.Fatalf("expected 8 dropped records, got %d", dropped)
+	}
+}
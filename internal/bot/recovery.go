@@ -119,7 +119,7 @@ type RecoveryResult struct {
 
 // DiscoveredPosition представляет найденную позицию на бирже
 type DiscoveredPosition struct {
-	Exchange      string
+	Exchange      string // идентификатор аккаунта (exchange.AccountKey)
 	Symbol        string
 	Side          string // "long" или "short"
 	Size          float64
@@ -227,6 +227,7 @@ func (rm *RecoveryManager) Recover(ctx context.Context) (*RecoveryResult, error)
 }
 
 // restoreExchanges загружает и подключает все биржи из БД
+// Возвращает подключения по идентификатору аккаунта (exchange.AccountKey)
 func (rm *RecoveryManager) restoreExchanges(ctx context.Context) (map[string]exchange.Exchange, error) {
 	// Получаем все подключенные биржи из БД
 	accounts, err := rm.exchangeRepo.GetConnected()
@@ -244,16 +245,17 @@ func (rm *RecoveryManager) restoreExchanges(ctx context.Context) (map[string]exc
 		go func(acc *models.ExchangeAccount) {
 			defer wg.Done()
 
+			key := exchange.AccountKey(acc.Name, acc.Label)
 			exch, err := rm.connectExchange(ctx, acc)
 			if err != nil {
-				errChan <- fmt.Errorf("exchange %s: %w", acc.Name, err)
+				errChan <- fmt.Errorf("exchange %s: %w", key, err)
 				return
 			}
 
 			mu.Lock()
-			exchanges[acc.Name] = exch
-			// Добавляем биржу в engine
-			rm.engine.AddExchange(acc.Name, exch)
+			exchanges[key] = exch
+			// Добавляем аккаунт в engine
+			rm.engine.AddExchange(key, exch)
 			mu.Unlock()
 		}(account)
 	}
@@ -384,18 +386,8 @@ func (rm *RecoveryManager) matchPositionsToPairs(
 			continue
 		}
 
-		// Ищем long и short ноги
-		var longLeg, shortLeg *DiscoveredPosition
-		for _, pos := range symbolPositions {
-			if usedPositions[pos] {
-				continue
-			}
-			if pos.Side == exchange.SideLong && longLeg == nil {
-				longLeg = pos
-			} else if pos.Side == exchange.SideShort && shortLeg == nil {
-				shortLeg = pos
-			}
-		}
+		// Ищем long и short ноги на аккаунтах пары
+		longLeg, shortLeg := pickLegs(symbolPositions, pair, usedPositions)
 
 		// Если найдена хотя бы одна нога
 		if longLeg != nil || shortLeg != nil {
@@ -431,6 +423,41 @@ func (rm *RecoveryManager) matchPositionsToPairs(
 	return matched, orphaned
 }
 
+// pickLegs выбирает ноги пары среди позиций символа на её аккаунтах
+// Ноги связки - лонг и шорт на разных аккаунтах: в режиме hedge лонг и шорт одного
+// аккаунта могут оказаться позициями ручной торговли. Без полной связки
+// возвращается первая найденная нога
+func pickLegs(positions []*DiscoveredPosition, pair *models.PairConfig, used map[*DiscoveredPosition]bool) (longLeg, shortLeg *DiscoveredPosition) {
+	var longs, shorts []*DiscoveredPosition
+	for _, pos := range positions {
+		if used[pos] || !pair.UsesAccount(pos.Exchange) {
+			continue
+		}
+		switch pos.Side {
+		case exchange.SideLong:
+			longs = append(longs, pos)
+		case exchange.SideShort:
+			shorts = append(shorts, pos)
+		}
+	}
+
+	for _, long := range longs {
+		for _, short := range shorts {
+			if long.Exchange != short.Exchange {
+				return long, short
+			}
+		}
+	}
+
+	if len(longs) > 0 {
+		return longs[0], nil
+	}
+	if len(shorts) > 0 {
+		return nil, shorts[0]
+	}
+	return nil, nil
+}
+
 // restoreRuntimeState восстанавливает runtime состояние для найденных пар
 func (rm *RecoveryManager) restoreRuntimeState(matched []*MatchedPosition) {
 	for _, mp := range matched {
@@ -485,7 +512,6 @@ func (rm *RecoveryManager) restoreRuntimeState(matched []*MatchedPosition) {
 			})
 		}
 
-		oldState := ps.Runtime.State
 		ForceTransitionWithLog(ps.Runtime, ps.Config.ID, models.StateHolding)
 		ps.Runtime.Legs = legs
 		ps.Runtime.UnrealizedPnl = mp.TotalPnl
@@ -620,6 +646,10 @@ func (rm *RecoveryManager) activateMonitoring(pairs []*models.PairConfig) int {
 			err := rm.engine.StartPair(pair.ID)
 			if err == nil {
 				activated++
+			} else {
+				rm.notify("RECOVERY", "error", fmt.Sprintf(
+					"Pair %s not started: %v", pair.Symbol, err,
+				), nil)
 			}
 		}
 	}
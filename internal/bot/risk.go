@@ -118,6 +118,13 @@ func (rm *RiskManager) AddExchange(name string, exch exchange.Exchange) {
 	rm.exchMu.Unlock()
 }
 
+// RemoveExchange удаляет биржу
+func (rm *RiskManager) RemoveExchange(name string) {
+	rm.exchMu.Lock()
+	delete(rm.exchanges, name)
+	rm.exchMu.Unlock()
+}
+
 // ============================================================
 // Stop Loss мониторинг
 // ============================================================
@@ -268,9 +275,8 @@ func (rm *RiskManager) emergencyCloseLeg(ctx context.Context, symbol string, leg
 		closeSide = exchange.SideBuy
 	}
 
-	// Используем aggressive retry для критической операции
-	cfg := retry.AggressiveConfig()
-	cfg.MaxRetries = rm.config.MaxCloseRetries
+	// Используем aggressive retry для критической операции, на деградировавшей бирже - больше попыток
+	cfg := closeRetryConfig(leg.Exchange, rm.config.MaxCloseRetries)
 
 	return retry.Do(ctx, func() error {
 		return exch.ClosePosition(ctx, symbol, closeSide, leg.Quantity)
@@ -5,6 +5,8 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"arbitrage/internal/exchange"
 )
 
 // ============ ОПТИМИЗАЦИЯ: Inline FNV-1a hash без аллокаций ============
@@ -55,6 +57,10 @@ func ReleaseArbitrageOpportunity(opp *ArbitrageOpportunity) {
 	opp.ShortPrice = 0
 	opp.RawSpread = 0
 	opp.NetSpread = 0
+	opp.FundingPct = 0
+	opp.FundingDiff = 0
+	opp.RoundTripCost = 0
+	opp.MakerExchange = ""
 	opp.Timestamp = time.Time{}
 	arbitrageOpportunityPool.Put(opp)
 }
@@ -324,7 +330,7 @@ func (shard *PriceShard) recalculateBest(symbol string) *BestPrices {
 			existing.BestBidExch = bestBidExch
 			existing.BestBidTime = bestBidTime
 			existing.RawSpread = rawSpread
-			bestCopy = &BestPrices{*existing}
+			bestCopy = existing
 		} else {
 			// Первый раз - создаём новый объект
 			shard.bestPrices[symbol] = &BestPrices{
@@ -407,6 +413,56 @@ func (pt *PriceTracker) GetBestPrices(symbol string) *BestPrices {
 	return &bpCopy
 }
 
+// GetEntryPrices возвращает лучшие цены для открытия позиции
+// Отключённая биржа (exchange.HealthTripped) и аккаунты вне accounts (nil - все аккаунты)
+// не выбираются ногой входа: если лучшая цена на них, лучшие цены пересчитываются
+// по остальным аккаунтам символа
+func (pt *PriceTracker) GetEntryPrices(symbol string, accounts []string) *BestPrices {
+	best := pt.GetBestPrices(symbol)
+	if best == nil || entryAllowed(best.BestAskExch, accounts) && entryAllowed(best.BestBidExch, accounts) {
+		return best
+	}
+
+	shard := pt.getShard(symbol)
+	shard.mu.RLock()
+	defer shard.mu.RUnlock()
+
+	entry := &BestPrices{Symbol: symbol}
+	for _, key := range shard.symbolIndex[symbol] {
+		price := shard.allPrices[key]
+		if price == nil || !entryAllowed(price.Exchange, accounts) {
+			continue
+		}
+		if price.AskPrice > 0 && (entry.BestAsk == 0 || price.AskPrice < entry.BestAsk) {
+			entry.BestAsk, entry.BestAskExch, entry.BestAskTime = price.AskPrice, price.Exchange, price.Timestamp
+		}
+		if price.BidPrice > entry.BestBid {
+			entry.BestBid, entry.BestBidExch, entry.BestBidTime = price.BidPrice, price.Exchange, price.Timestamp
+		}
+	}
+	if entry.BestAsk <= 0 || entry.BestBid <= 0 {
+		return nil
+	}
+	entry.RawSpread = (entry.BestBid - entry.BestAsk) / entry.BestAsk * 100
+	return entry
+}
+
+// entryAllowed проверяет, может ли аккаунт быть ногой входа
+func entryAllowed(account string, accounts []string) bool {
+	if exchange.HealthOf(account).Tripped() {
+		return false
+	}
+	if len(accounts) == 0 {
+		return true
+	}
+	for _, a := range accounts {
+		if a == account {
+			return true
+		}
+	}
+	return false
+}
+
 // GetExchangePrice возвращает КОПИЮ цены конкретной биржи
 // Возвращает копию для thread safety (аналогично GetBestPrices)
 func (pt *PriceTracker) GetExchangePrice(symbol, exchange string) *ExchangePrice {
@@ -434,8 +490,17 @@ type SpreadCalculator struct {
 	tracker *PriceTracker
 
 	// Кэш комиссий по биржам (загружается один раз)
-	fees   map[string]float64 // exchange -> taker fee (например 0.0005 = 0.05%)
-	feesMu sync.RWMutex
+	fees      map[string]float64 // exchange -> taker fee (например 0.0005 = 0.05%)
+	makerFees map[string]float64 // exchange -> maker fee (режим maker_taker)
+	feesMu    sync.RWMutex
+
+	// Ставки фандинга и учёт ожидаемого фандинга в NetSpread (см. funding.go)
+	fundingRates    map[PositionKey]*exchange.FundingRate
+	fundingEvents   map[PositionKey]FundingEvent // последнее прошедшее начисление (стратегия funding)
+	fundingMu       sync.RWMutex
+	considerFunding int32 // atomic: 1 = Settings.ConsiderFunding
+	fundingHold     int64 // atomic: ожидаемое время удержания, time.Duration
+	now             func() time.Time
 
 	// Анализатор стаканов для расчёта VWAP/ликвидности (опционально)
 	orderBookAnalyzer *OrderBookAnalyzer
@@ -462,7 +527,19 @@ type ArbitrageOpportunity struct {
 
 	// Спреды
 	RawSpread float64 // без комиссий
-	NetSpread float64 // после вычета комиссий (4 сделки)
+	NetSpread float64 // после вычета комиссий (4 сделки) и с ожидаемым фандингом
+
+	// Ожидаемый фандинг за время удержания, % (0 если учёт фандинга выключен)
+	FundingPct float64
+
+	// Разница ставок фандинга шорт − лонг за 8ч, % (только стратегия funding)
+	FundingDiff float64
+
+	// Стоимость входа и выхода по текущим ценам с комиссиями тейкера, % (только стратегия funding)
+	RoundTripCost float64
+
+	// Биржа пассивной (post-only) ноги; пусто для входа двумя тейкерами
+	MakerExchange string
 
 	// Используем timestamp из лучших цен (без syscall!)
 	Timestamp time.Time
@@ -473,10 +550,20 @@ func NewSpreadCalculator(tracker *PriceTracker) *SpreadCalculator {
 	return &SpreadCalculator{
 		tracker:         tracker,
 		fees:            make(map[string]float64),
+		makerFees:       make(map[string]float64),
+		fundingRates:    make(map[PositionKey]*exchange.FundingRate),
+		fundingEvents:   make(map[PositionKey]FundingEvent),
+		fundingHold:     int64(defaultFundingHoldPeriod),
+		now:             time.Now,
 		volumesBySymbol: make(map[string]float64),
 	}
 }
 
+// SetClock подменяет источник времени для расчёта фандинга (виртуальные часы replay)
+func (sc *SpreadCalculator) SetClock(now func() time.Time) {
+	sc.now = now
+}
+
 // AttachOrderBookAnalyzer подключает анализатор стаканов и задаёт базовый объём для VWAP
 func (sc *SpreadCalculator) AttachOrderBookAnalyzer(analyzer *OrderBookAnalyzer, defaultVolume float64) {
 	sc.orderBookAnalyzer = analyzer
@@ -516,13 +603,22 @@ func (sc *SpreadCalculator) SetFee(exchange string, fee float64) {
 	sc.feesMu.Unlock()
 }
 
+// SetMakerFee устанавливает комиссию мейкера биржи
+func (sc *SpreadCalculator) SetMakerFee(exchange string, fee float64) {
+	sc.feesMu.Lock()
+	sc.makerFees[exchange] = fee
+	sc.feesMu.Unlock()
+}
+
 // GetBestOpportunity возвращает лучшую арбитражную возможность
 // Сложность: O(1) - все данные уже предвычислены в PriceTracker
 //
 // ОПТИМИЗАЦИЯ: использует sync.Pool для объектов ArbitrageOpportunity
+// accounts - аккаунты пары для ног (nil - все аккаунты)
+//
 // ВАЖНО: вызывающий код ДОЛЖЕН вызвать ReleaseArbitrageOpportunity() после использования!
-func (sc *SpreadCalculator) GetBestOpportunity(symbol string) *ArbitrageOpportunity {
-	best := sc.tracker.GetBestPrices(symbol)
+func (sc *SpreadCalculator) GetBestOpportunity(symbol string, accounts []string) *ArbitrageOpportunity {
+	best := sc.tracker.GetEntryPrices(symbol, accounts)
 	if best == nil {
 		return nil
 	}
@@ -565,6 +661,7 @@ func (sc *SpreadCalculator) GetBestOpportunity(symbol string) *ArbitrageOpportun
 	opp.RawSpread = best.RawSpread
 	opp.NetSpread = netSpread
 	opp.Timestamp = best.BestAskTime
+	sc.applyFunding(opp)
 
 	return opp
 }
@@ -572,18 +669,8 @@ func (sc *SpreadCalculator) GetBestOpportunity(symbol string) *ArbitrageOpportun
 // calculateNetSpread вычисляет чистый спред после комиссий
 // 4 тейкер-сделки: открытие лонга, открытие шорта, закрытие лонга, закрытие шорта
 func (sc *SpreadCalculator) calculateNetSpread(best *BestPrices) float64 {
-	sc.feesMu.RLock()
-	feeLong := sc.fees[best.BestAskExch]
-	feeShort := sc.fees[best.BestBidExch]
-	sc.feesMu.RUnlock()
-
 	// Если комиссии не установлены, используем дефолт 0.05%
-	if feeLong == 0 {
-		feeLong = 0.0005
-	}
-	if feeShort == 0 {
-		feeShort = 0.0005
-	}
+	feeLong, feeShort := sc.takerFees(best.BestAskExch, best.BestBidExch)
 
 	// Суммарные комиссии: 2 сделки на каждой бирже (открытие + закрытие)
 	totalFees := 2 * (feeLong + feeShort) * 100 // в процентах
@@ -591,12 +678,132 @@ func (sc *SpreadCalculator) calculateNetSpread(best *BestPrices) float64 {
 	return best.RawSpread - totalFees
 }
 
+// MakerTakerFees возвращает суммарные комиссии входа maker_taker в процентах:
+// мейкер на открытии пассивной ноги, тейкер на её закрытии и две тейкер-сделки хеджа
+func (sc *SpreadCalculator) MakerTakerFees(makerExch, hedgeExch string) float64 {
+	// Дефолты как в calculateNetSpread: 0.05% тейкер, 0.02% мейкер
+	makerFee := sc.makerFee(makerExch)
+	takerMaker, takerHedge := sc.takerFees(makerExch, hedgeExch)
+
+	return (makerFee + takerMaker + 2*takerHedge) * 100
+}
+
+// GetMakerOpportunity возвращает возможность для входа maker_taker
+//
+// Пассивная нога выставляется на менее ликвидной из двух бирж по своей лучшей
+// цене (лонг - по Bid, шорт - по Ask), хедж - рыночным ордером на другой бирже.
+// Поэтому спред считается от пассивной цены, а не от пересечения Ask/Bid,
+// и возможность бывает даже когда тейкерский спред отрицательный.
+//
+// ВАЖНО: вызывающий код ДОЛЖЕН вызвать ReleaseArbitrageOpportunity() после использования!
+func (sc *SpreadCalculator) GetMakerOpportunity(symbol string, accounts []string) *ArbitrageOpportunity {
+	best := sc.tracker.GetEntryPrices(symbol, accounts)
+	if best == nil || best.BestAskExch == best.BestBidExch {
+		return nil
+	}
+
+	longPrice := sc.tracker.GetExchangePrice(symbol, best.BestAskExch)
+	shortPrice := sc.tracker.GetExchangePrice(symbol, best.BestBidExch)
+	if longPrice == nil || shortPrice == nil ||
+		longPrice.BidPrice <= 0 || longPrice.AskPrice <= 0 ||
+		shortPrice.BidPrice <= 0 || shortPrice.AskPrice <= 0 {
+		return nil
+	}
+
+	makerExch := sc.selectMakerExchange(symbol, longPrice, shortPrice)
+	if makerExch == "" {
+		return nil
+	}
+
+	var rawSpread, longPx, shortPx float64
+	var hedgeExch string
+	if makerExch == longPrice.Exchange {
+		// Покупаем пассивно по Bid лонга, хеджируем продажей по Bid шорта
+		longPx, shortPx = longPrice.BidPrice, shortPrice.BidPrice
+		rawSpread = (shortPx - longPx) / longPx * 100
+		hedgeExch = shortPrice.Exchange
+	} else {
+		// Продаём пассивно по Ask шорта, хеджируем покупкой по Ask лонга
+		longPx, shortPx = longPrice.AskPrice, shortPrice.AskPrice
+		rawSpread = (shortPx - longPx) / longPx * 100
+		hedgeExch = longPrice.Exchange
+	}
+
+	if rawSpread <= 0 {
+		return nil
+	}
+
+	opp := acquireArbitrageOpportunity()
+	opp.Symbol = symbol
+	opp.LongExchange = longPrice.Exchange
+	opp.LongPrice = longPx
+	opp.ShortExchange = shortPrice.Exchange
+	opp.ShortPrice = shortPx
+	opp.RawSpread = rawSpread
+	opp.NetSpread = rawSpread - sc.MakerTakerFees(makerExch, hedgeExch)
+	opp.MakerExchange = makerExch
+	opp.Timestamp = best.BestAskTime
+	sc.applyFunding(opp)
+
+	return opp
+}
+
+// selectMakerExchange выбирает менее ликвидную биржу для пассивной ноги
+// По объёму кэшированных стаканов, а без них - по более широкому Bid/Ask.
+// Пассивной может быть только биржа с лимитными ордерами; "" - ни одна не подходит
+func (sc *SpreadCalculator) selectMakerExchange(symbol string, longPrice, shortPrice *ExchangePrice) string {
+	longLimit := exchange.CapabilitiesOf(longPrice.Exchange).LimitOrders
+	shortLimit := exchange.CapabilitiesOf(shortPrice.Exchange).LimitOrders
+	switch {
+	case !longLimit && !shortLimit:
+		return ""
+	case !longLimit:
+		return shortPrice.Exchange
+	case !shortLimit:
+		return longPrice.Exchange
+	}
+
+	if sc.orderBookAnalyzer != nil {
+		longBook := sc.orderBookAnalyzer.GetOrderBook(symbol, longPrice.Exchange)
+		shortBook := sc.orderBookAnalyzer.GetOrderBook(symbol, shortPrice.Exchange)
+		if longBook != nil && shortBook != nil {
+			if bookDepth(shortBook) < bookDepth(longBook) {
+				return shortPrice.Exchange
+			}
+			return longPrice.Exchange
+		}
+	}
+
+	longWidth := (longPrice.AskPrice - longPrice.BidPrice) / longPrice.AskPrice
+	shortWidth := (shortPrice.AskPrice - shortPrice.BidPrice) / shortPrice.AskPrice
+	if shortWidth > longWidth {
+		return shortPrice.Exchange
+	}
+	return longPrice.Exchange
+}
+
+// bookDepth возвращает суммарный объём обеих сторон стакана
+func bookDepth(book *CachedOrderBook) float64 {
+	var total float64
+	for _, level := range book.Bids {
+		total += level.Volume
+	}
+	for _, level := range book.Asks {
+		total += level.Volume
+	}
+	return total
+}
+
 // ============================================================
 // Дополнительные утилиты
 // ============================================================
 
 // GetCurrentSpread возвращает текущий спред для открытой позиции
 // Используется для проверки условий выхода
+//
+// Спред измеряется так же, как при входе: насколько биржа шорта дороже
+// биржи лонга. Пока разница не сузилась, спред положительный и близок
+// к спреду входа; выход при спред ≤ спред выхода (ТЗ).
 func (sc *SpreadCalculator) GetCurrentSpread(symbol, longExch, shortExch string) float64 {
 	longPrice := sc.tracker.GetExchangePrice(symbol, longExch)
 	shortPrice := sc.tracker.GetExchangePrice(symbol, shortExch)
@@ -606,12 +813,12 @@ func (sc *SpreadCalculator) GetCurrentSpread(symbol, longExch, shortExch string)
 	}
 
 	// Для выхода: продаём лонг по Bid, покупаем шорт по Ask
-	// Спред = (Bid_long - Ask_short) / Ask_short * 100
-	if shortPrice.AskPrice == 0 {
+	// Спред = (Ask_short - Bid_long) / Bid_long * 100
+	if longPrice.BidPrice == 0 {
 		return 0
 	}
 
-	return (longPrice.BidPrice - shortPrice.AskPrice) / shortPrice.AskPrice * 100
+	return (shortPrice.AskPrice - longPrice.BidPrice) / longPrice.BidPrice * 100
 }
 
 // CalculatePnl рассчитывает PNL для открытой позиции
@@ -665,6 +872,9 @@ type OrderBookAnalyzer struct {
 
 	// Максимальное время актуальности стакана
 	maxAge time.Duration
+
+	// Источник времени (подменяется в backtest на виртуальные часы)
+	now func() time.Time
 }
 
 // OrderBookKey - ключ для кэша стаканов
@@ -753,9 +963,16 @@ func NewOrderBookAnalyzer(depth int, maxAge time.Duration) *OrderBookAnalyzer {
 		depth:              depth,
 		maxAge:             maxAge,
 		minAnalyzeInterval: 50 * time.Millisecond,
+		now:                time.Now,
 	}
 }
 
+// SetClock подменяет источник времени для актуальности стаканов и кэша анализа
+// Вызывать до начала работы (не потокобезопасно)
+func (oba *OrderBookAnalyzer) SetClock(now func() time.Time) {
+	oba.now = now
+}
+
 func liquidityVolumeBucket(volume float64) int64 {
 	// Биндим объёмы с шагом 1e-3 чтобы избежать бесконечного роста ключей и не искажать точность
 	return int64(volume * 1000)
@@ -792,7 +1009,7 @@ func (oba *OrderBookAnalyzer) UpdateOrderBook(symbol, exchange string, bids, ask
 	cached := &CachedOrderBook{
 		Bids:      bidsCopy,
 		Asks:      asksCopy,
-		Timestamp: time.Now(),
+		Timestamp: oba.now(),
 	}
 
 	oba.orderBooks.Store(key, cached)
@@ -805,7 +1022,7 @@ func (oba *OrderBookAnalyzer) GetOrderBook(symbol, exchange string) *CachedOrder
 	if v, ok := oba.orderBooks.Load(key); ok {
 		cached := v.(*CachedOrderBook)
 		// Проверяем актуальность
-		if time.Since(cached.Timestamp) <= oba.maxAge {
+		if oba.now().Sub(cached.Timestamp) <= oba.maxAge {
 			return cached
 		}
 	}
@@ -916,7 +1133,7 @@ func (oba *OrderBookAnalyzer) AnalyzeLiquidity(
 		volumeBucket: liquidityVolumeBucket(volume),
 	}
 
-	now := time.Now().UnixNano()
+	now := oba.now().UnixNano()
 	if entry, ok := oba.liquidityCache.Load(cacheKey); ok {
 		cached := entry.(*liquidityCacheEntry)
 		last := cached.lastCalc.Load()
@@ -1030,7 +1247,7 @@ func (sc *SpreadCalculator) GetRealSpread(
 	orderBookAnalyzer *OrderBookAnalyzer,
 ) *ArbitrageOpportunity {
 	// Сначала получаем базовую возможность (лучшие цены)
-	best := sc.tracker.GetBestPrices(symbol)
+	best := sc.tracker.GetEntryPrices(symbol, nil)
 	if best == nil || best.BestAskExch == best.BestBidExch || best.RawSpread <= 0 {
 		return nil
 	}
@@ -1047,17 +1264,7 @@ func (sc *SpreadCalculator) GetRealSpread(
 	adjustedSpread := analysis.AdjustedSpread
 
 	// Вычитаем комиссии
-	sc.feesMu.RLock()
-	feeLong := sc.fees[best.BestAskExch]
-	feeShort := sc.fees[best.BestBidExch]
-	sc.feesMu.RUnlock()
-
-	if feeLong == 0 {
-		feeLong = 0.0005
-	}
-	if feeShort == 0 {
-		feeShort = 0.0005
-	}
+	feeLong, feeShort := sc.takerFees(best.BestAskExch, best.BestBidExch)
 
 	totalFees := 2 * (feeLong + feeShort) * 100
 	netSpread := adjustedSpread - totalFees
@@ -1073,6 +1280,7 @@ func (sc *SpreadCalculator) GetRealSpread(
 	opp.RawSpread = adjustedSpread                     // спред с учётом slippage
 	opp.NetSpread = netSpread                          // минус комиссии
 	opp.Timestamp = best.BestAskTime
+	sc.applyFunding(opp)
 
 	return opp
 }
@@ -1112,13 +1320,15 @@ type SpreadWithLiquidity struct {
 }
 
 // GetSpreadWithLiquidity возвращает спред с учётом ликвидности
+// accounts - аккаунты пары для ног (nil - все аккаунты)
 func (sc *SpreadCalculator) GetSpreadWithLiquidity(
 	symbol string,
+	accounts []string,
 	volume float64,
 	orderBookAnalyzer *OrderBookAnalyzer,
 ) *SpreadWithLiquidity {
 	// Базовая возможность
-	opp := sc.GetBestOpportunity(symbol)
+	opp := sc.GetBestOpportunity(symbol, accounts)
 	if opp == nil {
 		return nil
 	}
@@ -1158,10 +1368,10 @@ func (sc *SpreadCalculator) GetSpreadWithLiquidity(
 	result.TotalSlippage = result.LongSlippage + result.ShortSlippage
 	result.AdjustedSpread = analysis.AdjustedSpread
 
-	// Пересчитываем спред с учётом комиссий и VWAP
+	// Пересчитываем спред с учётом комиссий и VWAP (фандинг уже посчитан в GetBestOpportunity)
 	opp.RawSpread = analysis.AdjustedSpread
 	opp.NetSpread = sc.calculateNetSpreadFromPrices(
-		opp.RawSpread, opp.LongExchange, opp.ShortExchange)
+		opp.RawSpread, opp.LongExchange, opp.ShortExchange) + opp.FundingPct
 
 	return result
 }
@@ -1172,17 +1382,7 @@ func (sc *SpreadCalculator) calculateNetSpreadFromPrices(
 	longExchange string,
 	shortExchange string,
 ) float64 {
-	sc.feesMu.RLock()
-	feeLong := sc.fees[longExchange]
-	feeShort := sc.fees[shortExchange]
-	sc.feesMu.RUnlock()
-
-	if feeLong == 0 {
-		feeLong = 0.0005
-	}
-	if feeShort == 0 {
-		feeShort = 0.0005
-	}
+	feeLong, feeShort := sc.takerFees(longExchange, shortExchange)
 
 	totalFees := 2 * (feeLong + feeShort) * 100
 	return rawSpread - totalFees
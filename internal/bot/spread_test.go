@@ -273,7 +273,7 @@ func TestSpreadCalculatorGetBestOpportunity(t *testing.T) {
 		Timestamp: now,
 	})
 
-	opp := sc.GetBestOpportunity("BTCUSDT")
+	opp := sc.GetBestOpportunity("BTCUSDT", nil)
 	if opp == nil {
 		t.Fatal("GetBestOpportunity returned nil")
 	}
@@ -319,7 +319,7 @@ func TestSpreadCalculatorSameExchange(t *testing.T) {
 		Timestamp: time.Now(),
 	})
 
-	opp := sc.GetBestOpportunity("ETHUSDT")
+	opp := sc.GetBestOpportunity("ETHUSDT", nil)
 	if opp != nil {
 		t.Error("expected nil when same exchange for bid/ask")
 	}
@@ -348,7 +348,7 @@ func TestSpreadCalculatorNegativeSpread(t *testing.T) {
 		Timestamp: now,
 	})
 
-	opp := sc.GetBestOpportunity("DOTUSDT")
+	opp := sc.GetBestOpportunity("DOTUSDT", nil)
 	if opp != nil {
 		t.Error("expected nil for negative spread")
 	}
@@ -377,11 +377,11 @@ func TestSpreadCalculatorGetCurrentSpread(t *testing.T) {
 	})
 
 	// Для выхода: продаём лонг по Bid_long, покупаем шорт по Ask_short
-	// Spread = (Bid_bybit - Ask_okx) / Ask_okx * 100
-	// = (15.0 - 14.9) / 14.9 * 100 = 0.6711...%
+	// Spread = (Ask_okx - Bid_bybit) / Bid_bybit * 100
+	// = (14.9 - 15.0) / 15.0 * 100 = -0.6667...% (цены пересеклись - выход в плюс)
 	spread := sc.GetCurrentSpread("LINKUSDT", "bybit", "okx")
 
-	expected := (15.0 - 14.9) / 14.9 * 100
+	expected := (14.9 - 15.0) / 15.0 * 100
 	if abs(spread-expected) > 0.0001 {
 		t.Errorf("expected CurrentSpread=%f, got %f", expected, spread)
 	}
@@ -461,7 +461,7 @@ func TestSpreadCalculatorDefaultFees(t *testing.T) {
 		Timestamp: now,
 	})
 
-	opp := sc.GetBestOpportunity("TESTUSDT")
+	opp := sc.GetBestOpportunity("TESTUSDT", nil)
 	if opp == nil {
 		t.Fatal("GetBestOpportunity returned nil")
 	}
@@ -1031,7 +1031,7 @@ func TestSpreadCalculatorGetSpreadWithLiquidity(t *testing.T) {
 		},
 		[]PriceLevel{{Price: 1.06, Volume: 100.0}})
 
-	result := sc.GetSpreadWithLiquidity("ARBUSDT", 75.0, oba)
+	result := sc.GetSpreadWithLiquidity("ARBUSDT", nil, 75.0, oba)
 	if result == nil {
 		t.Fatal("GetSpreadWithLiquidity returned nil")
 	}
@@ -1074,7 +1074,7 @@ func TestSpreadCalculatorGetSpreadWithLiquidityNoAnalyzer(t *testing.T) {
 	})
 
 	// Без analyzer - должен вернуть базовые данные
-	result := sc.GetSpreadWithLiquidity("NOOBTEST", 1.0, nil)
+	result := sc.GetSpreadWithLiquidity("NOOBTEST", nil, 1.0, nil)
 	if result == nil {
 		t.Fatal("GetSpreadWithLiquidity returned nil without analyzer")
 	}
@@ -1149,7 +1149,7 @@ func BenchmarkSpreadCalculatorGetBestOpportunity(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = sc.GetBestOpportunity("BTCUSDT")
+		_ = sc.GetBestOpportunity("BTCUSDT", nil)
 	}
 }
 
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 )
 
@@ -13,6 +14,7 @@ type Config struct {
 	Database DatabaseConfig
 	Security SecurityConfig
 	Bot      BotConfig
+	Exchange ExchangeConfig
 	Logging  LoggingConfig
 }
 
@@ -62,8 +64,47 @@ type BotConfig struct {
 	RetryBackoff    time.Duration
 	OrderTimeout    time.Duration // таймаут ожидания исполнения ордера
 
+	// Подтверждение исполнения через поток ордеров (SubscribeOrders)
+	FillConfirmTimeout time.Duration // сколько ждать итогового статуса ордера из потока
+
+	// Режим входа maker_taker
+	MakerQuoteTimeout    time.Duration // сколько держать post-only котировку до отмены
+	MakerRepriceInterval time.Duration // период проверки исполнения и перестановки котировки
+
+	// Учёт фандинга в спреде (включается Settings.ConsiderFunding)
+	FundingHoldPeriod      time.Duration // ожидаемое время удержания позиции
+	FundingRefreshInterval time.Duration // период обновления ставок фандинга
+
+	// Комиссии аккаунта (мейкер/тейкер с учётом VIP-уровня)
+	FeeRefreshInterval time.Duration // период обновления комиссий с бирж
+
 	// Торговые параметры
 	MaxConcurrentArbs int // максимум одновременных арбитражей (0 = без лимита)
+
+	// Graceful shutdown
+	ShutdownTimeout time.Duration // ожидание завершения входов/выходов при остановке
+
+	// Запись рыночных данных (тикеры + снимки стаканов) для replay/backtest
+	RecordMarketData       bool          // включить запись
+	RecordDir              string        // каталог для файлов записи
+	RecordSnapshotInterval time.Duration // период снимков стаканов (0 = только тикеры)
+	RecordSnapshotDepth    int           // глубина снимка стакана
+	RecordMaxFileSizeMB    int           // ротация файла по размеру (несжатые МБ)
+	RecordRotateInterval   time.Duration // ротация файла по времени
+}
+
+// ExchangeConfig - настройки подписанных запросов к биржам и оценки их состояния
+type ExchangeConfig struct {
+	ClockSyncInterval time.Duration            // период пересинхронизации смещения часов биржи
+	RecvWindow        time.Duration            // окно приёма подписанного запроса (recvWindow)
+	RecvWindows       map[string]time.Duration // окно приёма по биржам (binance=3s,bybit=10s)
+
+	// Отключение биржи от новых входов (circuit breaker)
+	HealthWindow       time.Duration // окно статистики REST запросов
+	HealthTripErrorPct int           // доля ошибок REST в окне для отключения, %
+	HealthTripLatency  time.Duration // средняя задержка REST для отключения
+	HealthStaleAfter   time.Duration // WebSocket без сообщений дольше - биржа деградировала
+	HealthCooldown     time.Duration // отключение держится после последнего превышения порога
 }
 
 // LoggingConfig - настройки логирования
@@ -114,8 +155,44 @@ func Load() (*Config, error) {
 			RetryBackoff: getEnvAsDuration("RETRY_BACKOFF", 500*time.Millisecond),
 			OrderTimeout: getEnvAsDuration("ORDER_TIMEOUT", 5*time.Second),
 
+			// Подтверждение исполнения
+			FillConfirmTimeout: getEnvAsDuration("FILL_CONFIRM_TIMEOUT", 1*time.Second),
+
+			// Котировка maker_taker
+			MakerQuoteTimeout:    getEnvAsDuration("MAKER_QUOTE_TIMEOUT", 30*time.Second),
+			MakerRepriceInterval: getEnvAsDuration("MAKER_REPRICE_INTERVAL", 250*time.Millisecond),
+
+			// Фандинг
+			FundingHoldPeriod:      getEnvAsDuration("FUNDING_HOLD_PERIOD", 8*time.Hour),
+			FundingRefreshInterval: getEnvAsDuration("FUNDING_REFRESH_INTERVAL", 1*time.Minute),
+
+			// Комиссии
+			FeeRefreshInterval: getEnvAsDuration("FEE_REFRESH_INTERVAL", 1*time.Hour),
+
 			// Торговые лимиты
 			MaxConcurrentArbs: getEnvAsInt("MAX_CONCURRENT_ARBS", 0), // 0 = без лимита
+
+			// Остановка движка
+			ShutdownTimeout: getEnvAsDuration("SHUTDOWN_TIMEOUT", 30*time.Second),
+
+			// Запись рыночных данных (по умолчанию выключена)
+			RecordMarketData:       getEnvAsBool("RECORD_MARKET_DATA", false),
+			RecordDir:              getEnv("RECORD_DIR", "data/marketdata"),
+			RecordSnapshotInterval: getEnvAsDuration("RECORD_SNAPSHOT_INTERVAL", 1*time.Second),
+			RecordSnapshotDepth:    getEnvAsInt("RECORD_SNAPSHOT_DEPTH", 20),
+			RecordMaxFileSizeMB:    getEnvAsInt("RECORD_MAX_FILE_MB", 256),
+			RecordRotateInterval:   getEnvAsDuration("RECORD_ROTATE_INTERVAL", 1*time.Hour),
+		},
+		Exchange: ExchangeConfig{
+			ClockSyncInterval: getEnvAsDuration("CLOCK_SYNC_INTERVAL", 5*time.Minute),
+			RecvWindow:        getEnvAsDuration("EXCHANGE_RECV_WINDOW", 5*time.Second),
+			RecvWindows:       getEnvAsDurationMap("EXCHANGE_RECV_WINDOWS"),
+
+			HealthWindow:       getEnvAsDuration("HEALTH_WINDOW", time.Minute),
+			HealthTripErrorPct: getEnvAsInt("HEALTH_TRIP_ERROR_PCT", 50),
+			HealthTripLatency:  getEnvAsDuration("HEALTH_TRIP_LATENCY", 5*time.Second),
+			HealthStaleAfter:   getEnvAsDuration("HEALTH_WS_STALE", 90*time.Second),
+			HealthCooldown:     getEnvAsDuration("HEALTH_COOLDOWN", 30*time.Second),
 		},
 		Logging: LoggingConfig{
 			Level:  getEnv("LOG_LEVEL", "info"),
@@ -188,15 +265,83 @@ func (c *Config) validateRanges() error {
 		return fmt.Errorf("ORDER_TIMEOUT must be positive, got %v", c.Bot.OrderTimeout)
 	}
 
+	if c.Bot.FillConfirmTimeout <= 0 {
+		return fmt.Errorf("FILL_CONFIRM_TIMEOUT must be positive, got %v", c.Bot.FillConfirmTimeout)
+	}
+
+	if c.Bot.MakerQuoteTimeout <= 0 {
+		return fmt.Errorf("MAKER_QUOTE_TIMEOUT must be positive, got %v", c.Bot.MakerQuoteTimeout)
+	}
+
+	if c.Bot.MakerRepriceInterval <= 0 {
+		return fmt.Errorf("MAKER_REPRICE_INTERVAL must be positive, got %v", c.Bot.MakerRepriceInterval)
+	}
+
+	if c.Bot.FundingHoldPeriod <= 0 {
+		return fmt.Errorf("FUNDING_HOLD_PERIOD must be positive, got %v", c.Bot.FundingHoldPeriod)
+	}
+
+	if c.Bot.FundingRefreshInterval <= 0 {
+		return fmt.Errorf("FUNDING_REFRESH_INTERVAL must be positive, got %v", c.Bot.FundingRefreshInterval)
+	}
+
+	if c.Bot.FeeRefreshInterval <= 0 {
+		return fmt.Errorf("FEE_REFRESH_INTERVAL must be positive, got %v", c.Bot.FeeRefreshInterval)
+	}
+
 	if c.Bot.WSReadTimeout <= 0 {
 		return fmt.Errorf("WS_READ_TIMEOUT must be positive, got %v", c.Bot.WSReadTimeout)
 	}
 
+	if c.Bot.ShutdownTimeout <= 0 {
+		return fmt.Errorf("SHUTDOWN_TIMEOUT must be positive, got %v", c.Bot.ShutdownTimeout)
+	}
+
 	// Валидация MaxConcurrentArbs (0 = без лимита, иначе > 0)
+	if c.Bot.RecordMarketData {
+		if c.Bot.RecordDir == "" {
+			return fmt.Errorf("RECORD_DIR is required when RECORD_MARKET_DATA is enabled")
+		}
+		if c.Bot.RecordSnapshotInterval < 0 {
+			return fmt.Errorf("RECORD_SNAPSHOT_INTERVAL cannot be negative, got %v", c.Bot.RecordSnapshotInterval)
+		}
+		if c.Bot.RecordMaxFileSizeMB < 0 {
+			return fmt.Errorf("RECORD_MAX_FILE_MB cannot be negative, got %d", c.Bot.RecordMaxFileSizeMB)
+		}
+	}
+
 	if c.Bot.MaxConcurrentArbs < 0 {
 		return fmt.Errorf("MAX_CONCURRENT_ARBS cannot be negative, got %d", c.Bot.MaxConcurrentArbs)
 	}
 
+	if c.Exchange.ClockSyncInterval <= 0 {
+		return fmt.Errorf("CLOCK_SYNC_INTERVAL must be positive, got %v", c.Exchange.ClockSyncInterval)
+	}
+
+	// Binance и Bybit принимают recvWindow не больше 60 секунд
+	if c.Exchange.RecvWindow <= 0 || c.Exchange.RecvWindow > time.Minute {
+		return fmt.Errorf("EXCHANGE_RECV_WINDOW must be between 0 and 1m, got %v", c.Exchange.RecvWindow)
+	}
+	for name, window := range c.Exchange.RecvWindows {
+		if window <= 0 || window > time.Minute {
+			return fmt.Errorf("EXCHANGE_RECV_WINDOWS: %s must be between 0 and 1m, got %v", name, window)
+		}
+	}
+
+	if c.Exchange.HealthTripErrorPct < 1 || c.Exchange.HealthTripErrorPct > 100 {
+		return fmt.Errorf("HEALTH_TRIP_ERROR_PCT must be between 1 and 100, got %d", c.Exchange.HealthTripErrorPct)
+	}
+	for name, value := range map[string]time.Duration{
+		"HEALTH_WINDOW":       c.Exchange.HealthWindow,
+		"HEALTH_TRIP_LATENCY": c.Exchange.HealthTripLatency,
+		"HEALTH_WS_STALE":     c.Exchange.HealthStaleAfter,
+		"HEALTH_COOLDOWN":     c.Exchange.HealthCooldown,
+	} {
+		if value <= 0 {
+			return fmt.Errorf("%s must be positive, got %v", name, value)
+		}
+	}
+
 	// Валидация SessionTimeout
 	if c.Security.SessionTimeout < 60 {
 		return fmt.Errorf("SESSION_TIMEOUT must be at least 60 seconds, got %d", c.Security.SessionTimeout)
@@ -261,3 +406,25 @@ func getEnvAsDuration(key string, defaultValue time.Duration) time.Duration {
 	}
 	return value
 }
+
+// getEnvAsDurationMap читает список вида "binance=3s,bybit=10s"
+// Некорректные элементы пропускаются
+func getEnvAsDurationMap(key string) map[string]time.Duration {
+	valueStr := os.Getenv(key)
+	if valueStr == "" {
+		return nil
+	}
+	values := make(map[string]time.Duration)
+	for _, item := range strings.Split(valueStr, ",") {
+		name, durationStr, ok := strings.Cut(strings.TrimSpace(item), "=")
+		if !ok {
+			continue
+		}
+		value, err := time.ParseDuration(strings.TrimSpace(durationStr))
+		if err != nil {
+			continue
+		}
+		values[strings.ToLower(strings.TrimSpace(name))] = value
+	}
+	return values
+}
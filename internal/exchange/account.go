@@ -0,0 +1,67 @@
+package exchange
+
+import "strings"
+
+// ============================================================
+// Аккаунты бирж
+// ============================================================
+//
+// На одной бирже может быть несколько аккаунтов (субаккаунтов) с отдельными
+// ключами и маржой. Аккаунт определяется биржей и меткой; движок, пары и
+// ноги позиций используют идентификатор аккаунта:
+//   - "bybit"      - основной аккаунт (пустая метка)
+//   - "arb1@bybit" - аккаунт с меткой arb1
+//
+// Основной аккаунт совпадает с именем биржи, поэтому конфигурации с одним
+// аккаунтом на биржу не меняются. Возможности, состояние (HealthOf) и лимиты
+// REST общие для всех аккаунтов биржи.
+
+// accountSeparator отделяет метку аккаунта от имени биржи
+const accountSeparator = "@"
+
+// MaxAccountLabelLen - максимальная длина метки аккаунта
+const MaxAccountLabelLen = 32
+
+// AccountKey возвращает идентификатор аккаунта по имени биржи и метке
+func AccountKey(venue, label string) string {
+	venue = strings.ToLower(venue)
+	if label == "" {
+		return venue
+	}
+	return strings.ToLower(label) + accountSeparator + venue
+}
+
+// SplitAccountKey разбирает идентификатор аккаунта на имя биржи и метку
+func SplitAccountKey(key string) (venue, label string) {
+	key = strings.ToLower(key)
+	if i := strings.LastIndex(key, accountSeparator); i >= 0 {
+		return key[i+1:], key[:i]
+	}
+	return key, ""
+}
+
+// VenueOf возвращает имя биржи аккаунта: "arb1@bybit" -> "bybit"
+func VenueOf(key string) string {
+	venue, _ := SplitAccountKey(key)
+	return venue
+}
+
+// ValidAccountLabel проверяет метку аккаунта: до MaxAccountLabelLen символов a-z, 0-9, _ и -
+// Пустая метка - основной аккаунт
+func ValidAccountLabel(label string) bool {
+	if len(label) > MaxAccountLabelLen {
+		return false
+	}
+	for _, c := range label {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '_' && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// IsSupportedAccount проверяет идентификатор аккаунта: биржа зарегистрирована, метка допустима
+func IsSupportedAccount(key string) bool {
+	venue, label := SplitAccountKey(key)
+	return IsSupported(venue) && ValidAccountLabel(label)
+}
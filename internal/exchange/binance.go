@@ -0,0 +1,1617 @@
+package exchange
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	binanceBaseURL = "https://fapi.binance.com"
+	binanceWSURL   = "wss://fstream.binance.com/ws"
+
+	// listenKey действует 60 минут, продлеваем с запасом
+	binanceListenKeyEndpoint  = "/fapi/v1/listenKey"
+	binanceListenKeyKeepAlive = 30 * time.Minute
+
+	// binanceMaxLeverage - плечо без запроса leverageBracket (зависит от символа и объёма позиции)
+	binanceMaxLeverage = 125
+
+	// binanceSnapshotDepth - глубина REST снимка для синхронизации локального стакана
+	binanceSnapshotDepth = 1000
+
+	// binanceDepthBufferSize - сколько событий depthUpdate копить, пока грузится снимок
+	binanceDepthBufferSize = 1000
+
+	// binanceSnapshotRetry - пауза перед повторной загрузкой снимка после ошибки
+	binanceSnapshotRetry = time.Second
+)
+
+// Binance реализует интерфейс Exchange для фьючерсов Binance USDT-M
+// Счёт в режиме одной позиции на символ (positionSide BOTH), размеры в монетах
+type Binance struct {
+	apiKey    string
+	secretKey string
+
+	httpClient *http.Client
+	endpoints  Endpoints       // адреса API, подменяются SetEndpoints
+	limiter    *requestLimiter // лимиты REST запросов
+	clock      *Clock          // смещение часов биржи для подписи запросов
+	health     *ExchangeHealth // состояние биржи для отключения от входов
+
+	// WebSocket managers с автоматическим переподключением
+	wsManager *WSReconnectManager
+	wsMu      sync.Mutex // защита инициализации WebSocket managers
+	wsReqID   int64      // id запросов SUBSCRIBE
+
+	// Приватный поток (user data stream) адресуется listenKey
+	wsPrivateManager *WSReconnectManager
+	listenKey        string
+	listenKeyMu      sync.Mutex
+
+	tickerCallbacks  map[string]func(*Ticker)
+	positionCallback func(*Position)
+	orderCallback    func(*Order)
+	callbackMu       sync.RWMutex
+
+	// Локальные стаканы SubscribeOrderBook и буферы их синхронизации
+	books     orderBookSubscriptions
+	depthSync map[string]*binanceDepthSync
+	depthMu   sync.Mutex
+
+	// Накопленная комиссия ордеров из потока: событие сообщает комиссию только последней сделки
+	orderFees   map[string]float64
+	orderFeesMu sync.Mutex
+
+	// Спецификации контрактов (размеры Binance в монетах)
+	instruments *InstrumentRegistry
+
+	connected bool
+	closeChan chan struct{}
+}
+
+// parseFloat парсит строку в float64 с логированием ошибок
+func (b *Binance) parseFloat(value, field string) float64 {
+	result, err := strconv.ParseFloat(value, 64)
+	if err != nil && value != "" {
+		log.Printf("[binance] failed to parse %s %q: %v", field, value, err)
+	}
+	return result
+}
+
+// init регистрирует адаптер Binance в реестре бирж
+func init() {
+	Register(Adapter{
+		Name: "binance",
+		New:  func() Exchange { return NewBinance() },
+		Capabilities: Capabilities{
+			PrivateWS:   true,
+			LimitOrders: true,
+			Funding:     true,
+			HedgeMode:   false,
+		},
+		TakerFee: 0.0005,
+		MakerFee: 0.0002,
+		// 2400 единиц веса в минуту на IP, 300 ордеров за 10 секунд на аккаунт
+		RateLimits: RateLimits{
+			Total:  40,
+			Orders: 30,
+			Endpoints: map[string]EndpointLimit{
+				"/fapi/v1/order":             {Category: RateCategoryOrders},
+				"/fapi/v1/openOrders":        {Category: RateCategoryOrders},
+				"/fapi/v1/ticker/bookTicker": {Weight: 2},
+				// От 2 (limit до 50) до 20 (limit 1000), точный расход приходит в заголовке
+				"/fapi/v1/depth":          {Weight: 5},
+				"/fapi/v2/account":        {Weight: 5},
+				"/fapi/v2/balance":        {Weight: 5},
+				"/fapi/v2/positionRisk":   {Weight: 5},
+				"/fapi/v1/commissionRate": {Weight: 20},
+				binanceListenKeyEndpoint:  {Category: RateCategoryAccount},
+			},
+			UsedHeader:    "X-MBX-USED-WEIGHT-1M",
+			WindowLimit:   2400,
+			SharedHeaders: true,
+			LimitCodes:    []string{"-1015"},
+		},
+	})
+}
+
+// NewBinance создаёт новый экземпляр Binance USDT-M
+// Использует глобальный HTTP клиент с connection pooling и оптимизированными таймаутами
+func NewBinance() *Binance {
+	b := &Binance{
+		httpClient:      GetGlobalHTTPClient().GetClient(),
+		endpoints:       Endpoints{REST: binanceBaseURL, WSPublic: binanceWSURL, WSPrivate: binanceWSURL},
+		limiter:         newRequestLimiter("binance"),
+		health:          HealthOf("binance"),
+		tickerCallbacks: make(map[string]func(*Ticker)),
+		depthSync:       make(map[string]*binanceDepthSync),
+		orderFees:       make(map[string]float64),
+		closeChan:       make(chan struct{}),
+	}
+	b.instruments = NewInstrumentRegistry("binance", b.loadInstruments)
+	b.clock = NewClock("binance", b.getServerTime, "-1021")
+	return b
+}
+
+// SetEndpoints подменяет адреса REST и WebSocket API
+func (b *Binance) SetEndpoints(endpoints Endpoints) {
+	b.endpoints = endpoints
+}
+
+// getServerTime запрашивает время сервера для синхронизации часов
+func (b *Binance) getServerTime(ctx context.Context) (time.Time, error) {
+	body, err := b.doRequest(ctx, http.MethodGet, "/fapi/v1/time", nil, false)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var resp struct {
+		ServerTime int64 `json:"serverTime"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(resp.ServerTime), nil
+}
+
+// sign создаёт подпись HMAC SHA256 строки параметров запроса
+func (b *Binance) sign(params string) string {
+	h := hmac.New(sha256.New, []byte(b.secretKey))
+	h.Write([]byte(params))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// doRequest выполняет запрос к Binance API и учитывает его в состоянии биржи
+func (b *Binance) doRequest(ctx context.Context, method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
+	start := time.Now()
+	body, err := b.sendRequest(ctx, method, endpoint, params, signed)
+	b.health.ObserveRequest(time.Since(start), err)
+	return body, err
+}
+
+// sendRequest выполняет HTTP запрос к Binance API
+// Ошибки приходят с HTTP кодом 4xx/5xx и телом {"code": -2019, "msg": "..."}
+func (b *Binance) sendRequest(ctx context.Context, method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
+	var reqBody string
+	reqURL := b.endpoints.REST + endpoint
+
+	query := url.Values{}
+	for k, v := range params {
+		query.Set(k, v)
+	}
+
+	queryStr := query.Encode()
+	if signed {
+		timestamp := strconv.FormatInt(b.clock.Now().UnixMilli(), 10)
+		query.Set("timestamp", timestamp)
+		query.Set("recvWindow", recvWindowMillis("binance"))
+
+		// Подпись - последний параметр, считается по строке остальных параметров
+		queryStr = query.Encode()
+		queryStr += "&signature=" + b.sign(queryStr)
+	}
+
+	// GET и DELETE передают параметры в строке запроса, POST и PUT - в теле
+	if method == http.MethodGet || method == http.MethodDelete {
+		if queryStr != "" {
+			reqURL += "?" + queryStr
+		}
+	} else {
+		reqBody = queryStr
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, reqURL, strings.NewReader(reqBody))
+	if err != nil {
+		return nil, err
+	}
+
+	if reqBody != "" {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if b.apiKey != "" {
+		req.Header.Set("X-MBX-APIKEY", b.apiKey)
+	}
+
+	if err := b.limiter.Wait(ctx, endpoint, signed); err != nil {
+		return nil, err
+	}
+
+	resp, err := b.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	b.limiter.Observe(endpoint, signed, resp)
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= http.StatusBadRequest {
+		var errResp struct {
+			Code int    `json:"code"`
+			Msg  string `json:"msg"`
+		}
+		if err := json.Unmarshal(body, &errResp); err != nil || errResp.Msg == "" {
+			return nil, httpStatusError("binance", resp.StatusCode, body, err)
+		}
+		return nil, b.limiter.CheckError(b.clock.CheckError(&ExchangeError{
+			Exchange: "binance",
+			Code:     strconv.Itoa(errResp.Code),
+			Message:  errResp.Msg,
+		}))
+	}
+
+	return body, nil
+}
+
+func (b *Binance) Connect(apiKey, secret, passphrase string) error {
+	b.apiKey = apiKey
+	b.secretKey = secret
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Смещение часов нужно до первого подписанного запроса; при ошибке подпись идёт по локальному времени
+	if err := b.clock.Sync(ctx); err != nil {
+		log.Printf("[binance] %v", err)
+	}
+
+	_, err := b.GetBalance(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Binance: %w", err)
+	}
+
+	b.connected = true
+	return nil
+}
+
+func (b *Binance) GetName() string {
+	return "binance"
+}
+
+// GetBalance возвращает equity USDT: баланс кошелька плюс нереализованный PnL
+func (b *Binance) GetBalance(ctx context.Context) (float64, error) {
+	body, err := b.doRequest(ctx, http.MethodGet, "/fapi/v2/balance", nil, true)
+	if err != nil {
+		return 0, err
+	}
+
+	var resp []struct {
+		Asset      string `json:"asset"`
+		Balance    string `json:"balance"`
+		CrossUnPnl string `json:"crossUnPnl"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, err
+	}
+
+	for _, asset := range resp {
+		if asset.Asset == "USDT" {
+			return b.parseFloat(asset.Balance, "balance") + b.parseFloat(asset.CrossUnPnl, "crossUnPnl"), nil
+		}
+	}
+
+	return 0, nil
+}
+
+func (b *Binance) GetTicker(ctx context.Context, symbol string) (*Ticker, error) {
+	params := map[string]string{
+		"symbol": symbol,
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/fapi/v1/ticker/bookTicker", params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var book struct {
+		Symbol   string `json:"symbol"`
+		BidPrice string `json:"bidPrice"`
+		AskPrice string `json:"askPrice"`
+		Time     int64  `json:"time"`
+	}
+
+	if err := json.Unmarshal(body, &book); err != nil {
+		return nil, err
+	}
+
+	if book.Symbol == "" {
+		return nil, fmt.Errorf("ticker not found for %s", symbol)
+	}
+
+	// Последняя сделка - отдельным запросом, bookTicker её не содержит
+	body, err = b.doRequest(ctx, http.MethodGet, "/fapi/v1/ticker/price", params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var price struct {
+		Price string `json:"price"`
+	}
+
+	if err := json.Unmarshal(body, &price); err != nil {
+		return nil, err
+	}
+
+	return &Ticker{
+		Symbol:    symbol,
+		BidPrice:  b.parseFloat(book.BidPrice, "bidPrice"),
+		AskPrice:  b.parseFloat(book.AskPrice, "askPrice"),
+		LastPrice: b.parseFloat(price.Price, "price"),
+		Timestamp: time.UnixMilli(book.Time),
+	}, nil
+}
+
+// binanceDepth - ответ /fapi/v1/depth
+type binanceDepth struct {
+	LastUpdateID int64      `json:"lastUpdateId"`
+	Time         int64      `json:"T"`
+	Bids         [][]string `json:"bids"`
+	Asks         [][]string `json:"asks"`
+}
+
+// binanceDepthLimits - допустимые значения limit для /fapi/v1/depth
+var binanceDepthLimits = []int{5, 10, 20, 50, 100, 500, 1000}
+
+// getDepth запрашивает стакан с минимальным допустимым limit, покрывающим depth
+func (b *Binance) getDepth(ctx context.Context, symbol string, depth int) (*binanceDepth, error) {
+	limit := binanceDepthLimits[len(binanceDepthLimits)-1]
+	for _, l := range binanceDepthLimits {
+		if depth <= l {
+			limit = l
+			break
+		}
+	}
+
+	params := map[string]string{
+		"symbol": symbol,
+		"limit":  strconv.Itoa(limit),
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/fapi/v1/depth", params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp binanceDepth
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (b *Binance) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	resp, err := b.getDepth(ctx, symbol, depth)
+	if err != nil {
+		return nil, err
+	}
+
+	orderBook := &OrderBook{
+		Symbol:    symbol,
+		Bids:      make([]PriceLevel, 0, len(resp.Bids)),
+		Asks:      make([]PriceLevel, 0, len(resp.Asks)),
+		Timestamp: time.UnixMilli(resp.Time),
+	}
+
+	for _, bid := range resp.Bids {
+		if len(bid) >= 2 {
+			orderBook.Bids = append(orderBook.Bids, PriceLevel{
+				Price:  b.parseFloat(bid[0], "bid.price"),
+				Volume: b.parseFloat(bid[1], "bid.volume"),
+			})
+		}
+	}
+
+	for _, ask := range resp.Asks {
+		if len(ask) >= 2 {
+			orderBook.Asks = append(orderBook.Asks, PriceLevel{
+				Price:  b.parseFloat(ask[0], "ask.price"),
+				Volume: b.parseFloat(ask[1], "ask.volume"),
+			})
+		}
+	}
+
+	sort.Slice(orderBook.Bids, func(i, j int) bool {
+		return orderBook.Bids[i].Price > orderBook.Bids[j].Price
+	})
+	sort.Slice(orderBook.Asks, func(i, j int) bool {
+		return orderBook.Asks[i].Price < orderBook.Asks[j].Price
+	})
+
+	if depth > 0 && len(orderBook.Bids) > depth {
+		orderBook.Bids = orderBook.Bids[:depth]
+	}
+	if depth > 0 && len(orderBook.Asks) > depth {
+		orderBook.Asks = orderBook.Asks[:depth]
+	}
+
+	return orderBook, nil
+}
+
+// binanceOrderInfo - ордер в ответах /fapi/v1/order и /fapi/v1/openOrders
+type binanceOrderInfo struct {
+	OrderID       int64  `json:"orderId"`
+	ClientOrderID string `json:"clientOrderId"`
+	Symbol        string `json:"symbol"`
+	Side          string `json:"side"`
+	Type          string `json:"type"`
+	Price         string `json:"price"`
+	OrigQty       string `json:"origQty"`
+	ExecutedQty   string `json:"executedQty"`
+	AvgPrice      string `json:"avgPrice"`
+	Status        string `json:"status"`
+	TimeInForce   string `json:"timeInForce"`
+	Time          int64  `json:"time"`
+	UpdateTime    int64  `json:"updateTime"`
+}
+
+// binanceTimeInForce - соответствие time in force значениям Binance (GTX - post only)
+var binanceTimeInForce = map[string]string{
+	TimeInForceGTC:      "GTC",
+	TimeInForceIOC:      "IOC",
+	TimeInForceFOK:      "FOK",
+	TimeInForcePostOnly: "GTX",
+}
+
+// binanceSide конвертирует сторону ордера в формат Binance
+func binanceSide(side string) string {
+	if side == SideSell || side == SideShort {
+		return "SELL"
+	}
+	return "BUY"
+}
+
+func (b *Binance) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*Order, error) {
+	return b.placeMarketOrder(ctx, symbol, binanceSide(side), side, qty, false, clientOrderID)
+}
+
+// placeMarketOrder размещает рыночный ордер; reduceOnly - только уменьшение позиции
+// Ответ RESULT содержит итог исполнения: статус, объём и среднюю цену
+func (b *Binance) placeMarketOrder(ctx context.Context, symbol, venueSide, side string, qty float64, reduceOnly bool, clientOrderID string) (*Order, error) {
+	inst, size, err := b.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return nil, err
+	}
+	qty = inst.FromVenueSize(size)
+
+	params := map[string]string{
+		"symbol":           symbol,
+		"side":             venueSide,
+		"type":             "MARKET",
+		"quantity":         inst.FormatSize(size),
+		"newOrderRespType": "RESULT",
+	}
+	if reduceOnly {
+		params["reduceOnly"] = "true"
+	}
+	if clientOrderID != "" {
+		params["newClientOrderId"] = clientOrderID
+	}
+
+	body, err := b.doRequest(ctx, http.MethodPost, "/fapi/v1/order", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var info binanceOrderInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, err
+	}
+
+	order := b.parseOrder(info)
+	order.Symbol = symbol
+	order.Side = side
+	order.Quantity = qty
+	return order, nil
+}
+
+func (b *Binance) GetOpenPositions(ctx context.Context) ([]*Position, error) {
+	body, err := b.doRequest(ctx, http.MethodGet, "/fapi/v2/positionRisk", nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []struct {
+		Symbol           string `json:"symbol"`
+		PositionAmt      string `json:"positionAmt"`
+		EntryPrice       string `json:"entryPrice"`
+		MarkPrice        string `json:"markPrice"`
+		UnRealizedProfit string `json:"unRealizedProfit"`
+		Leverage         string `json:"leverage"`
+		PositionSide     string `json:"positionSide"`
+		UpdateTime       int64  `json:"updateTime"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	positions := make([]*Position, 0)
+	for _, p := range resp {
+		amount := b.parseFloat(p.PositionAmt, "positionAmt")
+		if amount == 0 {
+			continue
+		}
+
+		side := SideLong
+		if p.PositionSide == "SHORT" || amount < 0 {
+			side = SideShort
+		}
+
+		leverage, _ := strconv.Atoi(p.Leverage)
+
+		positions = append(positions, &Position{
+			Symbol:        p.Symbol,
+			Side:          side,
+			Size:          math.Abs(amount),
+			EntryPrice:    b.parseFloat(p.EntryPrice, "entryPrice"),
+			MarkPrice:     b.parseFloat(p.MarkPrice, "markPrice"),
+			Leverage:      leverage,
+			UnrealizedPnl: b.parseFloat(p.UnRealizedProfit, "unRealizedProfit"),
+			UpdatedAt:     time.UnixMilli(p.UpdateTime),
+		})
+	}
+
+	return positions, nil
+}
+
+// ClosePosition закрывает позицию рыночным ордером reduceOnly в противоположную сторону
+func (b *Binance) ClosePosition(ctx context.Context, symbol, side string, qty float64) error {
+	closeSide := SideBuy
+	if side == SideLong || side == SideBuy {
+		closeSide = SideSell
+	}
+
+	_, err := b.placeMarketOrder(ctx, symbol, binanceSide(closeSide), closeSide, qty, true, "")
+	return err
+}
+
+func (b *Binance) PlaceLimitOrder(ctx context.Context, symbol, side string, qty, price float64, tif string) (*Order, error) {
+	tif, err := normalizeTimeInForce(tif)
+	if err != nil {
+		return nil, err
+	}
+
+	inst, size, err := b.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return nil, err
+	}
+	qty = inst.FromVenueSize(size)
+
+	params := map[string]string{
+		"symbol":           symbol,
+		"side":             binanceSide(side),
+		"type":             "LIMIT",
+		"quantity":         inst.FormatSize(size),
+		"price":            strconv.FormatFloat(price, 'f', -1, 64),
+		"timeInForce":      binanceTimeInForce[tif],
+		"newOrderRespType": "RESULT",
+	}
+
+	body, err := b.doRequest(ctx, http.MethodPost, "/fapi/v1/order", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var info binanceOrderInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, err
+	}
+
+	// RESULT уже содержит итог для IOC/FOK и отклонённых post-only ордеров
+	if info.Status == "" {
+		return newLimitOrder(strconv.FormatInt(info.OrderID, 10), symbol, side, qty, price, tif), nil
+	}
+	order := b.parseOrder(info)
+	order.Side = side
+	return order, nil
+}
+
+func (b *Binance) CancelOrder(ctx context.Context, symbol, orderID string) error {
+	params := map[string]string{
+		"symbol":  symbol,
+		"orderId": orderID,
+	}
+
+	_, err := b.doRequest(ctx, http.MethodDelete, "/fapi/v1/order", params, true)
+	return err
+}
+
+func (b *Binance) GetOrder(ctx context.Context, symbol, orderID string) (*Order, error) {
+	return b.queryOrder(ctx, symbol, "orderId", orderID)
+}
+
+func (b *Binance) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*Order, error) {
+	return b.queryOrder(ctx, symbol, "origClientOrderId", clientOrderID)
+}
+
+// queryOrder запрашивает ордер по orderId или origClientOrderId
+// -2013: биржа не знает ордер
+func (b *Binance) queryOrder(ctx context.Context, symbol, key, id string) (*Order, error) {
+	params := map[string]string{
+		"symbol": symbol,
+		key:      id,
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/fapi/v1/order", params, true)
+	if err != nil {
+		return nil, orderNotFound(err, "-2013")
+	}
+
+	var info binanceOrderInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, err
+	}
+
+	if info.OrderID == 0 {
+		return nil, fmt.Errorf("order %s: %w", id, ErrOrderNotFound)
+	}
+	return b.parseOrder(info), nil
+}
+
+func (b *Binance) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	params := map[string]string{}
+	if symbol != "" {
+		params["symbol"] = symbol
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/fapi/v1/openOrders", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []binanceOrderInfo
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	orders := make([]*Order, 0, len(resp))
+	for _, info := range resp {
+		orders = append(orders, b.parseOrder(info))
+	}
+	return orders, nil
+}
+
+// parseOrder конвертирует ордер Binance в Order
+// REST ответы не содержат комиссию - она приходит в потоке ордеров
+func (b *Binance) parseOrder(info binanceOrderInfo) *Order {
+	order := &Order{
+		ID:            strconv.FormatInt(info.OrderID, 10),
+		ClientOrderID: info.ClientOrderID,
+		Symbol:        info.Symbol,
+		Side:          strings.ToLower(info.Side),
+		Type:          strings.ToLower(info.Type),
+		Price:         b.parseFloat(info.Price, "order.price"),
+		Quantity:      b.parseFloat(info.OrigQty, "order.origQty"),
+		FilledQty:     b.parseFloat(info.ExecutedQty, "order.executedQty"),
+		AvgFillPrice:  b.parseFloat(info.AvgPrice, "order.avgPrice"),
+		CreatedAt:     time.UnixMilli(info.Time),
+		UpdatedAt:     time.UnixMilli(info.UpdateTime),
+	}
+
+	for tif, binanceTIF := range binanceTimeInForce {
+		if info.TimeInForce == binanceTIF {
+			order.TimeInForce = tif
+		}
+	}
+
+	switch info.Status {
+	case "NEW":
+		order.Status = OrderStatusNew
+	case "PARTIALLY_FILLED":
+		order.Status = OrderStatusPartial
+	case "FILLED":
+		order.Status = OrderStatusFilled
+	case "REJECTED":
+		order.Status = OrderStatusRejected
+	default: // CANCELED, EXPIRED, EXPIRED_IN_MATCH
+		order.Status = OrderStatusCancelled
+	}
+
+	return order
+}
+
+// SubscribeTicker подписывается на поток <symbol>@bookTicker (лучшие bid/ask в реальном времени)
+func (b *Binance) SubscribeTicker(symbol string, callback func(*Ticker)) error {
+	b.callbackMu.Lock()
+	b.tickerCallbacks[symbol] = callback
+	b.callbackMu.Unlock()
+
+	return b.subscribePublic(strings.ToLower(symbol) + "@bookTicker")
+}
+
+// SubscribeOrderBook подписывается на дельты стакана <symbol>@depth@100ms
+//
+// Локальный стакан строится по схеме Binance: события копятся, пока грузится
+// REST снимок; затем применяются события после lastUpdateId снимка. Каждое
+// следующее событие должно ссылаться на предыдущее (pu == u предыдущего),
+// иначе стакан пересинхронизируется новым снимком.
+func (b *Binance) SubscribeOrderBook(symbol string, depth int, callback func(*OrderBook)) error {
+	b.books.add(symbol, depth, nil, callback)
+	return b.subscribePublic(strings.ToLower(symbol) + "@depth@100ms")
+}
+
+// subscribePublic подписывается на поток публичного WebSocket, подключаясь при необходимости
+func (b *Binance) subscribePublic(stream string) error {
+	b.wsMu.Lock()
+	if b.wsManager == nil {
+		config := DefaultWSReconnectConfig()
+		b.wsManager = NewWSReconnectManager("binance", b.endpoints.WSPublic, config)
+		b.health.TrackWS(b.wsManager)
+
+		b.wsManager.SetOnMessage(b.handlePublicMessage)
+		b.wsManager.SetOnConnect(func() {
+			log.Printf("[binance] WebSocket connected")
+		})
+
+		// Стаканы ждут нового снимка, который загрузится по первому событию после переподписки
+		b.wsManager.SetOnDisconnect(func(err error) {
+			b.books.invalidateAll()
+			if err != nil {
+				log.Printf("[binance] WebSocket disconnected: %v", err)
+			}
+		})
+
+		if err := b.wsManager.Connect(); err != nil {
+			b.wsManager = nil
+			b.wsMu.Unlock()
+			return fmt.Errorf("failed to connect to WebSocket: %w", err)
+		}
+	}
+	wsManager := b.wsManager
+	b.wsMu.Unlock()
+
+	subMsg := map[string]interface{}{
+		"method": "SUBSCRIBE",
+		"params": []string{stream},
+		"id":     atomic.AddInt64(&b.wsReqID, 1),
+	}
+
+	wsManager.AddSubscription(subMsg)
+	return wsManager.Send(subMsg)
+}
+
+// binanceDepthEvent - событие depthUpdate
+// U и u - первый и последний номер обновления в событии, pu - u предыдущего события
+type binanceDepthEvent struct {
+	Symbol  string     `json:"s"`
+	Time    int64      `json:"T"`
+	FirstID int64      `json:"U"`
+	FinalID int64      `json:"u"`
+	PrevID  int64      `json:"pu"`
+	Bids    [][]string `json:"b"`
+	Asks    [][]string `json:"a"`
+}
+
+// handlePublicMessage обрабатывает одно сообщение публичного WebSocket
+// Ответы на SUBSCRIBE ({"result":null,"id":1}) не содержат поля e и пропускаются
+//
+// Поля событий Binance различаются регистром (e/E, b/B), а encoding/json без точного
+// совпадения сопоставляет ключ без учёта регистра - поэтому в структурах событий
+// объявлены и парные поля, даже если они не используются
+func (b *Binance) handlePublicMessage(message []byte) {
+	var msg struct {
+		Event     string `json:"e"`
+		EventTime int64  `json:"E"`
+	}
+
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
+	}
+
+	switch msg.Event {
+	case "bookTicker":
+		b.handleBookTicker(message)
+	case "depthUpdate":
+		var event binanceDepthEvent
+		if err := json.Unmarshal(message, &event); err != nil {
+			return
+		}
+		b.handleDepthUpdate(&event)
+	}
+}
+
+// handleBookTicker обрабатывает поток bookTicker
+// Поток не содержит последней сделки - как и симулятор, отдаём середину спреда
+func (b *Binance) handleBookTicker(message []byte) {
+	var ticker struct {
+		Symbol   string `json:"s"`
+		BidPrice string `json:"b"`
+		BidQty   string `json:"B"`
+		AskPrice string `json:"a"`
+		AskQty   string `json:"A"`
+		Time     int64  `json:"T"`
+	}
+
+	if err := json.Unmarshal(message, &ticker); err != nil {
+		return
+	}
+
+	b.callbackMu.RLock()
+	callback, ok := b.tickerCallbacks[ticker.Symbol]
+	b.callbackMu.RUnlock()
+
+	if !ok || callback == nil {
+		return
+	}
+
+	bid := b.parseFloat(ticker.BidPrice, "ws.bidPrice")
+	ask := b.parseFloat(ticker.AskPrice, "ws.askPrice")
+
+	callback(&Ticker{
+		Symbol:    ticker.Symbol,
+		BidPrice:  bid,
+		AskPrice:  ask,
+		LastPrice: (bid + ask) / 2,
+		Timestamp: time.UnixMilli(ticker.Time),
+	})
+}
+
+// binanceDepthSync - буфер событий depthUpdate на время загрузки снимка стакана
+type binanceDepthSync struct {
+	mu       sync.Mutex
+	loading  bool
+	retryAt  time.Time
+	buffered []*binanceDepthEvent
+}
+
+// getDepthSync возвращает состояние синхронизации стакана символа
+func (b *Binance) getDepthSync(symbol string) *binanceDepthSync {
+	b.depthMu.Lock()
+	defer b.depthMu.Unlock()
+
+	ds, ok := b.depthSync[symbol]
+	if !ok {
+		ds = &binanceDepthSync{}
+		b.depthSync[symbol] = ds
+	}
+	return ds
+}
+
+// handleDepthUpdate применяет событие к локальному стакану
+// Несинхронизированный стакан копит события и запускает загрузку снимка
+func (b *Binance) handleDepthUpdate(event *binanceDepthEvent) {
+	sub := b.books.get(event.Symbol)
+	if sub == nil {
+		return
+	}
+
+	ds := b.getDepthSync(event.Symbol)
+	ds.mu.Lock()
+
+	if ds.loading || !sub.book.Synced() {
+		ds.buffered = append(ds.buffered, event)
+		if len(ds.buffered) > binanceDepthBufferSize {
+			ds.buffered = ds.buffered[len(ds.buffered)-binanceDepthBufferSize:]
+		}
+
+		start := !ds.loading && !time.Now().Before(ds.retryAt)
+		if start {
+			ds.loading = true
+		}
+		ds.mu.Unlock()
+
+		if start {
+			go b.syncOrderBook(sub, ds)
+		}
+		return
+	}
+
+	err := b.applyDepthEvent(sub.book, event)
+	ds.mu.Unlock()
+
+	if err != nil {
+		if err != ErrOrderBookNotSynced {
+			log.Printf("[binance] %s depth: %v, loading new snapshot", event.Symbol, err)
+		}
+		return
+	}
+
+	sub.emit()
+}
+
+// syncOrderBook загружает REST снимок и применяет накопленные события
+func (b *Binance) syncOrderBook(sub *orderBookSubscription, ds *binanceDepthSync) {
+	symbol := sub.book.symbol
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	snapshot, err := b.getDepth(ctx, symbol, binanceSnapshotDepth)
+	cancel()
+
+	ds.mu.Lock()
+	defer ds.mu.Unlock()
+	ds.loading = false
+
+	if err != nil {
+		log.Printf("[binance] %s depth snapshot: %v", symbol, err)
+		ds.retryAt = time.Now().Add(binanceSnapshotRetry)
+		return
+	}
+
+	sub.book.Reset(stringBookLevels(snapshot.Bids), stringBookLevels(snapshot.Asks),
+		snapshot.LastUpdateID, time.UnixMilli(snapshot.Time))
+
+	buffered := ds.buffered
+	ds.buffered = nil
+	for _, event := range buffered {
+		if err := b.applyDepthEvent(sub.book, event); err != nil {
+			log.Printf("[binance] %s depth: %v after snapshot %d", symbol, err, snapshot.LastUpdateID)
+			return
+		}
+	}
+
+	sub.emit()
+}
+
+// applyDepthEvent применяет событие к стакану
+//
+// События, целиком вошедшие в снимок (u <= lastUpdateId), пропускаются. Первое
+// событие после снимка содержит lastUpdateId внутри [U, u] и применяется без
+// проверки pu; все последующие должны ссылаться на предыдущее событие.
+func (b *Binance) applyDepthEvent(book *LocalOrderBook, event *binanceDepthEvent) error {
+	seq := book.Seq()
+	if event.FinalID <= seq {
+		return nil
+	}
+
+	prevID := event.PrevID
+	if event.FirstID <= seq {
+		prevID = -1
+	}
+
+	return book.Apply(stringBookLevels(event.Bids), stringBookLevels(event.Asks),
+		prevID, event.FinalID, time.UnixMilli(event.Time))
+}
+
+// SubscribePositions подписывается на приватный поток пользователя (user data stream)
+// Поток адресуется listenKey: ключ получается перед каждым подключением,
+// продлевается по таймеру и пересоздаётся при истечении
+func (b *Binance) SubscribePositions(callback func(*Position)) error {
+	b.callbackMu.Lock()
+	b.positionCallback = callback
+	b.callbackMu.Unlock()
+
+	return b.subscribePrivate()
+}
+
+// SubscribeOrders подписывается на обновления ордеров (ORDER_TRADE_UPDATE user data stream)
+func (b *Binance) SubscribeOrders(callback func(*Order)) error {
+	b.callbackMu.Lock()
+	b.orderCallback = callback
+	b.callbackMu.Unlock()
+
+	return b.subscribePrivate()
+}
+
+// subscribePrivate подключается к user data stream
+// Отдельные подписки не нужны - поток присылает все события аккаунта
+func (b *Binance) subscribePrivate() error {
+	b.wsMu.Lock()
+	defer b.wsMu.Unlock()
+
+	if b.wsPrivateManager != nil {
+		return nil
+	}
+
+	config := DefaultWSReconnectConfig()
+	wsManager := NewWSReconnectManager("binance-private", b.endpoints.WSPrivate, config)
+	b.health.TrackWS(wsManager)
+
+	wsManager.SetURLFunc(b.privateWSURL)
+	wsManager.SetOnMessage(b.handlePrivateMessage)
+	wsManager.SetOnConnect(func() {
+		log.Printf("[binance] Private WebSocket connected")
+	})
+	wsManager.SetOnDisconnect(func(err error) {
+		if err != nil {
+			log.Printf("[binance] Private WebSocket disconnected: %v", err)
+		}
+	})
+
+	if err := wsManager.Connect(); err != nil {
+		return fmt.Errorf("failed to connect to private WebSocket: %w", err)
+	}
+
+	b.wsPrivateManager = wsManager
+	go b.keepAliveListenKey()
+
+	return nil
+}
+
+// privateWSURL возвращает адрес приватного потока, при необходимости получая новый listenKey
+// Запрос listenKey подписывается только API ключом в заголовке
+func (b *Binance) privateWSURL() (string, error) {
+	b.listenKeyMu.Lock()
+	defer b.listenKeyMu.Unlock()
+
+	if b.listenKey == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		body, err := b.doRequest(ctx, http.MethodPost, binanceListenKeyEndpoint, nil, false)
+		if err != nil {
+			return "", fmt.Errorf("listen key: %w", err)
+		}
+
+		var resp struct {
+			ListenKey string `json:"listenKey"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return "", err
+		}
+		if resp.ListenKey == "" {
+			return "", fmt.Errorf("listen key: empty response")
+		}
+		b.listenKey = resp.ListenKey
+	}
+
+	return b.endpoints.WSPrivate + "/" + url.PathEscape(b.listenKey), nil
+}
+
+// getListenKey возвращает текущий listenKey (пустой, если не получен)
+func (b *Binance) getListenKey() string {
+	b.listenKeyMu.Lock()
+	defer b.listenKeyMu.Unlock()
+	return b.listenKey
+}
+
+// resetListenKey сбрасывает listenKey и переподключает приватный поток с новым ключом
+func (b *Binance) resetListenKey() {
+	b.listenKeyMu.Lock()
+	b.listenKey = ""
+	b.listenKeyMu.Unlock()
+
+	b.wsMu.Lock()
+	wsManager := b.wsPrivateManager
+	b.wsMu.Unlock()
+
+	if wsManager != nil {
+		wsManager.Reconnect()
+	}
+}
+
+// keepAliveListenKey продлевает listenKey, пока биржа не закрыта
+// Если продлить не удалось, ключ считается истёкшим и пересоздаётся
+func (b *Binance) keepAliveListenKey() {
+	ticker := time.NewTicker(binanceListenKeyKeepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.closeChan:
+			return
+		case <-ticker.C:
+		}
+
+		if b.getListenKey() == "" {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		_, err := b.doRequest(ctx, http.MethodPut, binanceListenKeyEndpoint, nil, false)
+		cancel()
+
+		if err != nil {
+			log.Printf("[binance] Failed to extend listen key, recreating: %v", err)
+			b.resetListenKey()
+		}
+	}
+}
+
+// binancePrivateEvent - событие приватного потока Binance
+// Парные по регистру поля (E, t, AP) объявлены, чтобы не затирать e, T и ap
+type binancePrivateEvent struct {
+	Event     string `json:"e"`
+	EventTime int64  `json:"E"`
+
+	// ACCOUNT_UPDATE
+	Account struct {
+		Reason    string `json:"m"`
+		Positions []struct {
+			Symbol        string `json:"s"`
+			Amount        string `json:"pa"`
+			EntryPrice    string `json:"ep"`
+			UnrealizedPnl string `json:"up"`
+			PositionSide  string `json:"ps"`
+		} `json:"P"`
+	} `json:"a"`
+
+	// ORDER_TRADE_UPDATE
+	Order struct {
+		Symbol          string `json:"s"`
+		ClientOrderID   string `json:"c"`
+		Side            string `json:"S"`
+		Type            string `json:"o"`
+		TimeInForce     string `json:"f"`
+		Quantity        string `json:"q"`
+		Price           string `json:"p"`
+		AvgPrice        string `json:"ap"`
+		ExecutionType   string `json:"x"`
+		Status          string `json:"X"`
+		OrderID         int64  `json:"i"`
+		FilledQty       string `json:"z"`
+		CommissionAsset string `json:"N"`
+		Commission      string `json:"n"` // комиссия последней сделки, не накопленная
+		TradeTime       int64  `json:"T"`
+		TradeID         int64  `json:"t"`
+		ActivationPrice string `json:"AP"`
+		PositionSide    string `json:"ps"`
+	} `json:"o"`
+}
+
+// handlePrivateMessage обрабатывает сообщение приватного потока
+func (b *Binance) handlePrivateMessage(message []byte) {
+	var event binancePrivateEvent
+	if err := json.Unmarshal(message, &event); err != nil {
+		return
+	}
+
+	switch event.Event {
+	case "listenKeyExpired":
+		log.Printf("[binance] Listen key expired, recreating")
+		b.resetListenKey()
+
+	case "ACCOUNT_UPDATE":
+		b.handleAccountUpdate(&event)
+
+	case "ORDER_TRADE_UPDATE":
+		b.handleOrderTradeUpdate(&event)
+	}
+}
+
+// handleAccountUpdate отправляет изменения позиций в callback
+// Причина LIQUIDATION означает, что позиции изменены принудительной ликвидацией
+func (b *Binance) handleAccountUpdate(event *binancePrivateEvent) {
+	b.callbackMu.RLock()
+	callback := b.positionCallback
+	b.callbackMu.RUnlock()
+
+	if callback == nil {
+		return
+	}
+
+	liquidation := event.Account.Reason == "LIQUIDATION"
+
+	for _, p := range event.Account.Positions {
+		amount := b.parseFloat(p.Amount, "ws.positionAmt")
+
+		side := SideLong
+		if p.PositionSide == "SHORT" || (p.PositionSide == "BOTH" && amount < 0) {
+			side = SideShort
+		}
+
+		callback(&Position{
+			Symbol:        p.Symbol,
+			Side:          side,
+			Size:          math.Abs(amount),
+			EntryPrice:    b.parseFloat(p.EntryPrice, "ws.entryPrice"),
+			UnrealizedPnl: b.parseFloat(p.UnrealizedPnl, "ws.unrealizedPnl"),
+			Liquidation:   liquidation,
+			UpdatedAt:     time.Now(),
+		})
+	}
+}
+
+// handleOrderTradeUpdate отправляет обновление ордера в callback и сообщает о ликвидации
+// по исполнению ордера принудительной ликвидации. Ордер ликвидации закрывает позицию:
+// SELL - лонг, BUY - шорт
+func (b *Binance) handleOrderTradeUpdate(event *binancePrivateEvent) {
+	o := &event.Order
+
+	b.callbackMu.RLock()
+	orderCallback := b.orderCallback
+	positionCallback := b.positionCallback
+	b.callbackMu.RUnlock()
+
+	if orderCallback != nil && o.OrderID != 0 {
+		order := b.parseOrder(binanceOrderInfo{
+			OrderID:       o.OrderID,
+			ClientOrderID: o.ClientOrderID,
+			Symbol:        o.Symbol,
+			Side:          o.Side,
+			Type:          o.Type,
+			Price:         o.Price,
+			OrigQty:       o.Quantity,
+			ExecutedQty:   o.FilledQty,
+			AvgPrice:      o.AvgPrice,
+			Status:        o.Status,
+			TimeInForce:   o.TimeInForce,
+			Time:          o.TradeTime,
+			UpdateTime:    o.TradeTime,
+		})
+		order.Fee = b.accumulateFee(order, o.ExecutionType, o.CommissionAsset, o.Commission)
+		orderCallback(order)
+	}
+
+	liquidation := o.Type == "LIQUIDATION" || o.ExecutionType == "CALCULATED" ||
+		strings.HasPrefix(o.ClientOrderID, "autoclose-")
+	if !liquidation || positionCallback == nil {
+		return
+	}
+
+	side := SideShort
+	if o.PositionSide == "LONG" || (o.PositionSide != "SHORT" && o.Side == "SELL") {
+		side = SideLong
+	}
+
+	positionCallback(&Position{
+		Symbol:      o.Symbol,
+		Side:        side,
+		Size:        b.parseFloat(o.FilledQty, "ws.filledQty"),
+		MarkPrice:   b.parseFloat(o.AvgPrice, "ws.avgPrice"),
+		Liquidation: true,
+		UpdatedAt:   time.Now(),
+	})
+}
+
+// accumulateFee возвращает накопленную комиссию ордера в USDT
+// Событие TRADE несёт комиссию одной сделки; комиссия в другой валюте (BNB) не учитывается
+func (b *Binance) accumulateFee(order *Order, executionType, asset, commission string) float64 {
+	b.orderFeesMu.Lock()
+	defer b.orderFeesMu.Unlock()
+
+	fee := b.orderFees[order.ID]
+	if executionType == "TRADE" && asset == "USDT" {
+		fee += b.parseFloat(commission, "ws.commission")
+	}
+
+	if order.Status == OrderStatusNew || order.Status == OrderStatusPartial {
+		b.orderFees[order.ID] = fee
+	} else {
+		delete(b.orderFees, order.ID)
+	}
+	return fee
+}
+
+func (b *Binance) GetTradingFee(ctx context.Context, symbol string) (float64, error) {
+	fees, err := b.GetTradingFees(ctx, symbol)
+	if err != nil {
+		return 0.0005, nil // 0.05% стандартная комиссия тейкера
+	}
+	return fees.Taker, nil
+}
+
+// GetTradingFees получает комиссии аккаунта из /fapi/v1/commissionRate
+// Ставки уже учитывают VIP-уровень и скидку за BNB
+func (b *Binance) GetTradingFees(ctx context.Context, symbol string) (*TradingFees, error) {
+	params := map[string]string{
+		"symbol": symbol,
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/fapi/v1/commissionRate", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Symbol              string `json:"symbol"`
+		MakerCommissionRate string `json:"makerCommissionRate"`
+		TakerCommissionRate string `json:"takerCommissionRate"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Symbol == "" {
+		return nil, fmt.Errorf("commission rate not found for %s", symbol)
+	}
+
+	return &TradingFees{
+		Symbol:    symbol,
+		Maker:     b.parseFloat(resp.MakerCommissionRate, "makerCommissionRate"),
+		Taker:     b.parseFloat(resp.TakerCommissionRate, "takerCommissionRate"),
+		Tier:      b.feeTier(ctx),
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// feeTier возвращает VIP-уровень аккаунта ("" если не удалось получить)
+// Уровень информационный: ставки commissionRate его уже учитывают
+func (b *Binance) feeTier(ctx context.Context) string {
+	body, err := b.doRequest(ctx, http.MethodGet, "/fapi/v2/account", nil, true)
+	if err != nil {
+		return ""
+	}
+
+	var resp struct {
+		FeeTier *int `json:"feeTier"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.FeeTier == nil {
+		return ""
+	}
+	return strconv.Itoa(*resp.FeeTier)
+}
+
+func (b *Binance) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
+	inst, err := b.GetInstrument(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return inst.Limits(), nil
+}
+
+func (b *Binance) GetInstrument(ctx context.Context, symbol string) (*Instrument, error) {
+	return b.instruments.Get(ctx, symbol)
+}
+
+// binanceInstrumentStatus - соответствие status контракта Binance статусам Instrument
+var binanceInstrumentStatus = map[string]string{
+	"TRADING":         InstrumentStatusTrading,
+	"PENDING_TRADING": InstrumentStatusSuspended,
+	"PRE_TRADING":     InstrumentStatusSuspended,
+	"PRE_SETTLE":      InstrumentStatusSuspended,
+	"SETTLING":        InstrumentStatusSuspended,
+}
+
+// loadInstruments загружает спецификации всех USDT бессрочных контрактов из exchangeInfo
+// Лимиты заданы фильтрами LOT_SIZE, PRICE_FILTER и MIN_NOTIONAL
+func (b *Binance) loadInstruments(ctx context.Context) ([]*Instrument, error) {
+	body, err := b.doRequest(ctx, http.MethodGet, "/fapi/v1/exchangeInfo", nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Symbols []struct {
+			Symbol       string `json:"symbol"`
+			ContractType string `json:"contractType"`
+			Status       string `json:"status"`
+			MarginAsset  string `json:"marginAsset"`
+			Filters      []struct {
+				FilterType string `json:"filterType"`
+				TickSize   string `json:"tickSize"`
+				StepSize   string `json:"stepSize"`
+				MinQty     string `json:"minQty"`
+				MaxQty     string `json:"maxQty"`
+				Notional   string `json:"notional"`
+			} `json:"filters"`
+		} `json:"symbols"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	instruments := make([]*Instrument, 0, len(resp.Symbols))
+	for _, info := range resp.Symbols {
+		if info.ContractType != "PERPETUAL" || info.MarginAsset != "USDT" {
+			continue
+		}
+
+		status, ok := binanceInstrumentStatus[info.Status]
+		if !ok {
+			status = InstrumentStatusDelisted
+		}
+
+		inst := &Instrument{
+			Symbol:         info.Symbol,
+			VenueSymbol:    info.Symbol,
+			ContractValue:  1,
+			MinNotional:    5.0,
+			MaxLeverage:    binanceMaxLeverage,
+			SettleCurrency: info.MarginAsset,
+			Status:         status,
+		}
+
+		for _, f := range info.Filters {
+			switch f.FilterType {
+			case "LOT_SIZE":
+				inst.LotSize = b.parseFloat(f.StepSize, "instrument.stepSize")
+				inst.MinSize = b.parseFloat(f.MinQty, "instrument.minQty")
+				inst.MaxSize = b.parseFloat(f.MaxQty, "instrument.maxQty")
+			case "PRICE_FILTER":
+				inst.TickSize = b.parseFloat(f.TickSize, "instrument.tickSize")
+			case "MIN_NOTIONAL":
+				inst.MinNotional = b.parseFloat(f.Notional, "instrument.notional")
+			}
+		}
+
+		instruments = append(instruments, inst)
+	}
+
+	return instruments, nil
+}
+
+func (b *Binance) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	params := map[string]string{
+		"symbol": symbol,
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/fapi/v1/premiumIndex", params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Symbol          string `json:"symbol"`
+		LastFundingRate string `json:"lastFundingRate"`
+		NextFundingTime int64  `json:"nextFundingTime"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Symbol == "" {
+		return nil, fmt.Errorf("funding rate not found for %s", symbol)
+	}
+
+	// lastFundingRate у Binance - текущая ставка, которая будет начислена в nextFundingTime
+	return &FundingRate{
+		Symbol:          symbol,
+		Rate:            b.parseFloat(resp.LastFundingRate, "lastFundingRate"),
+		NextFundingTime: time.UnixMilli(resp.NextFundingTime),
+		Interval:        DefaultFundingInterval,
+		Timestamp:       time.Now(),
+	}, nil
+}
+
+// SetLeverage устанавливает плечо символа (одно для лонга и шорта)
+func (b *Binance) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if err := validateLeverage(leverage); err != nil {
+		return err
+	}
+
+	params := map[string]string{
+		"symbol":   symbol,
+		"leverage": strconv.Itoa(leverage),
+	}
+
+	_, err := b.doRequest(ctx, http.MethodPost, "/fapi/v1/leverage", params, true)
+	return err
+}
+
+// SetMarginMode устанавливает режим маржи символа (ISOLATED / CROSSED)
+func (b *Binance) SetMarginMode(ctx context.Context, symbol, mode string) error {
+	if err := validateMarginMode(mode); err != nil {
+		return err
+	}
+
+	marginType := "CROSSED"
+	if mode == MarginModeIsolated {
+		marginType = "ISOLATED"
+	}
+
+	params := map[string]string{
+		"symbol":     symbol,
+		"marginType": marginType,
+	}
+
+	_, err := b.doRequest(ctx, http.MethodPost, "/fapi/v1/marginType", params, true)
+	// -4046 - режим уже установлен
+	if isExchangeErrorCode(err, "-4046") {
+		return nil
+	}
+	return err
+}
+
+// GetMarginSettings читает плечо и режим маржи символа из /fapi/v2/positionRisk
+func (b *Binance) GetMarginSettings(ctx context.Context, symbol string) (int, string, error) {
+	params := map[string]string{
+		"symbol": symbol,
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/fapi/v2/positionRisk", params, true)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var resp []struct {
+		Symbol     string `json:"symbol"`
+		Leverage   string `json:"leverage"`
+		MarginType string `json:"marginType"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, "", err
+	}
+
+	var leverages []int
+	mode := ""
+	for _, p := range resp {
+		if p.Symbol != symbol {
+			continue
+		}
+		leverage, _ := strconv.Atoi(p.Leverage)
+		leverages = append(leverages, leverage)
+
+		mode = MarginModeCross
+		if p.MarginType == "isolated" {
+			mode = MarginModeIsolated
+		}
+	}
+
+	leverage, err := commonLeverage(symbol, leverages...)
+	if err != nil {
+		return 0, "", err
+	}
+	return leverage, mode, nil
+}
+
+// GetFundingPayments читает начисления фандинга символа из /fapi/v1/income (FUNDING_FEE)
+func (b *Binance) GetFundingPayments(ctx context.Context, symbol string, from, to time.Time) ([]FundingPayment, error) {
+	params := map[string]string{
+		"symbol":     symbol,
+		"incomeType": "FUNDING_FEE",
+		"startTime":  strconv.FormatInt(from.UnixMilli(), 10),
+		"endTime":    strconv.FormatInt(to.UnixMilli(), 10),
+		"limit":      "1000",
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/fapi/v1/income", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []struct {
+		Symbol     string `json:"symbol"`
+		IncomeType string `json:"incomeType"`
+		Income     string `json:"income"`
+		Time       int64  `json:"time"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	payments := make([]FundingPayment, 0, len(resp))
+	for _, r := range resp {
+		if r.Symbol != symbol || r.IncomeType != "FUNDING_FEE" {
+			continue
+		}
+		payments = append(payments, FundingPayment{
+			Symbol: symbol,
+			Amount: b.parseFloat(r.Income, "income.income"),
+			Time:   time.UnixMilli(r.Time),
+		})
+	}
+	return fundingPaymentsBetween(payments, from, to), nil
+}
+
+func (b *Binance) Close() error {
+	select {
+	case <-b.closeChan:
+	default:
+		close(b.closeChan)
+	}
+
+	b.wsMu.Lock()
+	if b.wsManager != nil {
+		b.wsManager.Close()
+		b.wsManager = nil
+	}
+	privateManager := b.wsPrivateManager
+	b.wsPrivateManager = nil
+	b.wsMu.Unlock()
+
+	if privateManager != nil {
+		privateManager.Close()
+
+		// Ключ больше не нужен - закрываем поток на бирже
+		if b.getListenKey() != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			b.doRequest(ctx, http.MethodDelete, binanceListenKeyEndpoint, nil, false)
+			cancel()
+		}
+	}
+
+	b.connected = false
+	return nil
+}
@@ -0,0 +1,609 @@
+package exchange
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	binanceTestAPIKey = "test-api-key"
+	binanceTestSecret = "test-secret"
+)
+
+// binanceFixtures - ответы REST API Binance, записанные в testdata/binance
+var binanceFixtures = map[string]string{
+	"GET /fapi/v2/balance":           "balance.json",
+	"GET /fapi/v1/ticker/bookTicker": "book_ticker.json",
+	"GET /fapi/v1/ticker/price":      "ticker_price.json",
+	"GET /fapi/v1/exchangeInfo":      "exchange_info.json",
+	"GET /fapi/v1/openOrders":        "open_orders.json",
+	"GET /fapi/v1/order":             "order_limit.json",
+	"DELETE /fapi/v1/order":          "order_limit.json",
+	"GET /fapi/v2/positionRisk":      "position_risk.json",
+	"GET /fapi/v1/commissionRate":    "commission_rate.json",
+	"GET /fapi/v2/account":           "account.json",
+	"GET /fapi/v1/premiumIndex":      "premium_index.json",
+	"POST /fapi/v1/listenKey":        "listen_key.json",
+	"GET /fapi/v1/time":              "server_time.json",
+}
+
+// binanceSignedEndpoints - запросы, требующие подписи HMAC
+var binanceSignedEndpoints = map[string]bool{
+	"/fapi/v2/balance":        true,
+	"/fapi/v1/order":          true,
+	"/fapi/v1/openOrders":     true,
+	"/fapi/v2/positionRisk":   true,
+	"/fapi/v1/commissionRate": true,
+	"/fapi/v2/account":        true,
+	"/fapi/v1/leverage":       true,
+	"/fapi/v1/marginType":     true,
+}
+
+// binanceRequest - запрос, принятый тестовым сервером
+type binanceRequest struct {
+	Method string
+	Path   string
+	Params url.Values
+}
+
+// binanceTestServer - локальный Binance: REST ответы из fixtures, публичный и приватный WebSocket
+type binanceTestServer struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu         sync.Mutex
+	requests   []binanceRequest
+	depthCalls int
+
+	// depthGate держит ответ снимка, пока поток не отправит события из ws_depth_updates.json
+	depthGate     chan struct{}
+	depthGateOnce sync.Once
+	// gapGate запускает отправку события с разрывом последовательности
+	gapGate chan struct{}
+	// userGate запускает отправку событий приватного потока
+	userGate chan struct{}
+}
+
+func newBinanceTestServer(t *testing.T) *binanceTestServer {
+	s := &binanceTestServer{
+		t:         t,
+		depthGate: make(chan struct{}),
+		gapGate:   make(chan struct{}),
+		userGate:  make(chan struct{}),
+	}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// newBinanceForTest создаёт адаптер, направленный на тестовый сервер
+func newBinanceForTest(s *binanceTestServer) *Binance {
+	wsURL := "ws" + strings.TrimPrefix(s.server.URL, "http") + "/ws"
+	b := NewBinance()
+	b.SetEndpoints(Endpoints{REST: s.server.URL, WSPublic: wsURL, WSPrivate: wsURL})
+	return b
+}
+
+// loadBinanceFixture читает fixture; вызывается из горутин сервера, поэтому без Fatal
+func loadBinanceFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", "binance", name))
+	if err != nil {
+		t.Errorf("read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+func (s *binanceTestServer) handle(w http.ResponseWriter, r *http.Request) {
+	if strings.HasPrefix(r.URL.Path, "/ws") {
+		s.handleWS(w, r)
+		return
+	}
+
+	raw := r.URL.RawQuery
+	if r.Method == http.MethodPost || r.Method == http.MethodPut {
+		body, _ := io.ReadAll(r.Body)
+		raw = string(body)
+	}
+	params, err := url.ParseQuery(raw)
+	if err != nil {
+		s.t.Errorf("%s %s: bad params %q", r.Method, r.URL.Path, raw)
+	}
+
+	s.verifySignature(r, raw, params)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, binanceRequest{Method: r.Method, Path: r.URL.Path, Params: params})
+	s.mu.Unlock()
+
+	switch {
+	case r.URL.Path == "/fapi/v1/depth":
+		s.handleDepth(w, params)
+		return
+	case r.Method == http.MethodPost && r.URL.Path == "/fapi/v1/order":
+		switch {
+		case params.Get("symbol") == "ETHUSDT":
+			w.WriteHeader(http.StatusBadRequest)
+			w.Write(loadBinanceFixture(s.t, "error_insufficient_margin.json"))
+		case params.Get("type") == "LIMIT":
+			w.Write(loadBinanceFixture(s.t, "order_limit.json"))
+		default:
+			w.Write(loadBinanceFixture(s.t, "order_market.json"))
+		}
+		return
+	case r.URL.Path == "/fapi/v1/leverage":
+		w.Write([]byte(`{"leverage":10,"maxNotionalValue":"40000000","symbol":"BTCUSDT"}`))
+		return
+	case r.URL.Path == "/fapi/v1/marginType":
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"code":-4046,"msg":"No need to change margin type."}`))
+		return
+	case r.URL.Path == binanceListenKeyEndpoint && r.Method != http.MethodPost:
+		w.Write([]byte(`{}`))
+		return
+	}
+
+	fixture, ok := binanceFixtures[r.Method+" "+r.URL.Path]
+	if !ok {
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+	w.Write(loadBinanceFixture(s.t, fixture))
+}
+
+// verifySignature проверяет API ключ и подпись: HMAC SHA256 строки параметров до signature
+func (s *binanceTestServer) verifySignature(r *http.Request, raw string, params url.Values) {
+	signed := binanceSignedEndpoints[r.URL.Path]
+	if (signed || r.URL.Path == binanceListenKeyEndpoint) && r.Header.Get("X-MBX-APIKEY") != binanceTestAPIKey {
+		s.t.Errorf("%s %s: missing API key header", r.Method, r.URL.Path)
+	}
+
+	idx := strings.LastIndex(raw, "&signature=")
+	if idx < 0 {
+		if signed {
+			s.t.Errorf("%s %s: missing signature", r.Method, r.URL.Path)
+		}
+		return
+	}
+
+	h := hmac.New(sha256.New, []byte(binanceTestSecret))
+	h.Write([]byte(raw[:idx]))
+	if params.Get("signature") != hex.EncodeToString(h.Sum(nil)) {
+		s.t.Errorf("%s %s: invalid signature", r.Method, r.URL.Path)
+	}
+	if params.Get("timestamp") == "" || params.Get("recvWindow") != recvWindowMillis("binance") {
+		s.t.Errorf("%s %s: missing timestamp or recvWindow", r.Method, r.URL.Path)
+	}
+}
+
+// handleDepth отдаёт стакан; снимки для локального стакана (limit 1000) считаются,
+// повторный снимок (после разрыва) сдвинут на lastUpdateId 112
+func (s *binanceTestServer) handleDepth(w http.ResponseWriter, params url.Values) {
+	snapshot := loadBinanceFixture(s.t, "depth.json")
+	if params.Get("limit") != "1000" {
+		w.Write(snapshot)
+		return
+	}
+
+	s.mu.Lock()
+	s.depthCalls++
+	calls := s.depthCalls
+	s.mu.Unlock()
+
+	if calls == 1 {
+		select {
+		case <-s.depthGate:
+		case <-time.After(5 * time.Second):
+		}
+	} else {
+		snapshot = []byte(strings.Replace(string(snapshot), `"lastUpdateId": 100`, `"lastUpdateId": 112`, 1))
+	}
+	w.Write(snapshot)
+}
+
+// handleWS обслуживает публичный поток /ws и приватный /ws/<listenKey>
+func (s *binanceTestServer) handleWS(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var writeMu sync.Mutex
+	send := func(message []byte) {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		conn.WriteMessage(websocket.TextMessage, message)
+	}
+
+	if key := strings.TrimPrefix(r.URL.Path, "/ws/"); key != r.URL.Path {
+		var listenKey struct {
+			ListenKey string `json:"listenKey"`
+		}
+		json.Unmarshal(loadBinanceFixture(s.t, "listen_key.json"), &listenKey)
+		if key != listenKey.ListenKey {
+			s.t.Errorf("private stream opened with unexpected listen key %q", key)
+			return
+		}
+
+		go func() {
+			select {
+			case <-s.userGate:
+			case <-time.After(5 * time.Second):
+				return
+			}
+			for _, event := range s.fixtureEvents("ws_user_events.json") {
+				send(event)
+			}
+		}()
+	}
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var sub struct {
+			Method string   `json:"method"`
+			Params []string `json:"params"`
+			ID     int64    `json:"id"`
+		}
+		if err := json.Unmarshal(message, &sub); err != nil || sub.Method != "SUBSCRIBE" {
+			continue
+		}
+		ack, _ := json.Marshal(map[string]interface{}{"result": nil, "id": sub.ID})
+		send(ack)
+
+		for _, stream := range sub.Params {
+			switch stream {
+			case "btcusdt@bookTicker":
+				send(loadBinanceFixture(s.t, "ws_book_ticker.json"))
+			case "btcusdt@depth@100ms":
+				for _, event := range s.fixtureEvents("ws_depth_updates.json") {
+					send(event)
+				}
+				s.depthGateOnce.Do(func() { close(s.depthGate) })
+
+				go func() {
+					select {
+					case <-s.gapGate:
+					case <-time.After(5 * time.Second):
+						return
+					}
+					send([]byte(`{"e":"depthUpdate","E":1760601600550,"T":1760601600548,"s":"BTCUSDT","U":110,"u":112,"pu":109,"b":[],"a":[]}`))
+					send([]byte(`{"e":"depthUpdate","E":1760601600650,"T":1760601600648,"s":"BTCUSDT","U":113,"u":115,"pu":112,"b":[["64010.40","0.300"]],"a":[]}`))
+				}()
+			}
+		}
+	}
+}
+
+// fixtureEvents читает fixture с массивом сообщений WebSocket
+func (s *binanceTestServer) fixtureEvents(name string) []json.RawMessage {
+	var events []json.RawMessage
+	if err := json.Unmarshal(loadBinanceFixture(s.t, name), &events); err != nil {
+		s.t.Errorf("parse fixture %s: %v", name, err)
+	}
+	return events
+}
+
+// lastRequest возвращает последний запрос к endpoint
+func (s *binanceTestServer) lastRequest(method, path string) *binanceRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := len(s.requests) - 1; i >= 0; i-- {
+		if s.requests[i].Method == method && s.requests[i].Path == path {
+			req := s.requests[i]
+			return &req
+		}
+	}
+	return nil
+}
+
+// TestBinance_REST проверяет подпись запросов и разбор ответов REST API
+func TestBinance_REST(t *testing.T) {
+	srv := newBinanceTestServer(t)
+	b := newBinanceForTest(srv)
+	ctx := context.Background()
+
+	// Подключение проверяется запросом баланса: equity = баланс + нереализованный PnL
+	if err := b.Connect(binanceTestAPIKey, binanceTestSecret, ""); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	balance, err := b.GetBalance(ctx)
+	if err != nil || !almostEqual(balance, 12463.25) {
+		t.Fatalf("expected balance 12463.25, got %v (%v)", balance, err)
+	}
+
+	ticker, err := b.GetTicker(ctx, "BTCUSDT")
+	if err != nil {
+		t.Fatalf("GetTicker: %v", err)
+	}
+	if ticker.BidPrice != 64010.10 || ticker.AskPrice != 64010.20 || ticker.LastPrice != 64010.20 {
+		t.Fatalf("unexpected ticker: %+v", ticker)
+	}
+
+	book, err := b.GetOrderBook(ctx, "BTCUSDT", 2)
+	if err != nil {
+		t.Fatalf("GetOrderBook: %v", err)
+	}
+	if len(book.Bids) != 2 || book.Bids[0].Price != 64010.10 || book.Bids[1].Price != 64010.00 ||
+		len(book.Asks) != 2 || book.Asks[0].Price != 64010.20 || book.Asks[1].Price != 64010.30 {
+		t.Fatalf("expected sorted book truncated to 2 levels, got %+v", book)
+	}
+	if req := srv.lastRequest(http.MethodGet, "/fapi/v1/depth"); req == nil || req.Params.Get("limit") != "5" {
+		t.Fatalf("expected depth limit 5, got %+v", req)
+	}
+
+	// Спецификации: только USDT бессрочные контракты, лимиты из фильтров
+	limits, err := b.GetLimits(ctx, "BTCUSDT")
+	if err != nil {
+		t.Fatalf("GetLimits: %v", err)
+	}
+	if limits.MinOrderQty != 0.001 || limits.QtyStep != 0.001 || limits.MaxOrderQty != 1000 ||
+		limits.MinNotional != 100 || limits.PriceStep != 0.1 || limits.MaxLeverage != binanceMaxLeverage {
+		t.Fatalf("unexpected limits: %+v", limits)
+	}
+	for _, symbol := range []string{"BTCUSDT_251226", "BTCUSDC"} {
+		if _, err := b.GetInstrument(ctx, symbol); err == nil {
+			t.Fatalf("expected %s to be skipped", symbol)
+		}
+	}
+	if inst, err := b.GetInstrument(ctx, "ALPACAUSDT"); err != nil || inst.Status != InstrumentStatusSuspended {
+		t.Fatalf("expected settling contract to be suspended, got %+v (%v)", inst, err)
+	}
+
+	// Рыночный ордер: объём округляется вниз до шага лота
+	order, err := b.PlaceMarketOrder(ctx, "BTCUSDT", SideShort, 0.0125, "")
+	if err != nil {
+		t.Fatalf("PlaceMarketOrder: %v", err)
+	}
+	req := srv.lastRequest(http.MethodPost, "/fapi/v1/order")
+	if req.Params.Get("side") != "SELL" || req.Params.Get("type") != "MARKET" ||
+		req.Params.Get("quantity") != "0.012" || req.Params.Get("reduceOnly") != "" {
+		t.Fatalf("unexpected market order params: %v", req.Params)
+	}
+	if order.ID != "4079512880" || order.Status != OrderStatusFilled || order.Side != SideShort ||
+		order.FilledQty != 0.012 || order.AvgFillPrice != 64012.30 {
+		t.Fatalf("unexpected market order: %+v", order)
+	}
+
+	if err := b.ClosePosition(ctx, "BTCUSDT", SideShort, 0.012); err != nil {
+		t.Fatalf("ClosePosition: %v", err)
+	}
+	req = srv.lastRequest(http.MethodPost, "/fapi/v1/order")
+	if req.Params.Get("side") != "BUY" || req.Params.Get("reduceOnly") != "true" {
+		t.Fatalf("expected reduce-only buy to close short, got %v", req.Params)
+	}
+
+	// Ошибка биржи: код и сообщение из тела ответа
+	_, err = b.PlaceMarketOrder(ctx, "ETHUSDT", SideBuy, 1, "")
+	var exchErr *ExchangeError
+	if !errors.As(err, &exchErr) || exchErr.Code != "-2019" || exchErr.Message != "Margin is insufficient." {
+		t.Fatalf("expected -2019 exchange error, got %v", err)
+	}
+
+	// Post-only лимитный ордер передаётся как GTX
+	limit, err := b.PlaceLimitOrder(ctx, "BTCUSDT", SideBuy, 0.02, 63900, TimeInForcePostOnly)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder: %v", err)
+	}
+	req = srv.lastRequest(http.MethodPost, "/fapi/v1/order")
+	if req.Params.Get("timeInForce") != "GTX" || req.Params.Get("price") != "63900" || req.Params.Get("quantity") != "0.020" {
+		t.Fatalf("unexpected limit order params: %v", req.Params)
+	}
+	if limit.Status != OrderStatusNew || limit.TimeInForce != TimeInForcePostOnly || limit.Price != 63900 {
+		t.Fatalf("unexpected limit order: %+v", limit)
+	}
+
+	open, err := b.GetOpenOrders(ctx, "BTCUSDT")
+	if err != nil || len(open) != 1 || open[0].Status != OrderStatusPartial || open[0].FilledQty != 0.005 {
+		t.Fatalf("unexpected open orders: %+v (%v)", open, err)
+	}
+	if err := b.CancelOrder(ctx, "BTCUSDT", limit.ID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	if req := srv.lastRequest(http.MethodDelete, "/fapi/v1/order"); req.Params.Get("orderId") != "4079513104" {
+		t.Fatalf("unexpected cancel params: %v", req.Params)
+	}
+
+	// Позиции: нулевые пропускаются, отрицательный объём - шорт
+	positions, err := b.GetOpenPositions(ctx)
+	if err != nil {
+		t.Fatalf("GetOpenPositions: %v", err)
+	}
+	if len(positions) != 1 || positions[0].Side != SideShort || positions[0].Size != 0.012 ||
+		positions[0].Leverage != 10 || positions[0].EntryPrice != 64012.3 {
+		t.Fatalf("unexpected positions: %+v", positions)
+	}
+
+	fees, err := b.GetTradingFees(ctx, "BTCUSDT")
+	if err != nil {
+		t.Fatalf("GetTradingFees: %v", err)
+	}
+	if fees.Taker != 0.00045 || fees.Maker != 0.00018 || fees.Tier != "1" {
+		t.Fatalf("unexpected fees: %+v", fees)
+	}
+
+	funding, err := b.GetFundingRate(ctx, "BTCUSDT")
+	if err != nil || funding.Rate != 0.0001 || !funding.NextFundingTime.Equal(time.UnixMilli(1760616000000)) {
+		t.Fatalf("unexpected funding: %+v (%v)", funding, err)
+	}
+
+	if err := b.SetLeverage(ctx, "BTCUSDT", 10); err != nil {
+		t.Fatalf("SetLeverage: %v", err)
+	}
+	// -4046: режим маржи уже установлен - не ошибка
+	if err := b.SetMarginMode(ctx, "BTCUSDT", MarginModeCross); err != nil {
+		t.Fatalf("SetMarginMode: %v", err)
+	}
+	if req := srv.lastRequest(http.MethodPost, "/fapi/v1/marginType"); req.Params.Get("marginType") != "CROSSED" {
+		t.Fatalf("unexpected margin type params: %v", req.Params)
+	}
+}
+
+// TestBinance_PublicStreams проверяет поток bookTicker и синхронизацию стакана по снимку и дельтам
+func TestBinance_PublicStreams(t *testing.T) {
+	srv := newBinanceTestServer(t)
+	b := newBinanceForTest(srv)
+	defer b.Close()
+
+	tickers := make(chan *Ticker, 4)
+	if err := b.SubscribeTicker("BTCUSDT", func(ticker *Ticker) { tickers <- ticker }); err != nil {
+		t.Fatalf("SubscribeTicker: %v", err)
+	}
+
+	select {
+	case ticker := <-tickers:
+		if ticker.BidPrice != 64011.50 || ticker.AskPrice != 64011.60 || !almostEqual(ticker.LastPrice, 64011.55) {
+			t.Fatalf("unexpected ticker: %+v", ticker)
+		}
+		if !ticker.Timestamp.Equal(time.UnixMilli(1760601601005)) {
+			t.Fatalf("expected exchange timestamp, got %v", ticker.Timestamp)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for ticker")
+	}
+
+	books := make(chan *OrderBook, 16)
+	if err := b.SubscribeOrderBook("BTCUSDT", 5, func(book *OrderBook) { books <- book }); err != nil {
+		t.Fatalf("SubscribeOrderBook: %v", err)
+	}
+
+	// События до снимка копятся: u=99 уже в снимке, u=103 пересекает lastUpdateId, u=106 продолжает
+	var book *OrderBook
+	select {
+	case book = <-books:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for order book")
+	}
+
+	expectedBids := []PriceLevel{{Price: 64010.15, Volume: 0.75}, {Price: 64010.10, Volume: 2}, {Price: 64010.00, Volume: 1.2}}
+	expectedAsks := []PriceLevel{{Price: 64010.20, Volume: 1.5}, {Price: 64011.00, Volume: 5.1}}
+	if len(book.Bids) != len(expectedBids) || len(book.Asks) != len(expectedAsks) {
+		t.Fatalf("unexpected book: %+v", book)
+	}
+	for i, level := range expectedBids {
+		if book.Bids[i] != level {
+			t.Fatalf("bid %d: expected %+v, got %+v", i, level, book.Bids[i])
+		}
+	}
+	for i, level := range expectedAsks {
+		if book.Asks[i] != level {
+			t.Fatalf("ask %d: expected %+v, got %+v", i, level, book.Asks[i])
+		}
+	}
+
+	// Разрыв последовательности (pu != u предыдущего) - новый снимок и применение буфера
+	close(srv.gapGate)
+	select {
+	case book = <-books:
+	case <-time.After(5 * time.Second):
+		t.Fatal("timeout waiting for resynced order book")
+	}
+	if book.Bids[0].Price != 64010.40 || book.Bids[1].Price != 64010.10 || book.Bids[1].Volume != 3.512 {
+		t.Fatalf("expected book rebuilt from new snapshot, got %+v", book.Bids)
+	}
+
+	srv.mu.Lock()
+	depthCalls := srv.depthCalls
+	srv.mu.Unlock()
+	if depthCalls != 2 {
+		t.Fatalf("expected 2 depth snapshots, got %d", depthCalls)
+	}
+}
+
+// TestBinance_UserDataStream проверяет listenKey и события позиций и ордеров приватного потока
+func TestBinance_UserDataStream(t *testing.T) {
+	srv := newBinanceTestServer(t)
+	b := newBinanceForTest(srv)
+	b.apiKey = binanceTestAPIKey
+	b.secretKey = binanceTestSecret
+
+	positions := make(chan *Position, 8)
+	orders := make(chan *Order, 8)
+	if err := b.SubscribePositions(func(p *Position) { positions <- p }); err != nil {
+		t.Fatalf("SubscribePositions: %v", err)
+	}
+	if err := b.SubscribeOrders(func(o *Order) { orders <- o }); err != nil {
+		t.Fatalf("SubscribeOrders: %v", err)
+	}
+	close(srv.userGate)
+
+	nextOrder := func() *Order {
+		select {
+		case order := <-orders:
+			return order
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for order update")
+		}
+		return nil
+	}
+	nextPosition := func() *Position {
+		select {
+		case position := <-positions:
+			return position
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for position update")
+		}
+		return nil
+	}
+
+	// Комиссия событий TRADE накапливается по ордеру
+	order := nextOrder()
+	if order.ID != "4079513104" || order.Status != OrderStatusPartial || order.FilledQty != 0.005 || !almostEqual(order.Fee, 0.05751) {
+		t.Fatalf("unexpected partial fill: %+v", order)
+	}
+	order = nextOrder()
+	if order.Status != OrderStatusFilled || order.FilledQty != 0.02 || !almostEqual(order.Fee, 0.23004) {
+		t.Fatalf("unexpected fill: %+v", order)
+	}
+
+	position := nextPosition()
+	if position.Symbol != "BTCUSDT" || position.Side != SideLong || position.Size != 0.02 ||
+		position.EntryPrice != 63900 || position.Liquidation {
+		t.Fatalf("unexpected position: %+v", position)
+	}
+
+	// Ордер ликвидации закрывает лонг: и ордер, и позиция помечены
+	order = nextOrder()
+	if order.Status != OrderStatusFilled || !almostEqual(order.Fee, 0.575124) {
+		t.Fatalf("unexpected liquidation order: %+v", order)
+	}
+	position = nextPosition()
+	if !position.Liquidation || position.Side != SideLong || position.Size != 0.02 {
+		t.Fatalf("expected liquidation of long position, got %+v", position)
+	}
+	position = nextPosition()
+	if !position.Liquidation || position.Size != 0 {
+		t.Fatalf("expected LIQUIDATION account update, got %+v", position)
+	}
+
+	if req := srv.lastRequest(http.MethodPost, binanceListenKeyEndpoint); req == nil || req.Params.Get("signature") != "" {
+		t.Fatalf("expected unsigned listen key request, got %+v", req)
+	}
+
+	// Закрытие удаляет listenKey на бирже
+	b.Close()
+	if srv.lastRequest(http.MethodDelete, binanceListenKeyEndpoint) == nil {
+		t.Fatal("expected listen key to be deleted on close")
+	}
+}
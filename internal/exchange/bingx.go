@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"net/url"
 	"sort"
@@ -21,6 +22,10 @@ import (
 const (
 	bingxBaseURL = "https://open-api.bingx.com"
 	bingxWSURL   = "wss://open-api-swap.bingx.com/swap-market"
+
+	// listenKey действует 60 минут, продлеваем с запасом
+	bingxListenKeyEndpoint  = "/openApi/user/auth/userDataStream"
+	bingxListenKeyKeepAlive = 30 * time.Minute
 )
 
 type BingX struct {
@@ -28,15 +33,32 @@ type BingX struct {
 	secretKey string
 
 	httpClient *http.Client
+	endpoints  Endpoints       // адреса API, подменяются SetEndpoints
+	limiter    *requestLimiter // лимиты REST запросов
+	clock      *Clock          // смещение часов биржи для подписи запросов
+	health     *ExchangeHealth // состояние биржи для отключения от входов
 
 	// WebSocket manager с автоматическим переподключением
 	wsManager *WSReconnectManager
 	wsMu      sync.Mutex // защита инициализации WebSocket manager
 
+	// Приватный поток (user data stream) адресуется listenKey
+	wsPrivateManager *WSReconnectManager
+	listenKey        string
+	listenKeyMu      sync.Mutex
+	keepAlive        time.Duration // период продления listenKey
+
 	tickerCallbacks  map[string]func(*Ticker)
 	positionCallback func(*Position)
+	orderCallback    func(*Order)
 	callbackMu       sync.RWMutex
 
+	// Локальные стаканы SubscribeOrderBook
+	books orderBookSubscriptions
+
+	// Спецификации контрактов (размеры BingX в монетах)
+	instruments *InstrumentRegistry
+
 	connected bool
 	closeChan chan struct{}
 }
@@ -50,14 +72,73 @@ func (b *BingX) parseFloat(value, field string) float64 {
 	return result
 }
 
+// init регистрирует адаптер BingX в реестре бирж
+func init() {
+	Register(Adapter{
+		Name: "bingx",
+		New:  func() Exchange { return NewBingX() },
+		Capabilities: Capabilities{
+			PrivateWS:   true,
+			LimitOrders: true,
+			Funding:     true,
+			HedgeMode:   true,
+		},
+		TakerFee: 0.0005,
+		MakerFee: 0.0002,
+		// Ордера - 10 запросов в секунду на ключ, остальные группы - 100 за 10 секунд
+		RateLimits: RateLimits{
+			Total:   100,
+			Orders:  10,
+			Market:  10,
+			Account: 10,
+			Endpoints: map[string]EndpointLimit{
+				"/openApi/swap/v2/trade/order":      {Category: RateCategoryOrders},
+				"/openApi/swap/v2/trade/openOrders": {Category: RateCategoryOrders},
+				bingxListenKeyEndpoint:              {Category: RateCategoryAccount},
+			},
+			LimitCodes: []string{"100410"},
+		},
+	})
+}
+
 // NewBingX создаёт новый экземпляр BingX
 // Использует глобальный HTTP клиент с connection pooling и оптимизированными таймаутами
 func NewBingX() *BingX {
-	return &BingX{
+	b := &BingX{
 		httpClient:      GetGlobalHTTPClient().GetClient(),
+		endpoints:       Endpoints{REST: bingxBaseURL, WSPublic: bingxWSURL, WSPrivate: bingxWSURL},
+		limiter:         newRequestLimiter("bingx"),
+		health:          HealthOf("bingx"),
+		keepAlive:       bingxListenKeyKeepAlive,
 		tickerCallbacks: make(map[string]func(*Ticker)),
 		closeChan:       make(chan struct{}),
 	}
+	b.instruments = NewInstrumentRegistry("bingx", b.loadInstruments)
+	b.clock = NewClock("bingx", b.getServerTime, "100421")
+	return b
+}
+
+// SetEndpoints подменяет адреса REST и WebSocket API
+func (b *BingX) SetEndpoints(endpoints Endpoints) {
+	b.endpoints = endpoints
+}
+
+// getServerTime запрашивает время сервера для синхронизации часов
+func (b *BingX) getServerTime(ctx context.Context) (time.Time, error) {
+	body, err := b.doRequest(ctx, http.MethodGet, "/openApi/swap/v2/server/time", nil, false)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var resp struct {
+		Data struct {
+			ServerTime int64 `json:"serverTime"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(resp.Data.ServerTime), nil
 }
 
 // sign создает подпись для BingX API
@@ -67,9 +148,18 @@ func (b *BingX) sign(params string) string {
 	return hex.EncodeToString(h.Sum(nil))
 }
 
+// doRequest выполняет запрос к BingX API и учитывает его в состоянии биржи
 func (b *BingX) doRequest(ctx context.Context, method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
+	start := time.Now()
+	body, err := b.sendRequest(ctx, method, endpoint, params, signed)
+	b.health.ObserveRequest(time.Since(start), err)
+	return body, err
+}
+
+// sendRequest выполняет HTTP запрос к BingX API
+func (b *BingX) sendRequest(ctx context.Context, method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
 	var reqBody string
-	reqURL := bingxBaseURL + endpoint
+	reqURL := b.endpoints.REST + endpoint
 
 	query := url.Values{}
 	for k, v := range params {
@@ -77,8 +167,9 @@ func (b *BingX) doRequest(ctx context.Context, method, endpoint string, params m
 	}
 
 	if signed {
-		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		timestamp := strconv.FormatInt(b.clock.Now().UnixMilli(), 10)
 		query.Set("timestamp", timestamp)
+		query.Set("recvWindow", recvWindowMillis("bingx"))
 
 		// Сортируем и создаем строку для подписи
 		queryStr := query.Encode()
@@ -86,7 +177,8 @@ func (b *BingX) doRequest(ctx context.Context, method, endpoint string, params m
 		query.Set("signature", signature)
 	}
 
-	if method == http.MethodGet {
+	// GET и DELETE передают параметры в строке запроса, POST - в теле
+	if method == http.MethodGet || method == http.MethodDelete {
 		if len(query) > 0 {
 			reqURL += "?" + query.Encode()
 		}
@@ -104,11 +196,16 @@ func (b *BingX) doRequest(ctx context.Context, method, endpoint string, params m
 	}
 	req.Header.Set("X-BX-APIKEY", b.apiKey)
 
+	if err := b.limiter.Wait(ctx, endpoint, signed); err != nil {
+		return nil, err
+	}
+
 	resp, err := b.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	b.limiter.Observe(endpoint, signed, resp)
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -120,15 +217,18 @@ func (b *BingX) doRequest(ctx context.Context, method, endpoint string, params m
 		Msg  string `json:"msg"`
 	}
 	if err := json.Unmarshal(body, &baseResp); err != nil {
-		return nil, err
+		return nil, httpStatusError("bingx", resp.StatusCode, body, err)
 	}
 
 	if baseResp.Code != 0 {
-		return nil, &ExchangeError{
+		return nil, b.limiter.CheckError(b.clock.CheckError(&ExchangeError{
 			Exchange: "bingx",
 			Code:     strconv.Itoa(baseResp.Code),
 			Message:  baseResp.Msg,
-		}
+		}))
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, httpStatusError("bingx", resp.StatusCode, body, nil)
 	}
 
 	return body, nil
@@ -141,6 +241,11 @@ func (b *BingX) Connect(apiKey, secret, passphrase string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Смещение часов нужно до первого подписанного запроса; при ошибке подпись идёт по локальному времени
+	if err := b.clock.Sync(ctx); err != nil {
+		log.Printf("[bingx] %v", err)
+	}
+
 	_, err := b.GetBalance(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to connect to BingX: %w", err)
@@ -276,9 +381,15 @@ func (b *BingX) GetOrderBook(ctx context.Context, symbol string, depth int) (*Or
 	return orderBook, nil
 }
 
-func (b *BingX) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64) (*Order, error) {
+func (b *BingX) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*Order, error) {
 	bingxSymbol := b.toBingXSymbol(symbol)
 
+	inst, size, err := b.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return nil, err
+	}
+	qty = inst.FromVenueSize(size)
+
 	bingxSide := "BUY"
 	positionSide := "LONG"
 	if side == SideSell || side == SideShort {
@@ -291,7 +402,10 @@ func (b *BingX) PlaceMarketOrder(ctx context.Context, symbol, side string, qty f
 		"side":         bingxSide,
 		"positionSide": positionSide,
 		"type":         "MARKET",
-		"quantity":     strconv.FormatFloat(qty, 'f', -1, 64),
+		"quantity":     inst.FormatSize(size),
+	}
+	if clientOrderID != "" {
+		params["clientOrderID"] = clientOrderID
 	}
 
 	body, err := b.doRequest(ctx, http.MethodPost, "/openApi/swap/v2/trade/order", params, true)
@@ -301,14 +415,7 @@ func (b *BingX) PlaceMarketOrder(ctx context.Context, symbol, side string, qty f
 
 	var resp struct {
 		Data struct {
-			Order struct {
-				OrderId     string `json:"orderId"`
-				Symbol      string `json:"symbol"`
-				Side        string `json:"side"`
-				ExecutedQty string `json:"executedQty"`
-				AvgPrice    string `json:"avgPrice"`
-				Status      string `json:"status"`
-			} `json:"order"`
+			Order bingxOrderInfo `json:"order"`
 		} `json:"data"`
 	}
 
@@ -316,21 +423,29 @@ func (b *BingX) PlaceMarketOrder(ctx context.Context, symbol, side string, qty f
 		return nil, err
 	}
 
-	filledQty := b.parseFloat(resp.Data.Order.ExecutedQty, "executedQty")
-	avgPrice := b.parseFloat(resp.Data.Order.AvgPrice, "avgPrice")
-
-	return &Order{
-		ID:           resp.Data.Order.OrderId,
-		Symbol:       symbol,
-		Side:         side,
-		Type:         "market",
-		Quantity:     qty,
-		FilledQty:    filledQty,
-		AvgFillPrice: avgPrice,
-		Status:       OrderStatusFilled,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
-	}, nil
+	order := &Order{
+		ID:            resp.Data.Order.OrderId.String(),
+		ClientOrderID: clientOrderID,
+		Symbol:        symbol,
+		Side:          side,
+		Type:          "market",
+		Quantity:      qty,
+		FilledQty:     b.parseFloat(resp.Data.Order.ExecutedQty, "executedQty"),
+		AvgFillPrice:  b.parseFloat(resp.Data.Order.AvgPrice, "avgPrice"),
+		Status:        OrderStatusFilled,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
+	}
+
+	// Ответ на создание обычно не содержит исполнения, получаем его из состояния ордера
+	if order.FilledQty == 0 {
+		if info, err := b.GetOrder(ctx, symbol, order.ID); err == nil {
+			order.FilledQty = info.FilledQty
+			order.AvgFillPrice = info.AvgFillPrice
+		}
+	}
+
+	return order, nil
 }
 
 func (b *BingX) GetOpenPositions(ctx context.Context) ([]*Position, error) {
@@ -341,15 +456,15 @@ func (b *BingX) GetOpenPositions(ctx context.Context) ([]*Position, error) {
 
 	var resp struct {
 		Data []struct {
-			Symbol          string `json:"symbol"`
-			PositionSide    string `json:"positionSide"`
-			PositionAmt     string `json:"positionAmt"`
-			AvgPrice        string `json:"avgPrice"`
-			MarkPrice       string `json:"markPrice"`
-			Leverage        int    `json:"leverage"`
-			UnrealizedProfit string `json:"unrealizedProfit"`
-			LiquidationPrice string `json:"liquidationPrice"`
-			UpdateTime      int64  `json:"updateTime"`
+			Symbol           string      `json:"symbol"`
+			PositionSide     string      `json:"positionSide"`
+			PositionAmt      string      `json:"positionAmt"`
+			AvgPrice         string      `json:"avgPrice"`
+			MarkPrice        string      `json:"markPrice"`
+			Leverage         int         `json:"leverage"`
+			UnrealizedProfit string      `json:"unrealizedProfit"`
+			LiquidationPrice json.Number `json:"liquidationPrice"`
+			UpdateTime       int64       `json:"updateTime"`
 		} `json:"data"`
 	}
 
@@ -396,6 +511,11 @@ func (b *BingX) GetOpenPositions(ctx context.Context) ([]*Position, error) {
 func (b *BingX) ClosePosition(ctx context.Context, symbol, side string, qty float64) error {
 	bingxSymbol := b.toBingXSymbol(symbol)
 
+	inst, size, err := b.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return err
+	}
+
 	closeSide := "SELL"
 	positionSide := "LONG"
 	if side == SideShort {
@@ -408,28 +528,257 @@ func (b *BingX) ClosePosition(ctx context.Context, symbol, side string, qty floa
 		"side":         closeSide,
 		"positionSide": positionSide,
 		"type":         "MARKET",
-		"quantity":     strconv.FormatFloat(qty, 'f', -1, 64),
+		"quantity":     inst.FormatSize(size),
+	}
+
+	_, err = b.doRequest(ctx, http.MethodPost, "/openApi/swap/v2/trade/order", params, true)
+	return err
+}
+
+// bingxTimeInForce - соответствие time in force значениям BingX
+var bingxTimeInForce = map[string]string{
+	TimeInForceGTC:      "GTC",
+	TimeInForceIOC:      "IOC",
+	TimeInForceFOK:      "FOK",
+	TimeInForcePostOnly: "PostOnly",
+}
+
+// bingxOrderInfo - ордер в ответах /openApi/swap/v2/trade/order и openOrders
+// orderId приходит числом или строкой в зависимости от эндпоинта
+type bingxOrderInfo struct {
+	OrderId       json.Number `json:"orderId"`
+	ClientOrderId string      `json:"clientOrderId"`
+	Symbol        string      `json:"symbol"`
+	Side          string      `json:"side"`
+	Type          string      `json:"type"`
+	Price         string      `json:"price"`
+	OrigQty       string      `json:"origQty"`
+	ExecutedQty   string      `json:"executedQty"`
+	AvgPrice      string      `json:"avgPrice"`
+	Commission    string      `json:"commission"` // отрицательная - списанная комиссия
+	Status        string      `json:"status"`
+	TimeInForce   string      `json:"timeInForce"`
+	Time          int64       `json:"time"`
+	UpdateTime    int64       `json:"updateTime"`
+}
+
+func (b *BingX) PlaceLimitOrder(ctx context.Context, symbol, side string, qty, price float64, tif string) (*Order, error) {
+	tif, err := normalizeTimeInForce(tif)
+	if err != nil {
+		return nil, err
+	}
+
+	inst, size, err := b.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return nil, err
+	}
+	qty = inst.FromVenueSize(size)
+
+	bingxSide := "BUY"
+	positionSide := "LONG"
+	if side == SideSell || side == SideShort {
+		bingxSide = "SELL"
+		positionSide = "SHORT"
+	}
+
+	params := map[string]string{
+		"symbol":       b.toBingXSymbol(symbol),
+		"side":         bingxSide,
+		"positionSide": positionSide,
+		"type":         "LIMIT",
+		"quantity":     inst.FormatSize(size),
+		"price":        strconv.FormatFloat(price, 'f', -1, 64),
+		"timeInForce":  bingxTimeInForce[tif],
 	}
 
-	_, err := b.doRequest(ctx, http.MethodPost, "/openApi/swap/v2/trade/order", params, true)
+	body, err := b.doRequest(ctx, http.MethodPost, "/openApi/swap/v2/trade/order", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data struct {
+			Order bingxOrderInfo `json:"order"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	orderID := resp.Data.Order.OrderId.String()
+
+	// Получаем состояние ордера (IOC/FOK к этому моменту уже исполнены или отменены)
+	order, err := b.GetOrder(ctx, symbol, orderID)
+	if err != nil {
+		return newLimitOrder(orderID, symbol, side, qty, price, tif), nil
+	}
+	order.Side = side
+	return order, nil
+}
+
+func (b *BingX) CancelOrder(ctx context.Context, symbol, orderID string) error {
+	params := map[string]string{
+		"symbol":  b.toBingXSymbol(symbol),
+		"orderId": orderID,
+	}
+
+	_, err := b.doRequest(ctx, http.MethodDelete, "/openApi/swap/v2/trade/order", params, true)
 	return err
 }
 
+func (b *BingX) GetOrder(ctx context.Context, symbol, orderID string) (*Order, error) {
+	return b.queryOrder(ctx, symbol, "orderId", orderID)
+}
+
+func (b *BingX) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*Order, error) {
+	return b.queryOrder(ctx, symbol, "clientOrderId", clientOrderID)
+}
+
+// queryOrder запрашивает ордер по orderId или clientOrderId
+// 80016: биржа не знает ордер
+func (b *BingX) queryOrder(ctx context.Context, symbol, key, id string) (*Order, error) {
+	params := map[string]string{
+		"symbol": b.toBingXSymbol(symbol),
+		key:      id,
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/openApi/swap/v2/trade/order", params, true)
+	if err != nil {
+		return nil, orderNotFound(err, "80016")
+	}
+
+	var resp struct {
+		Data struct {
+			Order bingxOrderInfo `json:"order"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Data.Order.OrderId == "" {
+		return nil, fmt.Errorf("order %s: %w", id, ErrOrderNotFound)
+	}
+	return b.parseOrder(resp.Data.Order), nil
+}
+
+func (b *BingX) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	params := map[string]string{}
+	if symbol != "" {
+		params["symbol"] = b.toBingXSymbol(symbol)
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/openApi/swap/v2/trade/openOrders", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data struct {
+			Orders []bingxOrderInfo `json:"orders"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	orders := make([]*Order, 0, len(resp.Data.Orders))
+	for _, info := range resp.Data.Orders {
+		orders = append(orders, b.parseOrder(info))
+	}
+	return orders, nil
+}
+
+// parseOrder конвертирует ордер BingX в Order
+func (b *BingX) parseOrder(info bingxOrderInfo) *Order {
+	order := &Order{
+		ID:            info.OrderId.String(),
+		ClientOrderID: info.ClientOrderId,
+		Symbol:        b.fromBingXSymbol(info.Symbol),
+		Side:          strings.ToLower(info.Side),
+		Type:          strings.ToLower(info.Type),
+		Price:         b.parseFloat(info.Price, "order.price"),
+		Quantity:      b.parseFloat(info.OrigQty, "order.origQty"),
+		FilledQty:     b.parseFloat(info.ExecutedQty, "order.executedQty"),
+		AvgFillPrice:  b.parseFloat(info.AvgPrice, "order.avgPrice"),
+		Fee:           -b.parseFloat(info.Commission, "order.commission"),
+		CreatedAt:     time.UnixMilli(info.Time),
+		UpdatedAt:     time.UnixMilli(info.UpdateTime),
+	}
+
+	for tif, bingxTIF := range bingxTimeInForce {
+		if info.TimeInForce == bingxTIF {
+			order.TimeInForce = tif
+		}
+	}
+
+	switch info.Status {
+	case "NEW", "PENDING":
+		order.Status = OrderStatusNew
+	case "PARTIALLY_FILLED":
+		order.Status = OrderStatusPartial
+	case "FILLED":
+		order.Status = OrderStatusFilled
+	case "FAILED":
+		order.Status = OrderStatusRejected
+	default: // CANCELED, CANCELLED, EXPIRED
+		order.Status = OrderStatusCancelled
+	}
+
+	return order
+}
+
 func (b *BingX) SubscribeTicker(symbol string, callback func(*Ticker)) error {
 	b.callbackMu.Lock()
 	b.tickerCallbacks[symbol] = callback
 	b.callbackMu.Unlock()
 
+	bingxSymbol := b.toBingXSymbol(symbol)
+	return b.subscribePublic(map[string]interface{}{
+		"id":       fmt.Sprintf("ticker_%s", symbol),
+		"reqType":  "sub",
+		"dataType": fmt.Sprintf("%s@ticker", bingxSymbol),
+	})
+}
+
+// SubscribeOrderBook подписывается на канал depth{5..100}@100ms
+// BingX присылает в каждом сообщении полный топ стакана без номеров обновлений,
+// поэтому каждое сообщение заменяет локальный стакан целиком
+func (b *BingX) SubscribeOrderBook(symbol string, depth int, callback func(*OrderBook)) error {
+	sub := b.books.add(symbol, depth, nil, callback)
+
+	level := 100
+	for _, l := range []int{5, 10, 20, 50} {
+		if sub.depth <= l {
+			level = l
+			break
+		}
+	}
+
+	return b.subscribePublic(map[string]interface{}{
+		"id":       fmt.Sprintf("depth_%s", symbol),
+		"reqType":  "sub",
+		"dataType": fmt.Sprintf("%s@depth%d@100ms", b.toBingXSymbol(symbol), level),
+	})
+}
+
+// subscribePublic отправляет подписку в публичный WebSocket, подключаясь при необходимости
+func (b *BingX) subscribePublic(subMsg map[string]interface{}) error {
 	b.wsMu.Lock()
 	if b.wsManager == nil {
 		config := DefaultWSReconnectConfig()
-		b.wsManager = NewWSReconnectManager("bingx", bingxWSURL, config)
+		b.wsManager = NewWSReconnectManager("bingx", b.endpoints.WSPublic, config)
+		b.health.TrackWS(b.wsManager)
 
 		b.wsManager.SetOnMessage(b.handleMessage)
 		b.wsManager.SetOnConnect(func() {
 			log.Printf("[bingx] WebSocket connected")
 		})
 		b.wsManager.SetOnDisconnect(func(err error) {
+			b.books.invalidateAll()
 			if err != nil {
 				log.Printf("[bingx] WebSocket disconnected: %v", err)
 			}
@@ -443,105 +792,463 @@ func (b *BingX) SubscribeTicker(symbol string, callback func(*Ticker)) error {
 	wsManager := b.wsManager
 	b.wsMu.Unlock()
 
-	bingxSymbol := b.toBingXSymbol(symbol)
-	subMsg := map[string]interface{}{
-		"id":       fmt.Sprintf("ticker_%s", symbol),
-		"reqType":  "sub",
-		"dataType": fmt.Sprintf("%s@ticker", bingxSymbol),
-	}
-
 	wsManager.AddSubscription(subMsg)
 	return wsManager.Send(subMsg)
 }
 
 // handleMessage обрабатывает одно сообщение из WebSocket
 func (b *BingX) handleMessage(message []byte) {
+	// BingX отправляет сообщения в gzip
+	message = decompressWSMessage(message)
+
+	// Без ответа на Ping сервер закрывает соединение
+	if string(message) == "Ping" {
+		b.wsMu.Lock()
+		wsManager := b.wsManager
+		b.wsMu.Unlock()
+		if wsManager != nil {
+			wsManager.SendText("Pong")
+		}
+		return
+	}
+
 	var msg struct {
-		DataType string `json:"dataType"`
-		Data     struct {
-			Symbol    string `json:"s"`
-			LastPrice string `json:"c"`
-			BidPrice  string `json:"b"`
-			AskPrice  string `json:"a"`
-		} `json:"data"`
+		DataType string          `json:"dataType"`
+		Data     json.RawMessage `json:"data"`
+	}
+
+	if err := json.Unmarshal(message, &msg); err != nil || len(msg.Data) == 0 {
+		return
+	}
+
+	switch {
+	case strings.Contains(msg.DataType, "@ticker"):
+		b.handleTickerUpdate(msg.Data)
+	case strings.Contains(msg.DataType, "@depth"):
+		b.handleOrderBookUpdate(b.fromBingXSymbol(strings.SplitN(msg.DataType, "@", 2)[0]), msg.Data)
+	}
+}
+
+// handleTickerUpdate обрабатывает канал ticker
+func (b *BingX) handleTickerUpdate(data json.RawMessage) {
+	// Ключи объёмов и времени (B, A, C) объявлены явно, иначе encoding/json
+	// без учёта регистра запишет их в b, a и c
+	var ticker struct {
+		Symbol    string          `json:"s"`
+		LastPrice string          `json:"c"`
+		BidPrice  string          `json:"b"`
+		AskPrice  string          `json:"a"`
+		BidQty    json.RawMessage `json:"B"`
+		AskQty    json.RawMessage `json:"A"`
+		CloseTime json.RawMessage `json:"C"`
 	}
 
-	if err := json.Unmarshal(message, &msg); err != nil {
+	if err := json.Unmarshal(data, &ticker); err != nil {
 		return
 	}
 
-	if strings.Contains(msg.DataType, "@ticker") {
-		symbol := b.fromBingXSymbol(msg.Data.Symbol)
+	symbol := b.fromBingXSymbol(ticker.Symbol)
 
-		b.callbackMu.RLock()
-		callback, ok := b.tickerCallbacks[symbol]
-		b.callbackMu.RUnlock()
+	b.callbackMu.RLock()
+	callback, ok := b.tickerCallbacks[symbol]
+	b.callbackMu.RUnlock()
 
-		if ok && callback != nil {
-			bidPrice := b.parseFloat(msg.Data.BidPrice, "ws_bidPrice")
-			askPrice := b.parseFloat(msg.Data.AskPrice, "ws_askPrice")
-			lastPrice := b.parseFloat(msg.Data.LastPrice, "ws_lastPrice")
+	if ok && callback != nil {
+		bidPrice := b.parseFloat(ticker.BidPrice, "ws_bidPrice")
+		askPrice := b.parseFloat(ticker.AskPrice, "ws_askPrice")
+		lastPrice := b.parseFloat(ticker.LastPrice, "ws_lastPrice")
 
-			callback(&Ticker{
-				Symbol:    symbol,
-				BidPrice:  bidPrice,
-				AskPrice:  askPrice,
-				LastPrice: lastPrice,
-				Timestamp: time.Now(),
-			})
-		}
+		callback(&Ticker{
+			Symbol:    symbol,
+			BidPrice:  bidPrice,
+			AskPrice:  askPrice,
+			LastPrice: lastPrice,
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// handleOrderBookUpdate заменяет локальный стакан очередным снимком
+func (b *BingX) handleOrderBookUpdate(symbol string, data json.RawMessage) {
+	var book struct {
+		Bids [][]string `json:"bids"`
+		Asks [][]string `json:"asks"`
 	}
+
+	if err := json.Unmarshal(data, &book); err != nil {
+		return
+	}
+
+	sub := b.books.get(symbol)
+	if sub == nil {
+		return
+	}
+
+	sub.book.Reset(stringBookLevels(book.Bids), stringBookLevels(book.Asks), 0, time.Now())
+	sub.emit()
 }
 
-// SubscribePositions сохраняет callback для обновлений позиций.
-//
-// ВАЖНО: BingX требует отдельного аутентифицированного WebSocket соединения
-// для приватных каналов. Текущая реализация использует упрощённый подход -
-// callback сохраняется, но WebSocket подписка не создаётся.
-//
-// Обнаружение ликвидаций реализуется через:
-// 1. Периодический polling через GetOpenPositions() в Risk Manager (каждые 500ms)
-// 2. Сравнение markPrice с liquidationPrice для раннего предупреждения
-//
-// Для полной реализации потребуется:
-// - WebSocket к wss://open-api-swap.bingx.com/swap-market с аутентификацией
-// - Подписка на канал ACCOUNT_UPDATE для отслеживания позиций
-// - Обработка сообщений типа "ACCOUNT_UPDATE" с eventType "LIQUIDATION"
-//
-// См. документацию BingX: https://bingx-api.github.io/docs/#/en-us/swapV2/socket/account.html
+// SubscribePositions подписывается на приватный поток пользователя (user data stream)
+// Поток адресуется listenKey: ключ получается перед каждым подключением,
+// продлевается по таймеру и пересоздаётся при истечении
 func (b *BingX) SubscribePositions(callback func(*Position)) error {
 	b.callbackMu.Lock()
 	b.positionCallback = callback
 	b.callbackMu.Unlock()
 
-	// Callback сохранён для использования при polling через REST API
-	// или для будущей реализации WebSocket подписки
-	log.Printf("[bingx] SubscribePositions: callback registered (using REST API polling for position updates)")
+	return b.subscribePrivate()
+}
+
+// SubscribeOrders подписывается на обновления ордеров (ORDER_TRADE_UPDATE user data stream)
+func (b *BingX) SubscribeOrders(callback func(*Order)) error {
+	b.callbackMu.Lock()
+	b.orderCallback = callback
+	b.callbackMu.Unlock()
+
+	return b.subscribePrivate()
+}
+
+// subscribePrivate подключается к user data stream
+// Отдельные подписки не нужны - поток присылает все события аккаунта
+func (b *BingX) subscribePrivate() error {
+	b.wsMu.Lock()
+	defer b.wsMu.Unlock()
+
+	if b.wsPrivateManager != nil {
+		return nil
+	}
+
+	config := DefaultWSReconnectConfig()
+	wsManager := NewWSReconnectManager("bingx-private", b.endpoints.WSPrivate, config)
+	b.health.TrackWS(wsManager)
+
+	wsManager.SetURLFunc(b.privateWSURL)
+	wsManager.SetOnMessage(b.handlePrivateMessage)
+	wsManager.SetOnConnect(func() {
+		log.Printf("[bingx] Private WebSocket connected")
+	})
+	wsManager.SetOnDisconnect(func(err error) {
+		if err != nil {
+			log.Printf("[bingx] Private WebSocket disconnected: %v", err)
+		}
+	})
+
+	if err := wsManager.Connect(); err != nil {
+		return fmt.Errorf("failed to connect to private WebSocket: %w", err)
+	}
+
+	b.wsPrivateManager = wsManager
+	go b.keepAliveListenKey()
+
 	return nil
 }
 
+// privateWSURL возвращает адрес приватного потока, при необходимости получая новый listenKey
+func (b *BingX) privateWSURL() (string, error) {
+	b.listenKeyMu.Lock()
+	defer b.listenKeyMu.Unlock()
+
+	if b.listenKey == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+
+		body, err := b.doRequest(ctx, http.MethodPost, bingxListenKeyEndpoint, nil, false)
+		if err != nil {
+			return "", fmt.Errorf("listen key: %w", err)
+		}
+
+		var resp struct {
+			ListenKey string `json:"listenKey"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return "", err
+		}
+		if resp.ListenKey == "" {
+			return "", fmt.Errorf("listen key: empty response")
+		}
+		b.listenKey = resp.ListenKey
+	}
+
+	return b.endpoints.WSPrivate + "?listenKey=" + url.QueryEscape(b.listenKey), nil
+}
+
+// getListenKey возвращает текущий listenKey (пустой, если не получен)
+func (b *BingX) getListenKey() string {
+	b.listenKeyMu.Lock()
+	defer b.listenKeyMu.Unlock()
+	return b.listenKey
+}
+
+// resetListenKey сбрасывает listenKey и переподключает приватный поток с новым ключом
+func (b *BingX) resetListenKey() {
+	b.listenKeyMu.Lock()
+	b.listenKey = ""
+	b.listenKeyMu.Unlock()
+
+	b.wsMu.Lock()
+	wsManager := b.wsPrivateManager
+	b.wsMu.Unlock()
+
+	if wsManager != nil {
+		wsManager.Reconnect()
+	}
+}
+
+// keepAliveListenKey продлевает listenKey, пока биржа не закрыта
+// Если продлить не удалось, ключ считается истёкшим и пересоздаётся
+func (b *BingX) keepAliveListenKey() {
+	ticker := time.NewTicker(b.keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-b.closeChan:
+			return
+		case <-ticker.C:
+		}
+
+		listenKey := b.getListenKey()
+		if listenKey == "" {
+			continue
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		_, err := b.doRequest(ctx, http.MethodPut, bingxListenKeyEndpoint+"?listenKey="+url.QueryEscape(listenKey), nil, false)
+		cancel()
+
+		if err != nil {
+			log.Printf("[bingx] Failed to extend listen key, recreating: %v", err)
+			b.resetListenKey()
+		}
+	}
+}
+
+// bingxPrivateEvent - событие приватного потока BingX
+// Парные по регистру поля (E, N) объявлены, чтобы не затирать e и n
+type bingxPrivateEvent struct {
+	Event     string `json:"e"`
+	EventTime int64  `json:"E"`
+
+	// ACCOUNT_UPDATE
+	Account struct {
+		Reason    string `json:"m"`
+		Positions []struct {
+			Symbol        string `json:"s"`
+			Amount        string `json:"pa"`
+			EntryPrice    string `json:"ep"`
+			UnrealizedPnl string `json:"up"`
+			PositionSide  string `json:"ps"`
+		} `json:"P"`
+	} `json:"a"`
+
+	// ORDER_TRADE_UPDATE
+	Order struct {
+		Symbol        string      `json:"s"`
+		OrderID       json.Number `json:"i"`
+		ClientOrderID string      `json:"c"`
+		Side          string      `json:"S"`
+		Type          string      `json:"o"`
+		Quantity      string      `json:"q"`
+		Price         string      `json:"p"`
+		ExecutionType string      `json:"x"`
+		Status        string      `json:"X"`
+		AvgPrice      string      `json:"ap"`
+		FilledQty     string      `json:"z"`
+		Commission    string      `json:"n"`
+		FeeAsset      string      `json:"N"`
+		PositionSide  string      `json:"ps"`
+		TradeTime     int64       `json:"T"`
+	} `json:"o"`
+}
+
+// handlePrivateMessage обрабатывает сообщение приватного потока
+func (b *BingX) handlePrivateMessage(message []byte) {
+	message = decompressWSMessage(message)
+
+	// Без ответа на Ping сервер закрывает соединение
+	if string(message) == "Ping" {
+		b.wsMu.Lock()
+		wsManager := b.wsPrivateManager
+		b.wsMu.Unlock()
+		if wsManager != nil {
+			wsManager.SendText("Pong")
+		}
+		return
+	}
+
+	var event bingxPrivateEvent
+	if err := json.Unmarshal(message, &event); err != nil {
+		return
+	}
+
+	switch event.Event {
+	case "listenKeyExpired":
+		log.Printf("[bingx] Listen key expired, recreating")
+		b.resetListenKey()
+
+	case "ACCOUNT_UPDATE":
+		b.handleAccountUpdate(&event)
+
+	case "ORDER_TRADE_UPDATE":
+		b.handleOrderTradeUpdate(&event)
+	}
+}
+
+// handleAccountUpdate отправляет изменения позиций в callback
+// Причина LIQUIDATION означает, что позиции изменены принудительной ликвидацией
+func (b *BingX) handleAccountUpdate(event *bingxPrivateEvent) {
+	b.callbackMu.RLock()
+	callback := b.positionCallback
+	b.callbackMu.RUnlock()
+
+	if callback == nil {
+		return
+	}
+
+	liquidation := event.Account.Reason == "LIQUIDATION"
+
+	for _, p := range event.Account.Positions {
+		amount := b.parseFloat(p.Amount, "ws_positionAmt")
+
+		side := SideLong
+		if p.PositionSide == "SHORT" || (p.PositionSide == "BOTH" && amount < 0) {
+			side = SideShort
+		}
+
+		callback(&Position{
+			Symbol:        b.fromBingXSymbol(p.Symbol),
+			Side:          side,
+			Size:          math.Abs(amount),
+			EntryPrice:    b.parseFloat(p.EntryPrice, "ws_entryPrice"),
+			UnrealizedPnl: b.parseFloat(p.UnrealizedPnl, "ws_unrealizedPnl"),
+			Liquidation:   liquidation,
+			UpdatedAt:     time.Now(),
+		})
+	}
+}
+
+// handleOrderTradeUpdate отправляет обновление ордера в callback и сообщает о ликвидации
+// по исполнению ордера принудительной ликвидации. Ордер ликвидации закрывает позицию:
+// SELL - лонг, BUY - шорт
+func (b *BingX) handleOrderTradeUpdate(event *bingxPrivateEvent) {
+	order := &event.Order
+
+	b.callbackMu.RLock()
+	orderCallback := b.orderCallback
+	positionCallback := b.positionCallback
+	b.callbackMu.RUnlock()
+
+	if orderCallback != nil && order.OrderID != "" {
+		orderCallback(b.parseOrder(bingxOrderInfo{
+			OrderId:       order.OrderID,
+			ClientOrderId: order.ClientOrderID,
+			Symbol:        order.Symbol,
+			Side:          order.Side,
+			Type:          order.Type,
+			Price:         order.Price,
+			OrigQty:       order.Quantity,
+			ExecutedQty:   order.FilledQty,
+			AvgPrice:      order.AvgPrice,
+			Commission:    order.Commission,
+			Status:        order.Status,
+			Time:          order.TradeTime,
+			UpdateTime:    order.TradeTime,
+		}))
+	}
+
+	liquidation := order.Type == "LIQUIDATION" || order.ExecutionType == "CALCULATED" ||
+		strings.HasPrefix(order.ClientOrderID, "autoclose-")
+	if !liquidation || positionCallback == nil {
+		return
+	}
+
+	side := SideShort
+	if order.PositionSide == "LONG" || (order.PositionSide != "SHORT" && order.Side == "SELL") {
+		side = SideLong
+	}
+
+	positionCallback(&Position{
+		Symbol:      b.fromBingXSymbol(order.Symbol),
+		Side:        side,
+		Size:        b.parseFloat(order.FilledQty, "ws_filledQty"),
+		MarkPrice:   b.parseFloat(order.AvgPrice, "ws_avgPrice"),
+		Liquidation: true,
+		UpdatedAt:   time.Now(),
+	})
+}
+
 func (b *BingX) GetTradingFee(ctx context.Context, symbol string) (float64, error) {
-	return 0.0005, nil // 0.05% стандартная комиссия
+	fees, err := b.GetTradingFees(ctx, symbol)
+	if err != nil {
+		return 0.0005, nil // 0.05% стандартная комиссия
+	}
+	return fees.Taker, nil
 }
 
-func (b *BingX) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
-	bingxSymbol := b.toBingXSymbol(symbol)
+// GetTradingFees получает комиссии аккаунта из /openApi/swap/v2/user/commissionRate
+// Ставки общие для всех контрактов и уже учитывают VIP-уровень
+func (b *BingX) GetTradingFees(ctx context.Context, symbol string) (*TradingFees, error) {
+	body, err := b.doRequest(ctx, http.MethodGet, "/openApi/swap/v2/user/commissionRate", nil, true)
+	if err != nil {
+		return nil, err
+	}
 
-	params := map[string]string{
-		"symbol": bingxSymbol,
+	var resp struct {
+		Data struct {
+			Commission *struct {
+				TakerCommissionRate float64 `json:"takerCommissionRate"`
+				MakerCommissionRate float64 `json:"makerCommissionRate"`
+			} `json:"commission"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Data.Commission == nil {
+		return nil, fmt.Errorf("commission rate not found")
+	}
+
+	return &TradingFees{
+		Symbol:    symbol,
+		Maker:     resp.Data.Commission.MakerCommissionRate,
+		Taker:     resp.Data.Commission.TakerCommissionRate,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+func (b *BingX) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
+	inst, err := b.GetInstrument(ctx, symbol)
+	if err != nil {
+		return nil, err
 	}
+	return inst.Limits(), nil
+}
+
+func (b *BingX) GetInstrument(ctx context.Context, symbol string) (*Instrument, error) {
+	return b.instruments.Get(ctx, symbol)
+}
 
-	body, err := b.doRequest(ctx, http.MethodGet, "/openApi/swap/v2/quote/contracts", params, false)
+// loadInstruments загружает спецификации всех USDT бессрочных контрактов
+// Количество BingX в монетах с точностью quantityPrecision знаков
+func (b *BingX) loadInstruments(ctx context.Context) ([]*Instrument, error) {
+	body, err := b.doRequest(ctx, http.MethodGet, "/openApi/swap/v2/quote/contracts", nil, false)
 	if err != nil {
 		return nil, err
 	}
 
 	var resp struct {
 		Data []struct {
-			Symbol       string `json:"symbol"`
-			Size         string `json:"size"`
-			TickSize     string `json:"tickSize"`
-			MaxLongLeverage int `json:"maxLongLeverage"`
+			Symbol            string  `json:"symbol"`
+			Currency          string  `json:"currency"`
+			Size              string  `json:"size"`
+			QuantityPrecision int     `json:"quantityPrecision"`
+			PricePrecision    int     `json:"pricePrecision"`
+			TradeMinQuantity  float64 `json:"tradeMinQuantity"`
+			TradeMinUSDT      float64 `json:"tradeMinUSDT"`
+			MaxLongLeverage   int     `json:"maxLongLeverage"`
+			Status            int     `json:"status"` // 1 - торгуется, 5 - pre-open, 25 - запрет открытия
 		} `json:"data"`
 	}
 
@@ -549,25 +1256,208 @@ func (b *BingX) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
 		return nil, err
 	}
 
-	if len(resp.Data) == 0 {
-		return nil, fmt.Errorf("contract info not found for %s", symbol)
+	instruments := make([]*Instrument, 0, len(resp.Data))
+	for _, info := range resp.Data {
+		if info.Currency != "" && info.Currency != "USDT" {
+			continue
+		}
+
+		minSize := info.TradeMinQuantity
+		if minSize <= 0 {
+			minSize = b.parseFloat(info.Size, "instrument.size")
+		}
+
+		minNotional := info.TradeMinUSDT
+		if minNotional <= 0 {
+			minNotional = 5.0
+		}
+
+		status := InstrumentStatusDelisted
+		switch info.Status {
+		case 1:
+			status = InstrumentStatusTrading
+		case 5, 25:
+			status = InstrumentStatusSuspended
+		}
+
+		instruments = append(instruments, &Instrument{
+			Symbol:         b.fromBingXSymbol(info.Symbol),
+			VenueSymbol:    info.Symbol,
+			ContractValue:  1,
+			LotSize:        precisionStep(info.QuantityPrecision),
+			MinSize:        minSize,
+			TickSize:       precisionStep(info.PricePrecision),
+			MinNotional:    minNotional,
+			MaxLeverage:    info.MaxLongLeverage,
+			SettleCurrency: "USDT",
+			Status:         status,
+		})
+	}
+
+	return instruments, nil
+}
+
+func (b *BingX) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	params := map[string]string{
+		"symbol": b.toBingXSymbol(symbol),
 	}
 
-	info := resp.Data[0]
-	minSize := b.parseFloat(info.Size, "size")
-	tickSize := b.parseFloat(info.TickSize, "tickSize")
+	body, err := b.doRequest(ctx, http.MethodGet, "/openApi/swap/v2/quote/premiumIndex", params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data struct {
+			LastFundingRate string `json:"lastFundingRate"`
+			NextFundingTime int64  `json:"nextFundingTime"`
+		} `json:"data"`
+	}
 
-	return &Limits{
-		Symbol:      symbol,
-		MinOrderQty: minSize,
-		MaxOrderQty: 1000000,
-		QtyStep:     minSize,
-		MinNotional: 5.0,
-		PriceStep:   tickSize,
-		MaxLeverage: info.MaxLongLeverage,
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	// lastFundingRate у BingX - текущая ставка, которая будет начислена в nextFundingTime
+	return &FundingRate{
+		Symbol:          symbol,
+		Rate:            b.parseFloat(resp.Data.LastFundingRate, "lastFundingRate"),
+		NextFundingTime: time.UnixMilli(resp.Data.NextFundingTime),
+		Interval:        DefaultFundingInterval,
+		Timestamp:       time.Now(),
 	}, nil
 }
 
+// SetLeverage устанавливает плечо символа для обеих сторон (LONG и SHORT)
+func (b *BingX) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if err := validateLeverage(leverage); err != nil {
+		return err
+	}
+
+	for _, side := range []string{"LONG", "SHORT"} {
+		params := map[string]string{
+			"symbol":   b.toBingXSymbol(symbol),
+			"side":     side,
+			"leverage": strconv.Itoa(leverage),
+		}
+
+		if _, err := b.doRequest(ctx, http.MethodPost, "/openApi/swap/v2/trade/leverage", params, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetMarginMode устанавливает режим маржи символа (ISOLATED / CROSSED)
+func (b *BingX) SetMarginMode(ctx context.Context, symbol, mode string) error {
+	if err := validateMarginMode(mode); err != nil {
+		return err
+	}
+
+	marginType := "CROSSED"
+	if mode == MarginModeIsolated {
+		marginType = "ISOLATED"
+	}
+
+	params := map[string]string{
+		"symbol":     b.toBingXSymbol(symbol),
+		"marginType": marginType,
+	}
+
+	_, err := b.doRequest(ctx, http.MethodPost, "/openApi/swap/v2/trade/marginType", params, true)
+	return err
+}
+
+// GetMarginSettings читает плечо сторон и режим маржи символа
+func (b *BingX) GetMarginSettings(ctx context.Context, symbol string) (int, string, error) {
+	params := map[string]string{
+		"symbol": b.toBingXSymbol(symbol),
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/openApi/swap/v2/trade/leverage", params, true)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var leverageResp struct {
+		Data struct {
+			LongLeverage  int `json:"longLeverage"`
+			ShortLeverage int `json:"shortLeverage"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &leverageResp); err != nil {
+		return 0, "", err
+	}
+
+	leverage, err := commonLeverage(symbol, leverageResp.Data.LongLeverage, leverageResp.Data.ShortLeverage)
+	if err != nil {
+		return 0, "", err
+	}
+
+	body, err = b.doRequest(ctx, http.MethodGet, "/openApi/swap/v2/trade/marginType", params, true)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var modeResp struct {
+		Data struct {
+			MarginType string `json:"marginType"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &modeResp); err != nil {
+		return 0, "", err
+	}
+
+	mode := MarginModeCross
+	if modeResp.Data.MarginType == "ISOLATED" {
+		mode = MarginModeIsolated
+	}
+	return leverage, mode, nil
+}
+
+// GetFundingPayments читает начисления фандинга символа из /openApi/swap/v2/user/income (FUNDING_FEE)
+func (b *BingX) GetFundingPayments(ctx context.Context, symbol string, from, to time.Time) ([]FundingPayment, error) {
+	bingxSymbol := b.toBingXSymbol(symbol)
+	params := map[string]string{
+		"symbol":     bingxSymbol,
+		"incomeType": "FUNDING_FEE",
+		"startTime":  strconv.FormatInt(from.UnixMilli(), 10),
+		"endTime":    strconv.FormatInt(to.UnixMilli(), 10),
+		"limit":      "1000",
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/openApi/swap/v2/user/income", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data []struct {
+			Symbol     string `json:"symbol"`
+			IncomeType string `json:"incomeType"`
+			Income     string `json:"income"`
+			Time       int64  `json:"time"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	payments := make([]FundingPayment, 0, len(resp.Data))
+	for _, r := range resp.Data {
+		if r.Symbol != bingxSymbol || r.IncomeType != "FUNDING_FEE" {
+			continue
+		}
+		payments = append(payments, FundingPayment{
+			Symbol: symbol,
+			Amount: b.parseFloat(r.Income, "income.income"),
+			Time:   time.UnixMilli(r.Time),
+		})
+	}
+	return fundingPaymentsBetween(payments, from, to), nil
+}
+
 func (b *BingX) Close() error {
 	select {
 	case <-b.closeChan:
@@ -580,8 +1470,21 @@ func (b *BingX) Close() error {
 		b.wsManager.Close()
 		b.wsManager = nil
 	}
+	privateManager := b.wsPrivateManager
+	b.wsPrivateManager = nil
 	b.wsMu.Unlock()
 
+	if privateManager != nil {
+		privateManager.Close()
+
+		// Ключ больше не нужен - удаляем, чтобы не держать поток на бирже
+		if listenKey := b.getListenKey(); listenKey != "" {
+			ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+			b.doRequest(ctx, http.MethodDelete, bingxListenKeyEndpoint, map[string]string{"listenKey": listenKey}, false)
+			cancel()
+		}
+	}
+
 	b.connected = false
 	return nil
 }
@@ -0,0 +1,318 @@
+package exchange
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	bingxTestAPIKey = "test-api-key"
+
+	bingxTestPrivatePath = "/swap-market"
+
+	bingxTestListenKey        = "a8ea75681542e66f1a50a1616dd06ed77dab61baa0c296bca03a9b13ee5f2dd7"
+	bingxTestRenewedListenKey = "d84d39fe78762b39e202ba204bf3f7ebed43bbe7a481299779cb53479ea9677d"
+)
+
+// bingxTestServer - локальный BingX: выдача и продление listenKey и приватный поток
+type bingxTestServer struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu          sync.Mutex
+	created     int      // выданные listenKey
+	keepAlives  []string // продлённые listenKey
+	deleted     []string // удалённые listenKey
+	connections []string // listenKey подключений приватного потока
+	pongs       int
+
+	// failKeepAlive - продление отклоняется, как для истёкшего ключа
+	failKeepAlive bool
+
+	// userGate запускает отправку событий первому подключению
+	userGate chan struct{}
+}
+
+func newBingXTestServer(t *testing.T) *bingxTestServer {
+	s := &bingxTestServer{t: t, userGate: make(chan struct{})}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// newBingXForTest создаёт адаптер, направленный на тестовый сервер
+func newBingXForTest(s *bingxTestServer) *BingX {
+	wsURL := "ws" + strings.TrimPrefix(s.server.URL, "http")
+	b := NewBingX()
+	b.SetEndpoints(Endpoints{REST: s.server.URL, WSPublic: wsURL + "/swap-market-public", WSPrivate: wsURL + bingxTestPrivatePath})
+	b.apiKey = bingxTestAPIKey
+	b.secretKey = "test-secret"
+	return b
+}
+
+// loadBingXFixture читает fixture; вызывается из горутин сервера, поэтому без Fatal
+func loadBingXFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", "bingx", name))
+	if err != nil {
+		t.Errorf("read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+func (s *bingxTestServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == bingxTestPrivatePath {
+		s.handlePrivateWS(w, r)
+		return
+	}
+	if r.URL.Path != bingxListenKeyEndpoint {
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+	if r.Header.Get("X-BX-APIKEY") != bingxTestAPIKey {
+		s.t.Errorf("%s %s without API key header", r.Method, r.URL.Path)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	switch r.Method {
+	case http.MethodPost:
+		s.created++
+		fixture := "listen_key.json"
+		if s.created > 1 {
+			fixture = "listen_key_renewed.json"
+		}
+		w.Write(loadBingXFixture(s.t, fixture))
+	case http.MethodPut:
+		s.keepAlives = append(s.keepAlives, r.URL.Query().Get("listenKey"))
+		if s.failKeepAlive {
+			w.WriteHeader(http.StatusNotFound)
+			w.Write([]byte(`{"code":80018,"msg":"listenKey not exist"}`))
+			return
+		}
+		w.Write(loadBingXFixture(s.t, "listen_key_keepalive.json"))
+	case http.MethodDelete:
+		s.deleted = append(s.deleted, r.URL.Query().Get("listenKey"))
+		w.Write(loadBingXFixture(s.t, "listen_key_keepalive.json"))
+	default:
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+	}
+}
+
+// handlePrivateWS запоминает listenKey подключения и по userGate отправляет первому
+// подключению события из ws_user_events.json в сжатом виде, как BingX
+func (s *bingxTestServer) handlePrivateWS(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	s.connections = append(s.connections, r.URL.Query().Get("listenKey"))
+	first := len(s.connections) == 1
+	s.mu.Unlock()
+
+	var writeMu sync.Mutex
+	send := func(message []byte) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(message)
+		zw.Close()
+
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		conn.WriteMessage(websocket.BinaryMessage, buf.Bytes())
+	}
+
+	if first {
+		go func() {
+			select {
+			case <-s.userGate:
+			case <-time.After(5 * time.Second):
+				return
+			}
+			var events []json.RawMessage
+			if err := json.Unmarshal(loadBingXFixture(s.t, "ws_user_events.json"), &events); err != nil {
+				s.t.Errorf("parse fixture ws_user_events.json: %v", err)
+			}
+			for _, event := range events {
+				// Строка в fixture - текстовое сообщение ("Ping")
+				var text string
+				if json.Unmarshal(event, &text) == nil {
+					event = []byte(text)
+				}
+				send(event)
+			}
+		}()
+	}
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if string(message) == "Pong" {
+			s.mu.Lock()
+			s.pongs++
+			s.mu.Unlock()
+		}
+	}
+}
+
+// waitFor ждёт выполнения условия над состоянием сервера
+func (s *bingxTestServer) waitFor(timeout time.Duration, cond func() bool) bool {
+	for deadline := time.Now().Add(timeout); ; time.Sleep(10 * time.Millisecond) {
+		s.mu.Lock()
+		ok := cond()
+		s.mu.Unlock()
+		if ok {
+			return true
+		}
+		if time.Now().After(deadline) {
+			return false
+		}
+	}
+}
+
+// TestBingX_UserDataStream проверяет получение и продление listenKey, разбор событий
+// и переподключение с новым ключом после listenKeyExpired
+func TestBingX_UserDataStream(t *testing.T) {
+	srv := newBingXTestServer(t)
+	b := newBingXForTest(srv)
+	b.keepAlive = 20 * time.Millisecond
+
+	positions := make(chan *Position, 8)
+	orders := make(chan *Order, 8)
+	if err := b.SubscribePositions(func(p *Position) { positions <- p }); err != nil {
+		t.Fatalf("SubscribePositions: %v", err)
+	}
+	if err := b.SubscribeOrders(func(o *Order) { orders <- o }); err != nil {
+		t.Fatalf("SubscribeOrders: %v", err)
+	}
+
+	// Поток один на обе подписки и адресуется полученным ключом
+	if !srv.waitFor(time.Second, func() bool { return len(srv.keepAlives) > 0 }) {
+		t.Fatal("listen key was not extended")
+	}
+	srv.mu.Lock()
+	if srv.created != 1 || len(srv.connections) != 1 || srv.connections[0] != bingxTestListenKey {
+		t.Fatalf("expected one connection with listen key, got %d keys, connections %v", srv.created, srv.connections)
+	}
+	if srv.keepAlives[0] != bingxTestListenKey {
+		t.Fatalf("expected keepalive of %s, got %s", bingxTestListenKey, srv.keepAlives[0])
+	}
+	srv.mu.Unlock()
+
+	close(srv.userGate)
+
+	nextOrder := func() *Order {
+		select {
+		case order := <-orders:
+			return order
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for order update")
+		}
+		return nil
+	}
+	nextPosition := func() *Position {
+		select {
+		case position := <-positions:
+			return position
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for position update")
+		}
+		return nil
+	}
+
+	order := nextOrder()
+	if order.ID != "1978012449498123456" || order.ClientOrderID != "a1t199ec1f1e00p0n0l" || order.Symbol != "BTCUSDT" ||
+		order.Side != SideBuy || order.Status != OrderStatusPartial || !almostEqual(order.FilledQty, 0.006) {
+		t.Fatalf("unexpected partial fill: %+v", order)
+	}
+	order = nextOrder()
+	if order.Status != OrderStatusFilled || !almostEqual(order.FilledQty, 0.012) ||
+		order.AvgFillPrice != 64012.3 || !almostEqual(order.Fee, 0.384074) {
+		t.Fatalf("unexpected fill: %+v", order)
+	}
+
+	position := nextPosition()
+	if position.Symbol != "BTCUSDT" || position.Side != SideLong || !almostEqual(position.Size, 0.012) ||
+		position.EntryPrice != 64012.3 || position.UnrealizedPnl != 0.264 || position.Liquidation {
+		t.Fatalf("unexpected position: %+v", position)
+	}
+
+	// Ордер ликвидации SELL закрывает лонг
+	order = nextOrder()
+	if order.Side != SideSell || order.Type != "liquidation" || order.Status != OrderStatusFilled {
+		t.Fatalf("unexpected liquidation order: %+v", order)
+	}
+	position = nextPosition()
+	if !position.Liquidation || position.Side != SideLong || !almostEqual(position.Size, 0.012) || position.MarkPrice != 57512.4 {
+		t.Fatalf("expected liquidation of long position from order, got %+v", position)
+	}
+	position = nextPosition()
+	if !position.Liquidation || position.Size != 0 {
+		t.Fatalf("expected ACCOUNT_UPDATE with LIQUIDATION reason, got %+v", position)
+	}
+
+	// listenKeyExpired: новый ключ и переподключение с ним (после задержки переподключения)
+	if !srv.waitFor(10*time.Second, func() bool { return len(srv.connections) == 2 }) {
+		t.Fatal("private stream was not reconnected after listenKeyExpired")
+	}
+	srv.mu.Lock()
+	if srv.created != 2 || srv.connections[1] != bingxTestRenewedListenKey || srv.pongs != 1 {
+		t.Fatalf("expected reconnect with renewed key and one pong, got %d keys, connections %v, pongs %d",
+			srv.created, srv.connections, srv.pongs)
+	}
+	srv.mu.Unlock()
+
+	if !srv.waitFor(time.Second, func() bool {
+		return srv.keepAlives[len(srv.keepAlives)-1] == bingxTestRenewedListenKey
+	}) {
+		t.Fatal("renewed listen key was not extended")
+	}
+
+	b.Close()
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if len(srv.deleted) != 1 || srv.deleted[0] != bingxTestRenewedListenKey {
+		t.Fatalf("expected renewed listen key deleted on Close, got %v", srv.deleted)
+	}
+}
+
+// TestBingX_ListenKeyKeepAliveFailure проверяет, что ключ, который не удалось продлить,
+// пересоздаётся и поток переподключается с новым ключом
+func TestBingX_ListenKeyKeepAliveFailure(t *testing.T) {
+	srv := newBingXTestServer(t)
+	srv.failKeepAlive = true
+	b := newBingXForTest(srv)
+	b.keepAlive = 20 * time.Millisecond
+	defer b.Close()
+
+	if err := b.SubscribeOrders(func(*Order) {}); err != nil {
+		t.Fatalf("SubscribeOrders: %v", err)
+	}
+
+	if !srv.waitFor(10*time.Second, func() bool { return len(srv.connections) == 2 }) {
+		t.Fatal("private stream was not reconnected after failed keepalive")
+	}
+	srv.mu.Lock()
+	defer srv.mu.Unlock()
+	if srv.keepAlives[0] != bingxTestListenKey || srv.connections[1] != bingxTestRenewedListenKey {
+		t.Fatalf("expected reconnect with renewed key, got keepalives %v, connections %v", srv.keepAlives, srv.connections)
+	}
+}
@@ -15,6 +15,7 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -27,12 +28,19 @@ const (
 	bitgetProductType = "USDT-FUTURES"
 )
 
+// bitgetModeSymbol - символ запроса режима позиций: режим общий для всех контрактов USDT-FUTURES
+const bitgetModeSymbol = "BTCUSDT"
+
 type Bitget struct {
 	apiKey     string
 	secretKey  string
 	passphrase string
 
 	httpClient *http.Client
+	endpoints  Endpoints       // адреса API, подменяются SetEndpoints
+	limiter    *requestLimiter // лимиты REST запросов
+	clock      *Clock          // смещение часов биржи для подписи запросов
+	health     *ExchangeHealth // состояние биржи для отключения от входов
 
 	// WebSocket managers с автоматическим переподключением
 	wsPublicManager  *WSReconnectManager
@@ -41,20 +49,96 @@ type Bitget struct {
 
 	tickerCallbacks  map[string]func(*Ticker)
 	positionCallback func(*Position)
+	orderCallback    func(*Order)
 	callbackMu       sync.RWMutex
 
+	// Локальные стаканы SubscribeOrderBook
+	books orderBookSubscriptions
+
+	// Спецификации контрактов (размеры Bitget в монетах)
+	instruments *InstrumentRegistry
+
+	// Режим маржи передаётся в каждом ордере и должен совпадать с режимом символа
+	marginModes   map[string]string // symbol -> MarginModeCross / MarginModeIsolated
+	marginModesMu sync.RWMutex
+
+	// Режим позиций аккаунта: true - hedge (ордера с tradeSide open / close)
+	hedgeMode atomic.Bool
+
 	connected bool
 	closeChan chan struct{}
 }
 
+// init регистрирует адаптер Bitget в реестре бирж
+func init() {
+	Register(Adapter{
+		Name: "bitget",
+		New:  func() Exchange { return NewBitget() },
+		Capabilities: Capabilities{
+			PrivateWS:   true,
+			LimitOrders: true,
+			Funding:     true,
+			HedgeMode:   true,
+		},
+		RequiresPassphrase: true,
+		TakerFee:           0.0004,
+		MakerFee:           0.0002,
+		// 6000 запросов в минуту на IP, ордера - 10 в секунду на ключ
+		RateLimits: RateLimits{
+			Total:   100,
+			Orders:  10,
+			Market:  20,
+			Account: 5,
+			Endpoints: map[string]EndpointLimit{
+				"/api/v2/mix/order/": {Category: RateCategoryOrders},
+			},
+		},
+	})
+}
+
 // NewBitget создаёт новый экземпляр Bitget
 // Использует глобальный HTTP клиент с connection pooling и оптимизированными таймаутами
 func NewBitget() *Bitget {
-	return &Bitget{
+	b := &Bitget{
 		httpClient:      GetGlobalHTTPClient().GetClient(),
+		endpoints:       Endpoints{REST: bitgetBaseURL, WSPublic: bitgetWSPublic, WSPrivate: bitgetWSPrivate},
+		limiter:         newRequestLimiter("bitget"),
+		health:          HealthOf("bitget"),
 		tickerCallbacks: make(map[string]func(*Ticker)),
+		marginModes:     make(map[string]string),
 		closeChan:       make(chan struct{}),
 	}
+	b.instruments = NewInstrumentRegistry("bitget", b.loadInstruments)
+	b.clock = NewClock("bitget", b.getServerTime, "40008")
+	return b
+}
+
+// SetEndpoints подменяет адреса REST и WebSocket API
+func (b *Bitget) SetEndpoints(endpoints Endpoints) {
+	b.endpoints = endpoints
+}
+
+// getServerTime запрашивает время сервера для синхронизации часов
+func (b *Bitget) getServerTime(ctx context.Context) (time.Time, error) {
+	body, err := b.doRequest(ctx, http.MethodGet, "/api/v2/public/time", nil, false)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var resp struct {
+		Data struct {
+			ServerTime string `json:"serverTime"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return time.Time{}, err
+	}
+
+	millis, err := strconv.ParseInt(resp.Data.ServerTime, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid server time %q", resp.Data.ServerTime)
+	}
+	return time.UnixMilli(millis), nil
 }
 
 // sign создает подпись для Bitget API
@@ -92,7 +176,16 @@ func (b *Bitget) parseInt64(value, field string) int64 {
 	return result
 }
 
+// doRequest выполняет запрос к Bitget API и учитывает его в состоянии биржи
 func (b *Bitget) doRequest(ctx context.Context, method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
+	start := time.Now()
+	body, err := b.sendRequest(ctx, method, endpoint, params, signed)
+	b.health.ObserveRequest(time.Since(start), err)
+	return body, err
+}
+
+// sendRequest выполняет HTTP запрос к Bitget API
+func (b *Bitget) sendRequest(ctx context.Context, method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
 	var reqBody string
 	var reqURL string
 
@@ -103,12 +196,12 @@ func (b *Bitget) doRequest(ctx context.Context, method, endpoint string, params
 		}
 		queryStr := query.Encode()
 		if queryStr != "" {
-			reqURL = bitgetBaseURL + endpoint + "?" + queryStr
+			reqURL = b.endpoints.REST + endpoint + "?" + queryStr
 		} else {
-			reqURL = bitgetBaseURL + endpoint
+			reqURL = b.endpoints.REST + endpoint
 		}
 	} else {
-		reqURL = bitgetBaseURL + endpoint
+		reqURL = b.endpoints.REST + endpoint
 		if len(params) > 0 {
 			jsonBytes, _ := json.Marshal(params)
 			reqBody = string(jsonBytes)
@@ -123,7 +216,7 @@ func (b *Bitget) doRequest(ctx context.Context, method, endpoint string, params
 	req.Header.Set("Content-Type", "application/json")
 
 	if signed {
-		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
+		timestamp := strconv.FormatInt(b.clock.Now().UnixMilli(), 10)
 		var signPath string
 		if method == http.MethodGet && len(params) > 0 {
 			query := url.Values{}
@@ -142,11 +235,16 @@ func (b *Bitget) doRequest(ctx context.Context, method, endpoint string, params
 		req.Header.Set("ACCESS-PASSPHRASE", b.passphrase)
 	}
 
+	if err := b.limiter.Wait(ctx, endpoint, signed); err != nil {
+		return nil, err
+	}
+
 	resp, err := b.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	b.limiter.Observe(endpoint, signed, resp)
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -157,16 +255,16 @@ func (b *Bitget) doRequest(ctx context.Context, method, endpoint string, params
 		Code string `json:"code"`
 		Msg  string `json:"msg"`
 	}
-	if err := json.Unmarshal(body, &baseResp); err != nil {
-		return nil, err
+	if err := json.Unmarshal(body, &baseResp); err != nil || baseResp.Code == "" {
+		return nil, httpStatusError("bitget", resp.StatusCode, body, err)
 	}
 
 	if baseResp.Code != "00000" {
-		return nil, &ExchangeError{
+		return nil, b.limiter.CheckError(b.clock.CheckError(&ExchangeError{
 			Exchange: "bitget",
 			Code:     baseResp.Code,
 			Message:  baseResp.Msg,
-		}
+		}))
 	}
 
 	return body, nil
@@ -180,11 +278,20 @@ func (b *Bitget) Connect(apiKey, secret, passphrase string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Смещение часов нужно до первого подписанного запроса; при ошибке подпись идёт по локальному времени
+	if err := b.clock.Sync(ctx); err != nil {
+		log.Printf("[bitget] %v", err)
+	}
+
 	_, err := b.GetBalance(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to connect to Bitget: %w", err)
 	}
 
+	if err := b.detectPositionMode(ctx); err != nil {
+		log.Printf("[bitget] failed to detect position mode, using %s: %v", b.PositionMode(), err)
+	}
+
 	b.connected = true
 	return nil
 }
@@ -193,6 +300,77 @@ func (b *Bitget) GetName() string {
 	return "bitget"
 }
 
+// PositionMode возвращает режим позиций аккаунта
+func (b *Bitget) PositionMode() string {
+	if b.hedgeMode.Load() {
+		return PositionModeHedge
+	}
+	return PositionModeOneWay
+}
+
+// detectPositionMode определяет режим позиций аккаунта по /api/v2/mix/account/account
+func (b *Bitget) detectPositionMode(ctx context.Context) error {
+	params := map[string]string{
+		"productType": bitgetProductType,
+		"symbol":      bitgetModeSymbol,
+		"marginCoin":  "USDT",
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/api/v2/mix/account/account", params, true)
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Data struct {
+			PosMode string `json:"posMode"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+
+	switch resp.Data.PosMode {
+	case "hedge_mode":
+		b.hedgeMode.Store(true)
+	case "one_way_mode":
+		b.hedgeMode.Store(false)
+	default:
+		return fmt.Errorf("unknown position mode %q", resp.Data.PosMode)
+	}
+	return nil
+}
+
+// orderSideParams задаёт сторону ордера side (SideBuy / SideSell)
+// В режиме hedge side Bitget - сторона позиции, а tradeSide - открытие или закрытие:
+// закрытие лонга - buy + close. В режиме one-way закрытие помечается reduceOnly
+func (b *Bitget) orderSideParams(params map[string]string, side string, closing bool) {
+	bitgetSide := "buy"
+	if side == SideSell || side == SideShort {
+		bitgetSide = "sell"
+	}
+
+	if !b.hedgeMode.Load() {
+		params["side"] = bitgetSide
+		if closing {
+			params["reduceOnly"] = "YES"
+		}
+		return
+	}
+
+	params["tradeSide"] = "open"
+	if closing {
+		// Ордер закрытия адресован позиции противоположной стороны
+		params["tradeSide"] = "close"
+		if bitgetSide == "buy" {
+			bitgetSide = "sell"
+		} else {
+			bitgetSide = "buy"
+		}
+	}
+	params["side"] = bitgetSide
+}
+
 func (b *Bitget) GetBalance(ctx context.Context) (float64, error) {
 	params := map[string]string{
 		"productType": bitgetProductType,
@@ -284,11 +462,12 @@ func (b *Bitget) GetOrderBook(ctx context.Context, symbol string, depth int) (*O
 		return nil, err
 	}
 
+	// merge-depth отдаёт уровни числами, json.Number принимает и строки
 	var resp struct {
 		Data struct {
-			Bids [][]string `json:"bids"`
-			Asks [][]string `json:"asks"`
-			Ts   string     `json:"ts"`
+			Bids [][]json.Number `json:"bids"`
+			Asks [][]json.Number `json:"asks"`
+			Ts   string          `json:"ts"`
 		} `json:"data"`
 	}
 
@@ -305,14 +484,14 @@ func (b *Bitget) GetOrderBook(ctx context.Context, symbol string, depth int) (*O
 	}
 
 	for i, bid := range resp.Data.Bids {
-		price := b.parseFloat(bid[0], "bid.price")
-		volume := b.parseFloat(bid[1], "bid.volume")
+		price := b.parseFloat(bid[0].String(), "bid.price")
+		volume := b.parseFloat(bid[1].String(), "bid.volume")
 		orderBook.Bids[i] = PriceLevel{Price: price, Volume: volume}
 	}
 
 	for i, ask := range resp.Data.Asks {
-		price := b.parseFloat(ask[0], "ask.price")
-		volume := b.parseFloat(ask[1], "ask.volume")
+		price := b.parseFloat(ask[0].String(), "ask.price")
+		volume := b.parseFloat(ask[1].String(), "ask.volume")
 		orderBook.Asks[i] = PriceLevel{Price: price, Volume: volume}
 	}
 
@@ -326,23 +505,35 @@ func (b *Bitget) GetOrderBook(ctx context.Context, symbol string, depth int) (*O
 	return orderBook, nil
 }
 
-func (b *Bitget) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64) (*Order, error) {
-	bitgetSide := "buy"
-	tradeSide := "open"
-	if side == SideSell || side == SideShort {
-		bitgetSide = "sell"
+func (b *Bitget) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*Order, error) {
+	return b.placeMarketOrder(ctx, symbol, side, qty, clientOrderID, false)
+}
+
+// PlaceCloseOrder закрывает позицию стороны side рыночным ордером противоположной стороны
+func (b *Bitget) PlaceCloseOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*Order, error) {
+	return b.placeMarketOrder(ctx, symbol, CloseOrderSide(side), qty, clientOrderID, true)
+}
+
+// placeMarketOrder размещает рыночный ордер; closing - ордер закрытия позиции (см. orderSideParams)
+func (b *Bitget) placeMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string, closing bool) (*Order, error) {
+	inst, size, err := b.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return nil, err
 	}
+	qty = inst.FromVenueSize(size)
 
 	params := map[string]string{
 		"productType": bitgetProductType,
 		"symbol":      symbol,
-		"marginMode":  "crossed",
+		"marginMode":  b.orderMarginMode(symbol),
 		"marginCoin":  "USDT",
-		"side":        bitgetSide,
-		"tradeSide":   tradeSide,
 		"orderType":   "market",
-		"size":        strconv.FormatFloat(qty, 'f', -1, 64),
+		"size":        inst.FormatSize(size),
+	}
+	if clientOrderID != "" {
+		params["clientOid"] = clientOrderID
 	}
+	b.orderSideParams(params, side, closing)
 
 	body, err := b.doRequest(ctx, http.MethodPost, "/api/v2/mix/order/place-order", params, true)
 	if err != nil {
@@ -361,15 +552,16 @@ func (b *Bitget) PlaceMarketOrder(ctx context.Context, symbol, side string, qty
 	}
 
 	order := &Order{
-		ID:        resp.Data.OrderId,
-		Symbol:    symbol,
-		Side:      side,
-		Type:      "market",
-		Quantity:  qty,
-		FilledQty: qty,
-		Status:    OrderStatusFilled,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:            resp.Data.OrderId,
+		ClientOrderID: clientOrderID,
+		Symbol:        symbol,
+		Side:          side,
+		Type:          "market",
+		Quantity:      qty,
+		FilledQty:     qty,
+		Status:        OrderStatusFilled,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	// Получаем цену исполнения
@@ -484,42 +676,224 @@ func (b *Bitget) GetOpenPositions(ctx context.Context) ([]*Position, error) {
 }
 
 func (b *Bitget) ClosePosition(ctx context.Context, symbol, side string, qty float64) error {
-	closeSide := SideBuy
-	if side == SideLong || side == SideBuy {
-		closeSide = SideSell
+	_, err := b.PlaceCloseOrder(ctx, symbol, side, qty, "")
+	return err
+}
+
+// bitgetOrderInfo - ордер в ответах order/detail и orders-pending
+type bitgetOrderInfo struct {
+	Symbol     string `json:"symbol"`
+	OrderId    string `json:"orderId"`
+	ClientOid  string `json:"clientOid"`
+	Side       string `json:"side"`
+	OrderType  string `json:"orderType"`
+	Force      string `json:"force"`
+	Price      string `json:"price"`
+	Size       string `json:"size"`
+	BaseVolume string `json:"baseVolume"`
+	PriceAvg   string `json:"priceAvg"`
+	Fee        string `json:"fee"`    // отрицательная - списанная комиссия
+	State      string `json:"state"`  // order/detail
+	Status     string `json:"status"` // orders-pending
+	CTime      string `json:"cTime"`
+	UTime      string `json:"uTime"`
+}
+
+func (b *Bitget) PlaceLimitOrder(ctx context.Context, symbol, side string, qty, price float64, tif string) (*Order, error) {
+	tif, err := normalizeTimeInForce(tif)
+	if err != nil {
+		return nil, err
 	}
 
+	inst, size, err := b.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return nil, err
+	}
+	qty = inst.FromVenueSize(size)
+
 	params := map[string]string{
 		"productType": bitgetProductType,
 		"symbol":      symbol,
-		"marginMode":  "crossed",
+		"marginMode":  b.orderMarginMode(symbol),
 		"marginCoin":  "USDT",
-		"side":        closeSide,
-		"tradeSide":   "close",
-		"orderType":   "market",
-		"size":        strconv.FormatFloat(qty, 'f', -1, 64),
+		"orderType":   "limit",
+		"force":       tif, // gtc, ioc, fok, post_only совпадают с нашими значениями
+		"size":        inst.FormatSize(size),
+		"price":       strconv.FormatFloat(price, 'f', -1, 64),
 	}
+	b.orderSideParams(params, side, false)
 
-	_, err := b.doRequest(ctx, http.MethodPost, "/api/v2/mix/order/place-order", params, true)
+	body, err := b.doRequest(ctx, http.MethodPost, "/api/v2/mix/order/place-order", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data struct {
+			OrderId string `json:"orderId"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	// Получаем состояние ордера (IOC/FOK к этому моменту уже исполнены или отменены)
+	order, err := b.GetOrder(ctx, symbol, resp.Data.OrderId)
+	if err != nil {
+		return newLimitOrder(resp.Data.OrderId, symbol, side, qty, price, tif), nil
+	}
+	order.Side = side
+	return order, nil
+}
+
+func (b *Bitget) CancelOrder(ctx context.Context, symbol, orderID string) error {
+	params := map[string]string{
+		"productType": bitgetProductType,
+		"symbol":      symbol,
+		"orderId":     orderID,
+	}
+
+	_, err := b.doRequest(ctx, http.MethodPost, "/api/v2/mix/order/cancel-order", params, true)
 	return err
 }
 
+func (b *Bitget) GetOrder(ctx context.Context, symbol, orderID string) (*Order, error) {
+	return b.queryOrder(ctx, symbol, "orderId", orderID)
+}
+
+func (b *Bitget) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*Order, error) {
+	return b.queryOrder(ctx, symbol, "clientOid", clientOrderID)
+}
+
+// queryOrder запрашивает ордер по orderId или clientOid
+// 40109: биржа не знает ордер
+func (b *Bitget) queryOrder(ctx context.Context, symbol, key, id string) (*Order, error) {
+	params := map[string]string{
+		"productType": bitgetProductType,
+		"symbol":      symbol,
+		key:           id,
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/api/v2/mix/order/detail", params, true)
+	if err != nil {
+		return nil, orderNotFound(err, "40109")
+	}
+
+	var resp struct {
+		Data bitgetOrderInfo `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Data.OrderId == "" {
+		return nil, fmt.Errorf("order %s: %w", id, ErrOrderNotFound)
+	}
+	return b.parseOrder(resp.Data), nil
+}
+
+func (b *Bitget) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	params := map[string]string{
+		"productType": bitgetProductType,
+	}
+	if symbol != "" {
+		params["symbol"] = symbol
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/api/v2/mix/order/orders-pending", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data struct {
+			EntrustedList []bitgetOrderInfo `json:"entrustedList"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	orders := make([]*Order, 0, len(resp.Data.EntrustedList))
+	for _, info := range resp.Data.EntrustedList {
+		orders = append(orders, b.parseOrder(info))
+	}
+	return orders, nil
+}
+
+// parseOrder конвертирует ордер Bitget в Order
+func (b *Bitget) parseOrder(info bitgetOrderInfo) *Order {
+	order := &Order{
+		ID:            info.OrderId,
+		ClientOrderID: info.ClientOid,
+		Symbol:        info.Symbol,
+		Side:          info.Side,
+		Type:          info.OrderType,
+		Price:         b.parseFloat(info.Price, "order.price"),
+		Quantity:      b.parseFloat(info.Size, "order.size"),
+		FilledQty:     b.parseFloat(info.BaseVolume, "order.baseVolume"),
+		AvgFillPrice:  b.parseFloat(info.PriceAvg, "order.priceAvg"),
+		Fee:           -b.parseFloat(info.Fee, "order.fee"),
+		CreatedAt:     time.UnixMilli(b.parseInt64(info.CTime, "order.cTime")),
+		UpdatedAt:     time.UnixMilli(b.parseInt64(info.UTime, "order.uTime")),
+	}
+
+	if info.OrderType == OrderTypeLimit {
+		order.TimeInForce, _ = normalizeTimeInForce(info.Force)
+	}
+
+	state := info.State
+	if state == "" {
+		state = info.Status
+	}
+
+	switch state {
+	case "live", "new", "init":
+		order.Status = OrderStatusNew
+	case "partially_filled":
+		order.Status = OrderStatusPartial
+	case "filled":
+		order.Status = OrderStatusFilled
+	default: // canceled, cancelled
+		order.Status = OrderStatusCancelled
+	}
+
+	return order
+}
+
 func (b *Bitget) SubscribeTicker(symbol string, callback func(*Ticker)) error {
 	b.callbackMu.Lock()
 	b.tickerCallbacks[symbol] = callback
 	b.callbackMu.Unlock()
 
+	return b.subscribePublic("ticker", symbol)
+}
+
+// SubscribeOrderBook подписывается на канал books (полный стакан, контрольная сумма CRC32)
+func (b *Bitget) SubscribeOrderBook(symbol string, depth int, callback func(*OrderBook)) error {
+	b.books.add(symbol, depth, nil, callback)
+	return b.subscribePublic("books", symbol)
+}
+
+// subscribePublic подписывается на канал USDT-FUTURES публичного WebSocket, подключаясь при необходимости
+func (b *Bitget) subscribePublic(channel, symbol string) error {
 	// Защита от race condition при инициализации WebSocket manager
 	b.wsMu.Lock()
 	if b.wsPublicManager == nil {
 		config := DefaultWSReconnectConfig()
-		b.wsPublicManager = NewWSReconnectManager("bitget-public", bitgetWSPublic, config)
+		b.wsPublicManager = NewWSReconnectManager("bitget-public", b.endpoints.WSPublic, config)
+		b.health.TrackWS(b.wsPublicManager)
 
 		b.wsPublicManager.SetOnMessage(b.handlePublicMessage)
 		b.wsPublicManager.SetOnConnect(func() {
 			log.Printf("[bitget] Public WebSocket connected")
 		})
+		// Стаканы ждут нового снимка, который придёт после переподписки
 		b.wsPublicManager.SetOnDisconnect(func(err error) {
+			b.books.invalidateAll()
 			if err != nil {
 				log.Printf("[bitget] Public WebSocket disconnected: %v", err)
 			}
@@ -534,20 +908,25 @@ func (b *Bitget) SubscribeTicker(symbol string, callback func(*Ticker)) error {
 	b.wsMu.Unlock()
 
 	subMsg := map[string]interface{}{
-		"op": "subscribe",
-		"args": []map[string]string{
-			{
-				"instType": "USDT-FUTURES",
-				"channel":  "ticker",
-				"instId":   symbol,
-			},
-		},
+		"op":   "subscribe",
+		"args": b.publicArgs(channel, symbol),
 	}
 
 	wsManager.AddSubscription(subMsg)
 	return wsManager.Send(subMsg)
 }
 
+// publicArgs возвращает аргументы подписки на публичный канал
+func (b *Bitget) publicArgs(channel, symbol string) []map[string]string {
+	return []map[string]string{
+		{
+			"instType": "USDT-FUTURES",
+			"channel":  channel,
+			"instId":   symbol,
+		},
+	}
+}
+
 // handlePublicMessage обрабатывает одно сообщение из публичного WebSocket
 func (b *Bitget) handlePublicMessage(message []byte) {
 	var msg struct {
@@ -556,40 +935,116 @@ func (b *Bitget) handlePublicMessage(message []byte) {
 			Channel string `json:"channel"`
 			InstId  string `json:"instId"`
 		} `json:"arg"`
-		Data []struct {
-			BidPr  string `json:"bidPr"`
-			AskPr  string `json:"askPr"`
-			LastPr string `json:"lastPr"`
-			Ts     string `json:"ts"`
-		} `json:"data"`
+		Data json.RawMessage `json:"data"`
 	}
 
-	if err := json.Unmarshal(message, &msg); err != nil {
+	if err := json.Unmarshal(message, &msg); err != nil || len(msg.Data) == 0 {
+		return
+	}
+
+	switch msg.Arg.Channel {
+	case "ticker":
+		b.handleTickerUpdate(msg.Arg.InstId, msg.Data)
+	case "books":
+		b.handleOrderBookUpdate(msg.Arg.InstId, msg.Action, msg.Data)
+	}
+}
+
+// handleTickerUpdate обрабатывает канал ticker
+func (b *Bitget) handleTickerUpdate(symbol string, data json.RawMessage) {
+	var tickers []struct {
+		BidPr  string `json:"bidPr"`
+		AskPr  string `json:"askPr"`
+		LastPr string `json:"lastPr"`
+		Ts     string `json:"ts"`
+	}
+
+	if err := json.Unmarshal(data, &tickers); err != nil || len(tickers) == 0 {
 		return
 	}
 
-	if msg.Arg.Channel == "ticker" && len(msg.Data) > 0 {
-		symbol := msg.Arg.InstId
+	b.callbackMu.RLock()
+	callback, ok := b.tickerCallbacks[symbol]
+	b.callbackMu.RUnlock()
 
-		b.callbackMu.RLock()
-		callback, ok := b.tickerCallbacks[symbol]
-		b.callbackMu.RUnlock()
+	if ok && callback != nil {
+		d := tickers[0]
+		bidPrice := b.parseFloat(d.BidPr, "ws.ticker.bidPr")
+		askPrice := b.parseFloat(d.AskPr, "ws.ticker.askPr")
+		lastPrice := b.parseFloat(d.LastPr, "ws.ticker.lastPr")
+		ts := b.parseInt64(d.Ts, "ws.ticker.ts")
 
-		if ok && callback != nil {
-			d := msg.Data[0]
-			bidPrice := b.parseFloat(d.BidPr, "ws.ticker.bidPr")
-			askPrice := b.parseFloat(d.AskPr, "ws.ticker.askPr")
-			lastPrice := b.parseFloat(d.LastPr, "ws.ticker.lastPr")
-			ts := b.parseInt64(d.Ts, "ws.ticker.ts")
+		callback(&Ticker{
+			Symbol:    symbol,
+			BidPrice:  bidPrice,
+			AskPrice:  askPrice,
+			LastPrice: lastPrice,
+			Timestamp: time.UnixMilli(ts),
+		})
+	}
+}
 
-			callback(&Ticker{
-				Symbol:    symbol,
-				BidPrice:  bidPrice,
-				AskPrice:  askPrice,
-				LastPrice: lastPrice,
-				Timestamp: time.UnixMilli(ts),
-			})
+// handleOrderBookUpdate применяет снимок или обновление канала books
+// Bitget не сообщает номер предыдущего обновления - seq должен только расти
+func (b *Bitget) handleOrderBookUpdate(symbol, action string, data json.RawMessage) {
+	var books []struct {
+		Asks     [][]string `json:"asks"`
+		Bids     [][]string `json:"bids"`
+		Checksum int32      `json:"checksum"`
+		Seq      int64      `json:"seq"`
+		Ts       string     `json:"ts"`
+	}
+
+	if err := json.Unmarshal(data, &books); err != nil || len(books) == 0 {
+		return
+	}
+
+	sub := b.books.get(symbol)
+	if sub == nil {
+		return
+	}
+
+	d := books[0]
+	bids, asks := stringBookLevels(d.Bids), stringBookLevels(d.Asks)
+	ts := time.UnixMilli(b.parseInt64(d.Ts, "ws.books.ts"))
+
+	var err error
+	if action == "snapshot" {
+		sub.book.Reset(bids, asks, d.Seq, ts)
+	} else {
+		err = sub.book.Apply(bids, asks, -1, d.Seq, ts)
+	}
+	if err == nil {
+		err = sub.book.VerifyChecksum(d.Checksum)
+	}
+
+	if err != nil {
+		if err != ErrOrderBookNotSynced {
+			log.Printf("[bitget] books %s: %v, resubscribing", symbol, err)
+			b.resubscribePublic("books", symbol)
 		}
+		return
+	}
+
+	sub.emit()
+}
+
+// resubscribePublic переподписывается на канал, чтобы получить новый снимок
+func (b *Bitget) resubscribePublic(channel, symbol string) {
+	b.wsMu.Lock()
+	wsManager := b.wsPublicManager
+	b.wsMu.Unlock()
+
+	if wsManager == nil {
+		return
+	}
+
+	args := b.publicArgs(channel, symbol)
+	if err := wsManager.Send(map[string]interface{}{"op": "unsubscribe", "args": args}); err != nil {
+		return
+	}
+	if err := wsManager.Send(map[string]interface{}{"op": "subscribe", "args": args}); err != nil {
+		log.Printf("[bitget] resubscribe %s %s failed: %v", channel, symbol, err)
 	}
 }
 
@@ -598,11 +1053,26 @@ func (b *Bitget) SubscribePositions(callback func(*Position)) error {
 	b.positionCallback = callback
 	b.callbackMu.Unlock()
 
+	return b.subscribePrivate("positions")
+}
+
+// SubscribeOrders подписывается на канал orders приватного WebSocket
+func (b *Bitget) SubscribeOrders(callback func(*Order)) error {
+	b.callbackMu.Lock()
+	b.orderCallback = callback
+	b.callbackMu.Unlock()
+
+	return b.subscribePrivate("orders")
+}
+
+// subscribePrivate подписывается на канал USDT-FUTURES приватного WebSocket, подключаясь при необходимости
+func (b *Bitget) subscribePrivate(channel string) error {
 	// Защита от race condition при инициализации WebSocket manager
 	b.wsMu.Lock()
 	if b.wsPrivateManager == nil {
 		config := DefaultWSReconnectConfig()
-		b.wsPrivateManager = NewWSReconnectManager("bitget-private", bitgetWSPrivate, config)
+		b.wsPrivateManager = NewWSReconnectManager("bitget-private", b.endpoints.WSPrivate, config)
+		b.health.TrackWS(b.wsPrivateManager)
 
 		b.wsPrivateManager.SetAuthFunc(b.authenticateWebSocket)
 		b.wsPrivateManager.SetOnMessage(b.handlePrivateMessage)
@@ -616,6 +1086,7 @@ func (b *Bitget) SubscribePositions(callback func(*Position)) error {
 		})
 
 		if err := b.wsPrivateManager.Connect(); err != nil {
+			b.wsPrivateManager = nil
 			b.wsMu.Unlock()
 			return fmt.Errorf("failed to connect to private WebSocket: %w", err)
 		}
@@ -628,7 +1099,7 @@ func (b *Bitget) SubscribePositions(callback func(*Position)) error {
 		"args": []map[string]string{
 			{
 				"instType": "USDT-FUTURES",
-				"channel":  "positions",
+				"channel":  channel,
 				"instId":   "default",
 			},
 		},
@@ -639,7 +1110,7 @@ func (b *Bitget) SubscribePositions(callback func(*Position)) error {
 }
 
 func (b *Bitget) authenticateWebSocket(conn *websocket.Conn) error {
-	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	timestamp := strconv.FormatInt(b.clock.Now().Unix(), 10)
 	message := timestamp + "GET" + "/user/verify"
 	h := hmac.New(sha256.New, []byte(b.secretKey))
 	h.Write([]byte(message))
@@ -688,66 +1159,180 @@ func (b *Bitget) handlePrivateMessage(message []byte) {
 		Arg struct {
 			Channel string `json:"channel"`
 		} `json:"arg"`
-		Data []struct {
-			InstId       string `json:"instId"`
-			HoldSide     string `json:"holdSide"`
-			Total        string `json:"total"`
-			OpenPriceAvg string `json:"openPriceAvg"`
-			MarkPrice    string `json:"markPrice"`
-			Leverage     string `json:"leverage"`
-			UnrealizedPL string `json:"unrealizedPL"`
-			UTime        string `json:"uTime"`
-		} `json:"data"`
+		Data json.RawMessage `json:"data"`
 	}
 
 	if err := json.Unmarshal(message, &msg); err != nil {
 		return
 	}
 
-	if msg.Arg.Channel == "positions" {
-		b.callbackMu.RLock()
-		callback := b.positionCallback
-		b.callbackMu.RUnlock()
-
-		if callback != nil {
-			for _, p := range msg.Data {
-				size := b.parseFloat(p.Total, "ws.position.total")
-				entryPrice := b.parseFloat(p.OpenPriceAvg, "ws.position.openPriceAvg")
-				markPrice := b.parseFloat(p.MarkPrice, "ws.position.markPrice")
-				leverage := b.parseInt(p.Leverage, "ws.position.leverage")
-				unrealizedPnl := b.parseFloat(p.UnrealizedPL, "ws.position.unrealizedPL")
-				uTime := b.parseInt64(p.UTime, "ws.position.uTime")
-
-				side := SideLong
-				if p.HoldSide == "short" {
-					side = SideShort
-				}
-
-				callback(&Position{
-					Symbol:        p.InstId,
-					Side:          side,
-					Size:          size,
-					EntryPrice:    entryPrice,
-					MarkPrice:     markPrice,
-					Leverage:      leverage,
-					UnrealizedPnl: unrealizedPnl,
-					Liquidation:   false,
-					UpdatedAt:     time.UnixMilli(uTime),
-				})
-			}
+	switch msg.Arg.Channel {
+	case "positions":
+		b.handlePositionUpdate(msg.Data)
+	case "orders":
+		b.handleOrderUpdate(msg.Data)
+	}
+}
+
+// handlePositionUpdate отправляет обновления позиций в callback
+func (b *Bitget) handlePositionUpdate(data json.RawMessage) {
+	var positions []struct {
+		InstId       string `json:"instId"`
+		HoldSide     string `json:"holdSide"`
+		Total        string `json:"total"`
+		OpenPriceAvg string `json:"openPriceAvg"`
+		MarkPrice    string `json:"markPrice"`
+		Leverage     string `json:"leverage"`
+		UnrealizedPL string `json:"unrealizedPL"`
+		UTime        string `json:"uTime"`
+	}
+
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return
+	}
+
+	b.callbackMu.RLock()
+	callback := b.positionCallback
+	b.callbackMu.RUnlock()
+
+	if callback == nil {
+		return
+	}
+
+	for _, p := range positions {
+		size := b.parseFloat(p.Total, "ws.position.total")
+		entryPrice := b.parseFloat(p.OpenPriceAvg, "ws.position.openPriceAvg")
+		markPrice := b.parseFloat(p.MarkPrice, "ws.position.markPrice")
+		leverage := b.parseInt(p.Leverage, "ws.position.leverage")
+		unrealizedPnl := b.parseFloat(p.UnrealizedPL, "ws.position.unrealizedPL")
+		uTime := b.parseInt64(p.UTime, "ws.position.uTime")
+
+		side := SideLong
+		if p.HoldSide == "short" {
+			side = SideShort
+		}
+
+		callback(&Position{
+			Symbol:        p.InstId,
+			Side:          side,
+			Size:          size,
+			EntryPrice:    entryPrice,
+			MarkPrice:     markPrice,
+			Leverage:      leverage,
+			UnrealizedPnl: unrealizedPnl,
+			Liquidation:   false,
+			UpdatedAt:     time.UnixMilli(uTime),
+		})
+	}
+}
+
+// handleOrderUpdate отправляет обновления ордеров в callback
+// Поля канала orders отличаются от REST: instId, accBaseVolume, feeDetail
+func (b *Bitget) handleOrderUpdate(data json.RawMessage) {
+	var orders []struct {
+		bitgetOrderInfo
+		InstId        string `json:"instId"`
+		AccBaseVolume string `json:"accBaseVolume"`
+		FeeDetail     []struct {
+			Fee string `json:"fee"`
+		} `json:"feeDetail"`
+	}
+
+	if err := json.Unmarshal(data, &orders); err != nil {
+		return
+	}
+
+	b.callbackMu.RLock()
+	callback := b.orderCallback
+	b.callbackMu.RUnlock()
+
+	if callback == nil {
+		return
+	}
+
+	for _, o := range orders {
+		info := o.bitgetOrderInfo
+		info.Symbol = o.InstId
+		info.BaseVolume = o.AccBaseVolume
+
+		order := b.parseOrder(info)
+		for _, fee := range o.FeeDetail {
+			order.Fee -= b.parseFloat(fee.Fee, "ws.order.fee")
 		}
+		callback(order)
 	}
 }
 
 func (b *Bitget) GetTradingFee(ctx context.Context, symbol string) (float64, error) {
-	// Bitget стандартная комиссия тейкера 0.04%
-	return 0.0004, nil
+	fees, err := b.GetTradingFees(ctx, symbol)
+	if err != nil {
+		// Bitget стандартная комиссия тейкера 0.04%
+		return 0.0004, nil
+	}
+	return fees.Taker, nil
+}
+
+// GetTradingFees получает комиссии аккаунта из /api/v2/common/trade-rate
+// Ставки уже учитывают VIP-уровень аккаунта
+func (b *Bitget) GetTradingFees(ctx context.Context, symbol string) (*TradingFees, error) {
+	params := map[string]string{
+		"symbol":       symbol,
+		"businessType": "mix",
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/api/v2/common/trade-rate", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data struct {
+			MakerFeeRate string `json:"makerFeeRate"`
+			TakerFeeRate string `json:"takerFeeRate"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if resp.Data.TakerFeeRate == "" {
+		return nil, fmt.Errorf("trade rate not found for %s", symbol)
+	}
+
+	return &TradingFees{
+		Symbol:    symbol,
+		Maker:     b.parseFloat(resp.Data.MakerFeeRate, "makerFeeRate"),
+		Taker:     b.parseFloat(resp.Data.TakerFeeRate, "takerFeeRate"),
+		Timestamp: time.Now(),
+	}, nil
 }
 
 func (b *Bitget) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
+	inst, err := b.GetInstrument(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return inst.Limits(), nil
+}
+
+func (b *Bitget) GetInstrument(ctx context.Context, symbol string) (*Instrument, error) {
+	return b.instruments.Get(ctx, symbol)
+}
+
+// bitgetInstrumentStatus - соответствие symbolStatus Bitget статусам Instrument
+var bitgetInstrumentStatus = map[string]string{
+	"normal":        InstrumentStatusTrading,
+	"maintain":      InstrumentStatusSuspended,
+	"limit_open":    InstrumentStatusSuspended,
+	"restrictedAPI": InstrumentStatusSuspended,
+}
+
+// loadInstruments загружает спецификации всех USDT бессрочных контрактов
+// Размер ордера Bitget в монетах, кратный sizeMultiplier
+func (b *Bitget) loadInstruments(ctx context.Context) ([]*Instrument, error) {
 	params := map[string]string{
 		"productType": bitgetProductType,
-		"symbol":      symbol,
 	}
 
 	body, err := b.doRequest(ctx, http.MethodGet, "/api/v2/mix/market/contracts", params, false)
@@ -757,13 +1342,16 @@ func (b *Bitget) GetLimits(ctx context.Context, symbol string) (*Limits, error)
 
 	var resp struct {
 		Data []struct {
-			Symbol        string `json:"symbol"`
-			MinTradeNum   string `json:"minTradeNum"`
-			MaxPositionNum string `json:"maxPositionNum"`
+			Symbol         string `json:"symbol"`
+			MinTradeNum    string `json:"minTradeNum"`
+			MinTradeUSDT   string `json:"minTradeUSDT"`
+			MaxOrderQty    string `json:"maxOrderQty"`
 			SizeMultiplier string `json:"sizeMultiplier"`
-			PricePlace    string `json:"pricePlace"`
-			VolPlace      string `json:"volPlace"`
-			MaxLever      string `json:"maxLever"`
+			PricePlace     string `json:"pricePlace"`
+			PriceEndStep   string `json:"priceEndStep"`
+			VolumePlace    string `json:"volumePlace"`
+			MaxLever       string `json:"maxLever"`
+			SymbolStatus   string `json:"symbolStatus"`
 		} `json:"data"`
 	}
 
@@ -771,39 +1359,230 @@ func (b *Bitget) GetLimits(ctx context.Context, symbol string) (*Limits, error)
 		return nil, err
 	}
 
-	if len(resp.Data) == 0 {
-		return nil, fmt.Errorf("contract info not found for %s", symbol)
+	instruments := make([]*Instrument, 0, len(resp.Data))
+	for _, info := range resp.Data {
+		lotSize := b.parseFloat(info.SizeMultiplier, "instrument.sizeMultiplier")
+		if lotSize <= 0 {
+			lotSize = precisionStep(b.parseInt(info.VolumePlace, "instrument.volumePlace"))
+		}
+
+		// Шаг цены: priceEndStep единиц последнего знака pricePlace
+		tickSize := precisionStep(b.parseInt(info.PricePlace, "instrument.pricePlace"))
+		if info.PriceEndStep != "" {
+			tickSize *= b.parseFloat(info.PriceEndStep, "instrument.priceEndStep")
+		}
+
+		minNotional := 5.0
+		if info.MinTradeUSDT != "" {
+			minNotional = b.parseFloat(info.MinTradeUSDT, "instrument.minTradeUSDT")
+		}
+
+		status, ok := bitgetInstrumentStatus[info.SymbolStatus]
+		if !ok {
+			status = InstrumentStatusDelisted
+		}
+
+		instruments = append(instruments, &Instrument{
+			Symbol:         info.Symbol,
+			VenueSymbol:    info.Symbol,
+			ContractValue:  1,
+			LotSize:        lotSize,
+			MinSize:        b.parseFloat(info.MinTradeNum, "instrument.minTradeNum"),
+			MaxSize:        b.parseFloat(info.MaxOrderQty, "instrument.maxOrderQty"),
+			TickSize:       tickSize,
+			MinNotional:    minNotional,
+			MaxLeverage:    b.parseInt(info.MaxLever, "instrument.maxLever"),
+			SettleCurrency: "USDT",
+			Status:         status,
+		})
 	}
 
-	info := resp.Data[0]
-	minOrderQty := b.parseFloat(info.MinTradeNum, "limits.minTradeNum")
-	maxOrderQty := b.parseFloat(info.MaxPositionNum, "limits.maxPositionNum")
-	sizeMultiplier := b.parseFloat(info.SizeMultiplier, "limits.sizeMultiplier")
-	pricePlace := b.parseInt(info.PricePlace, "limits.pricePlace")
-	volPlace := b.parseInt(info.VolPlace, "limits.volPlace")
-	maxLeverage := b.parseInt(info.MaxLever, "limits.maxLever")
+	return instruments, nil
+}
 
-	qtyStep := 1.0
-	for i := 0; i < volPlace; i++ {
-		qtyStep /= 10
+func (b *Bitget) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	params := map[string]string{
+		"productType": bitgetProductType,
+		"symbol":      symbol,
 	}
 
-	priceStep := 1.0
-	for i := 0; i < pricePlace; i++ {
-		priceStep /= 10
+	body, err := b.doRequest(ctx, http.MethodGet, "/api/v2/mix/market/current-fund-rate", params, false)
+	if err != nil {
+		return nil, err
 	}
 
-	return &Limits{
-		Symbol:      symbol,
-		MinOrderQty: minOrderQty * sizeMultiplier,
-		MaxOrderQty: maxOrderQty * sizeMultiplier,
-		QtyStep:     qtyStep,
-		MinNotional: 5.0,
-		PriceStep:   priceStep,
-		MaxLeverage: maxLeverage,
+	var resp struct {
+		Data []struct {
+			FundingRate         string `json:"fundingRate"`
+			FundingRateInterval string `json:"fundingRateInterval"`
+			NextUpdate          string `json:"nextUpdate"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("funding rate not found for %s", symbol)
+	}
+
+	d := resp.Data[0]
+
+	// fundingRateInterval - период в часах
+	interval := DefaultFundingInterval
+	if hours := b.parseInt(d.FundingRateInterval, "fundingRateInterval"); hours > 0 {
+		interval = time.Duration(hours) * time.Hour
+	}
+
+	return &FundingRate{
+		Symbol:          symbol,
+		Rate:            b.parseFloat(d.FundingRate, "fundingRate"),
+		NextFundingTime: time.UnixMilli(b.parseInt64(d.NextUpdate, "nextUpdate")),
+		Interval:        interval,
+		Timestamp:       time.Now(),
 	}, nil
 }
 
+// SetLeverage устанавливает плечо символа (без holdSide - для обеих сторон)
+func (b *Bitget) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if err := validateLeverage(leverage); err != nil {
+		return err
+	}
+
+	params := map[string]string{
+		"productType": bitgetProductType,
+		"symbol":      symbol,
+		"marginCoin":  "USDT",
+		"leverage":    strconv.Itoa(leverage),
+	}
+
+	_, err := b.doRequest(ctx, http.MethodPost, "/api/v2/mix/account/set-leverage", params, true)
+	return err
+}
+
+// SetMarginMode устанавливает режим маржи символа и запоминает его для ордеров
+func (b *Bitget) SetMarginMode(ctx context.Context, symbol, mode string) error {
+	if err := validateMarginMode(mode); err != nil {
+		return err
+	}
+
+	marginMode := "crossed"
+	if mode == MarginModeIsolated {
+		marginMode = "isolated"
+	}
+
+	params := map[string]string{
+		"productType": bitgetProductType,
+		"symbol":      symbol,
+		"marginCoin":  "USDT",
+		"marginMode":  marginMode,
+	}
+
+	if _, err := b.doRequest(ctx, http.MethodPost, "/api/v2/mix/account/set-margin-mode", params, true); err != nil {
+		return err
+	}
+
+	b.marginModesMu.Lock()
+	b.marginModes[symbol] = mode
+	b.marginModesMu.Unlock()
+	return nil
+}
+
+// orderMarginMode возвращает режим маржи символа в формате Bitget (по умолчанию crossed)
+func (b *Bitget) orderMarginMode(symbol string) string {
+	b.marginModesMu.RLock()
+	mode := b.marginModes[symbol]
+	b.marginModesMu.RUnlock()
+
+	if mode == MarginModeIsolated {
+		return "isolated"
+	}
+	return "crossed"
+}
+
+// GetMarginSettings читает плечо и режим маржи символа из /api/v2/mix/account/account
+func (b *Bitget) GetMarginSettings(ctx context.Context, symbol string) (int, string, error) {
+	params := map[string]string{
+		"productType": bitgetProductType,
+		"symbol":      symbol,
+		"marginCoin":  "USDT",
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/api/v2/mix/account/account", params, true)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var resp struct {
+		Data struct {
+			MarginMode            string      `json:"marginMode"`
+			CrossedMarginLeverage json.Number `json:"crossedMarginLeverage"`
+			IsolatedLongLever     json.Number `json:"isolatedLongLever"`
+			IsolatedShortLever    json.Number `json:"isolatedShortLever"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, "", err
+	}
+
+	d := resp.Data
+	if d.MarginMode == "isolated" {
+		leverage, err := commonLeverage(symbol,
+			b.parseInt(d.IsolatedLongLever.String(), "account.isolatedLongLever"),
+			b.parseInt(d.IsolatedShortLever.String(), "account.isolatedShortLever"))
+		if err != nil {
+			return 0, "", err
+		}
+		return leverage, MarginModeIsolated, nil
+	}
+	return b.parseInt(d.CrossedMarginLeverage.String(), "account.crossedMarginLeverage"), MarginModeCross, nil
+}
+
+// GetFundingPayments читает начисления фандинга символа из /api/v2/mix/account/bill
+// (contract_settle_fee)
+func (b *Bitget) GetFundingPayments(ctx context.Context, symbol string, from, to time.Time) ([]FundingPayment, error) {
+	params := map[string]string{
+		"productType":  bitgetProductType,
+		"symbol":       symbol,
+		"businessType": "contract_settle_fee",
+		"startTime":    strconv.FormatInt(from.UnixMilli(), 10),
+		"endTime":      strconv.FormatInt(to.UnixMilli(), 10),
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/api/v2/mix/account/bill", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data struct {
+			Bills []struct {
+				Symbol       string `json:"symbol"`
+				BusinessType string `json:"businessType"`
+				Amount       string `json:"amount"`
+				CTime        string `json:"cTime"`
+			} `json:"bills"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	payments := make([]FundingPayment, 0, len(resp.Data.Bills))
+	for _, r := range resp.Data.Bills {
+		if r.Symbol != symbol || r.BusinessType != "contract_settle_fee" {
+			continue
+		}
+		payments = append(payments, FundingPayment{
+			Symbol: symbol,
+			Amount: b.parseFloat(r.Amount, "bill.amount"),
+			Time:   time.UnixMilli(b.parseInt64(r.CTime, "bill.cTime")),
+		})
+	}
+	return fundingPaymentsBetween(payments, from, to), nil
+}
+
 func (b *Bitget) Close() error {
 	select {
 	case <-b.closeChan:
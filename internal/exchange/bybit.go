@@ -6,6 +6,7 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log"
@@ -15,16 +16,16 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
 )
 
 const (
-	bybitBaseURL     = "https://api.bybit.com"
-	bybitWSPublic    = "wss://stream.bybit.com/v5/public/linear"
-	bybitWSPrivate   = "wss://stream.bybit.com/v5/private"
-	bybitRecvWindow  = "5000"
+	bybitBaseURL   = "https://api.bybit.com"
+	bybitWSPublic  = "wss://stream.bybit.com/v5/public/linear"
+	bybitWSPrivate = "wss://stream.bybit.com/v5/private"
 )
 
 // parseFloat парсит строку в float64 с логированием ошибок
@@ -60,6 +61,10 @@ type Bybit struct {
 	secretKey string
 
 	httpClient *http.Client
+	endpoints  Endpoints       // адреса API, подменяются SetEndpoints
+	limiter    *requestLimiter // лимиты REST запросов
+	clock      *Clock          // смещение часов биржи для подписи запросов
+	health     *ExchangeHealth // состояние биржи для отключения от входов
 
 	// WebSocket managers с автоматическим переподключением
 	wsPublicManager  *WSReconnectManager
@@ -69,33 +74,113 @@ type Bybit struct {
 	// Callbacks
 	tickerCallbacks  map[string]func(*Ticker)
 	positionCallback func(*Position)
+	orderCallback    func(*Order)
 	callbackMu       sync.RWMutex
 
+	// Локальные стаканы SubscribeOrderBook
+	books orderBookSubscriptions
+
+	// Спецификации контрактов (размеры Bybit в монетах)
+	instruments *InstrumentRegistry
+
+	// Режим позиций аккаунта: true - hedge (positionIdx 1 - лонг, 2 - шорт)
+	hedgeMode atomic.Bool
+
 	// State
 	connected bool
 	closeChan chan struct{}
 }
 
+// init регистрирует адаптер Bybit в реестре бирж
+func init() {
+	Register(Adapter{
+		Name: "bybit",
+		New:  func() Exchange { return NewBybit() },
+		Capabilities: Capabilities{
+			PrivateWS:   true,
+			LimitOrders: true,
+			Funding:     true,
+			HedgeMode:   true,
+		},
+		TakerFee: 0.00055,
+		MakerFee: 0.0002,
+		// 600 запросов за 5 секунд на IP, ордера и аккаунт - 10 в секунду на эндпоинт
+		RateLimits: RateLimits{
+			Total:   120,
+			Orders:  10,
+			Account: 10,
+			Endpoints: map[string]EndpointLimit{
+				"/v5/order/": {Category: RateCategoryOrders},
+			},
+			RemainHeader: "X-Bapi-Limit-Status",
+			LimitHeader:  "X-Bapi-Limit",
+			LimitCodes:   []string{"10006", "10018"},
+		},
+	})
+}
+
 // NewBybit создает новый экземпляр Bybit
 // Использует глобальный HTTP клиент с connection pooling и оптимизированными таймаутами
 func NewBybit() *Bybit {
-	return &Bybit{
+	b := &Bybit{
 		httpClient:      GetGlobalHTTPClient().GetClient(),
+		endpoints:       Endpoints{REST: bybitBaseURL, WSPublic: bybitWSPublic, WSPrivate: bybitWSPrivate},
+		limiter:         newRequestLimiter("bybit"),
+		health:          HealthOf("bybit"),
 		tickerCallbacks: make(map[string]func(*Ticker)),
 		closeChan:       make(chan struct{}),
 	}
+	b.instruments = NewInstrumentRegistry("bybit", b.loadInstruments)
+	b.clock = NewClock("bybit", b.getServerTime, "10002")
+	return b
+}
+
+// SetEndpoints подменяет адреса REST и WebSocket API
+func (b *Bybit) SetEndpoints(endpoints Endpoints) {
+	b.endpoints = endpoints
+}
+
+// getServerTime запрашивает время сервера для синхронизации часов
+func (b *Bybit) getServerTime(ctx context.Context) (time.Time, error) {
+	body, err := b.doRequest(ctx, http.MethodGet, "/v5/market/time", nil, false)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var resp struct {
+		Result struct {
+			TimeNano string `json:"timeNano"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return time.Time{}, err
+	}
+
+	nanos, err := strconv.ParseInt(resp.Result.TimeNano, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid server time %q", resp.Result.TimeNano)
+	}
+	return time.Unix(0, nanos), nil
 }
 
 // sign создает подпись для запроса к Bybit API v5
-func (b *Bybit) sign(timestamp string, params string) string {
-	message := timestamp + b.apiKey + bybitRecvWindow + params
+func (b *Bybit) sign(timestamp, recvWindow, params string) string {
+	message := timestamp + b.apiKey + recvWindow + params
 	h := hmac.New(sha256.New, []byte(b.secretKey))
 	h.Write([]byte(message))
 	return hex.EncodeToString(h.Sum(nil))
 }
 
-// doRequest выполняет HTTP запрос к Bybit API
+// doRequest выполняет запрос к Bybit API и учитывает его в состоянии биржи
 func (b *Bybit) doRequest(ctx context.Context, method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
+	start := time.Now()
+	body, err := b.sendRequest(ctx, method, endpoint, params, signed)
+	b.health.ObserveRequest(time.Since(start), err)
+	return body, err
+}
+
+// sendRequest выполняет HTTP запрос к Bybit API
+func (b *Bybit) sendRequest(ctx context.Context, method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
 	var reqBody string
 	var reqURL string
 
@@ -106,12 +191,12 @@ func (b *Bybit) doRequest(ctx context.Context, method, endpoint string, params m
 		}
 		reqBody = query.Encode()
 		if reqBody != "" {
-			reqURL = bybitBaseURL + endpoint + "?" + reqBody
+			reqURL = b.endpoints.REST + endpoint + "?" + reqBody
 		} else {
-			reqURL = bybitBaseURL + endpoint
+			reqURL = b.endpoints.REST + endpoint
 		}
 	} else {
-		reqURL = bybitBaseURL + endpoint
+		reqURL = b.endpoints.REST + endpoint
 		if len(params) > 0 {
 			jsonBytes, _ := json.Marshal(params)
 			reqBody = string(jsonBytes)
@@ -126,13 +211,18 @@ func (b *Bybit) doRequest(ctx context.Context, method, endpoint string, params m
 	req.Header.Set("Content-Type", "application/json")
 
 	if signed {
-		timestamp := strconv.FormatInt(time.Now().UnixMilli(), 10)
-		signature := b.sign(timestamp, reqBody)
+		timestamp := strconv.FormatInt(b.clock.Now().UnixMilli(), 10)
+		recvWindow := recvWindowMillis("bybit")
+		signature := b.sign(timestamp, recvWindow, reqBody)
 
 		req.Header.Set("X-BAPI-API-KEY", b.apiKey)
 		req.Header.Set("X-BAPI-SIGN", signature)
 		req.Header.Set("X-BAPI-TIMESTAMP", timestamp)
-		req.Header.Set("X-BAPI-RECV-WINDOW", bybitRecvWindow)
+		req.Header.Set("X-BAPI-RECV-WINDOW", recvWindow)
+	}
+
+	if err := b.limiter.Wait(ctx, endpoint, signed); err != nil {
+		return nil, err
 	}
 
 	resp, err := b.httpClient.Do(req)
@@ -140,6 +230,7 @@ func (b *Bybit) doRequest(ctx context.Context, method, endpoint string, params m
 		return nil, err
 	}
 	defer resp.Body.Close()
+	b.limiter.Observe(endpoint, signed, resp)
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -152,15 +243,18 @@ func (b *Bybit) doRequest(ctx context.Context, method, endpoint string, params m
 		RetMsg  string `json:"retMsg"`
 	}
 	if err := json.Unmarshal(body, &baseResp); err != nil {
-		return nil, err
+		return nil, httpStatusError("bybit", resp.StatusCode, body, err)
 	}
 
 	if baseResp.RetCode != 0 {
-		return nil, &ExchangeError{
+		return nil, b.limiter.CheckError(b.clock.CheckError(&ExchangeError{
 			Exchange: "bybit",
 			Code:     strconv.Itoa(baseResp.RetCode),
 			Message:  baseResp.RetMsg,
-		}
+		}))
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, httpStatusError("bybit", resp.StatusCode, body, nil)
 	}
 
 	return body, nil
@@ -174,11 +268,21 @@ func (b *Bybit) Connect(apiKey, secret, passphrase string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Смещение часов нужно до первого подписанного запроса; при ошибке подпись идёт по локальному времени
+	if err := b.clock.Sync(ctx); err != nil {
+		log.Printf("[bybit] %v", err)
+	}
+
 	_, err := b.GetBalance(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to connect to Bybit: %w", err)
 	}
 
+	// Без режима позиций ордера уточняют его по отказу биржи (см. createOrder)
+	if err := b.detectPositionMode(ctx); err != nil {
+		log.Printf("[bybit] failed to detect position mode: %v", err)
+	}
+
 	b.connected = true
 	return nil
 }
@@ -187,6 +291,69 @@ func (b *Bybit) GetName() string {
 	return "bybit"
 }
 
+// PositionMode возвращает режим позиций аккаунта
+func (b *Bybit) PositionMode() string {
+	if b.hedgeMode.Load() {
+		return PositionModeHedge
+	}
+	return PositionModeOneWay
+}
+
+// detectPositionMode определяет режим позиций по открытым позициям: positionIdx 1 и 2 - режим hedge
+// Bybit не сообщает режим аккаунта без позиций, в этом случае остаётся one-way
+func (b *Bybit) detectPositionMode(ctx context.Context) error {
+	positions, err := b.getPositionList(ctx)
+	if err != nil {
+		return err
+	}
+	for _, p := range positions {
+		if p.PositionIdx != 0 {
+			b.hedgeMode.Store(true)
+			return nil
+		}
+	}
+	return nil
+}
+
+// bybitPositionIdx возвращает positionIdx ордера для позиции стороны side
+// В режиме one-way - 0, в режиме hedge - 1 для лонга и 2 для шорта
+func bybitPositionIdx(hedge bool, side string) string {
+	if !hedge {
+		return "0"
+	}
+	if side == SideShort {
+		return "2"
+	}
+	return "1"
+}
+
+// isPositionIdxMismatch проверяет отказ ордера с positionIdx другого режима позиций
+func isPositionIdxMismatch(err error) bool {
+	var exchErr *ExchangeError
+	return errors.As(err, &exchErr) && exchErr.Code == "10001" &&
+		strings.Contains(strings.ToLower(exchErr.Message), "position idx")
+}
+
+// createOrder отправляет ордер /v5/order/create по позиции стороны side (SideLong / SideShort)
+// Отказ по positionIdx означает, что режим позиций аккаунта сменился (или не был
+// определён при Connect): режим переключается и ордер повторяется один раз
+func (b *Bybit) createOrder(ctx context.Context, params map[string]string, side string) ([]byte, error) {
+	hedge := b.hedgeMode.Load()
+	params["positionIdx"] = bybitPositionIdx(hedge, side)
+	body, err := b.doRequest(ctx, http.MethodPost, "/v5/order/create", params, true)
+	if !isPositionIdxMismatch(err) {
+		return body, err
+	}
+
+	// Параллельный ордер мог уже переключить режим
+	if b.hedgeMode.CompareAndSwap(hedge, !hedge) {
+		log.Printf("[bybit] position mode mismatch, switched to %s", b.PositionMode())
+	}
+
+	params["positionIdx"] = bybitPositionIdx(!hedge, side)
+	return b.doRequest(ctx, http.MethodPost, "/v5/order/create", params, true)
+}
+
 func (b *Bybit) GetBalance(ctx context.Context) (float64, error) {
 	params := map[string]string{
 		"accountType": "UNIFIED",
@@ -325,7 +492,31 @@ func (b *Bybit) GetOrderBook(ctx context.Context, symbol string, depth int) (*Or
 	return orderBook, nil
 }
 
-func (b *Bybit) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64) (*Order, error) {
+func (b *Bybit) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*Order, error) {
+	// В режиме hedge ордер открывает позицию своей стороны
+	positionSide := SideLong
+	if side == SideSell || side == SideShort {
+		positionSide = SideShort
+	}
+	return b.placeMarketOrder(ctx, symbol, side, positionSide, qty, clientOrderID)
+}
+
+// PlaceCloseOrder закрывает позицию стороны side рыночным ордером противоположной стороны
+// В режиме hedge закрываемую позицию задаёт positionIdx
+func (b *Bybit) PlaceCloseOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*Order, error) {
+	orderSide := CloseOrderSide(side)
+	return b.placeMarketOrder(ctx, symbol, orderSide, ClosedPositionSide(orderSide), qty, clientOrderID)
+}
+
+// placeMarketOrder размещает рыночный ордер side по позиции стороны positionSide
+// Ордер, сторона которого не совпадает со стороной позиции, уменьшает позицию
+func (b *Bybit) placeMarketOrder(ctx context.Context, symbol, side, positionSide string, qty float64, clientOrderID string) (*Order, error) {
+	inst, size, err := b.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return nil, err
+	}
+	qty = inst.FromVenueSize(size)
+
 	// Конвертируем side в формат Bybit
 	bybitSide := "Buy"
 	if side == SideSell || side == SideShort {
@@ -337,11 +528,14 @@ func (b *Bybit) PlaceMarketOrder(ctx context.Context, symbol, side string, qty f
 		"symbol":      symbol,
 		"side":        bybitSide,
 		"orderType":   "Market",
-		"qty":         strconv.FormatFloat(qty, 'f', -1, 64),
+		"qty":         inst.FormatSize(size),
 		"timeInForce": "IOC",
 	}
+	if clientOrderID != "" {
+		params["orderLinkId"] = clientOrderID
+	}
 
-	body, err := b.doRequest(ctx, http.MethodPost, "/v5/order/create", params, true)
+	body, err := b.createOrder(ctx, params, positionSide)
 	if err != nil {
 		return nil, err
 	}
@@ -359,14 +553,15 @@ func (b *Bybit) PlaceMarketOrder(ctx context.Context, symbol, side string, qty f
 
 	// Получаем детали ордера
 	order := &Order{
-		ID:        resp.Result.OrderId,
-		Symbol:    symbol,
-		Side:      side,
-		Type:      "market",
-		Quantity:  qty,
-		Status:    OrderStatusFilled,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:            resp.Result.OrderId,
+		ClientOrderID: clientOrderID,
+		Symbol:        symbol,
+		Side:          side,
+		Type:          "market",
+		Quantity:      qty,
+		Status:        OrderStatusFilled,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	// Получаем информацию об исполнении
@@ -425,7 +620,31 @@ func (b *Bybit) getOrderExecution(ctx context.Context, symbol, orderId string) (
 	}, nil
 }
 
-func (b *Bybit) GetOpenPositions(ctx context.Context) ([]*Position, error) {
+// bybitPosition - позиция в ответе /v5/position/list
+type bybitPosition struct {
+	PositionIdx    int    `json:"positionIdx"`
+	Symbol         string `json:"symbol"`
+	Side           string `json:"side"`
+	Size           string `json:"size"`
+	AvgPrice       string `json:"avgPrice"`
+	MarkPrice      string `json:"markPrice"`
+	Leverage       string `json:"leverage"`
+	UnrealisedPnl  string `json:"unrealisedPnl"`
+	UpdatedTime    string `json:"updatedTime"`
+	PositionStatus string `json:"positionStatus"`
+}
+
+// bybitPositionSide возвращает сторону позиции
+// В режиме hedge сторону задаёт positionIdx: у закрытой позиции side пустой
+func bybitPositionSide(side string, positionIdx int) string {
+	if positionIdx == 2 || (positionIdx == 0 && side == "Sell") {
+		return SideShort
+	}
+	return SideLong
+}
+
+// getPositionList получает позиции USDT контрактов
+func (b *Bybit) getPositionList(ctx context.Context) ([]bybitPosition, error) {
 	params := map[string]string{
 		"category":   "linear",
 		"settleCoin": "USDT",
@@ -438,39 +657,33 @@ func (b *Bybit) GetOpenPositions(ctx context.Context) ([]*Position, error) {
 
 	var resp struct {
 		Result struct {
-			List []struct {
-				Symbol         string `json:"symbol"`
-				Side           string `json:"side"`
-				Size           string `json:"size"`
-				AvgPrice       string `json:"avgPrice"`
-				MarkPrice      string `json:"markPrice"`
-				Leverage       string `json:"leverage"`
-				UnrealisedPnl  string `json:"unrealisedPnl"`
-				UpdatedTime    string `json:"updatedTime"`
-				PositionStatus string `json:"positionStatus"`
-			} `json:"list"`
+			List []bybitPosition `json:"list"`
 		} `json:"result"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
 		return nil, err
 	}
+	return resp.Result.List, nil
+}
+
+// GetOpenPositions получает открытые позиции; в режиме hedge стороны позиции возвращаются отдельно
+func (b *Bybit) GetOpenPositions(ctx context.Context) ([]*Position, error) {
+	list, err := b.getPositionList(ctx)
+	if err != nil {
+		return nil, err
+	}
 
 	positions := make([]*Position, 0)
-	for _, p := range resp.Result.List {
+	for _, p := range list {
 		size := b.parseFloat(p.Size, "position.size")
 		if size == 0 {
 			continue
 		}
 
-		side := SideLong
-		if p.Side == "Sell" {
-			side = SideShort
-		}
-
 		positions = append(positions, &Position{
 			Symbol:        p.Symbol,
-			Side:          side,
+			Side:          bybitPositionSide(p.Side, p.PositionIdx),
 			Size:          size,
 			EntryPrice:    b.parseFloat(p.AvgPrice, "position.avgPrice"),
 			MarkPrice:     b.parseFloat(p.MarkPrice, "position.markPrice"),
@@ -485,26 +698,246 @@ func (b *Bybit) GetOpenPositions(ctx context.Context) ([]*Position, error) {
 }
 
 func (b *Bybit) ClosePosition(ctx context.Context, symbol, side string, qty float64) error {
-	// Для закрытия позиции открываем противоположную
-	closeSide := SideBuy
-	if side == SideLong || side == SideBuy {
-		closeSide = SideSell
+	_, err := b.PlaceCloseOrder(ctx, symbol, side, qty, "")
+	return err
+}
+
+// bybitTimeInForce - соответствие time in force значениям Bybit
+var bybitTimeInForce = map[string]string{
+	TimeInForceGTC:      "GTC",
+	TimeInForceIOC:      "IOC",
+	TimeInForceFOK:      "FOK",
+	TimeInForcePostOnly: "PostOnly",
+}
+
+// bybitOrderInfo - ордер в ответах /v5/order/realtime и /v5/order/history
+type bybitOrderInfo struct {
+	OrderId     string `json:"orderId"`
+	OrderLinkId string `json:"orderLinkId"`
+	Symbol      string `json:"symbol"`
+	Side        string `json:"side"`
+	OrderType   string `json:"orderType"`
+	Price       string `json:"price"`
+	Qty         string `json:"qty"`
+	CumExecQty  string `json:"cumExecQty"`
+	AvgPrice    string `json:"avgPrice"`
+	CumExecFee  string `json:"cumExecFee"`
+	OrderStatus string `json:"orderStatus"`
+	TimeInForce string `json:"timeInForce"`
+	CreatedTime string `json:"createdTime"`
+	UpdatedTime string `json:"updatedTime"`
+}
+
+func (b *Bybit) PlaceLimitOrder(ctx context.Context, symbol, side string, qty, price float64, tif string) (*Order, error) {
+	tif, err := normalizeTimeInForce(tif)
+	if err != nil {
+		return nil, err
+	}
+
+	inst, size, err := b.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return nil, err
 	}
+	qty = inst.FromVenueSize(size)
 
-	_, err := b.PlaceMarketOrder(ctx, symbol, closeSide, qty)
+	bybitSide := "Buy"
+	positionSide := SideLong
+	if side == SideSell || side == SideShort {
+		bybitSide = "Sell"
+		positionSide = SideShort
+	}
+
+	params := map[string]string{
+		"category":    "linear",
+		"symbol":      symbol,
+		"side":        bybitSide,
+		"orderType":   "Limit",
+		"qty":         inst.FormatSize(size),
+		"price":       strconv.FormatFloat(price, 'f', -1, 64),
+		"timeInForce": bybitTimeInForce[tif],
+	}
+
+	body, err := b.createOrder(ctx, params, positionSide)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Result struct {
+			OrderId string `json:"orderId"`
+		} `json:"result"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	// Получаем состояние ордера (IOC/FOK к этому моменту уже исполнены или отменены)
+	order, err := b.GetOrder(ctx, symbol, resp.Result.OrderId)
+	if err != nil {
+		return newLimitOrder(resp.Result.OrderId, symbol, side, qty, price, tif), nil
+	}
+	order.Side = side
+	return order, nil
+}
+
+func (b *Bybit) CancelOrder(ctx context.Context, symbol, orderID string) error {
+	params := map[string]string{
+		"category": "linear",
+		"symbol":   symbol,
+		"orderId":  orderID,
+	}
+
+	_, err := b.doRequest(ctx, http.MethodPost, "/v5/order/cancel", params, true)
 	return err
 }
 
+func (b *Bybit) GetOrder(ctx context.Context, symbol, orderID string) (*Order, error) {
+	return b.findOrder(ctx, symbol, "orderId", orderID)
+}
+
+func (b *Bybit) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*Order, error) {
+	return b.findOrder(ctx, symbol, "orderLinkId", clientOrderID)
+}
+
+// findOrder ищет ордер по orderId или orderLinkId
+func (b *Bybit) findOrder(ctx context.Context, symbol, key, id string) (*Order, error) {
+	params := map[string]string{
+		"category": "linear",
+		"symbol":   symbol,
+		key:        id,
+	}
+
+	// realtime возвращает активные и недавно закрытые ордера, более старые - в history
+	for _, endpoint := range []string{"/v5/order/realtime", "/v5/order/history"} {
+		orders, err := b.queryOrders(ctx, endpoint, params)
+		if err != nil {
+			return nil, err
+		}
+		if len(orders) > 0 {
+			return orders[0], nil
+		}
+	}
+
+	return nil, fmt.Errorf("order %s: %w", id, ErrOrderNotFound)
+}
+
+func (b *Bybit) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	params := map[string]string{
+		"category": "linear",
+		"openOnly": "0",
+	}
+	if symbol != "" {
+		params["symbol"] = symbol
+	} else {
+		params["settleCoin"] = "USDT"
+	}
+
+	return b.queryOrders(ctx, "/v5/order/realtime", params)
+}
+
+// queryOrders запрашивает список ордеров и конвертирует его в Order
+func (b *Bybit) queryOrders(ctx context.Context, endpoint string, params map[string]string) ([]*Order, error) {
+	body, err := b.doRequest(ctx, http.MethodGet, endpoint, params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Result struct {
+			List []bybitOrderInfo `json:"list"`
+		} `json:"result"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	orders := make([]*Order, 0, len(resp.Result.List))
+	for _, info := range resp.Result.List {
+		orders = append(orders, b.parseOrder(info))
+	}
+	return orders, nil
+}
+
+// parseOrder конвертирует ордер Bybit в Order
+func (b *Bybit) parseOrder(info bybitOrderInfo) *Order {
+	side := SideBuy
+	if info.Side == "Sell" {
+		side = SideSell
+	}
+
+	order := &Order{
+		ID:            info.OrderId,
+		ClientOrderID: info.OrderLinkId,
+		Symbol:        info.Symbol,
+		Side:          side,
+		Type:          strings.ToLower(info.OrderType),
+		Price:         b.parseFloat(info.Price, "order.price"),
+		Quantity:      b.parseFloat(info.Qty, "order.qty"),
+		FilledQty:     b.parseFloat(info.CumExecQty, "order.cumExecQty"),
+		AvgFillPrice:  b.parseFloat(info.AvgPrice, "order.avgPrice"),
+		Fee:           b.parseFloat(info.CumExecFee, "order.cumExecFee"),
+		CreatedAt:     time.UnixMilli(b.parseInt64(info.CreatedTime, "order.createdTime")),
+		UpdatedAt:     time.UnixMilli(b.parseInt64(info.UpdatedTime, "order.updatedTime")),
+	}
+
+	for tif, bybitTIF := range bybitTimeInForce {
+		if info.TimeInForce == bybitTIF {
+			order.TimeInForce = tif
+		}
+	}
+
+	switch info.OrderStatus {
+	case "New", "Untriggered":
+		order.Status = OrderStatusNew
+	case "PartiallyFilled":
+		order.Status = OrderStatusPartial
+	case "Filled":
+		order.Status = OrderStatusFilled
+	case "Rejected":
+		order.Status = OrderStatusRejected
+	default: // Cancelled, PartiallyFilledCanceled, Deactivated
+		order.Status = OrderStatusCancelled
+	}
+
+	return order
+}
+
 func (b *Bybit) SubscribeTicker(symbol string, callback func(*Ticker)) error {
 	b.callbackMu.Lock()
 	b.tickerCallbacks[symbol] = callback
 	b.callbackMu.Unlock()
 
+	return b.subscribePublic("tickers." + symbol)
+}
+
+// SubscribeOrderBook подписывается на стакан orderbook.{50|200|500}
+// Bybit не публикует контрольную сумму - целостность проверяется по непрерывности update id
+func (b *Bybit) SubscribeOrderBook(symbol string, depth int, callback func(*OrderBook)) error {
+	sub := b.books.add(symbol, depth, nil, callback)
+	return b.subscribePublic(b.orderBookTopic(symbol, sub.depth))
+}
+
+// orderBookTopic возвращает топик стакана минимальной глубины, покрывающей depth
+func (b *Bybit) orderBookTopic(symbol string, depth int) string {
+	level := 50
+	if depth > 200 {
+		level = 500
+	} else if depth > 50 {
+		level = 200
+	}
+	return fmt.Sprintf("orderbook.%d.%s", level, symbol)
+}
+
+// subscribePublic подписывается на топик публичного WebSocket, подключаясь при необходимости
+func (b *Bybit) subscribePublic(topic string) error {
 	// Защита от race condition при инициализации WebSocket manager
 	b.wsMu.Lock()
 	if b.wsPublicManager == nil {
 		config := DefaultWSReconnectConfig()
-		b.wsPublicManager = NewWSReconnectManager("bybit-public", bybitWSPublic, config)
+		b.wsPublicManager = NewWSReconnectManager("bybit-public", b.endpoints.WSPublic, config)
+		b.health.TrackWS(b.wsPublicManager)
 
 		// Устанавливаем обработчик сообщений
 		b.wsPublicManager.SetOnMessage(b.handlePublicMessage)
@@ -515,7 +948,9 @@ func (b *Bybit) SubscribeTicker(symbol string, callback func(*Ticker)) error {
 		})
 
 		// Устанавливаем callback на отключение
+		// Стаканы ждут нового снимка, который придёт после переподписки
 		b.wsPublicManager.SetOnDisconnect(func(err error) {
+			b.books.invalidateAll()
 			if err != nil {
 				log.Printf("[bybit] Public WebSocket disconnected: %v", err)
 			}
@@ -533,7 +968,7 @@ func (b *Bybit) SubscribeTicker(symbol string, callback func(*Ticker)) error {
 	// Формируем сообщение подписки
 	subMsg := map[string]interface{}{
 		"op":   "subscribe",
-		"args": []string{"tickers." + symbol},
+		"args": []string{topic},
 	}
 
 	// Добавляем подписку для восстановления после переподключения
@@ -546,35 +981,103 @@ func (b *Bybit) SubscribeTicker(symbol string, callback func(*Ticker)) error {
 // handlePublicMessage обрабатывает одно сообщение из публичного WebSocket
 func (b *Bybit) handlePublicMessage(message []byte) {
 	var msg struct {
-		Topic string `json:"topic"`
-		Data  struct {
-			Symbol    string `json:"symbol"`
-			Bid1Price string `json:"bid1Price"`
-			Ask1Price string `json:"ask1Price"`
-			LastPrice string `json:"lastPrice"`
-		} `json:"data"`
+		Topic string          `json:"topic"`
+		Type  string          `json:"type"`
+		Ts    int64           `json:"ts"`
+		Data  json.RawMessage `json:"data"`
 	}
 
 	if err := json.Unmarshal(message, &msg); err != nil {
 		return
 	}
 
-	if strings.HasPrefix(msg.Topic, "tickers.") {
-		symbol := msg.Data.Symbol
+	switch {
+	case strings.HasPrefix(msg.Topic, "tickers."):
+		b.handleTickerUpdate(msg.Data)
+	case strings.HasPrefix(msg.Topic, "orderbook."):
+		b.handleOrderBookUpdate(msg.Topic, msg.Type, msg.Ts, msg.Data)
+	}
+}
 
-		b.callbackMu.RLock()
-		callback, ok := b.tickerCallbacks[symbol]
-		b.callbackMu.RUnlock()
+// handleTickerUpdate обрабатывает топик tickers
+func (b *Bybit) handleTickerUpdate(data json.RawMessage) {
+	var ticker struct {
+		Symbol    string `json:"symbol"`
+		Bid1Price string `json:"bid1Price"`
+		Ask1Price string `json:"ask1Price"`
+		LastPrice string `json:"lastPrice"`
+	}
 
-		if ok && callback != nil {
-			callback(&Ticker{
-				Symbol:    symbol,
-				BidPrice:  b.parseFloat(msg.Data.Bid1Price, "ws.bid1Price"),
-				AskPrice:  b.parseFloat(msg.Data.Ask1Price, "ws.ask1Price"),
-				LastPrice: b.parseFloat(msg.Data.LastPrice, "ws.lastPrice"),
-				Timestamp: time.Now(),
-			})
+	if err := json.Unmarshal(data, &ticker); err != nil {
+		return
+	}
+
+	symbol := ticker.Symbol
+
+	b.callbackMu.RLock()
+	callback, ok := b.tickerCallbacks[symbol]
+	b.callbackMu.RUnlock()
+
+	if ok && callback != nil {
+		callback(&Ticker{
+			Symbol:    symbol,
+			BidPrice:  b.parseFloat(ticker.Bid1Price, "ws.bid1Price"),
+			AskPrice:  b.parseFloat(ticker.Ask1Price, "ws.ask1Price"),
+			LastPrice: b.parseFloat(ticker.LastPrice, "ws.lastPrice"),
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// handleOrderBookUpdate применяет снимок или дельту стакана
+// update id u=1 - снимок после перезапуска сервиса биржи, его применяем как Reset
+func (b *Bybit) handleOrderBookUpdate(topic, msgType string, ts int64, data json.RawMessage) {
+	var book struct {
+		Symbol string     `json:"s"`
+		Bids   [][]string `json:"b"`
+		Asks   [][]string `json:"a"`
+		U      int64      `json:"u"`
+	}
+
+	if err := json.Unmarshal(data, &book); err != nil {
+		return
+	}
+
+	sub := b.books.get(book.Symbol)
+	if sub == nil {
+		return
+	}
+
+	bids, asks := stringBookLevels(book.Bids), stringBookLevels(book.Asks)
+	if msgType == "snapshot" || book.U == 1 {
+		sub.book.Reset(bids, asks, book.U, time.UnixMilli(ts))
+	} else if err := sub.book.Apply(bids, asks, book.U-1, book.U, time.UnixMilli(ts)); err != nil {
+		if err != ErrOrderBookNotSynced {
+			log.Printf("[bybit] %s: %v, resubscribing", topic, err)
+			b.resubscribePublic(topic)
 		}
+		return
+	}
+
+	sub.emit()
+}
+
+// resubscribePublic переподписывается на топик, чтобы получить новый снимок
+func (b *Bybit) resubscribePublic(topic string) {
+	b.wsMu.Lock()
+	wsManager := b.wsPublicManager
+	b.wsMu.Unlock()
+
+	if wsManager == nil {
+		return
+	}
+
+	args := []string{topic}
+	if err := wsManager.Send(map[string]interface{}{"op": "unsubscribe", "args": args}); err != nil {
+		return
+	}
+	if err := wsManager.Send(map[string]interface{}{"op": "subscribe", "args": args}); err != nil {
+		log.Printf("[bybit] resubscribe %s failed: %v", topic, err)
 	}
 }
 
@@ -583,11 +1086,26 @@ func (b *Bybit) SubscribePositions(callback func(*Position)) error {
 	b.positionCallback = callback
 	b.callbackMu.Unlock()
 
+	return b.subscribePrivate("position")
+}
+
+// SubscribeOrders подписывается на топик order приватного WebSocket
+func (b *Bybit) SubscribeOrders(callback func(*Order)) error {
+	b.callbackMu.Lock()
+	b.orderCallback = callback
+	b.callbackMu.Unlock()
+
+	return b.subscribePrivate("order")
+}
+
+// subscribePrivate подписывается на топик приватного WebSocket, подключаясь при необходимости
+func (b *Bybit) subscribePrivate(topic string) error {
 	// Защита от race condition при инициализации WebSocket manager
 	b.wsMu.Lock()
 	if b.wsPrivateManager == nil {
 		config := DefaultWSReconnectConfig()
-		b.wsPrivateManager = NewWSReconnectManager("bybit-private", bybitWSPrivate, config)
+		b.wsPrivateManager = NewWSReconnectManager("bybit-private", b.endpoints.WSPrivate, config)
+		b.health.TrackWS(b.wsPrivateManager)
 
 		// Устанавливаем функцию аутентификации
 		b.wsPrivateManager.SetAuthFunc(b.authenticateWebSocket)
@@ -609,6 +1127,7 @@ func (b *Bybit) SubscribePositions(callback func(*Position)) error {
 
 		// Подключаемся
 		if err := b.wsPrivateManager.Connect(); err != nil {
+			b.wsPrivateManager = nil
 			b.wsMu.Unlock()
 			return fmt.Errorf("failed to connect to private WebSocket: %w", err)
 		}
@@ -619,7 +1138,7 @@ func (b *Bybit) SubscribePositions(callback func(*Position)) error {
 	// Формируем сообщение подписки
 	subMsg := map[string]interface{}{
 		"op":   "subscribe",
-		"args": []string{"position"},
+		"args": []string{topic},
 	}
 
 	// Добавляем подписку для восстановления после переподключения
@@ -630,7 +1149,7 @@ func (b *Bybit) SubscribePositions(callback func(*Position)) error {
 }
 
 func (b *Bybit) authenticateWebSocket(conn *websocket.Conn) error {
-	expires := time.Now().UnixMilli() + 10000
+	expires := b.clock.Now().UnixMilli() + 10000
 
 	message := fmt.Sprintf("GET/realtime%d", expires)
 	h := hmac.New(sha256.New, []byte(b.secretKey))
@@ -648,53 +1167,97 @@ func (b *Bybit) authenticateWebSocket(conn *websocket.Conn) error {
 // handlePrivateMessage обрабатывает одно сообщение из приватного WebSocket
 func (b *Bybit) handlePrivateMessage(message []byte) {
 	var msg struct {
-		Topic string `json:"topic"`
-		Data  []struct {
-			Symbol         string `json:"symbol"`
-			Side           string `json:"side"`
-			Size           string `json:"size"`
-			EntryPrice     string `json:"entryPrice"`
-			MarkPrice      string `json:"markPrice"`
-			Leverage       string `json:"leverage"`
-			UnrealisedPnl  string `json:"unrealisedPnl"`
-			LiqPrice       string `json:"liqPrice"`
-			PositionStatus string `json:"positionStatus"`
-		} `json:"data"`
+		Topic string          `json:"topic"`
+		Data  json.RawMessage `json:"data"`
 	}
 
 	if err := json.Unmarshal(message, &msg); err != nil {
 		return
 	}
 
-	if msg.Topic == "position" {
-		b.callbackMu.RLock()
-		callback := b.positionCallback
-		b.callbackMu.RUnlock()
-
-		if callback != nil {
-			for _, p := range msg.Data {
-				side := SideLong
-				if p.Side == "Sell" {
-					side = SideShort
-				}
-
-				callback(&Position{
-					Symbol:        p.Symbol,
-					Side:          side,
-					Size:          b.parseFloat(p.Size, "ws.position.size"),
-					EntryPrice:    b.parseFloat(p.EntryPrice, "ws.position.entryPrice"),
-					MarkPrice:     b.parseFloat(p.MarkPrice, "ws.position.markPrice"),
-					Leverage:      b.parseInt(p.Leverage, "ws.position.leverage"),
-					UnrealizedPnl: b.parseFloat(p.UnrealisedPnl, "ws.position.unrealisedPnl"),
-					Liquidation:   p.PositionStatus == "Liq",
-					UpdatedAt:     time.Now(),
-				})
-			}
-		}
+	switch msg.Topic {
+	case "position":
+		b.handlePositionUpdate(msg.Data)
+	case "order":
+		b.handleOrderUpdate(msg.Data)
+	}
+}
+
+// handlePositionUpdate отправляет обновления позиций в callback
+func (b *Bybit) handlePositionUpdate(data json.RawMessage) {
+	var positions []struct {
+		PositionIdx    int    `json:"positionIdx"`
+		Symbol         string `json:"symbol"`
+		Side           string `json:"side"`
+		Size           string `json:"size"`
+		EntryPrice     string `json:"entryPrice"`
+		MarkPrice      string `json:"markPrice"`
+		Leverage       string `json:"leverage"`
+		UnrealisedPnl  string `json:"unrealisedPnl"`
+		LiqPrice       string `json:"liqPrice"`
+		PositionStatus string `json:"positionStatus"`
+	}
+
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return
+	}
+
+	b.callbackMu.RLock()
+	callback := b.positionCallback
+	b.callbackMu.RUnlock()
+
+	if callback == nil {
+		return
+	}
+
+	for _, p := range positions {
+		callback(&Position{
+			Symbol:        p.Symbol,
+			Side:          bybitPositionSide(p.Side, p.PositionIdx),
+			Size:          b.parseFloat(p.Size, "ws.position.size"),
+			EntryPrice:    b.parseFloat(p.EntryPrice, "ws.position.entryPrice"),
+			MarkPrice:     b.parseFloat(p.MarkPrice, "ws.position.markPrice"),
+			Leverage:      b.parseInt(p.Leverage, "ws.position.leverage"),
+			UnrealizedPnl: b.parseFloat(p.UnrealisedPnl, "ws.position.unrealisedPnl"),
+			Liquidation:   p.PositionStatus == "Liq",
+			UpdatedAt:     time.Now(),
+		})
+	}
+}
+
+// handleOrderUpdate отправляет обновления ордеров в callback
+// Топик order использует те же поля, что и /v5/order/realtime
+func (b *Bybit) handleOrderUpdate(data json.RawMessage) {
+	var orders []bybitOrderInfo
+	if err := json.Unmarshal(data, &orders); err != nil {
+		return
+	}
+
+	b.callbackMu.RLock()
+	callback := b.orderCallback
+	b.callbackMu.RUnlock()
+
+	if callback == nil {
+		return
+	}
+
+	for _, info := range orders {
+		callback(b.parseOrder(info))
 	}
 }
 
 func (b *Bybit) GetTradingFee(ctx context.Context, symbol string) (float64, error) {
+	fees, err := b.GetTradingFees(ctx, symbol)
+	if err != nil {
+		// Возвращаем стандартную комиссию если не удалось получить
+		return 0.00055, nil // 0.055% стандартная комиссия тейкера Bybit
+	}
+	return fees.Taker, nil
+}
+
+// GetTradingFees получает комиссии аккаунта из /v5/account/fee-rate
+// Ставки уже учитывают VIP-уровень, отрицательный makerFeeRate - ребейт
+func (b *Bybit) GetTradingFees(ctx context.Context, symbol string) (*TradingFees, error) {
 	params := map[string]string{
 		"category": "linear",
 		"symbol":   symbol,
@@ -702,36 +1265,168 @@ func (b *Bybit) GetTradingFee(ctx context.Context, symbol string) (float64, erro
 
 	body, err := b.doRequest(ctx, http.MethodGet, "/v5/account/fee-rate", params, true)
 	if err != nil {
-		// Возвращаем стандартную комиссию если не удалось получить
-		return 0.00055, nil
+		return nil, err
 	}
 
 	var resp struct {
 		Result struct {
 			List []struct {
+				Symbol       string `json:"symbol"`
 				TakerFeeRate string `json:"takerFeeRate"`
+				MakerFeeRate string `json:"makerFeeRate"`
 			} `json:"list"`
 		} `json:"result"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
-		return 0.00055, nil
+		return nil, err
+	}
+
+	if len(resp.Result.List) == 0 {
+		return nil, fmt.Errorf("fee rate not found for %s", symbol)
 	}
 
-	if len(resp.Result.List) > 0 {
-		return b.parseFloat(resp.Result.List[0].TakerFeeRate, "takerFeeRate"), nil
+	d := resp.Result.List[0]
+	return &TradingFees{
+		Symbol:    symbol,
+		Maker:     b.parseFloat(d.MakerFeeRate, "makerFeeRate"),
+		Taker:     b.parseFloat(d.TakerFeeRate, "takerFeeRate"),
+		Tier:      b.vipLevel(ctx),
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// vipLevel возвращает VIP-уровень аккаунта ("" если не удалось получить)
+// Уровень информационный: ставки fee-rate его уже учитывают
+func (b *Bybit) vipLevel(ctx context.Context) string {
+	body, err := b.doRequest(ctx, http.MethodGet, "/v5/user/query-api", nil, true)
+	if err != nil {
+		return ""
 	}
 
-	return 0.00055, nil // 0.055% стандартная комиссия тейкера Bybit
+	var resp struct {
+		Result struct {
+			VipLevel string `json:"vipLevel"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return ""
+	}
+	return resp.Result.VipLevel
 }
 
 func (b *Bybit) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
+	inst, err := b.GetInstrument(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return inst.Limits(), nil
+}
+
+func (b *Bybit) GetInstrument(ctx context.Context, symbol string) (*Instrument, error) {
+	return b.instruments.Get(ctx, symbol)
+}
+
+// bybitInstrumentStatus - соответствие status инструмента Bybit статусам Instrument
+var bybitInstrumentStatus = map[string]string{
+	"Trading":   InstrumentStatusTrading,
+	"PreLaunch": InstrumentStatusSuspended,
+	"Settling":  InstrumentStatusSuspended,
+}
+
+// loadInstruments загружает спецификации всех USDT бессрочных контрактов
+// Список отдаётся страницами по 1000, следующая страница - по nextPageCursor
+func (b *Bybit) loadInstruments(ctx context.Context) ([]*Instrument, error) {
+	instruments := make([]*Instrument, 0)
+	cursor := ""
+
+	for {
+		params := map[string]string{
+			"category": "linear",
+			"limit":    "1000",
+		}
+		if cursor != "" {
+			params["cursor"] = cursor
+		}
+
+		body, err := b.doRequest(ctx, http.MethodGet, "/v5/market/instruments-info", params, false)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp struct {
+			Result struct {
+				List []struct {
+					Symbol        string `json:"symbol"`
+					ContractType  string `json:"contractType"`
+					Status        string `json:"status"`
+					SettleCoin    string `json:"settleCoin"`
+					LotSizeFilter struct {
+						MinOrderQty      string `json:"minOrderQty"`
+						MaxOrderQty      string `json:"maxOrderQty"`
+						QtyStep          string `json:"qtyStep"`
+						MinNotionalValue string `json:"minNotionalValue"`
+					} `json:"lotSizeFilter"`
+					PriceFilter struct {
+						TickSize string `json:"tickSize"`
+					} `json:"priceFilter"`
+					LeverageFilter struct {
+						MaxLeverage string `json:"maxLeverage"`
+					} `json:"leverageFilter"`
+				} `json:"list"`
+				NextPageCursor string `json:"nextPageCursor"`
+			} `json:"result"`
+		}
+
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, info := range resp.Result.List {
+			if info.ContractType != "" && info.ContractType != "LinearPerpetual" {
+				continue
+			}
+
+			status, ok := bybitInstrumentStatus[info.Status]
+			if !ok {
+				status = InstrumentStatusDelisted
+			}
+
+			// Bybit минимум 5 USDT, если биржа не передала своё значение
+			minNotional := 5.0
+			if info.LotSizeFilter.MinNotionalValue != "" {
+				minNotional = b.parseFloat(info.LotSizeFilter.MinNotionalValue, "instrument.minNotionalValue")
+			}
+
+			instruments = append(instruments, &Instrument{
+				Symbol:         info.Symbol,
+				VenueSymbol:    info.Symbol,
+				ContractValue:  1,
+				LotSize:        b.parseFloat(info.LotSizeFilter.QtyStep, "instrument.qtyStep"),
+				MinSize:        b.parseFloat(info.LotSizeFilter.MinOrderQty, "instrument.minOrderQty"),
+				MaxSize:        b.parseFloat(info.LotSizeFilter.MaxOrderQty, "instrument.maxOrderQty"),
+				TickSize:       b.parseFloat(info.PriceFilter.TickSize, "instrument.tickSize"),
+				MinNotional:    minNotional,
+				MaxLeverage:    int(b.parseFloat(info.LeverageFilter.MaxLeverage, "instrument.maxLeverage")),
+				SettleCurrency: info.SettleCoin,
+				Status:         status,
+			})
+		}
+
+		cursor = resp.Result.NextPageCursor
+		if cursor == "" || len(resp.Result.List) == 0 {
+			return instruments, nil
+		}
+	}
+}
+
+func (b *Bybit) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
 	params := map[string]string{
 		"category": "linear",
 		"symbol":   symbol,
 	}
 
-	body, err := b.doRequest(ctx, http.MethodGet, "/v5/market/instruments-info", params, false)
+	body, err := b.doRequest(ctx, http.MethodGet, "/v5/market/tickers", params, false)
 	if err != nil {
 		return nil, err
 	}
@@ -739,18 +1434,8 @@ func (b *Bybit) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
 	var resp struct {
 		Result struct {
 			List []struct {
-				Symbol       string `json:"symbol"`
-				LotSizeFilter struct {
-					MinOrderQty string `json:"minOrderQty"`
-					MaxOrderQty string `json:"maxOrderQty"`
-					QtyStep     string `json:"qtyStep"`
-				} `json:"lotSizeFilter"`
-				PriceFilter struct {
-					TickSize string `json:"tickSize"`
-				} `json:"priceFilter"`
-				LeverageFilter struct {
-					MaxLeverage string `json:"maxLeverage"`
-				} `json:"leverageFilter"`
+				FundingRate     string `json:"fundingRate"`
+				NextFundingTime string `json:"nextFundingTime"`
 			} `json:"list"`
 		} `json:"result"`
 	}
@@ -760,21 +1445,169 @@ func (b *Bybit) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
 	}
 
 	if len(resp.Result.List) == 0 {
-		return nil, fmt.Errorf("instrument info not found for %s", symbol)
-	}
-
-	info := resp.Result.List[0]
-	return &Limits{
-		Symbol:      symbol,
-		MinOrderQty: b.parseFloat(info.LotSizeFilter.MinOrderQty, "limits.minOrderQty"),
-		MaxOrderQty: b.parseFloat(info.LotSizeFilter.MaxOrderQty, "limits.maxOrderQty"),
-		QtyStep:     b.parseFloat(info.LotSizeFilter.QtyStep, "limits.qtyStep"),
-		MinNotional: 5.0, // Bybit минимум 5 USDT
-		PriceStep:   b.parseFloat(info.PriceFilter.TickSize, "limits.tickSize"),
-		MaxLeverage: b.parseInt(info.LeverageFilter.MaxLeverage, "limits.maxLeverage"),
+		return nil, fmt.Errorf("funding rate not found for %s", symbol)
+	}
+
+	// fundingRate у Bybit - ставка, которая будет начислена в nextFundingTime
+	t := resp.Result.List[0]
+	return &FundingRate{
+		Symbol:          symbol,
+		Rate:            b.parseFloat(t.FundingRate, "fundingRate"),
+		NextFundingTime: time.UnixMilli(b.parseInt64(t.NextFundingTime, "nextFundingTime")),
+		Interval:        DefaultFundingInterval,
+		Timestamp:       time.Now(),
 	}, nil
 }
 
+// SetLeverage устанавливает плечо символа (одинаковое для лонга и шорта)
+func (b *Bybit) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if err := validateLeverage(leverage); err != nil {
+		return err
+	}
+
+	lever := strconv.Itoa(leverage)
+	params := map[string]string{
+		"category":     "linear",
+		"symbol":       symbol,
+		"buyLeverage":  lever,
+		"sellLeverage": lever,
+	}
+
+	_, err := b.doRequest(ctx, http.MethodPost, "/v5/position/set-leverage", params, true)
+	// 110043 - плечо уже установлено
+	if isExchangeErrorCode(err, "110043") {
+		return nil
+	}
+	return err
+}
+
+// SetMarginMode проверяет режим маржи символа
+// У единого торгового аккаунта (UNIFIED) режим задаётся для всего аккаунта, а не символа:
+// адаптер не переключает его, а возвращает ошибку, если режим аккаунта другой
+func (b *Bybit) SetMarginMode(ctx context.Context, symbol, mode string) error {
+	if err := validateMarginMode(mode); err != nil {
+		return err
+	}
+
+	current, err := b.accountMarginMode(ctx)
+	if err != nil {
+		return err
+	}
+	if current != mode {
+		return fmt.Errorf("bybit account margin mode is %s, %s requested for %s: margin mode is set for the whole account", current, mode, symbol)
+	}
+	return nil
+}
+
+// GetMarginSettings читает плечо символа из /v5/position/list и режим маржи аккаунта
+func (b *Bybit) GetMarginSettings(ctx context.Context, symbol string) (int, string, error) {
+	params := map[string]string{
+		"category": "linear",
+		"symbol":   symbol,
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/v5/position/list", params, true)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var resp struct {
+		Result struct {
+			List []bybitPosition `json:"list"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, "", err
+	}
+
+	leverages := make([]int, 0, len(resp.Result.List))
+	for _, p := range resp.Result.List {
+		if p.Symbol == symbol {
+			leverages = append(leverages, b.parseInt(p.Leverage, "position.leverage"))
+		}
+	}
+	leverage, err := commonLeverage(symbol, leverages...)
+	if err != nil {
+		return 0, "", err
+	}
+
+	mode, err := b.accountMarginMode(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+	return leverage, mode, nil
+}
+
+// GetFundingPayments читает начисления фандинга символа из /v5/account/transaction-log
+// Начисление - запись SETTLEMENT, change - изменение баланса с учётом знака
+func (b *Bybit) GetFundingPayments(ctx context.Context, symbol string, from, to time.Time) ([]FundingPayment, error) {
+	params := map[string]string{
+		"accountType": "UNIFIED",
+		"category":    "linear",
+		"type":        "SETTLEMENT",
+		"startTime":   strconv.FormatInt(from.UnixMilli(), 10),
+		"endTime":     strconv.FormatInt(to.UnixMilli(), 10),
+		"limit":       "50",
+	}
+
+	body, err := b.doRequest(ctx, http.MethodGet, "/v5/account/transaction-log", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Result struct {
+			List []struct {
+				Symbol          string `json:"symbol"`
+				Type            string `json:"type"`
+				Change          string `json:"change"`
+				TransactionTime string `json:"transactionTime"`
+			} `json:"list"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	payments := make([]FundingPayment, 0, len(resp.Result.List))
+	for _, r := range resp.Result.List {
+		if r.Symbol != symbol || r.Type != "SETTLEMENT" {
+			continue
+		}
+		payments = append(payments, FundingPayment{
+			Symbol: symbol,
+			Amount: b.parseFloat(r.Change, "transaction.change"),
+			Time:   time.UnixMilli(b.parseInt64(r.TransactionTime, "transaction.transactionTime")),
+		})
+	}
+	return fundingPaymentsBetween(payments, from, to), nil
+}
+
+// accountMarginMode возвращает режим маржи аккаунта (PORTFOLIO_MARGIN считается cross)
+func (b *Bybit) accountMarginMode(ctx context.Context) (string, error) {
+	body, err := b.doRequest(ctx, http.MethodGet, "/v5/account/info", nil, true)
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		Result struct {
+			MarginMode string `json:"marginMode"`
+		} `json:"result"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", err
+	}
+
+	switch resp.Result.MarginMode {
+	case "ISOLATED_MARGIN":
+		return MarginModeIsolated, nil
+	case "REGULAR_MARGIN", "PORTFOLIO_MARGIN":
+		return MarginModeCross, nil
+	}
+	return "", fmt.Errorf("unknown bybit margin mode %q", resp.Result.MarginMode)
+}
+
 func (b *Bybit) Close() error {
 	// Закрываем closeChan только если он ещё не закрыт
 	select {
@@ -0,0 +1,228 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ClockOffsetSeconds - смещение часов биржи относительно локальных (сервер минус локальные)
+var ClockOffsetSeconds = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "arbitrage",
+		Subsystem: "exchange",
+		Name:      "clock_offset_seconds",
+		Help:      "Estimated exchange server clock offset relative to local clock",
+	},
+	[]string{"exchange"},
+)
+
+const (
+	clockSamples     = 3                // замеров за одну синхронизацию, берётся замер с наименьшим RTT
+	clockSyncTimeout = 10 * time.Second // таймаут синхронизации в фоне
+	clockRetryDelay  = 10 * time.Second // минимальный интервал между фоновыми синхронизациями
+	clockWarnOffset  = time.Second      // смещение, о котором стоит предупредить в логе
+)
+
+// ClockConfig - настройки синхронизации времени и окна приёма подписанных запросов
+type ClockConfig struct {
+	SyncInterval time.Duration            // период пересинхронизации смещения
+	RecvWindow   time.Duration            // окно приёма запроса по умолчанию (recvWindow)
+	RecvWindows  map[string]time.Duration // окно приёма по биржам
+}
+
+// DefaultClockConfig возвращает настройки по умолчанию
+func DefaultClockConfig() ClockConfig {
+	return ClockConfig{
+		SyncInterval: 5 * time.Minute,
+		RecvWindow:   5 * time.Second,
+	}
+}
+
+var (
+	clockConfigMu sync.RWMutex
+	clockConfig   = DefaultClockConfig()
+)
+
+// SetClockConfig задаёт настройки синхронизации времени для всех адаптеров
+// Нулевые значения заменяются значениями по умолчанию
+func SetClockConfig(cfg ClockConfig) {
+	defaults := DefaultClockConfig()
+	if cfg.SyncInterval <= 0 {
+		cfg.SyncInterval = defaults.SyncInterval
+	}
+	if cfg.RecvWindow <= 0 {
+		cfg.RecvWindow = defaults.RecvWindow
+	}
+
+	clockConfigMu.Lock()
+	clockConfig = cfg
+	clockConfigMu.Unlock()
+}
+
+// currentClockConfig возвращает текущие настройки синхронизации
+func currentClockConfig() ClockConfig {
+	clockConfigMu.RLock()
+	defer clockConfigMu.RUnlock()
+	return clockConfig
+}
+
+// RecvWindow возвращает окно приёма подписанного запроса для биржи
+func RecvWindow(exchange string) time.Duration {
+	cfg := currentClockConfig()
+	if window, ok := cfg.RecvWindows[exchange]; ok && window > 0 {
+		return window
+	}
+	return cfg.RecvWindow
+}
+
+// recvWindowMillis возвращает окно приёма в миллисекундах для параметра запроса
+func recvWindowMillis(exchange string) string {
+	return strconv.FormatInt(RecvWindow(exchange).Milliseconds(), 10)
+}
+
+// ServerTimeFunc запрашивает время сервера биржи
+type ServerTimeFunc func(ctx context.Context) (time.Time, error)
+
+// Clock оценивает смещение часов биржи по эндпоинту времени сервера
+// Подписанные запросы берут время из Now(), поэтому расхождение локальных
+// часов не приводит к отказам биржи по timestamp
+type Clock struct {
+	exchange   string
+	serverTime ServerTimeFunc
+	codes      []string // коды ExchangeError о неверном timestamp
+
+	mu          sync.RWMutex
+	offset      time.Duration
+	rtt         time.Duration
+	syncedAt    time.Time
+	attemptedAt time.Time // последняя фоновая синхронизация
+
+	syncing atomic.Bool
+}
+
+// NewClock создаёт часы биржи; codes - коды ошибок, после которых смещение пересчитывается сразу
+func NewClock(exchange string, serverTime ServerTimeFunc, codes ...string) *Clock {
+	return &Clock{
+		exchange:   exchange,
+		serverTime: serverTime,
+		codes:      codes,
+	}
+}
+
+// Sync замеряет смещение часов биржи
+// Смещение считается по середине запроса: server - (start + rtt/2)
+func (c *Clock) Sync(ctx context.Context) error {
+	var bestOffset, bestRTT time.Duration
+	samples := 0
+	var lastErr error
+
+	for i := 0; i < clockSamples; i++ {
+		start := time.Now()
+		server, err := c.serverTime(ctx)
+		rtt := time.Since(start)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				break
+			}
+			continue
+		}
+
+		if samples == 0 || rtt < bestRTT {
+			bestRTT = rtt
+			bestOffset = server.Sub(start.Add(rtt / 2))
+		}
+		samples++
+	}
+
+	if samples == 0 {
+		return fmt.Errorf("server time sync failed: %w", lastErr)
+	}
+
+	c.mu.Lock()
+	c.offset = bestOffset
+	c.rtt = bestRTT
+	c.syncedAt = time.Now()
+	c.mu.Unlock()
+
+	ClockOffsetSeconds.WithLabelValues(c.exchange).Set(bestOffset.Seconds())
+	if bestOffset > clockWarnOffset || bestOffset < -clockWarnOffset {
+		log.Printf("[%s] server clock offset %v (rtt %v)", c.exchange, bestOffset, bestRTT)
+	}
+	return nil
+}
+
+// Now возвращает время биржи с учётом смещения
+// Если смещение устарело, пересчитывает его в фоне
+func (c *Clock) Now() time.Time {
+	c.mu.RLock()
+	offset := c.offset
+	syncedAt := c.syncedAt
+	c.mu.RUnlock()
+
+	if syncedAt.IsZero() || time.Since(syncedAt) > currentClockConfig().SyncInterval {
+		c.resync()
+	}
+	return time.Now().Add(offset)
+}
+
+// Offset возвращает последнее измеренное смещение часов биржи
+func (c *Clock) Offset() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.offset
+}
+
+// RTT возвращает время запроса, по которому измерено смещение
+func (c *Clock) RTT() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.rtt
+}
+
+// CheckError пересчитывает смещение, если биржа отклонила запрос из-за timestamp
+// Возвращает исходную ошибку
+func (c *Clock) CheckError(err *ExchangeError) *ExchangeError {
+	for _, code := range c.codes {
+		if err.Code == code {
+			log.Printf("[%s] request rejected by timestamp (%s), resyncing clock", c.exchange, err.Message)
+			c.resync()
+			break
+		}
+	}
+	return err
+}
+
+// resync пересчитывает смещение в фоне, не чаще clockRetryDelay и без параллельных синхронизаций
+func (c *Clock) resync() {
+	if !c.syncing.CompareAndSwap(false, true) {
+		return
+	}
+
+	c.mu.Lock()
+	if time.Since(c.attemptedAt) < clockRetryDelay {
+		c.mu.Unlock()
+		c.syncing.Store(false)
+		return
+	}
+	c.attemptedAt = time.Now()
+	c.mu.Unlock()
+
+	go func() {
+		defer c.syncing.Store(false)
+
+		ctx, cancel := context.WithTimeout(context.Background(), clockSyncTimeout)
+		defer cancel()
+		if err := c.Sync(ctx); err != nil {
+			log.Printf("[%s] %v", c.exchange, err)
+		}
+	}()
+}
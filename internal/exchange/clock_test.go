@@ -0,0 +1,102 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestClock_Sync проверяет оценку смещения по замеру с наименьшим RTT
+func TestClock_Sync(t *testing.T) {
+	calls := 0
+	clock := NewClock("test-sync", func(ctx context.Context) (time.Time, error) {
+		calls++
+		// Первый замер медленный, его смещение искажено задержкой ответа
+		if calls == 1 {
+			time.Sleep(50 * time.Millisecond)
+		}
+		return time.Now().Add(2 * time.Second), nil
+	})
+
+	if err := clock.Sync(context.Background()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	if calls != clockSamples {
+		t.Fatalf("expected %d samples, got %d", clockSamples, calls)
+	}
+	if offset := clock.Offset(); offset < 1990*time.Millisecond || offset > 2010*time.Millisecond {
+		t.Fatalf("expected offset ~2s, got %v", offset)
+	}
+	if rtt := clock.RTT(); rtt > 40*time.Millisecond {
+		t.Fatalf("expected fastest sample rtt, got %v", rtt)
+	}
+	if diff := time.Until(clock.Now()); diff < 1990*time.Millisecond || diff > 2010*time.Millisecond {
+		t.Fatalf("expected Now shifted by offset, got %v", diff)
+	}
+
+	failing := NewClock("test-sync-error", func(ctx context.Context) (time.Time, error) {
+		return time.Time{}, errors.New("connection refused")
+	})
+	if err := failing.Sync(context.Background()); err == nil {
+		t.Fatal("expected error when all samples failed")
+	}
+	if failing.Offset() != 0 {
+		t.Fatalf("expected zero offset after failed sync, got %v", failing.Offset())
+	}
+}
+
+// TestClock_CheckError проверяет пересинхронизацию после отказа биржи по timestamp
+func TestClock_CheckError(t *testing.T) {
+	var calls atomic.Int32
+	clock := NewClock("test-check", func(ctx context.Context) (time.Time, error) {
+		calls.Add(1)
+		return time.Now(), nil
+	}, "-1021")
+	if err := clock.Sync(context.Background()); err != nil {
+		t.Fatalf("sync: %v", err)
+	}
+	calls.Store(0)
+
+	other := &ExchangeError{Exchange: "binance", Code: "-2019", Message: "Margin is insufficient."}
+	if err := clock.CheckError(other); err != other {
+		t.Fatalf("expected original error, got %v", err)
+	}
+
+	exchErr := &ExchangeError{Exchange: "binance", Code: "-1021", Message: "Timestamp for this request is outside of the recvWindow."}
+	clock.CheckError(exchErr)
+	// Повторный отказ сразу после синхронизации не запускает новую
+	clock.CheckError(exchErr)
+
+	deadline := time.Now().Add(time.Second)
+	for clock.syncing.Load() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	if got := calls.Load(); got != clockSamples {
+		t.Fatalf("expected one resync (%d samples), got %d", clockSamples, got)
+	}
+}
+
+// TestRecvWindow проверяет окно приёма по умолчанию и для отдельной биржи
+func TestRecvWindow(t *testing.T) {
+	t.Cleanup(func() { SetClockConfig(DefaultClockConfig()) })
+
+	if got := recvWindowMillis("binance"); got != "5000" {
+		t.Fatalf("expected default recvWindow 5000, got %s", got)
+	}
+
+	SetClockConfig(ClockConfig{
+		RecvWindow:  3 * time.Second,
+		RecvWindows: map[string]time.Duration{"bybit": 10 * time.Second},
+	})
+	if got := recvWindowMillis("binance"); got != "3000" {
+		t.Fatalf("expected recvWindow 3000, got %s", got)
+	}
+	if got := recvWindowMillis("bybit"); got != "10000" {
+		t.Fatalf("expected bybit recvWindow 10000, got %s", got)
+	}
+	if cfg := currentClockConfig(); cfg.SyncInterval != DefaultClockConfig().SyncInterval {
+		t.Fatalf("expected default sync interval, got %v", cfg.SyncInterval)
+	}
+}
@@ -0,0 +1,731 @@
+package exchange
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Набор проверок контракта Exchange для всех зарегистрированных адаптеров
+//
+// Каждый адаптер запускается против локального REST сервера (httptest) и WebSocket
+// сервера, которые отдают записанные ответы биржи из testdata/<exchange>/. Сценарий
+// биржи описан в testdata/<exchange>/conformance.json: маршруты REST, ожидаемые значения
+// и сообщения WebSocket. Новый адаптер, зарегистрированный через Register, попадает
+// в набор автоматически и не пройдёт его без своего сценария.
+
+const (
+	conformanceAPIKey     = "conformance-key"
+	conformanceSecret     = "conformance-secret"
+	conformancePassphrase = "conformance-passphrase"
+	conformanceClientID   = "arbconf1"
+)
+
+// Период истории фандинга: начисление 2023-11-15 00:00 UTC из fixtures
+var (
+	conformanceFundingFrom = time.Date(2023, 11, 14, 23, 59, 0, 0, time.UTC)
+	conformanceFundingTo   = time.Date(2023, 11, 15, 0, 10, 0, 0, time.UTC)
+)
+
+// conformanceScenario - описание биржи для набора проверок (testdata/<exchange>/conformance.json)
+type conformanceScenario struct {
+	Symbol      string `json:"symbol"`       // унифицированный символ (BTCUSDT)
+	VenueSymbol string `json:"venue_symbol"` // символ биржи, который должен уйти в запрос
+	RESTPrefix  string `json:"rest_prefix"`  // префикс версии API в базовом URL (Gate: /api/v4)
+
+	// Где адаптер передаёт API ключ подписанных запросов: заголовок или параметр запроса
+	Auth struct {
+		Header string `json:"header"`
+		Param  string `json:"param"`
+	} `json:"auth"`
+
+	Routes []conformanceRoute `json:"routes"`
+
+	Order struct {
+		Qty      float64  `json:"qty"`       // объём рыночного ордера в монетах
+		Path     string   `json:"path"`      // эндпоинт размещения ордера
+		ClientID string   `json:"client_id"` // как conformanceClientID уходит в запрос
+		Buy      []string `json:"buy"`       // подстроки запроса для SideBuy
+		Sell     []string `json:"sell"`      // подстроки запроса для SideSell
+		Error    struct {
+			conformanceRoute
+			Code string `json:"code"` // ожидаемый ExchangeError.Code
+		} `json:"error"` // отказ биржи на размещение ордера
+
+		// Режим позиций аккаунта, определённый при Connect, и подстроки запросов
+		// PlaceCloseOrder - для адаптеров с PositionCloser
+		PositionMode string   `json:"position_mode"`
+		CloseLong    []string `json:"close_long"`
+		CloseShort   []string `json:"close_short"`
+	} `json:"order"`
+
+	// Ответы на поиск ордера по клиентскому ID
+	Lookup struct {
+		Found   conformanceRoute `json:"found"`   // ордер с conformanceClientID
+		Missing conformanceRoute `json:"missing"` // биржа не знает ордер
+	} `json:"lookup"`
+
+	WS struct {
+		Path      string   `json:"path"`      // путь публичного WebSocket
+		Gzip      bool     `json:"gzip"`      // биржа сжимает сообщения
+		Subscribe string   `json:"subscribe"` // подстрока сообщения подписки на тикер
+		Messages  []string `json:"messages"`  // fixtures, отправляемые в ответ на подписку
+	} `json:"ws"`
+
+	Expect struct {
+		Balance   float64               `json:"balance"`
+		Ticker    conformanceTicker     `json:"ticker"`
+		WSTicker  conformanceTicker     `json:"ws_ticker"`
+		BestBid   float64               `json:"best_bid"`
+		BestAsk   float64               `json:"best_ask"`
+		Positions []conformancePosition `json:"positions"`
+		Funding   float64               `json:"funding"`
+		Margin    struct {
+			Leverage int    `json:"leverage"`
+			Mode     string `json:"mode"`
+		} `json:"margin"` // плечо и режим маржи символа (MarginReader)
+		FundingPayments []float64 `json:"funding_payments"` // начисления фандинга символа (FundingHistoryReader)
+	} `json:"expect"`
+}
+
+// conformanceRoute - ответ REST сервера на запрос
+type conformanceRoute struct {
+	Method  string `json:"method"`
+	Path    string `json:"path"`
+	Match   string `json:"match"`  // подстрока query или тела, если у пути несколько ответов
+	Status  int    `json:"status"` // HTTP статус ответа (по умолчанию 200)
+	Fixture string `json:"fixture"`
+	Signed  bool   `json:"signed"` // запрос должен нести API ключ
+}
+
+type conformanceTicker struct {
+	Bid  float64 `json:"bid"`
+	Ask  float64 `json:"ask"`
+	Last float64 `json:"last"`
+}
+
+type conformancePosition struct {
+	Side       string  `json:"side"`
+	Size       float64 `json:"size"`
+	EntryPrice float64 `json:"entry_price"`
+	MarkPrice  float64 `json:"mark_price"`
+	Leverage   int     `json:"leverage"`
+	Pnl        float64 `json:"pnl"`
+}
+
+// conformanceRequest - запрос, принятый REST сервером
+type conformanceRequest struct {
+	Method string
+	Path   string
+	Raw    string // query и тело запроса
+}
+
+// conformanceServer - локальная биржа: REST по маршрутам сценария и публичный WebSocket
+type conformanceServer struct {
+	t        *testing.T
+	name     string
+	scenario *conformanceScenario
+	server   *httptest.Server
+
+	mu       sync.Mutex
+	requests []conformanceRequest
+	failure  *conformanceRoute // ответ на все запросы, пока задан
+	wsConns  []*websocket.Conn
+	wsDials  int
+	wsSubs   int
+}
+
+func newConformanceServer(t *testing.T, name string, scenario *conformanceScenario) *conformanceServer {
+	s := &conformanceServer{t: t, name: name, scenario: scenario}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// endpoints возвращает адреса API локальной биржи
+func (s *conformanceServer) endpoints() Endpoints {
+	wsURL := "ws" + strings.TrimPrefix(s.server.URL, "http") + s.scenario.WS.Path
+	return Endpoints{
+		REST:      s.server.URL + s.scenario.RESTPrefix,
+		WSPublic:  wsURL,
+		WSPrivate: wsURL,
+	}
+}
+
+// fixture читает fixture биржи; вызывается из горутин сервера, поэтому без Fatal
+func (s *conformanceServer) fixture(name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", s.name, name))
+	if err != nil {
+		s.t.Errorf("read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+func (s *conformanceServer) handle(w http.ResponseWriter, r *http.Request) {
+	if s.scenario.WS.Path != "" && r.URL.Path == s.scenario.WS.Path {
+		s.handleWS(w, r)
+		return
+	}
+
+	body, _ := io.ReadAll(r.Body)
+	path := strings.TrimPrefix(r.URL.Path, s.scenario.RESTPrefix)
+	raw := r.URL.RawQuery + "\n" + string(body)
+
+	s.mu.Lock()
+	s.requests = append(s.requests, conformanceRequest{Method: r.Method, Path: path, Raw: raw})
+	failure := s.failure
+	s.mu.Unlock()
+
+	if failure != nil {
+		s.respond(w, *failure)
+		return
+	}
+
+	for _, route := range s.scenario.Routes {
+		if route.Method != r.Method || route.Path != path || !strings.Contains(raw, route.Match) {
+			continue
+		}
+		if route.Signed && !s.authorized(r) {
+			s.t.Errorf("%s %s: API key not sent", r.Method, path)
+		}
+		s.respond(w, route)
+		return
+	}
+
+	s.t.Errorf("unexpected request %s %s %q", r.Method, path, raw)
+	http.NotFound(w, r)
+}
+
+// authorized проверяет, что подписанный запрос несёт API ключ
+func (s *conformanceServer) authorized(r *http.Request) bool {
+	auth := s.scenario.Auth
+	if auth.Header != "" {
+		return r.Header.Get(auth.Header) == conformanceAPIKey
+	}
+	return r.URL.Query().Get(auth.Param) == conformanceAPIKey
+}
+
+func (s *conformanceServer) respond(w http.ResponseWriter, route conformanceRoute) {
+	status := route.Status
+	if status == 0 {
+		status = http.StatusOK
+	}
+
+	var body []byte
+	if strings.HasSuffix(route.Fixture, ".json") {
+		body = s.fixture(route.Fixture)
+	} else {
+		body = []byte(route.Fixture)
+	}
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// fail задаёт ответ на все последующие запросы; nil возвращает маршруты сценария
+func (s *conformanceServer) fail(route *conformanceRoute) {
+	s.mu.Lock()
+	s.failure = route
+	s.mu.Unlock()
+}
+
+// mark возвращает позицию в журнале запросов для since
+func (s *conformanceServer) mark() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.requests)
+}
+
+// since возвращает запросы, принятые после mark
+func (s *conformanceServer) since(mark int) []conformanceRequest {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]conformanceRequest(nil), s.requests[mark:]...)
+}
+
+// handleWS отвечает на подписку на тикер сообщениями из fixtures
+func (s *conformanceServer) handleWS(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	s.mu.Lock()
+	s.wsConns = append(s.wsConns, conn)
+	s.wsDials++
+	s.mu.Unlock()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if !strings.Contains(string(message), s.scenario.WS.Subscribe) {
+			continue
+		}
+
+		s.mu.Lock()
+		s.wsSubs++
+		s.mu.Unlock()
+
+		for _, name := range s.scenario.WS.Messages {
+			if err := s.sendWS(conn, s.fixture(name)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// sendWS отправляет сообщение, сжимая его gzip для бирж со сжатым потоком
+func (s *conformanceServer) sendWS(conn *websocket.Conn, message []byte) error {
+	message = bytes.TrimSpace(message)
+	if !s.scenario.WS.Gzip {
+		return conn.WriteMessage(websocket.TextMessage, message)
+	}
+
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	zw.Write(message)
+	zw.Close()
+	return conn.WriteMessage(websocket.BinaryMessage, buf.Bytes())
+}
+
+// dropWS разрывает все WebSocket соединения, имитируя обрыв на стороне биржи
+func (s *conformanceServer) dropWS() {
+	s.mu.Lock()
+	conns := s.wsConns
+	s.wsConns = nil
+	s.mu.Unlock()
+
+	for _, conn := range conns {
+		conn.Close()
+	}
+}
+
+// wsStats возвращает число подключений и подписок на тикер
+func (s *conformanceServer) wsStats() (dials, subs int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.wsDials, s.wsSubs
+}
+
+// loadConformanceScenario читает сценарий биржи
+func loadConformanceScenario(t *testing.T, name string) *conformanceScenario {
+	data, err := os.ReadFile(filepath.Join("testdata", name, "conformance.json"))
+	if err != nil {
+		t.Fatalf("no conformance scenario for %s: add testdata/%s/conformance.json (%v)", name, name, err)
+	}
+
+	var scenario conformanceScenario
+	if err := json.Unmarshal(data, &scenario); err != nil {
+		t.Fatalf("parse conformance scenario: %v", err)
+	}
+	return &scenario
+}
+
+// TestConformance прогоняет каждый зарегистрированный адаптер через общий набор проверок
+func TestConformance(t *testing.T) {
+	for _, adapter := range Adapters() {
+		adapter := adapter
+		t.Run(adapter.Name, func(t *testing.T) {
+			t.Parallel()
+			runConformance(t, adapter)
+		})
+	}
+}
+
+func runConformance(t *testing.T, adapter Adapter) {
+	scenario := loadConformanceScenario(t, adapter.Name)
+	server := newConformanceServer(t, adapter.Name, scenario)
+
+	exch := adapter.New()
+	setter, ok := exch.(EndpointSetter)
+	if !ok {
+		t.Fatalf("%s adapter does not implement EndpointSetter", adapter.Name)
+	}
+	setter.SetEndpoints(server.endpoints())
+	defer exch.Close()
+
+	if err := exch.Connect(conformanceAPIKey, conformanceSecret, conformancePassphrase); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if exch.GetName() != adapter.Name {
+		t.Fatalf("GetName() = %q, want %q", exch.GetName(), adapter.Name)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	t.Run("Balance", func(t *testing.T) {
+		balance, err := exch.GetBalance(ctx)
+		if err != nil {
+			t.Fatalf("GetBalance: %v", err)
+		}
+		assertConformanceValue(t, "balance", balance, scenario.Expect.Balance)
+	})
+
+	t.Run("Ticker", func(t *testing.T) {
+		mark := server.mark()
+		ticker, err := exch.GetTicker(ctx, scenario.Symbol)
+		if err != nil {
+			t.Fatalf("GetTicker: %v", err)
+		}
+		assertVenueSymbol(t, server.since(mark), scenario.VenueSymbol)
+		assertConformanceTicker(t, ticker, scenario.Symbol, scenario.Expect.Ticker)
+	})
+
+	t.Run("OrderBook", func(t *testing.T) {
+		mark := server.mark()
+		book, err := exch.GetOrderBook(ctx, scenario.Symbol, 5)
+		if err != nil {
+			t.Fatalf("GetOrderBook: %v", err)
+		}
+		assertVenueSymbol(t, server.since(mark), scenario.VenueSymbol)
+
+		if book.Symbol != scenario.Symbol {
+			t.Errorf("order book symbol %q, want %q", book.Symbol, scenario.Symbol)
+		}
+		if len(book.Bids) == 0 || len(book.Asks) == 0 {
+			t.Fatalf("empty order book: %d bids, %d asks", len(book.Bids), len(book.Asks))
+		}
+		if !sort.SliceIsSorted(book.Bids, func(i, j int) bool { return book.Bids[i].Price > book.Bids[j].Price }) {
+			t.Errorf("bids not sorted descending: %v", book.Bids)
+		}
+		if !sort.SliceIsSorted(book.Asks, func(i, j int) bool { return book.Asks[i].Price < book.Asks[j].Price }) {
+			t.Errorf("asks not sorted ascending: %v", book.Asks)
+		}
+		for _, level := range append(append([]PriceLevel(nil), book.Bids...), book.Asks...) {
+			assertFinite(t, "level price", level.Price)
+			assertFinite(t, "level volume", level.Volume)
+			if level.Price <= 0 || level.Volume <= 0 {
+				t.Errorf("non-positive level %+v", level)
+			}
+		}
+		assertConformanceValue(t, "best bid", book.Bids[0].Price, scenario.Expect.BestBid)
+		assertConformanceValue(t, "best ask", book.Asks[0].Price, scenario.Expect.BestAsk)
+	})
+
+	t.Run("MarketOrder", func(t *testing.T) {
+		for _, side := range []string{SideBuy, SideSell} {
+			mark := server.mark()
+			order, err := exch.PlaceMarketOrder(ctx, scenario.Symbol, side, scenario.Order.Qty, conformanceClientID)
+			if err != nil {
+				t.Fatalf("PlaceMarketOrder %s: %v", side, err)
+			}
+
+			want := scenario.Order.Buy
+			if side == SideSell {
+				want = scenario.Order.Sell
+			}
+			want = append(append([]string(nil), want...), scenario.Order.ClientID)
+			assertOrderRequest(t, server.since(mark), scenario.Order.Path, scenario.VenueSymbol, want)
+
+			if order.ID == "" || order.Symbol != scenario.Symbol || order.Side != side {
+				t.Errorf("%s order: id %q symbol %q side %q", side, order.ID, order.Symbol, order.Side)
+			}
+			if order.ClientOrderID != conformanceClientID {
+				t.Errorf("%s order client id %q, want %q", side, order.ClientOrderID, conformanceClientID)
+			}
+			assertConformanceValue(t, side+" quantity", order.Quantity, scenario.Order.Qty)
+			assertFinite(t, side+" filled", order.FilledQty)
+			assertFinite(t, side+" avg price", order.AvgFillPrice)
+			if order.FilledQty <= 0 || order.AvgFillPrice < 0 {
+				t.Errorf("%s order: filled %v avg price %v", side, order.FilledQty, order.AvgFillPrice)
+			}
+		}
+	})
+
+	t.Run("CloseOrder", func(t *testing.T) {
+		closer, ok := exch.(PositionCloser)
+		if !ok {
+			if scenario.Order.PositionMode != "" {
+				t.Fatalf("%s adapter does not implement PositionCloser", adapter.Name)
+			}
+			t.Skip("adapter without position modes")
+		}
+		if mode := closer.PositionMode(); mode != scenario.Order.PositionMode {
+			t.Fatalf("PositionMode() = %q, want %q", mode, scenario.Order.PositionMode)
+		}
+
+		for _, side := range []string{SideLong, SideShort} {
+			mark := server.mark()
+			order, err := closer.PlaceCloseOrder(ctx, scenario.Symbol, side, scenario.Order.Qty, conformanceClientID)
+			if err != nil {
+				t.Fatalf("PlaceCloseOrder %s: %v", side, err)
+			}
+
+			want := scenario.Order.CloseLong
+			if side == SideShort {
+				want = scenario.Order.CloseShort
+			}
+			want = append(append([]string(nil), want...), scenario.Order.ClientID)
+			assertOrderRequest(t, server.since(mark), scenario.Order.Path, scenario.VenueSymbol, want)
+
+			if order.Side != CloseOrderSide(side) {
+				t.Errorf("close %s order side %q, want %q", side, order.Side, CloseOrderSide(side))
+			}
+		}
+	})
+
+	t.Run("OrderByClientID", func(t *testing.T) {
+		defer server.fail(nil)
+
+		found := scenario.Lookup.Found
+		server.fail(&found)
+		mark := server.mark()
+		order, err := exch.GetOrderByClientID(ctx, scenario.Symbol, conformanceClientID)
+		if err != nil {
+			t.Fatalf("GetOrderByClientID: %v", err)
+		}
+		assertClientIDRequest(t, server.since(mark), scenario.Order.ClientID)
+		if order.ID == "" || order.Symbol != scenario.Symbol || order.ClientOrderID != conformanceClientID {
+			t.Errorf("order: id %q symbol %q client id %q", order.ID, order.Symbol, order.ClientOrderID)
+		}
+		if order.Status != OrderStatusFilled || order.FilledQty <= 0 {
+			t.Errorf("order: status %q filled %v", order.Status, order.FilledQty)
+		}
+
+		// Неизвестный ордер - ErrOrderNotFound: по нему исполнитель решает, что повтор безопасен
+		missing := scenario.Lookup.Missing
+		server.fail(&missing)
+		_, err = exch.GetOrderByClientID(ctx, scenario.Symbol, conformanceClientID)
+		if !errors.Is(err, ErrOrderNotFound) {
+			t.Errorf("expected ErrOrderNotFound, got %v", err)
+		}
+	})
+
+	t.Run("Positions", func(t *testing.T) {
+		positions, err := exch.GetOpenPositions(ctx)
+		if err != nil {
+			t.Fatalf("GetOpenPositions: %v", err)
+		}
+		// Нулевые позиции в fixtures должны быть пропущены
+		if len(positions) != len(scenario.Expect.Positions) {
+			t.Fatalf("got %d positions, want %d", len(positions), len(scenario.Expect.Positions))
+		}
+
+		for _, want := range scenario.Expect.Positions {
+			var got *Position
+			for _, pos := range positions {
+				if pos.Side == want.Side {
+					got = pos
+				}
+			}
+			if got == nil {
+				t.Errorf("no %s position in %+v", want.Side, positions)
+				continue
+			}
+			if got.Symbol != scenario.Symbol {
+				t.Errorf("%s position symbol %q, want %q", want.Side, got.Symbol, scenario.Symbol)
+			}
+			assertConformanceValue(t, want.Side+" size", got.Size, want.Size)
+			assertConformanceValue(t, want.Side+" entry price", got.EntryPrice, want.EntryPrice)
+			assertConformanceValue(t, want.Side+" mark price", got.MarkPrice, want.MarkPrice)
+			assertConformanceValue(t, want.Side+" pnl", got.UnrealizedPnl, want.Pnl)
+			if got.Leverage != want.Leverage {
+				t.Errorf("%s leverage %d, want %d", want.Side, got.Leverage, want.Leverage)
+			}
+		}
+	})
+
+	t.Run("Funding", func(t *testing.T) {
+		funding, err := exch.GetFundingRate(ctx, scenario.Symbol)
+		if err != nil {
+			t.Fatalf("GetFundingRate: %v", err)
+		}
+		if funding.Symbol != scenario.Symbol {
+			t.Errorf("funding symbol %q, want %q", funding.Symbol, scenario.Symbol)
+		}
+		assertConformanceValue(t, "funding rate", funding.Rate, scenario.Expect.Funding)
+		assertFinite(t, "predicted rate", funding.PredictedRate)
+		if funding.Interval <= 0 {
+			t.Errorf("funding interval %v", funding.Interval)
+		}
+	})
+
+	t.Run("MarginSettings", func(t *testing.T) {
+		reader, ok := exch.(MarginReader)
+		if !ok {
+			t.Fatalf("%s adapter does not implement MarginReader", adapter.Name)
+		}
+
+		mark := server.mark()
+		leverage, mode, err := reader.GetMarginSettings(ctx, scenario.Symbol)
+		if err != nil {
+			t.Fatalf("GetMarginSettings: %v", err)
+		}
+		assertVenueSymbol(t, server.since(mark), scenario.VenueSymbol)
+		if leverage != scenario.Expect.Margin.Leverage || mode != scenario.Expect.Margin.Mode {
+			t.Errorf("margin settings %dx %s, want %dx %s", leverage, mode, scenario.Expect.Margin.Leverage, scenario.Expect.Margin.Mode)
+		}
+	})
+
+	t.Run("FundingPayments", func(t *testing.T) {
+		reader, ok := exch.(FundingHistoryReader)
+		if !ok {
+			t.Fatalf("%s adapter does not implement FundingHistoryReader", adapter.Name)
+		}
+
+		payments, err := reader.GetFundingPayments(ctx, scenario.Symbol, conformanceFundingFrom, conformanceFundingTo)
+		if err != nil {
+			t.Fatalf("GetFundingPayments: %v", err)
+		}
+		if len(payments) != len(scenario.Expect.FundingPayments) {
+			t.Fatalf("got %d funding payments, want %d: %+v", len(payments), len(scenario.Expect.FundingPayments), payments)
+		}
+		for i, p := range payments {
+			if p.Symbol != scenario.Symbol {
+				t.Errorf("funding payment symbol %q, want %q", p.Symbol, scenario.Symbol)
+			}
+			if p.Time.Before(conformanceFundingFrom) || p.Time.After(conformanceFundingTo) {
+				t.Errorf("funding payment time %v outside requested period", p.Time)
+			}
+			assertConformanceValue(t, "funding payment", p.Amount, scenario.Expect.FundingPayments[i])
+		}
+	})
+
+	t.Run("Errors", func(t *testing.T) {
+		defer server.fail(nil)
+
+		// Отказ биржи: код ошибки из ответа
+		rejected := scenario.Order.Error.conformanceRoute
+		server.fail(&rejected)
+		_, err := exch.PlaceMarketOrder(ctx, scenario.Symbol, SideBuy, scenario.Order.Qty, "")
+		assertExchangeError(t, err, adapter.Name, scenario.Order.Error.Code)
+
+		// Ответ без кода биржи (HTML балансировщика): кодом становится HTTP статус
+		server.fail(&conformanceRoute{Status: http.StatusBadGateway, Fixture: "<html><body>502 Bad Gateway</body></html>"})
+		_, err = exch.GetTicker(ctx, scenario.Symbol)
+		assertExchangeError(t, err, adapter.Name, "502")
+	})
+
+	t.Run("WebSocketTicker", func(t *testing.T) {
+		tickers := make(chan *Ticker, 16)
+		if err := exch.SubscribeTicker(scenario.Symbol, func(ticker *Ticker) {
+			tickers <- ticker
+		}); err != nil {
+			t.Fatalf("SubscribeTicker: %v", err)
+		}
+
+		// Первое обновление, затем обрыв соединения: адаптер должен переподключиться
+		// и повторить подписку без участия вызывающего кода
+		assertConformanceTicker(t, waitConformanceTicker(t, tickers, 5*time.Second), scenario.Symbol, scenario.Expect.WSTicker)
+		for len(tickers) > 0 {
+			<-tickers
+		}
+		server.dropWS()
+		assertConformanceTicker(t, waitConformanceTicker(t, tickers, 15*time.Second), scenario.Symbol, scenario.Expect.WSTicker)
+
+		if dials, subs := server.wsStats(); dials < 2 || subs < 2 {
+			t.Errorf("expected reconnect with resubscribe, got %d dials and %d subscriptions", dials, subs)
+		}
+	})
+}
+
+// waitConformanceTicker ждёт обновление тикера из WebSocket
+func waitConformanceTicker(t *testing.T, tickers <-chan *Ticker, timeout time.Duration) *Ticker {
+	t.Helper()
+	select {
+	case ticker := <-tickers:
+		return ticker
+	case <-time.After(timeout):
+		t.Fatalf("no ticker update within %v", timeout)
+		return nil
+	}
+}
+
+func assertConformanceTicker(t *testing.T, ticker *Ticker, symbol string, want conformanceTicker) {
+	t.Helper()
+	if ticker.Symbol != symbol {
+		t.Errorf("ticker symbol %q, want %q", ticker.Symbol, symbol)
+	}
+	assertConformanceValue(t, "bid", ticker.BidPrice, want.Bid)
+	assertConformanceValue(t, "ask", ticker.AskPrice, want.Ask)
+	assertConformanceValue(t, "last", ticker.LastPrice, want.Last)
+}
+
+// assertConformanceValue сравнивает число с ожидаемым с учётом погрешности float
+func assertConformanceValue(t *testing.T, name string, got, want float64) {
+	t.Helper()
+	assertFinite(t, name, got)
+	if math.Abs(got-want) > 1e-9*math.Max(1, math.Abs(want)) {
+		t.Errorf("%s = %v, want %v", name, got, want)
+	}
+}
+
+func assertFinite(t *testing.T, name string, value float64) {
+	t.Helper()
+	if math.IsNaN(value) || math.IsInf(value, 0) {
+		t.Errorf("%s is not finite: %v", name, value)
+	}
+}
+
+// assertVenueSymbol проверяет, что символ ушёл на биржу в её формате
+func assertVenueSymbol(t *testing.T, requests []conformanceRequest, venueSymbol string) {
+	t.Helper()
+	for _, req := range requests {
+		if strings.Contains(req.Raw, venueSymbol) || strings.Contains(req.Path, venueSymbol) {
+			return
+		}
+	}
+	t.Errorf("no request carried venue symbol %q: %+v", venueSymbol, requests)
+}
+
+// assertOrderRequest проверяет запрос размещения ордера: символ биржи и направление
+func assertOrderRequest(t *testing.T, requests []conformanceRequest, path, venueSymbol string, want []string) {
+	t.Helper()
+	for _, req := range requests {
+		if req.Method != http.MethodPost || req.Path != path {
+			continue
+		}
+		if !strings.Contains(req.Raw, venueSymbol) {
+			t.Errorf("order request %q does not carry venue symbol %q", req.Raw, venueSymbol)
+		}
+		for _, s := range want {
+			if !strings.Contains(req.Raw, s) {
+				t.Errorf("order request %q does not contain %q", req.Raw, s)
+			}
+		}
+		return
+	}
+	t.Errorf("no POST %s request in %+v", path, requests)
+}
+
+// assertClientIDRequest проверяет, что поиск ордера передал клиентский ID (в пути или параметрах)
+func assertClientIDRequest(t *testing.T, requests []conformanceRequest, clientID string) {
+	t.Helper()
+	for _, req := range requests {
+		if strings.Contains(req.Path, clientID) || strings.Contains(req.Raw, clientID) {
+			return
+		}
+	}
+	t.Errorf("no request with client id %q in %+v", clientID, requests)
+}
+
+// assertExchangeError проверяет, что ошибка обёрнута в ExchangeError с кодом
+func assertExchangeError(t *testing.T, err error, exchange, code string) {
+	t.Helper()
+	var exchErr *ExchangeError
+	if !errors.As(err, &exchErr) {
+		t.Fatalf("expected *ExchangeError, got %T: %v", err, err)
+	}
+	if exchErr.Exchange != exchange || exchErr.Code != code {
+		t.Errorf("ExchangeError exchange %q code %q, want %q %q", exchErr.Exchange, exchErr.Code, exchange, code)
+	}
+}
@@ -5,45 +5,28 @@ import (
 	"strings"
 )
 
-// SupportedExchanges - список поддерживаемых бирж
-var SupportedExchanges = []string{
-	"bybit",
-	"bitget",
-	"okx",
-	"gate",
-	"htx",
-	"bingx",
-}
-
-// NewExchange создает новый экземпляр биржи по имени
+// NewExchange создает новый экземпляр биржи по имени из реестра адаптеров
+// Имя вида "sim:bybit" возвращает симулятор (paper trading) с параметрами указанной биржи
 func NewExchange(name string) (Exchange, error) {
 	name = strings.ToLower(name)
 
-	switch name {
-	case "bybit":
-		return NewBybit(), nil
-	case "bitget":
-		return NewBitget(), nil
-	case "okx":
-		return NewOKX(), nil
-	case "gate":
-		return NewGate(), nil
-	case "htx":
-		return NewHTX(), nil
-	case "bingx":
-		return NewBingX(), nil
-	default:
+	if strings.HasPrefix(name, simPrefix) {
+		venue := strings.TrimPrefix(name, simPrefix)
+		if !IsSupported(venue) {
+			return nil, fmt.Errorf("unsupported exchange for simulation: %s", venue)
+		}
+		return NewSim(DefaultSimConfig(venue)), nil
+	}
+
+	adapter, ok := LookupAdapter(name)
+	if !ok {
 		return nil, fmt.Errorf("unsupported exchange: %s", name)
 	}
+	return adapter.New(), nil
 }
 
-// IsSupported проверяет, поддерживается ли биржа
+// IsSupported проверяет, зарегистрирован ли адаптер биржи
 func IsSupported(name string) bool {
-	name = strings.ToLower(name)
-	for _, supported := range SupportedExchanges {
-		if name == supported {
-			return true
-		}
-	}
-	return false
+	_, ok := LookupAdapter(name)
+	return ok
 }
@@ -0,0 +1,40 @@
+package exchange
+
+import (
+	"context"
+	"time"
+)
+
+// ============================================================
+// История начислений фандинга
+// ============================================================
+//
+// Ставка из GetFundingRate даёт только оценку начисления: биржа считает его
+// по своей mark-цене в момент расчёта и по фактическому размеру позиции.
+// Фактическое начисление читается из истории доходов аккаунта
+// (income / transaction log / bills) и публикуется с задержкой после расчёта.
+
+// FundingPayment - начисление фандинга по позиции аккаунта
+type FundingPayment struct {
+	Symbol string    `json:"symbol"`
+	Amount float64   `json:"amount"` // USDT: > 0 получено, < 0 уплачено
+	Time   time.Time `json:"time"`   // время расчёта
+}
+
+// FundingHistoryReader реализуют адаптеры, умеющие читать фактические начисления фандинга
+type FundingHistoryReader interface {
+	// GetFundingPayments возвращает начисления фандинга символа за период [from, to]
+	GetFundingPayments(ctx context.Context, symbol string, from, to time.Time) ([]FundingPayment, error)
+}
+
+// fundingPaymentsBetween оставляет начисления периода [from, to]
+// Биржи округляют границы запроса до секунд или страниц, поэтому период проверяется ещё раз
+func fundingPaymentsBetween(payments []FundingPayment, from, to time.Time) []FundingPayment {
+	result := payments[:0]
+	for _, p := range payments {
+		if !p.Time.Before(from) && !p.Time.After(to) {
+			result = append(result, p)
+		}
+	}
+	return result
+}
@@ -22,6 +22,9 @@ import (
 const (
 	gateBaseURL   = "https://api.gateio.ws/api/v4"
 	gateWSURL     = "wss://fx-ws.gateio.ws/v4/ws/usdt"
+
+	// gateClientPrefix - обязательный префикс пользовательского поля text ордера
+	gateClientPrefix = "t-"
 )
 
 type Gate struct {
@@ -29,6 +32,10 @@ type Gate struct {
 	secretKey string
 
 	httpClient *http.Client
+	endpoints  Endpoints       // адреса API, подменяются SetEndpoints
+	limiter    *requestLimiter // лимиты REST запросов
+	clock      *Clock          // смещение часов биржи для подписи запросов
+	health     *ExchangeHealth // состояние биржи для отключения от входов
 
 	// WebSocket manager с автоматическим переподключением
 	wsManager *WSReconnectManager
@@ -36,20 +43,89 @@ type Gate struct {
 
 	tickerCallbacks  map[string]func(*Ticker)
 	positionCallback func(*Position)
+	orderCallback    func(*Order)
 	callbackMu       sync.RWMutex
 
+	// Локальные стаканы SubscribeOrderBook
+	books      orderBookSubscriptions
+	bookSyncs  map[string]*gateBookSync
+	bookSyncMu sync.Mutex
+
+	// Спецификации контрактов: размеры Gate в целых контрактах по quanto_multiplier монет
+	instruments *InstrumentRegistry
+
+	// Режим маржи Gate задаётся через плечо: leverage=0 - cross, > 0 - isolated
+	marginModes   map[string]string // symbol -> MarginModeCross / MarginModeIsolated
+	marginModesMu sync.RWMutex
+
 	connected bool
 	closeChan chan struct{}
 }
 
+// init регистрирует адаптер Gate.io в реестре бирж
+func init() {
+	Register(Adapter{
+		Name: "gate",
+		New:  func() Exchange { return NewGate() },
+		Capabilities: Capabilities{
+			PrivateWS:   true,
+			LimitOrders: true,
+			Funding:     true,
+			HedgeMode:   false,
+		},
+		TakerFee: 0.0005,
+		MakerFee: 0.0002,
+		// 200 запросов за 10 секунд на эндпоинт
+		RateLimits: RateLimits{
+			Orders:  20,
+			Market:  20,
+			Account: 20,
+			Endpoints: map[string]EndpointLimit{
+				"/futures/usdt/orders": {Category: RateCategoryOrders},
+			},
+			RemainHeader: "X-Gate-RateLimit-Requests-Remain",
+			LimitHeader:  "X-Gate-RateLimit-Limit",
+		},
+	})
+}
+
 // NewGate создаёт новый экземпляр Gate.io
 // Использует глобальный HTTP клиент с connection pooling и оптимизированными таймаутами
 func NewGate() *Gate {
-	return &Gate{
+	g := &Gate{
 		httpClient:      GetGlobalHTTPClient().GetClient(),
+		endpoints:       Endpoints{REST: gateBaseURL, WSPublic: gateWSURL, WSPrivate: gateWSURL},
+		limiter:         newRequestLimiter("gate"),
+		health:          HealthOf("gate"),
 		tickerCallbacks: make(map[string]func(*Ticker)),
+		marginModes:     make(map[string]string),
 		closeChan:       make(chan struct{}),
 	}
+	g.instruments = NewInstrumentRegistry("gate", g.loadInstruments)
+	g.clock = NewClock("gate", g.getServerTime, "REQUEST_EXPIRED")
+	return g
+}
+
+// SetEndpoints подменяет адреса REST и WebSocket API
+func (g *Gate) SetEndpoints(endpoints Endpoints) {
+	g.endpoints = endpoints
+}
+
+// getServerTime запрашивает время сервера для синхронизации часов
+// Отдельного эндпоинта у фьючерсов нет, время общее для API v4
+func (g *Gate) getServerTime(ctx context.Context) (time.Time, error) {
+	body, err := g.doRequest(ctx, http.MethodGet, "/spot/time", nil, false)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var resp struct {
+		ServerTime int64 `json:"server_time"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(resp.ServerTime), nil
 }
 
 // sign создает подпись для Gate.io API
@@ -75,10 +151,28 @@ func (g *Gate) parseFloat(value, field string) float64 {
 	return result
 }
 
+// doRequest выполняет запрос к Gate API и учитывает его в состоянии биржи
 func (g *Gate) doRequest(ctx context.Context, method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
+	start := time.Now()
+	body, err := g.sendRequest(ctx, method, endpoint, params, signed)
+	g.health.ObserveRequest(time.Since(start), err)
+	return body, err
+}
+
+// sendRequest выполняет HTTP запрос к Gate API
+func (g *Gate) sendRequest(ctx context.Context, method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
 	var reqBody string
 	var queryString string
-	reqURL := gateBaseURL + endpoint
+
+	// Некоторые POST эндпоинты принимают параметры только в query (например, leverage)
+	if i := strings.IndexByte(endpoint, '?'); i >= 0 {
+		queryString = endpoint[i+1:]
+		endpoint = endpoint[:i]
+	}
+	reqURL := g.endpoints.REST + endpoint
+	if queryString != "" {
+		reqURL += "?" + queryString
+	}
 
 	if method == http.MethodGet {
 		if len(params) > 0 {
@@ -105,7 +199,7 @@ func (g *Gate) doRequest(ctx context.Context, method, endpoint string, params ma
 	req.Header.Set("Accept", "application/json")
 
 	if signed {
-		timestamp := time.Now().Unix()
+		timestamp := g.clock.Now().Unix()
 		signature := g.sign(method, endpoint, queryString, reqBody, timestamp)
 
 		req.Header.Set("KEY", g.apiKey)
@@ -113,11 +207,16 @@ func (g *Gate) doRequest(ctx context.Context, method, endpoint string, params ma
 		req.Header.Set("Timestamp", strconv.FormatInt(timestamp, 10))
 	}
 
+	if err := g.limiter.Wait(ctx, endpoint, signed); err != nil {
+		return nil, err
+	}
+
 	resp, err := g.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	g.limiter.Observe(endpoint, signed, resp)
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -129,14 +228,14 @@ func (g *Gate) doRequest(ctx context.Context, method, endpoint string, params ma
 			Label   string `json:"label"`
 			Message string `json:"message"`
 		}
-		if err := json.Unmarshal(body, &errResp); err == nil {
-			return nil, &ExchangeError{
-				Exchange: "gate",
-				Code:     errResp.Label,
-				Message:  errResp.Message,
-			}
+		if err := json.Unmarshal(body, &errResp); err != nil || errResp.Label == "" {
+			return nil, httpStatusError("gate", resp.StatusCode, body, err)
 		}
-		return nil, fmt.Errorf("gate API error: %s", string(body))
+		return nil, g.limiter.CheckError(g.clock.CheckError(&ExchangeError{
+			Exchange: "gate",
+			Code:     errResp.Label,
+			Message:  errResp.Message,
+		}))
 	}
 
 	return body, nil
@@ -149,6 +248,11 @@ func (g *Gate) Connect(apiKey, secret, passphrase string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Смещение часов нужно до первого подписанного запроса; при ошибке подпись идёт по локальному времени
+	if err := g.clock.Sync(ctx); err != nil {
+		log.Printf("[gate] %v", err)
+	}
+
 	_, err := g.GetBalance(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to connect to Gate.io: %w", err)
@@ -226,6 +330,11 @@ func (g *Gate) GetOrderBook(ctx context.Context, symbol string, depth int) (*Ord
 		depth = 100
 	}
 
+	inst, err := g.instruments.Get(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
 	contract := g.toGateSymbol(symbol)
 
 	params := map[string]string{
@@ -277,13 +386,21 @@ func (g *Gate) GetOrderBook(ctx context.Context, symbol string, depth int) (*Ord
 		return orderBook.Asks[i].Price < orderBook.Asks[j].Price
 	})
 
+	// Объёмы стакана Gate в контрактах
+	inst.ScaleOrderBook(orderBook)
+
 	return orderBook, nil
 }
 
-func (g *Gate) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64) (*Order, error) {
+func (g *Gate) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*Order, error) {
 	contract := g.toGateSymbol(symbol)
 
-	size := int64(qty)
+	inst, contracts, err := g.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return nil, err
+	}
+
+	size := int64(contracts)
 	if side == SideSell || side == SideShort {
 		size = -size // Отрицательное значение для продажи
 	}
@@ -294,6 +411,9 @@ func (g *Gate) PlaceMarketOrder(ctx context.Context, symbol, side string, qty fl
 		"price":    "0", // Market order
 		"tif":      "ioc",
 	}
+	if clientOrderID != "" {
+		params["text"] = gateClientPrefix + clientOrderID
+	}
 
 	body, err := g.doRequest(ctx, http.MethodPost, "/futures/usdt/orders", params, true)
 	if err != nil {
@@ -314,22 +434,23 @@ func (g *Gate) PlaceMarketOrder(ctx context.Context, symbol, side string, qty fl
 	}
 
 	fillPrice := g.parseFloat(resp.FillPrice, "fillPrice")
-	filledSize := qty - float64(resp.Left)
-	if filledSize < 0 {
-		filledSize = -filledSize
+	left := resp.Left
+	if left < 0 {
+		left = -left
 	}
 
 	return &Order{
-		ID:           strconv.FormatInt(resp.Id, 10),
-		Symbol:       symbol,
-		Side:         side,
-		Type:         "market",
-		Quantity:     qty,
-		FilledQty:    filledSize,
-		AvgFillPrice: fillPrice,
-		Status:       OrderStatusFilled,
-		CreatedAt:    time.Now(),
-		UpdatedAt:    time.Now(),
+		ID:            strconv.FormatInt(resp.Id, 10),
+		ClientOrderID: clientOrderID,
+		Symbol:        symbol,
+		Side:          side,
+		Type:          "market",
+		Quantity:      inst.FromVenueSize(contracts),
+		FilledQty:     inst.FromVenueSize(contracts - float64(left)),
+		AvgFillPrice:  fillPrice,
+		Status:        OrderStatusFilled,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}, nil
 }
 
@@ -339,15 +460,17 @@ func (g *Gate) GetOpenPositions(ctx context.Context) ([]*Position, error) {
 		return nil, err
 	}
 
+	// REST отдаёт плечо строкой; в cross режиме leverage = "0", плечо в cross_leverage_limit
 	var resp []struct {
-		Contract      string `json:"contract"`
-		Size          int64  `json:"size"`
-		EntryPrice    string `json:"entry_price"`
-		MarkPrice     string `json:"mark_price"`
-		Leverage      int    `json:"leverage"`
-		UnrealisedPnl string `json:"unrealised_pnl"`
-		LiqPrice      string `json:"liq_price"`
-		UpdateTime    int64  `json:"update_time"`
+		Contract           string `json:"contract"`
+		Size               int64  `json:"size"`
+		EntryPrice         string `json:"entry_price"`
+		MarkPrice          string `json:"mark_price"`
+		Leverage           string `json:"leverage"`
+		CrossLeverageLimit string `json:"cross_leverage_limit"`
+		UnrealisedPnl      string `json:"unrealised_pnl"`
+		LiqPrice           string `json:"liq_price"`
+		UpdateTime         int64  `json:"update_time"`
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
@@ -360,9 +483,19 @@ func (g *Gate) GetOpenPositions(ctx context.Context) ([]*Position, error) {
 			continue
 		}
 
+		symbol := g.fromGateSymbol(p.Contract)
+		inst, err := g.instruments.Get(ctx, symbol)
+		if err != nil {
+			return nil, err
+		}
+
 		entryPrice := g.parseFloat(p.EntryPrice, "position.entryPrice")
 		markPrice := g.parseFloat(p.MarkPrice, "position.markPrice")
 		unrealizedPnl := g.parseFloat(p.UnrealisedPnl, "position.unrealisedPnl")
+		leverage := int(g.parseFloat(p.Leverage, "position.leverage"))
+		if leverage == 0 {
+			leverage = int(g.parseFloat(p.CrossLeverageLimit, "position.cross_leverage_limit"))
+		}
 
 		side := SideLong
 		size := float64(p.Size)
@@ -372,12 +505,12 @@ func (g *Gate) GetOpenPositions(ctx context.Context) ([]*Position, error) {
 		}
 
 		positions = append(positions, &Position{
-			Symbol:        g.fromGateSymbol(p.Contract),
+			Symbol:        symbol,
 			Side:          side,
-			Size:          size,
+			Size:          inst.FromVenueSize(size),
 			EntryPrice:    entryPrice,
 			MarkPrice:     markPrice,
-			Leverage:      p.Leverage,
+			Leverage:      leverage,
 			UnrealizedPnl: unrealizedPnl,
 			Liquidation:   false,
 			UpdatedAt:     time.Unix(p.UpdateTime, 0),
@@ -390,7 +523,12 @@ func (g *Gate) GetOpenPositions(ctx context.Context) ([]*Position, error) {
 func (g *Gate) ClosePosition(ctx context.Context, symbol, side string, qty float64) error {
 	contract := g.toGateSymbol(symbol)
 
-	size := int64(qty)
+	_, contracts, err := g.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return err
+	}
+
+	size := int64(contracts)
 	if side == SideLong || side == SideBuy {
 		size = -size // Закрываем лонг продажей
 	}
@@ -402,51 +540,438 @@ func (g *Gate) ClosePosition(ctx context.Context, symbol, side string, qty float
 		"tif":      "ioc",
 	}
 
-	_, err := g.doRequest(ctx, http.MethodPost, "/futures/usdt/orders", params, true)
+	_, err = g.doRequest(ctx, http.MethodPost, "/futures/usdt/orders", params, true)
 	return err
 }
 
+// gateTimeInForce - соответствие time in force значениям Gate.io
+var gateTimeInForce = map[string]string{
+	TimeInForceGTC:      "gtc",
+	TimeInForceIOC:      "ioc",
+	TimeInForceFOK:      "fok",
+	TimeInForcePostOnly: "poc",
+}
+
+// gateOrderInfo - ордер в ответах /futures/usdt/orders
+type gateOrderInfo struct {
+	Id         int64   `json:"id"`
+	Text       string  `json:"text"` // t-<client order ID> для ордеров с идентификатором клиента
+	Contract   string  `json:"contract"`
+	Size       int64   `json:"size"` // отрицательный для продажи
+	Left       int64   `json:"left"`
+	Price      string  `json:"price"`
+	FillPrice  string  `json:"fill_price"`
+	Status     string  `json:"status"`    // open, finished
+	FinishAs   string  `json:"finish_as"` // filled, cancelled, ioc, ...
+	Tif        string  `json:"tif"`
+	CreateTime float64 `json:"create_time"`
+	UpdateTime float64 `json:"update_time"`
+}
+
+func (g *Gate) PlaceLimitOrder(ctx context.Context, symbol, side string, qty, price float64, tif string) (*Order, error) {
+	tif, err := normalizeTimeInForce(tif)
+	if err != nil {
+		return nil, err
+	}
+
+	_, contracts, err := g.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return nil, err
+	}
+
+	size := int64(contracts)
+	if side == SideSell || side == SideShort {
+		size = -size
+	}
+
+	params := map[string]string{
+		"contract": g.toGateSymbol(symbol),
+		"size":     strconv.FormatInt(size, 10),
+		"price":    strconv.FormatFloat(price, 'f', -1, 64),
+		"tif":      gateTimeInForce[tif],
+	}
+
+	body, err := g.doRequest(ctx, http.MethodPost, "/futures/usdt/orders", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	// Gate.io возвращает ордер целиком - дополнительный запрос не нужен
+	var info gateOrderInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, err
+	}
+
+	order := g.parseOrder(info)
+	order.Side = side
+	return order, nil
+}
+
+func (g *Gate) CancelOrder(ctx context.Context, symbol, orderID string) error {
+	_, err := g.doRequest(ctx, http.MethodDelete, "/futures/usdt/orders/"+orderID, nil, true)
+	return err
+}
+
+func (g *Gate) GetOrder(ctx context.Context, symbol, orderID string) (*Order, error) {
+	body, err := g.doRequest(ctx, http.MethodGet, "/futures/usdt/orders/"+orderID, nil, true)
+	if err != nil {
+		return nil, orderNotFound(err, "ORDER_NOT_FOUND")
+	}
+
+	var info gateOrderInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, err
+	}
+
+	return g.parseOrder(info), nil
+}
+
+// GetOrderByClientID ищет ордер по полю text
+// Gate.io находит по text активные ордера и завершённые не позднее минуты назад
+func (g *Gate) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*Order, error) {
+	order, err := g.GetOrder(ctx, symbol, gateClientPrefix+clientOrderID)
+	if err != nil {
+		return nil, err
+	}
+	order.ClientOrderID = clientOrderID
+	return order, nil
+}
+
+func (g *Gate) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	params := map[string]string{
+		"status": "open",
+	}
+	if symbol != "" {
+		params["contract"] = g.toGateSymbol(symbol)
+	}
+
+	body, err := g.doRequest(ctx, http.MethodGet, "/futures/usdt/orders", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []gateOrderInfo
+	if err := json.Unmarshal(body, &infos); err != nil {
+		return nil, err
+	}
+
+	orders := make([]*Order, 0, len(infos))
+	for _, info := range infos {
+		orders = append(orders, g.parseOrder(info))
+	}
+	return orders, nil
+}
+
+// parseOrder конвертирует ордер Gate.io в Order
+// Размеры переводятся из контрактов в монеты
+func (g *Gate) parseOrder(info gateOrderInfo) *Order {
+	symbol := g.fromGateSymbol(info.Contract)
+	side := SideBuy
+	size := info.Size
+	left := info.Left
+	if size < 0 {
+		side = SideSell
+		size = -size
+	}
+	if left < 0 {
+		left = -left
+	}
+
+	price := g.parseFloat(info.Price, "order.price")
+	order := &Order{
+		ID:           strconv.FormatInt(info.Id, 10),
+		Symbol:       symbol,
+		Side:         side,
+		Type:         OrderTypeLimit,
+		Price:        price,
+		Quantity:     g.instruments.CoinQty(symbol, float64(size)),
+		FilledQty:    g.instruments.CoinQty(symbol, float64(size-left)),
+		AvgFillPrice: g.parseFloat(info.FillPrice, "order.fillPrice"),
+		CreatedAt:    time.Unix(0, int64(info.CreateTime*1e9)),
+		UpdatedAt:    time.Unix(0, int64(info.UpdateTime*1e9)),
+	}
+	if strings.HasPrefix(info.Text, gateClientPrefix) {
+		order.ClientOrderID = strings.TrimPrefix(info.Text, gateClientPrefix)
+	}
+
+	// Рыночный ордер Gate.io - это IOC с нулевой ценой
+	if price == 0 {
+		order.Type = OrderTypeMarket
+	} else {
+		for tif, gateTIF := range gateTimeInForce {
+			if info.Tif == gateTIF {
+				order.TimeInForce = tif
+			}
+		}
+	}
+
+	switch {
+	case info.Status == "open" && left == size:
+		order.Status = OrderStatusNew
+	case info.Status == "open":
+		order.Status = OrderStatusPartial
+	case info.FinishAs == "filled" || left == 0:
+		order.Status = OrderStatusFilled
+	default: // cancelled, ioc, liquidated, ...
+		order.Status = OrderStatusCancelled
+	}
+
+	return order
+}
+
 func (g *Gate) SubscribeTicker(symbol string, callback func(*Ticker)) error {
 	g.callbackMu.Lock()
 	g.tickerCallbacks[symbol] = callback
 	g.callbackMu.Unlock()
 
+	wsManager, err := g.connectWS()
+	if err != nil {
+		return err
+	}
+
+	contract := g.toGateSymbol(symbol)
+	subMsg := map[string]interface{}{
+		"time":    time.Now().Unix(),
+		"channel": "futures.tickers",
+		"event":   "subscribe",
+		"payload": []string{contract},
+	}
+
+	wsManager.AddSubscription(subMsg)
+	return wsManager.Send(subMsg)
+}
+
+// connectWS возвращает WebSocket manager, подключаясь при необходимости
+// Gate использует одно соединение для публичных и приватных каналов
+func (g *Gate) connectWS() (*WSReconnectManager, error) {
 	// Защита от race condition при инициализации WebSocket manager
 	g.wsMu.Lock()
+	defer g.wsMu.Unlock()
+
 	if g.wsManager == nil {
 		config := DefaultWSReconnectConfig()
-		g.wsManager = NewWSReconnectManager("gate", gateWSURL, config)
+		g.wsManager = NewWSReconnectManager("gate", g.endpoints.WSPublic, config)
+		g.health.TrackWS(g.wsManager)
 
 		g.wsManager.SetOnMessage(g.handleMessage)
 		g.wsManager.SetOnConnect(func() {
 			log.Printf("[gate] WebSocket connected")
 		})
+		// Стаканы перезагружаются по REST при первом обновлении после переподписки
 		g.wsManager.SetOnDisconnect(func(err error) {
+			g.books.invalidateAll()
 			if err != nil {
 				log.Printf("[gate] WebSocket disconnected: %v", err)
 			}
 		})
 
 		if err := g.wsManager.Connect(); err != nil {
-			g.wsMu.Unlock()
-			return fmt.Errorf("failed to connect to WebSocket: %w", err)
+			return nil, fmt.Errorf("failed to connect to WebSocket: %w", err)
 		}
 	}
-	wsManager := g.wsManager
-	g.wsMu.Unlock()
+	return g.wsManager, nil
+}
+
+// SubscribeOrderBook подписывается на futures.order_book_update
+// Gate присылает в потоке только обновления: снимок с id берётся по REST,
+// а пришедшие до него обновления буферизуются и применяются поверх
+func (g *Gate) SubscribeOrderBook(symbol string, depth int, callback func(*OrderBook)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	inst, err := g.instruments.Get(ctx, symbol)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	sub := g.books.add(symbol, depth, inst, callback)
+
+	wsManager, err := g.connectWS()
+	if err != nil {
+		return err
+	}
 
-	contract := g.toGateSymbol(symbol)
 	subMsg := map[string]interface{}{
 		"time":    time.Now().Unix(),
-		"channel": "futures.tickers",
+		"channel": "futures.order_book_update",
 		"event":   "subscribe",
-		"payload": []string{contract},
+		"payload": []string{g.toGateSymbol(symbol), "100ms", strconv.Itoa(gateBookLevel(sub.depth))},
 	}
 
 	wsManager.AddSubscription(subMsg)
 	return wsManager.Send(subMsg)
 }
 
+// gateBookLevel возвращает минимальную глубину потока Gate, покрывающую depth
+func gateBookLevel(depth int) int {
+	switch {
+	case depth <= 20:
+		return 20
+	case depth <= 50:
+		return 50
+	default:
+		return 100
+	}
+}
+
+// gateBookUpdate - обновление futures.order_book_update
+// U/u - id первого и последнего изменения, вошедших в обновление
+type gateBookUpdate struct {
+	Time     int64              `json:"t"`
+	Contract string             `json:"s"`
+	First    int64              `json:"U"`
+	Last     int64              `json:"u"`
+	Bids     []gateBookLevelRaw `json:"b"`
+	Asks     []gateBookLevelRaw `json:"a"`
+}
+
+type gateBookLevelRaw struct {
+	P string `json:"p"`
+	S int64  `json:"s"`
+}
+
+// gateBookLevels конвертирует уровни Gate (объём в контрактах) в BookLevel
+func gateBookLevels(levels []gateBookLevelRaw) []BookLevel {
+	result := make([]BookLevel, len(levels))
+	for i, l := range levels {
+		result[i] = BookLevel{Price: l.P, Size: strconv.FormatInt(l.S, 10)}
+	}
+	return result
+}
+
+// gateBookSync - состояние загрузки снимка стакана символа
+type gateBookSync struct {
+	mu      sync.Mutex
+	loading bool
+	pending []gateBookUpdate
+}
+
+// gateMaxPendingBookUpdates - предел буфера обновлений на время загрузки снимка
+const gateMaxPendingBookUpdates = 1000
+
+// bookSync возвращает состояние синхронизации стакана символа
+func (g *Gate) bookSync(symbol string) *gateBookSync {
+	g.bookSyncMu.Lock()
+	defer g.bookSyncMu.Unlock()
+
+	if g.bookSyncs == nil {
+		g.bookSyncs = make(map[string]*gateBookSync)
+	}
+	state, ok := g.bookSyncs[symbol]
+	if !ok {
+		state = &gateBookSync{}
+		g.bookSyncs[symbol] = state
+	}
+	return state
+}
+
+// handleOrderBookUpdate применяет обновление стакана или буферизует его до загрузки снимка
+func (g *Gate) handleOrderBookUpdate(data json.RawMessage) {
+	var upd gateBookUpdate
+	if err := json.Unmarshal(data, &upd); err != nil {
+		return
+	}
+
+	symbol := g.fromGateSymbol(upd.Contract)
+	sub := g.books.get(symbol)
+	if sub == nil {
+		return
+	}
+
+	state := g.bookSync(symbol)
+	state.mu.Lock()
+	if sub.book.Synced() {
+		err := g.applyBookUpdate(sub.book, upd)
+		if err == nil {
+			state.mu.Unlock()
+			sub.emit()
+			return
+		}
+		log.Printf("[gate] order book %s: %v, reloading snapshot", upd.Contract, err)
+	}
+
+	// Стакан не синхронизирован: копим обновления и загружаем снимок
+	if len(state.pending) < gateMaxPendingBookUpdates {
+		state.pending = append(state.pending, upd)
+	}
+	if !state.loading {
+		state.loading = true
+		go g.loadOrderBook(symbol, sub, state)
+	}
+	state.mu.Unlock()
+}
+
+// applyBookUpdate применяет обновление к синхронизированному стакану
+// Обновления, целиком покрытые снимком, пропускаются; разрыв id - ErrOrderBookGap
+func (g *Gate) applyBookUpdate(book *LocalOrderBook, upd gateBookUpdate) error {
+	seq := book.Seq()
+	if upd.Last <= seq {
+		return nil
+	}
+	if upd.First > seq+1 {
+		book.Invalidate()
+		return ErrOrderBookGap
+	}
+	return book.Apply(gateBookLevels(upd.Bids), gateBookLevels(upd.Asks), seq, upd.Last, time.UnixMilli(upd.Time))
+}
+
+// loadOrderBook загружает снимок стакана по REST и применяет накопленные обновления
+func (g *Gate) loadOrderBook(symbol string, sub *orderBookSubscription, state *gateBookSync) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	snapshot, err := g.getOrderBookSnapshot(ctx, symbol, gateBookLevel(sub.depth))
+	cancel()
+
+	state.mu.Lock()
+	pending := state.pending
+	state.pending = nil
+	state.loading = false
+
+	if err != nil {
+		state.mu.Unlock()
+		// Следующее обновление потока запустит загрузку снова
+		log.Printf("[gate] order book snapshot %s: %v", symbol, err)
+		return
+	}
+
+	sub.book.Reset(gateBookLevels(snapshot.Bids), gateBookLevels(snapshot.Asks), snapshot.ID, time.Now())
+	for _, upd := range pending {
+		if err := g.applyBookUpdate(sub.book, upd); err != nil {
+			state.mu.Unlock()
+			log.Printf("[gate] order book %s: %v after snapshot", symbol, err)
+			return
+		}
+	}
+	state.mu.Unlock()
+
+	sub.emit()
+}
+
+// gateBookSnapshot - снимок стакана с id последнего изменения
+type gateBookSnapshot struct {
+	ID   int64              `json:"id"`
+	Bids []gateBookLevelRaw `json:"bids"`
+	Asks []gateBookLevelRaw `json:"asks"`
+}
+
+// getOrderBookSnapshot запрашивает снимок стакана с id для синхронизации с потоком
+func (g *Gate) getOrderBookSnapshot(ctx context.Context, symbol string, limit int) (*gateBookSnapshot, error) {
+	params := map[string]string{
+		"contract": g.toGateSymbol(symbol),
+		"limit":    strconv.Itoa(limit),
+		"with_id":  "true",
+	}
+
+	body, err := g.doRequest(ctx, http.MethodGet, "/futures/usdt/order_book", params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var snapshot gateBookSnapshot
+	if err := json.Unmarshal(body, &snapshot); err != nil {
+		return nil, err
+	}
+	return &snapshot, nil
+}
+
 // handleMessage обрабатывает одно сообщение из WebSocket
 func (g *Gate) handleMessage(message []byte) {
 	// Сначала определяем тип канала
@@ -469,6 +994,14 @@ func (g *Gate) handleMessage(message []byte) {
 		if baseMsg.Event == "update" {
 			g.handlePositionUpdate(baseMsg.Result)
 		}
+	case "futures.orders":
+		if baseMsg.Event == "update" {
+			g.handleOrderUpdate(baseMsg.Result)
+		}
+	case "futures.order_book_update":
+		if baseMsg.Event == "update" {
+			g.handleOrderBookUpdate(baseMsg.Result)
+		}
 	}
 }
 
@@ -551,10 +1084,11 @@ func (g *Gate) handlePositionUpdate(data json.RawMessage) {
 			liquidation = false // будет определяться через markPrice vs liqPrice в Risk Manager
 		}
 
+		symbol := g.fromGateSymbol(p.Contract)
 		callback(&Position{
-			Symbol:        g.fromGateSymbol(p.Contract),
+			Symbol:        symbol,
 			Side:          side,
-			Size:          size,
+			Size:          g.instruments.CoinQty(symbol, size),
 			EntryPrice:    g.parseFloat(p.EntryPrice, "ws.position.entryPrice"),
 			MarkPrice:     g.parseFloat(p.MarkPrice, "ws.position.markPrice"),
 			Leverage:      p.Leverage,
@@ -565,44 +1099,64 @@ func (g *Gate) handlePositionUpdate(data json.RawMessage) {
 	}
 }
 
+// handleOrderUpdate обрабатывает обновления ордеров
+// Канал futures.orders использует те же поля, что и /futures/usdt/orders.
+// Сумма комиссии в канале не передаётся (только ставки tkfr/mkfr), Fee остаётся 0
+func (g *Gate) handleOrderUpdate(data json.RawMessage) {
+	var orders []gateOrderInfo
+	if err := json.Unmarshal(data, &orders); err != nil {
+		log.Printf("[gate] failed to parse order update: %v", err)
+		return
+	}
+
+	g.callbackMu.RLock()
+	callback := g.orderCallback
+	g.callbackMu.RUnlock()
+
+	if callback == nil {
+		return
+	}
+
+	for _, info := range orders {
+		callback(g.parseOrder(info))
+	}
+}
+
 func (g *Gate) SubscribePositions(callback func(*Position)) error {
 	g.callbackMu.Lock()
 	g.positionCallback = callback
 	g.callbackMu.Unlock()
 
-	// Защита от race condition при инициализации WebSocket manager
-	g.wsMu.Lock()
-	if g.wsManager == nil {
-		config := DefaultWSReconnectConfig()
-		g.wsManager = NewWSReconnectManager("gate", gateWSURL, config)
+	return g.subscribePrivate("futures.positions")
+}
 
-		g.wsManager.SetOnMessage(g.handleMessage)
-		g.wsManager.SetOnConnect(func() {
-			log.Printf("[gate] WebSocket connected")
-		})
-		g.wsManager.SetOnDisconnect(func(err error) {
-			if err != nil {
-				log.Printf("[gate] WebSocket disconnected: %v", err)
-			}
-		})
+// SubscribeOrders подписывается на канал futures.orders
+func (g *Gate) SubscribeOrders(callback func(*Order)) error {
+	g.callbackMu.Lock()
+	g.orderCallback = callback
+	g.callbackMu.Unlock()
 
-		if err := g.wsManager.Connect(); err != nil {
-			g.wsMu.Unlock()
-			return fmt.Errorf("failed to connect to WebSocket: %w", err)
-		}
+	return g.subscribePrivate("futures.orders")
+}
+
+// subscribePrivate подписывается на приватный канал по всем контрактам
+// Gate.io авторизует каждую подписку отдельно, соединение общее с тикерами
+func (g *Gate) subscribePrivate(channel string) error {
+	wsManager, err := g.connectWS()
+	if err != nil {
+		return err
 	}
-	wsManager := g.wsManager
-	g.wsMu.Unlock()
 
+	now := g.clock.Now().Unix()
 	subMsg := map[string]interface{}{
-		"time":    time.Now().Unix(),
-		"channel": "futures.positions",
+		"time":    now,
+		"channel": channel,
 		"event":   "subscribe",
 		"payload": []string{"!all"},
 		"auth": map[string]string{
 			"method": "api_key",
 			"KEY":    g.apiKey,
-			"SIGN":   g.signWS("subscribe", "futures.positions"),
+			"SIGN":   g.signWS("subscribe", channel, now),
 		},
 	}
 
@@ -615,8 +1169,8 @@ func (g *Gate) authenticateWebSocket(conn *websocket.Conn) error {
 	return nil // Auth happens per-subscription
 }
 
-func (g *Gate) signWS(event, channel string) string {
-	timestamp := time.Now().Unix()
+// signWS подписывает приватную подписку; timestamp должен совпадать с полем time сообщения
+func (g *Gate) signWS(event, channel string, timestamp int64) string {
 	message := fmt.Sprintf("channel=%s&event=%s&time=%d", channel, event, timestamp)
 	h := hmac.New(sha512.New, []byte(g.secretKey))
 	h.Write([]byte(message))
@@ -624,48 +1178,271 @@ func (g *Gate) signWS(event, channel string) string {
 }
 
 func (g *Gate) GetTradingFee(ctx context.Context, symbol string) (float64, error) {
-	return 0.0005, nil // 0.05% стандартная комиссия
+	fees, err := g.GetTradingFees(ctx, symbol)
+	if err != nil {
+		return 0.0005, nil // 0.05% стандартная комиссия
+	}
+	return fees.Taker, nil
 }
 
-func (g *Gate) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
+// GetTradingFees получает комиссии аккаунта из /futures/usdt/fee
+// Отрицательный maker_fee - ребейт мейкера
+func (g *Gate) GetTradingFees(ctx context.Context, symbol string) (*TradingFees, error) {
 	contract := g.toGateSymbol(symbol)
-
 	params := map[string]string{
 		"contract": contract,
 	}
 
-	body, err := g.doRequest(ctx, http.MethodGet, "/futures/usdt/contracts/"+contract, params, false)
+	body, err := g.doRequest(ctx, http.MethodGet, "/futures/usdt/fee", params, true)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp struct {
-		Name            string `json:"name"`
-		OrderSizeMin    int64  `json:"order_size_min"`
-		OrderSizeMax    int64  `json:"order_size_max"`
+	// Ответ - словарь контракт -> ставки
+	var resp map[string]struct {
+		TakerFee string `json:"taker_fee"`
+		MakerFee string `json:"maker_fee"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	d, ok := resp[contract]
+	if !ok {
+		return nil, fmt.Errorf("fee not found for %s", symbol)
+	}
+
+	return &TradingFees{
+		Symbol:    symbol,
+		Maker:     g.parseFloat(d.MakerFee, "fee.makerFee"),
+		Taker:     g.parseFloat(d.TakerFee, "fee.takerFee"),
+		Timestamp: time.Now(),
+	}, nil
+}
+
+func (g *Gate) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
+	inst, err := g.GetInstrument(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return inst.Limits(), nil
+}
+
+func (g *Gate) GetInstrument(ctx context.Context, symbol string) (*Instrument, error) {
+	return g.instruments.Get(ctx, symbol)
+}
+
+// loadInstruments загружает спецификации всех USDT бессрочных контрактов
+// Размер ордера Gate - целое число контрактов по quanto_multiplier монет
+func (g *Gate) loadInstruments(ctx context.Context) ([]*Instrument, error) {
+	body, err := g.doRequest(ctx, http.MethodGet, "/futures/usdt/contracts", nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []struct {
+		Name             string `json:"name"`
 		QuantoMultiplier string `json:"quanto_multiplier"`
-		OrderPriceDeviate string `json:"order_price_deviate"`
-		LeverageMin     int    `json:"leverage_min"`
-		LeverageMax     int    `json:"leverage_max"`
+		OrderSizeMin     int64  `json:"order_size_min"`
+		OrderSizeMax     int64  `json:"order_size_max"`
+		OrderPriceRound  string `json:"order_price_round"`
+		LeverageMax      string `json:"leverage_max"`
+		InDelisting      bool   `json:"in_delisting"`
+		Status           string `json:"status"` // trading, delisting, delisted
 	}
 
 	if err := json.Unmarshal(body, &resp); err != nil {
 		return nil, err
 	}
 
-	quantoMultiplier := g.parseFloat(resp.QuantoMultiplier, "limits.quantoMultiplier")
+	instruments := make([]*Instrument, 0, len(resp))
+	for _, c := range resp {
+		status := InstrumentStatusTrading
+		if c.InDelisting || (c.Status != "" && c.Status != "trading") {
+			status = InstrumentStatusDelisted
+		}
+
+		instruments = append(instruments, &Instrument{
+			Symbol:         g.fromGateSymbol(c.Name),
+			VenueSymbol:    c.Name,
+			ContractValue:  g.parseFloat(c.QuantoMultiplier, "instrument.quantoMultiplier"),
+			LotSize:        1,
+			MinSize:        float64(c.OrderSizeMin),
+			MaxSize:        float64(c.OrderSizeMax),
+			TickSize:       g.parseFloat(c.OrderPriceRound, "instrument.orderPriceRound"),
+			MinNotional:    5.0,
+			MaxLeverage:    int(g.parseFloat(c.LeverageMax, "instrument.leverageMax")),
+			SettleCurrency: "USDT",
+			Status:         status,
+		})
+	}
+
+	return instruments, nil
+}
+
+func (g *Gate) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	contract := g.toGateSymbol(symbol)
 
-	return &Limits{
-		Symbol:      symbol,
-		MinOrderQty: float64(resp.OrderSizeMin),
-		MaxOrderQty: float64(resp.OrderSizeMax),
-		QtyStep:     1.0, // Gate.io использует контракты
-		MinNotional: 5.0,
-		PriceStep:   quantoMultiplier,
-		MaxLeverage: resp.LeverageMax,
+	body, err := g.doRequest(ctx, http.MethodGet, "/futures/usdt/contracts/"+contract, nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		FundingRate           string  `json:"funding_rate"`
+		FundingRateIndicative string  `json:"funding_rate_indicative"`
+		FundingNextApply      float64 `json:"funding_next_apply"` // unix, секунды
+		FundingInterval       int64   `json:"funding_interval"`   // секунды
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	interval := DefaultFundingInterval
+	if resp.FundingInterval > 0 {
+		interval = time.Duration(resp.FundingInterval) * time.Second
+	}
+
+	// funding_rate_indicative - прогноз ставки следующего периода
+	var predicted float64
+	if resp.FundingRateIndicative != "" {
+		predicted = g.parseFloat(resp.FundingRateIndicative, "funding.rateIndicative")
+	}
+
+	return &FundingRate{
+		Symbol:          symbol,
+		Rate:            g.parseFloat(resp.FundingRate, "funding.rate"),
+		PredictedRate:   predicted,
+		NextFundingTime: time.Unix(int64(resp.FundingNextApply), 0),
+		Interval:        interval,
+		Timestamp:       time.Now(),
 	}, nil
 }
 
+// SetLeverage устанавливает плечо контракта с учётом режима маржи
+// В cross режиме плечо передаётся как cross_leverage_limit при leverage=0
+func (g *Gate) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if err := validateLeverage(leverage); err != nil {
+		return err
+	}
+	return g.updateLeverage(ctx, symbol, g.marginMode(symbol), leverage)
+}
+
+// SetMarginMode переключает режим маржи контракта, сохраняя текущее плечо
+func (g *Gate) SetMarginMode(ctx context.Context, symbol, mode string) error {
+	if err := validateMarginMode(mode); err != nil {
+		return err
+	}
+
+	leverage, _, err := g.GetMarginSettings(ctx, symbol)
+	if err != nil {
+		return err
+	}
+	if leverage < 1 {
+		leverage = 1
+	}
+
+	if err := g.updateLeverage(ctx, symbol, mode, leverage); err != nil {
+		return err
+	}
+
+	g.marginModesMu.Lock()
+	g.marginModes[symbol] = mode
+	g.marginModesMu.Unlock()
+	return nil
+}
+
+// GetMarginSettings читает плечо и режим маржи контракта
+// Gate хранит режим в плече позиции: leverage=0 - cross с плечом cross_leverage_limit
+func (g *Gate) GetMarginSettings(ctx context.Context, symbol string) (int, string, error) {
+	contract := g.toGateSymbol(symbol)
+	body, err := g.doRequest(ctx, http.MethodGet, "/futures/usdt/positions/"+contract, nil, true)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var pos struct {
+		Leverage           string `json:"leverage"`
+		CrossLeverageLimit string `json:"cross_leverage_limit"`
+	}
+	if err := json.Unmarshal(body, &pos); err != nil {
+		return 0, "", err
+	}
+
+	leverage := int(g.parseFloat(pos.Leverage, "position.leverage"))
+	if leverage == 0 {
+		return int(g.parseFloat(pos.CrossLeverageLimit, "position.cross_leverage_limit")), MarginModeCross, nil
+	}
+	return leverage, MarginModeIsolated, nil
+}
+
+// GetFundingPayments читает начисления фандинга контракта из /futures/usdt/account_book (type=fund)
+// Gate принимает границы периода в секундах
+func (g *Gate) GetFundingPayments(ctx context.Context, symbol string, from, to time.Time) ([]FundingPayment, error) {
+	contract := g.toGateSymbol(symbol)
+	params := map[string]string{
+		"contract": contract,
+		"type":     "fund",
+		"from":     strconv.FormatInt(from.Unix(), 10),
+		"to":       strconv.FormatInt(to.Unix()+1, 10),
+	}
+
+	body, err := g.doRequest(ctx, http.MethodGet, "/futures/usdt/account_book", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp []struct {
+		Time     float64 `json:"time"`
+		Change   string  `json:"change"`
+		Type     string  `json:"type"`
+		Contract string  `json:"contract"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	payments := make([]FundingPayment, 0, len(resp))
+	for _, r := range resp {
+		if r.Contract != contract || r.Type != "fund" {
+			continue
+		}
+		payments = append(payments, FundingPayment{
+			Symbol: symbol,
+			Amount: g.parseFloat(r.Change, "account_book.change"),
+			Time:   time.UnixMilli(int64(r.Time * 1000)),
+		})
+	}
+	return fundingPaymentsBetween(payments, from, to), nil
+}
+
+// updateLeverage отправляет плечо контракта в формате режима маржи
+func (g *Gate) updateLeverage(ctx context.Context, symbol, mode string, leverage int) error {
+	contract := g.toGateSymbol(symbol)
+
+	query := "leverage=" + strconv.Itoa(leverage)
+	if mode == MarginModeCross {
+		query = "leverage=0&cross_leverage_limit=" + strconv.Itoa(leverage)
+	}
+
+	_, err := g.doRequest(ctx, http.MethodPost, "/futures/usdt/positions/"+contract+"/leverage?"+query, nil, true)
+	return err
+}
+
+// marginMode возвращает установленный режим маржи контракта (по умолчанию cross)
+func (g *Gate) marginMode(symbol string) string {
+	g.marginModesMu.RLock()
+	mode := g.marginModes[symbol]
+	g.marginModesMu.RUnlock()
+
+	if mode == "" {
+		return MarginModeCross
+	}
+	return mode
+}
+
 func (g *Gate) Close() error {
 	select {
 	case <-g.closeChan:
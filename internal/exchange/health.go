@@ -0,0 +1,453 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ============================================================
+// Состояние бирж (circuit breaker)
+// ============================================================
+//
+// ExchangeHealth собирает по бирже задержку и долю ошибок REST запросов
+// за скользящее окно, время без сообщений и попытки переподключения
+// WebSocket. Каждый показатель даёт штраф - долю порога отключения,
+// оценка биржи = 1 - наибольший штраф:
+//   - healthy  - штрафы ниже healthDegradedRatio
+//   - degraded - вход разрешён, показатель приближается к порогу
+//   - tripped  - порог достигнут: биржа не выбирается ногой входа,
+//     закрытия продолжаются с агрессивными повторами
+//
+// Отключение держится HealthConfig.Cooldown после последнего превышения порога.
+
+// ExchangeHealthState - состояние биржи: 0 healthy, 1 degraded, 2 tripped
+var ExchangeHealthState = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "arbitrage",
+		Subsystem: "exchange",
+		Name:      "health_state",
+		Help:      "Exchange health state: 0 healthy, 1 degraded, 2 tripped",
+	},
+	[]string{"exchange"},
+)
+
+// ExchangeHealthScore - оценка состояния биржи от 0 (отключена) до 1
+var ExchangeHealthScore = promauto.NewGaugeVec(
+	prometheus.GaugeOpts{
+		Namespace: "arbitrage",
+		Subsystem: "exchange",
+		Name:      "health_score",
+		Help:      "Exchange health score from 0 (tripped) to 1 (healthy)",
+	},
+	[]string{"exchange"},
+)
+
+// ExchangeHealthTrips - число отключений биржи от входов
+var ExchangeHealthTrips = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "arbitrage",
+		Subsystem: "exchange",
+		Name:      "health_trips_total",
+		Help:      "Number of times the exchange circuit breaker tripped",
+	},
+	[]string{"exchange"},
+)
+
+// ExchangeRESTLatency - задержка REST запросов, включая ожидание лимита запросов
+var ExchangeRESTLatency = promauto.NewHistogramVec(
+	prometheus.HistogramOpts{
+		Namespace: "arbitrage",
+		Subsystem: "exchange",
+		Name:      "rest_latency_seconds",
+		Help:      "REST request latency including rate limiter wait",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10},
+	},
+	[]string{"exchange"},
+)
+
+// ExchangeRESTErrors - ошибки REST запросов, указывающие на проблемы биржи
+var ExchangeRESTErrors = promauto.NewCounterVec(
+	prometheus.CounterOpts{
+		Namespace: "arbitrage",
+		Subsystem: "exchange",
+		Name:      "rest_errors_total",
+		Help:      "REST requests failed by network errors, HTTP 5xx or rate limits",
+	},
+	[]string{"exchange"},
+)
+
+// HealthState - состояние биржи
+type HealthState int32
+
+const (
+	HealthHealthy  HealthState = iota // биржа работает штатно
+	HealthDegraded                    // показатели хуже нормы, вход разрешён
+	HealthTripped                     // биржа отключена от новых входов
+)
+
+func (s HealthState) String() string {
+	switch s {
+	case HealthHealthy:
+		return "healthy"
+	case HealthDegraded:
+		return "degraded"
+	case HealthTripped:
+		return "tripped"
+	default:
+		return "unknown"
+	}
+}
+
+// MarshalText сериализует состояние строкой (для API)
+func (s HealthState) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+const (
+	healthDegradedRatio = 0.2         // доля порога отключения, с которой биржа деградировала
+	healthStalePenalty  = 0.5         // штраф за WebSocket без сообщений дольше StaleAfter
+	healthEvalInterval  = time.Second // State пересчитывает оценку не чаще
+)
+
+// HealthConfig - пороги оценки состояния бирж
+type HealthConfig struct {
+	Window         time.Duration // скользящее окно статистики REST запросов
+	MinRequests    int           // минимум запросов в окне для оценки доли ошибок
+	TripErrorRate  float64       // доля ошибок REST (0..1) для отключения
+	TripLatency    time.Duration // средняя задержка REST для отключения
+	StaleAfter     time.Duration // WebSocket без сообщений дольше - биржа деградировала
+	TripReconnects int           // попыток переподключения WebSocket подряд для отключения
+	Cooldown       time.Duration // отключение держится после последнего превышения порога
+}
+
+// DefaultHealthConfig возвращает пороги по умолчанию
+func DefaultHealthConfig() HealthConfig {
+	return HealthConfig{
+		Window:         time.Minute,
+		MinRequests:    5,
+		TripErrorRate:  0.5,
+		TripLatency:    5 * time.Second,
+		StaleAfter:     90 * time.Second, // три интервала ping без pong
+		TripReconnects: 3,
+		Cooldown:       30 * time.Second,
+	}
+}
+
+var (
+	healthConfigMu sync.RWMutex
+	healthConfig   = DefaultHealthConfig()
+)
+
+// SetHealthConfig задаёт пороги оценки состояния для всех бирж
+// Нулевые значения заменяются значениями по умолчанию
+func SetHealthConfig(cfg HealthConfig) {
+	defaults := DefaultHealthConfig()
+	if cfg.Window <= 0 {
+		cfg.Window = defaults.Window
+	}
+	if cfg.MinRequests <= 0 {
+		cfg.MinRequests = defaults.MinRequests
+	}
+	if cfg.TripErrorRate <= 0 {
+		cfg.TripErrorRate = defaults.TripErrorRate
+	}
+	if cfg.TripLatency <= 0 {
+		cfg.TripLatency = defaults.TripLatency
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = defaults.StaleAfter
+	}
+	if cfg.TripReconnects <= 0 {
+		cfg.TripReconnects = defaults.TripReconnects
+	}
+	if cfg.Cooldown <= 0 {
+		cfg.Cooldown = defaults.Cooldown
+	}
+
+	healthConfigMu.Lock()
+	healthConfig = cfg
+	healthConfigMu.Unlock()
+}
+
+// currentHealthConfig возвращает текущие пороги оценки состояния
+func currentHealthConfig() HealthConfig {
+	healthConfigMu.RLock()
+	defer healthConfigMu.RUnlock()
+	return healthConfig
+}
+
+// HealthStatus - оценка состояния биржи
+type HealthStatus struct {
+	Exchange     string      `json:"exchange"`
+	State        HealthState `json:"state"`
+	Score        float64     `json:"score"`            // 1 - норма, 0 - отключена
+	Reason       string      `json:"reason,omitempty"` // показатель с наибольшим штрафом
+	Requests     int         `json:"requests"`         // REST запросов в окне
+	ErrorRate    float64     `json:"error_rate"`       // доля ошибок REST в окне
+	AvgLatencyMs float64     `json:"avg_latency_ms"`   // средняя задержка REST в окне
+	WSStaleSec   float64     `json:"ws_stale_sec"`     // наибольшее время без сообщений WebSocket
+	Reconnects   int         `json:"ws_reconnects"`    // наибольшее число попыток переподключения подряд
+	TrippedUntil *time.Time  `json:"tripped_until,omitempty"`
+}
+
+// healthSample - результат REST запроса
+type healthSample struct {
+	at      time.Time
+	latency time.Duration
+	failed  bool
+}
+
+// ExchangeHealth отслеживает состояние биржи
+// Экземпляры адаптера одной биржи разделяют его (см. HealthOf)
+type ExchangeHealth struct {
+	exchange   string
+	limitCodes []string // коды ExchangeError о превышении лимита запросов
+
+	mu           sync.Mutex
+	samples      []healthSample                 // запросы в окне по возрастанию времени
+	ws           map[string]*WSReconnectManager // по имени менеджера
+	trippedUntil time.Time
+	status       HealthStatus // последняя оценка
+
+	state       int32 // atomic HealthState последней оценки
+	evaluatedAt int64 // atomic время последней оценки, unix nano
+
+	now func() time.Time
+}
+
+// Состояния разделяются всеми экземплярами адаптера одной биржи
+var healthRegistry sync.Map // name -> *ExchangeHealth
+
+// HealthOf возвращает состояние биржи, создавая его при первом обращении
+// Идентификатор аккаунта ("arb1@bybit") возвращает состояние его биржи
+func HealthOf(name string) *ExchangeHealth {
+	name = VenueOf(name)
+	if h, ok := healthRegistry.Load(name); ok {
+		return h.(*ExchangeHealth)
+	}
+
+	h, _ := healthRegistry.LoadOrStore(name, newExchangeHealth(name))
+	return h.(*ExchangeHealth)
+}
+
+// newExchangeHealth создаёт состояние биржи с кодами лимитов из реестра адаптеров
+func newExchangeHealth(name string) *ExchangeHealth {
+	adapter, _ := LookupAdapter(name)
+	return &ExchangeHealth{
+		exchange:   name,
+		limitCodes: adapter.RateLimits.LimitCodes,
+		ws:         make(map[string]*WSReconnectManager),
+		status:     HealthStatus{Exchange: name, Score: 1},
+		now:        time.Now,
+	}
+}
+
+// HealthStatuses возвращает оценки всех отслеживаемых бирж, отсортированные по имени
+func HealthStatuses() []HealthStatus {
+	var statuses []HealthStatus
+	healthRegistry.Range(func(_, h any) bool {
+		statuses = append(statuses, h.(*ExchangeHealth).Status())
+		return true
+	})
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Exchange < statuses[j].Exchange })
+	return statuses
+}
+
+// ObserveRequest учитывает REST запрос: задержку и ошибку (nil - успешный ответ)
+// Запросы, отменённые вызывающим кодом, не учитываются
+func (h *ExchangeHealth) ObserveRequest(latency time.Duration, err error) {
+	if errors.Is(err, context.Canceled) {
+		return
+	}
+
+	failed := h.failure(err)
+	ExchangeRESTLatency.WithLabelValues(h.exchange).Observe(latency.Seconds())
+	if failed {
+		ExchangeRESTErrors.WithLabelValues(h.exchange).Inc()
+	}
+
+	h.mu.Lock()
+	h.samples = append(h.samples, healthSample{at: h.now(), latency: latency, failed: failed})
+	h.mu.Unlock()
+
+	// Ошибка может отключить биржу - оцениваем сразу, а не через healthEvalInterval
+	if failed {
+		h.Status()
+	} else {
+		h.State()
+	}
+}
+
+// failure сообщает, указывает ли ошибка запроса на проблемы биржи
+// Отказы по правилам биржи (маржа, ненайденный ордер) - штатный ответ, не ошибка
+func (h *ExchangeHealth) failure(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var exchErr *ExchangeError
+	if !errors.As(err, &exchErr) || exchErr.Original != nil {
+		return true // сеть, таймаут, неразобранный ответ
+	}
+
+	if status, convErr := strconv.Atoi(exchErr.Code); convErr == nil {
+		switch {
+		case status >= http.StatusInternalServerError && status < 600:
+			return true
+		case status == http.StatusTooManyRequests, status == http.StatusTeapot:
+			return true
+		case status == http.StatusForbidden:
+			return true // блокировка IP балансировщиком (Bybit, CloudFront)
+		}
+	}
+	for _, code := range h.limitCodes {
+		if exchErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// TrackWS добавляет WebSocket соединение в оценку состояния
+// Менеджер с тем же именем (пересозданный адаптером) заменяет прежний
+func (h *ExchangeHealth) TrackWS(m *WSReconnectManager) {
+	h.mu.Lock()
+	h.ws[m.exchangeName] = m
+	h.mu.Unlock()
+}
+
+// State возвращает состояние биржи
+// Оценка пересчитывается не чаще healthEvalInterval - вызов дешёвый для горячего пути
+func (h *ExchangeHealth) State() HealthState {
+	if h.now().UnixNano()-atomic.LoadInt64(&h.evaluatedAt) < int64(healthEvalInterval) {
+		return HealthState(atomic.LoadInt32(&h.state))
+	}
+	return h.Status().State
+}
+
+// Tripped сообщает, отключена ли биржа от новых входов
+func (h *ExchangeHealth) Tripped() bool {
+	return h.State() == HealthTripped
+}
+
+// Status пересчитывает и возвращает оценку состояния биржи
+func (h *ExchangeHealth) Status() HealthStatus {
+	cfg := currentHealthConfig()
+	now := h.now()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	// Отбрасываем запросы старше окна
+	cutoff := now.Add(-cfg.Window)
+	expired := 0
+	for expired < len(h.samples) && h.samples[expired].at.Before(cutoff) {
+		expired++
+	}
+	h.samples = append(h.samples[:0], h.samples[expired:]...)
+
+	status := HealthStatus{Exchange: h.exchange, Requests: len(h.samples)}
+	var penalty float64
+	raise := func(value float64, reason string) {
+		if value > penalty {
+			penalty, status.Reason = value, reason
+		}
+	}
+
+	if len(h.samples) > 0 {
+		var failures int
+		var total time.Duration
+		for _, sample := range h.samples {
+			total += sample.latency
+			if sample.failed {
+				failures++
+			}
+		}
+		avg := total / time.Duration(len(h.samples))
+		status.AvgLatencyMs = float64(avg) / float64(time.Millisecond)
+		status.ErrorRate = float64(failures) / float64(len(h.samples))
+
+		raise(float64(avg)/float64(cfg.TripLatency), "rest latency")
+		if len(h.samples) >= cfg.MinRequests {
+			raise(status.ErrorRate/cfg.TripErrorRate, "rest errors")
+		}
+	}
+
+	for name, m := range h.ws {
+		last := m.LastMessageAt()
+		switch m.GetState() {
+		case WSStateClosed:
+			delete(h.ws, name)
+			continue
+		case WSStateDisconnected:
+			// Менеджер исчерпал попытки переподключения; до первого подключения не учитывается
+			if !last.IsZero() {
+				raise(1, name+" disconnected")
+			}
+			continue
+		case WSStateConnected:
+			stale := now.Sub(last)
+			if stale.Seconds() > status.WSStaleSec {
+				status.WSStaleSec = stale.Seconds()
+			}
+			if stale >= cfg.StaleAfter {
+				raise(healthStalePenalty, name+" stale")
+			}
+		}
+
+		retries := m.GetRetryCount()
+		if retries > status.Reconnects {
+			status.Reconnects = retries
+		}
+		raise(float64(retries)/float64(cfg.TripReconnects), name+" reconnecting")
+	}
+
+	if penalty > 1 {
+		penalty = 1
+	}
+	status.Score = 1 - penalty
+
+	switch {
+	case penalty >= 1:
+		status.State = HealthTripped
+		h.trippedUntil = now.Add(cfg.Cooldown)
+	case now.Before(h.trippedUntil):
+		// Порог уже не превышен, но отключение держится до конца cooldown
+		status.State = HealthTripped
+		status.Score = 0
+		status.Reason = h.status.Reason
+	case penalty >= healthDegradedRatio:
+		status.State = HealthDegraded
+	default:
+		status.Reason = ""
+	}
+	if status.State == HealthTripped {
+		until := h.trippedUntil
+		status.TrippedUntil = &until
+	}
+
+	if status.State != h.status.State {
+		log.Printf("[%s] health %s -> %s (%s)", h.exchange, h.status.State, status.State, status.Reason)
+		if status.State == HealthTripped {
+			ExchangeHealthTrips.WithLabelValues(h.exchange).Inc()
+		}
+	}
+
+	h.status = status
+	atomic.StoreInt32(&h.state, int32(status.State))
+	atomic.StoreInt64(&h.evaluatedAt, now.UnixNano())
+	ExchangeHealthState.WithLabelValues(h.exchange).Set(float64(status.State))
+	ExchangeHealthScore.WithLabelValues(h.exchange).Set(status.Score)
+
+	return status
+}
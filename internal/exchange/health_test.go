@@ -0,0 +1,125 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// testHealth возвращает состояние биржи с управляемыми часами
+func testHealth(name string) (*ExchangeHealth, *time.Time) {
+	now := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newExchangeHealth(name)
+	h.now = func() time.Time { return now }
+	return h, &now
+}
+
+// TestHealthRESTErrors проверяет отключение по доле ошибок, cooldown и восстановление
+func TestHealthRESTErrors(t *testing.T) {
+	h, now := testHealth("bybit")
+	cfg := currentHealthConfig()
+
+	// Отказы по правилам биржи и отменённые запросы не ухудшают состояние
+	for i := 0; i < cfg.MinRequests; i++ {
+		h.ObserveRequest(10*time.Millisecond, &ExchangeError{Exchange: "bybit", Code: "110007", Message: "insufficient balance"})
+		h.ObserveRequest(time.Second, context.Canceled)
+	}
+	if status := h.Status(); status.State != HealthHealthy || status.ErrorRate != 0 || status.Requests != cfg.MinRequests {
+		t.Fatalf("expected healthy without failures, got %+v", status)
+	}
+
+	// Сеть, HTTP 5xx и коды лимита биржи - ошибки
+	h.ObserveRequest(10*time.Millisecond, errors.New("read tcp: connection reset"))
+	h.ObserveRequest(10*time.Millisecond, httpStatusError("bybit", 502, nil, nil))
+	if state := h.Status().State; state != HealthDegraded {
+		t.Fatalf("expected degraded at 2/7 errors, got %s", state)
+	}
+	for i := 0; i < 3; i++ {
+		h.ObserveRequest(10*time.Millisecond, &ExchangeError{Exchange: "bybit", Code: "10006", Message: "too many visits"})
+	}
+	status := h.Status()
+	if status.State != HealthTripped || status.Score != 0 || status.Reason != "rest errors" || status.TrippedUntil == nil {
+		t.Fatalf("expected tripped by rest errors, got %+v", status)
+	}
+	if !h.Tripped() {
+		t.Fatal("expected Tripped() after trip")
+	}
+
+	// Доля ошибок упала ниже порога, но отключение держится до конца cooldown
+	for i := 0; i < 10; i++ {
+		h.ObserveRequest(10*time.Millisecond, nil)
+	}
+	if status := h.Status(); status.State != HealthTripped || status.Score != 0 || status.Reason != "rest errors" {
+		t.Fatalf("expected tripped during cooldown, got %+v", status)
+	}
+
+	*now = now.Add(cfg.Cooldown)
+	if status := h.Status(); status.State != HealthDegraded || status.TrippedUntil != nil {
+		t.Fatalf("expected degraded after cooldown, got %+v", status)
+	}
+
+	*now = now.Add(cfg.Window)
+	h.ObserveRequest(10*time.Millisecond, nil)
+	if status := h.Status(); status.State != HealthHealthy || status.Score < 0.99 || status.Requests != 1 {
+		t.Fatalf("expected healthy after window, got %+v", status)
+	}
+}
+
+// TestHealthRESTLatency проверяет деградацию и отключение по средней задержке
+func TestHealthRESTLatency(t *testing.T) {
+	h, _ := testHealth("okx")
+	cfg := currentHealthConfig()
+
+	h.ObserveRequest(cfg.TripLatency/2, nil)
+	status := h.Status()
+	if status.State != HealthDegraded || status.Reason != "rest latency" || status.Score != 0.5 {
+		t.Fatalf("expected degraded by latency, got %+v", status)
+	}
+
+	h.ObserveRequest(cfg.TripLatency*2, nil)
+	if state := h.Status().State; state != HealthTripped {
+		t.Fatalf("expected tripped by latency, got %s", state)
+	}
+}
+
+// TestHealthWebSocket проверяет учёт времени без сообщений и переподключений WebSocket
+func TestHealthWebSocket(t *testing.T) {
+	h, now := testHealth("gate")
+	cfg := currentHealthConfig()
+
+	m := NewWSReconnectManager("gate", "", DefaultWSReconnectConfig())
+	h.TrackWS(m)
+
+	// До первого подключения менеджер не учитывается
+	if state := h.Status().State; state != HealthHealthy {
+		t.Fatalf("expected healthy before connect, got %s", state)
+	}
+
+	atomic.StoreInt32(&m.state, int32(WSStateConnected))
+	atomic.StoreInt64(&m.lastMessage, now.UnixNano())
+	if state := h.Status().State; state != HealthHealthy {
+		t.Fatalf("expected healthy with fresh messages, got %s", state)
+	}
+
+	*now = now.Add(cfg.StaleAfter)
+	status := h.Status()
+	if status.State != HealthDegraded || status.Reason != "gate stale" || status.WSStaleSec != cfg.StaleAfter.Seconds() {
+		t.Fatalf("expected degraded by stale websocket, got %+v", status)
+	}
+
+	atomic.StoreInt32(&m.state, int32(WSStateReconnecting))
+	atomic.StoreInt32(&m.retryCount, int32(cfg.TripReconnects))
+	status = h.Status()
+	if status.State != HealthTripped || status.Reconnects != cfg.TripReconnects || status.Reason != "gate reconnecting" {
+		t.Fatalf("expected tripped by reconnects, got %+v", status)
+	}
+
+	// Закрытый адаптером менеджер перестаёт учитываться
+	m.Close()
+	*now = now.Add(cfg.Cooldown)
+	if state := h.Status().State; state != HealthHealthy {
+		t.Fatalf("expected healthy after websocket closed, got %s", state)
+	}
+}
@@ -7,8 +7,10 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"hash/fnv"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"net/url"
 	"sort"
@@ -16,11 +18,14 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/gorilla/websocket"
 )
 
 const (
-	htxBaseURL   = "https://api.hbdm.com"
-	htxWSURL     = "wss://api.hbdm.com/linear-swap-ws"
+	htxBaseURL     = "https://api.hbdm.com"
+	htxWSURL       = "wss://api.hbdm.com/linear-swap-ws"
+	htxWSNotifyURL = "wss://api.hbdm.com/linear-swap-notification"
 )
 
 type HTX struct {
@@ -28,27 +33,107 @@ type HTX struct {
 	secretKey string
 
 	httpClient *http.Client
+	endpoints  Endpoints       // адреса API, подменяются SetEndpoints
+	limiter    *requestLimiter // лимиты REST запросов
+	clock      *Clock          // смещение часов биржи для подписи запросов
+	health     *ExchangeHealth // состояние биржи для отключения от входов
 
 	// WebSocket manager с автоматическим переподключением
-	wsManager *WSReconnectManager
-	wsMu      sync.Mutex // защита инициализации WebSocket manager
+	wsManager        *WSReconnectManager
+	wsPrivateManager *WSReconnectManager // приватный канал позиций и ордеров
+	wsMu             sync.Mutex          // защита инициализации WebSocket manager
 
 	tickerCallbacks  map[string]func(*Ticker)
 	positionCallback func(*Position)
+	orderCallback    func(*Order)
 	callbackMu       sync.RWMutex
 
+	// Локальные стаканы SubscribeOrderBook
+	books orderBookSubscriptions
+
+	// Спецификации контрактов: размеры HTX в целых контрактах по contract_size монет
+	instruments *InstrumentRegistry
+
+	// Режим маржи HTX определяется эндпоинтами (swap_* - isolated, swap_cross_* - cross),
+	// плечо передаётся в каждом ордере
+	marginModes map[string]string // symbol -> MarginModeCross / MarginModeIsolated
+	leverages   map[string]int    // symbol -> плечо
+	settingsMu  sync.RWMutex
+
 	connected bool
 	closeChan chan struct{}
 }
 
+// init регистрирует адаптер HTX в реестре бирж
+func init() {
+	Register(Adapter{
+		Name: "htx",
+		New:  func() Exchange { return NewHTX() },
+		Capabilities: Capabilities{
+			PrivateWS:   true,
+			LimitOrders: true,
+			Funding:     true,
+			HedgeMode:   true,
+		},
+		TakerFee: 0.0004,
+		MakerFee: 0.0002,
+		// На UID: торговля 72 запроса за 3 секунды, запросы аккаунта 48 за 3 секунды
+		RateLimits: RateLimits{
+			Orders:  24,
+			Market:  100,
+			Account: 16,
+			Endpoints: map[string]EndpointLimit{
+				"/linear-swap-api/v1/swap_order":            {Category: RateCategoryOrders},
+				"/linear-swap-api/v1/swap_cancel":           {Category: RateCategoryOrders},
+				"/linear-swap-api/v1/swap_openorders":       {Category: RateCategoryOrders},
+				"/linear-swap-api/v1/swap_cross_order":      {Category: RateCategoryOrders},
+				"/linear-swap-api/v1/swap_cross_cancel":     {Category: RateCategoryOrders},
+				"/linear-swap-api/v1/swap_cross_openorders": {Category: RateCategoryOrders},
+			},
+			RemainHeader: "ratelimit-remaining",
+			LimitHeader:  "ratelimit-limit",
+			LimitCodes:   []string{"1032"},
+		},
+	})
+}
+
 // NewHTX создаёт новый экземпляр HTX (Huobi)
 // Использует глобальный HTTP клиент с connection pooling и оптимизированными таймаутами
 func NewHTX() *HTX {
-	return &HTX{
+	h := &HTX{
 		httpClient:      GetGlobalHTTPClient().GetClient(),
+		endpoints:       Endpoints{REST: htxBaseURL, WSPublic: htxWSURL, WSPrivate: htxWSNotifyURL},
+		limiter:         newRequestLimiter("htx"),
+		health:          HealthOf("htx"),
 		tickerCallbacks: make(map[string]func(*Ticker)),
+		marginModes:     make(map[string]string),
+		leverages:       make(map[string]int),
 		closeChan:       make(chan struct{}),
 	}
+	h.instruments = NewInstrumentRegistry("htx", h.loadInstruments)
+	h.clock = NewClock("htx", h.getServerTime)
+	return h
+}
+
+// SetEndpoints подменяет адреса REST и WebSocket API
+func (h *HTX) SetEndpoints(endpoints Endpoints) {
+	h.endpoints = endpoints
+}
+
+// getServerTime запрашивает время сервера для синхронизации часов
+func (h *HTX) getServerTime(ctx context.Context) (time.Time, error) {
+	body, err := h.doRequest(ctx, http.MethodGet, "/api/v1/timestamp", nil, false)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var resp struct {
+		Ts int64 `json:"ts"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return time.Time{}, err
+	}
+	return time.UnixMilli(resp.Ts), nil
 }
 
 // sign создает подпись для HTX API
@@ -64,14 +149,33 @@ func (h *HTX) sign(method, host, path string, params url.Values) string {
 	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
 }
 
+// htxSignTarget возвращает host и path адреса API, которые входят в строку подписи
+func htxSignTarget(rawURL string) (string, string) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", ""
+	}
+	return u.Host, u.Path
+}
+
+// doRequest выполняет запрос к HTX API и учитывает его в состоянии биржи
 func (h *HTX) doRequest(ctx context.Context, method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
+	start := time.Now()
+	body, err := h.sendRequest(ctx, method, endpoint, params, signed)
+	h.health.ObserveRequest(time.Since(start), err)
+	return body, err
+}
+
+// sendRequest выполняет HTTP запрос к HTX API
+func (h *HTX) sendRequest(ctx context.Context, method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
 	var reqBody string
-	reqURL := htxBaseURL + endpoint
+	reqURL := h.endpoints.REST + endpoint
+	host, _ := htxSignTarget(h.endpoints.REST)
 
 	query := url.Values{}
 
 	if signed {
-		timestamp := time.Now().UTC().Format("2006-01-02T15:04:05")
+		timestamp := h.clock.Now().UTC().Format("2006-01-02T15:04:05")
 		query.Set("AccessKeyId", h.apiKey)
 		query.Set("SignatureMethod", "HmacSHA256")
 		query.Set("SignatureVersion", "2")
@@ -84,7 +188,7 @@ func (h *HTX) doRequest(ctx context.Context, method, endpoint string, params map
 		}
 
 		if signed {
-			signature := h.sign(method, "api.hbdm.com", endpoint, query)
+			signature := h.sign(method, host, endpoint, query)
 			query.Set("Signature", signature)
 		}
 
@@ -93,7 +197,7 @@ func (h *HTX) doRequest(ctx context.Context, method, endpoint string, params map
 		}
 	} else {
 		if signed {
-			signature := h.sign(method, "api.hbdm.com", endpoint, query)
+			signature := h.sign(method, host, endpoint, query)
 			query.Set("Signature", signature)
 			reqURL += "?" + query.Encode()
 		}
@@ -111,11 +215,16 @@ func (h *HTX) doRequest(ctx context.Context, method, endpoint string, params map
 
 	req.Header.Set("Content-Type", "application/json")
 
+	if err := h.limiter.Wait(ctx, endpoint, signed); err != nil {
+		return nil, err
+	}
+
 	resp, err := h.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	h.limiter.Observe(endpoint, signed, resp)
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -128,15 +237,18 @@ func (h *HTX) doRequest(ctx context.Context, method, endpoint string, params map
 		ErrMsg  string `json:"err_msg"`
 	}
 	if err := json.Unmarshal(body, &baseResp); err != nil {
-		return nil, err
+		return nil, httpStatusError("htx", resp.StatusCode, body, err)
 	}
 
 	if baseResp.Status == "error" {
-		return nil, &ExchangeError{
+		return nil, h.limiter.CheckError(h.clock.CheckError(&ExchangeError{
 			Exchange: "htx",
 			Code:     strconv.Itoa(baseResp.ErrCode),
 			Message:  baseResp.ErrMsg,
-		}
+		}))
+	}
+	if resp.StatusCode >= http.StatusBadRequest {
+		return nil, httpStatusError("htx", resp.StatusCode, body, nil)
 	}
 
 	return body, nil
@@ -149,6 +261,11 @@ func (h *HTX) Connect(apiKey, secret, passphrase string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Смещение часов нужно до первого подписанного запроса; при ошибке подпись идёт по локальному времени
+	if err := h.clock.Sync(ctx); err != nil {
+		log.Printf("[htx] %v", err)
+	}
+
 	_, err := h.GetBalance(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to connect to HTX: %w", err)
@@ -202,11 +319,12 @@ func (h *HTX) GetTicker(ctx context.Context, symbol string) (*Ticker, error) {
 		return nil, err
 	}
 
+	// REST отдаёт close строкой, а WebSocket - числом; json.Number принимает оба
 	var resp struct {
 		Tick struct {
-			Bid   []float64 `json:"bid"`
-			Ask   []float64 `json:"ask"`
-			Close float64   `json:"close"`
+			Bid   []float64   `json:"bid"`
+			Ask   []float64   `json:"ask"`
+			Close json.Number `json:"close"`
 		} `json:"tick"`
 		Ts int64 `json:"ts"`
 	}
@@ -215,6 +333,11 @@ func (h *HTX) GetTicker(ctx context.Context, symbol string) (*Ticker, error) {
 		return nil, err
 	}
 
+	lastPrice, err := strconv.ParseFloat(resp.Tick.Close.String(), 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid close price %q for %s", resp.Tick.Close, symbol)
+	}
+
 	bidPrice := 0.0
 	askPrice := 0.0
 	if len(resp.Tick.Bid) > 0 {
@@ -228,12 +351,17 @@ func (h *HTX) GetTicker(ctx context.Context, symbol string) (*Ticker, error) {
 		Symbol:    symbol,
 		BidPrice:  bidPrice,
 		AskPrice:  askPrice,
-		LastPrice: resp.Tick.Close,
+		LastPrice: lastPrice,
 		Timestamp: time.UnixMilli(resp.Ts),
 	}, nil
 }
 
 func (h *HTX) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	inst, err := h.instruments.Get(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
 	contract := h.toHTXSymbol(symbol)
 
 	depthType := "step0"
@@ -289,12 +417,21 @@ func (h *HTX) GetOrderBook(ctx context.Context, symbol string, depth int) (*Orde
 		return orderBook.Asks[i].Price < orderBook.Asks[j].Price
 	})
 
+	// Объёмы стакана HTX в контрактах
+	inst.ScaleOrderBook(orderBook)
+
 	return orderBook, nil
 }
 
-func (h *HTX) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64) (*Order, error) {
+func (h *HTX) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*Order, error) {
 	contract := h.toHTXSymbol(symbol)
 
+	inst, size, err := h.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return nil, err
+	}
+	qty = inst.FromVenueSize(size)
+
 	direction := "buy"
 	offset := "open"
 	if side == SideSell || side == SideShort {
@@ -303,14 +440,17 @@ func (h *HTX) PlaceMarketOrder(ctx context.Context, symbol, side string, qty flo
 
 	params := map[string]string{
 		"contract_code":   contract,
-		"volume":          strconv.FormatFloat(qty, 'f', 0, 64),
+		"volume":          inst.FormatSize(size),
 		"direction":       direction,
 		"offset":          offset,
 		"order_price_type": "opponent", // Market order
-		"lever_rate":      "10",
+		"lever_rate":      h.leverRate(symbol),
+	}
+	if clientOrderID != "" {
+		params["client_order_id"] = htxClientOrderID(clientOrderID)
 	}
 
-	body, err := h.doRequest(ctx, http.MethodPost, "/linear-swap-api/v1/swap_order", params, true)
+	body, err := h.doRequest(ctx, http.MethodPost, h.swapPath(symbol, "order"), params, true)
 	if err != nil {
 		return nil, err
 	}
@@ -327,22 +467,23 @@ func (h *HTX) PlaceMarketOrder(ctx context.Context, symbol, side string, qty flo
 	}
 
 	order := &Order{
-		ID:        resp.Data.OrderIdStr,
-		Symbol:    symbol,
-		Side:      side,
-		Type:      "market",
-		Quantity:  qty,
-		FilledQty: qty,
-		Status:    OrderStatusFilled,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:            resp.Data.OrderIdStr,
+		ClientOrderID: clientOrderID,
+		Symbol:        symbol,
+		Side:          side,
+		Type:          "market",
+		Quantity:      qty,
+		FilledQty:     qty,
+		Status:        OrderStatusFilled,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	// Получаем информацию о заполнении
 	execInfo, err := h.getOrderDetail(ctx, contract, resp.Data.OrderId)
 	if err == nil && execInfo != nil {
 		order.AvgFillPrice = execInfo.AvgPrice
-		order.FilledQty = execInfo.FilledQty
+		order.FilledQty = inst.FromVenueSize(execInfo.FilledQty)
 	}
 
 	return order, nil
@@ -357,7 +498,7 @@ func (h *HTX) getOrderDetail(ctx context.Context, contract string, orderId int64
 		"order_id":      strconv.FormatInt(orderId, 10),
 	}
 
-	body, err := h.doRequest(ctx, http.MethodPost, "/linear-swap-api/v1/swap_order_info", params, true)
+	body, err := h.doRequest(ctx, http.MethodPost, h.swapPath(h.fromHTXSymbol(contract), "order_info"), params, true)
 	if err != nil {
 		return nil, err
 	}
@@ -391,50 +532,64 @@ func (h *HTX) GetOpenPositions(ctx context.Context) ([]*Position, error) {
 		"margin_account": "USDT",
 	}
 
-	body, err := h.doRequest(ctx, http.MethodPost, "/linear-swap-api/v1/swap_position_info", params, true)
-	if err != nil {
-		return nil, err
-	}
-
-	var resp struct {
-		Data []struct {
-			ContractCode  string  `json:"contract_code"`
-			Direction     string  `json:"direction"`
-			Volume        float64 `json:"volume"`
-			CostOpen      float64 `json:"cost_open"`
-			LastPrice     float64 `json:"last_price"`
-			LeverRate     int     `json:"lever_rate"`
-			Profit        float64 `json:"profit"`
-			LiqPrice      float64 `json:"liq_price"`
-		} `json:"data"`
-	}
-
-	if err := json.Unmarshal(body, &resp); err != nil {
-		return nil, err
+	// Позиции cross режима возвращаются отдельным эндпоинтом
+	endpoints := []string{"/linear-swap-api/v1/swap_position_info"}
+	if h.hasCrossMode() {
+		endpoints = append(endpoints, "/linear-swap-api/v1/swap_cross_position_info")
 	}
 
 	positions := make([]*Position, 0)
-	for _, p := range resp.Data {
-		if p.Volume == 0 {
-			continue
+	for _, endpoint := range endpoints {
+		body, err := h.doRequest(ctx, http.MethodPost, endpoint, params, true)
+		if err != nil {
+			return nil, err
 		}
 
-		side := SideLong
-		if p.Direction == "sell" {
-			side = SideShort
+		var resp struct {
+			Data []struct {
+				ContractCode  string  `json:"contract_code"`
+				Direction     string  `json:"direction"`
+				Volume        float64 `json:"volume"`
+				CostOpen      float64 `json:"cost_open"`
+				LastPrice     float64 `json:"last_price"`
+				LeverRate     int     `json:"lever_rate"`
+				Profit        float64 `json:"profit"`
+				LiqPrice      float64 `json:"liq_price"`
+			} `json:"data"`
 		}
 
-		positions = append(positions, &Position{
-			Symbol:        h.fromHTXSymbol(p.ContractCode),
-			Side:          side,
-			Size:          p.Volume,
-			EntryPrice:    p.CostOpen,
-			MarkPrice:     p.LastPrice,
-			Leverage:      p.LeverRate,
-			UnrealizedPnl: p.Profit,
-			Liquidation:   false,
-			UpdatedAt:     time.Now(),
-		})
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, p := range resp.Data {
+			if p.Volume == 0 {
+				continue
+			}
+
+			symbol := h.fromHTXSymbol(p.ContractCode)
+			inst, err := h.instruments.Get(ctx, symbol)
+			if err != nil {
+				return nil, err
+			}
+
+			side := SideLong
+			if p.Direction == "sell" {
+				side = SideShort
+			}
+
+			positions = append(positions, &Position{
+				Symbol:        symbol,
+				Side:          side,
+				Size:          inst.FromVenueSize(p.Volume),
+				EntryPrice:    p.CostOpen,
+				MarkPrice:     p.LastPrice,
+				Leverage:      p.LeverRate,
+				UnrealizedPnl: p.Profit,
+				Liquidation:   false,
+				UpdatedAt:     time.Now(),
+			})
+		}
 	}
 
 	return positions, nil
@@ -443,6 +598,11 @@ func (h *HTX) GetOpenPositions(ctx context.Context) ([]*Position, error) {
 func (h *HTX) ClosePosition(ctx context.Context, symbol, side string, qty float64) error {
 	contract := h.toHTXSymbol(symbol)
 
+	inst, size, err := h.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return err
+	}
+
 	direction := "sell"
 	if side == SideShort {
 		direction = "buy"
@@ -450,32 +610,319 @@ func (h *HTX) ClosePosition(ctx context.Context, symbol, side string, qty float6
 
 	params := map[string]string{
 		"contract_code":    contract,
-		"volume":           strconv.FormatFloat(qty, 'f', 0, 64),
+		"volume":           inst.FormatSize(size),
 		"direction":        direction,
 		"offset":           "close",
 		"order_price_type": "opponent",
-		"lever_rate":       "10",
+		"lever_rate":       h.leverRate(symbol),
 	}
 
-	_, err := h.doRequest(ctx, http.MethodPost, "/linear-swap-api/v1/swap_order", params, true)
+	_, err = h.doRequest(ctx, http.MethodPost, h.swapPath(symbol, "order"), params, true)
 	return err
 }
 
+// htxOrderPriceTypes - соответствие time in force значениям order_price_type HTX
+var htxOrderPriceTypes = map[string]string{
+	TimeInForceGTC:      "limit",
+	TimeInForceIOC:      "ioc",
+	TimeInForceFOK:      "fok",
+	TimeInForcePostOnly: "post_only",
+}
+
+// htxOrderInfo - ордер в ответах swap_order_info и swap_openorders
+type htxOrderInfo struct {
+	OrderIdStr     string      `json:"order_id_str"`
+	ClientOrderID  json.Number `json:"client_order_id"` // htxClientOrderID от ID клиента
+	ContractCode   string      `json:"contract_code"`
+	Direction      string      `json:"direction"`
+	OrderPriceType string      `json:"order_price_type"`
+	Price          float64     `json:"price"`
+	Volume         float64     `json:"volume"`
+	TradeVolume    float64     `json:"trade_volume"`
+	TradeAvgPrice  float64     `json:"trade_avg_price"`
+	Fee            float64     `json:"fee"` // отрицательная - списанная комиссия
+	Status         int         `json:"status"`
+	CreatedAt      int64       `json:"created_at"`
+}
+
+// htxClientOrderID переводит client order ID в число: HTX принимает только
+// целый client_order_id в диапазоне [1, 2^63-1]
+func htxClientOrderID(clientOrderID string) string {
+	hash := fnv.New64a()
+	hash.Write([]byte(clientOrderID))
+	id := hash.Sum64() & math.MaxInt64
+	if id == 0 {
+		id = 1
+	}
+	return strconv.FormatUint(id, 10)
+}
+
+// VenueClientOrderID возвращает client_order_id, который HTX получает вместо clientOrderID
+func (h *HTX) VenueClientOrderID(clientOrderID string) string {
+	return htxClientOrderID(clientOrderID)
+}
+
+func (h *HTX) PlaceLimitOrder(ctx context.Context, symbol, side string, qty, price float64, tif string) (*Order, error) {
+	tif, err := normalizeTimeInForce(tif)
+	if err != nil {
+		return nil, err
+	}
+
+	inst, size, err := h.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return nil, err
+	}
+	qty = inst.FromVenueSize(size)
+
+	direction := "buy"
+	if side == SideSell || side == SideShort {
+		direction = "sell"
+	}
+
+	params := map[string]string{
+		"contract_code":    h.toHTXSymbol(symbol),
+		"volume":           inst.FormatSize(size),
+		"price":            strconv.FormatFloat(price, 'f', -1, 64),
+		"direction":        direction,
+		"offset":           "open",
+		"order_price_type": htxOrderPriceTypes[tif],
+		"lever_rate":       h.leverRate(symbol),
+	}
+
+	body, err := h.doRequest(ctx, http.MethodPost, h.swapPath(symbol, "order"), params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data struct {
+			OrderIdStr string `json:"order_id_str"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	// Получаем состояние ордера (IOC/FOK к этому моменту уже исполнены или отменены)
+	order, err := h.GetOrder(ctx, symbol, resp.Data.OrderIdStr)
+	if err != nil {
+		return newLimitOrder(resp.Data.OrderIdStr, symbol, side, qty, price, tif), nil
+	}
+	order.Side = side
+	return order, nil
+}
+
+func (h *HTX) CancelOrder(ctx context.Context, symbol, orderID string) error {
+	params := map[string]string{
+		"contract_code": h.toHTXSymbol(symbol),
+		"order_id":      orderID,
+	}
+
+	body, err := h.doRequest(ctx, http.MethodPost, h.swapPath(symbol, "cancel"), params, true)
+	if err != nil {
+		return err
+	}
+
+	// Ошибки отмены отдельных ордеров приходят в data.errors при status = ok
+	var resp struct {
+		Data struct {
+			Errors []struct {
+				OrderId string `json:"order_id"`
+				ErrCode int    `json:"err_code"`
+				ErrMsg  string `json:"err_msg"`
+			} `json:"errors"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+
+	if len(resp.Data.Errors) > 0 {
+		return &ExchangeError{
+			Exchange: "htx",
+			Code:     strconv.Itoa(resp.Data.Errors[0].ErrCode),
+			Message:  resp.Data.Errors[0].ErrMsg,
+		}
+	}
+
+	return nil
+}
+
+func (h *HTX) GetOrder(ctx context.Context, symbol, orderID string) (*Order, error) {
+	return h.queryOrder(ctx, symbol, "order_id", orderID)
+}
+
+// GetOrderByClientID ищет ордер по client_order_id
+// HTX находит по client_order_id ордера, размещённые не ранее 8 часов назад
+func (h *HTX) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*Order, error) {
+	order, err := h.queryOrder(ctx, symbol, "client_order_id", htxClientOrderID(clientOrderID))
+	if err != nil {
+		return nil, err
+	}
+	order.ClientOrderID = clientOrderID
+	return order, nil
+}
+
+// queryOrder запрашивает ордер по order_id или client_order_id
+// 1061: биржа не знает ордер
+func (h *HTX) queryOrder(ctx context.Context, symbol, key, id string) (*Order, error) {
+	params := map[string]string{
+		"contract_code": h.toHTXSymbol(symbol),
+		key:             id,
+	}
+
+	body, err := h.doRequest(ctx, http.MethodPost, h.swapPath(symbol, "order_info"), params, true)
+	if err != nil {
+		return nil, orderNotFound(err, "1061")
+	}
+
+	var resp struct {
+		Data []htxOrderInfo `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("order %s: %w", id, ErrOrderNotFound)
+	}
+	return h.parseOrder(resp.Data[0]), nil
+}
+
+func (h *HTX) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	params := map[string]string{}
+	if symbol != "" {
+		params["contract_code"] = h.toHTXSymbol(symbol)
+	}
+
+	body, err := h.doRequest(ctx, http.MethodPost, h.swapPath(symbol, "openorders"), params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data struct {
+			Orders []htxOrderInfo `json:"orders"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	orders := make([]*Order, 0, len(resp.Data.Orders))
+	for _, info := range resp.Data.Orders {
+		orders = append(orders, h.parseOrder(info))
+	}
+	return orders, nil
+}
+
+// parseOrder конвертирует ордер HTX в Order
+// Размеры переводятся из контрактов в монеты
+func (h *HTX) parseOrder(info htxOrderInfo) *Order {
+	symbol := h.fromHTXSymbol(info.ContractCode)
+	order := &Order{
+		ID:            info.OrderIdStr,
+		ClientOrderID: info.ClientOrderID.String(),
+		Symbol:        symbol,
+		Side:          info.Direction,
+		Type:          OrderTypeMarket,
+		Price:         info.Price,
+		Quantity:      h.instruments.CoinQty(symbol, info.Volume),
+		FilledQty:     h.instruments.CoinQty(symbol, info.TradeVolume),
+		AvgFillPrice:  info.TradeAvgPrice,
+		Fee:           -info.Fee,
+		CreatedAt:     time.UnixMilli(info.CreatedAt),
+		UpdatedAt:     time.Now(),
+	}
+	if order.ClientOrderID == "0" { // ордер без client_order_id (например, ликвидация)
+		order.ClientOrderID = ""
+	}
+
+	for tif, priceType := range htxOrderPriceTypes {
+		if info.OrderPriceType == priceType {
+			order.Type = OrderTypeLimit
+			order.TimeInForce = tif
+		}
+	}
+
+	// Статусы HTX: 1-3 принят, 4 частично исполнен, 5 частично исполнен и отменён,
+	// 6 исполнен, 7 отменён, 11 отменяется
+	switch info.Status {
+	case 1, 2, 3:
+		order.Status = OrderStatusNew
+	case 4, 11:
+		order.Status = OrderStatusPartial
+		if info.TradeVolume == 0 {
+			order.Status = OrderStatusNew
+		}
+	case 6:
+		order.Status = OrderStatusFilled
+	default: // 5, 7
+		order.Status = OrderStatusCancelled
+	}
+
+	return order
+}
+
 func (h *HTX) SubscribeTicker(symbol string, callback func(*Ticker)) error {
 	h.callbackMu.Lock()
 	h.tickerCallbacks[symbol] = callback
 	h.callbackMu.Unlock()
 
+	contract := h.toHTXSymbol(symbol)
+	return h.subscribePublic(map[string]interface{}{
+		"sub": fmt.Sprintf("market.%s.detail", contract),
+		"id":  fmt.Sprintf("ticker_%s", contract),
+	})
+}
+
+// SubscribeOrderBook подписывается на инкрементальный стакан depth.size_{20|150}.high_freq
+// Первое сообщение - снимок, далее version каждого обновления увеличивается на 1
+func (h *HTX) SubscribeOrderBook(symbol string, depth int, callback func(*OrderBook)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	inst, err := h.instruments.Get(ctx, symbol)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	sub := h.books.add(symbol, depth, inst, callback)
+	return h.subscribePublic(h.orderBookSub("sub", symbol, sub.depth))
+}
+
+// orderBookSub формирует сообщение sub/unsub канала стакана
+func (h *HTX) orderBookSub(op, symbol string, depth int) map[string]interface{} {
+	size := 20
+	if depth > 20 {
+		size = 150
+	}
+	contract := h.toHTXSymbol(symbol)
+	return map[string]interface{}{
+		op:          fmt.Sprintf("market.%s.depth.size_%d.high_freq", contract, size),
+		"data_type": "incremental",
+		"id":        fmt.Sprintf("depth_%s", contract),
+	}
+}
+
+// subscribePublic отправляет подписку в публичный WebSocket, подключаясь при необходимости
+func (h *HTX) subscribePublic(subMsg map[string]interface{}) error {
 	h.wsMu.Lock()
 	if h.wsManager == nil {
 		config := DefaultWSReconnectConfig()
-		h.wsManager = NewWSReconnectManager("htx", htxWSURL, config)
+		h.wsManager = NewWSReconnectManager("htx", h.endpoints.WSPublic, config)
+		h.health.TrackWS(h.wsManager)
 
 		h.wsManager.SetOnMessage(h.handleMessage)
 		h.wsManager.SetOnConnect(func() {
 			log.Printf("[htx] WebSocket connected")
 		})
+		// Стаканы ждут нового снимка, который придёт после переподписки
 		h.wsManager.SetOnDisconnect(func(err error) {
+			h.books.invalidateAll()
 			if err != nil {
 				log.Printf("[htx] WebSocket disconnected: %v", err)
 			}
@@ -489,116 +936,428 @@ func (h *HTX) SubscribeTicker(symbol string, callback func(*Ticker)) error {
 	wsManager := h.wsManager
 	h.wsMu.Unlock()
 
-	contract := h.toHTXSymbol(symbol)
-	subMsg := map[string]interface{}{
-		"sub": fmt.Sprintf("market.%s.detail", contract),
-		"id":  fmt.Sprintf("ticker_%s", contract),
-	}
-
 	wsManager.AddSubscription(subMsg)
 	return wsManager.Send(subMsg)
 }
 
 // handleMessage обрабатывает одно сообщение из WebSocket
 func (h *HTX) handleMessage(message []byte) {
-	// HTX отправляет сжатые сообщения, нужно распаковать
-	// Для упрощения пропускаем распаковку и обрабатываем как JSON
+	// HTX отправляет сообщения в gzip
+	message = decompressWSMessage(message)
 
 	var msg struct {
-		Ch   string `json:"ch"`
-		Tick struct {
-			Bid   []float64 `json:"bid"`
-			Ask   []float64 `json:"ask"`
-			Close float64   `json:"close"`
-		} `json:"tick"`
-		Ts int64 `json:"ts"`
+		Ping int64           `json:"ping"`
+		Ch   string          `json:"ch"`
+		Tick json.RawMessage `json:"tick"`
+		Ts   int64           `json:"ts"`
 	}
 
 	if err := json.Unmarshal(message, &msg); err != nil {
 		return
 	}
 
-	if strings.Contains(msg.Ch, ".detail") {
-		// Извлекаем symbol из канала
-		parts := strings.Split(msg.Ch, ".")
-		if len(parts) >= 2 {
-			contract := parts[1]
-			symbol := h.fromHTXSymbol(contract)
-
-			h.callbackMu.RLock()
-			callback, ok := h.tickerCallbacks[symbol]
-			h.callbackMu.RUnlock()
-
-			if ok && callback != nil {
-				bidPrice := 0.0
-				askPrice := 0.0
-				if len(msg.Tick.Bid) > 0 {
-					bidPrice = msg.Tick.Bid[0]
-				}
-				if len(msg.Tick.Ask) > 0 {
-					askPrice = msg.Tick.Ask[0]
-				}
-
-				callback(&Ticker{
-					Symbol:    symbol,
-					BidPrice:  bidPrice,
-					AskPrice:  askPrice,
-					LastPrice: msg.Tick.Close,
-					Timestamp: time.UnixMilli(msg.Ts),
-				})
-			}
+	// Без ответа на ping сервер закрывает соединение
+	if msg.Ping != 0 {
+		h.wsMu.Lock()
+		wsManager := h.wsManager
+		h.wsMu.Unlock()
+		if wsManager != nil {
+			wsManager.Send(map[string]int64{"pong": msg.Ping})
 		}
+		return
 	}
-}
-
-// SubscribePositions сохраняет callback для обновлений позиций.
-//
-// ВАЖНО: HTX (Huobi) требует отдельного аутентифицированного WebSocket соединения
-// для приватных каналов (позиции, ордера). Текущая реализация использует упрощённый
-// подход - callback сохраняется, но WebSocket подписка не создаётся.
-//
-// Обнаружение ликвидаций реализуется через:
-// 1. Периодический polling через GetOpenPositions() в Risk Manager (каждые 500ms)
-// 2. Сравнение markPrice с liquidationPrice для раннего предупреждения
-//
-// Для полной реализации потребуется:
-// - Отдельный WebSocket к wss://api.hbdm.com/linear-swap-notification
-// - Аутентификация через HMAC-SHA256 с timestamp
-// - Подписка на канал "positions" с contract_code
-//
-// См. документацию HTX: https://www.htx.com/en-us/opend/newApiPages/?id=8cb7e898-77b5-11ed-9966-0242ac110003
-func (h *HTX) SubscribePositions(callback func(*Position)) error {
-	h.callbackMu.Lock()
-	h.positionCallback = callback
-	h.callbackMu.Unlock()
 
-	// Callback сохранён для использования при polling через REST API
-	// или для будущей реализации WebSocket подписки
-	log.Printf("[htx] SubscribePositions: callback registered (using REST API polling for position updates)")
-	return nil
-}
+	// Извлекаем symbol из канала
+	parts := strings.Split(msg.Ch, ".")
+	if len(parts) < 3 || len(msg.Tick) == 0 {
+		return
+	}
+	symbol := h.fromHTXSymbol(parts[1])
 
-func (h *HTX) GetTradingFee(ctx context.Context, symbol string) (float64, error) {
-	return 0.0004, nil // 0.04% стандартная комиссия
+	switch {
+	case strings.Contains(msg.Ch, ".detail"):
+		h.handleTickerUpdate(symbol, msg.Tick, msg.Ts)
+	case strings.Contains(msg.Ch, ".depth."):
+		h.handleOrderBookUpdate(symbol, msg.Tick)
+	}
 }
 
-func (h *HTX) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
-	contract := h.toHTXSymbol(symbol)
+// handleTickerUpdate обрабатывает канал detail
+func (h *HTX) handleTickerUpdate(symbol string, data json.RawMessage, ts int64) {
+	var tick struct {
+		Bid   []float64 `json:"bid"`
+		Ask   []float64 `json:"ask"`
+		Close float64   `json:"close"`
+	}
 
-	params := map[string]string{
-		"contract_code": contract,
+	if err := json.Unmarshal(data, &tick); err != nil {
+		return
 	}
 
-	body, err := h.doRequest(ctx, http.MethodGet, "/linear-swap-api/v1/swap_contract_info", params, false)
-	if err != nil {
+	h.callbackMu.RLock()
+	callback, ok := h.tickerCallbacks[symbol]
+	h.callbackMu.RUnlock()
+
+	if ok && callback != nil {
+		bidPrice := 0.0
+		askPrice := 0.0
+		if len(tick.Bid) > 0 {
+			bidPrice = tick.Bid[0]
+		}
+		if len(tick.Ask) > 0 {
+			askPrice = tick.Ask[0]
+		}
+
+		callback(&Ticker{
+			Symbol:    symbol,
+			BidPrice:  bidPrice,
+			AskPrice:  askPrice,
+			LastPrice: tick.Close,
+			Timestamp: time.UnixMilli(ts),
+		})
+	}
+}
+
+// handleOrderBookUpdate применяет снимок или обновление инкрементального стакана
+func (h *HTX) handleOrderBookUpdate(symbol string, data json.RawMessage) {
+	var tick struct {
+		Bids    [][]float64 `json:"bids"`
+		Asks    [][]float64 `json:"asks"`
+		Event   string      `json:"event"`
+		Version int64       `json:"version"`
+		Ts      int64       `json:"ts"`
+	}
+
+	if err := json.Unmarshal(data, &tick); err != nil {
+		return
+	}
+
+	sub := h.books.get(symbol)
+	if sub == nil {
+		return
+	}
+
+	bids, asks := floatBookLevels(tick.Bids), floatBookLevels(tick.Asks)
+	if tick.Event == "snapshot" {
+		sub.book.Reset(bids, asks, tick.Version, time.UnixMilli(tick.Ts))
+	} else if err := sub.book.Apply(bids, asks, tick.Version-1, tick.Version, time.UnixMilli(tick.Ts)); err != nil {
+		if err != ErrOrderBookNotSynced {
+			log.Printf("[htx] order book %s: %v, resubscribing", symbol, err)
+			h.resubscribeOrderBook(symbol, sub.depth)
+		}
+		return
+	}
+
+	sub.emit()
+}
+
+// resubscribeOrderBook переподписывается на стакан, чтобы получить новый снимок
+func (h *HTX) resubscribeOrderBook(symbol string, depth int) {
+	h.wsMu.Lock()
+	wsManager := h.wsManager
+	h.wsMu.Unlock()
+
+	if wsManager == nil {
+		return
+	}
+
+	if err := wsManager.Send(h.orderBookSub("unsub", symbol, depth)); err != nil {
+		return
+	}
+	if err := wsManager.Send(h.orderBookSub("sub", symbol, depth)); err != nil {
+		log.Printf("[htx] resubscribe order book %s failed: %v", symbol, err)
+	}
+}
+
+// SubscribePositions подписывается на приватные каналы позиций и ордеров
+// (wss://api.hbdm.com/linear-swap-notification, isolated и cross топики)
+//
+// Ликвидация определяется по событию позиции "order.liquidation"
+// и по ордеру принудительной ликвидации (order_type = 3)
+func (h *HTX) SubscribePositions(callback func(*Position)) error {
+	h.callbackMu.Lock()
+	h.positionCallback = callback
+	h.callbackMu.Unlock()
+
+	return h.subscribePrivate()
+}
+
+// SubscribeOrders подписывается на обновления ордеров (топики orders.* и orders_cross.*)
+func (h *HTX) SubscribeOrders(callback func(*Order)) error {
+	h.callbackMu.Lock()
+	h.orderCallback = callback
+	h.callbackMu.Unlock()
+
+	return h.subscribePrivate()
+}
+
+// subscribePrivate подключается к приватному WebSocket и подписывается на все топики
+// Позиции и ордера нужны обоим подписчикам (ликвидация определяется и по ордерам),
+// поэтому подписка выполняется один раз при создании соединения
+func (h *HTX) subscribePrivate() error {
+	// Защита от race condition при инициализации WebSocket manager
+	h.wsMu.Lock()
+	if h.wsPrivateManager != nil {
+		h.wsMu.Unlock()
+		return nil
+	}
+
+	config := DefaultWSReconnectConfig()
+	wsManager := NewWSReconnectManager("htx-private", h.endpoints.WSPrivate, config)
+	h.health.TrackWS(wsManager)
+
+	// Авторизация выполняется заново при каждом переподключении
+	wsManager.SetAuthFunc(h.authenticateWebSocket)
+	wsManager.SetOnMessage(h.handlePrivateMessage)
+	wsManager.SetOnConnect(func() {
+		log.Printf("[htx] Private WebSocket connected")
+	})
+	wsManager.SetOnDisconnect(func(err error) {
+		if err != nil {
+			log.Printf("[htx] Private WebSocket disconnected: %v", err)
+		}
+	})
+
+	if err := wsManager.Connect(); err != nil {
+		h.wsMu.Unlock()
+		return fmt.Errorf("failed to connect to private WebSocket: %w", err)
+	}
+	h.wsPrivateManager = wsManager
+	h.wsMu.Unlock()
+
+	// Позиции и ордера isolated и cross режимов приходят в разные топики
+	for _, topic := range []string{"positions.*", "positions_cross.*", "orders.*", "orders_cross.*"} {
+		subMsg := map[string]interface{}{
+			"op":    "sub",
+			"cid":   topic,
+			"topic": topic,
+		}
+
+		wsManager.AddSubscription(subMsg)
+		if err := wsManager.Send(subMsg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// htxWSOp - служебное сообщение приватного WebSocket HTX (auth, ping, sub)
+type htxWSOp struct {
+	Op      string          `json:"op"`
+	Topic   string          `json:"topic"`
+	Ts      json.RawMessage `json:"ts"`
+	Event   string          `json:"event"`
+	ErrCode int             `json:"err-code"`
+	ErrMsg  string          `json:"err-msg"`
+}
+
+// authenticateWebSocket подписывает соединение ключом API и ждёт подтверждения
+// Подписки отправляются сразу после авторизации, поэтому ответ читается синхронно
+func (h *HTX) authenticateWebSocket(conn *websocket.Conn) error {
+	timestamp := h.clock.Now().UTC().Format("2006-01-02T15:04:05")
+
+	params := url.Values{}
+	params.Set("AccessKeyId", h.apiKey)
+	params.Set("SignatureMethod", "HmacSHA256")
+	params.Set("SignatureVersion", "2")
+	params.Set("Timestamp", timestamp)
+
+	host, path := htxSignTarget(h.endpoints.WSPrivate)
+	authMsg := map[string]string{
+		"op":               "auth",
+		"type":             "api",
+		"AccessKeyId":      h.apiKey,
+		"SignatureMethod":  "HmacSHA256",
+		"SignatureVersion": "2",
+		"Timestamp":        timestamp,
+		"Signature":        h.sign(http.MethodGet, host, path, params),
+	}
+
+	if err := conn.WriteJSON(authMsg); err != nil {
+		return err
+	}
+
+	conn.SetReadDeadline(time.Now().Add(10 * time.Second))
+	defer conn.SetReadDeadline(time.Time{})
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return err
+		}
+
+		var op htxWSOp
+		if err := json.Unmarshal(decompressWSMessage(message), &op); err != nil {
+			continue
+		}
+
+		switch op.Op {
+		case "ping":
+			if err := conn.WriteJSON(map[string]interface{}{"op": "pong", "ts": op.Ts}); err != nil {
+				return err
+			}
+		case "auth":
+			if op.ErrCode != 0 {
+				return &ExchangeError{
+					Exchange: "htx",
+					Code:     strconv.Itoa(op.ErrCode),
+					Message:  op.ErrMsg,
+				}
+			}
+			return nil
+		}
+	}
+}
+
+// handlePrivateMessage обрабатывает сообщение приватного WebSocket
+func (h *HTX) handlePrivateMessage(message []byte) {
+	message = decompressWSMessage(message)
+
+	var op htxWSOp
+	if err := json.Unmarshal(message, &op); err != nil {
+		return
+	}
+
+	switch {
+	case op.Op == "ping":
+		// Без ответа на ping сервер закрывает соединение
+		h.wsMu.Lock()
+		wsManager := h.wsPrivateManager
+		h.wsMu.Unlock()
+		if wsManager != nil {
+			wsManager.Send(map[string]interface{}{"op": "pong", "ts": op.Ts})
+		}
+
+	case op.Op == "notify" && strings.HasPrefix(op.Topic, "positions"):
+		h.handlePositionsNotify(message, op.Event == "order.liquidation")
+
+	case op.Op == "notify" && strings.HasPrefix(op.Topic, "orders"):
+		h.handleOrdersNotify(message)
+
+	case op.Op == "sub" && op.ErrCode != 0:
+		log.Printf("[htx] Private subscription %s failed: %d %s", op.Topic, op.ErrCode, op.ErrMsg)
+	}
+}
+
+// handlePositionsNotify отправляет обновления позиций в callback
+func (h *HTX) handlePositionsNotify(message []byte, liquidation bool) {
+	var msg struct {
+		Data []struct {
+			ContractCode string  `json:"contract_code"`
+			Direction    string  `json:"direction"`
+			Volume       float64 `json:"volume"`
+			CostOpen     float64 `json:"cost_open"`
+			LastPrice    float64 `json:"last_price"`
+			LeverRate    int     `json:"lever_rate"`
+			ProfitUnreal float64 `json:"profit_unreal"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
+	}
+
+	h.callbackMu.RLock()
+	callback := h.positionCallback
+	h.callbackMu.RUnlock()
+
+	if callback == nil {
+		return
+	}
+
+	for _, p := range msg.Data {
+		side := SideLong
+		if p.Direction == "sell" {
+			side = SideShort
+		}
+
+		symbol := h.fromHTXSymbol(p.ContractCode)
+		callback(&Position{
+			Symbol:        symbol,
+			Side:          side,
+			Size:          h.instruments.CoinQty(symbol, p.Volume),
+			EntryPrice:    p.CostOpen,
+			MarkPrice:     p.LastPrice,
+			Leverage:      p.LeverRate,
+			UnrealizedPnl: p.ProfitUnreal,
+			Liquidation:   liquidation,
+			UpdatedAt:     time.Now(),
+		})
+	}
+}
+
+// htxOrderTypeLiquidation - order_type ордера принудительной ликвидации
+const htxOrderTypeLiquidation = 3
+
+// handleOrdersNotify отправляет обновление ордера в callback и сообщает о ликвидации
+// по исполнению ордера принудительной ликвидации. Ордер ликвидации закрывает позицию:
+// sell - лонг, buy - шорт
+func (h *HTX) handleOrdersNotify(message []byte) {
+	var msg struct {
+		htxOrderInfo
+		OrderType int `json:"order_type"`
+		LeverRate int `json:"lever_rate"`
+	}
+
+	if err := json.Unmarshal(message, &msg); err != nil {
+		return
+	}
+
+	h.callbackMu.RLock()
+	orderCallback := h.orderCallback
+	positionCallback := h.positionCallback
+	h.callbackMu.RUnlock()
+
+	if orderCallback != nil && msg.OrderIdStr != "" {
+		orderCallback(h.parseOrder(msg.htxOrderInfo))
+	}
+
+	if msg.OrderType != htxOrderTypeLiquidation || msg.TradeVolume <= 0 || positionCallback == nil {
+		return
+	}
+
+	side := SideShort
+	if msg.Direction == "sell" {
+		side = SideLong
+	}
+
+	positionCallback(&Position{
+		Symbol:      h.fromHTXSymbol(msg.ContractCode),
+		Side:        side,
+		MarkPrice:   msg.TradeAvgPrice,
+		Leverage:    msg.LeverRate,
+		Liquidation: true,
+		UpdatedAt:   time.Now(),
+	})
+}
+
+func (h *HTX) GetTradingFee(ctx context.Context, symbol string) (float64, error) {
+	fees, err := h.GetTradingFees(ctx, symbol)
+	if err != nil {
+		return 0.0004, nil // 0.04% стандартная комиссия
+	}
+	return fees.Taker, nil
+}
+
+// GetTradingFees получает комиссии аккаунта из /linear-swap-api/v1/swap_fee
+// HTX отдаёт ставки открытия и закрытия раздельно - берём большую из них
+func (h *HTX) GetTradingFees(ctx context.Context, symbol string) (*TradingFees, error) {
+	params := map[string]string{
+		"contract_code": h.toHTXSymbol(symbol),
+	}
+
+	body, err := h.doRequest(ctx, http.MethodPost, "/linear-swap-api/v1/swap_fee", params, true)
+	if err != nil {
 		return nil, err
 	}
 
 	var resp struct {
 		Data []struct {
-			ContractCode  string  `json:"contract_code"`
-			ContractSize  float64 `json:"contract_size"`
-			PriceTick     float64 `json:"price_tick"`
+			OpenMakerFee  string `json:"open_maker_fee"`
+			OpenTakerFee  string `json:"open_taker_fee"`
+			CloseMakerFee string `json:"close_maker_fee"`
+			CloseTakerFee string `json:"close_taker_fee"`
 		} `json:"data"`
 	}
 
@@ -607,22 +1366,364 @@ func (h *HTX) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
 	}
 
 	if len(resp.Data) == 0 {
-		return nil, fmt.Errorf("contract info not found for %s", symbol)
+		return nil, fmt.Errorf("fee not found for %s", symbol)
 	}
 
-	info := resp.Data[0]
+	d := resp.Data[0]
+	parse := func(values ...string) float64 {
+		var fee float64
+		for i, v := range values {
+			f, _ := strconv.ParseFloat(v, 64)
+			if i == 0 || f > fee {
+				fee = f
+			}
+		}
+		return fee
+	}
 
-	return &Limits{
-		Symbol:      symbol,
-		MinOrderQty: 1,
-		MaxOrderQty: 1000000,
-		QtyStep:     1,
-		MinNotional: 5.0,
-		PriceStep:   info.PriceTick,
-		MaxLeverage: 125,
+	return &TradingFees{
+		Symbol:    symbol,
+		Maker:     parse(d.OpenMakerFee, d.CloseMakerFee),
+		Taker:     parse(d.OpenTakerFee, d.CloseTakerFee),
+		Timestamp: time.Now(),
 	}, nil
 }
 
+func (h *HTX) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
+	inst, err := h.GetInstrument(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return inst.Limits(), nil
+}
+
+func (h *HTX) GetInstrument(ctx context.Context, symbol string) (*Instrument, error) {
+	return h.instruments.Get(ctx, symbol)
+}
+
+// htxMaxLeverage - максимальное плечо HTX (в спецификации контракта не передаётся)
+const htxMaxLeverage = 125
+
+// loadInstruments загружает спецификации всех USDT бессрочных контрактов
+// Объём ордера HTX - целое число контрактов по contract_size монет
+func (h *HTX) loadInstruments(ctx context.Context) ([]*Instrument, error) {
+	body, err := h.doRequest(ctx, http.MethodGet, "/linear-swap-api/v1/swap_contract_info", nil, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data []struct {
+			ContractCode   string  `json:"contract_code"`
+			ContractSize   float64 `json:"contract_size"`
+			PriceTick      float64 `json:"price_tick"`
+			ContractStatus int     `json:"contract_status"`
+			BusinessType   string  `json:"business_type"`
+			TradePartition string  `json:"trade_partition"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	instruments := make([]*Instrument, 0, len(resp.Data))
+	for _, c := range resp.Data {
+		if c.BusinessType != "" && c.BusinessType != "swap" {
+			continue
+		}
+
+		// contract_status: 1 - торгуется, 2-6 - листинг, приостановка или расчёты,
+		// 0, 7, 8 - делистинг и завершённые расчёты
+		status := InstrumentStatusDelisted
+		switch c.ContractStatus {
+		case 1:
+			status = InstrumentStatusTrading
+		case 2, 3, 4, 5, 6:
+			status = InstrumentStatusSuspended
+		}
+
+		settle := c.TradePartition
+		if settle == "" {
+			settle = "USDT"
+		}
+
+		instruments = append(instruments, &Instrument{
+			Symbol:         h.fromHTXSymbol(c.ContractCode),
+			VenueSymbol:    c.ContractCode,
+			ContractValue:  c.ContractSize,
+			LotSize:        1,
+			MinSize:        1,
+			TickSize:       c.PriceTick,
+			MinNotional:    5.0,
+			MaxLeverage:    htxMaxLeverage,
+			SettleCurrency: settle,
+			Status:         status,
+		})
+	}
+
+	return instruments, nil
+}
+
+func (h *HTX) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	params := map[string]string{
+		"contract_code": h.toHTXSymbol(symbol),
+	}
+
+	body, err := h.doRequest(ctx, http.MethodGet, "/linear-swap-api/v1/swap_funding_rate", params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	// Числа HTX отдаёт строками; estimated_rate может быть null
+	var resp struct {
+		Data struct {
+			FundingRate     string  `json:"funding_rate"`
+			EstimatedRate   *string `json:"estimated_rate"`
+			FundingTime     string  `json:"funding_time"`
+			NextFundingTime *string `json:"next_funding_time"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	rate, err := strconv.ParseFloat(resp.Data.FundingRate, 64)
+	if err != nil {
+		return nil, fmt.Errorf("funding rate not found for %s", symbol)
+	}
+
+	var predicted float64
+	if resp.Data.EstimatedRate != nil {
+		predicted, _ = strconv.ParseFloat(*resp.Data.EstimatedRate, 64)
+	}
+
+	// funding_time - ближайшее начисление, next_funding_time - следующее за ним
+	fundingTime, _ := strconv.ParseInt(resp.Data.FundingTime, 10, 64)
+	interval := DefaultFundingInterval
+	if resp.Data.NextFundingTime != nil {
+		if next, err := strconv.ParseInt(*resp.Data.NextFundingTime, 10, 64); err == nil && next > fundingTime && fundingTime > 0 {
+			interval = time.Duration(next-fundingTime) * time.Millisecond
+		}
+	}
+
+	return &FundingRate{
+		Symbol:          symbol,
+		Rate:            rate,
+		PredictedRate:   predicted,
+		NextFundingTime: time.UnixMilli(fundingTime),
+		Interval:        interval,
+		Timestamp:       time.Now(),
+	}, nil
+}
+
+// SetLeverage устанавливает плечо контракта в текущем режиме маржи и запоминает его для ордеров
+func (h *HTX) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if err := validateLeverage(leverage); err != nil {
+		return err
+	}
+
+	params := map[string]string{
+		"contract_code": h.toHTXSymbol(symbol),
+		"lever_rate":    strconv.Itoa(leverage),
+	}
+
+	if _, err := h.doRequest(ctx, http.MethodPost, h.swapPath(symbol, "switch_lever_rate"), params, true); err != nil {
+		return err
+	}
+
+	h.settingsMu.Lock()
+	h.leverages[symbol] = leverage
+	h.settingsMu.Unlock()
+	return nil
+}
+
+// SetMarginMode выбирает режим маржи контракта
+// У HTX нет переключения режима: isolated и cross - разные эндпоинты торговли,
+// поэтому режим только запоминается. Плечо нужно установить заново
+func (h *HTX) SetMarginMode(ctx context.Context, symbol, mode string) error {
+	if err := validateMarginMode(mode); err != nil {
+		return err
+	}
+
+	h.settingsMu.Lock()
+	if h.marginModes[symbol] != mode {
+		delete(h.leverages, symbol)
+	}
+	h.marginModes[symbol] = mode
+	h.settingsMu.Unlock()
+	return nil
+}
+
+// GetMarginSettings читает плечо и режим маржи открытых позиций контракта (margin_mode)
+// Без позиции режим у HTX задаётся эндпоинтом торговли: возвращается плечо счёта
+// режима ордеров и ErrMarginModeNotReported
+func (h *HTX) GetMarginSettings(ctx context.Context, symbol string) (int, string, error) {
+	contract := h.toHTXSymbol(symbol)
+
+	// Позиции isolated и cross возвращают разные эндпоинты
+	var positions []positionMargin
+	for _, endpoint := range []string{"/linear-swap-api/v1/swap_position_info", "/linear-swap-api/v1/swap_cross_position_info"} {
+		body, err := h.doRequest(ctx, http.MethodPost, endpoint, map[string]string{"contract_code": contract}, true)
+		if err != nil {
+			return 0, "", err
+		}
+
+		var resp struct {
+			Data []struct {
+				ContractCode string  `json:"contract_code"`
+				Volume       float64 `json:"volume"`
+				LeverRate    int     `json:"lever_rate"`
+				MarginMode   string  `json:"margin_mode"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return 0, "", err
+		}
+		for _, p := range resp.Data {
+			if p.ContractCode == contract && p.Volume > 0 {
+				positions = append(positions, positionMargin{leverage: p.LeverRate, mode: p.MarginMode})
+			}
+		}
+	}
+	if len(positions) > 0 {
+		return commonMargin(symbol, positions)
+	}
+
+	mode := h.marginMode(symbol)
+	params := map[string]string{"contract_code": contract}
+	if mode == MarginModeCross {
+		params = map[string]string{"margin_account": "USDT"}
+	}
+
+	body, err := h.doRequest(ctx, http.MethodPost, h.swapPath(symbol, "account_info"), params, true)
+	if err != nil {
+		return 0, "", err
+	}
+
+	// isolated: счёт на контракт; cross: общий счёт с плечом по контрактам
+	type htxContractLever struct {
+		ContractCode string `json:"contract_code"`
+		LeverRate    int    `json:"lever_rate"`
+	}
+	var resp struct {
+		Data []struct {
+			htxContractLever
+			ContractDetail []htxContractLever `json:"contract_detail"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, "", err
+	}
+
+	for _, account := range resp.Data {
+		for _, c := range append([]htxContractLever{account.htxContractLever}, account.ContractDetail...) {
+			if c.ContractCode == contract && c.LeverRate > 0 {
+				return c.LeverRate, "", ErrMarginModeNotReported
+			}
+		}
+	}
+	return 0, "", fmt.Errorf("no %s margin account for %s", mode, contract)
+}
+
+// GetFundingPayments читает начисления фандинга контракта из финансовой истории
+// (type 30 - получено, 31 - уплачено). Счета isolated (маржа контракта) и cross (USDT)
+// у HTX раздельные, поэтому история читается по обоим
+func (h *HTX) GetFundingPayments(ctx context.Context, symbol string, from, to time.Time) ([]FundingPayment, error) {
+	contract := h.toHTXSymbol(symbol)
+
+	var payments []FundingPayment
+	for _, account := range []string{contract, "USDT"} {
+		params := map[string]string{
+			"mar_acct":      account,
+			"contract_code": contract,
+			"type":          "30,31",
+			"start_time":    strconv.FormatInt(from.UnixMilli(), 10),
+			"end_time":      strconv.FormatInt(to.UnixMilli(), 10),
+		}
+		body, err := h.doRequest(ctx, http.MethodPost, "/linear-swap-api/v1/swap_financial_record_exact", params, true)
+		if err != nil {
+			return nil, err
+		}
+
+		var resp struct {
+			Data struct {
+				FinancialRecord []struct {
+					ContractCode string  `json:"contract_code"`
+					Type         int     `json:"type"`
+					Amount       float64 `json:"amount"`
+					Ts           int64   `json:"ts"`
+				} `json:"financial_record"`
+			} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &resp); err != nil {
+			return nil, err
+		}
+
+		for _, r := range resp.Data.FinancialRecord {
+			if r.ContractCode != contract {
+				continue
+			}
+			amount := math.Abs(r.Amount)
+			switch r.Type {
+			case 30:
+			case 31:
+				amount = -amount
+			default:
+				continue
+			}
+			payments = append(payments, FundingPayment{Symbol: symbol, Amount: amount, Time: time.UnixMilli(r.Ts)})
+		}
+	}
+	return fundingPaymentsBetween(payments, from, to), nil
+}
+
+// marginMode возвращает режим маржи контракта (по умолчанию isolated)
+func (h *HTX) marginMode(symbol string) string {
+	h.settingsMu.RLock()
+	mode := h.marginModes[symbol]
+	h.settingsMu.RUnlock()
+
+	if mode == "" {
+		return MarginModeIsolated
+	}
+	return mode
+}
+
+// swapPath возвращает эндпоинт торговли контрактом с учётом режима маржи
+func (h *HTX) swapPath(symbol, op string) string {
+	if h.marginMode(symbol) == MarginModeCross {
+		return "/linear-swap-api/v1/swap_cross_" + op
+	}
+	return "/linear-swap-api/v1/swap_" + op
+}
+
+// leverRate возвращает плечо контракта для ордеров (по умолчанию 10)
+func (h *HTX) leverRate(symbol string) string {
+	h.settingsMu.RLock()
+	leverage := h.leverages[symbol]
+	h.settingsMu.RUnlock()
+
+	if leverage <= 0 {
+		return "10"
+	}
+	return strconv.Itoa(leverage)
+}
+
+// hasCrossMode возвращает true если хотя бы один контракт торгуется в cross режиме
+func (h *HTX) hasCrossMode() bool {
+	h.settingsMu.RLock()
+	defer h.settingsMu.RUnlock()
+
+	for _, mode := range h.marginModes {
+		if mode == MarginModeCross {
+			return true
+		}
+	}
+	return false
+}
+
 func (h *HTX) Close() error {
 	select {
 	case <-h.closeChan:
@@ -635,6 +1736,10 @@ func (h *HTX) Close() error {
 		h.wsManager.Close()
 		h.wsManager = nil
 	}
+	if h.wsPrivateManager != nil {
+		h.wsPrivateManager.Close()
+		h.wsPrivateManager = nil
+	}
 	h.wsMu.Unlock()
 
 	h.connected = false
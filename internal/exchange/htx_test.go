@@ -0,0 +1,284 @@
+package exchange
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	htxTestAPIKey = "test-access-key"
+	htxTestSecret = "test-secret"
+
+	htxTestNotifyPath = "/linear-swap-notification"
+)
+
+// htxFixtures - ответы REST API HTX, записанные в testdata/htx
+var htxFixtures = map[string]string{
+	"GET /api/v1/timestamp":                      "timestamp.json",
+	"POST /linear-swap-api/v1/swap_account_info": "swap_account_info.json",
+	"GET /linear-swap-api/v1/swap_contract_info": "swap_contract_info.json",
+}
+
+// htxTestServer - локальный HTX: REST ответы из fixtures и приватный WebSocket уведомлений
+type htxTestServer struct {
+	t      *testing.T
+	server *httptest.Server
+
+	mu    sync.Mutex
+	subs  []string // топики подписок приватного потока
+	pongs []string // ts ответов на ping
+
+	// userGate запускает отправку событий приватного потока
+	userGate chan struct{}
+}
+
+func newHTXTestServer(t *testing.T) *htxTestServer {
+	s := &htxTestServer{t: t, userGate: make(chan struct{})}
+	s.server = httptest.NewServer(http.HandlerFunc(s.handle))
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+// newHTXForTest создаёт адаптер, направленный на тестовый сервер
+func newHTXForTest(s *htxTestServer, secret string) *HTX {
+	wsURL := "ws" + strings.TrimPrefix(s.server.URL, "http")
+	h := NewHTX()
+	h.SetEndpoints(Endpoints{REST: s.server.URL, WSPublic: wsURL + "/linear-swap-ws", WSPrivate: wsURL + htxTestNotifyPath})
+	h.apiKey = htxTestAPIKey
+	h.secretKey = secret
+	return h
+}
+
+// loadHTXFixture читает fixture; вызывается из горутин сервера, поэтому без Fatal
+func loadHTXFixture(t *testing.T, name string) []byte {
+	data, err := os.ReadFile(filepath.Join("testdata", "htx", name))
+	if err != nil {
+		t.Errorf("read fixture %s: %v", name, err)
+	}
+	return data
+}
+
+func (s *htxTestServer) handle(w http.ResponseWriter, r *http.Request) {
+	if r.URL.Path == htxTestNotifyPath {
+		s.handleNotifyWS(w, r)
+		return
+	}
+
+	fixture, ok := htxFixtures[r.Method+" "+r.URL.Path]
+	if !ok {
+		s.t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+		http.NotFound(w, r)
+		return
+	}
+	w.Write(loadHTXFixture(s.t, fixture))
+}
+
+// handleNotifyWS проверяет подпись авторизации, принимает подписки и по userGate
+// отправляет события из ws_private_events.json в сжатом виде, как HTX
+func (s *htxTestServer) handleNotifyWS(w http.ResponseWriter, r *http.Request) {
+	upgrader := websocket.Upgrader{}
+	conn, err := upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	var writeMu sync.Mutex
+	send := func(message []byte) {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		zw.Write(message)
+		zw.Close()
+
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		conn.WriteMessage(websocket.BinaryMessage, buf.Bytes())
+	}
+
+	var auth map[string]string
+	if err := conn.ReadJSON(&auth); err != nil || auth["op"] != "auth" {
+		s.t.Errorf("expected auth message first, got %v (%v)", auth, err)
+		return
+	}
+	if !s.validAuth(r.Host, auth) {
+		send(loadHTXFixture(s.t, "ws_auth_error.json"))
+		return
+	}
+	send(loadHTXFixture(s.t, "ws_auth.json"))
+
+	go func() {
+		select {
+		case <-s.userGate:
+		case <-time.After(5 * time.Second):
+			return
+		}
+		var events []json.RawMessage
+		if err := json.Unmarshal(loadHTXFixture(s.t, "ws_private_events.json"), &events); err != nil {
+			s.t.Errorf("parse fixture ws_private_events.json: %v", err)
+		}
+		for _, event := range events {
+			send(event)
+		}
+	}()
+
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+
+		var op struct {
+			Op    string          `json:"op"`
+			Topic string          `json:"topic"`
+			Ts    json.RawMessage `json:"ts"`
+		}
+		if err := json.Unmarshal(message, &op); err != nil {
+			continue
+		}
+
+		s.mu.Lock()
+		switch op.Op {
+		case "sub":
+			s.subs = append(s.subs, op.Topic)
+		case "pong":
+			s.pongs = append(s.pongs, strings.Trim(string(op.Ts), `"`))
+		}
+		s.mu.Unlock()
+	}
+}
+
+// validAuth проверяет ключ и подпись: HMAC SHA256 строки GET, хоста, пути и параметров
+func (s *htxTestServer) validAuth(host string, auth map[string]string) bool {
+	params := url.Values{}
+	for _, key := range []string{"AccessKeyId", "SignatureMethod", "SignatureVersion", "Timestamp"} {
+		params.Set(key, auth[key])
+	}
+
+	mac := hmac.New(sha256.New, []byte(htxTestSecret))
+	mac.Write([]byte("GET\n" + host + "\n" + htxTestNotifyPath + "\n" + params.Encode()))
+	signature := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return auth["AccessKeyId"] == htxTestAPIKey && auth["Signature"] == signature
+}
+
+// TestHTX_PrivateStream проверяет авторизацию, подписки и события позиций и ордеров
+func TestHTX_PrivateStream(t *testing.T) {
+	srv := newHTXTestServer(t)
+	h := newHTXForTest(srv, htxTestSecret)
+	defer h.Close()
+
+	// Размеры в уведомлениях - в контрактах: спецификации нужны до событий
+	if _, err := h.GetLimits(context.Background(), "BTCUSDT"); err != nil {
+		t.Fatalf("GetLimits: %v", err)
+	}
+
+	positions := make(chan *Position, 8)
+	orders := make(chan *Order, 8)
+	if err := h.SubscribePositions(func(p *Position) { positions <- p }); err != nil {
+		t.Fatalf("SubscribePositions: %v", err)
+	}
+	if err := h.SubscribeOrders(func(o *Order) { orders <- o }); err != nil {
+		t.Fatalf("SubscribeOrders: %v", err)
+	}
+	close(srv.userGate)
+
+	nextOrder := func() *Order {
+		select {
+		case order := <-orders:
+			return order
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for order update")
+		}
+		return nil
+	}
+	nextPosition := func() *Position {
+		select {
+		case position := <-positions:
+			return position
+		case <-time.After(5 * time.Second):
+			t.Fatal("timeout waiting for position update")
+		}
+		return nil
+	}
+
+	// 500 контрактов по 0.001 BTC
+	position := nextPosition()
+	if position.Symbol != "BTCUSDT" || position.Side != SideLong || !almostEqual(position.Size, 0.5) ||
+		position.EntryPrice != 63880.5 || position.Leverage != 10 || position.Liquidation {
+		t.Fatalf("unexpected position: %+v", position)
+	}
+
+	// client_order_id - число, полученное HTX вместо ID клиента
+	order := nextOrder()
+	if order.ClientOrderID != VenueClientOrderID(h, "a7t19a1e5f2e00p0n0l") {
+		t.Fatalf("expected client order id in HTX form, got %q", order.ClientOrderID)
+	}
+	if order.ID != "1183052713532858368" || order.Status != OrderStatusFilled || order.Side != SideBuy ||
+		!almostEqual(order.FilledQty, 0.5) || order.AvgFillPrice != 63880.5 || !almostEqual(order.Fee, 12.77) {
+		t.Fatalf("unexpected order: %+v", order)
+	}
+
+	// Ордер принудительной ликвидации (order_type 3) sell закрывает лонг
+	order = nextOrder()
+	if order.Side != SideSell || order.Status != OrderStatusFilled || !almostEqual(order.FilledQty, 0.5) || order.ClientOrderID != "" {
+		t.Fatalf("unexpected liquidation order: %+v", order)
+	}
+	position = nextPosition()
+	if !position.Liquidation || position.Side != SideLong || position.MarkPrice != 57512.4 {
+		t.Fatalf("expected liquidation of long position from order, got %+v", position)
+	}
+	position = nextPosition()
+	if !position.Liquidation || position.Size != 0 {
+		t.Fatalf("expected order.liquidation position event, got %+v", position)
+	}
+
+	// pong отправлен до обработки следующих событий, но сервер мог ещё не прочитать его
+	var subs, pongs []string
+	for deadline := time.Now().Add(time.Second); ; time.Sleep(10 * time.Millisecond) {
+		srv.mu.Lock()
+		subs = append([]string(nil), srv.subs...)
+		pongs = append([]string(nil), srv.pongs...)
+		srv.mu.Unlock()
+		if len(pongs) > 0 || time.Now().After(deadline) {
+			break
+		}
+	}
+
+	want := []string{"positions.*", "positions_cross.*", "orders.*", "orders_cross.*"}
+	if strings.Join(subs, ",") != strings.Join(want, ",") {
+		t.Fatalf("expected subscriptions %v, got %v", want, subs)
+	}
+	if len(pongs) != 1 || pongs[0] != "1760601600300" {
+		t.Fatalf("expected pong with ping ts, got %v", pongs)
+	}
+}
+
+// TestHTX_PrivateStreamAuthFailure проверяет, что отказ авторизации - ошибка подписки с кодом биржи
+func TestHTX_PrivateStreamAuthFailure(t *testing.T) {
+	srv := newHTXTestServer(t)
+	h := newHTXForTest(srv, "wrong-secret")
+	defer h.Close()
+
+	err := h.SubscribeOrders(func(*Order) {})
+	var exchErr *ExchangeError
+	if !errors.As(err, &exchErr) || exchErr.Code != "2003" || exchErr.Message != "auth.fail" {
+		t.Fatalf("expected auth.fail exchange error, got %v", err)
+	}
+}
@@ -0,0 +1,279 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Ошибки реестра инструментов
+var (
+	ErrInstrumentNotFound   = errors.New("instrument not found")
+	ErrInstrumentNotTrading = errors.New("instrument not trading")
+	ErrQtyBelowLot          = errors.New("qty below one lot")
+)
+
+// Статусы инструмента
+const (
+	InstrumentStatusTrading   = "trading"   // торги идут
+	InstrumentStatusSuspended = "suspended" // торги приостановлены (техработы, pre-open)
+	InstrumentStatusDelisted  = "delisted"  // делистинг или закрытие только позиций
+)
+
+// DefaultInstrumentTTL - период перезагрузки спецификаций контрактов
+const DefaultInstrumentTTL = time.Hour
+
+// instrumentReloadInterval - не чаще этого перезагружаем реестр ради неизвестного символа
+const instrumentReloadInterval = time.Minute
+
+// sizeEpsilon защищает округление вниз от ошибки float (0.03/0.01 = 2.9999999999999996)
+const sizeEpsilon = 1e-9
+
+// Instrument - спецификация бессрочного контракта биржи
+//
+// Размеры (LotSize, MinSize, MaxSize) заданы в единицах биржи: контрактах для OKX,
+// Gate и HTX, монетах для Bybit, Bitget и BingX. ContractValue переводит их в монеты.
+// Бот везде оперирует количеством в монетах, перевод - только через методы Instrument.
+type Instrument struct {
+	Symbol         string    `json:"symbol"`          // унифицированный символ (BTCUSDT)
+	VenueSymbol    string    `json:"venue_symbol"`    // символ биржи (BTC-USDT-SWAP)
+	ContractValue  float64   `json:"contract_value"`  // монет в единице размера биржи (1 - размер в монетах)
+	LotSize        float64   `json:"lot_size"`        // шаг размера в единицах биржи
+	MinSize        float64   `json:"min_size"`        // минимальный размер ордера в единицах биржи
+	MaxSize        float64   `json:"max_size"`        // максимальный размер ордера (0 - без ограничения)
+	TickSize       float64   `json:"tick_size"`       // шаг цены
+	MinNotional    float64   `json:"min_notional"`    // минимальная сумма сделки в USDT
+	MaxLeverage    int       `json:"max_leverage"`    // максимальное плечо
+	SettleCurrency string    `json:"settle_currency"` // валюта расчётов (USDT)
+	Status         string    `json:"status"`          // InstrumentStatus*
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// contractValue возвращает размер контракта (1 для бирж с количеством в монетах)
+func (i *Instrument) contractValue() float64 {
+	if i.ContractValue > 0 {
+		return i.ContractValue
+	}
+	return 1
+}
+
+// Trading сообщает, открыты ли торги по инструменту
+func (i *Instrument) Trading() bool {
+	return i.Status == "" || i.Status == InstrumentStatusTrading
+}
+
+// ToVenueSize переводит количество в монетах в размер биржи, округляя вниз до лота
+func (i *Instrument) ToVenueSize(qty float64) float64 {
+	size := qty / i.contractValue()
+	if i.LotSize > 0 {
+		size = math.Floor(size/i.LotSize+sizeEpsilon) * i.LotSize
+	}
+	return roundSize(size)
+}
+
+// FromVenueSize переводит размер биржи в количество в монетах
+func (i *Instrument) FromVenueSize(size float64) float64 {
+	return roundSize(size * i.contractValue())
+}
+
+// RoundQty округляет количество в монетах вниз до целого лота биржи
+func (i *Instrument) RoundQty(qty float64) float64 {
+	return i.FromVenueSize(i.ToVenueSize(qty))
+}
+
+// FormatSize форматирует размер биржи с точностью лота
+func (i *Instrument) FormatSize(size float64) string {
+	return strconv.FormatFloat(size, 'f', stepDecimals(i.LotSize), 64)
+}
+
+// Limits возвращает торговые лимиты в монетах
+func (i *Instrument) Limits() *Limits {
+	return &Limits{
+		Symbol:      i.Symbol,
+		MinOrderQty: i.FromVenueSize(i.MinSize),
+		MaxOrderQty: i.FromVenueSize(i.MaxSize),
+		QtyStep:     i.FromVenueSize(i.LotSize),
+		MinNotional: i.MinNotional,
+		PriceStep:   i.TickSize,
+		MaxLeverage: i.MaxLeverage,
+	}
+}
+
+// ScaleOrderBook переводит объёмы стакана из единиц биржи в монеты
+func (i *Instrument) ScaleOrderBook(book *OrderBook) {
+	if book == nil || i.contractValue() == 1 {
+		return
+	}
+	for j := range book.Bids {
+		book.Bids[j].Volume = i.FromVenueSize(book.Bids[j].Volume)
+	}
+	for j := range book.Asks {
+		book.Asks[j].Volume = i.FromVenueSize(book.Asks[j].Volume)
+	}
+}
+
+// roundSize убирает хвосты float после умножения (7 * 0.1 = 0.7000000000000001)
+func roundSize(v float64) float64 {
+	return math.Round(v*1e10) / 1e10
+}
+
+// stepDecimals возвращает число знаков после запятой у шага (0.001 -> 3, 1 -> 0)
+func stepDecimals(step float64) int {
+	if step <= 0 {
+		return -1
+	}
+	s := strconv.FormatFloat(step, 'f', -1, 64)
+	if idx := strings.IndexByte(s, '.'); idx >= 0 {
+		return len(s) - idx - 1
+	}
+	return 0
+}
+
+// precisionStep переводит точность в знаках (3) в шаг (0.001)
+func precisionStep(decimals int) float64 {
+	return math.Pow10(-decimals)
+}
+
+// ============================================================
+// InstrumentRegistry - кэш спецификаций контрактов биржи
+// ============================================================
+
+// InstrumentLoader загружает спецификации всех контрактов биржи одним запросом
+type InstrumentLoader func(ctx context.Context) ([]*Instrument, error)
+
+// InstrumentRegistry хранит спецификации контрактов одной биржи
+//
+// Все инструменты загружаются одним запросом и перезагружаются раз в DefaultInstrumentTTL.
+// Если биржа недоступна, используется последняя загруженная спецификация.
+type InstrumentRegistry struct {
+	exchange string
+	loader   InstrumentLoader
+	ttl      time.Duration
+
+	mu          sync.RWMutex
+	instruments map[string]*Instrument
+	loadedAt    time.Time
+
+	loadMu sync.Mutex // один запрос загрузки за раз
+}
+
+// NewInstrumentRegistry создаёт реестр инструментов биржи
+func NewInstrumentRegistry(exchange string, loader InstrumentLoader) *InstrumentRegistry {
+	return &InstrumentRegistry{
+		exchange:    exchange,
+		loader:      loader,
+		ttl:         DefaultInstrumentTTL,
+		instruments: make(map[string]*Instrument),
+	}
+}
+
+// Get возвращает спецификацию символа, при необходимости загружая реестр
+func (r *InstrumentRegistry) Get(ctx context.Context, symbol string) (*Instrument, error) {
+	r.mu.RLock()
+	inst := r.instruments[symbol]
+	loadedAt := r.loadedAt
+	r.mu.RUnlock()
+
+	age := time.Since(loadedAt)
+	if inst != nil && age < r.ttl {
+		return inst, nil
+	}
+
+	// Новый листинг появляется в реестре только после перезагрузки
+	if inst != nil || loadedAt.IsZero() || age >= instrumentReloadInterval {
+		if err := r.refresh(ctx, time.Now()); err != nil {
+			if inst != nil {
+				return inst, nil
+			}
+			return nil, err
+		}
+	}
+
+	if inst = r.Lookup(symbol); inst == nil {
+		return nil, fmt.Errorf("%w: %s on %s", ErrInstrumentNotFound, symbol, r.exchange)
+	}
+	return inst, nil
+}
+
+// Lookup возвращает загруженную спецификацию без запроса к бирже (nil - не загружена)
+func (r *InstrumentRegistry) Lookup(symbol string) *Instrument {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.instruments[symbol]
+}
+
+// Refresh перезагружает спецификации всех контрактов
+func (r *InstrumentRegistry) Refresh(ctx context.Context) error {
+	return r.refresh(ctx, time.Now())
+}
+
+// refresh загружает реестр, если он не был загружен после requested
+// Одновременные запросы на холодном кэше дожидаются одной загрузки
+func (r *InstrumentRegistry) refresh(ctx context.Context, requested time.Time) error {
+	r.loadMu.Lock()
+	defer r.loadMu.Unlock()
+
+	r.mu.RLock()
+	fresh := r.loadedAt.After(requested)
+	r.mu.RUnlock()
+	if fresh {
+		return nil
+	}
+
+	list, err := r.loader(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load %s instruments: %w", r.exchange, err)
+	}
+
+	now := time.Now()
+	instruments := make(map[string]*Instrument, len(list))
+	for _, inst := range list {
+		if inst == nil || inst.Symbol == "" {
+			continue
+		}
+		inst.UpdatedAt = now
+		instruments[inst.Symbol] = inst
+	}
+
+	r.mu.Lock()
+	r.instruments = instruments
+	r.loadedAt = now
+	r.mu.Unlock()
+	return nil
+}
+
+// OrderSize переводит количество ордера в монетах в размер биржи
+// Ошибка, если торги закрыты или количество меньше одного лота
+func (r *InstrumentRegistry) OrderSize(ctx context.Context, symbol string, qty float64) (*Instrument, float64, error) {
+	inst, err := r.Get(ctx, symbol)
+	if err != nil {
+		return nil, 0, err
+	}
+	if !inst.Trading() {
+		return nil, 0, fmt.Errorf("%w: %s is %s on %s", ErrInstrumentNotTrading, symbol, inst.Status, r.exchange)
+	}
+
+	size := inst.ToVenueSize(qty)
+	if size <= 0 {
+		return nil, 0, fmt.Errorf("%w: %.8f %s, lot %v on %s",
+			ErrQtyBelowLot, qty, symbol, inst.FromVenueSize(inst.LotSize), r.exchange)
+	}
+	return inst, size, nil
+}
+
+// CoinQty переводит размер биржи в монеты по загруженной спецификации
+// Используется в WebSocket-обработчиках, где нельзя сделать запрос к бирже
+func (r *InstrumentRegistry) CoinQty(symbol string, size float64) float64 {
+	inst := r.Lookup(symbol)
+	if inst == nil {
+		log.Printf("[%s] instrument %s not loaded, size %v left in venue units", r.exchange, symbol, size)
+		return size
+	}
+	return inst.FromVenueSize(size)
+}
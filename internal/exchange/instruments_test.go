@@ -0,0 +1,107 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestInstrument_ContractConversion проверяет перевод монет в контракты и обратно
+func TestInstrument_ContractConversion(t *testing.T) {
+	// OKX BTC-USDT-SWAP: контракт 0.01 BTC, лот 1 контракт
+	okx := &Instrument{Symbol: "BTCUSDT", ContractValue: 0.01, LotSize: 1, MinSize: 1, MaxSize: 2000}
+
+	if size := okx.ToVenueSize(0.0355); size != 3 {
+		t.Fatalf("expected 3 contracts for 0.0355 BTC, got %v", size)
+	}
+	// 0.03 / 0.01 в float - 2.9999999999999996, округление не должно терять контракт
+	if size := okx.ToVenueSize(0.03); size != 3 {
+		t.Fatalf("expected 3 contracts for 0.03 BTC, got %v", size)
+	}
+	if qty := okx.FromVenueSize(7); qty != 0.07 {
+		t.Fatalf("expected 0.07 BTC for 7 contracts, got %v", qty)
+	}
+	if qty := okx.RoundQty(0.0199); qty != 0.01 {
+		t.Fatalf("expected qty rounded down to 0.01, got %v", qty)
+	}
+	if s := okx.FormatSize(3); s != "3" {
+		t.Fatalf("expected size formatted as 3, got %q", s)
+	}
+
+	limits := okx.Limits()
+	if limits.MinOrderQty != 0.01 || limits.MaxOrderQty != 20 || limits.QtyStep != 0.01 {
+		t.Fatalf("expected limits in coins, got %+v", limits)
+	}
+
+	// Bybit: размер в монетах, лот 0.001
+	bybit := &Instrument{Symbol: "BTCUSDT", ContractValue: 1, LotSize: 0.001}
+	if size := bybit.ToVenueSize(0.0355); size != 0.035 {
+		t.Fatalf("expected 0.035 BTC, got %v", size)
+	}
+	if s := bybit.FormatSize(0.035); s != "0.035" {
+		t.Fatalf("expected size formatted as 0.035, got %q", s)
+	}
+
+	// Объёмы стакана в контрактах переводятся в монеты
+	book := &OrderBook{Bids: []PriceLevel{{Price: 100, Volume: 5}}, Asks: []PriceLevel{{Price: 101, Volume: 12}}}
+	okx.ScaleOrderBook(book)
+	if book.Bids[0].Volume != 0.05 || book.Asks[0].Volume != 0.12 {
+		t.Fatalf("expected book volumes in BTC, got bids %v asks %v", book.Bids, book.Asks)
+	}
+}
+
+// TestInstrumentRegistry_LoadAndOrderSize проверяет кэш спецификаций и размер ордера
+func TestInstrumentRegistry_LoadAndOrderSize(t *testing.T) {
+	loads := 0
+	failLoad := false
+	registry := NewInstrumentRegistry("gate", func(ctx context.Context) ([]*Instrument, error) {
+		loads++
+		if failLoad {
+			return nil, errors.New("gate unavailable")
+		}
+		return []*Instrument{
+			{Symbol: "BTCUSDT", ContractValue: 0.0001, LotSize: 1, MinSize: 1, Status: InstrumentStatusTrading},
+			{Symbol: "LUNAUSDT", ContractValue: 1, LotSize: 1, MinSize: 1, Status: InstrumentStatusDelisted},
+		}, nil
+	})
+	ctx := context.Background()
+
+	inst, size, err := registry.OrderSize(ctx, "BTCUSDT", 0.01234)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if size != 123 || inst.FromVenueSize(size) != 0.0123 {
+		t.Fatalf("expected 123 contracts (0.0123 BTC), got %v", size)
+	}
+
+	if _, _, err := registry.OrderSize(ctx, "BTCUSDT", 0.00005); !errors.Is(err, ErrQtyBelowLot) {
+		t.Fatalf("expected ErrQtyBelowLot, got %v", err)
+	}
+	if _, _, err := registry.OrderSize(ctx, "LUNAUSDT", 10); !errors.Is(err, ErrInstrumentNotTrading) {
+		t.Fatalf("expected ErrInstrumentNotTrading, got %v", err)
+	}
+
+	// Неизвестный символ не перезагружает реестр чаще instrumentReloadInterval
+	if _, err := registry.Get(ctx, "NEWUSDT"); !errors.Is(err, ErrInstrumentNotFound) {
+		t.Fatalf("expected ErrInstrumentNotFound, got %v", err)
+	}
+	if loads != 1 {
+		t.Fatalf("expected single load, got %d", loads)
+	}
+
+	// Устаревшая спецификация лучше отказа, если биржа недоступна
+	registry.ttl = 0
+	failLoad = true
+	inst, err = registry.Get(ctx, "BTCUSDT")
+	if err != nil || inst.ContractValue != 0.0001 {
+		t.Fatalf("expected stale instrument on load failure, got %v, %v", inst, err)
+	}
+	if loads != 2 {
+		t.Fatalf("expected reload after ttl, got %d loads", loads)
+	}
+
+	if qty := registry.CoinQty("BTCUSDT", 250); math.Abs(qty-0.025) > 1e-12 {
+		t.Fatalf("expected 0.025 BTC for 250 contracts, got %v", qty)
+	}
+}
@@ -2,6 +2,11 @@ package exchange
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -23,26 +28,73 @@ type Exchange interface {
 	GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error)
 
 	// PlaceMarketOrder размещает рыночный ордер
-	PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64) (*Order, error)
+	// clientOrderID - идентификатор ордера на стороне клиента для идемпотентных повторов
+	// (буквы и цифры, до 28 символов; "" - без идентификатора)
+	PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*Order, error)
+
+	// PlaceLimitOrder размещает лимитный ордер с ценой price
+	// tif - TimeInForceGTC / IOC / FOK / PostOnly ("" = GTC)
+	PlaceLimitOrder(ctx context.Context, symbol, side string, qty, price float64, tif string) (*Order, error)
+
+	// CancelOrder отменяет активный ордер
+	CancelOrder(ctx context.Context, symbol, orderID string) error
+
+	// GetOrder получает текущее состояние ордера
+	GetOrder(ctx context.Context, symbol, orderID string) (*Order, error)
+
+	// GetOrderByClientID получает ордер по client order ID, переданному при размещении
+	// Возвращает ошибку ErrOrderNotFound, если биржа не принимала ордер с таким ID
+	GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*Order, error)
+
+	// GetOpenOrders получает активные ордера по символу ("" = все символы)
+	GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error)
 
 	// GetOpenPositions получает список открытых позиций
 	GetOpenPositions(ctx context.Context) ([]*Position, error)
 
-	// ClosePosition закрывает позицию
+	// ClosePosition закрывает позицию стороны side (в режиме hedge - только её, см. PositionCloser)
 	ClosePosition(ctx context.Context, symbol, side string, qty float64) error
 
 	// SubscribeTicker подписывается на обновления цен через WebSocket
 	SubscribeTicker(symbol string, callback func(*Ticker)) error
 
+	// SubscribeOrderBook подписывается на стакан символа через WebSocket
+	// Адаптер ведёт локальный L2-стакан (снимок + инкрементальные обновления),
+	// проверяет последовательность и контрольные суммы и пересинхронизируется при разрыве.
+	// callback получает топ depth уровней после каждого применённого обновления
+	SubscribeOrderBook(symbol string, depth int, callback func(*OrderBook)) error
+
 	// SubscribePositions подписывается на обновления позиций (для обнаружения ликвидаций)
 	SubscribePositions(callback func(*Position)) error
 
+	// SubscribeOrders подписывается на обновления ордеров через приватный WebSocket
+	// Сообщает частичные и полные исполнения, среднюю цену, комиссию и отклонения
+	SubscribeOrders(callback func(*Order)) error
+
 	// GetTradingFee получает комиссию тейкера для символа
 	GetTradingFee(ctx context.Context, symbol string) (float64, error)
 
-	// GetLimits получает торговые лимиты биржи для символа
+	// GetTradingFees получает комиссии мейкера и тейкера аккаунта для символа
+	// с учётом VIP-уровня; отрицательная комиссия мейкера - ребейт
+	GetTradingFees(ctx context.Context, symbol string) (*TradingFees, error)
+
+	// GetLimits получает торговые лимиты биржи для символа (количества в монетах)
 	GetLimits(ctx context.Context, symbol string) (*Limits, error)
 
+	// GetInstrument получает спецификацию контракта: размер контракта, шаги, статус листинга
+	// Количества во всех методах - в монетах, перевод в контракты биржи делает адаптер
+	GetInstrument(ctx context.Context, symbol string) (*Instrument, error)
+
+	// GetFundingRate получает текущую и прогнозную ставку фандинга для символа
+	GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error)
+
+	// SetLeverage устанавливает плечо символа для обеих сторон позиции
+	SetLeverage(ctx context.Context, symbol string, leverage int) error
+
+	// SetMarginMode устанавливает режим маржи символа (MarginModeIsolated / MarginModeCross)
+	// Биржи не меняют режим при открытой позиции - вызывать до входа
+	SetMarginMode(ctx context.Context, symbol, mode string) error
+
 	// Close закрывает соединения с биржей
 	Close() error
 }
@@ -73,13 +125,17 @@ type PriceLevel struct {
 // Order представляет ордер
 type Order struct {
 	ID            string    `json:"id"`
+	ClientOrderID string    `json:"client_order_id,omitempty"` // идентификатор клиента (см. PlaceMarketOrder)
 	Symbol        string    `json:"symbol"`
 	Side          string    `json:"side"`          // "buy" или "sell"
 	Type          string    `json:"type"`          // "market" или "limit"
+	Price         float64   `json:"price,omitempty"`         // цена лимитного ордера
+	TimeInForce   string    `json:"time_in_force,omitempty"` // для лимитного ордера
 	Quantity      float64   `json:"quantity"`
 	FilledQty     float64   `json:"filled_qty"`
 	AvgFillPrice  float64   `json:"avg_fill_price"`
-	Status        string    `json:"status"`        // "filled", "partial", "cancelled"
+	Fee           float64   `json:"fee,omitempty"` // уплаченная комиссия в USDT (< 0 - ребейт)
+	Status        string    `json:"status"`        // "new", "filled", "partial", "cancelled", "rejected"
 	CreatedAt     time.Time `json:"created_at"`
 	UpdatedAt     time.Time `json:"updated_at"`
 }
@@ -108,6 +164,37 @@ type Limits struct {
 	MaxLeverage    int     `json:"max_leverage"`     // максимальное плечо
 }
 
+// FundingRate содержит ставку фандинга бессрочного контракта
+//
+// Положительная ставка: лонги платят шортам, отрицательная - наоборот.
+// Ставки в долях (0.0001 = 0.01%) за один период фандинга.
+type FundingRate struct {
+	Symbol          string        `json:"symbol"`
+	Rate            float64       `json:"rate"`              // ставка ближайшего начисления
+	PredictedRate   float64       `json:"predicted_rate"`    // прогноз следующего периода (0 = нет прогноза)
+	NextFundingTime time.Time     `json:"next_funding_time"` // время ближайшего начисления
+	Interval        time.Duration `json:"interval"`          // период фандинга
+	Timestamp       time.Time     `json:"timestamp"`
+}
+
+// TradingFees - комиссии аккаунта на бирже
+//
+// Ставки в долях (0.0005 = 0.05%) от объёма сделки.
+// Отрицательная ставка - ребейт: биржа доплачивает за сделку.
+type TradingFees struct {
+	Symbol    string    `json:"symbol"`
+	Maker     float64   `json:"maker"`
+	Taker     float64   `json:"taker"`
+	Tier      string    `json:"tier,omitempty"` // VIP-уровень аккаунта, если биржа его сообщает
+	Timestamp time.Time `json:"timestamp"`
+}
+
+// DefaultFundingInterval - стандартный период фандинга, если биржа его не сообщает
+const DefaultFundingInterval = 8 * time.Hour
+
+// ErrOrderNotFound - биржа не знает ордер с запрошенным идентификатором
+var ErrOrderNotFound = errors.New("order not found")
+
 // ExchangeError представляет ошибку от биржи
 type ExchangeError struct {
 	Exchange string
@@ -125,6 +212,56 @@ func (e *ExchangeError) Unwrap() error {
 	return e.Original
 }
 
+// maxHTTPErrorBody - сколько байт тела ответа попадает в сообщение httpStatusError
+const maxHTTPErrorBody = 256
+
+// httpStatusError оборачивает ответ без кода биржи (HTML балансировщика, пустое тело,
+// обрыв JSON) в ExchangeError, кодом ошибки становится HTTP статус
+func httpStatusError(exchange string, status int, body []byte, original error) *ExchangeError {
+	message := strings.TrimSpace(string(body))
+	if len(message) > maxHTTPErrorBody {
+		message = message[:maxHTTPErrorBody] + "..."
+	}
+	if message == "" {
+		message = http.StatusText(status)
+	}
+
+	return &ExchangeError{
+		Exchange: exchange,
+		Code:     strconv.Itoa(status),
+		Message:  fmt.Sprintf("HTTP %d: %s", status, message),
+		Original: original,
+	}
+}
+
+// Endpoints - адреса REST и WebSocket API биржи
+// По умолчанию адаптер использует продакшн адреса, тесты подменяют их локальными серверами
+type Endpoints struct {
+	REST      string // базовый URL REST API (с префиксом версии, если он есть у биржи)
+	WSPublic  string // публичный WebSocket
+	WSPrivate string // приватный WebSocket (для listenKey бирж - база адреса потока)
+}
+
+// EndpointSetter реализуют адаптеры, которым можно подменить адреса API
+// SetEndpoints вызывается до Connect и подписок
+type EndpointSetter interface {
+	SetEndpoints(endpoints Endpoints)
+}
+
+// ClientIDMapper реализуют адаптеры, передающие бирже не сам client order ID, а производный
+// от него (HTX принимает только число). Ордера из ответов и потоков содержат ID в форме биржи
+type ClientIDMapper interface {
+	VenueClientOrderID(clientOrderID string) string
+}
+
+// VenueClientOrderID возвращает client order ID в форме, в которой биржа возвращает его в ордерах
+func VenueClientOrderID(exch Exchange, clientOrderID string) string {
+	if mapper, ok := exch.(ClientIDMapper); ok && clientOrderID != "" {
+		return mapper.VenueClientOrderID(clientOrderID)
+	}
+	return clientOrderID
+}
+
 // Side constants for orders (используются при размещении ордеров)
 const (
 	SideBuy  = "buy"  // покупка (открытие long или закрытие short)
@@ -138,9 +275,102 @@ const (
 )
 
 // Order status constants
+//
+// Лимитный ордер активен в статусах new и partial. Ордер, отменённый после
+// частичного исполнения, получает статус cancelled с FilledQty > 0.
 const (
+	OrderStatusNew       = "new"
 	OrderStatusFilled    = "filled"
 	OrderStatusPartial   = "partial"
 	OrderStatusCancelled = "cancelled"
 	OrderStatusRejected  = "rejected"
 )
+
+// Order type constants
+const (
+	OrderTypeMarket = "market"
+	OrderTypeLimit  = "limit"
+)
+
+// Time in force constants для лимитных ордеров
+const (
+	TimeInForceGTC      = "gtc"       // действует до отмены
+	TimeInForceIOC      = "ioc"       // исполнить доступный объём, остаток отменить
+	TimeInForceFOK      = "fok"       // исполнить целиком или отменить
+	TimeInForcePostOnly = "post_only" // только мейкер: ордер, который исполнился бы сразу, отменяется
+)
+
+// Margin mode constants
+const (
+	MarginModeCross    = "cross"    // общая маржа аккаунта на все позиции
+	MarginModeIsolated = "isolated" // маржа выделяется каждой позиции отдельно
+)
+
+// validateLeverage проверяет плечо перед отправкой на биржу
+func validateLeverage(leverage int) error {
+	if leverage < 1 {
+		return fmt.Errorf("invalid leverage %d, must be at least 1", leverage)
+	}
+	return nil
+}
+
+// validateMarginMode проверяет режим маржи перед отправкой на биржу
+func validateMarginMode(mode string) error {
+	if mode != MarginModeCross && mode != MarginModeIsolated {
+		return fmt.Errorf("unsupported margin mode %q", mode)
+	}
+	return nil
+}
+
+// isExchangeErrorCode возвращает true если err - ошибка биржи с одним из кодов
+// Используется для ответов "значение не изменилось" при повторной установке плеча/режима
+func isExchangeErrorCode(err error, codes ...string) bool {
+	var exchErr *ExchangeError
+	if !errors.As(err, &exchErr) {
+		return false
+	}
+	for _, code := range codes {
+		if exchErr.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// orderNotFound помечает ошибку биржи с одним из кодов "ордер не найден" как ErrOrderNotFound
+func orderNotFound(err error, codes ...string) error {
+	if isExchangeErrorCode(err, codes...) {
+		return fmt.Errorf("%w: %w", ErrOrderNotFound, err)
+	}
+	return err
+}
+
+// normalizeTimeInForce проверяет time in force; пустое значение означает GTC
+func normalizeTimeInForce(tif string) (string, error) {
+	switch tif {
+	case "":
+		return TimeInForceGTC, nil
+	case TimeInForceGTC, TimeInForceIOC, TimeInForceFOK, TimeInForcePostOnly:
+		return tif, nil
+	default:
+		return "", fmt.Errorf("unsupported time in force %q", tif)
+	}
+}
+
+// newLimitOrder возвращает только что принятый лимитный ордер
+// Используется адаптерами, когда состояние ордера после размещения получить не удалось
+func newLimitOrder(id, symbol, side string, qty, price float64, tif string) *Order {
+	now := time.Now()
+	return &Order{
+		ID:          id,
+		Symbol:      symbol,
+		Side:        side,
+		Type:        OrderTypeLimit,
+		Price:       price,
+		TimeInForce: tif,
+		Quantity:    qty,
+		Status:      OrderStatusNew,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
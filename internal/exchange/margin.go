@@ -0,0 +1,68 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ============================================================
+// Чтение плеча и режима маржи
+// ============================================================
+//
+// Биржа может принять SetLeverage / SetMarginMode и оставить другое значение:
+// плечо урезается лимитом риска, режим не меняется при открытых ордерах,
+// у Bybit режим общий для аккаунта. Поэтому бот после установки читает
+// настройки обратно и не входит в позицию при расхождении.
+//
+// OKX и HTX не хранят режим маржи символа: режим задаётся ордером (tdMode)
+// или эндпоинтом торговли. Биржа сообщает режим только открытой позиции,
+// без позиции чтение возвращает плечо и ErrMarginModeNotReported.
+
+// ErrMarginModeNotReported - биржа не сообщает режим маржи символа без открытой позиции
+// Плечо, возвращённое вместе с ошибкой, прочитано с биржи
+var ErrMarginModeNotReported = errors.New("margin mode is not reported without an open position")
+
+// MarginReader реализуют адаптеры, умеющие читать плечо и режим маржи символа с биржи
+type MarginReader interface {
+	// GetMarginSettings возвращает плечо и режим маржи символа (MarginModeCross / MarginModeIsolated)
+	// Биржи без режима символа (OKX, HTX) берут режим из открытой позиции, а без позиции
+	// возвращают плечо с ErrMarginModeNotReported
+	GetMarginSettings(ctx context.Context, symbol string) (leverage int, mode string, err error)
+}
+
+// positionMargin - плечо и режим маржи открытой позиции по данным биржи
+type positionMargin struct {
+	leverage int
+	mode     string
+}
+
+// commonMargin возвращает плечо и режим маржи, общие для позиций символа
+func commonMargin(symbol string, positions []positionMargin) (int, string, error) {
+	leverages := make([]int, 0, len(positions))
+	for _, p := range positions {
+		if p.mode != positions[0].mode {
+			return 0, "", fmt.Errorf("%s positions use different margin modes: %s and %s", symbol, positions[0].mode, p.mode)
+		}
+		leverages = append(leverages, p.leverage)
+	}
+	leverage, err := commonLeverage(symbol, leverages...)
+	if err != nil {
+		return 0, "", err
+	}
+	return leverage, positions[0].mode, nil
+}
+
+// commonLeverage возвращает плечо, общее для сторон позиции
+// Разное плечо сторон - ошибка: SetLeverage устанавливает его для обеих сторон
+func commonLeverage(symbol string, leverages ...int) (int, error) {
+	if len(leverages) == 0 {
+		return 0, fmt.Errorf("no leverage for %s", symbol)
+	}
+	for _, leverage := range leverages[1:] {
+		if leverage != leverages[0] {
+			return 0, fmt.Errorf("%s leverage differs between position sides: %v", symbol, leverages)
+		}
+	}
+	return leverages[0], nil
+}
@@ -0,0 +1,70 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestBybitSetMarginMode_AccountWide проверяет, что Bybit не переключает режим маржи
+// всего аккаунта из настройки символа: другой режим - ошибка без запросов изменения
+func TestBybitSetMarginMode_AccountWide(t *testing.T) {
+	exch, _, scenario := connectScenario(t, "bybit")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if err := exch.SetMarginMode(ctx, scenario.Symbol, MarginModeCross); err != nil {
+		t.Fatalf("SetMarginMode(cross) on REGULAR_MARGIN account: %v", err)
+	}
+	if err := exch.SetMarginMode(ctx, scenario.Symbol, MarginModeIsolated); err == nil {
+		t.Fatal("expected error for isolated mode on REGULAR_MARGIN account")
+	}
+}
+
+// TestGetMarginSettings_SidesDiffer проверяет, что разное плечо сторон hedge -
+// ошибка чтения, а не плечо одной из сторон
+func TestGetMarginSettings_SidesDiffer(t *testing.T) {
+	exch, server, scenario := connectScenario(t, "bybit")
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Лонг 10x, шорт 5x
+	server.fail(&conformanceRoute{Fixture: "position_list.json"})
+	defer server.fail(nil)
+
+	if _, _, err := exch.(MarginReader).GetMarginSettings(ctx, scenario.Symbol); err == nil {
+		t.Fatal("expected error for different long and short leverage")
+	}
+}
+
+// TestGetMarginSettings_ModeNotReported проверяет, что OKX и HTX без открытой позиции
+// возвращают плечо с биржи и ErrMarginModeNotReported, а не режим, запомненный адаптером
+func TestGetMarginSettings_ModeNotReported(t *testing.T) {
+	flat := map[string]conformanceRoute{
+		"okx": {Method: http.MethodGet, Path: "/api/v5/account/positions", Match: "instId=",
+			Fixture: `{"code":"0","msg":"","data":[]}`, Signed: true},
+		"htx": {Method: http.MethodPost, Path: "/linear-swap-api/v1/swap_position_info", Match: "contract_code",
+			Fixture: `{"status":"ok","data":[],"ts":1760601600412}`, Signed: true},
+	}
+
+	for name, route := range flat {
+		t.Run(name, func(t *testing.T) {
+			exch, _, scenario := connectScenario(t, name, route)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if err := exch.SetMarginMode(ctx, scenario.Symbol, MarginModeIsolated); err != nil {
+				t.Fatalf("SetMarginMode: %v", err)
+			}
+			leverage, mode, err := exch.(MarginReader).GetMarginSettings(ctx, scenario.Symbol)
+			if !errors.Is(err, ErrMarginModeNotReported) {
+				t.Fatalf("expected ErrMarginModeNotReported, got %dx %q, %v", leverage, mode, err)
+			}
+			if leverage != 10 || mode != "" {
+				t.Fatalf("expected 10x from exchange without mode, got %dx %q", leverage, mode)
+			}
+		})
+	}
+}
@@ -9,11 +9,13 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"math"
 	"net/http"
 	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gorilla/websocket"
@@ -31,6 +33,10 @@ type OKX struct {
 	passphrase string
 
 	httpClient *http.Client
+	endpoints  Endpoints       // адреса API, подменяются SetEndpoints
+	limiter    *requestLimiter // лимиты REST запросов
+	clock      *Clock          // смещение часов биржи для подписи запросов
+	health     *ExchangeHealth // состояние биржи для отключения от входов
 
 	// WebSocket managers с автоматическим переподключением
 	wsPublicManager  *WSReconnectManager
@@ -39,20 +45,101 @@ type OKX struct {
 
 	tickerCallbacks  map[string]func(*Ticker)
 	positionCallback func(*Position)
+	orderCallback    func(*Order)
 	callbackMu       sync.RWMutex
 
+	// Локальные стаканы SubscribeOrderBook
+	books orderBookSubscriptions
+
+	// Спецификации контрактов: размеры OKX в контрактах по ctVal монет
+	instruments *InstrumentRegistry
+
+	// Режим маржи OKX задаётся в каждом ордере (tdMode), по умолчанию cross
+	marginModes   map[string]string // symbol -> MarginModeCross / MarginModeIsolated
+	marginModesMu sync.RWMutex
+
+	// Режим позиций аккаунта: true - long/short (hedge), false - net (one-way)
+	hedgeMode atomic.Bool
+
 	connected bool
 	closeChan chan struct{}
 }
 
+// init регистрирует адаптер OKX в реестре бирж
+func init() {
+	Register(Adapter{
+		Name: "okx",
+		New:  func() Exchange { return NewOKX() },
+		Capabilities: Capabilities{
+			PrivateWS:   true,
+			LimitOrders: true,
+			Funding:     true,
+			HedgeMode:   true,
+		},
+		RequiresPassphrase: true,
+		TakerFee:           0.0005,
+		MakerFee:           0.0002,
+		// Лимиты OKX заданы на эндпоинт за 2 секунды: ордера 60, баланс и позиции 10
+		RateLimits: RateLimits{
+			Orders:  30,
+			Market:  10,
+			Account: 5,
+			Endpoints: map[string]EndpointLimit{
+				"/api/v5/trade/": {Category: RateCategoryOrders},
+			},
+			LimitCodes: []string{"50011", "50061"},
+		},
+	})
+}
+
 // NewOKX создаёт новый экземпляр OKX
 // Использует глобальный HTTP клиент с connection pooling и оптимизированными таймаутами
 func NewOKX() *OKX {
-	return &OKX{
+	o := &OKX{
 		httpClient:      GetGlobalHTTPClient().GetClient(),
+		endpoints:       Endpoints{REST: okxBaseURL, WSPublic: okxWSPublic, WSPrivate: okxWSPrivate},
+		limiter:         newRequestLimiter("okx"),
+		health:          HealthOf("okx"),
 		tickerCallbacks: make(map[string]func(*Ticker)),
+		marginModes:     make(map[string]string),
 		closeChan:       make(chan struct{}),
 	}
+	o.instruments = NewInstrumentRegistry("okx", o.loadInstruments)
+	o.clock = NewClock("okx", o.getServerTime, "50102", "50112")
+	// Режим long/short, пока Connect не определил режим аккаунта
+	o.hedgeMode.Store(true)
+	return o
+}
+
+// SetEndpoints подменяет адреса REST и WebSocket API
+func (o *OKX) SetEndpoints(endpoints Endpoints) {
+	o.endpoints = endpoints
+}
+
+// getServerTime запрашивает время сервера для синхронизации часов
+func (o *OKX) getServerTime(ctx context.Context) (time.Time, error) {
+	body, err := o.doRequest(ctx, http.MethodGet, "/api/v5/public/time", nil, false)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	var resp struct {
+		Data []struct {
+			Ts string `json:"ts"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return time.Time{}, err
+	}
+	if len(resp.Data) == 0 {
+		return time.Time{}, fmt.Errorf("empty server time response")
+	}
+
+	millis, err := strconv.ParseInt(resp.Data[0].Ts, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid server time %q", resp.Data[0].Ts)
+	}
+	return time.UnixMilli(millis), nil
 }
 
 // sign создает подпись для OKX API
@@ -90,21 +177,34 @@ func (o *OKX) parseInt64(value, field string) int64 {
 	return result
 }
 
+// doRequest выполняет запрос к OKX API и учитывает его в состоянии биржи
 func (o *OKX) doRequest(ctx context.Context, method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
+	start := time.Now()
+	body, err := o.sendRequest(ctx, method, endpoint, params, signed)
+	o.health.ObserveRequest(time.Since(start), err)
+	return body, err
+}
+
+// sendRequest выполняет HTTP запрос к OKX API
+func (o *OKX) sendRequest(ctx context.Context, method, endpoint string, params map[string]string, signed bool) ([]byte, error) {
 	var reqBody string
 	var reqURL string
 
-	if method == http.MethodGet {
-		reqURL = okxBaseURL + endpoint
-		if len(params) > 0 {
-			query := make([]string, 0, len(params))
-			for k, v := range params {
-				query = append(query, k+"="+v)
-			}
-			reqURL += "?" + strings.Join(query, "&")
+	// Строка запроса строится один раз: порядок параметров в URL и в подписи должен совпадать
+	requestPath := endpoint
+	if method == http.MethodGet && len(params) > 0 {
+		query := make([]string, 0, len(params))
+		for k, v := range params {
+			query = append(query, k+"="+v)
 		}
+		sort.Strings(query)
+		requestPath += "?" + strings.Join(query, "&")
+	}
+
+	if method == http.MethodGet {
+		reqURL = o.endpoints.REST + requestPath
 	} else {
-		reqURL = okxBaseURL + endpoint
+		reqURL = o.endpoints.REST + endpoint
 		if len(params) > 0 {
 			jsonBytes, _ := json.Marshal(params)
 			reqBody = string(jsonBytes)
@@ -119,18 +219,8 @@ func (o *OKX) doRequest(ctx context.Context, method, endpoint string, params map
 	req.Header.Set("Content-Type", "application/json")
 
 	if signed {
-		timestamp := time.Now().UTC().Format("2006-01-02T15:04:05.000Z")
-		var signPath string
-		if method == http.MethodGet && len(params) > 0 {
-			query := make([]string, 0, len(params))
-			for k, v := range params {
-				query = append(query, k+"="+v)
-			}
-			signPath = endpoint + "?" + strings.Join(query, "&")
-		} else {
-			signPath = endpoint
-		}
-		signature := o.sign(timestamp, method, signPath, reqBody)
+		timestamp := o.clock.Now().UTC().Format("2006-01-02T15:04:05.000Z")
+		signature := o.sign(timestamp, method, requestPath, reqBody)
 
 		req.Header.Set("OK-ACCESS-KEY", o.apiKey)
 		req.Header.Set("OK-ACCESS-SIGN", signature)
@@ -138,11 +228,16 @@ func (o *OKX) doRequest(ctx context.Context, method, endpoint string, params map
 		req.Header.Set("OK-ACCESS-PASSPHRASE", o.passphrase)
 	}
 
+	if err := o.limiter.Wait(ctx, endpoint, signed); err != nil {
+		return nil, err
+	}
+
 	resp, err := o.httpClient.Do(req)
 	if err != nil {
 		return nil, err
 	}
 	defer resp.Body.Close()
+	o.limiter.Observe(endpoint, signed, resp)
 
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
@@ -150,19 +245,32 @@ func (o *OKX) doRequest(ctx context.Context, method, endpoint string, params map
 	}
 
 	var baseResp struct {
-		Code string `json:"code"`
-		Msg  string `json:"msg"`
+		Code string          `json:"code"`
+		Msg  string          `json:"msg"`
+		Data json.RawMessage `json:"data"`
 	}
-	if err := json.Unmarshal(body, &baseResp); err != nil {
-		return nil, err
+	if err := json.Unmarshal(body, &baseResp); err != nil || baseResp.Code == "" {
+		return nil, httpStatusError("okx", resp.StatusCode, body, err)
 	}
 
 	if baseResp.Code != "0" {
-		return nil, &ExchangeError{
+		// Для операций с ордерами (code 1, 2) причина отказа передаётся в data[].sCode
+		var items []struct {
+			SCode string `json:"sCode"`
+			SMsg  string `json:"sMsg"`
+		}
+		if json.Unmarshal(baseResp.Data, &items) == nil && len(items) > 0 && items[0].SCode != "" && items[0].SCode != "0" {
+			return nil, o.limiter.CheckError(o.clock.CheckError(&ExchangeError{
+				Exchange: "okx",
+				Code:     items[0].SCode,
+				Message:  items[0].SMsg,
+			}))
+		}
+		return nil, o.limiter.CheckError(o.clock.CheckError(&ExchangeError{
 			Exchange: "okx",
 			Code:     baseResp.Code,
 			Message:  baseResp.Msg,
-		}
+		}))
 	}
 
 	return body, nil
@@ -176,11 +284,20 @@ func (o *OKX) Connect(apiKey, secret, passphrase string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Смещение часов нужно до первого подписанного запроса; при ошибке подпись идёт по локальному времени
+	if err := o.clock.Sync(ctx); err != nil {
+		log.Printf("[okx] %v", err)
+	}
+
 	_, err := o.GetBalance(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to connect to OKX: %w", err)
 	}
 
+	if err := o.detectPositionMode(ctx); err != nil {
+		log.Printf("[okx] failed to detect position mode, using %s: %v", o.PositionMode(), err)
+	}
+
 	o.connected = true
 	return nil
 }
@@ -189,6 +306,65 @@ func (o *OKX) GetName() string {
 	return "okx"
 }
 
+// PositionMode возвращает режим позиций аккаунта
+func (o *OKX) PositionMode() string {
+	if o.hedgeMode.Load() {
+		return PositionModeHedge
+	}
+	return PositionModeOneWay
+}
+
+// detectPositionMode определяет режим позиций аккаунта по /api/v5/account/config
+func (o *OKX) detectPositionMode(ctx context.Context) error {
+	body, err := o.doRequest(ctx, http.MethodGet, "/api/v5/account/config", nil, true)
+	if err != nil {
+		return err
+	}
+
+	var resp struct {
+		Data []struct {
+			PosMode string `json:"posMode"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return err
+	}
+	if len(resp.Data) == 0 {
+		return fmt.Errorf("empty account config")
+	}
+
+	switch resp.Data[0].PosMode {
+	case "long_short_mode":
+		o.hedgeMode.Store(true)
+	case "net_mode":
+		o.hedgeMode.Store(false)
+	default:
+		return fmt.Errorf("unknown position mode %q", resp.Data[0].PosMode)
+	}
+	return nil
+}
+
+// setPositionParams задаёт позицию ордера стороны okxSide
+// В режиме long/short posSide - сторона позиции: ордер закрытия адресован позиции
+// противоположной стороны. В режиме net закрытие помечается reduceOnly
+func (o *OKX) setPositionParams(params map[string]string, okxSide string, closing bool) {
+	if !o.hedgeMode.Load() {
+		if closing {
+			params["reduceOnly"] = "true"
+		}
+		return
+	}
+
+	long := okxSide == "buy"
+	if closing {
+		long = !long
+	}
+	params["posSide"] = "short"
+	if long {
+		params["posSide"] = "long"
+	}
+}
+
 func (o *OKX) GetBalance(ctx context.Context) (float64, error) {
 	params := map[string]string{
 		"ccy": "USDT",
@@ -275,6 +451,11 @@ func (o *OKX) GetOrderBook(ctx context.Context, symbol string, depth int) (*Orde
 		depth = 400
 	}
 
+	inst, err := o.instruments.Get(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+
 	instId := o.toOKXSymbol(symbol)
 
 	params := map[string]string{
@@ -332,70 +513,76 @@ func (o *OKX) GetOrderBook(ctx context.Context, symbol string, depth int) (*Orde
 		return orderBook.Asks[i].Price < orderBook.Asks[j].Price
 	})
 
+	// Объёмы стакана OKX в контрактах
+	inst.ScaleOrderBook(orderBook)
+
 	return orderBook, nil
 }
 
-func (o *OKX) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64) (*Order, error) {
+func (o *OKX) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*Order, error) {
+	return o.placeMarketOrder(ctx, symbol, side, qty, clientOrderID, false)
+}
+
+// PlaceCloseOrder закрывает позицию стороны side рыночным ордером противоположной стороны
+func (o *OKX) PlaceCloseOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*Order, error) {
+	return o.placeMarketOrder(ctx, symbol, CloseOrderSide(side), qty, clientOrderID, true)
+}
+
+// placeMarketOrder размещает рыночный ордер; closing - ордер закрытия позиции (см. setPositionParams)
+func (o *OKX) placeMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string, closing bool) (*Order, error) {
 	instId := o.toOKXSymbol(symbol)
 
+	inst, size, err := o.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return nil, err
+	}
+	qty = inst.FromVenueSize(size)
+
 	okxSide := "buy"
-	posSide := "long"
 	if side == SideSell || side == SideShort {
 		okxSide = "sell"
-		posSide = "short"
 	}
 
 	params := map[string]string{
 		"instId":  instId,
-		"tdMode":  "cross",
+		"tdMode":  o.tdMode(symbol),
 		"side":    okxSide,
-		"posSide": posSide,
 		"ordType": "market",
-		"sz":      strconv.FormatFloat(qty, 'f', -1, 64),
+		"sz":      inst.FormatSize(size),
+	}
+	if clientOrderID != "" {
+		params["clOrdId"] = clientOrderID
 	}
+	o.setPositionParams(params, okxSide, closing)
 
 	body, err := o.doRequest(ctx, http.MethodPost, "/api/v5/trade/order", params, true)
 	if err != nil {
 		return nil, err
 	}
 
-	var resp struct {
-		Data []struct {
-			OrdId string `json:"ordId"`
-			SCode string `json:"sCode"`
-			SMsg  string `json:"sMsg"`
-		} `json:"data"`
-	}
-
-	if err := json.Unmarshal(body, &resp); err != nil {
+	ordId, err := o.parseOrderAck(body)
+	if err != nil {
 		return nil, err
 	}
 
-	if len(resp.Data) == 0 || resp.Data[0].SCode != "0" {
-		msg := "unknown error"
-		if len(resp.Data) > 0 {
-			msg = resp.Data[0].SMsg
-		}
-		return nil, fmt.Errorf("order failed: %s", msg)
-	}
-
 	order := &Order{
-		ID:        resp.Data[0].OrdId,
-		Symbol:    symbol,
-		Side:      side,
-		Type:      "market",
-		Quantity:  qty,
-		FilledQty: qty,
-		Status:    OrderStatusFilled,
-		CreatedAt: time.Now(),
-		UpdatedAt: time.Now(),
+		ID:            ordId,
+		ClientOrderID: clientOrderID,
+		Symbol:        symbol,
+		Side:          side,
+		Type:          "market",
+		Quantity:      qty,
+		FilledQty:     qty,
+		Status:        OrderStatusFilled,
+		CreatedAt:     time.Now(),
+		UpdatedAt:     time.Now(),
 	}
 
 	// Получаем цену исполнения
-	execInfo, err := o.getOrderDetail(ctx, instId, resp.Data[0].OrdId)
+	execInfo, err := o.getOrderDetail(ctx, instId, ordId)
 	if err == nil && execInfo != nil {
 		order.AvgFillPrice = execInfo.AvgPrice
-		order.FilledQty = execInfo.FilledQty
+		order.FilledQty = inst.FromVenueSize(execInfo.FilledQty)
 	}
 
 	return order, nil
@@ -477,22 +664,29 @@ func (o *OKX) GetOpenPositions(ctx context.Context) ([]*Position, error) {
 			continue
 		}
 
+		symbol := o.fromOKXSymbol(p.InstId)
+		inst, err := o.instruments.Get(ctx, symbol)
+		if err != nil {
+			return nil, err
+		}
+
 		entryPrice := o.parseFloat(p.AvgPx, "position.avgPx")
 		markPrice := o.parseFloat(p.MarkPx, "position.markPx")
 		leverage := o.parseInt(p.Lever, "position.lever")
 		unrealizedPnl := o.parseFloat(p.Upl, "position.upl")
 		uTime := o.parseInt64(p.UTime, "position.uTime")
 
+		// В режиме long/short pos всегда положителен, в net режиме направление задаёт знак pos
 		side := SideLong
-		if p.PosSide == "short" {
+		if p.PosSide == "short" || pos < 0 {
 			side = SideShort
-			pos = -pos // Делаем положительным
 		}
+		pos = math.Abs(pos)
 
 		positions = append(positions, &Position{
-			Symbol:        o.fromOKXSymbol(p.InstId),
+			Symbol:        symbol,
 			Side:          side,
-			Size:          pos,
+			Size:          inst.FromVenueSize(pos),
 			EntryPrice:    entryPrice,
 			MarkPrice:     markPrice,
 			Leverage:      leverage,
@@ -506,44 +700,264 @@ func (o *OKX) GetOpenPositions(ctx context.Context) ([]*Position, error) {
 }
 
 func (o *OKX) ClosePosition(ctx context.Context, symbol, side string, qty float64) error {
+	_, err := o.PlaceCloseOrder(ctx, symbol, side, qty, "")
+	return err
+}
+
+// okxOrderTypes - соответствие time in force типам ордеров OKX
+var okxOrderTypes = map[string]string{
+	TimeInForceGTC:      "limit",
+	TimeInForceIOC:      "ioc",
+	TimeInForceFOK:      "fok",
+	TimeInForcePostOnly: "post_only",
+}
+
+// okxOrderInfo - ордер в ответах /api/v5/trade/order и orders-pending
+type okxOrderInfo struct {
+	InstId    string `json:"instId"`
+	OrdId     string `json:"ordId"`
+	ClOrdId   string `json:"clOrdId"`
+	Side      string `json:"side"`
+	OrdType   string `json:"ordType"`
+	Px        string `json:"px"`
+	Sz        string `json:"sz"`
+	AccFillSz string `json:"accFillSz"`
+	AvgPx     string `json:"avgPx"`
+	Fee       string `json:"fee"` // отрицательная - списанная комиссия
+	State     string `json:"state"`
+	CTime     string `json:"cTime"`
+	UTime     string `json:"uTime"`
+}
+
+func (o *OKX) PlaceLimitOrder(ctx context.Context, symbol, side string, qty, price float64, tif string) (*Order, error) {
+	tif, err := normalizeTimeInForce(tif)
+	if err != nil {
+		return nil, err
+	}
+
 	instId := o.toOKXSymbol(symbol)
 
-	closeSide := "sell"
-	posSide := "long"
-	if side == SideShort {
-		closeSide = "buy"
-		posSide = "short"
+	inst, size, err := o.instruments.OrderSize(ctx, symbol, qty)
+	if err != nil {
+		return nil, err
+	}
+	qty = inst.FromVenueSize(size)
+
+	okxSide := "buy"
+	if side == SideSell || side == SideShort {
+		okxSide = "sell"
 	}
 
 	params := map[string]string{
 		"instId":  instId,
-		"tdMode":  "cross",
-		"side":    closeSide,
-		"posSide": posSide,
-		"ordType": "market",
-		"sz":      strconv.FormatFloat(qty, 'f', -1, 64),
+		"tdMode":  o.tdMode(symbol),
+		"side":    okxSide,
+		"ordType": okxOrderTypes[tif],
+		"sz":      inst.FormatSize(size),
+		"px":      strconv.FormatFloat(price, 'f', -1, 64),
+	}
+	o.setPositionParams(params, okxSide, false)
+
+	body, err := o.doRequest(ctx, http.MethodPost, "/api/v5/trade/order", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	ordId, err := o.parseOrderAck(body)
+	if err != nil {
+		return nil, err
+	}
+
+	// Получаем состояние ордера (IOC/FOK к этому моменту уже исполнены или отменены)
+	order, err := o.GetOrder(ctx, symbol, ordId)
+	if err != nil {
+		return newLimitOrder(ordId, symbol, side, qty, price, tif), nil
+	}
+	order.Side = side
+	return order, nil
+}
+
+func (o *OKX) CancelOrder(ctx context.Context, symbol, orderID string) error {
+	params := map[string]string{
+		"instId": o.toOKXSymbol(symbol),
+		"ordId":  orderID,
+	}
+
+	body, err := o.doRequest(ctx, http.MethodPost, "/api/v5/trade/cancel-order", params, true)
+	if err != nil {
+		return err
 	}
 
-	_, err := o.doRequest(ctx, http.MethodPost, "/api/v5/trade/order", params, true)
+	_, err = o.parseOrderAck(body)
 	return err
 }
 
+func (o *OKX) GetOrder(ctx context.Context, symbol, orderID string) (*Order, error) {
+	return o.findOrder(ctx, symbol, "ordId", orderID)
+}
+
+func (o *OKX) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*Order, error) {
+	return o.findOrder(ctx, symbol, "clOrdId", clientOrderID)
+}
+
+// findOrder запрашивает ордер по ordId или clOrdId
+// 51603: биржа не знает ордер
+func (o *OKX) findOrder(ctx context.Context, symbol, key, id string) (*Order, error) {
+	params := map[string]string{
+		"instId": o.toOKXSymbol(symbol),
+		key:      id,
+	}
+
+	orders, err := o.queryOrders(ctx, "/api/v5/trade/order", params)
+	if err != nil {
+		return nil, orderNotFound(err, "51603")
+	}
+	if len(orders) == 0 {
+		return nil, fmt.Errorf("order %s: %w", id, ErrOrderNotFound)
+	}
+	return orders[0], nil
+}
+
+func (o *OKX) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	params := map[string]string{
+		"instType": "SWAP",
+	}
+	if symbol != "" {
+		params["instId"] = o.toOKXSymbol(symbol)
+	}
+
+	return o.queryOrders(ctx, "/api/v5/trade/orders-pending", params)
+}
+
+// parseOrderAck проверяет результат операции с ордером (sCode) и возвращает ordId
+func (o *OKX) parseOrderAck(body []byte) (string, error) {
+	var resp struct {
+		Data []struct {
+			OrdId string `json:"ordId"`
+			SCode string `json:"sCode"`
+			SMsg  string `json:"sMsg"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", err
+	}
+
+	if len(resp.Data) == 0 {
+		return "", &ExchangeError{Exchange: "okx", Message: "empty order response"}
+	}
+	if resp.Data[0].SCode != "0" {
+		return "", &ExchangeError{
+			Exchange: "okx",
+			Code:     resp.Data[0].SCode,
+			Message:  resp.Data[0].SMsg,
+		}
+	}
+
+	return resp.Data[0].OrdId, nil
+}
+
+// queryOrders запрашивает список ордеров и конвертирует его в Order
+func (o *OKX) queryOrders(ctx context.Context, endpoint string, params map[string]string) ([]*Order, error) {
+	body, err := o.doRequest(ctx, http.MethodGet, endpoint, params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data []okxOrderInfo `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	orders := make([]*Order, 0, len(resp.Data))
+	for _, info := range resp.Data {
+		orders = append(orders, o.parseOrder(info))
+	}
+	return orders, nil
+}
+
+// parseOrder конвертирует ордер OKX в Order
+// Размеры переводятся из контрактов в монеты
+func (o *OKX) parseOrder(info okxOrderInfo) *Order {
+	symbol := o.fromOKXSymbol(info.InstId)
+	order := &Order{
+		ID:            info.OrdId,
+		ClientOrderID: info.ClOrdId,
+		Symbol:        symbol,
+		Side:          info.Side,
+		Type:          OrderTypeLimit,
+		Price:         o.parseFloat(info.Px, "order.px"),
+		Quantity:      o.instruments.CoinQty(symbol, o.parseFloat(info.Sz, "order.sz")),
+		FilledQty:     o.instruments.CoinQty(symbol, o.parseFloat(info.AccFillSz, "order.accFillSz")),
+		AvgFillPrice:  o.parseFloat(info.AvgPx, "order.avgPx"),
+		Fee:           -o.parseFloat(info.Fee, "order.fee"),
+		CreatedAt:     time.UnixMilli(o.parseInt64(info.CTime, "order.cTime")),
+		UpdatedAt:     time.UnixMilli(o.parseInt64(info.UTime, "order.uTime")),
+	}
+
+	if info.OrdType == "market" {
+		order.Type = OrderTypeMarket
+	}
+	for tif, ordType := range okxOrderTypes {
+		if info.OrdType == ordType {
+			order.TimeInForce = tif
+		}
+	}
+
+	switch info.State {
+	case "live":
+		order.Status = OrderStatusNew
+	case "partially_filled":
+		order.Status = OrderStatusPartial
+	case "filled":
+		order.Status = OrderStatusFilled
+	default: // canceled, mmp_canceled
+		order.Status = OrderStatusCancelled
+	}
+
+	return order
+}
+
 func (o *OKX) SubscribeTicker(symbol string, callback func(*Ticker)) error {
 	o.callbackMu.Lock()
 	o.tickerCallbacks[symbol] = callback
 	o.callbackMu.Unlock()
 
+	return o.subscribePublic("tickers", o.toOKXSymbol(symbol))
+}
+
+// SubscribeOrderBook подписывается на канал books (400 уровней, контрольная сумма CRC32)
+func (o *OKX) SubscribeOrderBook(symbol string, depth int, callback func(*OrderBook)) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	inst, err := o.instruments.Get(ctx, symbol)
+	cancel()
+	if err != nil {
+		return err
+	}
+
+	o.books.add(symbol, depth, inst, callback)
+	return o.subscribePublic("books", o.toOKXSymbol(symbol))
+}
+
+// subscribePublic подписывается на канал публичного WebSocket, подключаясь при необходимости
+func (o *OKX) subscribePublic(channel, instId string) error {
 	// Защита от race condition при инициализации WebSocket manager
 	o.wsMu.Lock()
 	if o.wsPublicManager == nil {
 		config := DefaultWSReconnectConfig()
-		o.wsPublicManager = NewWSReconnectManager("okx-public", okxWSPublic, config)
+		o.wsPublicManager = NewWSReconnectManager("okx-public", o.endpoints.WSPublic, config)
+		o.health.TrackWS(o.wsPublicManager)
 
 		o.wsPublicManager.SetOnMessage(o.handlePublicMessage)
 		o.wsPublicManager.SetOnConnect(func() {
 			log.Printf("[okx] Public WebSocket connected")
 		})
+		// Стаканы ждут нового снимка, который придёт после переподписки
 		o.wsPublicManager.SetOnDisconnect(func(err error) {
+			o.books.invalidateAll()
 			if err != nil {
 				log.Printf("[okx] Public WebSocket disconnected: %v", err)
 			}
@@ -557,12 +971,11 @@ func (o *OKX) SubscribeTicker(symbol string, callback func(*Ticker)) error {
 	wsManager := o.wsPublicManager
 	o.wsMu.Unlock()
 
-	instId := o.toOKXSymbol(symbol)
 	subMsg := map[string]interface{}{
 		"op": "subscribe",
 		"args": []map[string]string{
 			{
-				"channel": "tickers",
+				"channel": channel,
 				"instId":  instId,
 			},
 		},
@@ -579,40 +992,119 @@ func (o *OKX) handlePublicMessage(message []byte) {
 			Channel string `json:"channel"`
 			InstId  string `json:"instId"`
 		} `json:"arg"`
-		Data []struct {
-			BidPx string `json:"bidPx"`
-			AskPx string `json:"askPx"`
-			Last  string `json:"last"`
-			Ts    string `json:"ts"`
-		} `json:"data"`
+		Action string          `json:"action"`
+		Data   json.RawMessage `json:"data"`
 	}
 
-	if err := json.Unmarshal(message, &msg); err != nil {
+	if err := json.Unmarshal(message, &msg); err != nil || len(msg.Data) == 0 {
+		return
+	}
+
+	symbol := o.fromOKXSymbol(msg.Arg.InstId)
+	switch msg.Arg.Channel {
+	case "tickers":
+		o.handleTickerUpdate(symbol, msg.Data)
+	case "books":
+		o.handleOrderBookUpdate(symbol, msg.Arg.InstId, msg.Action, msg.Data)
+	}
+}
+
+// handleTickerUpdate обрабатывает канал tickers
+func (o *OKX) handleTickerUpdate(symbol string, data json.RawMessage) {
+	var tickers []struct {
+		BidPx string `json:"bidPx"`
+		AskPx string `json:"askPx"`
+		Last  string `json:"last"`
+		Ts    string `json:"ts"`
+	}
+
+	if err := json.Unmarshal(data, &tickers); err != nil || len(tickers) == 0 {
 		return
 	}
 
-	if msg.Arg.Channel == "tickers" && len(msg.Data) > 0 {
-		symbol := o.fromOKXSymbol(msg.Arg.InstId)
+	o.callbackMu.RLock()
+	callback, ok := o.tickerCallbacks[symbol]
+	o.callbackMu.RUnlock()
 
-		o.callbackMu.RLock()
-		callback, ok := o.tickerCallbacks[symbol]
-		o.callbackMu.RUnlock()
+	if ok && callback != nil {
+		d := tickers[0]
+		bidPrice := o.parseFloat(d.BidPx, "ws.ticker.bidPx")
+		askPrice := o.parseFloat(d.AskPx, "ws.ticker.askPx")
+		lastPrice := o.parseFloat(d.Last, "ws.ticker.last")
+		ts := o.parseInt64(d.Ts, "ws.ticker.ts")
+
+		callback(&Ticker{
+			Symbol:    symbol,
+			BidPrice:  bidPrice,
+			AskPrice:  askPrice,
+			LastPrice: lastPrice,
+			Timestamp: time.UnixMilli(ts),
+		})
+	}
+}
 
-		if ok && callback != nil {
-			d := msg.Data[0]
-			bidPrice := o.parseFloat(d.BidPx, "ws.ticker.bidPx")
-			askPrice := o.parseFloat(d.AskPx, "ws.ticker.askPx")
-			lastPrice := o.parseFloat(d.Last, "ws.ticker.last")
-			ts := o.parseInt64(d.Ts, "ws.ticker.ts")
+// handleOrderBookUpdate применяет снимок или обновление канала books
+// Обновление должно продолжать seqId предыдущего (prevSeqId), после него сверяется checksum
+func (o *OKX) handleOrderBookUpdate(symbol, instId, action string, data json.RawMessage) {
+	var books []struct {
+		Asks      [][]string `json:"asks"`
+		Bids      [][]string `json:"bids"`
+		Ts        string     `json:"ts"`
+		Checksum  int32      `json:"checksum"`
+		PrevSeqId int64      `json:"prevSeqId"`
+		SeqId     int64      `json:"seqId"`
+	}
 
-			callback(&Ticker{
-				Symbol:    symbol,
-				BidPrice:  bidPrice,
-				AskPrice:  askPrice,
-				LastPrice: lastPrice,
-				Timestamp: time.UnixMilli(ts),
-			})
+	if err := json.Unmarshal(data, &books); err != nil || len(books) == 0 {
+		return
+	}
+
+	sub := o.books.get(symbol)
+	if sub == nil {
+		return
+	}
+
+	d := books[0]
+	bids, asks := stringBookLevels(d.Bids), stringBookLevels(d.Asks)
+	ts := time.UnixMilli(o.parseInt64(d.Ts, "ws.books.ts"))
+
+	var err error
+	if action == "snapshot" {
+		sub.book.Reset(bids, asks, d.SeqId, ts)
+	} else {
+		err = sub.book.Apply(bids, asks, d.PrevSeqId, d.SeqId, ts)
+	}
+	if err == nil {
+		err = sub.book.VerifyChecksum(d.Checksum)
+	}
+
+	if err != nil {
+		if err != ErrOrderBookNotSynced {
+			log.Printf("[okx] books %s: %v, resubscribing", instId, err)
+			o.resubscribePublic("books", instId)
 		}
+		return
+	}
+
+	sub.emit()
+}
+
+// resubscribePublic переподписывается на канал, чтобы получить новый снимок
+func (o *OKX) resubscribePublic(channel, instId string) {
+	o.wsMu.Lock()
+	wsManager := o.wsPublicManager
+	o.wsMu.Unlock()
+
+	if wsManager == nil {
+		return
+	}
+
+	args := []map[string]string{{"channel": channel, "instId": instId}}
+	if err := wsManager.Send(map[string]interface{}{"op": "unsubscribe", "args": args}); err != nil {
+		return
+	}
+	if err := wsManager.Send(map[string]interface{}{"op": "subscribe", "args": args}); err != nil {
+		log.Printf("[okx] resubscribe %s %s failed: %v", channel, instId, err)
 	}
 }
 
@@ -621,11 +1113,26 @@ func (o *OKX) SubscribePositions(callback func(*Position)) error {
 	o.positionCallback = callback
 	o.callbackMu.Unlock()
 
+	return o.subscribePrivate("positions")
+}
+
+// SubscribeOrders подписывается на канал orders приватного WebSocket
+func (o *OKX) SubscribeOrders(callback func(*Order)) error {
+	o.callbackMu.Lock()
+	o.orderCallback = callback
+	o.callbackMu.Unlock()
+
+	return o.subscribePrivate("orders")
+}
+
+// subscribePrivate подписывается на канал SWAP приватного WebSocket, подключаясь при необходимости
+func (o *OKX) subscribePrivate(channel string) error {
 	// Защита от race condition при инициализации WebSocket manager
 	o.wsMu.Lock()
 	if o.wsPrivateManager == nil {
 		config := DefaultWSReconnectConfig()
-		o.wsPrivateManager = NewWSReconnectManager("okx-private", okxWSPrivate, config)
+		o.wsPrivateManager = NewWSReconnectManager("okx-private", o.endpoints.WSPrivate, config)
+		o.health.TrackWS(o.wsPrivateManager)
 
 		o.wsPrivateManager.SetAuthFunc(o.authenticateWebSocket)
 		o.wsPrivateManager.SetOnMessage(o.handlePrivateMessage)
@@ -639,6 +1146,7 @@ func (o *OKX) SubscribePositions(callback func(*Position)) error {
 		})
 
 		if err := o.wsPrivateManager.Connect(); err != nil {
+			o.wsPrivateManager = nil
 			o.wsMu.Unlock()
 			return fmt.Errorf("failed to connect to private WebSocket: %w", err)
 		}
@@ -650,7 +1158,7 @@ func (o *OKX) SubscribePositions(callback func(*Position)) error {
 		"op": "subscribe",
 		"args": []map[string]string{
 			{
-				"channel":  "positions",
+				"channel":  channel,
 				"instType": "SWAP",
 			},
 		},
@@ -661,7 +1169,7 @@ func (o *OKX) SubscribePositions(callback func(*Position)) error {
 }
 
 func (o *OKX) authenticateWebSocket(conn *websocket.Conn) error {
-	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	timestamp := strconv.FormatInt(o.clock.Now().Unix(), 10)
 	message := timestamp + "GET" + "/users/self/verify"
 	h := hmac.New(sha256.New, []byte(o.secretKey))
 	h.Write([]byte(message))
@@ -709,71 +1217,177 @@ func (o *OKX) handlePrivateMessage(message []byte) {
 		Arg struct {
 			Channel string `json:"channel"`
 		} `json:"arg"`
-		Data []struct {
-			InstId  string `json:"instId"`
-			PosSide string `json:"posSide"`
-			Pos     string `json:"pos"`
-			AvgPx   string `json:"avgPx"`
-			MarkPx  string `json:"markPx"`
-			Lever   string `json:"lever"`
-			Upl     string `json:"upl"`
-			UTime   string `json:"uTime"`
-		} `json:"data"`
+		Data json.RawMessage `json:"data"`
 	}
 
 	if err := json.Unmarshal(message, &msg); err != nil {
 		return
 	}
 
-	if msg.Arg.Channel == "positions" {
-		o.callbackMu.RLock()
-		callback := o.positionCallback
-		o.callbackMu.RUnlock()
-
-		if callback != nil {
-			for _, p := range msg.Data {
-				pos := o.parseFloat(p.Pos, "ws.position.pos")
-				entryPrice := o.parseFloat(p.AvgPx, "ws.position.avgPx")
-				markPrice := o.parseFloat(p.MarkPx, "ws.position.markPx")
-				leverage := o.parseInt(p.Lever, "ws.position.lever")
-				unrealizedPnl := o.parseFloat(p.Upl, "ws.position.upl")
-				uTime := o.parseInt64(p.UTime, "ws.position.uTime")
-
-				side := SideLong
-				if p.PosSide == "short" {
-					side = SideShort
-					if pos < 0 {
-						pos = -pos
-					}
-				}
-
-				callback(&Position{
-					Symbol:        o.fromOKXSymbol(p.InstId),
-					Side:          side,
-					Size:          pos,
-					EntryPrice:    entryPrice,
-					MarkPrice:     markPrice,
-					Leverage:      leverage,
-					UnrealizedPnl: unrealizedPnl,
-					Liquidation:   false,
-					UpdatedAt:     time.UnixMilli(uTime),
-				})
-			}
+	switch msg.Arg.Channel {
+	case "positions":
+		o.handlePositionUpdate(msg.Data)
+	case "orders":
+		o.handleOrderUpdate(msg.Data)
+	}
+}
+
+// handlePositionUpdate отправляет обновления позиций в callback
+func (o *OKX) handlePositionUpdate(data json.RawMessage) {
+	var positions []struct {
+		InstId  string `json:"instId"`
+		PosSide string `json:"posSide"`
+		Pos     string `json:"pos"`
+		AvgPx   string `json:"avgPx"`
+		MarkPx  string `json:"markPx"`
+		Lever   string `json:"lever"`
+		Upl     string `json:"upl"`
+		UTime   string `json:"uTime"`
+	}
+
+	if err := json.Unmarshal(data, &positions); err != nil {
+		return
+	}
+
+	o.callbackMu.RLock()
+	callback := o.positionCallback
+	o.callbackMu.RUnlock()
+
+	if callback == nil {
+		return
+	}
+
+	for _, p := range positions {
+		pos := o.parseFloat(p.Pos, "ws.position.pos")
+		entryPrice := o.parseFloat(p.AvgPx, "ws.position.avgPx")
+		markPrice := o.parseFloat(p.MarkPx, "ws.position.markPx")
+		leverage := o.parseInt(p.Lever, "ws.position.lever")
+		unrealizedPnl := o.parseFloat(p.Upl, "ws.position.upl")
+		uTime := o.parseInt64(p.UTime, "ws.position.uTime")
+
+		// Как в GetOpenPositions: в net режиме направление задаёт знак pos
+		side := SideLong
+		if p.PosSide == "short" || pos < 0 {
+			side = SideShort
 		}
+		pos = math.Abs(pos)
+
+		symbol := o.fromOKXSymbol(p.InstId)
+		callback(&Position{
+			Symbol:        symbol,
+			Side:          side,
+			Size:          o.instruments.CoinQty(symbol, pos),
+			EntryPrice:    entryPrice,
+			MarkPrice:     markPrice,
+			Leverage:      leverage,
+			UnrealizedPnl: unrealizedPnl,
+			Liquidation:   false,
+			UpdatedAt:     time.UnixMilli(uTime),
+		})
+	}
+}
+
+// handleOrderUpdate отправляет обновления ордеров в callback
+// Канал orders использует те же поля, что и /api/v5/trade/order
+func (o *OKX) handleOrderUpdate(data json.RawMessage) {
+	var orders []okxOrderInfo
+	if err := json.Unmarshal(data, &orders); err != nil {
+		return
+	}
+
+	o.callbackMu.RLock()
+	callback := o.orderCallback
+	o.callbackMu.RUnlock()
+
+	if callback == nil {
+		return
+	}
+
+	for _, info := range orders {
+		callback(o.parseOrder(info))
 	}
 }
 
 func (o *OKX) GetTradingFee(ctx context.Context, symbol string) (float64, error) {
-	// OKX стандартная комиссия тейкера 0.05%
-	return 0.0005, nil
+	fees, err := o.GetTradingFees(ctx, symbol)
+	if err != nil {
+		// OKX стандартная комиссия тейкера 0.05%
+		return 0.0005, nil
+	}
+	return fees.Taker, nil
+}
+
+// GetTradingFees получает комиссии аккаунта из /api/v5/account/trade-fee
+// OKX отдаёт комиссию отрицательной, а ребейт положительным - знак меняем
+// Для USDT-маржинальных контрактов ставки в полях takerU/makerU
+func (o *OKX) GetTradingFees(ctx context.Context, symbol string) (*TradingFees, error) {
+	params := map[string]string{
+		"instType":   "SWAP",
+		"instFamily": strings.TrimSuffix(o.toOKXSymbol(symbol), "-SWAP"),
+	}
+
+	body, err := o.doRequest(ctx, http.MethodGet, "/api/v5/account/trade-fee", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data []struct {
+			Level  string `json:"level"`
+			Taker  string `json:"taker"`
+			Maker  string `json:"maker"`
+			TakerU string `json:"takerU"`
+			MakerU string `json:"makerU"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	if len(resp.Data) == 0 {
+		return nil, fmt.Errorf("trade fee not found for %s", symbol)
+	}
+
+	d := resp.Data[0]
+	taker, maker := d.TakerU, d.MakerU
+	if taker == "" {
+		taker, maker = d.Taker, d.Maker
+	}
+
+	return &TradingFees{
+		Symbol:    symbol,
+		Maker:     -o.parseFloat(maker, "maker"),
+		Taker:     -o.parseFloat(taker, "taker"),
+		Tier:      d.Level,
+		Timestamp: time.Now(),
+	}, nil
 }
 
 func (o *OKX) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
-	instId := o.toOKXSymbol(symbol)
+	inst, err := o.GetInstrument(ctx, symbol)
+	if err != nil {
+		return nil, err
+	}
+	return inst.Limits(), nil
+}
+
+func (o *OKX) GetInstrument(ctx context.Context, symbol string) (*Instrument, error) {
+	return o.instruments.Get(ctx, symbol)
+}
+
+// okxInstrumentStatus - соответствие state инструмента OKX статусам Instrument
+var okxInstrumentStatus = map[string]string{
+	"live":    InstrumentStatusTrading,
+	"suspend": InstrumentStatusSuspended,
+	"preopen": InstrumentStatusSuspended,
+}
 
+// loadInstruments загружает спецификации всех USDT бессрочных контрактов
+// Размеры OKX в контрактах: ctVal монет в одном контракте
+func (o *OKX) loadInstruments(ctx context.Context) ([]*Instrument, error) {
 	params := map[string]string{
 		"instType": "SWAP",
-		"instId":   instId,
 	}
 
 	body, err := o.doRequest(ctx, http.MethodGet, "/api/v5/public/instruments", params, false)
@@ -783,12 +1397,67 @@ func (o *OKX) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
 
 	var resp struct {
 		Data []struct {
-			InstId   string `json:"instId"`
-			MinSz    string `json:"minSz"`
-			MaxLmtSz string `json:"maxLmtSz"`
-			LotSz    string `json:"lotSz"`
-			TickSz   string `json:"tickSz"`
-			Lever    string `json:"lever"`
+			InstId    string `json:"instId"`
+			SettleCcy string `json:"settleCcy"`
+			CtVal     string `json:"ctVal"`
+			MinSz     string `json:"minSz"`
+			MaxLmtSz  string `json:"maxLmtSz"`
+			LotSz     string `json:"lotSz"`
+			TickSz    string `json:"tickSz"`
+			Lever     string `json:"lever"`
+			State     string `json:"state"`
+		} `json:"data"`
+	}
+
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	instruments := make([]*Instrument, 0, len(resp.Data))
+	for _, d := range resp.Data {
+		if d.SettleCcy != "USDT" {
+			continue
+		}
+
+		status, ok := okxInstrumentStatus[d.State]
+		if !ok {
+			status = InstrumentStatusDelisted
+		}
+
+		instruments = append(instruments, &Instrument{
+			Symbol:         o.fromOKXSymbol(d.InstId),
+			VenueSymbol:    d.InstId,
+			ContractValue:  o.parseFloat(d.CtVal, "instrument.ctVal"),
+			LotSize:        o.parseFloat(d.LotSz, "instrument.lotSz"),
+			MinSize:        o.parseFloat(d.MinSz, "instrument.minSz"),
+			MaxSize:        o.parseFloat(d.MaxLmtSz, "instrument.maxLmtSz"),
+			TickSize:       o.parseFloat(d.TickSz, "instrument.tickSz"),
+			MinNotional:    5.0,
+			MaxLeverage:    o.parseInt(d.Lever, "instrument.lever"),
+			SettleCurrency: d.SettleCcy,
+			Status:         status,
+		})
+	}
+
+	return instruments, nil
+}
+
+func (o *OKX) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	params := map[string]string{
+		"instId": o.toOKXSymbol(symbol),
+	}
+
+	body, err := o.doRequest(ctx, http.MethodGet, "/api/v5/public/funding-rate", params, false)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data []struct {
+			FundingRate     string `json:"fundingRate"`
+			NextFundingRate string `json:"nextFundingRate"`
+			FundingTime     string `json:"fundingTime"`
+			NextFundingTime string `json:"nextFundingTime"`
 		} `json:"data"`
 	}
 
@@ -797,27 +1466,201 @@ func (o *OKX) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
 	}
 
 	if len(resp.Data) == 0 {
-		return nil, fmt.Errorf("instrument info not found for %s", symbol)
-	}
-
-	info := resp.Data[0]
-	minOrderQty := o.parseFloat(info.MinSz, "limits.minSz")
-	maxOrderQty := o.parseFloat(info.MaxLmtSz, "limits.maxLmtSz")
-	qtyStep := o.parseFloat(info.LotSz, "limits.lotSz")
-	priceStep := o.parseFloat(info.TickSz, "limits.tickSz")
-	maxLeverage := o.parseInt(info.Lever, "limits.lever")
-
-	return &Limits{
-		Symbol:      symbol,
-		MinOrderQty: minOrderQty,
-		MaxOrderQty: maxOrderQty,
-		QtyStep:     qtyStep,
-		MinNotional: 5.0,
-		PriceStep:   priceStep,
-		MaxLeverage: maxLeverage,
+		return nil, fmt.Errorf("funding rate not found for %s", symbol)
+	}
+
+	d := resp.Data[0]
+	fundingTime := o.parseInt64(d.FundingTime, "fundingTime")
+	nextFundingTime := o.parseInt64(d.NextFundingTime, "nextFundingTime")
+
+	// fundingTime - ближайшее начисление, nextFundingTime - следующее за ним
+	interval := DefaultFundingInterval
+	if nextFundingTime > fundingTime && fundingTime > 0 {
+		interval = time.Duration(nextFundingTime-fundingTime) * time.Millisecond
+	}
+
+	// nextFundingRate пустой, если биржа не публикует прогноз
+	var predicted float64
+	if d.NextFundingRate != "" {
+		predicted = o.parseFloat(d.NextFundingRate, "nextFundingRate")
+	}
+
+	return &FundingRate{
+		Symbol:          symbol,
+		Rate:            o.parseFloat(d.FundingRate, "fundingRate"),
+		PredictedRate:   predicted,
+		NextFundingTime: time.UnixMilli(fundingTime),
+		Interval:        interval,
+		Timestamp:       time.Now(),
 	}, nil
 }
 
+// SetLeverage устанавливает плечо символа в текущем режиме маржи
+// В isolated и режиме long/short плечо задаётся отдельно для лонга и шорта
+func (o *OKX) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if err := validateLeverage(leverage); err != nil {
+		return err
+	}
+
+	mgnMode := o.tdMode(symbol)
+	posSides := []string{""}
+	if mgnMode == MarginModeIsolated && o.hedgeMode.Load() {
+		posSides = []string{"long", "short"}
+	}
+
+	for _, posSide := range posSides {
+		params := map[string]string{
+			"instId":  o.toOKXSymbol(symbol),
+			"lever":   strconv.Itoa(leverage),
+			"mgnMode": mgnMode,
+		}
+		if posSide != "" {
+			params["posSide"] = posSide
+		}
+
+		if _, err := o.doRequest(ctx, http.MethodPost, "/api/v5/account/set-leverage", params, true); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetMarginMode запоминает режим маржи символа для ордеров (tdMode)
+// Плечо OKX хранит отдельно для каждого режима - после смены режима нужен SetLeverage
+func (o *OKX) SetMarginMode(ctx context.Context, symbol, mode string) error {
+	if err := validateMarginMode(mode); err != nil {
+		return err
+	}
+
+	o.marginModesMu.Lock()
+	o.marginModes[symbol] = mode
+	o.marginModesMu.Unlock()
+	return nil
+}
+
+// tdMode возвращает режим маржи символа для параметра tdMode ордера
+func (o *OKX) tdMode(symbol string) string {
+	o.marginModesMu.RLock()
+	mode := o.marginModes[symbol]
+	o.marginModesMu.RUnlock()
+
+	if mode == "" {
+		return MarginModeCross
+	}
+	return mode
+}
+
+// GetMarginSettings читает плечо и режим маржи открытой позиции символа (mgnMode)
+// Без позиции режим у OKX задаётся ордером: возвращается плечо для режима ордеров
+// (tdMode) и ErrMarginModeNotReported
+func (o *OKX) GetMarginSettings(ctx context.Context, symbol string) (int, string, error) {
+	instID := o.toOKXSymbol(symbol)
+
+	body, err := o.doRequest(ctx, http.MethodGet, "/api/v5/account/positions",
+		map[string]string{"instType": "SWAP", "instId": instID}, true)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var positionsResp struct {
+		Data []struct {
+			InstId  string `json:"instId"`
+			MgnMode string `json:"mgnMode"`
+			Pos     string `json:"pos"`
+			Lever   string `json:"lever"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &positionsResp); err != nil {
+		return 0, "", err
+	}
+
+	var positions []positionMargin
+	for _, p := range positionsResp.Data {
+		if p.InstId != instID || o.parseFloat(p.Pos, "position.pos") == 0 {
+			continue
+		}
+		positions = append(positions, positionMargin{
+			leverage: o.parseInt(p.Lever, "position.lever"),
+			mode:     p.MgnMode,
+		})
+	}
+	if len(positions) > 0 {
+		return commonMargin(symbol, positions)
+	}
+
+	params := map[string]string{
+		"instId":  instID,
+		"mgnMode": o.tdMode(symbol),
+	}
+
+	body, err = o.doRequest(ctx, http.MethodGet, "/api/v5/account/leverage-info", params, true)
+	if err != nil {
+		return 0, "", err
+	}
+
+	var resp struct {
+		Data []struct {
+			Lever string `json:"lever"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, "", err
+	}
+
+	leverages := make([]int, 0, len(resp.Data))
+	for _, d := range resp.Data {
+		leverages = append(leverages, o.parseInt(d.Lever, "leverage.lever"))
+	}
+	leverage, err := commonLeverage(symbol, leverages...)
+	if err != nil {
+		return 0, "", err
+	}
+	return leverage, "", ErrMarginModeNotReported
+}
+
+// GetFundingPayments читает начисления фандинга символа из /api/v5/account/bills (type=8)
+func (o *OKX) GetFundingPayments(ctx context.Context, symbol string, from, to time.Time) ([]FundingPayment, error) {
+	instID := o.toOKXSymbol(symbol)
+	params := map[string]string{
+		"instType": "SWAP",
+		"instId":   instID,
+		"type":     "8",
+		"begin":    strconv.FormatInt(from.UnixMilli(), 10),
+		"end":      strconv.FormatInt(to.UnixMilli(), 10),
+	}
+
+	body, err := o.doRequest(ctx, http.MethodGet, "/api/v5/account/bills", params, true)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp struct {
+		Data []struct {
+			InstId string `json:"instId"`
+			Type   string `json:"type"`
+			BalChg string `json:"balChg"`
+			Ts     string `json:"ts"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, err
+	}
+
+	payments := make([]FundingPayment, 0, len(resp.Data))
+	for _, r := range resp.Data {
+		if r.InstId != instID || r.Type != "8" {
+			continue
+		}
+		payments = append(payments, FundingPayment{
+			Symbol: symbol,
+			Amount: o.parseFloat(r.BalChg, "bill.balChg"),
+			Time:   time.UnixMilli(o.parseInt64(r.Ts, "bill.ts")),
+		})
+	}
+	return fundingPaymentsBetween(payments, from, to), nil
+}
+
 func (o *OKX) Close() error {
 	select {
 	case <-o.closeChan:
@@ -0,0 +1,314 @@
+package exchange
+
+import (
+	"errors"
+	"hash/crc32"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Ошибки локального стакана - сигнал адаптеру пересинхронизировать стакан
+var (
+	ErrOrderBookNotSynced = errors.New("order book not synced")
+	ErrOrderBookGap       = errors.New("order book sequence gap")
+	ErrOrderBookChecksum  = errors.New("order book checksum mismatch")
+)
+
+// DefaultOrderBookDepth - глубина стакана по умолчанию для SubscribeOrderBook
+const DefaultOrderBookDepth = 20
+
+// checksumLevels - число уровней с каждой стороны в контрольной сумме OKX и Bitget
+const checksumLevels = 25
+
+// BookLevel - уровень стакана в исходном строковом виде биржи
+// Строки нужны для контрольной суммы: она считается по тексту, а не по числам
+type BookLevel struct {
+	Price string
+	Size  string
+}
+
+type bookEntry struct {
+	raw    BookLevel
+	price  float64
+	volume float64
+}
+
+// LocalOrderBook - локальный L2-стакан из снимка и инкрементальных обновлений
+//
+// Снимок (Reset) синхронизирует стакан, обновления (Apply) применяются только
+// к синхронизированному стакану с проверкой последовательности. При разрыве
+// последовательности или несовпадении контрольной суммы стакан помечается
+// несинхронизированным и ждёт нового снимка.
+type LocalOrderBook struct {
+	mu     sync.Mutex
+	symbol string
+	bids   map[float64]bookEntry
+	asks   map[float64]bookEntry
+	seq    int64
+	synced bool
+	ts     time.Time
+}
+
+// NewLocalOrderBook создаёт пустой несинхронизированный стакан
+func NewLocalOrderBook(symbol string) *LocalOrderBook {
+	return &LocalOrderBook{
+		symbol: symbol,
+		bids:   make(map[float64]bookEntry),
+		asks:   make(map[float64]bookEntry),
+	}
+}
+
+// Reset заменяет стакан снимком и помечает его синхронизированным
+func (b *LocalOrderBook) Reset(bids, asks []BookLevel, seq int64, ts time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.bids = make(map[float64]bookEntry, len(bids))
+	b.asks = make(map[float64]bookEntry, len(asks))
+	applyBookLevels(b.bids, bids)
+	applyBookLevels(b.asks, asks)
+	b.seq = seq
+	b.synced = true
+	b.ts = ts
+}
+
+// Apply применяет инкрементальное обновление
+//
+// prevSeq >= 0 - номер предыдущего обновления, должен совпасть с текущим seq стакана.
+// prevSeq < 0 - биржа не сообщает предыдущий номер, seq должен только расти.
+// Нулевой объём удаляет уровень.
+func (b *LocalOrderBook) Apply(bids, asks []BookLevel, prevSeq, seq int64, ts time.Time) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.synced {
+		return ErrOrderBookNotSynced
+	}
+	if (prevSeq >= 0 && prevSeq != b.seq) || (prevSeq < 0 && seq > 0 && seq <= b.seq) {
+		b.synced = false
+		return ErrOrderBookGap
+	}
+
+	applyBookLevels(b.bids, bids)
+	applyBookLevels(b.asks, asks)
+	b.seq = seq
+	b.ts = ts
+	return nil
+}
+
+// VerifyChecksum сверяет CRC32 топ-25 уровней с контрольной суммой биржи (OKX, Bitget)
+// При несовпадении стакан помечается несинхронизированным
+func (b *LocalOrderBook) VerifyChecksum(expected int32) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.checksumLocked() != expected {
+		b.synced = false
+		return ErrOrderBookChecksum
+	}
+	return nil
+}
+
+// checksumLocked - CRC32 строки "bidPx:bidSz:askPx:askSz:..." по топ-25 уровням
+func (b *LocalOrderBook) checksumLocked() int32 {
+	bids := sortedBookEntries(b.bids, true, checksumLevels)
+	asks := sortedBookEntries(b.asks, false, checksumLevels)
+
+	parts := make([]string, 0, 2*(len(bids)+len(asks)))
+	for i := 0; i < checksumLevels; i++ {
+		if i < len(bids) {
+			parts = append(parts, bids[i].raw.Price, bids[i].raw.Size)
+		}
+		if i < len(asks) {
+			parts = append(parts, asks[i].raw.Price, asks[i].raw.Size)
+		}
+	}
+	return int32(crc32.ChecksumIEEE([]byte(strings.Join(parts, ":"))))
+}
+
+// Invalidate помечает стакан несинхронизированным (разрыв соединения, ресинхронизация)
+func (b *LocalOrderBook) Invalidate() {
+	b.mu.Lock()
+	b.synced = false
+	b.mu.Unlock()
+}
+
+// Synced возвращает true если стакан синхронизирован
+func (b *LocalOrderBook) Synced() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.synced
+}
+
+// Seq возвращает номер последнего применённого обновления
+func (b *LocalOrderBook) Seq() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.seq
+}
+
+// Snapshot возвращает копию топ-depth уровней (depth <= 0 - весь стакан)
+func (b *LocalOrderBook) Snapshot(depth int) *OrderBook {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	bids := sortedBookEntries(b.bids, true, depth)
+	asks := sortedBookEntries(b.asks, false, depth)
+
+	book := &OrderBook{
+		Symbol:    b.symbol,
+		Bids:      make([]PriceLevel, len(bids)),
+		Asks:      make([]PriceLevel, len(asks)),
+		Timestamp: b.ts,
+	}
+	for i, e := range bids {
+		book.Bids[i] = PriceLevel{Price: e.price, Volume: e.volume}
+	}
+	for i, e := range asks {
+		book.Asks[i] = PriceLevel{Price: e.price, Volume: e.volume}
+	}
+	return book
+}
+
+// applyBookLevels обновляет сторону стакана; уровни с нечитаемыми числами пропускаются
+func applyBookLevels(side map[float64]bookEntry, levels []BookLevel) {
+	for _, l := range levels {
+		price, err := strconv.ParseFloat(l.Price, 64)
+		if err != nil {
+			continue
+		}
+		volume, err := strconv.ParseFloat(l.Size, 64)
+		if err != nil {
+			continue
+		}
+		if volume == 0 {
+			delete(side, price)
+			continue
+		}
+		side[price] = bookEntry{raw: l, price: price, volume: volume}
+	}
+}
+
+// sortedBookEntries сортирует сторону стакана (bids - по убыванию цены) и обрезает до limit
+func sortedBookEntries(side map[float64]bookEntry, desc bool, limit int) []bookEntry {
+	entries := make([]bookEntry, 0, len(side))
+	for _, e := range side {
+		entries = append(entries, e)
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		if desc {
+			return entries[i].price > entries[j].price
+		}
+		return entries[i].price < entries[j].price
+	})
+	if limit > 0 && len(entries) > limit {
+		entries = entries[:limit]
+	}
+	return entries
+}
+
+// stringBookLevels конвертирует уровни вида [["price","size",...]] в BookLevel
+func stringBookLevels(levels [][]string) []BookLevel {
+	result := make([]BookLevel, 0, len(levels))
+	for _, l := range levels {
+		if len(l) >= 2 {
+			result = append(result, BookLevel{Price: l[0], Size: l[1]})
+		}
+	}
+	return result
+}
+
+// floatBookLevels конвертирует числовые уровни [[price, size]] в BookLevel
+func floatBookLevels(levels [][]float64) []BookLevel {
+	result := make([]BookLevel, 0, len(levels))
+	for _, l := range levels {
+		if len(l) >= 2 {
+			result = append(result, BookLevel{
+				Price: strconv.FormatFloat(l[0], 'f', -1, 64),
+				Size:  strconv.FormatFloat(l[1], 'f', -1, 64),
+			})
+		}
+	}
+	return result
+}
+
+// orderBookSubscription - подписка адаптера на стакан символа
+type orderBookSubscription struct {
+	book       *LocalOrderBook
+	depth      int
+	instrument *Instrument // для бирж с размером в контрактах, nil - объёмы уже в монетах
+	callback   func(*OrderBook)
+}
+
+// emit передаёт подписчику топ стакана с объёмами в монетах
+func (s *orderBookSubscription) emit() {
+	if s.callback == nil {
+		return
+	}
+	book := s.book.Snapshot(s.depth)
+	if s.instrument != nil {
+		s.instrument.ScaleOrderBook(book)
+	}
+	s.callback(book)
+}
+
+// orderBookSubscriptions - подписки адаптера на стаканы по символам
+// Нулевое значение готово к использованию
+type orderBookSubscriptions struct {
+	mu   sync.RWMutex
+	subs map[string]*orderBookSubscription
+}
+
+// add регистрирует подписку (повторная подписка заменяет callback и глубину)
+// inst задаётся биржами с размером в контрактах для перевода объёмов в монеты
+func (s *orderBookSubscriptions) add(symbol string, depth int, inst *Instrument, callback func(*OrderBook)) *orderBookSubscription {
+	if depth <= 0 {
+		depth = DefaultOrderBookDepth
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.subs == nil {
+		s.subs = make(map[string]*orderBookSubscription)
+	}
+	sub, ok := s.subs[symbol]
+	if !ok {
+		sub = &orderBookSubscription{book: NewLocalOrderBook(symbol)}
+		s.subs[symbol] = sub
+	}
+	sub.depth = depth
+	sub.instrument = inst
+	sub.callback = callback
+	return sub
+}
+
+// get возвращает подписку символа или nil
+func (s *orderBookSubscriptions) get(symbol string) *orderBookSubscription {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.subs[symbol]
+}
+
+// invalidateAll помечает все стаканы несинхронизированными (при разрыве соединения)
+func (s *orderBookSubscriptions) invalidateAll() {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	for _, sub := range s.subs {
+		sub.book.Invalidate()
+	}
+}
+
+// symbols возвращает символы с активной подпиской
+func (s *orderBookSubscriptions) symbols() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	symbols := make([]string, 0, len(s.subs))
+	for symbol := range s.subs {
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
@@ -0,0 +1,77 @@
+package exchange
+
+import (
+	"hash/crc32"
+	"testing"
+	"time"
+)
+
+func levels(pairs ...string) []BookLevel {
+	result := make([]BookLevel, 0, len(pairs)/2)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		result = append(result, BookLevel{Price: pairs[i], Size: pairs[i+1]})
+	}
+	return result
+}
+
+// TestLocalOrderBook_SnapshotAndDiffs проверяет применение снимка и дельт
+func TestLocalOrderBook_SnapshotAndDiffs(t *testing.T) {
+	book := NewLocalOrderBook("BTCUSDT")
+
+	if err := book.Apply(levels("100", "1"), nil, 0, 1, time.Now()); err != ErrOrderBookNotSynced {
+		t.Fatalf("expected ErrOrderBookNotSynced before snapshot, got %v", err)
+	}
+
+	book.Reset(levels("100", "1", "99", "2"), levels("101", "1", "102", "3"), 10, time.Now())
+
+	// Новый уровень, изменение объёма и удаление нулевым объёмом
+	if err := book.Apply(levels("100.5", "4", "99", "0"), levels("101", "2"), 10, 11, time.Now()); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	snap := book.Snapshot(2)
+	if len(snap.Bids) != 2 || snap.Bids[0].Price != 100.5 || snap.Bids[1].Price != 100 {
+		t.Fatalf("unexpected bids: %+v", snap.Bids)
+	}
+	if len(snap.Asks) != 2 || snap.Asks[0].Price != 101 || snap.Asks[0].Volume != 2 {
+		t.Fatalf("unexpected asks: %+v", snap.Asks)
+	}
+}
+
+// TestLocalOrderBook_Gap проверяет обнаружение пропуска обновления
+func TestLocalOrderBook_Gap(t *testing.T) {
+	book := NewLocalOrderBook("BTCUSDT")
+	book.Reset(levels("100", "1"), levels("101", "1"), 10, time.Now())
+
+	// Пропущено обновление 11
+	if err := book.Apply(levels("100", "2"), nil, 11, 12, time.Now()); err != ErrOrderBookGap {
+		t.Fatalf("expected ErrOrderBookGap, got %v", err)
+	}
+	if book.Synced() {
+		t.Fatal("book must wait for a new snapshot after a gap")
+	}
+
+	// Без номера предыдущего обновления seq должен только расти
+	book.Reset(levels("100", "1"), levels("101", "1"), 10, time.Now())
+	if err := book.Apply(nil, nil, -1, 10, time.Now()); err != ErrOrderBookGap {
+		t.Fatalf("expected ErrOrderBookGap for repeated seq, got %v", err)
+	}
+}
+
+// TestLocalOrderBook_Checksum проверяет CRC32 по чередованию bid/ask в исходном формате биржи
+func TestLocalOrderBook_Checksum(t *testing.T) {
+	book := NewLocalOrderBook("BTCUSDT")
+	book.Reset(levels("3366.1", "7", "3366", "6"), levels("3366.8", "9", "3368", "8"), 1, time.Now())
+
+	expected := int32(crc32.ChecksumIEEE([]byte("3366.1:7:3366.8:9:3366:6:3368:8")))
+	if err := book.VerifyChecksum(expected); err != nil {
+		t.Fatalf("unexpected checksum error: %v", err)
+	}
+
+	if err := book.VerifyChecksum(expected + 1); err != ErrOrderBookChecksum {
+		t.Fatalf("expected ErrOrderBookChecksum, got %v", err)
+	}
+	if book.Synced() {
+		t.Fatal("book must wait for a new snapshot after checksum mismatch")
+	}
+}
@@ -0,0 +1,72 @@
+package exchange
+
+import "context"
+
+// ============================================================
+// Режимы позиций аккаунта
+// ============================================================
+//
+// В режиме one-way по символу одна позиция: ордер buy уменьшает шорт,
+// ордер sell - лонг. В режиме hedge лонг и шорт ведутся отдельно, ордер
+// указывает сторону позиции (positionIdx Bybit, posSide OKX, holdSide и
+// tradeSide Bitget), а ордер противоположной стороны открывает встречную
+// позицию вместо закрытия.
+//
+// Адаптеры бирж с режимом hedge определяют режим аккаунта при Connect:
+//   - PlaceMarketOrder и PlaceLimitOrder открывают позицию стороны ордера
+//     (buy - лонг, sell - шорт)
+//   - PlaceCloseOrder и ClosePosition закрывают позицию указанной стороны
+//   - GetOpenPositions возвращает стороны позиции отдельно
+//
+// Благодаря этому бот может работать на аккаунте вместе с трейдером,
+// использующим режим hedge.
+
+// Position mode constants
+const (
+	PositionModeOneWay = "one_way" // одна позиция на символ
+	PositionModeHedge  = "hedge"   // раздельные позиции лонг и шорт
+)
+
+// PositionCloser реализуют адаптеры, поддерживающие режим hedge
+type PositionCloser interface {
+	// PositionMode возвращает режим позиций аккаунта, определённый при Connect
+	PositionMode() string
+
+	// PlaceCloseOrder размещает рыночный ордер закрытия позиции стороны side (SideLong / SideShort)
+	// В режиме hedge ордер не открывает встречную позицию; Side результата - сторона ордера (SideSell закрывает лонг)
+	PlaceCloseOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*Order, error)
+}
+
+// PositionModeOf возвращает режим позиций аккаунта биржи
+// Адаптеры без PositionCloser работают в режиме one-way
+func PositionModeOf(exch Exchange) string {
+	if closer, ok := exch.(PositionCloser); ok {
+		return closer.PositionMode()
+	}
+	return PositionModeOneWay
+}
+
+// PlaceCloseOrder закрывает позицию стороны side (SideLong / SideShort) рыночным ордером
+// Адаптеры без PositionCloser закрывают позицию ордером противоположной стороны
+func PlaceCloseOrder(ctx context.Context, exch Exchange, symbol, side string, qty float64, clientOrderID string) (*Order, error) {
+	if closer, ok := exch.(PositionCloser); ok {
+		return closer.PlaceCloseOrder(ctx, symbol, side, qty, clientOrderID)
+	}
+	return exch.PlaceMarketOrder(ctx, symbol, CloseOrderSide(side), qty, clientOrderID)
+}
+
+// CloseOrderSide возвращает сторону ордера закрытия позиции: лонг закрывается продажей, шорт - покупкой
+func CloseOrderSide(side string) string {
+	if side == SideLong || side == SideBuy {
+		return SideSell
+	}
+	return SideBuy
+}
+
+// ClosedPositionSide возвращает сторону позиции, которую закрывает ордер side
+func ClosedPositionSide(orderSide string) string {
+	if orderSide == SideSell || orderSide == SideShort {
+		return SideLong
+	}
+	return SideShort
+}
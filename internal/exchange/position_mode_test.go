@@ -0,0 +1,158 @@
+package exchange
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+)
+
+// connectScenario подключает адаптер к локальной бирже сценария conformance
+// Маршруты routes проверяются раньше маршрутов сценария
+func connectScenario(t *testing.T, name string, routes ...conformanceRoute) (Exchange, *conformanceServer, *conformanceScenario) {
+	t.Helper()
+	adapter, ok := LookupAdapter(name)
+	if !ok {
+		t.Fatalf("adapter %s not registered", name)
+	}
+
+	scenario := loadConformanceScenario(t, name)
+	scenario.Routes = append(routes, scenario.Routes...)
+	server := newConformanceServer(t, name, scenario)
+
+	exch := adapter.New()
+	exch.(EndpointSetter).SetEndpoints(server.endpoints())
+	t.Cleanup(func() { exch.Close() })
+
+	if err := exch.Connect(conformanceAPIKey, conformanceSecret, conformancePassphrase); err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	return exch, server, scenario
+}
+
+// lastOrderRequest возвращает тело последнего запроса размещения ордера
+func lastOrderRequest(t *testing.T, requests []conformanceRequest, path string) string {
+	t.Helper()
+	for i := len(requests) - 1; i >= 0; i-- {
+		if requests[i].Method == http.MethodPost && requests[i].Path == path {
+			return requests[i].Raw
+		}
+	}
+	t.Fatalf("no POST %s request in %+v", path, requests)
+	return ""
+}
+
+// TestPositionMode_OneWay проверяет ордера аккаунта в режиме one-way: без стороны
+// позиции, закрытие - reduce-only ордер противоположной стороны
+func TestPositionMode_OneWay(t *testing.T) {
+	cases := []struct {
+		name   string
+		route  conformanceRoute
+		absent string // параметр режима hedge
+		reduce string // признак reduce-only
+	}{
+		{
+			name:   "okx",
+			route:  conformanceRoute{Method: http.MethodGet, Path: "/api/v5/account/config", Fixture: "account_config_net.json"},
+			absent: "posSide",
+			reduce: `"reduceOnly":"true"`,
+		},
+		{
+			name:   "bitget",
+			route:  conformanceRoute{Method: http.MethodGet, Path: "/api/v2/mix/account/account", Fixture: "account_one_way.json"},
+			absent: "tradeSide",
+			reduce: `"reduceOnly":"YES"`,
+		},
+	}
+
+	for _, tc := range cases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			exch, server, scenario := connectScenario(t, tc.name, tc.route)
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			defer cancel()
+
+			if mode := PositionModeOf(exch); mode != PositionModeOneWay {
+				t.Fatalf("PositionModeOf() = %q, want %q", mode, PositionModeOneWay)
+			}
+
+			mark := server.mark()
+			if _, err := exch.PlaceMarketOrder(ctx, scenario.Symbol, SideBuy, scenario.Order.Qty, ""); err != nil {
+				t.Fatalf("PlaceMarketOrder: %v", err)
+			}
+			if raw := lastOrderRequest(t, server.since(mark), scenario.Order.Path); strings.Contains(raw, tc.absent) || strings.Contains(raw, "reduceOnly") {
+				t.Errorf("open order request %q carries %s or reduceOnly", raw, tc.absent)
+			}
+
+			mark = server.mark()
+			order, err := PlaceCloseOrder(ctx, exch, scenario.Symbol, SideLong, scenario.Order.Qty, "")
+			if err != nil {
+				t.Fatalf("PlaceCloseOrder: %v", err)
+			}
+			raw := lastOrderRequest(t, server.since(mark), scenario.Order.Path)
+			if !strings.Contains(raw, `"side":"sell"`) || !strings.Contains(raw, tc.reduce) || strings.Contains(raw, tc.absent) {
+				t.Errorf("close long request %q: want sell with %s and no %s", raw, tc.reduce, tc.absent)
+			}
+			if order.Side != SideSell {
+				t.Errorf("close long order side %q, want %q", order.Side, SideSell)
+			}
+		})
+	}
+}
+
+// TestBybitPositionIdxMismatch проверяет, что Bybit без открытых позиций начинает с
+// режима one-way и переключается на hedge по отказу ордера с positionIdx 0
+func TestBybitPositionIdxMismatch(t *testing.T) {
+	exch, server, scenario := connectScenario(t, "bybit",
+		conformanceRoute{Method: http.MethodGet, Path: "/v5/position/list", Fixture: "order_empty.json"},
+		conformanceRoute{Method: http.MethodPost, Path: "/v5/order/create", Match: `"positionIdx":"0"`, Fixture: "error_position_idx.json"},
+	)
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if mode := PositionModeOf(exch); mode != PositionModeOneWay {
+		t.Fatalf("PositionModeOf() = %q before orders, want %q", mode, PositionModeOneWay)
+	}
+
+	mark := server.mark()
+	if _, err := PlaceCloseOrder(ctx, exch, scenario.Symbol, SideShort, scenario.Order.Qty, conformanceClientID); err != nil {
+		t.Fatalf("PlaceCloseOrder: %v", err)
+	}
+	if mode := PositionModeOf(exch); mode != PositionModeHedge {
+		t.Fatalf("PositionModeOf() = %q after rejected order, want %q", mode, PositionModeHedge)
+	}
+	raw := lastOrderRequest(t, server.since(mark), scenario.Order.Path)
+	if !strings.Contains(raw, `"side":"Buy"`) || !strings.Contains(raw, `"positionIdx":"2"`) {
+		t.Errorf("retried close short request %q: want Buy with positionIdx 2", raw)
+	}
+}
+
+// TestPlaceCloseOrder_Fallback проверяет закрытие позиции на бирже без PositionCloser
+func TestPlaceCloseOrder_Fallback(t *testing.T) {
+	sim := newTestSim()
+	ctx := context.Background()
+
+	if _, err := sim.PlaceMarketOrder(ctx, "BTCUSDT", SideSell, 1, ""); err != nil {
+		t.Fatalf("open short: %v", err)
+	}
+	if mode := PositionModeOf(sim); mode != PositionModeOneWay {
+		t.Fatalf("PositionModeOf() = %q, want %q", mode, PositionModeOneWay)
+	}
+
+	order, err := PlaceCloseOrder(ctx, sim, "BTCUSDT", SideShort, 1, "")
+	if err != nil {
+		t.Fatalf("PlaceCloseOrder: %v", err)
+	}
+	if order.Side != SideBuy {
+		t.Fatalf("close short order side %q, want %q", order.Side, SideBuy)
+	}
+
+	positions, err := sim.GetOpenPositions(ctx)
+	if err != nil {
+		t.Fatalf("GetOpenPositions: %v", err)
+	}
+	if len(positions) != 0 {
+		t.Fatalf("expected short closed, got %+v", positions)
+	}
+}
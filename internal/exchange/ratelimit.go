@@ -0,0 +1,292 @@
+package exchange
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"arbitrage/pkg/ratelimit"
+)
+
+// Категории эндпоинтов для лимитов запросов
+const (
+	RateCategoryOrders  = "orders"  // размещение, отмена и статус ордеров
+	RateCategoryMarket  = "market"  // публичные рыночные данные
+	RateCategoryAccount = "account" // баланс, позиции и настройки аккаунта
+)
+
+// Приоритет категорий в общем лимите IP: доля ёмкости, которую запрос
+// оставляет более важным запросам. Ордера берут весь остаток, опрос баланса
+// и позиций не опускает общий лимит ниже четверти
+var rateCategoryReserve = map[string]float64{
+	RateCategoryOrders:  0,
+	RateCategoryMarket:  0.1,
+	RateCategoryAccount: 0.25,
+}
+
+const (
+	rateLimitBackoff    = time.Second     // первая пауза после HTTP 429 или кода лимита биржи
+	rateLimitMaxBackoff = time.Minute     // пауза растёт вдвое до этого значения
+	rateLimitBanBackoff = 2 * time.Minute // пауза после HTTP 418 (бан по IP) без Retry-After
+)
+
+// RateLimits описывает лимиты REST API биржи в единицах веса в секунду
+// Ёмкость (burst) каждого лимита - две секунды запросов
+type RateLimits struct {
+	Total   float64 // общий лимит IP, его расходуют все категории; 0 - без общего лимита
+	Orders  float64 // лимит ордеров на ключ; 0 - только общий лимит
+	Market  float64 // лимит публичных данных
+	Account float64 // лимит запросов аккаунта на ключ
+
+	// Категория и вес эндпоинтов по префиксу пути (выбирается самый длинный)
+	// Остальные эндпоинты: подписанные - account, публичные - market, вес 1
+	Endpoints map[string]EndpointLimit
+
+	// Заголовки ответа с состоянием окна лимита: остаток (RemainHeader)
+	// или израсходованный вес (UsedHeader) и размер окна (LimitHeader или WindowLimit)
+	RemainHeader  string
+	UsedHeader    string
+	LimitHeader   string
+	WindowLimit   float64
+	SharedHeaders bool // заголовки описывают общий лимит IP, а не лимит эндпоинта
+
+	LimitCodes []string // коды ExchangeError о превышении лимита
+}
+
+// EndpointLimit - категория и вес эндпоинта
+type EndpointLimit struct {
+	Category string
+	Weight   float64 // 0 - вес 1
+}
+
+// Общие лимиты IP разделяются всеми экземплярами адаптера одной биржи
+var (
+	sharedLimitersMu sync.Mutex
+	sharedLimiters   = make(map[string]*ratelimit.RateLimiter)
+)
+
+// sharedLimiter возвращает общий лимит IP биржи
+func sharedLimiter(name string, rate float64) *ratelimit.RateLimiter {
+	sharedLimitersMu.Lock()
+	defer sharedLimitersMu.Unlock()
+
+	limiter, ok := sharedLimiters[name]
+	if !ok {
+		limiter = ratelimit.NewRateLimiter(rate, rate*2)
+		sharedLimiters[name] = limiter
+	}
+	return limiter
+}
+
+// requestLimiter ограничивает частоту REST запросов адаптера
+// Лимиты категорий действуют на ключ (экземпляр адаптера), общий лимит - на IP
+type requestLimiter struct {
+	exchange   string
+	limits     RateLimits
+	total      *ratelimit.RateLimiter
+	categories *ratelimit.MultiLimiter
+
+	mu        sync.Mutex
+	backoff   time.Duration // следующая пауза без Retry-After
+	lastPause time.Time
+}
+
+// newRequestLimiter создаёт limiter по лимитам из реестра адаптеров
+func newRequestLimiter(name string) *requestLimiter {
+	adapter, _ := LookupAdapter(name)
+	return newRequestLimiterWithLimits(name, adapter.RateLimits)
+}
+
+// newRequestLimiterWithLimits создаёт limiter с заданными лимитами
+func newRequestLimiterWithLimits(name string, limits RateLimits) *requestLimiter {
+	l := &requestLimiter{
+		exchange:   name,
+		limits:     limits,
+		categories: ratelimit.NewMultiLimiter(),
+		backoff:    rateLimitBackoff,
+	}
+	if limits.Total > 0 {
+		l.total = sharedLimiter(name, limits.Total)
+	}
+	for category, rate := range map[string]float64{
+		RateCategoryOrders:  limits.Orders,
+		RateCategoryMarket:  limits.Market,
+		RateCategoryAccount: limits.Account,
+	} {
+		if rate > 0 {
+			l.categories.Add(category, rate, rate*2)
+		}
+	}
+	return l
+}
+
+// classify возвращает категорию и вес эндпоинта
+func (l *requestLimiter) classify(endpoint string, signed bool) (string, float64) {
+	if i := strings.IndexByte(endpoint, '?'); i >= 0 {
+		endpoint = endpoint[:i]
+	}
+
+	category := RateCategoryMarket
+	if signed {
+		category = RateCategoryAccount
+	}
+	weight := 1.0
+
+	matched := -1
+	for prefix, limit := range l.limits.Endpoints {
+		if len(prefix) > matched && strings.HasPrefix(endpoint, prefix) {
+			matched = len(prefix)
+			if limit.Category != "" {
+				category = limit.Category
+			}
+			weight = 1
+			if limit.Weight > 0 {
+				weight = limit.Weight
+			}
+		}
+	}
+	return category, weight
+}
+
+// Wait ожидает разрешения на запрос к эндпоинту
+func (l *requestLimiter) Wait(ctx context.Context, endpoint string, signed bool) error {
+	category, weight := l.classify(endpoint, signed)
+
+	if err := l.categories.WaitWeight(ctx, category, weight); err != nil {
+		return err
+	}
+	if l.total != nil {
+		reserve := rateCategoryReserve[category] * l.total.Burst()
+		if err := l.total.WaitWeight(ctx, weight, reserve); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Observe подстраивает лимиты под ответ биржи: заголовки остатка лимита, HTTP 429 и 418
+func (l *requestLimiter) Observe(endpoint string, signed bool, resp *http.Response) {
+	switch resp.StatusCode {
+	case http.StatusTooManyRequests:
+		l.pause(retryAfter(resp.Header), false)
+		return
+	case http.StatusTeapot:
+		// Binance отвечает 418, когда IP забанен за игнорирование 429
+		delay := retryAfter(resp.Header)
+		if delay == 0 {
+			delay = rateLimitBanBackoff
+		}
+		l.pause(delay, true)
+		return
+	}
+
+	remaining, limit, ok := l.window(resp.Header)
+	if !ok {
+		return
+	}
+
+	target := l.total
+	if !l.limits.SharedHeaders {
+		category, _ := l.classify(endpoint, signed)
+		if categoryLimiter := l.categories.Get(category); categoryLimiter != nil {
+			target = categoryLimiter
+		}
+	}
+	if target != nil {
+		target.LimitTokens(target.Burst() * remaining / limit)
+	}
+}
+
+// CheckError приостанавливает запросы, если биржа вернула код превышения лимита
+// Возвращает исходную ошибку
+func (l *requestLimiter) CheckError(err *ExchangeError) *ExchangeError {
+	for _, code := range l.limits.LimitCodes {
+		if err.Code == code {
+			l.pause(0, false)
+			break
+		}
+	}
+	return err
+}
+
+// pause останавливает все запросы адаптера на delay
+// Без delay пауза растёт вдвое с каждым превышением в течение rateLimitMaxBackoff
+func (l *requestLimiter) pause(delay time.Duration, ban bool) {
+	l.mu.Lock()
+	if time.Since(l.lastPause) > rateLimitMaxBackoff {
+		l.backoff = rateLimitBackoff
+	}
+	l.lastPause = time.Now()
+	if delay == 0 {
+		delay = l.backoff
+		l.backoff *= 2
+		if l.backoff > rateLimitMaxBackoff {
+			l.backoff = rateLimitMaxBackoff
+		}
+	}
+	l.mu.Unlock()
+
+	if ban {
+		log.Printf("[%s] IP banned by rate limit, pausing requests for %v", l.exchange, delay)
+	} else {
+		log.Printf("[%s] rate limit exceeded, pausing requests for %v", l.exchange, delay)
+	}
+
+	if l.total != nil {
+		l.total.Pause(delay)
+	}
+	for _, category := range []string{RateCategoryOrders, RateCategoryMarket, RateCategoryAccount} {
+		if limiter := l.categories.Get(category); limiter != nil {
+			limiter.Pause(delay)
+		}
+	}
+}
+
+// window читает из заголовков остаток и размер окна лимита
+func (l *requestLimiter) window(header http.Header) (float64, float64, bool) {
+	limit := l.limits.WindowLimit
+	if l.limits.LimitHeader != "" {
+		if value, err := strconv.ParseFloat(header.Get(l.limits.LimitHeader), 64); err == nil {
+			limit = value
+		}
+	}
+	if limit <= 0 {
+		return 0, 0, false
+	}
+
+	var remaining float64
+	switch {
+	case l.limits.RemainHeader != "" && header.Get(l.limits.RemainHeader) != "":
+		value, err := strconv.ParseFloat(header.Get(l.limits.RemainHeader), 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		remaining = value
+	case l.limits.UsedHeader != "" && header.Get(l.limits.UsedHeader) != "":
+		value, err := strconv.ParseFloat(header.Get(l.limits.UsedHeader), 64)
+		if err != nil {
+			return 0, 0, false
+		}
+		remaining = limit - value
+	default:
+		return 0, 0, false
+	}
+
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, limit, true
+}
+
+// retryAfter возвращает паузу из заголовка Retry-After (в секундах)
+func retryAfter(header http.Header) time.Duration {
+	seconds, err := strconv.Atoi(header.Get("Retry-After"))
+	if err != nil || seconds <= 0 {
+		return 0
+	}
+	return time.Duration(seconds) * time.Second
+}
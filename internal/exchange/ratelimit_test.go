@@ -0,0 +1,146 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// TestRequestLimiter_Classify проверяет категории и веса эндпоинтов
+func TestRequestLimiter_Classify(t *testing.T) {
+	l := newRequestLimiter("binance")
+
+	tests := []struct {
+		endpoint string
+		signed   bool
+		category string
+		weight   float64
+	}{
+		{"/fapi/v1/order", true, RateCategoryOrders, 1},
+		{"/fapi/v1/openOrders", true, RateCategoryOrders, 1},
+		{"/fapi/v2/balance", true, RateCategoryAccount, 5},
+		{"/fapi/v1/depth", false, RateCategoryMarket, 5},
+		{"/fapi/v1/ticker/bookTicker", false, RateCategoryMarket, 2},
+		{"/fapi/v1/listenKey", false, RateCategoryAccount, 1},
+		{"/fapi/v1/leverage", true, RateCategoryAccount, 1},
+	}
+	for _, tt := range tests {
+		category, weight := l.classify(tt.endpoint, tt.signed)
+		if category != tt.category || weight != tt.weight {
+			t.Errorf("%s: got %s/%v, want %s/%v", tt.endpoint, category, weight, tt.category, tt.weight)
+		}
+	}
+
+	// Путь с параметрами (Gate передаёт id ордера в пути)
+	gate := newRequestLimiter("gate")
+	if category, _ := gate.classify("/futures/usdt/orders/58828342147", true); category != RateCategoryOrders {
+		t.Errorf("expected gate order by id in orders category, got %s", category)
+	}
+	if category, _ := gate.classify("/futures/usdt/positions/BTC_USDT/leverage?leverage=5", true); category != RateCategoryAccount {
+		t.Errorf("expected gate leverage in account category, got %s", category)
+	}
+}
+
+// TestRequestLimiter_OrdersPriority проверяет, что опрос аккаунта не забирает общий лимит у ордеров
+func TestRequestLimiter_OrdersPriority(t *testing.T) {
+	l := newRequestLimiterWithLimits("test-priority", RateLimits{Total: 10})
+
+	// Ёмкость 20, опрос аккаунта оставляет ордерам четверть
+	for i := 0; i < 15; i++ {
+		if err := l.Wait(context.Background(), "/balance", true); err != nil {
+			t.Fatalf("account request %d: %v", i, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, "/balance", true); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected account request to wait for reserve, got %v", err)
+	}
+
+	l.limits.Endpoints = map[string]EndpointLimit{"/order": {Category: RateCategoryOrders}}
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		if err := l.Wait(context.Background(), "/order", true); err != nil {
+			t.Fatalf("order request %d: %v", i, err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 50*time.Millisecond {
+		t.Fatalf("expected orders to use reserve without waiting, took %v", elapsed)
+	}
+}
+
+// TestRequestLimiter_Backoff проверяет паузу после HTTP 429 и кода лимита биржи
+func TestRequestLimiter_Backoff(t *testing.T) {
+	l := newRequestLimiterWithLimits("test-backoff", RateLimits{
+		Total:      100,
+		Orders:     100,
+		LimitCodes: []string{"10006"},
+	})
+
+	header := http.Header{}
+	header.Set("Retry-After", "1")
+	l.Observe("/order", true, &http.Response{StatusCode: http.StatusTooManyRequests, Header: header})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := l.Wait(ctx, "/ticker", false); !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected requests paused after 429, got %v", err)
+	}
+
+	// Код лимита биржи возвращается без изменений, пауза растёт вдвое
+	exchErr := &ExchangeError{Exchange: "bybit", Code: "10006", Message: "Too many visits!"}
+	if err := l.CheckError(exchErr); err != exchErr {
+		t.Fatalf("expected original error, got %v", err)
+	}
+	l.CheckError(exchErr)
+	if l.backoff != 4*rateLimitBackoff {
+		t.Fatalf("expected backoff doubled twice, got %v", l.backoff)
+	}
+
+	l.CheckError(&ExchangeError{Exchange: "bybit", Code: "110007", Message: "insufficient balance"})
+	if l.backoff != 4*rateLimitBackoff {
+		t.Fatalf("expected no backoff for other codes, got %v", l.backoff)
+	}
+}
+
+// TestRequestLimiter_Headers проверяет подстройку под остаток лимита из заголовков
+func TestRequestLimiter_Headers(t *testing.T) {
+	// Binance: израсходованный вес общего лимита IP
+	l := newRequestLimiterWithLimits("test-headers", RateLimits{
+		Total:         40,
+		UsedHeader:    "X-MBX-USED-WEIGHT-1M",
+		WindowLimit:   2400,
+		SharedHeaders: true,
+	})
+	header := http.Header{}
+	header.Set("X-MBX-USED-WEIGHT-1M", "2280")
+	l.Observe("/fapi/v2/balance", true, &http.Response{StatusCode: http.StatusOK, Header: header})
+
+	// Осталось 5% окна - 4 токена из 80
+	if tokens := l.total.Tokens(); tokens > 4.5 {
+		t.Fatalf("expected total limit reduced to 5%%, got %v tokens", tokens)
+	}
+
+	// Bybit: остаток лимита эндпоинта применяется к категории
+	l = newRequestLimiterWithLimits("test-headers-endpoint", RateLimits{
+		Total:        120,
+		Orders:       10,
+		Endpoints:    map[string]EndpointLimit{"/v5/order/": {Category: RateCategoryOrders}},
+		RemainHeader: "X-Bapi-Limit-Status",
+		LimitHeader:  "X-Bapi-Limit",
+	})
+	header = http.Header{}
+	header.Set("X-Bapi-Limit-Status", "0")
+	header.Set("X-Bapi-Limit", "10")
+	l.Observe("/v5/order/create", true, &http.Response{StatusCode: http.StatusOK, Header: header})
+
+	if tokens := l.categories.Get(RateCategoryOrders).Tokens(); tokens > 0.5 {
+		t.Fatalf("expected orders limit exhausted, got %v tokens", tokens)
+	}
+	if tokens := l.total.Tokens(); tokens < 239 {
+		t.Fatalf("expected total limit untouched, got %v tokens", tokens)
+	}
+}
@@ -0,0 +1,116 @@
+package exchange
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"arbitrage/pkg/utils"
+)
+
+// ============================================================
+// Реестр адаптеров бирж
+// ============================================================
+//
+// Каждый адаптер регистрирует себя в init() своего файла (или пакета):
+// имя, конструктор, возможности и требования к ключам. Фабрика, API
+// и движок узнают о биржах только из реестра, поэтому новая биржа -
+// это один новый файл с адаптером и вызовом Register.
+
+// Capabilities - возможности адаптера биржи
+type Capabilities struct {
+	PrivateWS   bool `json:"private_ws"`   // приватный WebSocket: позиции и ордера (SubscribePositions, SubscribeOrders)
+	LimitOrders bool `json:"limit_orders"` // лимитные ордера (мейкер-нога)
+	Funding     bool `json:"funding"`      // ставки фандинга (GetFundingRate)
+	HedgeMode   bool `json:"hedge_mode"`   // раздельные позиции лонг и шорт по символу
+}
+
+// AllCapabilities - полный набор возможностей
+// Используется для адаптеров вне реестра (моки в тестах, встраивание)
+var AllCapabilities = Capabilities{PrivateWS: true, LimitOrders: true, Funding: true, HedgeMode: true}
+
+// Adapter - описание адаптера биржи в реестре
+type Adapter struct {
+	Name               string          // имя биржи в нижнем регистре (bybit, okx, ...)
+	New                func() Exchange // конструктор адаптера
+	Capabilities       Capabilities
+	RequiresPassphrase bool       // помимо API key и secret нужен passphrase
+	TakerFee           float64    // стандартная комиссия тейкера (базовый VIP уровень) для симулятора
+	MakerFee           float64    // стандартная комиссия мейкера
+	RateLimits         RateLimits // лимиты REST API
+}
+
+var (
+	adaptersMu sync.RWMutex
+	adapters   = make(map[string]Adapter)
+)
+
+// Register регистрирует адаптер биржи
+// Вызывается из init(); пустое имя, nil конструктор и повторная регистрация - паника
+func Register(adapter Adapter) {
+	name := strings.ToLower(strings.TrimSpace(adapter.Name))
+	if name == "" || strings.HasPrefix(name, simPrefix) {
+		panic(fmt.Sprintf("exchange: invalid adapter name %q", adapter.Name))
+	}
+	if adapter.New == nil {
+		panic(fmt.Sprintf("exchange: adapter %s has nil constructor", name))
+	}
+	adapter.Name = name
+
+	adaptersMu.Lock()
+	defer adaptersMu.Unlock()
+	if _, exists := adapters[name]; exists {
+		panic(fmt.Sprintf("exchange: adapter %s registered twice", name))
+	}
+	adapters[name] = adapter
+	utils.RegisterExchange(name)
+}
+
+// LookupAdapter возвращает описание адаптера по имени
+func LookupAdapter(name string) (Adapter, bool) {
+	adaptersMu.RLock()
+	defer adaptersMu.RUnlock()
+	adapter, ok := adapters[strings.ToLower(name)]
+	return adapter, ok
+}
+
+// Adapters возвращает описания всех зарегистрированных адаптеров, отсортированные по имени
+func Adapters() []Adapter {
+	adaptersMu.RLock()
+	result := make([]Adapter, 0, len(adapters))
+	for _, adapter := range adapters {
+		result = append(result, adapter)
+	}
+	adaptersMu.RUnlock()
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
+
+// SupportedExchanges возвращает имена зарегистрированных бирж, отсортированные по алфавиту
+func SupportedExchanges() []string {
+	list := Adapters()
+	names := make([]string, len(list))
+	for i, adapter := range list {
+		names[i] = adapter.Name
+	}
+	return names
+}
+
+// CapabilitiesOf возвращает возможности биржи по имени
+// Принимает идентификатор аккаунта ("arb1@okx"); симулятор "sim:okx" наследует
+// возможности OKX; неизвестное имя - AllCapabilities
+func CapabilitiesOf(name string) Capabilities {
+	name = strings.TrimPrefix(VenueOf(name), simPrefix)
+	if adapter, ok := LookupAdapter(name); ok {
+		return adapter.Capabilities
+	}
+	return AllCapabilities
+}
+
+// RequiresPassphrase сообщает, нужен ли бирже passphrase при подключении
+func RequiresPassphrase(name string) bool {
+	adapter, ok := LookupAdapter(name)
+	return ok && adapter.RequiresPassphrase
+}
@@ -0,0 +1,92 @@
+package exchange
+
+import (
+	"testing"
+
+	"arbitrage/pkg/utils"
+)
+
+// TestRegistry_BuiltinAdapters проверяет саморегистрацию адаптеров и запросы к реестру
+func TestRegistry_BuiltinAdapters(t *testing.T) {
+	for _, name := range []string{"bybit", "bitget", "okx", "gate", "htx", "bingx", "binance"} {
+		if !IsSupported(name) {
+			t.Fatalf("expected %s to be registered", name)
+		}
+		exch, err := NewExchange(name)
+		if err != nil || exch == nil {
+			t.Fatalf("NewExchange(%s): %v", name, err)
+		}
+		if !utils.IsSupportedExchange(name) {
+			t.Fatalf("expected %s in utils validator", name)
+		}
+	}
+
+	if _, err := NewExchange("kraken"); err == nil {
+		t.Fatal("expected error for unregistered exchange")
+	}
+	if !RequiresPassphrase("okx") || !RequiresPassphrase("bitget") || RequiresPassphrase("bybit") {
+		t.Fatal("unexpected passphrase requirements")
+	}
+
+	// Симулятор наследует возможности биржи, неизвестное имя не ограничивается
+	if CapabilitiesOf("sim:gate").HedgeMode || !CapabilitiesOf("sim:okx").HedgeMode {
+		t.Fatal("expected sim to inherit venue capabilities")
+	}
+	if CapabilitiesOf("mock") != AllCapabilities {
+		t.Fatal("expected full capabilities for unregistered exchange")
+	}
+
+	if cfg := DefaultSimConfig("bybit"); cfg.TakerFee != 0.00055 || cfg.MakerFee != 0.0002 {
+		t.Fatalf("expected bybit fees from registry, got taker %v maker %v", cfg.TakerFee, cfg.MakerFee)
+	}
+}
+
+// TestRegistry_DuplicatePanics проверяет, что имя нельзя зарегистрировать дважды
+func TestRegistry_DuplicatePanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("expected panic on duplicate registration")
+		}
+	}()
+	Register(Adapter{Name: "OKX", New: func() Exchange { return NewOKX() }})
+}
+
+// TestAccountKey проверяет идентификаторы аккаунтов и общие для биржи возможности
+func TestAccountKey(t *testing.T) {
+	if key := AccountKey("Bybit", ""); key != "bybit" {
+		t.Fatalf("expected main account key bybit, got %q", key)
+	}
+	key := AccountKey("bybit", "Arb1")
+	if key != "arb1@bybit" {
+		t.Fatalf("expected arb1@bybit, got %q", key)
+	}
+	if venue, label := SplitAccountKey(key); venue != "bybit" || label != "arb1" {
+		t.Fatalf("expected bybit/arb1, got %q/%q", venue, label)
+	}
+	if venue, label := SplitAccountKey("okx"); venue != "okx" || label != "" {
+		t.Fatalf("expected okx main account, got %q/%q", venue, label)
+	}
+
+	for key, ok := range map[string]bool{
+		"bybit":          true,
+		"arb1@bybit":     true,
+		"sub_2-x@okx":    true,
+		"arb1@kraken":    false,
+		"a b@bybit":      false,
+		"a@b@bybit":      false,
+		"sim:bybit":      false,
+		"arb1@sim:bybit": false,
+		"verylonglabelverylonglabelverylonglabel@gate": false,
+	} {
+		if IsSupportedAccount(key) != ok {
+			t.Errorf("IsSupportedAccount(%q) = %v, want %v", key, !ok, ok)
+		}
+	}
+
+	if CapabilitiesOf("arb1@gate").HedgeMode || !CapabilitiesOf("arb1@okx").HedgeMode || !CapabilitiesOf("arb1@sim:okx").HedgeMode {
+		t.Fatal("expected account to inherit venue capabilities")
+	}
+	if HealthOf("arb1@htx") != HealthOf("htx") {
+		t.Fatal("expected accounts of one venue to share health")
+	}
+}
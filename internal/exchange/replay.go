@@ -0,0 +1,272 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"arbitrage/internal/marketdata"
+)
+
+// RecordSource - источник записанных рыночных данных (marketdata.Reader)
+type RecordSource interface {
+	Next() (*marketdata.Record, error)
+}
+
+// ReplayConfig - параметры воспроизведения
+type ReplayConfig struct {
+	// Speed - темп воспроизведения: 0 = максимально быстро,
+	// 1 = реальное время, 10 = в 10 раз быстрее
+	Speed float64
+
+	// TopVolume - объём лучшего уровня для тикеров, пришедших до первого снимка стакана
+	TopVolume float64
+
+	// SimConfig - параметры исполнения для биржи (nil = DefaultSimConfig)
+	SimConfig func(venue string) SimConfig
+
+	// AfterEvent вызывается синхронно после отправки каждой записи подписчикам
+	// Позволяет backtest дождаться обработки события движком (детерминизм в быстром режиме)
+	AfterEvent func(rec *marketdata.Record)
+}
+
+// VirtualClock - часы воспроизведения, время двигается только записями
+type VirtualClock struct {
+	nanos int64
+}
+
+// Now возвращает текущее виртуальное время
+func (c *VirtualClock) Now() time.Time {
+	return time.Unix(0, atomic.LoadInt64(&c.nanos))
+}
+
+// Set переводит часы; назад часы не идут
+func (c *VirtualClock) Set(t time.Time) {
+	nanos := t.UnixNano()
+	for {
+		current := atomic.LoadInt64(&c.nanos)
+		if nanos <= current || atomic.CompareAndSwapInt64(&c.nanos, current, nanos) {
+			return
+		}
+	}
+}
+
+// ReplayFeed воспроизводит записанные тикеры и стаканы через адаптеры ReplayExchange
+//
+// Поток данных:
+// marketdata.Reader → ReplayFeed.Run → ReplayExchange (стакан Sim) → SubscribeTicker callback
+//
+// Порядок и время:
+// - записи воспроизводятся в порядке записи (время получения RecvTs)
+// - виртуальные часы переводятся на RecvTs перед отправкой записи
+// - тикер отдаётся с временем биржи Ts, как его видел живой движок
+type ReplayFeed struct {
+	cfg    ReplayConfig
+	source RecordSource
+	clock  *VirtualClock
+
+	mu     sync.Mutex
+	venues map[string]*ReplayExchange
+
+	events int64
+}
+
+// NewReplayFeed создает воспроизведение из источника записей
+func NewReplayFeed(source RecordSource, cfg ReplayConfig) *ReplayFeed {
+	if cfg.TopVolume <= 0 {
+		cfg.TopVolume = 1e9
+	}
+
+	return &ReplayFeed{
+		cfg:    cfg,
+		source: source,
+		clock:  &VirtualClock{},
+		venues: make(map[string]*ReplayExchange),
+	}
+}
+
+// Clock возвращает виртуальные часы воспроизведения
+func (f *ReplayFeed) Clock() *VirtualClock {
+	return f.clock
+}
+
+// Exchange возвращает (создаёт при первом обращении) адаптер биржи
+// Адаптеры нужно получить и подключить к движку ДО Run
+func (f *ReplayFeed) Exchange(venue string) *ReplayExchange {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if exch, ok := f.venues[venue]; ok {
+		return exch
+	}
+
+	simCfg := DefaultSimConfig(venue)
+	if f.cfg.SimConfig != nil {
+		simCfg = f.cfg.SimConfig(venue)
+		simCfg.Venue = venue
+	}
+
+	exch := &ReplayExchange{
+		Sim:       NewSim(simCfg),
+		snapshots: make(map[string]*OrderBook),
+		topVolume: f.cfg.TopVolume,
+	}
+	exch.Sim.SetClock(f.clock.Now)
+	f.venues[venue] = exch
+	return exch
+}
+
+// Events возвращает количество воспроизведённых записей
+func (f *ReplayFeed) Events() int64 {
+	return atomic.LoadInt64(&f.events)
+}
+
+// Run воспроизводит все записи; возвращает nil по окончании данных
+// Записи бирж, для которых не был запрошен Exchange, пропускаются
+func (f *ReplayFeed) Run(ctx context.Context) error {
+	var firstRecord time.Time
+	var wallStart time.Time
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		rec, err := f.source.Next()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+
+		f.mu.Lock()
+		exch, ok := f.venues[rec.Exchange]
+		f.mu.Unlock()
+		if !ok {
+			continue
+		}
+
+		recTime := replayTime(rec)
+
+		// Темп воспроизведения
+		if f.cfg.Speed > 0 {
+			if firstRecord.IsZero() {
+				firstRecord = recTime
+				wallStart = time.Now()
+			}
+			target := wallStart.Add(time.Duration(float64(recTime.Sub(firstRecord)) / f.cfg.Speed))
+			if wait := time.Until(target); wait > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(wait):
+				}
+			}
+		}
+
+		f.clock.Set(recTime)
+		exch.apply(rec)
+		atomic.AddInt64(&f.events, 1)
+
+		if f.cfg.AfterEvent != nil {
+			f.cfg.AfterEvent(rec)
+		}
+	}
+}
+
+// replayTime - время записи для часов: время получения, если оно записано
+func replayTime(rec *marketdata.Record) time.Time {
+	if rec.RecvTs != 0 {
+		return time.Unix(0, rec.RecvTs)
+	}
+	return time.Unix(0, rec.Ts)
+}
+
+// ReplayExchange - адаптер биржи для воспроизведения
+//
+// Исполнение ордеров, баланс, позиции и ликвидации - через встроенный Sim.
+// Ордер исполняется по стакану, который был на бирже в этот момент записи:
+//   - снимок стакана заменяет стакан целиком
+//   - тикер заменяет лучшие уровни: глубина берётся из последнего снимка
+//     (уровни хуже нового bid/ask), объём лучшего уровня - из снимка или TopVolume
+type ReplayExchange struct {
+	*Sim
+
+	mu        sync.Mutex
+	snapshots map[string]*OrderBook
+	topVolume float64
+}
+
+// apply применяет запись к стакану биржи
+func (r *ReplayExchange) apply(rec *marketdata.Record) {
+	switch rec.Type {
+	case marketdata.TypeOrderBook:
+		book := &OrderBook{
+			Symbol:    rec.Symbol,
+			Bids:      fromLevels(rec.Bids),
+			Asks:      fromLevels(rec.Asks),
+			Timestamp: time.Unix(0, rec.Ts),
+		}
+		r.mu.Lock()
+		r.snapshots[rec.Symbol] = book
+		r.mu.Unlock()
+		r.Sim.SetOrderBook(book)
+
+	case marketdata.TypeTicker:
+		if rec.Bid <= 0 || rec.Ask <= 0 {
+			return
+		}
+		r.mu.Lock()
+		snapshot := r.snapshots[rec.Symbol]
+		r.mu.Unlock()
+		r.Sim.SetOrderBook(r.tickerBook(rec, snapshot))
+	}
+}
+
+// tickerBook строит стакан из тикера и последнего снимка
+func (r *ReplayExchange) tickerBook(rec *marketdata.Record, snapshot *OrderBook) *OrderBook {
+	bidVolume, askVolume := r.topVolume, r.topVolume
+	book := &OrderBook{
+		Symbol:    rec.Symbol,
+		Timestamp: time.Unix(0, rec.Ts),
+	}
+
+	if snapshot != nil {
+		if len(snapshot.Bids) > 0 {
+			bidVolume = snapshot.Bids[0].Volume
+		}
+		if len(snapshot.Asks) > 0 {
+			askVolume = snapshot.Asks[0].Volume
+		}
+	}
+
+	book.Bids = append(book.Bids, PriceLevel{Price: rec.Bid, Volume: bidVolume})
+	book.Asks = append(book.Asks, PriceLevel{Price: rec.Ask, Volume: askVolume})
+
+	if snapshot != nil {
+		for _, level := range snapshot.Bids {
+			if level.Price < rec.Bid {
+				book.Bids = append(book.Bids, level)
+			}
+		}
+		for _, level := range snapshot.Asks {
+			if level.Price > rec.Ask {
+				book.Asks = append(book.Asks, level)
+			}
+		}
+	}
+
+	return book
+}
+
+func fromLevels(levels []marketdata.Level) []PriceLevel {
+	result := make([]PriceLevel, len(levels))
+	for i, level := range levels {
+		result[i] = PriceLevel{Price: level[0], Volume: level[1]}
+	}
+	return result
+}
@@ -0,0 +1,100 @@
+package exchange
+
+import (
+	"context"
+	"io"
+	"testing"
+	"time"
+
+	"arbitrage/internal/marketdata"
+)
+
+// sliceSource - источник записей из памяти
+type sliceSource struct {
+	records []*marketdata.Record
+	idx     int
+}
+
+func (s *sliceSource) Next() (*marketdata.Record, error) {
+	if s.idx >= len(s.records) {
+		return nil, io.EOF
+	}
+	rec := s.records[s.idx]
+	s.idx++
+	return rec, nil
+}
+
+func replayRecords(base time.Time) []*marketdata.Record {
+	at := func(ms int) int64 { return base.Add(time.Duration(ms) * time.Millisecond).UnixNano() }
+	return []*marketdata.Record{
+		{Type: marketdata.TypeOrderBook, Ts: at(0), RecvTs: at(0), Exchange: "bybit", Symbol: "BTCUSDT",
+			Bids: []marketdata.Level{{100, 1}, {99, 5}}, Asks: []marketdata.Level{{101, 1}, {102, 5}}},
+		{Type: marketdata.TypeTicker, Ts: at(10), RecvTs: at(12), Exchange: "bybit", Symbol: "BTCUSDT", Bid: 100.5, Ask: 100.8},
+		{Type: marketdata.TypeTicker, Ts: at(20), RecvTs: at(21), Exchange: "okx", Symbol: "BTCUSDT", Bid: 100, Ask: 101},
+		{Type: marketdata.TypeTicker, Ts: at(30), RecvTs: at(31), Exchange: "bybit", Symbol: "BTCUSDT", Bid: 103, Ask: 104},
+	}
+}
+
+// TestReplayFeed_FillsAgainstRecordedBook проверяет исполнение по стакану на момент записи
+func TestReplayFeed_FillsAgainstRecordedBook(t *testing.T) {
+	base := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	feed := NewReplayFeed(&sliceSource{records: replayRecords(base)}, ReplayConfig{})
+	bybit := feed.Exchange("bybit")
+
+	var tickers []*Ticker
+	bybit.SubscribeTicker("BTCUSDT", func(t *Ticker) { tickers = append(tickers, t) })
+
+	var order *Order
+	feed.cfg.AfterEvent = func(rec *marketdata.Record) {
+		if rec.Type == marketdata.TypeTicker && rec.Bid == 100.5 {
+			var err error
+			order, err = bybit.PlaceMarketOrder(context.Background(), "BTCUSDT", SideBuy, 3, "")
+			if err != nil {
+				t.Fatalf("PlaceMarketOrder: %v", err)
+			}
+		}
+	}
+
+	if err := feed.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	// Записи okx пропущены - адаптер не запрашивался
+	if feed.Events() != 3 || len(tickers) != 3 {
+		t.Fatalf("expected 3 events and tickers, got %d and %d", feed.Events(), len(tickers))
+	}
+
+	// Стакан на момент ордера: 100.8 x1 (объём из снимка), 101 x1, 102 x5
+	if order == nil || order.FilledQty != 3 {
+		t.Fatalf("unexpected order: %+v", order)
+	}
+	expected := (100.8 + 101 + 102) / 3
+	if !almostEqual(order.AvgFillPrice, expected) {
+		t.Fatalf("expected avg %.4f, got %.4f", expected, order.AvgFillPrice)
+	}
+	if !order.CreatedAt.Equal(time.Unix(0, base.Add(12*time.Millisecond).UnixNano())) {
+		t.Fatalf("order time should come from virtual clock, got %v", order.CreatedAt)
+	}
+	if !tickers[1].Timestamp.Equal(base.Add(10 * time.Millisecond)) {
+		t.Fatalf("ticker should carry exchange time, got %v", tickers[1].Timestamp)
+	}
+}
+
+// TestReplayFeed_RealTimePacing проверяет воспроизведение в темпе записи
+func TestReplayFeed_RealTimePacing(t *testing.T) {
+	base := time.Now()
+	records := []*marketdata.Record{
+		{Type: marketdata.TypeTicker, RecvTs: base.UnixNano(), Exchange: "okx", Symbol: "ETHUSDT", Bid: 1, Ask: 2},
+		{Type: marketdata.TypeTicker, RecvTs: base.Add(100 * time.Millisecond).UnixNano(), Exchange: "okx", Symbol: "ETHUSDT", Bid: 1, Ask: 2},
+	}
+	feed := NewReplayFeed(&sliceSource{records: records}, ReplayConfig{Speed: 1})
+	feed.Exchange("okx")
+
+	start := time.Now()
+	if err := feed.Run(context.Background()); err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Fatalf("expected real-time pacing, finished in %v", elapsed)
+	}
+}
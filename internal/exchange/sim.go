@@ -0,0 +1,1232 @@
+package exchange
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// simPrefix - префикс имени симулятора в фабрике: "sim:bybit", "sim:okx", ...
+const simPrefix = "sim:"
+
+// Коды ошибок симулятора (ExchangeError.Code)
+const (
+	SimErrRejected              = "sim_rejected"
+	SimErrNoOrderBook           = "sim_no_orderbook"
+	SimErrInsufficientLiquidity = "sim_insufficient_liquidity"
+	SimErrInsufficientMargin    = "sim_insufficient_margin"
+	SimErrInvalidQty            = "sim_invalid_qty"
+	SimErrInvalidPrice          = "sim_invalid_price"
+	SimErrOrderNotFound         = "sim_order_not_found"
+	SimErrInvalidLeverage       = "sim_invalid_leverage"
+	SimErrPositionOpen          = "sim_position_open"
+)
+
+// SimConfig - параметры симулируемой биржи
+type SimConfig struct {
+	Venue                 string        // имитируемая биржа (bybit, okx, ...)
+	InitialBalance        float64       // стартовый баланс в USDT
+	TakerFee              float64       // комиссия тейкера (0.0005 = 0.05%)
+	MakerFee              float64       // комиссия мейкера для исполнений лимитных ордеров из стакана
+	Leverage              int           // плечо для расчёта маржи и цены ликвидации
+	MaintenanceMarginRate float64       // поддерживающая маржа (0.005 = 0.5% от notional)
+	ConsumeLiquidity      bool          // исполненный объём убирается из стакана до следующего SetOrderBook
+	Latency               time.Duration // искусственная задержка ответа на ордер
+	Limits                Limits        // торговые лимиты (Symbol подставляется при запросе)
+}
+
+// DefaultSimConfig возвращает конфигурацию симулятора для указанной биржи
+func DefaultSimConfig(venue string) SimConfig {
+	// Стандартные комиссии биржи из реестра адаптеров
+	fee, makerFee := 0.0005, 0.0002
+	if adapter, ok := LookupAdapter(venue); ok {
+		fee, makerFee = adapter.TakerFee, adapter.MakerFee
+	}
+
+	return SimConfig{
+		Venue:                 venue,
+		InitialBalance:        10000,
+		TakerFee:              fee,
+		MakerFee:              makerFee,
+		Leverage:              10,
+		MaintenanceMarginRate: 0.005,
+		ConsumeLiquidity:      true,
+		Limits: Limits{
+			MinOrderQty: 0.001,
+			MaxOrderQty: 1000,
+			QtyStep:     0.001,
+			MinNotional: 5,
+			PriceStep:   0.01,
+			MaxLeverage: 100,
+		},
+	}
+}
+
+// simPosition - позиция симулятора (one-way режим, одна позиция на символ)
+type simPosition struct {
+	size       float64 // > 0 лонг, < 0 шорт
+	entryPrice float64
+	margin     float64 // изолированная маржа позиции
+	leverage   int     // плечо на момент открытия
+	markPrice  float64
+	updatedAt  time.Time
+}
+
+// Sim реализует интерфейс Exchange поверх in-memory модели исполнения (paper trading)
+//
+// Назначение:
+// Позволяет прогонять реальные bot.Engine, OrderExecutor и RiskManager
+// end-to-end без реальных денег и сети.
+//
+// Модель:
+//   - Стакан задаётся извне через SetOrderBook (тест, backtest, replay)
+//   - Рыночный ордер проходит по уровням противоположной стороны стакана
+//   - Лимитный ордер сразу исполняется по уровням не хуже своей цены (тейкер),
+//     остаток GTC/post-only ордера ждёт в книге и исполняется по своей цене (мейкер),
+//     когда очередной SetOrderBook пересекает его цену
+//   - Комиссия тейкера/мейкера списывается с баланса при каждом исполнении
+//   - Изолированная маржа позиции = notional / leverage
+//   - Ликвидация при достижении цены ликвидации: маржа позиции теряется,
+//     в SubscribePositions уходит событие с Liquidation = true
+//   - Фандинг не начисляется сам: AddFundingPayment меняет баланс
+//     и историю начислений (FundingHistoryReader)
+//   - RejectNext / SetRejectFunc отклоняют ордера по требованию
+//     (для проверки SecondLegFailHandler и откатов)
+type Sim struct {
+	cfg SimConfig
+
+	mu        sync.Mutex
+	books     map[string]*OrderBook
+	positions map[string]*simPosition
+	orders    []*Order
+	orderByID map[string]*Order
+	byClient  map[string]*Order // рыночные ордера по клиентскому ID
+	resting   []*Order          // активные лимитные ордера в порядке размещения
+	orderSeq  int64
+
+	fundingRates    map[string]float64 // ставка фандинга по символу (SetFundingRate)
+	fundingPayments []FundingPayment   // начисления фандинга (AddFundingPayment)
+
+	leverages   map[string]int    // плечо по символу (SetLeverage), иначе cfg.Leverage
+	marginModes map[string]string // режим маржи по символу (SetMarginMode)
+
+	walletBalance float64 // баланс без учёта нереализованного PNL
+	realizedPnl   float64
+	feesPaid      float64
+
+	// Инъекция отказов
+	rejectCount int
+	rejectFn    func(symbol, side string, qty float64) error
+
+	// Часы (подменяются для детерминированного replay/backtest)
+	now func() time.Time
+
+	// Callbacks
+	tickerCallbacks  map[string]func(*Ticker)
+	bookCallbacks    map[string]simBookSubscription
+	positionCallback func(*Position)
+	orderCallback    func(*Order)
+	callbackMu       sync.RWMutex
+
+	connected bool
+}
+
+// NewSim создает новый симулятор биржи
+func NewSim(cfg SimConfig) *Sim {
+	if cfg.Leverage <= 0 {
+		cfg.Leverage = 1
+	}
+
+	return &Sim{
+		cfg:             cfg,
+		books:           make(map[string]*OrderBook),
+		positions:       make(map[string]*simPosition),
+		orderByID:       make(map[string]*Order),
+		byClient:        make(map[string]*Order),
+		fundingRates:    make(map[string]float64),
+		leverages:       make(map[string]int),
+		marginModes:     make(map[string]string),
+		walletBalance:   cfg.InitialBalance,
+		now:             time.Now,
+		tickerCallbacks: make(map[string]func(*Ticker)),
+		bookCallbacks:   make(map[string]simBookSubscription),
+	}
+}
+
+// simBookSubscription - подписка SubscribeOrderBook
+type simBookSubscription struct {
+	depth    int
+	callback func(*OrderBook)
+}
+
+// ============ Управление симуляцией ============
+
+// SetClock подменяет источник времени (для детерминированного replay)
+func (s *Sim) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	s.now = now
+	s.mu.Unlock()
+}
+
+// RejectNext отклоняет следующие n ордеров (включая ордера закрытия)
+func (s *Sim) RejectNext(n int) {
+	s.mu.Lock()
+	s.rejectCount = n
+	s.mu.Unlock()
+}
+
+// SetRejectFunc устанавливает функцию отказа: ненулевая ошибка отклоняет ордер
+// nil снимает функцию
+func (s *Sim) SetRejectFunc(fn func(symbol, side string, qty float64) error) {
+	s.mu.Lock()
+	s.rejectFn = fn
+	s.mu.Unlock()
+}
+
+// SetOrderBook заменяет стакан символа
+//
+// После обновления:
+// - исполняются лимитные ордера, цену которых пересёк стакан
+// - пересчитывается mark price позиции (mid стакана)
+// - проверяется ликвидация
+// - подписчикам SubscribeOrders отправляются изменившиеся ордера
+// - подписчикам SubscribeTicker отправляется лучший bid/ask
+// - подписчикам SubscribeOrderBook отправляется топ стакана
+func (s *Sim) SetOrderBook(book *OrderBook) {
+	if book == nil {
+		return
+	}
+
+	s.callbackMu.RLock()
+	tickerCb := s.tickerCallbacks[book.Symbol]
+	bookSub, hasBookSub := s.bookCallbacks[book.Symbol]
+	positionCb := s.positionCallback
+	s.callbackMu.RUnlock()
+
+	s.mu.Lock()
+	stored := copyOrderBook(book, 0)
+	sort.Slice(stored.Bids, func(i, j int) bool { return stored.Bids[i].Price > stored.Bids[j].Price })
+	sort.Slice(stored.Asks, func(i, j int) bool { return stored.Asks[i].Price < stored.Asks[j].Price })
+	if stored.Timestamp.IsZero() {
+		stored.Timestamp = s.now()
+	}
+	s.books[stored.Symbol] = stored
+
+	ticker := bookTicker(stored)
+	matched, updated := s.matchRestingLocked(stored.Symbol, stored.Timestamp)
+	liquidated := s.markToMarketLocked(stored.Symbol, stored.Timestamp)
+
+	var bookUpdate *OrderBook
+	if hasBookSub {
+		bookUpdate = copyOrderBook(stored, bookSub.depth)
+	}
+	s.mu.Unlock()
+
+	for _, order := range updated {
+		s.emitOrder(order)
+	}
+	if positionCb != nil && matched != nil {
+		positionCb(matched)
+	}
+	if positionCb != nil && liquidated != nil {
+		positionCb(liquidated)
+	}
+	if tickerCb != nil && ticker != nil {
+		tickerCb(ticker)
+	}
+	if bookUpdate != nil {
+		bookSub.callback(bookUpdate)
+	}
+}
+
+// SetFundingRate задаёт ставку фандинга символа, которую вернёт GetFundingRate
+func (s *Sim) SetFundingRate(symbol string, rate float64) {
+	s.mu.Lock()
+	s.fundingRates[symbol] = rate
+	s.mu.Unlock()
+}
+
+// AddFundingPayment начисляет фандинг на баланс и записывает его в историю,
+// которую вернёт GetFundingPayments
+func (s *Sim) AddFundingPayment(payment FundingPayment) {
+	s.mu.Lock()
+	s.walletBalance += payment.Amount
+	s.fundingPayments = append(s.fundingPayments, payment)
+	s.mu.Unlock()
+}
+
+// GetFundingPayments возвращает начисления символа из AddFundingPayment за период [from, to]
+func (s *Sim) GetFundingPayments(ctx context.Context, symbol string, from, to time.Time) ([]FundingPayment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var payments []FundingPayment
+	for _, p := range s.fundingPayments {
+		if p.Symbol == symbol {
+			payments = append(payments, p)
+		}
+	}
+	return fundingPaymentsBetween(payments, from, to), nil
+}
+
+// WalletBalance возвращает баланс без учёта нереализованного PNL
+func (s *Sim) WalletBalance() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.walletBalance
+}
+
+// RealizedPnl возвращает реализованный PNL (без комиссий)
+func (s *Sim) RealizedPnl() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.realizedPnl
+}
+
+// FeesPaid возвращает сумму уплаченных комиссий
+func (s *Sim) FeesPaid() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.feesPaid
+}
+
+// Orders возвращает копию истории ордеров (включая лимитные)
+func (s *Sim) Orders() []Order {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	result := make([]Order, len(s.orders))
+	for i, o := range s.orders {
+		result[i] = *o
+	}
+	return result
+}
+
+// ============ Реализация Exchange ============
+
+// Connect не проверяет ключи - симулятору они не нужны
+func (s *Sim) Connect(apiKey, secret, passphrase string) error {
+	s.mu.Lock()
+	s.connected = true
+	s.mu.Unlock()
+	return nil
+}
+
+// GetName возвращает имя имитируемой биржи, чтобы симулятор
+// подставлялся в Engine вместо реального адаптера без изменения ключей
+func (s *Sim) GetName() string {
+	return s.cfg.Venue
+}
+
+// GetBalance возвращает equity: баланс + нереализованный PNL
+func (s *Sim) GetBalance(ctx context.Context) (float64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.equityLocked(), nil
+}
+
+func (s *Sim) GetTicker(ctx context.Context, symbol string) (*Ticker, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	book, ok := s.books[symbol]
+	if !ok {
+		return nil, fmt.Errorf("ticker not found for %s", symbol)
+	}
+
+	ticker := bookTicker(book)
+	if ticker == nil {
+		return nil, fmt.Errorf("ticker not found for %s", symbol)
+	}
+	return ticker, nil
+}
+
+func (s *Sim) GetOrderBook(ctx context.Context, symbol string, depth int) (*OrderBook, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	book, ok := s.books[symbol]
+	if !ok {
+		return nil, s.error(SimErrNoOrderBook, "no order book for "+symbol)
+	}
+	return copyOrderBook(book, depth), nil
+}
+
+// PlaceMarketOrder исполняет рыночный ордер проходом по стакану
+//
+// Ордер исполняется по принципу IOC: если ликвидности не хватает,
+// остаток отменяется и ордер получает статус partial.
+// Повтор уже принятого clientOrderID отклоняется, как на реальных биржах.
+func (s *Sim) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*Order, error) {
+	if s.cfg.Latency > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(s.cfg.Latency):
+		}
+	}
+
+	s.mu.Lock()
+	if _, dup := s.byClient[clientOrderID]; dup && clientOrderID != "" {
+		s.mu.Unlock()
+		return nil, s.error(SimErrRejected, "duplicate client order id: "+clientOrderID)
+	}
+	order, pos, err := s.executeLocked(symbol, normalizeSimSide(side), qty, 0, "", false)
+	if err == nil && clientOrderID != "" {
+		stored := s.orderByID[order.ID]
+		stored.ClientOrderID = clientOrderID
+		order.ClientOrderID = clientOrderID
+		s.byClient[clientOrderID] = stored
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.emitOrder(order)
+	s.emitPosition(pos)
+	return order, nil
+}
+
+// PlaceLimitOrder размещает лимитный ордер
+//
+// Часть, пересекающая стакан, исполняется сразу как тейкер. Остаток:
+// GTC и post-only ждут в книге, IOC отменяется, FOK без полного исполнения
+// отменяется целиком. Post-only ордер, пересекающий стакан, отменяется.
+func (s *Sim) PlaceLimitOrder(ctx context.Context, symbol, side string, qty, price float64, tif string) (*Order, error) {
+	tif, err := normalizeTimeInForce(tif)
+	if err != nil {
+		return nil, s.error(SimErrRejected, err.Error())
+	}
+	if price <= 0 || math.IsNaN(price) {
+		return nil, s.error(SimErrInvalidPrice, "limit price must be positive")
+	}
+
+	if s.cfg.Latency > 0 {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(s.cfg.Latency):
+		}
+	}
+
+	s.mu.Lock()
+	order, pos, err := s.executeLocked(symbol, normalizeSimSide(side), qty, price, tif, false)
+	s.mu.Unlock()
+
+	if err != nil {
+		return nil, err
+	}
+
+	s.emitOrder(order)
+	s.emitPosition(pos)
+	return order, nil
+}
+
+// CancelOrder отменяет активный лимитный ордер
+func (s *Sim) CancelOrder(ctx context.Context, symbol, orderID string) error {
+	s.mu.Lock()
+	for i, order := range s.resting {
+		if order.ID != orderID {
+			continue
+		}
+		order.Status = OrderStatusCancelled
+		order.UpdatedAt = s.now()
+		s.resting = append(s.resting[:i], s.resting[i+1:]...)
+
+		result := *order
+		s.mu.Unlock()
+
+		s.emitOrder(&result)
+		return nil
+	}
+	s.mu.Unlock()
+
+	return s.error(SimErrOrderNotFound, "active order not found: "+orderID)
+}
+
+// GetOrder возвращает состояние ордера
+func (s *Sim) GetOrder(ctx context.Context, symbol, orderID string) (*Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.orderByID[orderID]
+	if !ok {
+		return nil, orderNotFound(s.error(SimErrOrderNotFound, "order not found: "+orderID), SimErrOrderNotFound)
+	}
+	result := *order
+	return &result, nil
+}
+
+// GetOrderByClientID возвращает рыночный ордер по клиентскому ID
+func (s *Sim) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	order, ok := s.byClient[clientOrderID]
+	if !ok {
+		return nil, orderNotFound(s.error(SimErrOrderNotFound, "order not found: "+clientOrderID), SimErrOrderNotFound)
+	}
+	result := *order
+	return &result, nil
+}
+
+// GetOpenOrders возвращает активные лимитные ордера
+func (s *Sim) GetOpenOrders(ctx context.Context, symbol string) ([]*Order, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	orders := make([]*Order, 0, len(s.resting))
+	for _, order := range s.resting {
+		if symbol != "" && order.Symbol != symbol {
+			continue
+		}
+		result := *order
+		orders = append(orders, &result)
+	}
+	return orders, nil
+}
+
+func (s *Sim) GetOpenPositions(ctx context.Context) ([]*Position, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	symbols := make([]string, 0, len(s.positions))
+	for symbol := range s.positions {
+		symbols = append(symbols, symbol)
+	}
+	sort.Strings(symbols)
+
+	positions := make([]*Position, 0, len(symbols))
+	for _, symbol := range symbols {
+		positions = append(positions, s.positionSnapshotLocked(symbol, false))
+	}
+	return positions, nil
+}
+
+// ClosePosition закрывает позицию reduce-only ордером
+// side - сторона позиции ("long"/"short"), как и у реальных адаптеров
+func (s *Sim) ClosePosition(ctx context.Context, symbol, side string, qty float64) error {
+	closeSide := SideBuy
+	if side == SideLong || side == SideBuy {
+		closeSide = SideSell
+	}
+
+	s.mu.Lock()
+	order, pos, err := s.executeLocked(symbol, closeSide, qty, 0, "", true)
+	s.mu.Unlock()
+
+	if err != nil {
+		return err
+	}
+
+	s.emitOrder(order)
+	s.emitPosition(pos)
+	return nil
+}
+
+// SubscribeTicker регистрирует callback; тикеры приходят при каждом SetOrderBook
+func (s *Sim) SubscribeTicker(symbol string, callback func(*Ticker)) error {
+	s.callbackMu.Lock()
+	s.tickerCallbacks[symbol] = callback
+	s.callbackMu.Unlock()
+	return nil
+}
+
+// SubscribeOrderBook регистрирует callback; топ depth уровней приходит при каждом SetOrderBook
+func (s *Sim) SubscribeOrderBook(symbol string, depth int, callback func(*OrderBook)) error {
+	if depth <= 0 {
+		depth = DefaultOrderBookDepth
+	}
+
+	s.callbackMu.Lock()
+	s.bookCallbacks[symbol] = simBookSubscription{depth: depth, callback: callback}
+	s.callbackMu.Unlock()
+	return nil
+}
+
+// SubscribePositions регистрирует callback для изменений позиций и ликвидаций
+func (s *Sim) SubscribePositions(callback func(*Position)) error {
+	s.callbackMu.Lock()
+	s.positionCallback = callback
+	s.callbackMu.Unlock()
+	return nil
+}
+
+// SubscribeOrders регистрирует callback для изменений ордеров:
+// размещение, исполнение лимитных ордеров из книги, отмена и отклонение
+func (s *Sim) SubscribeOrders(callback func(*Order)) error {
+	s.callbackMu.Lock()
+	s.orderCallback = callback
+	s.callbackMu.Unlock()
+	return nil
+}
+
+func (s *Sim) GetTradingFee(ctx context.Context, symbol string) (float64, error) {
+	return s.cfg.TakerFee, nil
+}
+
+func (s *Sim) GetTradingFees(ctx context.Context, symbol string) (*TradingFees, error) {
+	return &TradingFees{
+		Symbol:    symbol,
+		Maker:     s.cfg.MakerFee,
+		Taker:     s.cfg.TakerFee,
+		Timestamp: s.now(),
+	}, nil
+}
+
+func (s *Sim) GetLimits(ctx context.Context, symbol string) (*Limits, error) {
+	limits := s.cfg.Limits
+	limits.Symbol = symbol
+	return &limits, nil
+}
+
+// GetInstrument возвращает спецификацию из SimConfig.Limits
+// Симулятор торгует в монетах: размер контракта 1, статус - торгуется
+func (s *Sim) GetInstrument(ctx context.Context, symbol string) (*Instrument, error) {
+	limits := s.cfg.Limits
+	return &Instrument{
+		Symbol:         symbol,
+		VenueSymbol:    symbol,
+		ContractValue:  1,
+		LotSize:        limits.QtyStep,
+		MinSize:        limits.MinOrderQty,
+		MaxSize:        limits.MaxOrderQty,
+		TickSize:       limits.PriceStep,
+		MinNotional:    limits.MinNotional,
+		MaxLeverage:    limits.MaxLeverage,
+		SettleCurrency: "USDT",
+		Status:         InstrumentStatusTrading,
+	}, nil
+}
+
+// GetFundingRate возвращает ставку из SetFundingRate (0 по умолчанию)
+// Начисления идут каждые DefaultFundingInterval от полуночи UTC
+func (s *Sim) GetFundingRate(ctx context.Context, symbol string) (*FundingRate, error) {
+	s.mu.Lock()
+	rate := s.fundingRates[symbol]
+	now := s.now()
+	s.mu.Unlock()
+
+	return &FundingRate{
+		Symbol:          symbol,
+		Rate:            rate,
+		NextFundingTime: now.UTC().Truncate(DefaultFundingInterval).Add(DefaultFundingInterval),
+		Interval:        DefaultFundingInterval,
+		Timestamp:       now,
+	}, nil
+}
+
+// SetLeverage задаёт плечо символа для новых позиций
+// Плечо выше Limits.MaxLeverage отклоняется, как на реальной бирже
+func (s *Sim) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	if leverage < 1 || (s.cfg.Limits.MaxLeverage > 0 && leverage > s.cfg.Limits.MaxLeverage) {
+		return s.error(SimErrInvalidLeverage, fmt.Sprintf("leverage %d out of range", leverage))
+	}
+
+	s.mu.Lock()
+	s.leverages[symbol] = leverage
+	s.mu.Unlock()
+	return nil
+}
+
+// SetMarginMode запоминает режим маржи символа
+// Маржа симулятора всегда изолированная; смена режима при открытой позиции отклоняется
+func (s *Sim) SetMarginMode(ctx context.Context, symbol, mode string) error {
+	if err := validateMarginMode(mode); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if pos := s.positions[symbol]; pos != nil && s.marginModes[symbol] != mode {
+		return s.error(SimErrPositionOpen, "cannot change margin mode with open position for "+symbol)
+	}
+	s.marginModes[symbol] = mode
+	return nil
+}
+
+// Leverage возвращает плечо символа
+func (s *Sim) Leverage(symbol string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.leverageLocked(symbol)
+}
+
+// MarginMode возвращает режим маржи символа, установленный через SetMarginMode
+func (s *Sim) MarginMode(symbol string) string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.marginModes[symbol]
+}
+
+// GetMarginSettings возвращает плечо и режим маржи символа (по умолчанию isolated)
+func (s *Sim) GetMarginSettings(ctx context.Context, symbol string) (int, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	mode := s.marginModes[symbol]
+	if mode == "" {
+		mode = MarginModeIsolated
+	}
+	return s.leverageLocked(symbol), mode, nil
+}
+
+// leverageLocked возвращает плечо символа (SetLeverage или cfg.Leverage)
+func (s *Sim) leverageLocked(symbol string) int {
+	if leverage, ok := s.leverages[symbol]; ok {
+		return leverage
+	}
+	return s.cfg.Leverage
+}
+
+func (s *Sim) Close() error {
+	s.callbackMu.Lock()
+	s.tickerCallbacks = make(map[string]func(*Ticker))
+	s.bookCallbacks = make(map[string]simBookSubscription)
+	s.positionCallback = nil
+	s.orderCallback = nil
+	s.callbackMu.Unlock()
+
+	s.mu.Lock()
+	s.connected = false
+	s.mu.Unlock()
+	return nil
+}
+
+// ============ Модель исполнения ============
+
+// executeLocked исполняет ордер (вызывать под s.mu)
+// price = 0 - рыночный ордер, иначе лимитный с time in force tif
+// Возвращает ордер и снимок позиции после исполнения (nil, если ничего не исполнено)
+func (s *Sim) executeLocked(symbol, side string, qty, price float64, tif string, reduceOnly bool) (*Order, *Position, error) {
+	if qty <= 0 || math.IsNaN(qty) {
+		return nil, nil, s.error(SimErrInvalidQty, "order qty must be positive")
+	}
+
+	// Инъекция отказов
+	if s.rejectCount > 0 {
+		s.rejectCount--
+		return nil, nil, s.error(SimErrRejected, "order rejected by simulator")
+	}
+	if s.rejectFn != nil {
+		if err := s.rejectFn(symbol, side, qty); err != nil {
+			return nil, nil, &ExchangeError{
+				Exchange: s.cfg.Venue,
+				Code:     SimErrRejected,
+				Message:  err.Error(),
+				Original: err,
+			}
+		}
+	}
+
+	book, ok := s.books[symbol]
+	if !ok {
+		return nil, nil, s.error(SimErrNoOrderBook, "no order book for "+symbol)
+	}
+
+	pos := s.positions[symbol]
+	signed := qty
+	if side == SideSell {
+		signed = -qty
+	}
+
+	// Reduce-only: не больше текущей позиции и только в сторону уменьшения
+	if reduceOnly {
+		if pos == nil || pos.size*signed >= 0 {
+			return nil, nil, s.error(SimErrInvalidQty, "no position to reduce for "+symbol)
+		}
+		if qty > math.Abs(pos.size) {
+			qty = math.Abs(pos.size)
+		}
+	}
+
+	limit := price > 0
+	levels := book.Asks
+	if side == SideSell {
+		levels = book.Bids
+	}
+	if limit {
+		levels = crossingLevels(levels, side, price)
+	}
+
+	var filled, avgPrice float64
+	if tif != TimeInForcePostOnly {
+		filled, avgPrice = walkLevels(levels, qty)
+	}
+	if tif == TimeInForceFOK && filled < qty {
+		filled, avgPrice = 0, 0
+	}
+	if !limit && filled == 0 {
+		return nil, nil, s.error(SimErrInsufficientLiquidity, "no liquidity for "+symbol)
+	}
+
+	// Проверка маржи: исполненная часть, а для ордера в книге - весь объём по его цене
+	checkQty, checkPrice := filled, avgPrice
+	resting := limit && filled < qty && (tif == TimeInForceGTC || (tif == TimeInForcePostOnly && len(levels) == 0))
+	if resting {
+		checkQty, checkPrice = qty, math.Max(price, avgPrice)
+	}
+	if checkQty > 0 {
+		if err := s.checkMarginLocked(symbol, side, checkQty, checkPrice, s.cfg.TakerFee); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	now := s.now()
+	var fee float64
+	if filled > 0 {
+		if s.cfg.ConsumeLiquidity {
+			if side == SideSell {
+				book.Bids = consumeLevels(book.Bids, filled)
+			} else {
+				book.Asks = consumeLevels(book.Asks, filled)
+			}
+		}
+		fee = s.fillLocked(symbol, side, filled, avgPrice, s.cfg.TakerFee, now)
+	}
+
+	s.orderSeq++
+	order := &Order{
+		ID:           s.cfg.Venue + "-sim-" + strconv.FormatInt(s.orderSeq, 10),
+		Symbol:       symbol,
+		Side:         side,
+		Type:         OrderTypeMarket,
+		Quantity:     qty,
+		FilledQty:    filled,
+		AvgFillPrice: avgPrice,
+		Fee:          fee,
+		Status:       OrderStatusFilled,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	switch {
+	case !limit:
+		if filled < qty {
+			order.Status = OrderStatusPartial
+		}
+	default:
+		order.Type = OrderTypeLimit
+		order.Price = price
+		order.TimeInForce = tif
+		switch {
+		case filled >= qty:
+			order.Status = OrderStatusFilled
+		case resting && filled > 0:
+			order.Status = OrderStatusPartial
+		case resting:
+			order.Status = OrderStatusNew
+		default:
+			order.Status = OrderStatusCancelled
+		}
+		if resting {
+			s.resting = append(s.resting, order)
+		}
+	}
+
+	s.orders = append(s.orders, order)
+	s.orderByID[order.ID] = order
+
+	result := *order
+	if filled == 0 {
+		return &result, nil, nil
+	}
+	return &result, s.positionSnapshotLocked(symbol, false), nil
+}
+
+// checkMarginLocked проверяет маржу для части ордера, увеличивающей позицию
+func (s *Sim) checkMarginLocked(symbol, side string, qty, price, feeRate float64) error {
+	signed := qty
+	if side == SideSell {
+		signed = -qty
+	}
+
+	increase := qty
+	if pos := s.positions[symbol]; pos != nil && pos.size*signed < 0 {
+		increase = math.Max(0, qty-math.Abs(pos.size))
+	}
+	if increase == 0 {
+		return nil
+	}
+
+	required := increase*price/float64(s.leverageLocked(symbol)) + qty*price*feeRate
+	if available := s.availableMarginLocked(); required > available {
+		return s.error(SimErrInsufficientMargin,
+			fmt.Sprintf("insufficient margin: required %.2f, available %.2f", required, available))
+	}
+	return nil
+}
+
+// fillLocked списывает комиссию и применяет исполнение к позиции
+// Возвращает списанную комиссию
+func (s *Sim) fillLocked(symbol, side string, qty, price, feeRate float64, now time.Time) float64 {
+	fee := qty * price * feeRate
+	s.walletBalance -= fee
+	s.feesPaid += fee
+	s.applyFillLocked(symbol, side, qty, price, now)
+	return fee
+}
+
+// matchRestingLocked исполняет лимитные ордера символа, цену которых пересёк стакан
+// Исполнение по цене ордера с комиссией мейкера. Возвращает снимок позиции (nil,
+// если ничего не исполнено) и копии изменившихся ордеров
+func (s *Sim) matchRestingLocked(symbol string, now time.Time) (*Position, []*Order) {
+	book := s.books[symbol]
+	matched := false
+	var updated []*Order
+
+	active := s.resting[:0]
+	for _, order := range s.resting {
+		if order.Symbol != symbol {
+			active = append(active, order)
+			continue
+		}
+
+		levels := book.Asks
+		if order.Side == SideSell {
+			levels = book.Bids
+		}
+		remaining := order.Quantity - order.FilledQty
+		filled, _ := walkLevels(crossingLevels(levels, order.Side, order.Price), remaining)
+		if filled == 0 {
+			active = append(active, order)
+			continue
+		}
+
+		// Маржу могли съесть другие позиции - ордер отклоняется, как при размещении
+		if err := s.checkMarginLocked(symbol, order.Side, filled, order.Price, s.cfg.MakerFee); err != nil {
+			order.Status = OrderStatusRejected
+			order.UpdatedAt = now
+			updated = append(updated, copyOrder(order))
+			continue
+		}
+
+		if s.cfg.ConsumeLiquidity {
+			if order.Side == SideSell {
+				book.Bids = consumeLevels(book.Bids, filled)
+			} else {
+				book.Asks = consumeLevels(book.Asks, filled)
+			}
+		}
+		order.Fee += s.fillLocked(symbol, order.Side, filled, order.Price, s.cfg.MakerFee, now)
+		matched = true
+
+		order.AvgFillPrice = (order.AvgFillPrice*order.FilledQty + filled*order.Price) / (order.FilledQty + filled)
+		order.UpdatedAt = now
+		if filled >= remaining {
+			order.FilledQty = order.Quantity
+			order.Status = OrderStatusFilled
+			updated = append(updated, copyOrder(order))
+			continue
+		}
+		order.FilledQty += filled
+		order.Status = OrderStatusPartial
+		updated = append(updated, copyOrder(order))
+		active = append(active, order)
+	}
+	for i := len(active); i < len(s.resting); i++ {
+		s.resting[i] = nil
+	}
+	s.resting = active
+
+	if !matched {
+		return nil, updated
+	}
+	return s.positionSnapshotLocked(symbol, false), updated
+}
+
+// copyOrder возвращает копию ордера для отправки подписчику вне s.mu
+func copyOrder(order *Order) *Order {
+	result := *order
+	return &result
+}
+
+// applyFillLocked обновляет позицию после исполнения (one-way netting)
+func (s *Sim) applyFillLocked(symbol, side string, qty, price float64, now time.Time) {
+	signed := qty
+	if side == SideSell {
+		signed = -qty
+	}
+
+	pos := s.positions[symbol]
+	if pos == nil {
+		pos = &simPosition{leverage: s.leverageLocked(symbol)}
+		s.positions[symbol] = pos
+	}
+
+	leverage := float64(pos.leverage)
+
+	switch {
+	case pos.size == 0 || pos.size*signed > 0:
+		// Открытие или увеличение позиции: средневзвешенная цена входа
+		newSize := pos.size + signed
+		pos.entryPrice = (math.Abs(pos.size)*pos.entryPrice + qty*price) / math.Abs(newSize)
+		pos.size = newSize
+		pos.margin += qty * price / leverage
+
+	default:
+		// Уменьшение, закрытие или разворот
+		closeQty := math.Min(qty, math.Abs(pos.size))
+		pnl := (price - pos.entryPrice) * closeQty
+		if pos.size < 0 {
+			pnl = -pnl
+		}
+		s.realizedPnl += pnl
+		s.walletBalance += pnl
+
+		remaining := math.Abs(pos.size) - closeQty
+		if remaining > 0 {
+			pos.margin *= remaining / math.Abs(pos.size)
+			pos.size = math.Copysign(remaining, pos.size)
+		} else {
+			// Позиция закрыта; остаток ордера открывает противоположную
+			rest := qty - closeQty
+			pos.size = 0
+			pos.margin = 0
+			pos.entryPrice = 0
+			if rest > 0 {
+				pos.size = math.Copysign(rest, signed)
+				pos.entryPrice = price
+				pos.margin = rest * price / leverage
+			}
+		}
+	}
+
+	pos.markPrice = price
+	pos.updatedAt = now
+
+	if pos.size == 0 {
+		delete(s.positions, symbol)
+	}
+}
+
+// markToMarketLocked обновляет mark price и ликвидирует позицию при необходимости
+// Возвращает снимок ликвидированной позиции или nil
+func (s *Sim) markToMarketLocked(symbol string, now time.Time) *Position {
+	pos, ok := s.positions[symbol]
+	if !ok {
+		return nil
+	}
+
+	book := s.books[symbol]
+	mark := bookMid(book)
+	if mark == 0 {
+		return nil
+	}
+	pos.markPrice = mark
+	pos.updatedAt = now
+
+	liqPrice := s.liquidationPriceLocked(pos)
+	if (pos.size > 0 && mark > liqPrice) || (pos.size < 0 && mark < liqPrice) {
+		return nil
+	}
+
+	// Ликвидация: изолированная маржа позиции теряется полностью
+	snapshot := s.positionSnapshotLocked(symbol, true)
+	s.walletBalance -= pos.margin
+	s.realizedPnl -= pos.margin
+	delete(s.positions, symbol)
+
+	return snapshot
+}
+
+// liquidationPriceLocked рассчитывает цену ликвидации изолированной позиции
+func (s *Sim) liquidationPriceLocked(pos *simPosition) float64 {
+	lev := float64(pos.leverage)
+	mmr := s.cfg.MaintenanceMarginRate
+	if pos.size > 0 {
+		return pos.entryPrice * (1 - 1/lev + mmr)
+	}
+	return pos.entryPrice * (1 + 1/lev - mmr)
+}
+
+// positionSnapshotLocked формирует Position для внешнего мира
+func (s *Sim) positionSnapshotLocked(symbol string, liquidated bool) *Position {
+	pos, ok := s.positions[symbol]
+	if !ok {
+		return &Position{Symbol: symbol, UpdatedAt: s.now()}
+	}
+
+	side := SideLong
+	if pos.size < 0 {
+		side = SideShort
+	}
+
+	return &Position{
+		Symbol:        symbol,
+		Side:          side,
+		Size:          math.Abs(pos.size),
+		EntryPrice:    pos.entryPrice,
+		MarkPrice:     pos.markPrice,
+		Leverage:      pos.leverage,
+		UnrealizedPnl: (pos.markPrice - pos.entryPrice) * pos.size,
+		Liquidation:   liquidated,
+		UpdatedAt:     pos.updatedAt,
+	}
+}
+
+// equityLocked возвращает баланс с учётом нереализованного PNL
+func (s *Sim) equityLocked() float64 {
+	equity := s.walletBalance
+	for _, pos := range s.positions {
+		equity += (pos.markPrice - pos.entryPrice) * pos.size
+	}
+	return equity
+}
+
+// availableMarginLocked возвращает свободную маржу
+func (s *Sim) availableMarginLocked() float64 {
+	used := 0.0
+	for _, pos := range s.positions {
+		used += pos.margin
+	}
+	return s.equityLocked() - used
+}
+
+// emitPosition отправляет обновление позиции подписчику
+func (s *Sim) emitPosition(pos *Position) {
+	if pos == nil {
+		return
+	}
+
+	s.callbackMu.RLock()
+	callback := s.positionCallback
+	s.callbackMu.RUnlock()
+
+	if callback != nil {
+		callback(pos)
+	}
+}
+
+// emitOrder отправляет обновление ордера подписчику
+func (s *Sim) emitOrder(order *Order) {
+	if order == nil {
+		return
+	}
+
+	s.callbackMu.RLock()
+	callback := s.orderCallback
+	s.callbackMu.RUnlock()
+
+	if callback != nil {
+		result := *order
+		callback(&result)
+	}
+}
+
+func (s *Sim) error(code, message string) *ExchangeError {
+	return &ExchangeError{
+		Exchange: s.cfg.Venue,
+		Code:     code,
+		Message:  message,
+	}
+}
+
+// ============ Вспомогательные функции ============
+
+// normalizeSimSide приводит сторону ордера к buy/sell
+func normalizeSimSide(side string) string {
+	switch strings.ToLower(side) {
+	case SideSell, SideShort:
+		return SideSell
+	default:
+		return SideBuy
+	}
+}
+
+// walkLevels проходит по уровням стакана и возвращает исполненный объём и среднюю цену
+func walkLevels(levels []PriceLevel, qty float64) (filled, avgPrice float64) {
+	var notional float64
+	for _, level := range levels {
+		if filled >= qty {
+			break
+		}
+		take := math.Min(level.Volume, qty-filled)
+		filled += take
+		notional += take * level.Price
+	}
+	if filled == 0 {
+		return 0, 0
+	}
+	return filled, notional / filled
+}
+
+// crossingLevels возвращает уровни, доступные лимитному ордеру:
+// для покупки - asks не дороже price, для продажи - bids не дешевле price
+func crossingLevels(levels []PriceLevel, side string, price float64) []PriceLevel {
+	n := 0
+	for _, level := range levels {
+		if (side == SideSell && level.Price < price) || (side != SideSell && level.Price > price) {
+			break
+		}
+		n++
+	}
+	return levels[:n]
+}
+
+// consumeLevels убирает исполненный объём из уровней стакана
+func consumeLevels(levels []PriceLevel, qty float64) []PriceLevel {
+	for len(levels) > 0 && qty > 0 {
+		if levels[0].Volume > qty {
+			levels[0].Volume -= qty
+			return levels
+		}
+		qty -= levels[0].Volume
+		levels = levels[1:]
+	}
+	return levels
+}
+
+// copyOrderBook возвращает копию стакана, ограниченную depth уровнями (0 = все)
+func copyOrderBook(book *OrderBook, depth int) *OrderBook {
+	bids := book.Bids
+	asks := book.Asks
+	if depth > 0 {
+		if len(bids) > depth {
+			bids = bids[:depth]
+		}
+		if len(asks) > depth {
+			asks = asks[:depth]
+		}
+	}
+
+	result := &OrderBook{
+		Symbol:    book.Symbol,
+		Bids:      make([]PriceLevel, len(bids)),
+		Asks:      make([]PriceLevel, len(asks)),
+		Timestamp: book.Timestamp,
+	}
+	copy(result.Bids, bids)
+	copy(result.Asks, asks)
+	return result
+}
+
+// bookTicker строит тикер из лучших уровней стакана
+func bookTicker(book *OrderBook) *Ticker {
+	if book == nil || len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return nil
+	}
+	return &Ticker{
+		Symbol:    book.Symbol,
+		BidPrice:  book.Bids[0].Price,
+		AskPrice:  book.Asks[0].Price,
+		LastPrice: bookMid(book),
+		Timestamp: book.Timestamp,
+	}
+}
+
+// bookMid возвращает середину спреда (0 если стакан пуст)
+func bookMid(book *OrderBook) float64 {
+	if book == nil || len(book.Bids) == 0 || len(book.Asks) == 0 {
+		return 0
+	}
+	return (book.Bids[0].Price + book.Asks[0].Price) / 2
+}
@@ -0,0 +1,337 @@
+package exchange
+
+import (
+	"context"
+	"errors"
+	"math"
+	"testing"
+)
+
+func newTestSim() *Sim {
+	cfg := DefaultSimConfig("bybit")
+	cfg.InitialBalance = 1000
+	sim := NewSim(cfg)
+	sim.SetOrderBook(&OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   []PriceLevel{{Price: 99, Volume: 1}, {Price: 98, Volume: 2}},
+		Asks:   []PriceLevel{{Price: 101, Volume: 1}, {Price: 102, Volume: 2}},
+	})
+	return sim
+}
+
+func almostEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+// TestSimMarketOrder_WalksBook проверяет исполнение по нескольким уровням и комиссию
+func TestSimMarketOrder_WalksBook(t *testing.T) {
+	sim := newTestSim()
+
+	order, err := sim.PlaceMarketOrder(context.Background(), "BTCUSDT", SideBuy, 2, "")
+	if err != nil {
+		t.Fatalf("PlaceMarketOrder: %v", err)
+	}
+
+	if order.Status != OrderStatusFilled || !almostEqual(order.FilledQty, 2) {
+		t.Fatalf("expected full fill of 2, got %s %.4f", order.Status, order.FilledQty)
+	}
+	if !almostEqual(order.AvgFillPrice, 101.5) {
+		t.Fatalf("expected avg price 101.5, got %.4f", order.AvgFillPrice)
+	}
+	if !almostEqual(sim.FeesPaid(), 203*0.00055) {
+		t.Fatalf("unexpected fees: %.6f", sim.FeesPaid())
+	}
+
+	// Ликвидность израсходована: следующий ордер исполняется по 102
+	order, err = sim.PlaceMarketOrder(context.Background(), "BTCUSDT", SideBuy, 5, "")
+	if err != nil {
+		t.Fatalf("PlaceMarketOrder: %v", err)
+	}
+	if order.Status != OrderStatusPartial || !almostEqual(order.FilledQty, 1) {
+		t.Fatalf("expected partial fill of 1, got %s %.4f", order.Status, order.FilledQty)
+	}
+}
+
+// TestSimClosePosition_RealizesPnl проверяет закрытие позиции и реализованный PNL
+func TestSimClosePosition_RealizesPnl(t *testing.T) {
+	sim := newTestSim()
+	ctx := context.Background()
+
+	if _, err := sim.PlaceMarketOrder(ctx, "BTCUSDT", SideSell, 1, ""); err != nil {
+		t.Fatalf("PlaceMarketOrder: %v", err)
+	}
+
+	positions, _ := sim.GetOpenPositions(ctx)
+	if len(positions) != 1 || positions[0].Side != SideShort || !almostEqual(positions[0].EntryPrice, 99) {
+		t.Fatalf("unexpected positions: %+v", positions)
+	}
+
+	if err := sim.ClosePosition(ctx, "BTCUSDT", SideShort, 1); err != nil {
+		t.Fatalf("ClosePosition: %v", err)
+	}
+
+	positions, _ = sim.GetOpenPositions(ctx)
+	if len(positions) != 0 {
+		t.Fatalf("expected no positions, got %d", len(positions))
+	}
+	if !almostEqual(sim.RealizedPnl(), -2) {
+		t.Fatalf("expected realized pnl -2, got %.4f", sim.RealizedPnl())
+	}
+}
+
+// TestSimRejectNext проверяет инъекцию отказов
+func TestSimRejectNext(t *testing.T) {
+	sim := newTestSim()
+	sim.RejectNext(1)
+
+	_, err := sim.PlaceMarketOrder(context.Background(), "BTCUSDT", SideBuy, 0.1, "")
+	var exchErr *ExchangeError
+	if !errors.As(err, &exchErr) || exchErr.Code != SimErrRejected {
+		t.Fatalf("expected sim rejection, got %v", err)
+	}
+
+	if _, err := sim.PlaceMarketOrder(context.Background(), "BTCUSDT", SideBuy, 0.1, ""); err != nil {
+		t.Fatalf("expected second order to pass, got %v", err)
+	}
+}
+
+// TestSimClientOrderID проверяет поиск ордера по клиентскому ID и отказ на повтор ID
+func TestSimClientOrderID(t *testing.T) {
+	sim := newTestSim()
+	ctx := context.Background()
+
+	order, err := sim.PlaceMarketOrder(ctx, "BTCUSDT", SideBuy, 1, "a1t1p0n0l")
+	if err != nil {
+		t.Fatalf("PlaceMarketOrder: %v", err)
+	}
+
+	found, err := sim.GetOrderByClientID(ctx, "BTCUSDT", "a1t1p0n0l")
+	if err != nil || found.ID != order.ID || found.ClientOrderID != "a1t1p0n0l" {
+		t.Fatalf("expected order %s by client id, got %+v (%v)", order.ID, found, err)
+	}
+
+	var exchErr *ExchangeError
+	if _, err := sim.PlaceMarketOrder(ctx, "BTCUSDT", SideBuy, 1, "a1t1p0n0l"); !errors.As(err, &exchErr) || exchErr.Code != SimErrRejected {
+		t.Fatalf("expected duplicate client id rejected, got %v", err)
+	}
+	if _, err := sim.GetOrderByClientID(ctx, "BTCUSDT", "unknown"); !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("expected ErrOrderNotFound, got %v", err)
+	}
+}
+
+// TestSimInsufficientMargin проверяет отказ при нехватке маржи
+func TestSimInsufficientMargin(t *testing.T) {
+	cfg := DefaultSimConfig("okx")
+	cfg.InitialBalance = 10
+	sim := NewSim(cfg)
+	sim.SetOrderBook(&OrderBook{
+		Symbol: "ETHUSDT",
+		Bids:   []PriceLevel{{Price: 1000, Volume: 10}},
+		Asks:   []PriceLevel{{Price: 1001, Volume: 10}},
+	})
+
+	_, err := sim.PlaceMarketOrder(context.Background(), "ETHUSDT", SideBuy, 1, "")
+	var exchErr *ExchangeError
+	if !errors.As(err, &exchErr) || exchErr.Code != SimErrInsufficientMargin {
+		t.Fatalf("expected insufficient margin, got %v", err)
+	}
+}
+
+// TestSimLiquidation проверяет ликвидацию и событие в SubscribePositions
+func TestSimLiquidation(t *testing.T) {
+	sim := newTestSim()
+
+	var liquidated *Position
+	sim.SubscribePositions(func(p *Position) {
+		if p.Liquidation {
+			liquidated = p
+		}
+	})
+
+	if _, err := sim.PlaceMarketOrder(context.Background(), "BTCUSDT", SideBuy, 1, ""); err != nil {
+		t.Fatalf("PlaceMarketOrder: %v", err)
+	}
+
+	// Цена ликвидации лонга 10x: 101 * (1 - 0.1 + 0.005) = 91.405
+	sim.SetOrderBook(&OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   []PriceLevel{{Price: 90, Volume: 1}},
+		Asks:   []PriceLevel{{Price: 91, Volume: 1}},
+	})
+
+	if liquidated == nil || liquidated.Side != SideLong {
+		t.Fatal("expected liquidation event for long position")
+	}
+	positions, _ := sim.GetOpenPositions(context.Background())
+	if len(positions) != 0 {
+		t.Fatalf("expected position to be removed after liquidation, got %d", len(positions))
+	}
+}
+
+// TestNewExchange_Sim проверяет создание симулятора через фабрику
+func TestNewExchange_Sim(t *testing.T) {
+	exch, err := NewExchange("sim:bybit")
+	if err != nil {
+		t.Fatalf("NewExchange: %v", err)
+	}
+	if _, ok := exch.(*Sim); !ok || exch.GetName() != "bybit" {
+		t.Fatalf("expected sim for bybit, got %T %s", exch, exch.GetName())
+	}
+
+	if _, err := NewExchange("sim:unknown"); err == nil {
+		t.Fatal("expected error for unsupported venue")
+	}
+}
+
+// TestSimLimitOrder_TimeInForce проверяет немедленное исполнение IOC/FOK и отмену post-only
+func TestSimLimitOrder_TimeInForce(t *testing.T) {
+	ctx := context.Background()
+
+	// IOC: берёт уровни не дороже цены, остаток отменяется
+	sim := newTestSim()
+	order, err := sim.PlaceLimitOrder(ctx, "BTCUSDT", SideBuy, 2, 101, TimeInForceIOC)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder IOC: %v", err)
+	}
+	if order.Status != OrderStatusCancelled || !almostEqual(order.FilledQty, 1) || !almostEqual(order.AvgFillPrice, 101) {
+		t.Fatalf("IOC: expected cancelled with 1 filled at 101, got %s %.4f @ %.4f", order.Status, order.FilledQty, order.AvgFillPrice)
+	}
+
+	// FOK: объёма не хватает - не исполняется ничего
+	sim = newTestSim()
+	order, err = sim.PlaceLimitOrder(ctx, "BTCUSDT", SideBuy, 2, 101, TimeInForceFOK)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder FOK: %v", err)
+	}
+	if order.Status != OrderStatusCancelled || order.FilledQty != 0 {
+		t.Fatalf("FOK: expected cancelled without fills, got %s %.4f", order.Status, order.FilledQty)
+	}
+	if positions, _ := sim.GetOpenPositions(ctx); len(positions) != 0 {
+		t.Fatalf("FOK: expected no position, got %d", len(positions))
+	}
+
+	// Post-only, пересекающий стакан, отменяется без исполнения
+	order, err = sim.PlaceLimitOrder(ctx, "BTCUSDT", SideSell, 1, 99, TimeInForcePostOnly)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder post-only: %v", err)
+	}
+	if order.Status != OrderStatusCancelled || order.FilledQty != 0 {
+		t.Fatalf("post-only: expected cancelled without fills, got %s %.4f", order.Status, order.FilledQty)
+	}
+}
+
+// TestSimLimitOrder_RestsAndFillsAsMaker проверяет ордер в книге: исполнение по своей цене
+// с комиссией мейкера, когда стакан пересекает цену
+func TestSimLimitOrder_RestsAndFillsAsMaker(t *testing.T) {
+	sim := newTestSim()
+	ctx := context.Background()
+
+	order, err := sim.PlaceLimitOrder(ctx, "BTCUSDT", SideBuy, 1, 100, TimeInForcePostOnly)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder: %v", err)
+	}
+	if order.Status != OrderStatusNew {
+		t.Fatalf("expected new order, got %s", order.Status)
+	}
+
+	open, _ := sim.GetOpenOrders(ctx, "BTCUSDT")
+	if len(open) != 1 || open[0].ID != order.ID {
+		t.Fatalf("expected order in open orders, got %v", open)
+	}
+
+	// Продавцы опускают ask до 99.5 - ордер по 100 исполняется частично (0.4 на уровне)
+	sim.SetOrderBook(&OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   []PriceLevel{{Price: 99, Volume: 1}},
+		Asks:   []PriceLevel{{Price: 99.5, Volume: 0.4}, {Price: 101, Volume: 1}},
+	})
+
+	state, err := sim.GetOrder(ctx, "BTCUSDT", order.ID)
+	if err != nil {
+		t.Fatalf("GetOrder: %v", err)
+	}
+	if state.Status != OrderStatusPartial || !almostEqual(state.FilledQty, 0.4) || !almostEqual(state.AvgFillPrice, 100) {
+		t.Fatalf("expected partial 0.4 @ 100, got %s %.4f @ %.4f", state.Status, state.FilledQty, state.AvgFillPrice)
+	}
+	if !almostEqual(sim.FeesPaid(), 0.4*100*0.0002) {
+		t.Fatalf("expected maker fee, got %.6f", sim.FeesPaid())
+	}
+
+	// Отмена остатка: исполненная часть остаётся в позиции
+	if err := sim.CancelOrder(ctx, "BTCUSDT", order.ID); err != nil {
+		t.Fatalf("CancelOrder: %v", err)
+	}
+	state, _ = sim.GetOrder(ctx, "BTCUSDT", order.ID)
+	if state.Status != OrderStatusCancelled || !almostEqual(state.FilledQty, 0.4) {
+		t.Fatalf("expected cancelled with 0.4 filled, got %s %.4f", state.Status, state.FilledQty)
+	}
+	if open, _ := sim.GetOpenOrders(ctx, ""); len(open) != 0 {
+		t.Fatalf("expected no open orders, got %d", len(open))
+	}
+
+	positions, _ := sim.GetOpenPositions(ctx)
+	if len(positions) != 1 || !almostEqual(positions[0].Size, 0.4) || !almostEqual(positions[0].EntryPrice, 100) {
+		t.Fatalf("expected long 0.4 @ 100, got %+v", positions)
+	}
+
+	var exchErr *ExchangeError
+	if err := sim.CancelOrder(ctx, "BTCUSDT", order.ID); !errors.As(err, &exchErr) || exchErr.Code != SimErrOrderNotFound {
+		t.Fatalf("expected %s on second cancel, got %v", SimErrOrderNotFound, err)
+	}
+}
+
+// TestSimSubscribeOrders проверяет поток ордеров: исполнение, комиссию и исполнение из книги
+func TestSimSubscribeOrders(t *testing.T) {
+	sim := newTestSim()
+	ctx := context.Background()
+
+	var updates []*Order
+	sim.SubscribeOrders(func(order *Order) { updates = append(updates, order) })
+
+	if _, err := sim.PlaceMarketOrder(ctx, "BTCUSDT", SideBuy, 1, ""); err != nil {
+		t.Fatalf("PlaceMarketOrder: %v", err)
+	}
+	if len(updates) != 1 || updates[0].Status != OrderStatusFilled || !almostEqual(updates[0].Fee, 101*0.00055) {
+		t.Fatalf("expected filled update with taker fee, got %+v", updates)
+	}
+
+	order, err := sim.PlaceLimitOrder(ctx, "BTCUSDT", SideBuy, 1, 100, TimeInForceGTC)
+	if err != nil {
+		t.Fatalf("PlaceLimitOrder: %v", err)
+	}
+
+	sim.SetOrderBook(&OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   []PriceLevel{{Price: 99, Volume: 1}},
+		Asks:   []PriceLevel{{Price: 100, Volume: 1}},
+	})
+
+	last := updates[len(updates)-1]
+	if last.ID != order.ID || last.Status != OrderStatusFilled {
+		t.Fatalf("expected resting order filled from book, got %+v", last)
+	}
+	if !almostEqual(last.Fee, 100*sim.cfg.MakerFee) {
+		t.Fatalf("expected maker fee %.6f, got %.6f", 100*sim.cfg.MakerFee, last.Fee)
+	}
+}
+
+// TestSimSubscribeOrderBook проверяет поток стаканов с ограничением глубины
+func TestSimSubscribeOrderBook(t *testing.T) {
+	sim := newTestSim()
+
+	var last *OrderBook
+	sim.SubscribeOrderBook("BTCUSDT", 1, func(book *OrderBook) { last = book })
+
+	sim.SetOrderBook(&OrderBook{
+		Symbol: "BTCUSDT",
+		Bids:   []PriceLevel{{Price: 98, Volume: 2}, {Price: 99, Volume: 1}},
+		Asks:   []PriceLevel{{Price: 101, Volume: 1}, {Price: 102, Volume: 2}},
+	})
+
+	if last == nil || len(last.Bids) != 1 || len(last.Asks) != 1 {
+		t.Fatalf("expected top-1 book, got %+v", last)
+	}
+	if last.Bids[0].Price != 99 || last.Asks[0].Price != 101 {
+		t.Fatalf("expected sorted best levels, got %+v", last)
+	}
+}
@@ -1,8 +1,11 @@
 package exchange
 
 import (
+	"bytes"
+	"compress/gzip"
 	"context"
 	"fmt"
+	"io"
 	"log"
 	"sync"
 	"sync/atomic"
@@ -107,6 +110,9 @@ type WSReconnectManager struct {
 	// Счётчик попыток переподключения
 	retryCount int32 // atomic
 
+	// Время последнего входящего сообщения или pong, unix nano
+	lastMessage int64 // atomic
+
 	// Каналы управления
 	closeChan   chan struct{}
 	messageChan chan []byte
@@ -123,6 +129,9 @@ type WSReconnectManager struct {
 
 	// Аутентификация (для приватных каналов)
 	authFunc func(*websocket.Conn) error
+
+	// URL вычисляется при каждом подключении (например, с listenKey)
+	urlFunc func() (string, error)
 }
 
 // NewWSReconnectManager создаёт новый менеджер переподключений
@@ -163,6 +172,12 @@ func (m *WSReconnectManager) SetAuthFunc(authFunc func(*websocket.Conn) error) {
 	m.authFunc = authFunc
 }
 
+// SetURLFunc устанавливает функцию получения URL, вызываемую при каждом подключении
+// Используется биржами, где приватный канал адресуется временным ключом (listenKey)
+func (m *WSReconnectManager) SetURLFunc(urlFunc func() (string, error)) {
+	m.urlFunc = urlFunc
+}
+
 // AddSubscription добавляет подписку для восстановления после переподключения
 func (m *WSReconnectManager) AddSubscription(sub interface{}) {
 	m.subscriptionsMu.Lock()
@@ -233,11 +248,27 @@ func (m *WSReconnectManager) dial() error {
 		HandshakeTimeout: m.config.ConnectTimeout,
 	}
 
-	conn, _, err := dialer.DialContext(ctx, m.wsURL, nil)
+	wsURL := m.wsURL
+	if m.urlFunc != nil {
+		u, err := m.urlFunc()
+		if err != nil {
+			return fmt.Errorf("url error: %w", err)
+		}
+		wsURL = u
+	}
+
+	conn, _, err := dialer.DialContext(ctx, wsURL, nil)
 	if err != nil {
 		return fmt.Errorf("dial error: %w", err)
 	}
 
+	// Pong на ping из pingPump подтверждает живость соединения без сообщений (приватные каналы)
+	conn.SetPongHandler(func(string) error {
+		m.touch()
+		return nil
+	})
+	m.touch()
+
 	m.connMu.Lock()
 	m.conn = conn
 	m.connMu.Unlock()
@@ -314,6 +345,7 @@ func (m *WSReconnectManager) readPump() {
 			m.handleDisconnect(err)
 			return
 		}
+		m.touch()
 
 		// Отправляем сообщение в callback
 		m.callbackMu.RLock()
@@ -482,6 +514,55 @@ func (m *WSReconnectManager) Send(msg interface{}) error {
 	return conn.WriteJSON(msg)
 }
 
+// SendText отправляет текстовое сообщение как есть (например, "Pong" в ответ на "Ping")
+func (m *WSReconnectManager) SendText(msg string) error {
+	if m.GetState() != WSStateConnected {
+		return fmt.Errorf("not connected (state: %s)", m.GetState())
+	}
+
+	m.connMu.RLock()
+	conn := m.conn
+	m.connMu.RUnlock()
+
+	if conn == nil {
+		return fmt.Errorf("no connection")
+	}
+
+	return conn.WriteMessage(websocket.TextMessage, []byte(msg))
+}
+
+// Reconnect разрывает текущее соединение; переподключение выполнит readPump
+// Используется, когда нужно подключиться заново с новым URL (истёкший listenKey)
+func (m *WSReconnectManager) Reconnect() {
+	m.connMu.RLock()
+	conn := m.conn
+	m.connMu.RUnlock()
+
+	if conn != nil {
+		conn.Close()
+	}
+}
+
+// decompressWSMessage распаковывает gzip-сообщение WebSocket (несжатые возвращаются как есть)
+// HTX и BingX сжимают сообщения gzip
+func decompressWSMessage(message []byte) []byte {
+	if len(message) < 2 || message[0] != 0x1f || message[1] != 0x8b {
+		return message
+	}
+
+	reader, err := gzip.NewReader(bytes.NewReader(message))
+	if err != nil {
+		return message
+	}
+	defer reader.Close()
+
+	data, err := io.ReadAll(reader)
+	if err != nil {
+		return message
+	}
+	return data
+}
+
 // Close закрывает WebSocket соединение и останавливает переподключение
 func (m *WSReconnectManager) Close() error {
 	// Проверяем, не закрыт ли уже
@@ -510,3 +591,18 @@ func (m *WSReconnectManager) Close() error {
 func (m *WSReconnectManager) GetRetryCount() int {
 	return int(atomic.LoadInt32(&m.retryCount))
 }
+
+// LastMessageAt возвращает время последнего входящего сообщения или pong
+// Нулевое время - соединение ещё не устанавливалось
+func (m *WSReconnectManager) LastMessageAt() time.Time {
+	nanos := atomic.LoadInt64(&m.lastMessage)
+	if nanos == 0 {
+		return time.Time{}
+	}
+	return time.Unix(0, nanos)
+}
+
+// touch отмечает активность соединения
+func (m *WSReconnectManager) touch() {
+	atomic.StoreInt64(&m.lastMessage, time.Now().UnixNano())
+}
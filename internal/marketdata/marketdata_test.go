@@ -0,0 +1,109 @@
+package marketdata
+
+import (
+	"io"
+	"os"
+	"testing"
+	"time"
+)
+
+func readAll(t *testing.T, dir string) []*Record {
+	t.Helper()
+
+	reader, err := NewReader(dir)
+	if err != nil {
+		t.Fatalf("NewReader: %v", err)
+	}
+	defer reader.Close()
+
+	var records []*Record
+	for {
+		rec, err := reader.Next()
+		if err == io.EOF {
+			return records
+		}
+		if err != nil {
+			t.Fatalf("Next: %v", err)
+		}
+		records = append(records, rec)
+	}
+}
+
+// TestWriterReader_RoundTrip проверяет запись и чтение с ротацией по размеру
+func TestWriterReader_RoundTrip(t *testing.T) {
+	dir := t.TempDir()
+
+	writer, err := NewWriter(WriterConfig{Dir: dir, MaxFileSize: 200})
+	if err != nil {
+		t.Fatalf("NewWriter: %v", err)
+	}
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	for i := 0; i < 20; i++ {
+		rec := &Record{
+			Type:     TypeTicker,
+			Ts:       base.Add(time.Duration(i) * time.Millisecond).UnixNano(),
+			Exchange: "bybit",
+			Symbol:   "BTCUSDT",
+			Bid:      100 + float64(i),
+			Ask:      101 + float64(i),
+		}
+		if i%5 == 0 {
+			rec.Type = TypeOrderBook
+			rec.Bids = []Level{{100, 1}}
+			rec.Asks = []Level{{101, 2}}
+		}
+		if err := writer.Write(rec); err != nil {
+			t.Fatalf("Write: %v", err)
+		}
+	}
+	if err := writer.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	files, _ := ListFiles(dir)
+	if len(files) < 2 {
+		t.Fatalf("expected rotation into several files, got %d", len(files))
+	}
+
+	records := readAll(t, dir)
+	if len(records) != 20 {
+		t.Fatalf("expected 20 records, got %d", len(records))
+	}
+	for i, rec := range records {
+		if rec.Ts != base.Add(time.Duration(i)*time.Millisecond).UnixNano() {
+			t.Fatalf("record %d out of order", i)
+		}
+	}
+	if records[5].Type != TypeOrderBook || records[5].Asks[0] != (Level{101, 2}) {
+		t.Fatalf("unexpected order book record: %+v", records[5])
+	}
+	if records[1].Key() != "bybit:BTCUSDT" {
+		t.Fatalf("unexpected key %s", records[1].Key())
+	}
+}
+
+// TestReader_TruncatedFile проверяет чтение файла, оборванного при падении процесса
+func TestReader_TruncatedFile(t *testing.T) {
+	dir := t.TempDir()
+
+	writer, _ := NewWriter(WriterConfig{Dir: dir})
+	for i := 0; i < 10; i++ {
+		writer.Write(&Record{Type: TypeTicker, Ts: int64(i), Exchange: "okx", Symbol: "ETHUSDT"})
+	}
+	// Flush без Close: gzip без футера, как после kill -9
+	if err := writer.Flush(); err != nil {
+		t.Fatalf("Flush: %v", err)
+	}
+
+	files, _ := ListFiles(dir)
+	data, _ := os.ReadFile(files[0])
+	truncated := files[0][:len(files[0])-len(FileExt)] + "_cut" + FileExt
+	os.WriteFile(truncated, data, 0o644)
+	os.Remove(files[0])
+
+	records := readAll(t, dir)
+	if len(records) != 10 {
+		t.Fatalf("expected 10 records from truncated file, got %d", len(records))
+	}
+}
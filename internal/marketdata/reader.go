@@ -0,0 +1,132 @@
+package marketdata
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ListFiles возвращает файлы записи в каталоге в хронологическом порядке
+func ListFiles(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("marketdata: read dir: %w", err)
+	}
+
+	var files []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, FilePrefix) || !strings.HasSuffix(name, FileExt) {
+			continue
+		}
+		files = append(files, filepath.Join(dir, name))
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// Reader последовательно читает записи из списка файлов
+//
+// Оборванный хвост файла (процесс упал во время записи) не считается ошибкой:
+// чтение переходит к следующему файлу.
+type Reader struct {
+	files []string
+	idx   int
+
+	file *os.File
+	gz   *gzip.Reader
+	scan *bufio.Scanner
+}
+
+// NewReader создает Reader для всех файлов каталога
+func NewReader(dir string) (*Reader, error) {
+	files, err := ListFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+	return NewFileReader(files), nil
+}
+
+// NewFileReader создает Reader для явного списка файлов
+func NewFileReader(files []string) *Reader {
+	return &Reader{files: files}
+}
+
+// Next возвращает следующую запись или io.EOF после последнего файла
+func (r *Reader) Next() (*Record, error) {
+	for {
+		if r.scan == nil {
+			if r.idx >= len(r.files) {
+				return nil, io.EOF
+			}
+			if err := r.open(r.files[r.idx]); err != nil {
+				return nil, err
+			}
+			r.idx++
+		}
+
+		if r.scan.Scan() {
+			var rec Record
+			if err := json.Unmarshal(r.scan.Bytes(), &rec); err != nil {
+				// Обрезанная последняя строка - пропускаем
+				continue
+			}
+			return &rec, nil
+		}
+
+		err := r.scan.Err()
+		r.closeFile()
+		if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("marketdata: read %s: %w", r.files[r.idx-1], err)
+		}
+	}
+}
+
+// Close закрывает текущий файл
+func (r *Reader) Close() error {
+	r.closeFile()
+	return nil
+}
+
+func (r *Reader) open(path string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("marketdata: open %s: %w", path, err)
+	}
+
+	gz, err := gzip.NewReader(file)
+	if err != nil {
+		file.Close()
+		if errors.Is(err, io.EOF) {
+			// Пустой файл (создан, но не записан)
+			r.scan = bufio.NewScanner(strings.NewReader(""))
+			return nil
+		}
+		return fmt.Errorf("marketdata: gzip %s: %w", path, err)
+	}
+
+	r.file = file
+	r.gz = gz
+	r.scan = bufio.NewScanner(gz)
+	r.scan.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	return nil
+}
+
+func (r *Reader) closeFile() {
+	if r.gz != nil {
+		r.gz.Close()
+	}
+	if r.file != nil {
+		r.file.Close()
+	}
+	r.file = nil
+	r.gz = nil
+	r.scan = nil
+}
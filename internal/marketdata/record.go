@@ -0,0 +1,51 @@
+// Package marketdata - формат и хранение записанных рыночных данных
+//
+// Используется:
+// - bot.Recorder пишет тикеры и снимки стаканов со всех бирж
+// - replay/backtest читают записанные потоки для воспроизведения
+//
+// Формат хранения:
+// - JSON Lines внутри gzip, одна запись на строку
+// - файлы только дописываются и ротируются по размеру/времени
+// - имя файла содержит время создания, лексикографический порядок = хронологический
+//
+// Пакет не зависит от internal/exchange, чтобы его мог импортировать любой слой.
+package marketdata
+
+import "time"
+
+// Типы записей
+const (
+	TypeTicker    = "ticker"
+	TypeOrderBook = "book"
+)
+
+// Level - уровень стакана [цена, объём]
+type Level [2]float64
+
+// Record - одна запись рыночных данных
+//
+// Ts - время события на бирже (если биржа его не прислала - время получения),
+// RecvTs - локальное время получения. Оба в наносекундах Unix.
+type Record struct {
+	Type     string  `json:"t"`
+	Ts       int64   `json:"ts"`
+	RecvTs   int64   `json:"rts"`
+	Exchange string  `json:"ex"`
+	Symbol   string  `json:"s"`
+	Bid      float64 `json:"bid,omitempty"`
+	Ask      float64 `json:"ask,omitempty"`
+	Last     float64 `json:"last,omitempty"`
+	Bids     []Level `json:"b,omitempty"`
+	Asks     []Level `json:"a,omitempty"`
+}
+
+// Time возвращает время события
+func (r *Record) Time() time.Time {
+	return time.Unix(0, r.Ts)
+}
+
+// Key возвращает ключ потока exchange:symbol
+func (r *Record) Key() string {
+	return r.Exchange + ":" + r.Symbol
+}
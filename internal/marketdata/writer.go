@@ -0,0 +1,178 @@
+package marketdata
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+const (
+	// FilePrefix и FileExt - шаблон имени файла: marketdata-<время>.jsonl.gz
+	FilePrefix = "marketdata-"
+	FileExt    = ".jsonl.gz"
+
+	fileTimeLayout = "20060102T150405.000000000"
+)
+
+// WriterConfig - параметры ротации
+type WriterConfig struct {
+	Dir            string        // каталог для файлов
+	MaxFileSize    int64         // ротация после N несжатых байт (0 = без ограничения)
+	RotateInterval time.Duration // ротация по времени (0 = без ограничения)
+}
+
+// Writer пишет записи в сжатые ротируемые файлы
+//
+// Ожидается один писатель (горутина Recorder); mutex нужен для Flush/Close
+// из других горутин.
+type Writer struct {
+	cfg WriterConfig
+
+	mu       sync.Mutex
+	file     *os.File
+	gz       *gzip.Writer
+	buf      *bufio.Writer
+	enc      *json.Encoder
+	written  int64
+	openedAt time.Time
+	now      func() time.Time
+	lastName string
+}
+
+// NewWriter создает Writer; каталог создаётся при необходимости
+// Первый файл открывается при первой записи
+func NewWriter(cfg WriterConfig) (*Writer, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("marketdata: empty directory")
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("marketdata: create dir: %w", err)
+	}
+
+	return &Writer{
+		cfg: cfg,
+		now: time.Now,
+	}, nil
+}
+
+// Write дописывает запись, при необходимости ротируя файл
+func (w *Writer) Write(rec *Record) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.needRotate() {
+		if err := w.rotate(); err != nil {
+			return err
+		}
+	}
+
+	if err := w.enc.Encode(rec); err != nil {
+		return fmt.Errorf("marketdata: encode: %w", err)
+	}
+	return nil
+}
+
+// Flush сбрасывает буферы в файл
+// gzip.Flush пишет sync-блок: файл остаётся читаемым до последней записи даже при падении процесса
+func (w *Writer) Flush() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush()
+}
+
+// Close закрывает текущий файл
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.closeFile()
+}
+
+func (w *Writer) needRotate() bool {
+	if w.file == nil {
+		return true
+	}
+	if w.cfg.MaxFileSize > 0 && w.written >= w.cfg.MaxFileSize {
+		return true
+	}
+	if w.cfg.RotateInterval > 0 && w.now().Sub(w.openedAt) >= w.cfg.RotateInterval {
+		return true
+	}
+	return false
+}
+
+func (w *Writer) rotate() error {
+	if err := w.closeFile(); err != nil {
+		return err
+	}
+
+	now := w.now().UTC()
+	name := FilePrefix + now.Format(fileTimeLayout) + FileExt
+	// Имя не должно повторяться при ротации в пределах одной наносекунды (грубые часы)
+	if name <= w.lastName {
+		name = w.lastName[:len(w.lastName)-len(FileExt)] + "_1" + FileExt
+	}
+
+	file, err := os.OpenFile(filepath.Join(w.cfg.Dir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("marketdata: open file: %w", err)
+	}
+
+	w.file = file
+	w.gz = gzip.NewWriter(file)
+	w.buf = bufio.NewWriterSize(w.gz, 64*1024)
+	w.enc = json.NewEncoder(&countingWriter{w: w.buf, n: &w.written})
+	w.written = 0
+	w.openedAt = w.now()
+	w.lastName = name
+	return nil
+}
+
+func (w *Writer) flush() error {
+	if w.file == nil {
+		return nil
+	}
+	if err := w.buf.Flush(); err != nil {
+		return fmt.Errorf("marketdata: flush: %w", err)
+	}
+	if err := w.gz.Flush(); err != nil {
+		return fmt.Errorf("marketdata: flush: %w", err)
+	}
+	return nil
+}
+
+func (w *Writer) closeFile() error {
+	if w.file == nil {
+		return nil
+	}
+
+	err := w.flush()
+	if cerr := w.gz.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("marketdata: close gzip: %w", cerr)
+	}
+	if cerr := w.file.Close(); err == nil && cerr != nil {
+		err = fmt.Errorf("marketdata: close file: %w", cerr)
+	}
+
+	w.file = nil
+	w.gz = nil
+	w.buf = nil
+	w.enc = nil
+	return err
+}
+
+// countingWriter считает несжатые байты для ротации по размеру
+type countingWriter struct {
+	w *bufio.Writer
+	n *int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.w.Write(p)
+	*c.n += int64(n)
+	return n, err
+}
@@ -4,6 +4,8 @@ import (
 	"encoding/json"
 	"testing"
 	"time"
+
+	"arbitrage/internal/exchange"
 )
 
 // ============ ExchangeAccount Tests ============
@@ -83,12 +85,15 @@ func TestExchangeAccount_JSONDeserialization(t *testing.T) {
 }
 
 func TestExchangeAccount_SupportedExchanges(t *testing.T) {
-	supportedExchanges := []string{"bybit", "bitget", "okx", "gate", "htx", "bingx"}
+	supportedExchanges := exchange.SupportedExchanges()
+	if len(supportedExchanges) == 0 {
+		t.Fatal("реестр адаптеров бирж пуст")
+	}
 
-	for _, exchange := range supportedExchanges {
-		account := ExchangeAccount{Name: exchange}
-		if account.Name != exchange {
-			t.Errorf("биржа %s должна поддерживаться", exchange)
+	for _, name := range supportedExchanges {
+		account := ExchangeAccount{Name: name}
+		if account.Name != name {
+			t.Errorf("биржа %s должна поддерживаться", name)
 		}
 	}
 }
@@ -203,6 +208,71 @@ func TestPairConfig_ValidValues(t *testing.T) {
 	}
 }
 
+func TestPairConfig_Accounts(t *testing.T) {
+	valid := PairConfig{Symbol: "BTCUSDT", Base: "BTC", Quote: "USDT", EntrySpreadPct: 1.0, ExitSpreadPct: 0.2, VolumeAsset: 0.5, NOrders: 1}
+
+	tests := []struct {
+		name          string
+		accounts      []string
+		shouldBeValid bool
+	}{
+		{"без привязки", nil, true},
+		{"два аккаунта одной биржи", []string{"bybit", "arb1@bybit"}, true},
+		{"один аккаунт", []string{"bybit"}, false},
+		{"пустой аккаунт", []string{"bybit", ""}, false},
+		{"повтор аккаунта", []string{"okx", "okx"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pair := valid
+			pair.Accounts = tt.accounts
+			if err := pair.Validate(); (err == nil) != tt.shouldBeValid {
+				t.Errorf("валидация для %s: ожидали %v, получили %v", tt.name, tt.shouldBeValid, err)
+			}
+		})
+	}
+
+	unbound := PairConfig{}
+	if !unbound.UsesAccount("arb1@bybit") {
+		t.Error("пара без привязки должна использовать любой аккаунт")
+	}
+	bound := PairConfig{Accounts: []string{"arb1@bybit", "okx"}}
+	if !bound.UsesAccount("arb1@bybit") || bound.UsesAccount("bybit") {
+		t.Error("привязанная пара должна использовать только свои аккаунты")
+	}
+}
+
+func TestPairConfig_Leverage(t *testing.T) {
+	valid := PairConfig{Symbol: "BTCUSDT", Base: "BTC", Quote: "USDT", EntrySpreadPct: 1.0, ExitSpreadPct: 0.2, VolumeAsset: 0.5, NOrders: 1}
+
+	tests := []struct {
+		name          string
+		leverage      int
+		wantLeverage  int
+		shouldBeValid bool
+	}{
+		{"не задано - 1x", 0, 1, true},
+		{"1x", 1, 1, true},
+		{"максимум", MaxLeverage, MaxLeverage, true},
+		{"выше максимума", MaxLeverage + 1, 0, false},
+		{"отрицательное", -1, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			pair := valid
+			pair.Leverage = tt.leverage
+			if err := pair.Validate(); (err == nil) != tt.shouldBeValid {
+				t.Fatalf("валидация для %s: ожидали %v, получили %v", tt.name, tt.shouldBeValid, err)
+			}
+			if tt.shouldBeValid && pair.GetLeverage() != tt.wantLeverage {
+				t.Errorf("плечо для %s: ожидали %d, получили %d", tt.name, tt.wantLeverage, pair.GetLeverage())
+			}
+		})
+	}
+}
+
 func TestPairConfig_JSONFieldNames(t *testing.T) {
 	pair := PairConfig{
 		EntrySpreadPct: 1.5,
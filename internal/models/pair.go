@@ -8,17 +8,24 @@ import (
 // PairConfig представляет конфигурацию торговой пары
 type PairConfig struct {
 	ID             int       `json:"id" db:"id"`
-	Symbol         string    `json:"symbol" db:"symbol"`                       // BTCUSDT
-	Base           string    `json:"base" db:"base"`                           // BTC
-	Quote          string    `json:"quote" db:"quote"`                         // USDT
-	EntrySpreadPct float64   `json:"entry_spread" db:"entry_spread_pct"`       // % для входа
-	ExitSpreadPct  float64   `json:"exit_spread" db:"exit_spread_pct"`         // % для выхода
-	VolumeAsset    float64   `json:"volume" db:"volume_asset"`                 // объем в монетах
-	NOrders        int       `json:"n_orders" db:"n_orders"`                   // количество частей
-	StopLoss       float64   `json:"stop_loss" db:"stop_loss"`                 // в USDT
-	Status         string    `json:"status" db:"status"`                       // paused, active
-	TradesCount    int       `json:"trades_count" db:"trades_count"`           // локальная статистика
-	TotalPnl       float64   `json:"total_pnl" db:"total_pnl"`                 // локальная статистика
+	Symbol         string    `json:"symbol" db:"symbol"`                 // BTCUSDT
+	Base           string    `json:"base" db:"base"`                     // BTC
+	Quote          string    `json:"quote" db:"quote"`                   // USDT
+	EntrySpreadPct float64   `json:"entry_spread" db:"entry_spread_pct"` // % для входа
+	ExitSpreadPct  float64   `json:"exit_spread" db:"exit_spread_pct"`   // % для выхода
+	VolumeAsset    float64   `json:"volume" db:"volume_asset"`           // объем в монетах
+	NOrders        int       `json:"n_orders" db:"n_orders"`             // количество частей
+	StopLoss       float64   `json:"stop_loss" db:"stop_loss"`           // в USDT
+	EntryMode      string    `json:"entry_mode" db:"entry_mode"`         // taker, maker_taker
+	Strategy       string    `json:"strategy" db:"strategy"`             // spread, funding
+	FundingDiffPct float64   `json:"funding_diff" db:"funding_diff_pct"` // % разницы ставок фандинга за 8ч для входа
+	MaxHoldHours   int       `json:"max_hold_hours" db:"max_hold_hours"` // максимальное время удержания (0 = без ограничения)
+	Leverage       int       `json:"leverage" db:"leverage"`             // плечо на обеих биржах (0 = 1x)
+	MarginMode     string    `json:"margin_mode" db:"margin_mode"`       // cross, isolated
+	Accounts       []string  `json:"accounts,omitempty" db:"accounts"`   // аккаунты для ног (bybit, arb1@okx), пусто - любые подключенные
+	Status         string    `json:"status" db:"status"`                 // paused, active
+	TradesCount    int       `json:"trades_count" db:"trades_count"`     // локальная статистика
+	TotalPnl       float64   `json:"total_pnl" db:"total_pnl"`           // локальная статистика
 	CreatedAt      time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt      time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -29,6 +36,27 @@ const (
 	PairStatusActive = "active"
 )
 
+// Режимы входа в позицию
+const (
+	EntryModeTaker      = "taker"       // обе ноги рыночными ордерами
+	EntryModeMakerTaker = "maker_taker" // post-only на менее ликвидной бирже, рыночный хедж на исполнение
+)
+
+// Стратегии пары
+const (
+	StrategySpread  = "spread"  // схождение межбиржевого спреда
+	StrategyFunding = "funding" // удержание дельта-нейтральной позиции ради разницы ставок фандинга
+)
+
+// Режимы маржи
+const (
+	MarginModeCross    = "cross"    // общая маржа аккаунта
+	MarginModeIsolated = "isolated" // маржа выделяется каждой позиции
+)
+
+// MaxLeverage - максимальное плечо пары (верхняя граница на поддерживаемых биржах)
+const MaxLeverage = 125
+
 // Validate проверяет корректность параметров пары
 func (p *PairConfig) Validate() error {
 	if p.Symbol == "" {
@@ -40,14 +68,36 @@ func (p *PairConfig) Validate() error {
 	if p.Quote == "" {
 		return fmt.Errorf("quote currency is required")
 	}
-	if p.EntrySpreadPct <= 0 {
-		return fmt.Errorf("entry_spread must be positive, got %f", p.EntrySpreadPct)
+	if p.Strategy != "" && p.Strategy != StrategySpread && p.Strategy != StrategyFunding {
+		return fmt.Errorf("invalid strategy: %s, must be '%s' or '%s'", p.Strategy, StrategySpread, StrategyFunding)
+	}
+	if p.IsFundingStrategy() {
+		if p.FundingDiffPct <= 0 {
+			return fmt.Errorf("funding_diff must be positive, got %f", p.FundingDiffPct)
+		}
+	} else {
+		if p.EntrySpreadPct <= 0 {
+			return fmt.Errorf("entry_spread must be positive, got %f", p.EntrySpreadPct)
+		}
+		if p.ExitSpreadPct < 0 {
+			return fmt.Errorf("exit_spread cannot be negative, got %f", p.ExitSpreadPct)
+		}
+		if p.ExitSpreadPct >= p.EntrySpreadPct {
+			return fmt.Errorf("exit_spread (%f) must be less than entry_spread (%f)", p.ExitSpreadPct, p.EntrySpreadPct)
+		}
+	}
+	if p.MaxHoldHours < 0 {
+		return fmt.Errorf("max_hold_hours cannot be negative, got %d", p.MaxHoldHours)
 	}
-	if p.ExitSpreadPct < 0 {
-		return fmt.Errorf("exit_spread cannot be negative, got %f", p.ExitSpreadPct)
+	// 0 - плечо не задано, используется 1x (GetLeverage)
+	if p.Leverage < 0 || p.Leverage > MaxLeverage {
+		return fmt.Errorf("leverage must be between 1 and %d, or 0 for the default 1x, got %d", MaxLeverage, p.Leverage)
 	}
-	if p.ExitSpreadPct >= p.EntrySpreadPct {
-		return fmt.Errorf("exit_spread (%f) must be less than entry_spread (%f)", p.ExitSpreadPct, p.EntrySpreadPct)
+	if p.MarginMode != "" && p.MarginMode != MarginModeCross && p.MarginMode != MarginModeIsolated {
+		return fmt.Errorf("invalid margin_mode: %s, must be '%s' or '%s'", p.MarginMode, MarginModeCross, MarginModeIsolated)
+	}
+	if err := validateAccounts(p.Accounts); err != nil {
+		return err
 	}
 	if p.VolumeAsset <= 0 {
 		return fmt.Errorf("volume must be positive, got %f", p.VolumeAsset)
@@ -58,12 +108,81 @@ func (p *PairConfig) Validate() error {
 	if p.StopLoss < 0 {
 		return fmt.Errorf("stop_loss cannot be negative, got %f", p.StopLoss)
 	}
+	if p.EntryMode != "" && p.EntryMode != EntryModeTaker && p.EntryMode != EntryModeMakerTaker {
+		return fmt.Errorf("invalid entry_mode: %s, must be '%s' or '%s'", p.EntryMode, EntryModeTaker, EntryModeMakerTaker)
+	}
 	if p.Status != "" && p.Status != PairStatusPaused && p.Status != PairStatusActive {
 		return fmt.Errorf("invalid status: %s, must be '%s' or '%s'", p.Status, PairStatusPaused, PairStatusActive)
 	}
 	return nil
 }
 
+// IsMakerTaker возвращает true если вход выполняется в режиме maker-taker
+func (p *PairConfig) IsMakerTaker() bool {
+	return p.EntryMode == EntryModeMakerTaker
+}
+
+// IsFundingStrategy возвращает true если пара торгует разницу ставок фандинга
+func (p *PairConfig) IsFundingStrategy() bool {
+	return p.Strategy == StrategyFunding
+}
+
+// MaxHold возвращает максимальное время удержания позиции (0 = без ограничения)
+func (p *PairConfig) MaxHold() time.Duration {
+	return time.Duration(p.MaxHoldHours) * time.Hour
+}
+
+// GetLeverage возвращает плечо пары (1 если не задано)
+func (p *PairConfig) GetLeverage() int {
+	if p.Leverage < 1 {
+		return 1
+	}
+	return p.Leverage
+}
+
+// GetMarginMode возвращает режим маржи пары (cross если не задан)
+func (p *PairConfig) GetMarginMode() string {
+	if p.MarginMode == "" {
+		return MarginModeCross
+	}
+	return p.MarginMode
+}
+
+// UsesAccount возвращает true если пара может открывать ноги на аккаунте
+func (p *PairConfig) UsesAccount(account string) bool {
+	if len(p.Accounts) == 0 {
+		return true
+	}
+	for _, a := range p.Accounts {
+		if a == account {
+			return true
+		}
+	}
+	return false
+}
+
+// validateAccounts проверяет привязку пары к аккаунтам
+// Для арбитража нужны минимум два аккаунта
+func validateAccounts(accounts []string) error {
+	if len(accounts) == 0 {
+		return nil
+	}
+	if len(accounts) < 2 {
+		return fmt.Errorf("accounts must list at least 2 accounts, got %d", len(accounts))
+	}
+	seen := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		if account == "" {
+			return fmt.Errorf("accounts cannot contain empty account")
+		}
+		if seen[account] {
+			return fmt.Errorf("duplicate account: %s", account)
+		}
+		seen[account] = true
+	}
+	return nil
+}
+
 // IsActive возвращает true если пара активна
 func (p *PairConfig) IsActive() bool {
 	return p.Status == PairStatusActive
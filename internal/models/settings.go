@@ -5,7 +5,7 @@ import "time"
 // Settings представляет глобальные настройки бота
 type Settings struct {
 	ID                  int                     `json:"id" db:"id"`
-	ConsiderFunding     bool                    `json:"consider_funding" db:"consider_funding"`           // учитывать фандинг (future)
+	ConsiderFunding     bool                    `json:"consider_funding" db:"consider_funding"`           // учитывать фандинг в чистом спреде
 	MaxConcurrentTrades *int                    `json:"max_concurrent_trades" db:"max_concurrent_trades"` // null = без ограничений
 	NotificationPrefs   NotificationPreferences `json:"notification_prefs" db:"notification_prefs"`       // JSON в БД
 	UpdatedAt           time.Time               `json:"updated_at" db:"updated_at"`
@@ -28,8 +28,8 @@ func NewExchangeRepository(db *sql.DB) *ExchangeRepository {
 // Create создает новый аккаунт биржи
 func (r *ExchangeRepository) Create(account *models.ExchangeAccount) error {
 	query := `
-		INSERT INTO exchanges (name, api_key, secret_key, passphrase, connected, balance, last_error, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		INSERT INTO exchanges (name, label, api_key, secret_key, passphrase, connected, balance, last_error, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
 		RETURNING id`
 
 	now := time.Now()
@@ -39,6 +39,7 @@ func (r *ExchangeRepository) Create(account *models.ExchangeAccount) error {
 	err := r.db.QueryRow(
 		query,
 		account.Name,
+		account.Label,
 		account.APIKey,
 		account.SecretKey,
 		account.Passphrase,
@@ -62,7 +63,7 @@ func (r *ExchangeRepository) Create(account *models.ExchangeAccount) error {
 // GetByID возвращает биржу по ID
 func (r *ExchangeRepository) GetByID(id int) (*models.ExchangeAccount, error) {
 	query := `
-		SELECT id, name, api_key, secret_key, passphrase, connected, balance, last_error, updated_at, created_at
+		SELECT id, name, label, api_key, secret_key, passphrase, connected, balance, last_error, updated_at, created_at
 		FROM exchanges
 		WHERE id = $1`
 
@@ -70,6 +71,7 @@ func (r *ExchangeRepository) GetByID(id int) (*models.ExchangeAccount, error) {
 	err := r.db.QueryRow(query, id).Scan(
 		&account.ID,
 		&account.Name,
+		&account.Label,
 		&account.APIKey,
 		&account.SecretKey,
 		&account.Passphrase,
@@ -90,17 +92,19 @@ func (r *ExchangeRepository) GetByID(id int) (*models.ExchangeAccount, error) {
 	return account, nil
 }
 
-// GetByName возвращает биржу по имени (bybit, bitget, etc.)
-func (r *ExchangeRepository) GetByName(name string) (*models.ExchangeAccount, error) {
+// GetByAccount возвращает аккаунт по имени биржи (bybit, bitget, etc.) и метке
+// Пустая метка - основной аккаунт биржи
+func (r *ExchangeRepository) GetByAccount(name, label string) (*models.ExchangeAccount, error) {
 	query := `
-		SELECT id, name, api_key, secret_key, passphrase, connected, balance, last_error, updated_at, created_at
+		SELECT id, name, label, api_key, secret_key, passphrase, connected, balance, last_error, updated_at, created_at
 		FROM exchanges
-		WHERE name = $1`
+		WHERE name = $1 AND label = $2`
 
 	account := &models.ExchangeAccount{}
-	err := r.db.QueryRow(query, name).Scan(
+	err := r.db.QueryRow(query, name, label).Scan(
 		&account.ID,
 		&account.Name,
+		&account.Label,
 		&account.APIKey,
 		&account.SecretKey,
 		&account.Passphrase,
@@ -124,9 +128,9 @@ func (r *ExchangeRepository) GetByName(name string) (*models.ExchangeAccount, er
 // GetAll возвращает все биржи
 func (r *ExchangeRepository) GetAll() ([]*models.ExchangeAccount, error) {
 	query := `
-		SELECT id, name, api_key, secret_key, passphrase, connected, balance, last_error, updated_at, created_at
+		SELECT id, name, label, api_key, secret_key, passphrase, connected, balance, last_error, updated_at, created_at
 		FROM exchanges
-		ORDER BY name`
+		ORDER BY name, label`
 
 	rows, err := r.db.Query(query)
 	if err != nil {
@@ -140,6 +144,7 @@ func (r *ExchangeRepository) GetAll() ([]*models.ExchangeAccount, error) {
 		err := rows.Scan(
 			&account.ID,
 			&account.Name,
+			&account.Label,
 			&account.APIKey,
 			&account.SecretKey,
 			&account.Passphrase,
@@ -165,10 +170,10 @@ func (r *ExchangeRepository) GetAll() ([]*models.ExchangeAccount, error) {
 // GetConnected возвращает все подключенные биржи
 func (r *ExchangeRepository) GetConnected() ([]*models.ExchangeAccount, error) {
 	query := `
-		SELECT id, name, api_key, secret_key, passphrase, connected, balance, last_error, updated_at, created_at
+		SELECT id, name, label, api_key, secret_key, passphrase, connected, balance, last_error, updated_at, created_at
 		FROM exchanges
 		WHERE connected = true
-		ORDER BY name`
+		ORDER BY name, label`
 
 	rows, err := r.db.Query(query)
 	if err != nil {
@@ -182,6 +187,7 @@ func (r *ExchangeRepository) GetConnected() ([]*models.ExchangeAccount, error) {
 		err := rows.Scan(
 			&account.ID,
 			&account.Name,
+			&account.Label,
 			&account.APIKey,
 			&account.SecretKey,
 			&account.Passphrase,
@@ -261,9 +267,9 @@ func (r *ExchangeRepository) Delete(id int) error {
 	return nil
 }
 
-// DeleteByName удаляет аккаунт биржи по имени
+// DeleteByName удаляет основной аккаунт биржи по имени
 func (r *ExchangeRepository) DeleteByName(name string) error {
-	query := `DELETE FROM exchanges WHERE name = $1`
+	query := `DELETE FROM exchanges WHERE name = $1 AND label = ''`
 
 	result, err := r.db.Exec(query, name)
 	if err != nil {
@@ -306,12 +312,12 @@ func (r *ExchangeRepository) UpdateBalance(id int, balance float64) error {
 	return nil
 }
 
-// UpdateBalanceByName обновляет баланс биржи по имени
+// UpdateBalanceByName обновляет баланс основного аккаунта биржи по имени
 func (r *ExchangeRepository) UpdateBalanceByName(name string, balance float64) error {
 	query := `
 		UPDATE exchanges
 		SET balance = $1, updated_at = $2
-		WHERE name = $3`
+		WHERE name = $3 AND label = ''`
 
 	result, err := r.db.Exec(query, balance, time.Now(), name)
 	if err != nil {
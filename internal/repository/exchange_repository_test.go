@@ -51,7 +51,7 @@ func TestExchangeRepositoryCreate(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(`INSERT INTO exchanges`).
-					WithArgs("bybit", "test-api-key", "test-secret-key", "", false, float64(0), "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WithArgs("bybit", "", "test-api-key", "test-secret-key", "", false, float64(0), "", sqlmock.AnyArg(), sqlmock.AnyArg()).
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 			},
 			expectError: nil,
@@ -65,7 +65,7 @@ func TestExchangeRepositoryCreate(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(`INSERT INTO exchanges`).
-					WithArgs("bybit", "test-api-key", "test-secret-key", "", false, float64(0), "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WithArgs("bybit", "", "test-api-key", "test-secret-key", "", false, float64(0), "", sqlmock.AnyArg(), sqlmock.AnyArg()).
 					WillReturnError(errors.New("duplicate key value violates unique constraint"))
 			},
 			expectError: ErrExchangeExists,
@@ -79,7 +79,7 @@ func TestExchangeRepositoryCreate(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(`INSERT INTO exchanges`).
-					WithArgs("okx", "api", "secret", "", false, float64(0), "", sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WithArgs("okx", "", "api", "secret", "", false, float64(0), "", sqlmock.AnyArg(), sqlmock.AnyArg()).
 					WillReturnError(errors.New("connection refused"))
 			},
 			expectError: errors.New("connection refused"),
@@ -135,8 +135,8 @@ func TestExchangeRepositoryGetByID(t *testing.T) {
 			name: "success",
 			id:   1,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "api_key", "secret_key", "passphrase", "connected", "balance", "last_error", "updated_at", "created_at"}).
-					AddRow(1, "bybit", "api-key", "secret-key", "", true, 1000.50, "", now, now)
+				rows := sqlmock.NewRows([]string{"id", "name", "label", "api_key", "secret_key", "passphrase", "connected", "balance", "last_error", "updated_at", "created_at"}).
+					AddRow(1, "bybit", "", "api-key", "secret-key", "", true, 1000.50, "", now, now)
 				mock.ExpectQuery(`SELECT .+ FROM exchanges WHERE id = \$1`).
 					WithArgs(1).
 					WillReturnRows(rows)
@@ -209,12 +209,13 @@ func TestExchangeRepositoryGetByID(t *testing.T) {
 	}
 }
 
-func TestExchangeRepositoryGetByName(t *testing.T) {
+func TestExchangeRepositoryGetByAccount(t *testing.T) {
 	now := time.Now()
 
 	tests := []struct {
 		name        string
 		exchName    string
+		label       string
 		mockSetup   func(mock sqlmock.Sqlmock)
 		expected    *models.ExchangeAccount
 		expectError error
@@ -222,16 +223,18 @@ func TestExchangeRepositoryGetByName(t *testing.T) {
 		{
 			name:     "success",
 			exchName: "okx",
+			label:    "arb1",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "api_key", "secret_key", "passphrase", "connected", "balance", "last_error", "updated_at", "created_at"}).
-					AddRow(2, "okx", "api", "secret", "pass", true, 500.0, "", now, now)
-				mock.ExpectQuery(`SELECT .+ FROM exchanges WHERE name = \$1`).
-					WithArgs("okx").
+				rows := sqlmock.NewRows([]string{"id", "name", "label", "api_key", "secret_key", "passphrase", "connected", "balance", "last_error", "updated_at", "created_at"}).
+					AddRow(2, "okx", "arb1", "api", "secret", "pass", true, 500.0, "", now, now)
+				mock.ExpectQuery(`SELECT .+ FROM exchanges WHERE name = \$1 AND label = \$2`).
+					WithArgs("okx", "arb1").
 					WillReturnRows(rows)
 			},
 			expected: &models.ExchangeAccount{
 				ID:         2,
 				Name:       "okx",
+				Label:      "arb1",
 				Passphrase: "pass",
 				Connected:  true,
 				Balance:    500.0,
@@ -242,8 +245,8 @@ func TestExchangeRepositoryGetByName(t *testing.T) {
 			name:     "not found",
 			exchName: "unknown",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				mock.ExpectQuery(`SELECT .+ FROM exchanges WHERE name = \$1`).
-					WithArgs("unknown").
+				mock.ExpectQuery(`SELECT .+ FROM exchanges WHERE name = \$1 AND label = \$2`).
+					WithArgs("unknown", "").
 					WillReturnError(sql.ErrNoRows)
 			},
 			expected:    nil,
@@ -262,7 +265,7 @@ func TestExchangeRepositoryGetByName(t *testing.T) {
 			tt.mockSetup(mock)
 
 			repo := NewExchangeRepository(db)
-			result, err := repo.GetByName(tt.exchName)
+			result, err := repo.GetByAccount(tt.exchName, tt.label)
 
 			if tt.expectError != nil {
 				if !errors.Is(err, tt.expectError) {
@@ -272,8 +275,8 @@ func TestExchangeRepositoryGetByName(t *testing.T) {
 				if err != nil {
 					t.Errorf("unexpected error: %v", err)
 				}
-				if result.Name != tt.expected.Name {
-					t.Errorf("expected Name=%s, got %s", tt.expected.Name, result.Name)
+				if result.Name != tt.expected.Name || result.Label != tt.expected.Label {
+					t.Errorf("expected %s/%s, got %s/%s", tt.expected.Name, tt.expected.Label, result.Name, result.Label)
 				}
 			}
 
@@ -296,10 +299,10 @@ func TestExchangeRepositoryGetAll(t *testing.T) {
 		{
 			name: "success with multiple exchanges",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "api_key", "secret_key", "passphrase", "connected", "balance", "last_error", "updated_at", "created_at"}).
-					AddRow(1, "bybit", "api1", "secret1", "", true, 1000.0, "", now, now).
-					AddRow(2, "okx", "api2", "secret2", "pass", true, 500.0, "", now, now).
-					AddRow(3, "bitget", "api3", "secret3", "", false, 0.0, "connection error", now, now)
+				rows := sqlmock.NewRows([]string{"id", "name", "label", "api_key", "secret_key", "passphrase", "connected", "balance", "last_error", "updated_at", "created_at"}).
+					AddRow(1, "bybit", "", "api1", "secret1", "", true, 1000.0, "", now, now).
+					AddRow(2, "okx", "arb1", "api2", "secret2", "pass", true, 500.0, "", now, now).
+					AddRow(3, "bitget", "", "api3", "secret3", "", false, 0.0, "connection error", now, now)
 				mock.ExpectQuery(`SELECT .+ FROM exchanges ORDER BY name`).
 					WillReturnRows(rows)
 			},
@@ -309,7 +312,7 @@ func TestExchangeRepositoryGetAll(t *testing.T) {
 		{
 			name: "empty result",
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "name", "api_key", "secret_key", "passphrase", "connected", "balance", "last_error", "updated_at", "created_at"})
+				rows := sqlmock.NewRows([]string{"id", "name", "label", "api_key", "secret_key", "passphrase", "connected", "balance", "last_error", "updated_at", "created_at"})
 				mock.ExpectQuery(`SELECT .+ FROM exchanges ORDER BY name`).
 					WillReturnRows(rows)
 			},
@@ -369,9 +372,9 @@ func TestExchangeRepositoryGetConnected(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "name", "api_key", "secret_key", "passphrase", "connected", "balance", "last_error", "updated_at", "created_at"}).
-		AddRow(1, "bybit", "api1", "secret1", "", true, 1000.0, "", now, now).
-		AddRow(2, "okx", "api2", "secret2", "pass", true, 500.0, "", now, now)
+	rows := sqlmock.NewRows([]string{"id", "name", "label", "api_key", "secret_key", "passphrase", "connected", "balance", "last_error", "updated_at", "created_at"}).
+		AddRow(1, "bybit", "", "api1", "secret1", "", true, 1000.0, "", now, now).
+		AddRow(2, "okx", "", "api2", "secret2", "pass", true, 500.0, "", now, now)
 	mock.ExpectQuery(`SELECT .+ FROM exchanges WHERE connected = true ORDER BY name`).
 		WillReturnRows(rows)
 
@@ -6,6 +6,8 @@ import (
 	"strings"
 	"time"
 
+	"github.com/lib/pq"
+
 	"arbitrage/internal/models"
 )
 
@@ -28,8 +30,8 @@ func NewPairRepository(db *sql.DB) *PairRepository {
 // Create создает новую торговую пару
 func (r *PairRepository) Create(pair *models.PairConfig) error {
 	query := `
-		INSERT INTO pairs (symbol, base, quote, entry_spread_pct, exit_spread_pct, volume_asset, n_orders, stop_loss, status, trades_count, total_pnl, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+		INSERT INTO pairs (symbol, base, quote, entry_spread_pct, exit_spread_pct, volume_asset, n_orders, stop_loss, entry_mode, strategy, funding_diff_pct, max_hold_hours, leverage, margin_mode, accounts, status, trades_count, total_pnl, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20)
 		RETURNING id`
 
 	now := time.Now()
@@ -43,6 +45,18 @@ func (r *PairRepository) Create(pair *models.PairConfig) error {
 	if pair.NOrders == 0 {
 		pair.NOrders = 1
 	}
+	if pair.EntryMode == "" {
+		pair.EntryMode = models.EntryModeTaker
+	}
+	if pair.Strategy == "" {
+		pair.Strategy = models.StrategySpread
+	}
+	if pair.Leverage == 0 {
+		pair.Leverage = 1
+	}
+	if pair.MarginMode == "" {
+		pair.MarginMode = models.MarginModeCross
+	}
 
 	err := r.db.QueryRow(
 		query,
@@ -54,6 +68,13 @@ func (r *PairRepository) Create(pair *models.PairConfig) error {
 		pair.VolumeAsset,
 		pair.NOrders,
 		pair.StopLoss,
+		pair.EntryMode,
+		pair.Strategy,
+		pair.FundingDiffPct,
+		pair.MaxHoldHours,
+		pair.Leverage,
+		pair.MarginMode,
+		accountsArray(pair.Accounts),
 		pair.Status,
 		pair.TradesCount,
 		pair.TotalPnl,
@@ -74,7 +95,7 @@ func (r *PairRepository) Create(pair *models.PairConfig) error {
 // GetByID возвращает пару по ID
 func (r *PairRepository) GetByID(id int) (*models.PairConfig, error) {
 	query := `
-		SELECT id, symbol, base, quote, entry_spread_pct, exit_spread_pct, volume_asset, n_orders, stop_loss, status, trades_count, total_pnl, created_at, updated_at
+		SELECT id, symbol, base, quote, entry_spread_pct, exit_spread_pct, volume_asset, n_orders, stop_loss, entry_mode, strategy, funding_diff_pct, max_hold_hours, leverage, margin_mode, accounts, status, trades_count, total_pnl, created_at, updated_at
 		FROM pairs
 		WHERE id = $1`
 
@@ -89,6 +110,13 @@ func (r *PairRepository) GetByID(id int) (*models.PairConfig, error) {
 		&pair.VolumeAsset,
 		&pair.NOrders,
 		&pair.StopLoss,
+		&pair.EntryMode,
+		&pair.Strategy,
+		&pair.FundingDiffPct,
+		&pair.MaxHoldHours,
+		&pair.Leverage,
+		&pair.MarginMode,
+		pq.Array(&pair.Accounts),
 		&pair.Status,
 		&pair.TradesCount,
 		&pair.TotalPnl,
@@ -109,7 +137,7 @@ func (r *PairRepository) GetByID(id int) (*models.PairConfig, error) {
 // GetBySymbol возвращает пару по символу
 func (r *PairRepository) GetBySymbol(symbol string) (*models.PairConfig, error) {
 	query := `
-		SELECT id, symbol, base, quote, entry_spread_pct, exit_spread_pct, volume_asset, n_orders, stop_loss, status, trades_count, total_pnl, created_at, updated_at
+		SELECT id, symbol, base, quote, entry_spread_pct, exit_spread_pct, volume_asset, n_orders, stop_loss, entry_mode, strategy, funding_diff_pct, max_hold_hours, leverage, margin_mode, accounts, status, trades_count, total_pnl, created_at, updated_at
 		FROM pairs
 		WHERE symbol = $1`
 
@@ -124,6 +152,13 @@ func (r *PairRepository) GetBySymbol(symbol string) (*models.PairConfig, error)
 		&pair.VolumeAsset,
 		&pair.NOrders,
 		&pair.StopLoss,
+		&pair.EntryMode,
+		&pair.Strategy,
+		&pair.FundingDiffPct,
+		&pair.MaxHoldHours,
+		&pair.Leverage,
+		&pair.MarginMode,
+		pq.Array(&pair.Accounts),
 		&pair.Status,
 		&pair.TradesCount,
 		&pair.TotalPnl,
@@ -144,7 +179,7 @@ func (r *PairRepository) GetBySymbol(symbol string) (*models.PairConfig, error)
 // GetAll возвращает все пары
 func (r *PairRepository) GetAll() ([]*models.PairConfig, error) {
 	query := `
-		SELECT id, symbol, base, quote, entry_spread_pct, exit_spread_pct, volume_asset, n_orders, stop_loss, status, trades_count, total_pnl, created_at, updated_at
+		SELECT id, symbol, base, quote, entry_spread_pct, exit_spread_pct, volume_asset, n_orders, stop_loss, entry_mode, strategy, funding_diff_pct, max_hold_hours, leverage, margin_mode, accounts, status, trades_count, total_pnl, created_at, updated_at
 		FROM pairs
 		ORDER BY created_at DESC`
 
@@ -167,6 +202,13 @@ func (r *PairRepository) GetAll() ([]*models.PairConfig, error) {
 			&pair.VolumeAsset,
 			&pair.NOrders,
 			&pair.StopLoss,
+			&pair.EntryMode,
+			&pair.Strategy,
+			&pair.FundingDiffPct,
+			&pair.MaxHoldHours,
+			&pair.Leverage,
+			&pair.MarginMode,
+			pq.Array(&pair.Accounts),
 			&pair.Status,
 			&pair.TradesCount,
 			&pair.TotalPnl,
@@ -189,7 +231,7 @@ func (r *PairRepository) GetAll() ([]*models.PairConfig, error) {
 // GetActive возвращает только активные пары
 func (r *PairRepository) GetActive() ([]*models.PairConfig, error) {
 	query := `
-		SELECT id, symbol, base, quote, entry_spread_pct, exit_spread_pct, volume_asset, n_orders, stop_loss, status, trades_count, total_pnl, created_at, updated_at
+		SELECT id, symbol, base, quote, entry_spread_pct, exit_spread_pct, volume_asset, n_orders, stop_loss, entry_mode, strategy, funding_diff_pct, max_hold_hours, leverage, margin_mode, accounts, status, trades_count, total_pnl, created_at, updated_at
 		FROM pairs
 		WHERE status = $1
 		ORDER BY created_at DESC`
@@ -213,6 +255,13 @@ func (r *PairRepository) GetActive() ([]*models.PairConfig, error) {
 			&pair.VolumeAsset,
 			&pair.NOrders,
 			&pair.StopLoss,
+			&pair.EntryMode,
+			&pair.Strategy,
+			&pair.FundingDiffPct,
+			&pair.MaxHoldHours,
+			&pair.Leverage,
+			&pair.MarginMode,
+			pq.Array(&pair.Accounts),
 			&pair.Status,
 			&pair.TradesCount,
 			&pair.TotalPnl,
@@ -235,7 +284,7 @@ func (r *PairRepository) GetActive() ([]*models.PairConfig, error) {
 // GetPaused возвращает только приостановленные пары
 func (r *PairRepository) GetPaused() ([]*models.PairConfig, error) {
 	query := `
-		SELECT id, symbol, base, quote, entry_spread_pct, exit_spread_pct, volume_asset, n_orders, stop_loss, status, trades_count, total_pnl, created_at, updated_at
+		SELECT id, symbol, base, quote, entry_spread_pct, exit_spread_pct, volume_asset, n_orders, stop_loss, entry_mode, strategy, funding_diff_pct, max_hold_hours, leverage, margin_mode, accounts, status, trades_count, total_pnl, created_at, updated_at
 		FROM pairs
 		WHERE status = $1
 		ORDER BY created_at DESC`
@@ -259,6 +308,13 @@ func (r *PairRepository) GetPaused() ([]*models.PairConfig, error) {
 			&pair.VolumeAsset,
 			&pair.NOrders,
 			&pair.StopLoss,
+			&pair.EntryMode,
+			&pair.Strategy,
+			&pair.FundingDiffPct,
+			&pair.MaxHoldHours,
+			&pair.Leverage,
+			&pair.MarginMode,
+			pq.Array(&pair.Accounts),
 			&pair.Status,
 			&pair.TradesCount,
 			&pair.TotalPnl,
@@ -282,8 +338,8 @@ func (r *PairRepository) GetPaused() ([]*models.PairConfig, error) {
 func (r *PairRepository) Update(pair *models.PairConfig) error {
 	query := `
 		UPDATE pairs
-		SET symbol = $1, base = $2, quote = $3, entry_spread_pct = $4, exit_spread_pct = $5, volume_asset = $6, n_orders = $7, stop_loss = $8, status = $9, trades_count = $10, total_pnl = $11, updated_at = $12
-		WHERE id = $13`
+		SET symbol = $1, base = $2, quote = $3, entry_spread_pct = $4, exit_spread_pct = $5, volume_asset = $6, n_orders = $7, stop_loss = $8, entry_mode = $9, strategy = $10, funding_diff_pct = $11, max_hold_hours = $12, leverage = $13, margin_mode = $14, accounts = $15, status = $16, trades_count = $17, total_pnl = $18, updated_at = $19
+		WHERE id = $20`
 
 	pair.UpdatedAt = time.Now()
 
@@ -297,6 +353,13 @@ func (r *PairRepository) Update(pair *models.PairConfig) error {
 		pair.VolumeAsset,
 		pair.NOrders,
 		pair.StopLoss,
+		pair.EntryMode,
+		pair.Strategy,
+		pair.FundingDiffPct,
+		pair.MaxHoldHours,
+		pair.Leverage,
+		pair.MarginMode,
+		accountsArray(pair.Accounts),
 		pair.Status,
 		pair.TradesCount,
 		pair.TotalPnl,
@@ -320,13 +383,26 @@ func (r *PairRepository) Update(pair *models.PairConfig) error {
 }
 
 // UpdateParams обновляет только торговые параметры пары (без статуса и статистики)
-func (r *PairRepository) UpdateParams(id int, entrySpread, exitSpread, volume float64, nOrders int, stopLoss float64) error {
+func (r *PairRepository) UpdateParams(id int, entrySpread, exitSpread, volume float64, nOrders int, stopLoss float64, entryMode, strategy string, fundingDiff float64, maxHoldHours, leverage int, marginMode string, accounts []string) error {
 	query := `
 		UPDATE pairs
-		SET entry_spread_pct = $1, exit_spread_pct = $2, volume_asset = $3, n_orders = $4, stop_loss = $5, updated_at = $6
-		WHERE id = $7`
+		SET entry_spread_pct = $1, exit_spread_pct = $2, volume_asset = $3, n_orders = $4, stop_loss = $5, entry_mode = $6, strategy = $7, funding_diff_pct = $8, max_hold_hours = $9, leverage = $10, margin_mode = $11, accounts = $12, updated_at = $13
+		WHERE id = $14`
 
-	result, err := r.db.Exec(query, entrySpread, exitSpread, volume, nOrders, stopLoss, time.Now(), id)
+	if entryMode == "" {
+		entryMode = models.EntryModeTaker
+	}
+	if strategy == "" {
+		strategy = models.StrategySpread
+	}
+	if leverage == 0 {
+		leverage = 1
+	}
+	if marginMode == "" {
+		marginMode = models.MarginModeCross
+	}
+
+	result, err := r.db.Exec(query, entrySpread, exitSpread, volume, nOrders, stopLoss, entryMode, strategy, fundingDiff, maxHoldHours, leverage, marginMode, accountsArray(accounts), time.Now(), id)
 	if err != nil {
 		return err
 	}
@@ -507,7 +583,7 @@ func (r *PairRepository) ExistsBySymbol(symbol string) (bool, error) {
 // Search ищет пары по части символа
 func (r *PairRepository) Search(searchQuery string) ([]*models.PairConfig, error) {
 	query := `
-		SELECT id, symbol, base, quote, entry_spread_pct, exit_spread_pct, volume_asset, n_orders, stop_loss, status, trades_count, total_pnl, created_at, updated_at
+		SELECT id, symbol, base, quote, entry_spread_pct, exit_spread_pct, volume_asset, n_orders, stop_loss, entry_mode, strategy, funding_diff_pct, max_hold_hours, leverage, margin_mode, accounts, status, trades_count, total_pnl, created_at, updated_at
 		FROM pairs
 		WHERE LOWER(symbol) LIKE LOWER($1) OR LOWER(base) LIKE LOWER($2)
 		ORDER BY symbol`
@@ -532,6 +608,13 @@ func (r *PairRepository) Search(searchQuery string) ([]*models.PairConfig, error
 			&pair.VolumeAsset,
 			&pair.NOrders,
 			&pair.StopLoss,
+			&pair.EntryMode,
+			&pair.Strategy,
+			&pair.FundingDiffPct,
+			&pair.MaxHoldHours,
+			&pair.Leverage,
+			&pair.MarginMode,
+			pq.Array(&pair.Accounts),
 			&pair.Status,
 			&pair.TradesCount,
 			&pair.TotalPnl,
@@ -559,3 +642,12 @@ func isPairUniqueViolation(err error) bool {
 	errStr := err.Error()
 	return strings.Contains(errStr, "duplicate key") || strings.Contains(errStr, "23505")
 }
+
+// accountsArray возвращает аккаунты пары для колонки TEXT[]
+// nil сохраняется пустым массивом: колонка NOT NULL
+func accountsArray(accounts []string) pq.StringArray {
+	if accounts == nil {
+		return pq.StringArray{}
+	}
+	return pq.StringArray(accounts)
+}
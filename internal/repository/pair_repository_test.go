@@ -52,7 +52,7 @@ func TestPairRepositoryCreate(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(`INSERT INTO pairs`).
-					WithArgs("BTCUSDT", "BTC", "USDT", 0.1, 0.05, 0.01, 1, 50.0, models.PairStatusPaused, 0, float64(0), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WithArgs("BTCUSDT", "BTC", "USDT", 0.1, 0.05, 0.01, 1, 50.0, models.EntryModeTaker, models.StrategySpread, float64(0), 0, 1, models.MarginModeCross, "{}", models.PairStatusPaused, 0, float64(0), sqlmock.AnyArg(), sqlmock.AnyArg()).
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 			},
 			expectError: nil,
@@ -66,7 +66,7 @@ func TestPairRepositoryCreate(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(`INSERT INTO pairs`).
-					WithArgs("BTCUSDT", "BTC", "USDT", float64(0), float64(0), float64(0), 1, float64(0), models.PairStatusPaused, 0, float64(0), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WithArgs("BTCUSDT", "BTC", "USDT", float64(0), float64(0), float64(0), 1, float64(0), models.EntryModeTaker, models.StrategySpread, float64(0), 0, 1, models.MarginModeCross, "{}", models.PairStatusPaused, 0, float64(0), sqlmock.AnyArg(), sqlmock.AnyArg()).
 					WillReturnError(errors.New("duplicate key value violates unique constraint"))
 			},
 			expectError: ErrPairExists,
@@ -86,7 +86,7 @@ func TestPairRepositoryCreate(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectQuery(`INSERT INTO pairs`).
-					WithArgs("ETHUSDT", "ETH", "USDT", 0.15, 0.1, 0.1, 2, 30.0, models.PairStatusActive, 0, float64(0), sqlmock.AnyArg(), sqlmock.AnyArg()).
+					WithArgs("ETHUSDT", "ETH", "USDT", 0.15, 0.1, 0.1, 2, 30.0, models.EntryModeTaker, models.StrategySpread, float64(0), 0, 1, models.MarginModeCross, "{}", models.PairStatusActive, 0, float64(0), sqlmock.AnyArg(), sqlmock.AnyArg()).
 					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
 			},
 			expectError: nil,
@@ -139,8 +139,8 @@ func TestPairRepositoryGetByID(t *testing.T) {
 			name: "success",
 			id:   1,
 			mockSetup: func(mock sqlmock.Sqlmock) {
-				rows := sqlmock.NewRows([]string{"id", "symbol", "base", "quote", "entry_spread_pct", "exit_spread_pct", "volume_asset", "n_orders", "stop_loss", "status", "trades_count", "total_pnl", "created_at", "updated_at"}).
-					AddRow(1, "BTCUSDT", "BTC", "USDT", 0.1, 0.05, 0.01, 1, 50.0, "active", 10, 100.5, now, now)
+				rows := sqlmock.NewRows([]string{"id", "symbol", "base", "quote", "entry_spread_pct", "exit_spread_pct", "volume_asset", "n_orders", "stop_loss", "entry_mode", "strategy", "funding_diff_pct", "max_hold_hours", "leverage", "margin_mode", "accounts", "status", "trades_count", "total_pnl", "created_at", "updated_at"}).
+					AddRow(1, "BTCUSDT", "BTC", "USDT", 0.1, 0.05, 0.01, 1, 50.0, "taker", "spread", 0.0, 0, 1, "cross", "{}", "active", 10, 100.5, now, now)
 				mock.ExpectQuery(`SELECT .+ FROM pairs WHERE id = \$1`).
 					WithArgs(1).
 					WillReturnRows(rows)
@@ -219,8 +219,8 @@ func TestPairRepositoryGetBySymbol(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "symbol", "base", "quote", "entry_spread_pct", "exit_spread_pct", "volume_asset", "n_orders", "stop_loss", "status", "trades_count", "total_pnl", "created_at", "updated_at"}).
-		AddRow(1, "ETHUSDT", "ETH", "USDT", 0.15, 0.1, 0.1, 2, 30.0, "paused", 5, 50.0, now, now)
+	rows := sqlmock.NewRows([]string{"id", "symbol", "base", "quote", "entry_spread_pct", "exit_spread_pct", "volume_asset", "n_orders", "stop_loss", "entry_mode", "strategy", "funding_diff_pct", "max_hold_hours", "leverage", "margin_mode", "accounts", "status", "trades_count", "total_pnl", "created_at", "updated_at"}).
+		AddRow(1, "ETHUSDT", "ETH", "USDT", 0.15, 0.1, 0.1, 2, 30.0, "taker", "spread", 0.0, 0, 1, "cross", "{arb1@bybit,okx}", "paused", 5, 50.0, now, now)
 	mock.ExpectQuery(`SELECT .+ FROM pairs WHERE symbol = \$1`).
 		WithArgs("ETHUSDT").
 		WillReturnRows(rows)
@@ -234,6 +234,9 @@ func TestPairRepositoryGetBySymbol(t *testing.T) {
 	if result.Symbol != "ETHUSDT" {
 		t.Errorf("expected Symbol=ETHUSDT, got %s", result.Symbol)
 	}
+	if len(result.Accounts) != 2 || result.Accounts[0] != "arb1@bybit" || result.Accounts[1] != "okx" {
+		t.Errorf("expected accounts [arb1@bybit okx], got %v", result.Accounts)
+	}
 
 	if err := mock.ExpectationsWereMet(); err != nil {
 		t.Errorf("unfulfilled expectations: %v", err)
@@ -249,9 +252,9 @@ func TestPairRepositoryGetAll(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "symbol", "base", "quote", "entry_spread_pct", "exit_spread_pct", "volume_asset", "n_orders", "stop_loss", "status", "trades_count", "total_pnl", "created_at", "updated_at"}).
-		AddRow(1, "BTCUSDT", "BTC", "USDT", 0.1, 0.05, 0.01, 1, 50.0, "active", 10, 100.5, now, now).
-		AddRow(2, "ETHUSDT", "ETH", "USDT", 0.15, 0.1, 0.1, 2, 30.0, "paused", 5, 50.0, now, now)
+	rows := sqlmock.NewRows([]string{"id", "symbol", "base", "quote", "entry_spread_pct", "exit_spread_pct", "volume_asset", "n_orders", "stop_loss", "entry_mode", "strategy", "funding_diff_pct", "max_hold_hours", "leverage", "margin_mode", "accounts", "status", "trades_count", "total_pnl", "created_at", "updated_at"}).
+		AddRow(1, "BTCUSDT", "BTC", "USDT", 0.1, 0.05, 0.01, 1, 50.0, "taker", "spread", 0.0, 0, 1, "cross", "{}", "active", 10, 100.5, now, now).
+		AddRow(2, "ETHUSDT", "ETH", "USDT", 0.15, 0.1, 0.1, 2, 30.0, "taker", "spread", 0.0, 0, 1, "cross", "{}", "paused", 5, 50.0, now, now)
 	mock.ExpectQuery(`SELECT .+ FROM pairs ORDER BY created_at DESC`).
 		WillReturnRows(rows)
 
@@ -279,8 +282,8 @@ func TestPairRepositoryGetActive(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "symbol", "base", "quote", "entry_spread_pct", "exit_spread_pct", "volume_asset", "n_orders", "stop_loss", "status", "trades_count", "total_pnl", "created_at", "updated_at"}).
-		AddRow(1, "BTCUSDT", "BTC", "USDT", 0.1, 0.05, 0.01, 1, 50.0, "active", 10, 100.5, now, now)
+	rows := sqlmock.NewRows([]string{"id", "symbol", "base", "quote", "entry_spread_pct", "exit_spread_pct", "volume_asset", "n_orders", "stop_loss", "entry_mode", "strategy", "funding_diff_pct", "max_hold_hours", "leverage", "margin_mode", "accounts", "status", "trades_count", "total_pnl", "created_at", "updated_at"}).
+		AddRow(1, "BTCUSDT", "BTC", "USDT", 0.1, 0.05, 0.01, 1, 50.0, "taker", "spread", 0.0, 0, 1, "cross", "{}", "active", 10, 100.5, now, now)
 	mock.ExpectQuery(`SELECT .+ FROM pairs WHERE status = \$1`).
 		WithArgs(models.PairStatusActive).
 		WillReturnRows(rows)
@@ -312,8 +315,8 @@ func TestPairRepositoryGetPaused(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "symbol", "base", "quote", "entry_spread_pct", "exit_spread_pct", "volume_asset", "n_orders", "stop_loss", "status", "trades_count", "total_pnl", "created_at", "updated_at"}).
-		AddRow(2, "ETHUSDT", "ETH", "USDT", 0.15, 0.1, 0.1, 2, 30.0, "paused", 5, 50.0, now, now)
+	rows := sqlmock.NewRows([]string{"id", "symbol", "base", "quote", "entry_spread_pct", "exit_spread_pct", "volume_asset", "n_orders", "stop_loss", "entry_mode", "strategy", "funding_diff_pct", "max_hold_hours", "leverage", "margin_mode", "accounts", "status", "trades_count", "total_pnl", "created_at", "updated_at"}).
+		AddRow(2, "ETHUSDT", "ETH", "USDT", 0.15, 0.1, 0.1, 2, 30.0, "taker", "spread", 0.0, 0, 1, "cross", "{}", "paused", 5, 50.0, now, now)
 	mock.ExpectQuery(`SELECT .+ FROM pairs WHERE status = \$1`).
 		WithArgs(models.PairStatusPaused).
 		WillReturnRows(rows)
@@ -361,7 +364,7 @@ func TestPairRepositoryUpdate(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectExec(`UPDATE pairs SET`).
-					WithArgs("BTCUSDT", "BTC", "USDT", 0.2, 0.1, 0.02, 2, 100.0, "active", 10, 200.0, sqlmock.AnyArg(), 1).
+					WithArgs("BTCUSDT", "BTC", "USDT", 0.2, 0.1, 0.02, 2, 100.0, "", "", float64(0), 0, 0, "", "{}", "active", 10, 200.0, sqlmock.AnyArg(), 1).
 					WillReturnResult(sqlmock.NewResult(0, 1))
 			},
 			expectError: nil,
@@ -374,7 +377,7 @@ func TestPairRepositoryUpdate(t *testing.T) {
 			},
 			mockSetup: func(mock sqlmock.Sqlmock) {
 				mock.ExpectExec(`UPDATE pairs SET`).
-					WithArgs("UNKNOWN", "", "", float64(0), float64(0), float64(0), 0, float64(0), "", 0, float64(0), sqlmock.AnyArg(), 999).
+					WithArgs("UNKNOWN", "", "", float64(0), float64(0), float64(0), 0, float64(0), "", "", float64(0), 0, 0, "", "{}", "", 0, float64(0), sqlmock.AnyArg(), 999).
 					WillReturnResult(sqlmock.NewResult(0, 0))
 			},
 			expectError: ErrPairNotFound,
@@ -418,12 +421,12 @@ func TestPairRepositoryUpdateParams(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectExec(`UPDATE pairs SET entry_spread_pct = \$1, exit_spread_pct = \$2, volume_asset = \$3, n_orders = \$4, stop_loss = \$5, updated_at = \$6 WHERE id = \$7`).
-		WithArgs(0.25, 0.15, 0.05, 3, 75.0, sqlmock.AnyArg(), 1).
+	mock.ExpectExec(`UPDATE pairs SET entry_spread_pct = \$1, exit_spread_pct = \$2, volume_asset = \$3, n_orders = \$4, stop_loss = \$5, entry_mode = \$6, strategy = \$7, funding_diff_pct = \$8, max_hold_hours = \$9, leverage = \$10, margin_mode = \$11, accounts = \$12, updated_at = \$13 WHERE id = \$14`).
+		WithArgs(0.25, 0.15, 0.05, 3, 75.0, models.EntryModeMakerTaker, models.StrategyFunding, 0.02, 48, 5, models.MarginModeIsolated, `{"arb1@bybit","okx"}`, sqlmock.AnyArg(), 1).
 		WillReturnResult(sqlmock.NewResult(0, 1))
 
 	repo := NewPairRepository(db)
-	err = repo.UpdateParams(1, 0.25, 0.15, 0.05, 3, 75.0)
+	err = repo.UpdateParams(1, 0.25, 0.15, 0.05, 3, 75.0, models.EntryModeMakerTaker, models.StrategyFunding, 0.02, 48, 5, models.MarginModeIsolated, []string{"arb1@bybit", "okx"})
 
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
@@ -733,8 +736,8 @@ func TestPairRepositorySearch(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "symbol", "base", "quote", "entry_spread_pct", "exit_spread_pct", "volume_asset", "n_orders", "stop_loss", "status", "trades_count", "total_pnl", "created_at", "updated_at"}).
-		AddRow(1, "BTCUSDT", "BTC", "USDT", 0.1, 0.05, 0.01, 1, 50.0, "active", 10, 100.5, now, now)
+	rows := sqlmock.NewRows([]string{"id", "symbol", "base", "quote", "entry_spread_pct", "exit_spread_pct", "volume_asset", "n_orders", "stop_loss", "entry_mode", "strategy", "funding_diff_pct", "max_hold_hours", "leverage", "margin_mode", "accounts", "status", "trades_count", "total_pnl", "created_at", "updated_at"}).
+		AddRow(1, "BTCUSDT", "BTC", "USDT", 0.1, 0.05, 0.01, 1, 50.0, "taker", "spread", 0.0, 0, 1, "cross", "{}", "active", 10, 100.5, now, now)
 	mock.ExpectQuery(`SELECT .+ FROM pairs WHERE LOWER\(symbol\) LIKE LOWER\(\$1\) OR LOWER\(base\) LIKE LOWER\(\$2\)`).
 		WithArgs("%BTC%", "%BTC%").
 		WillReturnRows(rows)
@@ -15,32 +15,51 @@ import (
 
 // Ошибки сервиса
 var (
-	ErrExchangeNotSupported    = errors.New("exchange is not supported")
+	ErrExchangeNotSupported     = errors.New("exchange is not supported")
 	ErrExchangeAlreadyConnected = errors.New("exchange is already connected")
-	ErrExchangeNotConnected    = errors.New("exchange is not connected")
-	ErrInvalidCredentials      = errors.New("invalid API credentials")
-	ErrConnectionFailed        = errors.New("failed to connect to exchange")
-	ErrHasActivePositions      = errors.New("cannot disconnect: exchange has active positions")
+	ErrExchangeNotConnected     = errors.New("exchange is not connected")
+	ErrInvalidCredentials       = errors.New("invalid API credentials")
+	ErrConnectionFailed         = errors.New("failed to connect to exchange")
+	ErrHasActivePositions       = errors.New("cannot disconnect: exchange has active positions")
+	ErrInvalidAccountLabel      = errors.New("account label must be up to 32 characters: a-z, 0-9, _ or -")
 )
 
 // BalanceBroadcaster - интерфейс для отправки обновлений балансов через WebSocket
+// Балансы передаются по идентификатору аккаунта (exchange.AccountKey)
 type BalanceBroadcaster interface {
-	BroadcastBalanceUpdate(exchange string, balance float64)
+	BroadcastBalanceUpdate(account string, balance float64)
 	BroadcastAllBalances(balances map[string]float64)
 }
 
+// ExchangeEngine - интерфейс торгового движка для синхронизации подключенных бирж
+type ExchangeEngine interface {
+	// AddExchange передаёт движку подключенную биржу
+	AddExchange(name string, exch exchange.Exchange)
+	// RemoveExchange убирает биржу из движка
+	RemoveExchange(name string)
+	// PausePair останавливает пару (без закрытия позиций)
+	PausePair(pairID int) error
+}
+
 // ExchangeService - бизнес-логика для управления биржами
+//
+// Методы принимают идентификатор аккаунта (exchange.AccountKey): "bybit" -
+// основной аккаунт биржи, "arb1@bybit" - субаккаунт с меткой arb1.
+// Соединения, движок и балансы работают с тем же идентификатором.
 type ExchangeService struct {
 	exchangeRepo  *repository.ExchangeRepository
 	pairRepo      *repository.PairRepository
 	encryptionKey []byte
 
-	// Кэш активных соединений с биржами
+	// Кэш активных соединений с аккаунтами бирж
 	connections   map[string]exchange.Exchange
 	connectionsMu sync.RWMutex // Защита от race condition при конкурентном доступе
 
 	// WebSocket hub для broadcast балансов
 	wsHub BalanceBroadcaster
+
+	// Торговый движок (может быть nil при инициализации)
+	engine ExchangeEngine
 }
 
 // NewExchangeService создает новый экземпляр сервиса
@@ -67,28 +86,51 @@ func (s *ExchangeService) SetWebSocketHub(hub BalanceBroadcaster) {
 	s.wsHub = hub
 }
 
-// ConnectExchange подключает биржу с указанными API ключами
+// SetEngine устанавливает торговый движок
+// После вызова все подключения и отключения бирж передаются в движок
+func (s *ExchangeService) SetEngine(engine ExchangeEngine) {
+	s.engine = engine
+}
+
+// AttachConnection добавляет в кэш уже установленное соединение
+// Используется после восстановления при старте, чтобы API и движок
+// работали через одно соединение, а не открывали второе
+func (s *ExchangeService) AttachConnection(name string, conn exchange.Exchange) {
+	name = strings.ToLower(name)
+
+	s.connectionsMu.Lock()
+	defer s.connectionsMu.Unlock()
+
+	if _, exists := s.connections[name]; !exists {
+		s.connections[name] = conn
+	}
+}
+
+// ConnectExchange подключает аккаунт биржи с указанными API ключами
 // Выполняет:
-// 1. Проверку поддержки биржи
+// 1. Проверку поддержки биржи и метки аккаунта
 // 2. Тестовое подключение (проверка ключей)
 // 3. Шифрование ключей перед сохранением
 // 4. Сохранение в БД
 func (s *ExchangeService) ConnectExchange(ctx context.Context, name, apiKey, secretKey, passphrase string) error {
-	name = strings.ToLower(name)
-
 	// 1. Проверяем, поддерживается ли биржа
-	if !exchange.IsSupported(name) {
-		return ErrExchangeNotSupported
+	venue, label, err := parseAccount(name)
+	if err != nil {
+		return err
+	}
+	name = exchange.AccountKey(venue, label)
+	if exchange.RequiresPassphrase(venue) && passphrase == "" {
+		return errors.Join(ErrInvalidCredentials, errors.New("passphrase is required for "+venue))
 	}
 
-	// 2. Проверяем, не подключена ли уже биржа
-	existing, err := s.exchangeRepo.GetByName(name)
+	// 2. Проверяем, не подключен ли уже аккаунт
+	existing, err := s.exchangeRepo.GetByAccount(venue, label)
 	if err == nil && existing.Connected {
 		return ErrExchangeAlreadyConnected
 	}
 
 	// 3. Создаем экземпляр биржи через фабрику
-	exch, err := exchange.NewExchange(name)
+	exch, err := exchange.NewExchange(venue)
 	if err != nil {
 		return err
 	}
@@ -146,7 +188,8 @@ func (s *ExchangeService) ConnectExchange(ctx context.Context, name, apiKey, sec
 	} else {
 		// Создаем новую запись
 		account := &models.ExchangeAccount{
-			Name:       name,
+			Name:       venue,
+			Label:      label,
 			APIKey:     encryptedAPIKey,
 			SecretKey:  encryptedSecretKey,
 			Passphrase: encryptedPassphrase,
@@ -166,19 +209,28 @@ func (s *ExchangeService) ConnectExchange(ctx context.Context, name, apiKey, sec
 	s.connections[name] = exch
 	s.connectionsMu.Unlock()
 
+	// 9. Передаём биржу в торговый движок
+	if s.engine != nil {
+		s.engine.AddExchange(name, exch)
+	}
+
 	return nil
 }
 
-// DisconnectExchange отключает биржу
+// DisconnectExchange отключает аккаунт биржи
 // Выполняет:
 // 1. Проверку наличия подключения
-// 2. Остановку всех активных пар (ставит на паузу)
+// 2. Остановку пар, которым не хватит аккаунтов (ставит на паузу)
 // 3. Удаление ключей из БД
 func (s *ExchangeService) DisconnectExchange(ctx context.Context, name string) error {
-	name = strings.ToLower(name)
+	venue, label, err := parseAccount(name)
+	if err != nil {
+		return err
+	}
+	name = exchange.AccountKey(venue, label)
 
-	// 1. Проверяем, подключена ли биржа
-	account, err := s.exchangeRepo.GetByName(name)
+	// 1. Проверяем, подключен ли аккаунт
+	account, err := s.exchangeRepo.GetByAccount(venue, label)
 	if err != nil {
 		if errors.Is(err, repository.ErrExchangeNotFound) {
 			return ErrExchangeNotConnected
@@ -190,32 +242,44 @@ func (s *ExchangeService) DisconnectExchange(ctx context.Context, name string) e
 		return ErrExchangeNotConnected
 	}
 
-	// 2. Получаем все активные пары и ставим их на паузу
-	// Пары, которые используют эту биржу, должны быть остановлены
+	// 2. Получаем все активные пары и ставим на паузу те,
+	// у которых после отключения останется меньше 2 аккаунтов
 	activePairs, err := s.pairRepo.GetActive()
 	if err != nil {
 		return err
 	}
 
-	// Примечание: в текущей архитектуре пары не привязаны к конкретным биржам,
-	// бот сам выбирает биржи на основе спреда. Однако при отключении биржи
-	// если осталось меньше 2 подключенных бирж, нужно остановить все пары.
-	connectedCount, err := s.exchangeRepo.CountConnected()
+	// Пара без привязки использует любые подключенные аккаунты,
+	// привязанная (PairConfig.Accounts) - только свои
+	connected, err := s.exchangeRepo.GetConnected()
 	if err != nil {
 		return err
 	}
+	remaining := make([]string, 0, len(connected))
+	for _, acc := range connected {
+		if key := exchange.AccountKey(acc.Name, acc.Label); key != name {
+			remaining = append(remaining, key)
+		}
+	}
 
-	// Если после отключения останется меньше 2 бирж - останавливаем все пары
-	if connectedCount <= 2 {
-		for _, pair := range activePairs {
-			if err := s.pairRepo.UpdateStatus(pair.ID, models.PairStatusPaused); err != nil {
-				// Логируем ошибку, но продолжаем
-				continue
-			}
+	for _, pair := range activePairs {
+		if countPairAccounts(pair, remaining) >= 2 {
+			continue
+		}
+		if err := s.pairRepo.UpdateStatus(pair.ID, models.PairStatusPaused); err != nil {
+			// Логируем ошибку, но продолжаем
+			continue
 		}
+		if s.engine != nil {
+			_ = s.engine.PausePair(pair.ID)
+		}
+	}
+
+	// 3. Убираем биржу из движка и закрываем соединение (если есть в кэше)
+	if s.engine != nil {
+		s.engine.RemoveExchange(name)
 	}
 
-	// 3. Закрываем соединение с биржей (если есть в кэше)
 	s.connectionsMu.Lock()
 	if conn, exists := s.connections[name]; exists {
 		_ = conn.Close()
@@ -223,7 +287,7 @@ func (s *ExchangeService) DisconnectExchange(ctx context.Context, name string) e
 	}
 	s.connectionsMu.Unlock()
 
-	// 4. Помечаем биржу как отключенную и очищаем ключи
+	// 4. Помечаем аккаунт как отключенный и очищаем ключи
 	account.Connected = false
 	account.APIKey = ""
 	account.SecretKey = ""
@@ -235,14 +299,15 @@ func (s *ExchangeService) DisconnectExchange(ctx context.Context, name string) e
 	return s.exchangeRepo.Update(account)
 }
 
-// UpdateBalance обновляет баланс биржи
+// UpdateBalance обновляет баланс аккаунта биржи
 // Запрашивает актуальный баланс через API биржи
 // После успешного обновления отправляет broadcast через WebSocket
 func (s *ExchangeService) UpdateBalance(ctx context.Context, name string) (float64, error) {
-	name = strings.ToLower(name)
+	venue, label := exchange.SplitAccountKey(name)
+	name = exchange.AccountKey(venue, label)
 
-	// 1. Получаем данные биржи из БД
-	account, err := s.exchangeRepo.GetByName(name)
+	// 1. Получаем данные аккаунта из БД
+	account, err := s.exchangeRepo.GetByAccount(venue, label)
 	if err != nil {
 		if errors.Is(err, repository.ErrExchangeNotFound) {
 			return 0, ErrExchangeNotConnected
@@ -287,38 +352,35 @@ func (s *ExchangeService) UpdateBalance(ctx context.Context, name string) (float
 	return balance, nil
 }
 
-// GetAllExchanges возвращает список всех бирж с их статусами
-// Для каждой поддерживаемой биржи возвращает информацию о подключении
+// GetAllExchanges возвращает список всех аккаунтов бирж с их статусами
+// Для каждой поддерживаемой биржи возвращает основной аккаунт (даже не подключенный)
+// и следом её субаккаунты из БД
 func (s *ExchangeService) GetAllExchanges() ([]*models.ExchangeAccount, error) {
-	// Получаем все биржи из БД
+	// Получаем все аккаунты из БД (по имени биржи и метке)
 	dbExchanges, err := s.exchangeRepo.GetAll()
 	if err != nil {
 		return nil, err
 	}
 
-	// Создаем map для быстрого поиска
-	dbMap := make(map[string]*models.ExchangeAccount)
+	// Группируем по бирже: основной аккаунт и субаккаунты
+	mainAccounts := make(map[string]*models.ExchangeAccount)
+	subAccounts := make(map[string][]*models.ExchangeAccount)
 	for _, ex := range dbExchanges {
-		dbMap[ex.Name] = ex
+		if ex.Label == "" {
+			mainAccounts[ex.Name] = ex
+		} else {
+			subAccounts[ex.Name] = append(subAccounts[ex.Name], ex)
+		}
 	}
 
 	// Формируем полный список (включая неподключенные биржи)
-	result := make([]*models.ExchangeAccount, 0, len(exchange.SupportedExchanges))
-
-	for _, name := range exchange.SupportedExchanges {
-		if dbAccount, exists := dbMap[name]; exists {
-			// Биржа есть в БД - очищаем ключи перед отправкой
-			safeCopy := &models.ExchangeAccount{
-				ID:        dbAccount.ID,
-				Name:      dbAccount.Name,
-				Connected: dbAccount.Connected,
-				Balance:   dbAccount.Balance,
-				LastError: dbAccount.LastError,
-				UpdatedAt: dbAccount.UpdatedAt,
-				CreatedAt: dbAccount.CreatedAt,
-				// APIKey, SecretKey, Passphrase не возвращаем!
-			}
-			result = append(result, safeCopy)
+	supported := exchange.SupportedExchanges()
+	result := make([]*models.ExchangeAccount, 0, len(supported)+len(dbExchanges))
+
+	for _, name := range supported {
+		if dbAccount, exists := mainAccounts[name]; exists {
+			// Аккаунт есть в БД - очищаем ключи перед отправкой
+			result = append(result, safeAccount(dbAccount))
 		} else {
 			// Биржа не в БД - возвращаем пустую запись
 			result = append(result, &models.ExchangeAccount{
@@ -327,6 +389,9 @@ func (s *ExchangeService) GetAllExchanges() ([]*models.ExchangeAccount, error) {
 				Balance:   0,
 			})
 		}
+		for _, sub := range subAccounts[name] {
+			result = append(result, safeAccount(sub))
+		}
 	}
 
 	return result, nil
@@ -337,30 +402,22 @@ func (s *ExchangeService) GetConnectedExchanges() ([]*models.ExchangeAccount, er
 	return s.exchangeRepo.GetConnected()
 }
 
-// GetExchangeByName возвращает биржу по имени
+// GetExchangeByName возвращает аккаунт биржи по идентификатору
 func (s *ExchangeService) GetExchangeByName(name string) (*models.ExchangeAccount, error) {
-	name = strings.ToLower(name)
-	account, err := s.exchangeRepo.GetByName(name)
+	account, err := s.exchangeRepo.GetByAccount(exchange.SplitAccountKey(name))
 	if err != nil {
 		return nil, err
 	}
 
 	// Очищаем ключи перед возвратом
-	return &models.ExchangeAccount{
-		ID:        account.ID,
-		Name:      account.Name,
-		Connected: account.Connected,
-		Balance:   account.Balance,
-		LastError: account.LastError,
-		UpdatedAt: account.UpdatedAt,
-		CreatedAt: account.CreatedAt,
-	}, nil
+	return safeAccount(account), nil
 }
 
-// GetConnection возвращает активное соединение с биржей
+// GetConnection возвращает активное соединение с аккаунтом биржи
 // Используется торговым движком для выполнения операций
 func (s *ExchangeService) GetConnection(ctx context.Context, name string) (exchange.Exchange, error) {
-	name = strings.ToLower(name)
+	venue, label := exchange.SplitAccountKey(name)
+	name = exchange.AccountKey(venue, label)
 
 	// Проверяем кэш (read lock)
 	s.connectionsMu.RLock()
@@ -372,7 +429,7 @@ func (s *ExchangeService) GetConnection(ctx context.Context, name string) (excha
 	}
 
 	// Получаем данные из БД и создаем соединение
-	account, err := s.exchangeRepo.GetByName(name)
+	account, err := s.exchangeRepo.GetByAccount(venue, label)
 	if err != nil {
 		return nil, err
 	}
@@ -384,9 +441,10 @@ func (s *ExchangeService) GetConnection(ctx context.Context, name string) (excha
 	return s.getOrCreateConnection(ctx, name, account)
 }
 
-// UpdateAllBalances обновляет балансы всех подключенных бирж
+// UpdateAllBalances обновляет балансы всех подключенных аккаунтов
 // Вызывается периодически (каждую минуту) из торгового движка
 // После обновления отправляет broadcast всех балансов через WebSocket
+// Результат - по идентификатору аккаунта
 func (s *ExchangeService) UpdateAllBalances(ctx context.Context) map[string]float64 {
 	result := make(map[string]float64)
 
@@ -396,12 +454,13 @@ func (s *ExchangeService) UpdateAllBalances(ctx context.Context) map[string]floa
 	}
 
 	for _, account := range connected {
-		balance, err := s.UpdateBalance(ctx, account.Name)
+		key := exchange.AccountKey(account.Name, account.Label)
+		balance, err := s.UpdateBalance(ctx, key)
 		if err != nil {
 			// Логируем ошибку, но продолжаем
 			continue
 		}
-		result[account.Name] = balance
+		result[key] = balance
 	}
 
 	// Broadcast всех балансов одним сообщением (для начальной загрузки UI)
@@ -413,6 +472,7 @@ func (s *ExchangeService) UpdateAllBalances(ctx context.Context) map[string]floa
 }
 
 // getOrCreateConnection получает соединение из кэша или создает новое
+// name - идентификатор аккаунта
 func (s *ExchangeService) getOrCreateConnection(ctx context.Context, name string, account *models.ExchangeAccount) (exchange.Exchange, error) {
 	// Проверяем кэш (read lock)
 	s.connectionsMu.RLock()
@@ -443,7 +503,7 @@ func (s *ExchangeService) getOrCreateConnection(ctx context.Context, name string
 	}
 
 	// Создаем новое соединение
-	conn, err = exchange.NewExchange(name)
+	conn, err = exchange.NewExchange(account.Name)
 	if err != nil {
 		return nil, err
 	}
@@ -457,6 +517,11 @@ func (s *ExchangeService) getOrCreateConnection(ctx context.Context, name string
 	s.connections[name] = conn
 	s.connectionsMu.Unlock()
 
+	// Новое соединение нужно и движку
+	if s.engine != nil {
+		s.engine.AddExchange(name, conn)
+	}
+
 	return conn, nil
 }
 
@@ -473,12 +538,12 @@ func (s *ExchangeService) Close() error {
 	return nil
 }
 
-// CountConnected возвращает количество подключенных бирж
+// CountConnected возвращает количество подключенных аккаунтов
 func (s *ExchangeService) CountConnected() (int, error) {
 	return s.exchangeRepo.CountConnected()
 }
 
-// HasMinimumExchanges проверяет, подключено ли минимум 2 биржи
+// HasMinimumExchanges проверяет, подключено ли минимум 2 аккаунта
 // Необходимо для работы арбитража
 func (s *ExchangeService) HasMinimumExchanges() (bool, error) {
 	count, err := s.exchangeRepo.CountConnected()
@@ -487,3 +552,41 @@ func (s *ExchangeService) HasMinimumExchanges() (bool, error) {
 	}
 	return count >= 2, nil
 }
+
+// parseAccount разбирает идентификатор аккаунта и проверяет биржу и метку
+func parseAccount(key string) (venue, label string, err error) {
+	venue, label = exchange.SplitAccountKey(key)
+	if !exchange.IsSupported(venue) {
+		return "", "", ErrExchangeNotSupported
+	}
+	if !exchange.ValidAccountLabel(label) {
+		return "", "", ErrInvalidAccountLabel
+	}
+	return venue, label, nil
+}
+
+// safeAccount возвращает копию аккаунта без ключей
+func safeAccount(account *models.ExchangeAccount) *models.ExchangeAccount {
+	return &models.ExchangeAccount{
+		ID:        account.ID,
+		Name:      account.Name,
+		Label:     account.Label,
+		Connected: account.Connected,
+		Balance:   account.Balance,
+		LastError: account.LastError,
+		UpdatedAt: account.UpdatedAt,
+		CreatedAt: account.CreatedAt,
+		// APIKey, SecretKey, Passphrase не возвращаем!
+	}
+}
+
+// countPairAccounts возвращает число аккаунтов из списка, на которых пара может открывать ноги
+func countPairAccounts(pair *models.PairConfig, accounts []string) int {
+	count := 0
+	for _, account := range accounts {
+		if pair.UsesAccount(account) {
+			count++
+		}
+	}
+	return count
+}
@@ -160,6 +160,22 @@ func TestExchangeService_GetExchangeByName(t *testing.T) {
 				m.accounts["bybit"] = &models.ExchangeAccount{ID: 1, Name: "bybit", Connected: true, Balance: 1000}
 			},
 		},
+		{
+			name:         "субаккаунт",
+			exchangeName: "arb1@bybit",
+			setup: func(m *MockExchangeRepository) {
+				m.accounts["bybit"] = &models.ExchangeAccount{ID: 1, Name: "bybit", Connected: true, Balance: 1000}
+				m.accounts["arb1@bybit"] = &models.ExchangeAccount{ID: 2, Name: "bybit", Label: "arb1", Connected: true, Balance: 500}
+			},
+		},
+		{
+			name:         "субаккаунт не найден",
+			exchangeName: "arb2@bybit",
+			setup: func(m *MockExchangeRepository) {
+				m.accounts["bybit"] = &models.ExchangeAccount{ID: 1, Name: "bybit", Connected: true, Balance: 1000}
+			},
+			wantErr: repository.ErrExchangeNotFound,
+		},
 		{
 			name:         "биржа не найдена",
 			exchangeName: "unknown",
@@ -175,7 +191,7 @@ func TestExchangeService_GetExchangeByName(t *testing.T) {
 				tt.setup(mockRepo)
 			}
 
-			account, err := mockRepo.GetByName(tt.exchangeName)
+			account, err := mockRepo.GetByAccount(exchange.SplitAccountKey(tt.exchangeName))
 
 			if tt.wantErr != nil {
 				if err == nil {
@@ -193,8 +209,8 @@ func TestExchangeService_GetExchangeByName(t *testing.T) {
 				return
 			}
 
-			if account.Name != tt.exchangeName {
-				t.Errorf("expected name %s, got %s", tt.exchangeName, account.Name)
+			if key := exchange.AccountKey(account.Name, account.Label); key != tt.exchangeName {
+				t.Errorf("expected account %s, got %s", tt.exchangeName, key)
 			}
 		})
 	}
@@ -575,8 +591,28 @@ func (m *MockExchange) GetOrderBook(ctx context.Context, symbol string, depth in
 	return &exchange.OrderBook{Symbol: symbol}, nil
 }
 
-func (m *MockExchange) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64) (*exchange.Order, error) {
-	return &exchange.Order{ID: "test-order-1", Symbol: symbol, Side: side, Quantity: qty, Status: exchange.OrderStatusFilled}, nil
+func (m *MockExchange) PlaceMarketOrder(ctx context.Context, symbol, side string, qty float64, clientOrderID string) (*exchange.Order, error) {
+	return &exchange.Order{ID: "test-order-1", ClientOrderID: clientOrderID, Symbol: symbol, Side: side, Quantity: qty, Status: exchange.OrderStatusFilled}, nil
+}
+
+func (m *MockExchange) PlaceLimitOrder(ctx context.Context, symbol, side string, qty, price float64, tif string) (*exchange.Order, error) {
+	return &exchange.Order{ID: "test-order-1", Symbol: symbol, Side: side, Quantity: qty, Price: price, Status: exchange.OrderStatusNew}, nil
+}
+
+func (m *MockExchange) CancelOrder(ctx context.Context, symbol, orderID string) error {
+	return nil
+}
+
+func (m *MockExchange) GetOrder(ctx context.Context, symbol, orderID string) (*exchange.Order, error) {
+	return &exchange.Order{ID: orderID, Symbol: symbol, Status: exchange.OrderStatusNew}, nil
+}
+
+func (m *MockExchange) GetOrderByClientID(ctx context.Context, symbol, clientOrderID string) (*exchange.Order, error) {
+	return nil, exchange.ErrOrderNotFound
+}
+
+func (m *MockExchange) GetOpenOrders(ctx context.Context, symbol string) ([]*exchange.Order, error) {
+	return nil, nil
 }
 
 func (m *MockExchange) GetOpenPositions(ctx context.Context) ([]*exchange.Position, error) {
@@ -591,18 +627,46 @@ func (m *MockExchange) SubscribeTicker(symbol string, callback func(*exchange.Ti
 	return nil
 }
 
+func (m *MockExchange) SubscribeOrderBook(symbol string, depth int, callback func(*exchange.OrderBook)) error {
+	return nil
+}
+
 func (m *MockExchange) SubscribePositions(callback func(*exchange.Position)) error {
 	return nil
 }
 
+func (m *MockExchange) SubscribeOrders(callback func(*exchange.Order)) error {
+	return nil
+}
+
 func (m *MockExchange) GetTradingFee(ctx context.Context, symbol string) (float64, error) {
 	return 0.001, nil
 }
 
+func (m *MockExchange) GetTradingFees(ctx context.Context, symbol string) (*exchange.TradingFees, error) {
+	return &exchange.TradingFees{Symbol: symbol, Maker: 0.0005, Taker: 0.001}, nil
+}
+
 func (m *MockExchange) GetLimits(ctx context.Context, symbol string) (*exchange.Limits, error) {
 	return &exchange.Limits{Symbol: symbol, MinOrderQty: 0.001, MaxOrderQty: 1000}, nil
 }
 
+func (m *MockExchange) GetInstrument(ctx context.Context, symbol string) (*exchange.Instrument, error) {
+	return &exchange.Instrument{Symbol: symbol, ContractValue: 1, MinSize: 0.001, MaxSize: 1000}, nil
+}
+
+func (m *MockExchange) GetFundingRate(ctx context.Context, symbol string) (*exchange.FundingRate, error) {
+	return &exchange.FundingRate{Symbol: symbol, Interval: exchange.DefaultFundingInterval}, nil
+}
+
+func (m *MockExchange) SetLeverage(ctx context.Context, symbol string, leverage int) error {
+	return nil
+}
+
+func (m *MockExchange) SetMarginMode(ctx context.Context, symbol, mode string) error {
+	return nil
+}
+
 func (m *MockExchange) Close() error {
 	if m.closeErr != nil {
 		return m.closeErr
@@ -724,3 +788,46 @@ func TestExchangeService_UpdateAllBalances(t *testing.T) {
 		}
 	}
 }
+
+func TestExchangeService_ParseAccount(t *testing.T) {
+	tests := []struct {
+		key       string
+		wantVenue string
+		wantLabel string
+		wantErr   error
+	}{
+		{key: "bybit", wantVenue: "bybit"},
+		{key: "ARB1@Bybit", wantVenue: "bybit", wantLabel: "arb1"},
+		{key: "arb1@unknown", wantErr: ErrExchangeNotSupported},
+		{key: "arb 1@bybit", wantErr: ErrInvalidAccountLabel},
+	}
+
+	for _, tt := range tests {
+		venue, label, err := parseAccount(tt.key)
+		if !errors.Is(err, tt.wantErr) {
+			t.Errorf("%s: expected error %v, got %v", tt.key, tt.wantErr, err)
+			continue
+		}
+		if venue != tt.wantVenue || label != tt.wantLabel {
+			t.Errorf("%s: expected %s/%s, got %s/%s", tt.key, tt.wantVenue, tt.wantLabel, venue, label)
+		}
+	}
+}
+
+func TestExchangeService_CountPairAccounts(t *testing.T) {
+	connected := []string{"bybit", "arb1@bybit", "okx"}
+
+	// Пара без привязки торгует на всех аккаунтах
+	if n := countPairAccounts(&models.PairConfig{}, connected); n != 3 {
+		t.Errorf("expected 3 accounts for unbound pair, got %d", n)
+	}
+
+	// После отключения okx у привязанной пары остаётся один аккаунт
+	bound := &models.PairConfig{Accounts: []string{"arb1@bybit", "okx"}}
+	if n := countPairAccounts(bound, connected); n != 2 {
+		t.Errorf("expected 2 accounts for bound pair, got %d", n)
+	}
+	if n := countPairAccounts(bound, connected[:2]); n != 1 {
+		t.Errorf("expected 1 account after disconnect, got %d", n)
+	}
+}
@@ -69,7 +69,7 @@ type PairRepositoryInterface interface {
 	Update(pair *models.PairConfig) error
 	Delete(id int) error
 	UpdateStatus(id int, status string) error
-	UpdateParams(id int, entrySpread, exitSpread, volume float64, nOrders int, stopLoss float64) error
+	UpdateParams(id int, entrySpread, exitSpread, volume float64, nOrders int, stopLoss float64, entryMode, strategy string, fundingDiff float64, maxHoldHours, leverage int, marginMode string, accounts []string) error
 	Count() (int, error)
 	CountActive() (int, error)
 	ExistsBySymbol(symbol string) (bool, error)
@@ -82,7 +82,7 @@ type PairRepositoryInterface interface {
 // ExchangeRepositoryInterface определяет интерфейс репозитория бирж
 type ExchangeRepositoryInterface interface {
 	Create(account *models.ExchangeAccount) error
-	GetByName(name string) (*models.ExchangeAccount, error)
+	GetByAccount(name, label string) (*models.ExchangeAccount, error)
 	GetByID(id int) (*models.ExchangeAccount, error)
 	GetAll() ([]*models.ExchangeAccount, error)
 	GetConnected() ([]*models.ExchangeAccount, error)
@@ -142,7 +142,8 @@ type StatsServiceInterface interface {
 
 // ExchangeServiceInterface определяет интерфейс сервиса бирж
 type ExchangeServiceInterface interface {
-	// ConnectExchange подключает биржу с API ключами
+	// ConnectExchange подключает аккаунт биржи с API ключами
+	// name - идентификатор аккаунта: "bybit" или "arb1@bybit"
 	ConnectExchange(ctx context.Context, name, apiKey, secretKey, passphrase string) error
 	// DisconnectExchange отключает биржу
 	DisconnectExchange(ctx context.Context, name string) error
@@ -150,7 +151,7 @@ type ExchangeServiceInterface interface {
 	UpdateBalance(ctx context.Context, name string) (float64, error)
 	// GetAllExchanges возвращает список всех бирж
 	GetAllExchanges() ([]*models.ExchangeAccount, error)
-	// GetExchangeByName возвращает биржу по имени
+	// GetExchangeByName возвращает аккаунт биржи по идентификатору
 	GetExchangeByName(name string) (*models.ExchangeAccount, error)
 	// GetConnection возвращает активное соединение с биржей
 	GetConnection(ctx context.Context, name string) (exchange.Exchange, error)
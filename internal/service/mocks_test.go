@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"arbitrage/internal/exchange"
 	"arbitrage/internal/models"
 	"arbitrage/internal/repository"
 )
@@ -653,7 +654,7 @@ func (m *MockPairRepository) UpdateStatus(id int, status string) error {
 	return repository.ErrPairNotFound
 }
 
-func (m *MockPairRepository) UpdateParams(id int, entrySpread, exitSpread, volume float64, nOrders int, stopLoss float64) error {
+func (m *MockPairRepository) UpdateParams(id int, entrySpread, exitSpread, volume float64, nOrders int, stopLoss float64, entryMode, strategy string, fundingDiff float64, maxHoldHours, leverage int, marginMode string, accounts []string) error {
 	if m.updateErr != nil {
 		return m.updateErr
 	}
@@ -663,6 +664,13 @@ func (m *MockPairRepository) UpdateParams(id int, entrySpread, exitSpread, volum
 		pair.VolumeAsset = volume
 		pair.NOrders = nOrders
 		pair.StopLoss = stopLoss
+		pair.EntryMode = entryMode
+		pair.Strategy = strategy
+		pair.FundingDiffPct = fundingDiff
+		pair.MaxHoldHours = maxHoldHours
+		pair.Leverage = leverage
+		pair.MarginMode = marginMode
+		pair.Accounts = accounts
 		pair.UpdatedAt = time.Now()
 		return nil
 	}
@@ -811,7 +819,7 @@ func contains(s, substr string) bool {
 // ============ Mock ExchangeRepository ============
 
 type MockExchangeRepository struct {
-	accounts    map[string]*models.ExchangeAccount
+	accounts    map[string]*models.ExchangeAccount // по exchange.AccountKey
 	createErr   error
 	getErr      error
 	updateErr   error
@@ -830,22 +838,23 @@ func (m *MockExchangeRepository) Create(account *models.ExchangeAccount) error {
 	if m.createErr != nil {
 		return m.createErr
 	}
-	if _, exists := m.accounts[account.Name]; exists {
+	key := exchange.AccountKey(account.Name, account.Label)
+	if _, exists := m.accounts[key]; exists {
 		return repository.ErrExchangeExists
 	}
 	account.ID = m.nextID
 	m.nextID++
 	account.CreatedAt = time.Now()
 	account.UpdatedAt = time.Now()
-	m.accounts[account.Name] = account
+	m.accounts[key] = account
 	return nil
 }
 
-func (m *MockExchangeRepository) GetByName(name string) (*models.ExchangeAccount, error) {
+func (m *MockExchangeRepository) GetByAccount(name, label string) (*models.ExchangeAccount, error) {
 	if m.getErr != nil {
 		return nil, m.getErr
 	}
-	if account, exists := m.accounts[name]; exists {
+	if account, exists := m.accounts[exchange.AccountKey(name, label)]; exists {
 		return account, nil
 	}
 	return nil, repository.ErrExchangeNotFound
@@ -891,11 +900,12 @@ func (m *MockExchangeRepository) Update(account *models.ExchangeAccount) error {
 	if m.updateErr != nil {
 		return m.updateErr
 	}
-	if _, exists := m.accounts[account.Name]; !exists {
+	key := exchange.AccountKey(account.Name, account.Label)
+	if _, exists := m.accounts[key]; !exists {
 		return repository.ErrExchangeNotFound
 	}
 	account.UpdatedAt = time.Now()
-	m.accounts[account.Name] = account
+	m.accounts[key] = account
 	return nil
 }
 
@@ -3,10 +3,12 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"strings"
 	"sync"
 	"time"
 
+	"arbitrage/internal/exchange"
 	"arbitrage/internal/models"
 	"arbitrage/internal/repository"
 )
@@ -20,8 +22,15 @@ var (
 	ErrInvalidVolume          = errors.New("volume must be greater than 0")
 	ErrInvalidNOrders         = errors.New("number of orders must be at least 1")
 	ErrInvalidStopLoss        = errors.New("stop loss must be non-negative")
+	ErrInvalidEntryMode       = errors.New("entry mode must be 'taker' or 'maker_taker'")
+	ErrInvalidStrategy        = errors.New("strategy must be 'spread' or 'funding'")
+	ErrInvalidFundingDiff     = errors.New("funding differential must be greater than 0")
+	ErrInvalidMaxHold         = errors.New("max hold hours must be non-negative")
+	ErrInvalidLeverage        = errors.New("leverage must be between 1 and 125, or 0 for the default 1x")
+	ErrInvalidMarginMode      = errors.New("margin mode must be 'cross' or 'isolated'")
+	ErrInvalidPairAccounts    = errors.New("accounts must list at least 2 distinct supported accounts")
 	ErrExitSpreadTooHigh      = errors.New("exit spread must be less than entry spread")
-	ErrSymbolNotAvailable     = errors.New("symbol must be available on at least 2 connected exchanges")
+	ErrSymbolNotAvailable     = errors.New("symbol must be available on at least 2 connected accounts")
 	ErrNotEnoughExchanges     = errors.New("at least 2 exchanges must be connected for arbitrage")
 	ErrPairHasOpenPosition    = errors.New("cannot delete pair with open position")
 	ErrPairNotPaused          = errors.New("pair must be paused to delete")
@@ -77,6 +86,13 @@ type PendingConfig struct {
 	VolumeAsset    float64   `json:"volume"`
 	NOrders        int       `json:"n_orders"`
 	StopLoss       float64   `json:"stop_loss"`
+	EntryMode      string    `json:"entry_mode"`
+	Strategy       string    `json:"strategy"`
+	FundingDiffPct float64   `json:"funding_diff"`
+	MaxHoldHours   int       `json:"max_hold_hours"`
+	Leverage       int       `json:"leverage"`
+	MarginMode     string    `json:"margin_mode"`
+	Accounts       []string  `json:"accounts,omitempty"`
 	CreatedAt      time.Time `json:"created_at"`
 }
 
@@ -131,8 +147,8 @@ func (s *PairService) CreatePair(ctx context.Context, cfg *models.PairConfig) er
 		return ErrPairAlreadyExists
 	}
 
-	// 4. Проверка доступности актива на ≥2 биржах
-	availableExchanges, err := s.checkSymbolAvailability(ctx, cfg.Symbol)
+	// 4. Проверка доступности актива на ≥2 аккаунтах пары
+	availableExchanges, err := s.checkSymbolAvailability(ctx, cfg.Symbol, cfg.Accounts)
 	if err != nil {
 		return err
 	}
@@ -200,6 +216,27 @@ func (s *PairService) UpdatePair(ctx context.Context, id int, params UpdatePairP
 	if params.StopLoss != nil {
 		updated.StopLoss = *params.StopLoss
 	}
+	if params.EntryMode != nil {
+		updated.EntryMode = *params.EntryMode
+	}
+	if params.Strategy != nil {
+		updated.Strategy = *params.Strategy
+	}
+	if params.FundingDiffPct != nil {
+		updated.FundingDiffPct = *params.FundingDiffPct
+	}
+	if params.MaxHoldHours != nil {
+		updated.MaxHoldHours = *params.MaxHoldHours
+	}
+	if params.Leverage != nil {
+		updated.Leverage = *params.Leverage
+	}
+	if params.MarginMode != nil {
+		updated.MarginMode = *params.MarginMode
+	}
+	if params.Accounts != nil {
+		updated.Accounts = normalizeAccounts(*params.Accounts)
+	}
 
 	// 3. Валидация новых параметров
 	if err := s.validatePairParams(&updated); err != nil {
@@ -217,6 +254,13 @@ func (s *PairService) UpdatePair(ctx context.Context, id int, params UpdatePairP
 			VolumeAsset:    updated.VolumeAsset,
 			NOrders:        updated.NOrders,
 			StopLoss:       updated.StopLoss,
+			EntryMode:      updated.EntryMode,
+			Strategy:       updated.Strategy,
+			FundingDiffPct: updated.FundingDiffPct,
+			MaxHoldHours:   updated.MaxHoldHours,
+			Leverage:       updated.Leverage,
+			MarginMode:     updated.MarginMode,
+			Accounts:       updated.Accounts,
 			CreatedAt:      time.Now(),
 		})
 
@@ -232,6 +276,13 @@ func (s *PairService) UpdatePair(ctx context.Context, id int, params UpdatePairP
 		updated.VolumeAsset,
 		updated.NOrders,
 		updated.StopLoss,
+		updated.EntryMode,
+		updated.Strategy,
+		updated.FundingDiffPct,
+		updated.MaxHoldHours,
+		updated.Leverage,
+		updated.MarginMode,
+		updated.Accounts,
 	); err != nil {
 		return nil, err
 	}
@@ -246,11 +297,18 @@ func (s *PairService) UpdatePair(ctx context.Context, id int, params UpdatePairP
 
 // UpdatePairParams содержит параметры для обновления пары
 type UpdatePairParams struct {
-	EntrySpreadPct *float64 `json:"entry_spread,omitempty"`
-	ExitSpreadPct  *float64 `json:"exit_spread,omitempty"`
-	VolumeAsset    *float64 `json:"volume,omitempty"`
-	NOrders        *int     `json:"n_orders,omitempty"`
-	StopLoss       *float64 `json:"stop_loss,omitempty"`
+	EntrySpreadPct *float64  `json:"entry_spread,omitempty"`
+	ExitSpreadPct  *float64  `json:"exit_spread,omitempty"`
+	VolumeAsset    *float64  `json:"volume,omitempty"`
+	NOrders        *int      `json:"n_orders,omitempty"`
+	StopLoss       *float64  `json:"stop_loss,omitempty"`
+	EntryMode      *string   `json:"entry_mode,omitempty"`
+	Strategy       *string   `json:"strategy,omitempty"`
+	FundingDiffPct *float64  `json:"funding_diff,omitempty"`
+	MaxHoldHours   *int      `json:"max_hold_hours,omitempty"`
+	Leverage       *int      `json:"leverage,omitempty"`
+	MarginMode     *string   `json:"margin_mode,omitempty"`
+	Accounts       *[]string `json:"accounts,omitempty"` // пустой список снимает привязку к аккаунтам
 }
 
 // DeletePair удаляет торговую пару
@@ -320,8 +378,8 @@ func (s *PairService) StartPair(ctx context.Context, id int) error {
 		return ErrNotEnoughExchanges
 	}
 
-	// 4. Проверяем доступность символа
-	available, err := s.checkSymbolAvailability(ctx, pair.Symbol)
+	// 4. Проверяем доступность символа на аккаунтах пары
+	available, err := s.checkSymbolAvailability(ctx, pair.Symbol, pair.Accounts)
 	if err != nil {
 		return err
 	}
@@ -334,9 +392,15 @@ func (s *PairService) StartPair(ctx context.Context, id int) error {
 		return err
 	}
 
-	// 6. Запускаем в движке
+	// 6. Запускаем в движке (плечо и режим маржи сверяются на биржах)
+	// Пара, не прошедшая проверку, остаётся на паузе
 	if s.engine != nil {
-		return s.engine.StartPair(id)
+		if err := s.engine.StartPair(id); err != nil {
+			if statusErr := s.pairRepo.UpdateStatus(id, models.PairStatusPaused); statusErr != nil {
+				return fmt.Errorf("%w (failed to restore paused status: %v)", err, statusErr)
+			}
+			return err
+		}
 	}
 
 	return nil
@@ -479,6 +543,13 @@ func (s *PairService) ApplyPendingConfig(ctx context.Context, id int) error {
 		pending.VolumeAsset,
 		pending.NOrders,
 		pending.StopLoss,
+		pending.EntryMode,
+		pending.Strategy,
+		pending.FundingDiffPct,
+		pending.MaxHoldHours,
+		pending.Leverage,
+		pending.MarginMode,
+		pending.Accounts,
 	); err != nil {
 		return err
 	}
@@ -518,19 +589,61 @@ func (s *PairService) validatePairParams(cfg *models.PairConfig) error {
 		return ErrInvalidSymbol
 	}
 
-	// Валидация спреда входа (> 0)
-	if cfg.EntrySpreadPct <= 0 {
-		return ErrInvalidEntrySpread
+	// Валидация стратегии (пустая - spread по умолчанию)
+	if cfg.Strategy != "" && cfg.Strategy != models.StrategySpread && cfg.Strategy != models.StrategyFunding {
+		return ErrInvalidStrategy
+	}
+
+	if cfg.IsFundingStrategy() {
+		// Фандинговая пара входит по разнице ставок, спреды не используются
+		if cfg.FundingDiffPct <= 0 {
+			return ErrInvalidFundingDiff
+		}
+	} else {
+		// Валидация спреда входа (> 0)
+		if cfg.EntrySpreadPct <= 0 {
+			return ErrInvalidEntrySpread
+		}
+
+		// Валидация спреда выхода (> 0)
+		if cfg.ExitSpreadPct <= 0 {
+			return ErrInvalidExitSpread
+		}
+
+		// Спред выхода должен быть меньше спреда входа
+		if cfg.ExitSpreadPct >= cfg.EntrySpreadPct {
+			return ErrExitSpreadTooHigh
+		}
+	}
+
+	// Валидация максимального времени удержания (0 - без ограничения)
+	if cfg.MaxHoldHours < 0 {
+		return ErrInvalidMaxHold
 	}
 
-	// Валидация спреда выхода (> 0)
-	if cfg.ExitSpreadPct <= 0 {
-		return ErrInvalidExitSpread
+	// Валидация плеча (0 - по умолчанию 1)
+	if cfg.Leverage < 0 || cfg.Leverage > models.MaxLeverage {
+		return ErrInvalidLeverage
 	}
 
-	// Спред выхода должен быть меньше спреда входа
-	if cfg.ExitSpreadPct >= cfg.EntrySpreadPct {
-		return ErrExitSpreadTooHigh
+	// Валидация режима маржи (пустой - cross по умолчанию)
+	if cfg.MarginMode != "" && cfg.MarginMode != models.MarginModeCross && cfg.MarginMode != models.MarginModeIsolated {
+		return ErrInvalidMarginMode
+	}
+
+	// Валидация аккаунтов (пусто - любые подключенные)
+	cfg.Accounts = normalizeAccounts(cfg.Accounts)
+	if len(cfg.Accounts) > 0 {
+		if len(cfg.Accounts) < 2 {
+			return ErrInvalidPairAccounts
+		}
+		seen := make(map[string]bool, len(cfg.Accounts))
+		for _, account := range cfg.Accounts {
+			if !exchange.IsSupportedAccount(account) || seen[account] {
+				return ErrInvalidPairAccounts
+			}
+			seen[account] = true
+		}
 	}
 
 	// Валидация объема (> 0)
@@ -548,13 +661,19 @@ func (s *PairService) validatePairParams(cfg *models.PairConfig) error {
 		return ErrInvalidStopLoss
 	}
 
+	// Валидация режима входа (пустой - taker по умолчанию)
+	if cfg.EntryMode != "" && cfg.EntryMode != models.EntryModeTaker && cfg.EntryMode != models.EntryModeMakerTaker {
+		return ErrInvalidEntryMode
+	}
+
 	return nil
 }
 
-// checkSymbolAvailability проверяет доступность символа на подключенных биржах
-// Возвращает список бирж, где символ доступен
-func (s *PairService) checkSymbolAvailability(ctx context.Context, symbol string) ([]string, error) {
-	// Получаем подключенные биржи
+// checkSymbolAvailability проверяет доступность символа на подключенных аккаунтах
+// accounts ограничивает проверку аккаунтами пары (пусто - все подключенные)
+// Возвращает идентификаторы аккаунтов, где символ доступен
+func (s *PairService) checkSymbolAvailability(ctx context.Context, symbol string, accounts []string) ([]string, error) {
+	// Получаем подключенные аккаунты
 	connected, err := s.exchangeRepo.GetConnected()
 	if err != nil {
 		return nil, err
@@ -564,26 +683,45 @@ func (s *PairService) checkSymbolAvailability(ctx context.Context, symbol string
 		return nil, ErrNotEnoughExchanges
 	}
 
+	pair := &models.PairConfig{Accounts: accounts}
 	var available []string
 
-	// Проверяем каждую биржу
+	// Проверяем каждый аккаунт
 	for _, account := range connected {
+		key := exchange.AccountKey(account.Name, account.Label)
+		if !pair.UsesAccount(key) {
+			continue
+		}
+
 		// Получаем соединение
-		conn, err := s.exchangeSvc.GetConnection(ctx, account.Name)
+		conn, err := s.exchangeSvc.GetConnection(ctx, key)
 		if err != nil {
-			continue // Пропускаем биржу с ошибкой соединения
+			continue // Пропускаем аккаунт с ошибкой соединения
 		}
 
 		// Пробуем получить тикер для проверки доступности символа
 		_, err = conn.GetTicker(ctx, symbol)
 		if err == nil {
-			available = append(available, account.Name)
+			available = append(available, key)
 		}
 	}
 
 	return available, nil
 }
 
+// normalizeAccounts приводит идентификаторы аккаунтов к виду exchange.AccountKey
+// Пустой список - nil (пара без привязки)
+func normalizeAccounts(accounts []string) []string {
+	if len(accounts) == 0 {
+		return nil
+	}
+	normalized := make([]string, len(accounts))
+	for i, account := range accounts {
+		normalized[i] = exchange.AccountKey(exchange.SplitAccountKey(strings.TrimSpace(account)))
+	}
+	return normalized
+}
+
 // hasOpenPosition проверяет, есть ли открытая позиция у пары
 func (s *PairService) hasOpenPosition(id int) bool {
 	if s.engine == nil {
@@ -619,10 +757,10 @@ func (s *PairService) clearPendingConfig(id int) {
 	delete(s.pendingChanges, id)
 }
 
-// GetSymbolAvailability возвращает список бирж, на которых доступен символ
+// GetSymbolAvailability возвращает список аккаунтов, на которых доступен символ
 // Публичный метод для использования в handlers
 func (s *PairService) GetSymbolAvailability(ctx context.Context, symbol string) ([]string, error) {
-	return s.checkSymbolAvailability(ctx, strings.ToUpper(symbol))
+	return s.checkSymbolAvailability(ctx, strings.ToUpper(symbol), nil)
 }
 
 // RecordTradeCompletion записывает завершение сделки и обновляет статистику пары
@@ -842,7 +842,7 @@ func updatePairFromMock(repo *MockPairRepository, id int, params UpdatePairParam
 		return nil, err
 	}
 
-	_ = repo.UpdateParams(id, updated.EntrySpreadPct, updated.ExitSpreadPct, updated.VolumeAsset, updated.NOrders, updated.StopLoss)
+	_ = repo.UpdateParams(id, updated.EntrySpreadPct, updated.ExitSpreadPct, updated.VolumeAsset, updated.NOrders, updated.StopLoss, updated.EntryMode, updated.Strategy, updated.FundingDiffPct, updated.MaxHoldHours, updated.Leverage, updated.MarginMode, updated.Accounts)
 
 	return &updated, nil
 }
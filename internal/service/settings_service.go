@@ -12,14 +12,24 @@ var (
 	ErrInvalidMaxConcurrentTrades = errors.New("max_concurrent_trades must be >= 1 or null")
 )
 
+// SettingsEngine - интерфейс торгового движка для применения глобальных настроек
+type SettingsEngine interface {
+	// SetConsiderFunding включает учёт фандинга в чистом спреде
+	SetConsiderFunding(consider bool)
+}
+
 // SettingsService предоставляет бизнес-логику для управления глобальными настройками.
 //
 // Отвечает за:
 // - Получение и обновление глобальных настроек бота
 // - Валидацию параметров настроек
 // - Управление notification_prefs, max_concurrent_trades, consider_funding
+// - Применение consider_funding в торговом движке
 type SettingsService struct {
 	settingsRepo *repository.SettingsRepository
+
+	// Торговый движок (может быть nil при инициализации)
+	engine SettingsEngine
 }
 
 // NewSettingsService создает новый экземпляр SettingsService.
@@ -29,6 +39,12 @@ func NewSettingsService(settingsRepo *repository.SettingsRepository) *SettingsSe
 	}
 }
 
+// SetEngine устанавливает торговый движок
+// После вызова изменения consider_funding сразу применяются в движке
+func (s *SettingsService) SetEngine(engine SettingsEngine) {
+	s.engine = engine
+}
+
 // GetSettings возвращает текущие глобальные настройки.
 //
 // Если записи в БД нет, создается запись с дефолтными значениями.
@@ -89,6 +105,10 @@ func (s *SettingsService) UpdateSettings(req *UpdateSettingsRequest) (*models.Se
 		return nil, err
 	}
 
+	if req.ConsiderFunding != nil && s.engine != nil {
+		s.engine.SetConsiderFunding(settings.ConsiderFunding)
+	}
+
 	return settings, nil
 }
 
@@ -111,7 +131,14 @@ func (s *SettingsService) UpdateMaxConcurrentTrades(maxTrades *int) error {
 
 // UpdateConsiderFunding обновляет настройку учета фандинга.
 func (s *SettingsService) UpdateConsiderFunding(consider bool) error {
-	return s.settingsRepo.UpdateConsiderFunding(consider)
+	if err := s.settingsRepo.UpdateConsiderFunding(consider); err != nil {
+		return err
+	}
+
+	if s.engine != nil {
+		s.engine.SetConsiderFunding(consider)
+	}
+	return nil
 }
 
 // GetNotificationPrefs возвращает только настройки уведомлений.
@@ -132,5 +159,12 @@ func (s *SettingsService) GetMaxConcurrentTrades() (*int, error) {
 // - max_concurrent_trades: null (без ограничений)
 // - notification_prefs: все типы включены (true)
 func (s *SettingsService) ResetToDefaults() error {
-	return s.settingsRepo.ResetToDefaults()
+	if err := s.settingsRepo.ResetToDefaults(); err != nil {
+		return err
+	}
+
+	if s.engine != nil {
+		s.engine.SetConsiderFunding(false)
+	}
+	return nil
 }
@@ -305,12 +305,12 @@ func (h *Hub) BroadcastNotification(notif *models.Notification) {
 	h.Broadcast(msg)
 }
 
-// BroadcastBalanceUpdate отправляет обновление баланса биржи
+// BroadcastBalanceUpdate отправляет обновление баланса аккаунта биржи
 //
 // Использует типизированное сообщение BalanceUpdateMessage
-// Отправляется каждую минуту для каждой подключенной биржи
-func (h *Hub) BroadcastBalanceUpdate(exchange string, balance float64) {
-	msg := NewBalanceUpdateMessage(exchange, balance)
+// Отправляется каждую минуту для каждого подключенного аккаунта
+func (h *Hub) BroadcastBalanceUpdate(account string, balance float64) {
+	msg := NewBalanceUpdateMessage(account, balance)
 	h.Broadcast(msg)
 }
 
@@ -323,7 +323,7 @@ func (h *Hub) BroadcastStatsUpdate(stats *models.Stats) {
 	h.Broadcast(msg)
 }
 
-// BroadcastAllBalances отправляет балансы всех бирж
+// BroadcastAllBalances отправляет балансы всех аккаунтов бирж
 //
 // Используется при начальной загрузке frontend или массовом обновлении
 func (h *Hub) BroadcastAllBalances(balances map[string]float64) {
@@ -3,6 +3,7 @@ package websocket
 import (
 	"time"
 
+	"arbitrage/internal/exchange"
 	"arbitrage/internal/models"
 )
 
@@ -134,13 +135,15 @@ type NotificationData struct {
 	Timestamp time.Time `json:"timestamp"`
 }
 
-// BalanceUpdateMessage - сообщение об обновлении баланса биржи
+// BalanceUpdateMessage - сообщение об обновлении баланса аккаунта биржи
 //
-// Отправляется каждую минуту для каждой подключенной биржи
+// Отправляется каждую минуту для каждого подключенного аккаунта
 // Позволяет frontend отображать актуальные балансы в реальном времени
 type BalanceUpdateMessage struct {
 	BaseMessage
-	Exchange string  `json:"exchange"`
+	Exchange string  `json:"exchange"`        // имя биржи
+	Account  string  `json:"account"`         // идентификатор аккаунта: bybit, arb1@bybit
+	Label    string  `json:"label,omitempty"` // метка субаккаунта
 	Balance  float64 `json:"balance"`
 }
 
@@ -235,14 +238,17 @@ func NewNotificationMessage(notif *models.Notification) *NotificationMessage {
 	}
 }
 
-// NewBalanceUpdateMessage создает сообщение обновления баланса
-func NewBalanceUpdateMessage(exchange string, balance float64) *BalanceUpdateMessage {
+// NewBalanceUpdateMessage создает сообщение обновления баланса аккаунта
+func NewBalanceUpdateMessage(account string, balance float64) *BalanceUpdateMessage {
+	venue, label := exchange.SplitAccountKey(account)
 	return &BalanceUpdateMessage{
 		BaseMessage: BaseMessage{
 			Type:      MessageTypeBalanceUpdate,
 			Timestamp: time.Now(),
 		},
-		Exchange: exchange,
+		Exchange: venue,
+		Account:  exchange.AccountKey(venue, label),
+		Label:    label,
 		Balance:  balance,
 	}
 }
@@ -280,11 +286,11 @@ func NewStatsUpdateMessage(stats *models.Stats) *StatsUpdateMessage {
 
 // ============ Дополнительные типы для совместимости ============
 
-// AllBalancesUpdateMessage - сообщение с балансами всех бирж
+// AllBalancesUpdateMessage - сообщение с балансами всех аккаунтов бирж
 // Используется при начальной загрузке или массовом обновлении
 type AllBalancesUpdateMessage struct {
 	BaseMessage
-	Balances map[string]float64 `json:"balances"`
+	Balances map[string]float64 `json:"balances"` // по идентификатору аккаунта: bybit, arb1@bybit
 }
 
 // NewAllBalancesUpdateMessage создает сообщение со всеми балансами
@@ -0,0 +1,3 @@
+-- Откат миграции 009
+ALTER TABLE pairs DROP CONSTRAINT IF EXISTS chk_pairs_entry_mode;
+ALTER TABLE pairs DROP COLUMN IF EXISTS entry_mode;
@@ -0,0 +1,14 @@
+-- Миграция 009: Режим входа в позицию для пары
+-- taker: обе ноги рыночными ордерами; maker_taker: post-only + рыночный хедж
+
+ALTER TABLE pairs ADD COLUMN IF NOT EXISTS entry_mode VARCHAR(20) NOT NULL DEFAULT 'taker';
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint WHERE conname = 'chk_pairs_entry_mode'
+    ) THEN
+        ALTER TABLE pairs ADD CONSTRAINT chk_pairs_entry_mode
+            CHECK (entry_mode IN ('taker', 'maker_taker'));
+    END IF;
+END $$;
@@ -0,0 +1,6 @@
+-- Откат миграции 010
+ALTER TABLE pairs DROP CONSTRAINT IF EXISTS chk_pairs_max_hold_hours;
+ALTER TABLE pairs DROP CONSTRAINT IF EXISTS chk_pairs_strategy;
+ALTER TABLE pairs DROP COLUMN IF EXISTS max_hold_hours;
+ALTER TABLE pairs DROP COLUMN IF EXISTS funding_diff_pct;
+ALTER TABLE pairs DROP COLUMN IF EXISTS strategy;
@@ -0,0 +1,23 @@
+-- Миграция 010: Стратегия пары
+-- spread: схождение межбиржевого спреда; funding: удержание позиции ради разницы ставок фандинга
+
+ALTER TABLE pairs ADD COLUMN IF NOT EXISTS strategy VARCHAR(20) NOT NULL DEFAULT 'spread';
+ALTER TABLE pairs ADD COLUMN IF NOT EXISTS funding_diff_pct DECIMAL(10,4) NOT NULL DEFAULT 0;
+ALTER TABLE pairs ADD COLUMN IF NOT EXISTS max_hold_hours INTEGER NOT NULL DEFAULT 0;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint WHERE conname = 'chk_pairs_strategy'
+    ) THEN
+        ALTER TABLE pairs ADD CONSTRAINT chk_pairs_strategy
+            CHECK (strategy IN ('spread', 'funding'));
+    END IF;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint WHERE conname = 'chk_pairs_max_hold_hours'
+    ) THEN
+        ALTER TABLE pairs ADD CONSTRAINT chk_pairs_max_hold_hours
+            CHECK (max_hold_hours >= 0);
+    END IF;
+END $$;
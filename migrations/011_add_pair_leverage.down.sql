@@ -0,0 +1,5 @@
+-- Откат миграции 011
+ALTER TABLE pairs DROP CONSTRAINT IF EXISTS chk_pairs_margin_mode;
+ALTER TABLE pairs DROP CONSTRAINT IF EXISTS chk_pairs_leverage;
+ALTER TABLE pairs DROP COLUMN IF EXISTS margin_mode;
+ALTER TABLE pairs DROP COLUMN IF EXISTS leverage;
@@ -0,0 +1,22 @@
+-- Миграция 011: Плечо и режим маржи пары
+-- Устанавливаются на обеих биржах перед первым входом
+
+ALTER TABLE pairs ADD COLUMN IF NOT EXISTS leverage INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE pairs ADD COLUMN IF NOT EXISTS margin_mode VARCHAR(20) NOT NULL DEFAULT 'cross';
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint WHERE conname = 'chk_pairs_leverage'
+    ) THEN
+        ALTER TABLE pairs ADD CONSTRAINT chk_pairs_leverage
+            CHECK (leverage BETWEEN 1 AND 125);
+    END IF;
+
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint WHERE conname = 'chk_pairs_margin_mode'
+    ) THEN
+        ALTER TABLE pairs ADD CONSTRAINT chk_pairs_margin_mode
+            CHECK (margin_mode IN ('cross', 'isolated'));
+    END IF;
+END $$;
@@ -0,0 +1,8 @@
+-- Откат миграции 012
+-- Субаккаунты удаляются: без метки имя биржи снова уникально
+ALTER TABLE pairs DROP COLUMN IF EXISTS accounts;
+DELETE FROM exchanges WHERE label <> '';
+ALTER TABLE exchanges DROP CONSTRAINT IF EXISTS chk_exchanges_label;
+DROP INDEX IF EXISTS idx_exchanges_name_label;
+ALTER TABLE exchanges DROP COLUMN IF EXISTS label;
+ALTER TABLE exchanges ADD CONSTRAINT exchanges_name_key UNIQUE (name);
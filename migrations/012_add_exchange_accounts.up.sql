@@ -0,0 +1,19 @@
+-- Миграция 012: Несколько аккаунтов на бирже
+-- Аккаунт определяется биржей (name) и меткой; пустая метка - основной аккаунт.
+-- Пара может быть привязана к аккаунтам ("bybit", "arb1@bybit"), пустой список - любые подключенные
+
+ALTER TABLE exchanges ADD COLUMN IF NOT EXISTS label VARCHAR(32) NOT NULL DEFAULT '';
+ALTER TABLE exchanges DROP CONSTRAINT IF EXISTS exchanges_name_key;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_exchanges_name_label ON exchanges(name, label);
+
+ALTER TABLE pairs ADD COLUMN IF NOT EXISTS accounts TEXT[] NOT NULL DEFAULT '{}';
+
+DO $$
+BEGIN
+    IF NOT EXISTS (
+        SELECT 1 FROM pg_constraint WHERE conname = 'chk_exchanges_label'
+    ) THEN
+        ALTER TABLE exchanges ADD CONSTRAINT chk_exchanges_label
+            CHECK (label ~ '^[a-z0-9_-]*$');
+    END IF;
+END $$;
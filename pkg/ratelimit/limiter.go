@@ -68,6 +68,9 @@ func NewRateLimiter(rate, burst float64) *RateLimiter {
 // ВАЖНО: вызывается под lock'ом
 func (rl *RateLimiter) refill() {
 	now := time.Now()
+	if now.Before(rl.lastRefill) {
+		return // пауза после превышения лимита (см. Pause)
+	}
 	elapsed := now.Sub(rl.lastRefill).Seconds()
 
 	// Добавляем токены пропорционально прошедшему времени
@@ -81,6 +84,16 @@ func (rl *RateLimiter) refill() {
 	rl.lastRefill = now
 }
 
+// waitDuration возвращает время до накопления need токенов с учётом паузы
+// ВАЖНО: вызывается под lock'ом
+func (rl *RateLimiter) waitDuration(need float64) time.Duration {
+	wait := time.Duration((need - rl.tokens) / rl.rate * float64(time.Second))
+	if pause := time.Until(rl.lastRefill); pause > 0 {
+		wait += pause
+	}
+	return wait
+}
+
 // Wait блокирует до получения токена или отмены контекста
 //
 // Возвращает:
@@ -107,7 +120,7 @@ func (rl *RateLimiter) Wait(ctx context.Context) error {
 		}
 
 		// Вычисляем время ожидания до следующего токена
-		waitTime := time.Duration((1 - rl.tokens) / rl.rate * float64(time.Second))
+		waitTime := rl.waitDuration(1)
 		rl.mu.Unlock()
 
 		// Ждём с возможностью отмены
@@ -136,6 +149,79 @@ func (rl *RateLimiter) WaitN(ctx context.Context, n int) error {
 	return nil
 }
 
+// WaitWeight блокирует до получения weight токенов, оставляя в ведре не меньше reserve
+//
+// reserve - запас для более приоритетных запросов: запрос с reserve = 0 берёт
+// любые доступные токены, запрос с reserve > 0 ждёт, пока запас не накопится.
+// Вес больше burst допустим: ведро уходит в минус, и следующие запросы ждут дольше
+//
+// Пример:
+//
+//	limiter.WaitWeight(ctx, 1, 0)  // ордер - без запаса
+//	limiter.WaitWeight(ctx, 5, 10) // опрос баланса уступает 10 токенов ордерам
+func (rl *RateLimiter) WaitWeight(ctx context.Context, weight, reserve float64) error {
+	if weight <= 0 {
+		return nil
+	}
+
+	for {
+		rl.mu.Lock()
+		rl.refill()
+
+		need := weight + reserve
+		if need > rl.burst {
+			need = rl.burst
+		}
+
+		if rl.tokens >= need {
+			rl.tokens -= weight
+			rl.mu.Unlock()
+			return nil
+		}
+
+		waitTime := rl.waitDuration(need)
+		rl.mu.Unlock()
+
+		select {
+		case <-time.After(waitTime):
+			continue
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+// Pause опустошает ведро и останавливает пополнение на d
+// Используется, когда биржа сообщила о превышении лимита (HTTP 429, бан по IP)
+func (rl *RateLimiter) Pause(d time.Duration) {
+	if d <= 0 {
+		return
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.refill()
+	if rl.tokens > 0 {
+		rl.tokens = 0
+	}
+	if until := time.Now().Add(d); until.After(rl.lastRefill) {
+		rl.lastRefill = until
+	}
+}
+
+// LimitTokens ограничивает количество доступных токенов сверху
+// Используется для подстройки под остаток лимита, который сообщает биржа
+func (rl *RateLimiter) LimitTokens(max float64) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.refill()
+	if rl.tokens > max {
+		rl.tokens = max
+	}
+}
+
 // Allow проверяет доступность токена без блокировки
 //
 // Возвращает:
@@ -207,7 +293,7 @@ func (rl *RateLimiter) Reserve() *Reservation {
 	} else {
 		// Резервируем будущий токен
 		res.ok = true
-		res.delay = time.Duration((1 - rl.tokens) / rl.rate * float64(time.Second))
+		res.delay = rl.waitDuration(1)
 		rl.tokens-- // уходим в минус, refill восполнит
 	}
 
@@ -341,6 +427,19 @@ func (ml *MultiLimiter) Wait(ctx context.Context, category string) error {
 	return limiter.Wait(ctx)
 }
 
+// WaitWeight ожидает weight токенов для указанной категории
+func (ml *MultiLimiter) WaitWeight(ctx context.Context, category string, weight float64) error {
+	ml.mu.RLock()
+	limiter, ok := ml.limiters[category]
+	ml.mu.RUnlock()
+
+	if !ok {
+		return nil // нет лимита для этой категории
+	}
+
+	return limiter.WaitWeight(ctx, weight, 0)
+}
+
 // Allow проверяет доступность токена для категории
 func (ml *MultiLimiter) Allow(category string) bool {
 	ml.mu.RLock()
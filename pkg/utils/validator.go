@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"regexp"
 	"strings"
+	"sync"
 	"unicode"
 )
 
@@ -88,14 +89,29 @@ const (
 	MaxLeverage     = 100    // Максимальное плечо
 )
 
-// SupportedExchanges - список поддерживаемых бирж
-var SupportedExchanges = []string{
-	"bybit",
-	"bitget",
-	"okx",
-	"gate",
-	"htx",
-	"bingx",
+// supportedExchanges - список поддерживаемых бирж
+// Заполняется реестром адаптеров internal/exchange через RegisterExchange
+var (
+	supportedExchangesMu sync.RWMutex
+	supportedExchanges   []string
+)
+
+// RegisterExchange добавляет биржу в список поддерживаемых
+// Вызывается при регистрации адаптера (exchange.Register), повторное имя игнорируется
+func RegisterExchange(name string) {
+	name = NormalizeExchange(name)
+	if name == "" {
+		return
+	}
+
+	supportedExchangesMu.Lock()
+	defer supportedExchangesMu.Unlock()
+	for _, supported := range supportedExchanges {
+		if supported == name {
+			return
+		}
+	}
+	supportedExchanges = append(supportedExchanges, name)
 }
 
 // ============================================================
@@ -476,13 +492,14 @@ func ValidateExchange(exchange string) error {
 
 	exchange = strings.ToLower(strings.TrimSpace(exchange))
 
-	for _, supported := range SupportedExchanges {
-		if exchange == supported {
+	supported := GetSupportedExchanges()
+	for _, name := range supported {
+		if exchange == name {
 			return nil
 		}
 	}
 
-	return fmt.Errorf("%w: %s (supported: %s)", ErrInvalidExchange, exchange, strings.Join(SupportedExchanges, ", "))
+	return fmt.Errorf("%w: %s (supported: %s)", ErrInvalidExchange, exchange, strings.Join(supported, ", "))
 }
 
 // NormalizeExchange приводит название биржи к стандартному виду (lowercase)
@@ -624,7 +641,10 @@ func IsSupportedExchange(exchange string) bool {
 
 // GetSupportedExchanges возвращает список поддерживаемых бирж
 func GetSupportedExchanges() []string {
-	result := make([]string, len(SupportedExchanges))
-	copy(result, SupportedExchanges)
+	supportedExchangesMu.RLock()
+	defer supportedExchangesMu.RUnlock()
+
+	result := make([]string, len(supportedExchanges))
+	copy(result, supportedExchanges)
 	return result
 }
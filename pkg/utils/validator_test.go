@@ -4,6 +4,15 @@ import (
 	"testing"
 )
 
+// testExchanges - биржи, которые в приложении регистрирует реестр адаптеров internal/exchange
+var testExchanges = []string{"bybit", "bitget", "okx", "gate", "htx", "bingx", "binance"}
+
+func init() {
+	for _, name := range testExchanges {
+		RegisterExchange(name)
+	}
+}
+
 func TestValidateSymbol(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -370,10 +379,11 @@ func TestValidateExchange(t *testing.T) {
 		{"valid gate", "gate", false},
 		{"valid htx", "htx", false},
 		{"valid bingx", "bingx", false},
+		{"valid binance", "binance", false},
 		{"valid uppercase", "BYBIT", false},
 		{"valid mixed case", "Bybit", false},
 		{"empty", "", true},
-		{"unsupported", "binance", true},
+		{"unsupported", "deribit", true},
 		{"unsupported kraken", "kraken", true},
 	}
 
@@ -609,17 +619,28 @@ func TestIsValidExchange(t *testing.T) {
 func TestGetSupportedExchanges(t *testing.T) {
 	exchanges := GetSupportedExchanges()
 
-	if len(exchanges) != len(SupportedExchanges) {
-		t.Errorf("GetSupportedExchanges() length = %d, want %d", len(exchanges), len(SupportedExchanges))
+	if len(exchanges) != len(testExchanges) {
+		t.Errorf("GetSupportedExchanges() length = %d, want %d", len(exchanges), len(testExchanges))
 	}
 
 	// Verify it's a copy
 	exchanges[0] = "modified"
-	if SupportedExchanges[0] == "modified" {
+	if GetSupportedExchanges()[0] == "modified" {
 		t.Error("GetSupportedExchanges() should return a copy, not the original")
 	}
 }
 
+func TestRegisterExchange(t *testing.T) {
+	// Повторная регистрация и регистр имени не создают дубликатов
+	RegisterExchange("BYBIT")
+	RegisterExchange(" okx ")
+	RegisterExchange("")
+
+	if got := len(GetSupportedExchanges()); got != len(testExchanges) {
+		t.Errorf("GetSupportedExchanges() length = %d, want %d", got, len(testExchanges))
+	}
+}
+
 // Benchmarks
 
 func BenchmarkValidateSymbol(b *testing.B) {